@@ -76,11 +76,13 @@ to assist developers in writing, debugging, and understanding code directly from
 
 		// Set up the TUI
 		zone.NewGlobal()
-		program := tea.NewProgram(
-			tui.New(app),
-			tea.WithAltScreen(),
-			tea.WithMouseCellMotion(),
-		)
+		opts := []tea.ProgramOption{tea.WithAltScreen()}
+		if config.Get().TUI.DisableMouse {
+			zone.SetEnabled(false)
+		} else {
+			opts = append(opts, tea.WithMouseCellMotion())
+		}
+		program := tea.NewProgram(tui.New(app), opts...)
 
 		// Initialize MCP tools in the background
 		initMCPTools(ctx, app)
@@ -219,6 +221,10 @@ func setupSubscriptions(app *app.App, parentCtx context.Context) (chan tea.Msg,
 	setupSubscriber(ctx, &wg, "sessions", app.Sessions.Subscribe, ch)
 	setupSubscriber(ctx, &wg, "messages", app.Messages.Subscribe, ch)
 	setupSubscriber(ctx, &wg, "permissions", app.Permissions.Subscribe, ch)
+	setupSubscriber(ctx, &wg, "agent-progress", app.CoderAgent.Subscribe, ch)
+	if app.Swarm != nil {
+		setupSubscriber(ctx, &wg, "swarm", app.Swarm.Subscribe, ch)
+	}
 
 	cleanupFunc := func() {
 		logging.Info("Cancelling all subscriptions")
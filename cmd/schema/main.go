@@ -98,6 +98,42 @@ func generateSchema() map[string]any {
 		},
 	}
 
+	// Add TUI configuration
+	schema["properties"].(map[string]any)["tui"] = map[string]any{
+		"type":        "object",
+		"description": "Terminal user interface configuration",
+		"properties": map[string]any{
+			"theme": map[string]any{
+				"type":        "string",
+				"description": "Color theme for the TUI",
+				"enum":        []string{"dark", "light", "high-contrast", "catppuccin", "custom"},
+				"default":     "dark",
+			},
+			"customTheme": map[string]any{
+				"type":        "object",
+				"description": "Hex color overrides applied on top of the dark theme when theme is \"custom\"",
+				"additionalProperties": map[string]any{
+					"type":    "string",
+					"pattern": "^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$",
+				},
+			},
+			"disableMouse": map[string]any{
+				"type":        "boolean",
+				"description": "Disable mouse support to keep the terminal's native text selection",
+				"default":     false,
+			},
+			"keybindings": map[string]any{
+				"type":        "object",
+				"description": "Key overrides by action, e.g. \"app.logs\": [\"ctrl+g\"]. Sidebar toggles are pressed after chat.sidebarPrefix",
+				"additionalProperties": map[string]any{
+					"type":     "array",
+					"items":    map[string]any{"type": "string"},
+					"minItems": 1,
+				},
+			},
+		},
+	}
+
 	// Add MCP servers
 	schema["properties"].(map[string]any)["mcpServers"] = map[string]any{
 		"type":        "object",
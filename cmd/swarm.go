@@ -0,0 +1,885 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/api"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+	"github.com/opencode-ai/opencode/internal/swarm/vault"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+var swarmCmd = &cobra.Command{
+	Use:   "swarm",
+	Short: "Run and control a multi-agent swarm without the TUI",
+	Long: `Run a swarm coordinator headless and control it through its HTTP API.
+
+Start a swarm in one terminal:
+
+  opencode swarm start -f swarm.yaml
+
+and talk to it from another:
+
+  opencode swarm submit --type analysis --description "Look for flaky tests"
+  opencode swarm tasks
+  opencode swarm status
+  opencode swarm stop
+
+See which swarm agents changed a file with:
+
+  opencode swarm who-changed main.go
+
+Create a master key to encrypt the artifacts and recordings of swarms with
+"encryption: {atRest: true}" with:
+
+  opencode swarm keygen --keychain
+
+Keep the API keys of providers in the OS keychain with:
+
+  opencode swarm secrets set openrouter`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Flags parsed fine, so errors from here on are not usage errors
+		cmd.SilenceUsage = true
+	},
+}
+
+var swarmStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start a swarm coordinator and serve its API",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("file")
+		cfg := swarm.FileConfig{API: swarm.DefaultAPIAddress}
+		if path != "" {
+			var err error
+			cfg, err = swarm.LoadFileConfig(path)
+			if err != nil {
+				return err
+			}
+		}
+		if cmd.Flags().Changed("addr") {
+			cfg.API, _ = cmd.Flags().GetString("addr")
+		}
+		if cmd.Flags().Changed("grpc-addr") {
+			cfg.GRPC, _ = cmd.Flags().GetString("grpc-addr")
+		}
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			cfg.DryRun = true
+		}
+
+		coordinatorConfig := cfg.CoordinatorConfig()
+		sealer, err := cfg.Sealer()
+		if err != nil {
+			return err
+		}
+		coordinatorConfig.Sealer = sealer
+		if record, _ := cmd.Flags().GetString("record"); record != "" {
+			f, err := os.OpenFile(record, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return fmt.Errorf("failed to open recording: %w", err)
+			}
+			defer f.Close()
+			coordinatorConfig.Recorder = swarm.NewSealedSimRecorder(f, sealer)
+		}
+
+		coordinator, err := swarm.NewCoordinator(coordinatorConfig)
+		if err != nil {
+			return err
+		}
+
+		listener, err := net.Listen("tcp", cfg.API)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", cfg.API, err)
+		}
+		if err := coordinator.Start(); err != nil {
+			listener.Close()
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if watch, _ := cmd.Flags().GetBool("watch"); watch && path != "" {
+			reloader, err := swarm.NewConfigWatcher(coordinator, path, cfg)
+			if err != nil {
+				listener.Close()
+				_ = coordinator.Stop()
+				return err
+			}
+			if err := reloader.Start(); err != nil {
+				listener.Close()
+				_ = coordinator.Stop()
+				return err
+			}
+			defer reloader.Stop()
+			go reportReloads(cmd, reloader)
+		}
+
+		var grpcServer *grpc.Server
+		var grpcListener net.Listener
+		if cfg.GRPC != "" {
+			grpcListener, err = net.Listen("tcp", cfg.GRPC)
+			if err != nil {
+				listener.Close()
+				_ = coordinator.Stop()
+				return fmt.Errorf("failed to listen on %s: %w", cfg.GRPC, err)
+			}
+			grpcServer = api.NewGRPCServer(coordinator)
+		}
+
+		server := &http.Server{
+			Handler:           api.NewServer(coordinator, stop),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		serveErr := make(chan error, 2)
+		go func() {
+			serveErr <- server.Serve(listener)
+		}()
+		if grpcServer != nil {
+			go func() {
+				serveErr <- grpcServer.Serve(grpcListener)
+			}()
+		}
+
+		name := cfg.Name
+		if name == "" {
+			name = "swarm"
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s listening on %s\n", name, listener.Addr())
+		if grpcListener != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s serving gRPC on %s\n", name, grpcListener.Addr())
+		}
+
+		select {
+		case <-ctx.Done():
+		case err := <-serveErr:
+			if !errors.Is(err, http.ErrServerClosed) {
+				if grpcServer != nil {
+					grpcServer.Stop()
+				}
+				_ = server.Close()
+				_ = coordinator.Stop()
+				return fmt.Errorf("swarm API failed: %w", err)
+			}
+		}
+
+		fmt.Fprintln(cmd.OutOrStdout(), "Stopping swarm")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if grpcServer != nil {
+			// Streams run until the client leaves, so they are cut
+			grpcServer.Stop()
+		}
+		_ = server.Shutdown(shutdownCtx)
+		return coordinator.Stop()
+	},
+}
+
+// reportReloads prints the outcome of config reloads, triggered by changes
+// to the file or by SIGHUP
+func reportReloads(cmd *cobra.Command, reloader *swarm.ConfigWatcher) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case report, ok := <-reloader.Reports():
+			if !ok {
+				return
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), report)
+		case <-hup:
+			fmt.Fprintln(cmd.OutOrStdout(), reloader.Reload())
+		}
+	}
+}
+
+var swarmStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of a running swarm",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		status, err := swarmClient(cmd).Status(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if asJSON(cmd) {
+			return printJSON(cmd, status)
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Running:         %t\n", status.Running)
+		fmt.Fprintf(out, "Health:          %s (%.2f)\n", status.SystemHealth.OverallStatus, status.SystemHealth.OverallScore)
+		fmt.Fprintf(out, "Agents:          %d\n", len(status.AgentHealth))
+		fmt.Fprintf(out, "Queued tasks:    %d\n", status.QueuedTasks)
+		fmt.Fprintf(out, "Running tasks:   %d\n", status.RunningTasks)
+		fmt.Fprintf(out, "Finished tasks:  %d completed, %d failed, %d cancelled\n",
+			status.FinishedTasks[swarm.TaskStateCompleted], status.FinishedTasks[swarm.TaskStateFailed], status.FinishedTasks[swarm.TaskStateCancelled])
+		fmt.Fprintf(out, "Active votes:    %d\n", status.ActiveSessions)
+		fmt.Fprintf(out, "Alerts:          %d\n", status.Alerts)
+		fmt.Fprintf(out, "Memories:        %d\n", status.MemoryStats.TotalMemories)
+		if stats := status.MemoryStats; stats.MergedMemories > 0 {
+			fmt.Fprintf(out, "Compacted:       %d repeats into %d memories\n", stats.MergedMemories, stats.CompactedMemories)
+		}
+		if writes := status.MonitorWrites; writes.Queued > 0 || writes.Dropped > 0 {
+			fmt.Fprintf(out, "Monitor writes:  %d queued, %d dropped\n", writes.Queued, writes.Dropped)
+		}
+		fmt.Fprintf(out, "Locks:           %d\n", len(status.Locks))
+
+		if len(status.AgentHealth) > 0 {
+			fmt.Fprintln(out)
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "AGENT\tTYPE\tSTATUS\tHEALTH\tHEARTBEAT")
+			for _, h := range status.AgentHealth {
+				agentStatus := string(h.Status)
+				if h.Unreachable {
+					agentStatus += " (unreachable)"
+				}
+				heartbeat := "-"
+				if !h.LastHeartbeat.IsZero() {
+					heartbeat = time.Since(h.LastHeartbeat).Round(time.Second).String() + " ago"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%.2f\t%s\n", h.ID, h.Type, agentStatus, h.HealthScore, heartbeat)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		if len(status.WarmPools) > 0 {
+			fmt.Fprintln(out)
+			types := make([]string, 0, len(status.WarmPools))
+			for typ := range status.WarmPools {
+				types = append(types, string(typ))
+			}
+			sort.Strings(types)
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "WARM POOL\tAGENTS\tIDLE\tWARMUPS\tFAILED\tEVICTIONS\tREFILLS")
+			for _, typ := range types {
+				p := status.WarmPools[agent.AgentType(typ)]
+				fmt.Fprintf(w, "%s\t%d/%d\t%d\t%d\t%d\t%d\t%d\n", typ, p.Agents, p.Size, p.Idle,
+					p.Warmups, p.WarmFailures, p.Evictions, p.Refills)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		if len(status.Shadows) > 0 {
+			fmt.Fprintln(out)
+			ids := make([]string, 0, len(status.Shadows))
+			for id := range status.Shadows {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SHADOW	TASKS	SUCCEEDED	SAME OUTCOME	SAME OUTPUT	AVG TIME	DRY RUNS")
+			for _, id := range ids {
+				s := status.Shadows[id]
+				avg := s.Duration / time.Duration(max(s.Tasks, 1))
+				fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%s\t%d\n", id, s.Tasks, s.Succeeded, s.SameOutcome, s.SameOutput,
+					avg.Round(time.Millisecond), s.DryRuns)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+		if len(status.Locks) > 0 {
+			fmt.Fprintln(out)
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "RESOURCE\tTASK\tAGENT\tSINCE\tWAITING")
+			for _, l := range status.Locks {
+				waiting := make([]string, 0, len(l.Waiting))
+				for _, o := range l.Waiting {
+					waiting = append(waiting, o.TaskID)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", l.Resource, l.Owner.TaskID, l.Owner.AgentID,
+					l.AcquiredAt.Format(time.DateTime), strings.Join(waiting, ", "))
+			}
+			return w.Flush()
+		}
+		return nil
+	},
+}
+
+var swarmSubmitCmd = &cobra.Command{
+	Use:   "submit",
+	Short: "Submit a task to a running swarm",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		taskType, _ := cmd.Flags().GetString("type")
+		description, _ := cmd.Flags().GetString("description")
+		priority, _ := cmd.Flags().GetInt("priority")
+		maxRetries, _ := cmd.Flags().GetInt("max-retries")
+		inputs, _ := cmd.Flags().GetStringToString("input")
+		idempotencyKey, _ := cmd.Flags().GetString("idempotency-key")
+
+		req := api.SubmitRequest{
+			Type:           taskType,
+			Description:    description,
+			Priority:       priority,
+			MaxRetries:     maxRetries,
+			IdempotencyKey: idempotencyKey,
+		}
+		if len(inputs) > 0 {
+			req.Input = make(map[string]interface{}, len(inputs))
+			for k, v := range inputs {
+				req.Input[k] = v
+			}
+		}
+
+		id, err := swarmClient(cmd).Submit(cmd.Context(), req)
+		if err != nil {
+			return err
+		}
+		if asJSON(cmd) {
+			return printJSON(cmd, api.SubmitResponse{ID: id})
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), id)
+		return nil
+	},
+}
+
+var swarmTasksCmd = &cobra.Command{
+	Use:   "tasks",
+	Short: "List the tasks of a running swarm",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, _ := cmd.Flags().GetString("state")
+		tasks, err := swarmClient(cmd).Tasks(cmd.Context(), swarm.TaskState(state))
+		if err != nil {
+			return err
+		}
+		if asJSON(cmd) {
+			return printJSON(cmd, tasks)
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTYPE\tPRIORITY\tSTATE\tAGENT\tSUBMITTED\tDESCRIPTION")
+		for _, t := range tasks {
+			state := string(t.State)
+			if t.StuckSince != nil {
+				state += " (stuck)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+				t.ID, t.Type, t.Priority, state, t.AgentID,
+				t.SubmittedAt.Format(time.DateTime), t.Description)
+		}
+		return w.Flush()
+	},
+}
+
+var swarmCompareCmd = &cobra.Command{
+	Use:   "compare [primary shadow]",
+	Short: "Compare shadow agents to the agents they shadowed",
+	Long: `Compare reports how shadow agents did against the agents that ran the
+tasks they got copies of: success rate, latency, tokens and cost, and how
+often their outcomes, outputs and proposed edits agreed. Without arguments
+every pair seen within --since is compared. The report is markdown, written
+to --output if set.`,
+	Args: cobra.MatchAll(cobra.MaximumNArgs(2), func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			return fmt.Errorf("compare needs both a primary and a shadow agent")
+		}
+		return nil
+	}),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, _ := cmd.Flags().GetDuration("since")
+		output, _ := cmd.Flags().GetString("output")
+		var primary, shadow string
+		if len(args) == 2 {
+			primary, shadow = args[0], args[1]
+		}
+		comparisons, err := swarmClient(cmd).Comparisons(cmd.Context(), primary, shadow, since)
+		if err != nil {
+			return err
+		}
+		if asJSON(cmd) {
+			return printJSON(cmd, comparisons)
+		}
+
+		reports := make([]string, len(comparisons))
+		for i, comparison := range comparisons {
+			reports[i] = comparison.Markdown()
+		}
+		report := strings.Join(reports, "\n")
+		if len(comparisons) == 0 {
+			report = "No shadow agent finished a task within " + since.String() + "\n"
+		}
+		if output == "" {
+			_, err := fmt.Fprint(cmd.OutOrStdout(), report)
+			return err
+		}
+		return os.WriteFile(output, []byte(report), 0o644)
+	},
+}
+
+var swarmTopologyCmd = &cobra.Command{
+	Use:   "topology",
+	Short: "Export the topology of the swarm as a Graphviz graph",
+	Long: `Topology exports the agents of the swarm as a Graphviz graph: the
+messages they sent each other with their rates, their subscriptions to the
+broker, the tasks they spawned for each other and the shadows they copied
+tasks to. Render it with e.g. "opencode swarm topology | dot -Tsvg".`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		topology, err := swarmClient(cmd).Topology(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if asJSON(cmd) {
+			return printJSON(cmd, topology)
+		}
+		if output == "" {
+			_, err := fmt.Fprint(cmd.OutOrStdout(), topology.DOT())
+			return err
+		}
+		return os.WriteFile(output, []byte(topology.DOT()), 0o644)
+	},
+}
+
+var swarmMemoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "Inspect the memory of a running swarm",
+}
+
+var swarmMemorySearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search the memory of a running swarm",
+	Long: `Search the memory of a running swarm. A query combines terms with the text
+to search for, which is quoted where it looks like a term:
+
+  type:<working|episodic|semantic|procedural>
+  ns:<namespace>            global, or session/<id>
+  tag:<tag>                 repeatable, any of the tags matches
+  priority:<low|normal|high|critical>
+                            the lowest priority listed
+  after:<time>, before:<time>
+                            a date (2024-01-01), an RFC 3339 time or a
+                            duration ago (24h)
+  limit:<n>
+
+For example: opencode swarm memory search 'type:episodic tag:error after:24h "timeout"'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		memories, err := swarmClient(cmd).Memories(cmd.Context(), strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		if asJSON(cmd) {
+			return printJSON(cmd, memories)
+		}
+		if len(memories) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No memories match")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTYPE\tPINNED\tTAGS\tCREATED\tCONTENT")
+		for _, m := range memories {
+			content := "encrypted"
+			if !m.Encrypted {
+				content = memoryPreview(m.Content)
+			}
+			pinned := ""
+			if m.Pinned {
+				pinned = "yes"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", m.ID, m.Type, pinned, strings.Join(m.Tags, ","),
+				m.CreatedAt.Format(time.DateTime), content)
+		}
+		return w.Flush()
+	},
+}
+
+var swarmMemoryPinCmd = &cobra.Command{
+	Use:   "pin <id>",
+	Short: "Keep a memory from being pruned or consolidated",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return swarmClient(cmd).PinMemory(cmd.Context(), args[0])
+	},
+}
+
+var swarmMemoryUnpinCmd = &cobra.Command{
+	Use:   "unpin <id>",
+	Short: "Let a pinned memory be pruned and consolidated again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return swarmClient(cmd).UnpinMemory(cmd.Context(), args[0])
+	},
+}
+
+var swarmMemoryExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a consistent copy of the memory store as CSV or Parquet",
+	Long: `Export takes a read-only replica of the memory store, so the export
+neither blocks the running swarm nor mixes memories from before and after a
+write. The format defaults to the extension of --output, else CSV. The
+content of encrypted memories is left out.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		format, _ := cmd.Flags().GetString("format")
+		if format == "" {
+			format = strings.TrimPrefix(filepath.Ext(output), ".")
+			if !slices.Contains(memory.ExportFormats, memory.ExportFormat(format)) {
+				format = string(memory.ExportCSV)
+			}
+		}
+
+		if output == "" {
+			return swarmClient(cmd).ExportMemories(cmd.Context(), memory.ExportFormat(format), cmd.OutOrStdout())
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		err = swarmClient(cmd).ExportMemories(cmd.Context(), memory.ExportFormat(format), f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(output)
+		}
+		return err
+	},
+}
+
+// memoryPreview is the content of a memory on one line, cut at 80
+// characters
+func memoryPreview(content interface{}) string {
+	text, ok := content.(string)
+	if !ok {
+		data, _ := json.Marshal(content)
+		text = string(data)
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > 80 {
+		text = string(runes[:79]) + "…"
+	}
+	return text
+}
+
+var swarmSimulateCmd = &cobra.Command{
+	Use:   "simulate <events.jsonl>",
+	Short: "Replay a recorded event stream against a swarm configuration",
+	Long: `Replay logs, tasks and votes recorded with "opencode swarm start --record"
+or written by hand against a swarm configuration, and report what the swarm
+would have done. Time follows the recorded events and agents answer with
+the recorded task results, so the same events always give the same report.
+Use it to try rule sets and agent configurations before enabling them.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("file")
+		var cfg swarm.FileConfig
+		if path != "" {
+			var err error
+			cfg, err = swarm.LoadFileConfig(path)
+			if err != nil {
+				return err
+			}
+		}
+
+		sealer, err := cfg.Sealer()
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		events, err := swarm.ReadSealedSimEvents(f, sealer)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("invalid event stream %s: %w", args[0], err)
+		}
+
+		report, err := swarm.Simulate(cmd.Context(), cfg.CoordinatorConfig(), events)
+		if err != nil {
+			return err
+		}
+		if asJSON(cmd) {
+			return printJSON(cmd, report)
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Replayed %d events from %s to %s\n\n", report.Events,
+			report.Start.Format(time.DateTime), report.End.Format(time.DateTime))
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tEVENT\tSUBJECT\tSUMMARY")
+		for _, e := range report.Timeline {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Timestamp.Format(time.DateTime), e.Type, e.Subject, e.Summary)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(out)
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "RULE\tEVALUATED\tFIRED\tFAILED")
+		for _, r := range report.Rules {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", r.RuleID, r.Evaluations, r.Fired, r.Failures)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		for _, e := range report.Errors {
+			fmt.Fprintf(out, "rejected: %s\n", e)
+		}
+		return nil
+	},
+}
+
+var swarmStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop a running swarm",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return swarmClient(cmd).Stop(cmd.Context())
+	},
+}
+
+var swarmConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Work with swarm configuration files",
+}
+
+var swarmConfigValidateCmd = &cobra.Command{
+	Use:   "validate <file>",
+	Short: "Check a swarm configuration file for errors",
+	Long: `Check a swarm configuration file in JSON, YAML or TOML format. Environment
+variables are expanded and every problem is reported, including invalid
+rules in the rules directory.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := swarm.LoadFileConfig(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s is valid: %d agents, %d providers\n", args[0], len(cfg.Agents), len(cfg.Providers))
+		return nil
+	},
+}
+
+var swarmSecretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Keep the API keys of swarm providers in the OS keychain",
+	Long: `Keep the API keys of model providers in the OS keychain, or without one in
+a file encrypted with the master key of "opencode swarm keygen". Agents
+whose provider has no apiKey configured use the key stored under the
+provider's name, and the provider's environment variable, such as
+OPENROUTER_API_KEY, only when none is stored. GitHub agents use the token
+stored as github.`,
+}
+
+var swarmSecretsSetCmd = &cobra.Command{
+	Use:   "set <provider>",
+	Short: "Store the API key of a provider, read from stdin",
+	Example: `  opencode swarm secrets set openrouter
+  pass show openrouter | opencode swarm secrets set openrouter
+  gh auth token | opencode swarm secrets set github`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if f, ok := cmd.InOrStdin().(*os.File); ok && f == os.Stdin {
+			if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "API key for %s: ", args[0])
+			}
+		}
+		line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		key := strings.TrimSpace(line)
+		if key == "" {
+			return errors.New("no API key given")
+		}
+		if err := vault.NewSecrets(vault.DefaultSecretsFile()).Set(args[0], key); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Stored the API key of %s\n", args[0])
+		return nil
+	},
+}
+
+var swarmSecretsDeleteCmd = &cobra.Command{
+	Use:   "delete <provider>",
+	Short: "Remove the stored API key of a provider",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := vault.NewSecrets(vault.DefaultSecretsFile()).Delete(args[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed the API key of %s\n", args[0])
+		return nil
+	},
+}
+
+var swarmWhoChangedCmd = &cobra.Command{
+	Use:   "who-changed <path>",
+	Short: "List the changes swarm agents made to a file",
+	Long: `List the versions of a file that swarm agents wrote, newest first, with the
+agent, task and vote behind each. Edits proposed by swarm agents and applied
+in opencode sessions are recorded in the file history of the project's
+database.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current working directory: %v", err)
+		}
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			return err
+		}
+		if _, err := config.Load(cwd, false); err != nil {
+			return err
+		}
+		conn, err := db.Connect()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		files, err := history.NewService(db.New(conn), conn).WhoChanged(cmd.Context(), path)
+		if err != nil {
+			return err
+		}
+		type change struct {
+			Version   string    `json:"version"`
+			SessionID string    `json:"sessionId"`
+			AgentID   string    `json:"agentId"`
+			TaskID    string    `json:"taskId"`
+			VoteID    string    `json:"voteId,omitempty"`
+			ChangedAt time.Time `json:"changedAt"`
+		}
+		changes := make([]change, 0, len(files))
+		for _, f := range files {
+			changes = append(changes, change{
+				Version:   f.Version,
+				SessionID: f.SessionID,
+				AgentID:   f.Provenance.AgentID,
+				TaskID:    f.Provenance.TaskID,
+				VoteID:    f.Provenance.VoteID,
+				ChangedAt: time.Unix(f.CreatedAt, 0),
+			})
+		}
+		if asJSON(cmd) {
+			return printJSON(cmd, changes)
+		}
+		if len(changes) == 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "No swarm agent changed %s\n", path)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tCHANGED\tAGENT\tTASK\tVOTE\tSESSION")
+		for _, c := range changes {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", c.Version, c.ChangedAt.Format(time.DateTime),
+				c.AgentID, c.TaskID, c.VoteID, c.SessionID)
+		}
+		return w.Flush()
+	},
+}
+
+var swarmKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Create a master key to encrypt swarm data at rest",
+	Long: `Create a random master key for swarms configured with
+"encryption: {atRest: true}". The key is printed for the OPENCODE_SWARM_KEY
+environment variable, or the variable named by encryption.keyEnv, unless
+--keychain stores it in the OS keychain, where swarms look for it when the
+variable is not set. Keep the key: what the swarm encrypted with it cannot
+be read without it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := vault.GenerateKey()
+		if keychain, _ := cmd.Flags().GetBool("keychain"); !keychain {
+			fmt.Fprintln(cmd.OutOrStdout(), vault.EncodeKey(key))
+			return nil
+		}
+		replace, _ := cmd.Flags().GetBool("replace")
+		if err := vault.StoreKeychainKey(key, replace); err != nil {
+			if errors.Is(err, vault.ErrKeyExists) {
+				return fmt.Errorf("%w, use --replace to replace it", err)
+			}
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "Stored a new master key in the OS keychain")
+		return nil
+	},
+}
+
+func swarmClient(cmd *cobra.Command) *api.Client {
+	addr, _ := cmd.Flags().GetString("addr")
+	return api.NewClient(addr)
+}
+
+func asJSON(cmd *cobra.Command) bool {
+	v, _ := cmd.Flags().GetBool("json")
+	return v
+}
+
+func printJSON(cmd *cobra.Command, v interface{}) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func init() {
+	swarmCmd.PersistentFlags().String("addr", swarm.DefaultAPIAddress, "Address of the swarm API")
+	swarmCmd.PersistentFlags().Bool("json", false, "Print JSON instead of text")
+
+	swarmStartCmd.Flags().StringP("file", "f", "", "Swarm configuration file (.json, .yaml or .toml)")
+	swarmStartCmd.Flags().Bool("watch", true, "Apply changes to the configuration file without restarting")
+	swarmStartCmd.Flags().String("grpc-addr", "", "Address to serve the gRPC streaming API on, none by default")
+	swarmStartCmd.Flags().Bool("dry-run", false, "Report the commands of tasks and simulate their results instead of running them")
+	swarmStartCmd.Flags().String("record", "", "Append the logs, tasks and results the swarm sees to this file for \"swarm simulate\"")
+
+	swarmSimulateCmd.Flags().StringP("file", "f", "", "Swarm configuration file (.json, .yaml or .toml)")
+
+	swarmSubmitCmd.Flags().StringP("type", "t", "", "Task type")
+	swarmSubmitCmd.Flags().StringP("description", "m", "", "Task description")
+	swarmSubmitCmd.Flags().IntP("priority", "p", 0, "Task priority, higher runs first")
+	swarmSubmitCmd.Flags().Int("max-retries", 0, "Maximum number of retries")
+	swarmSubmitCmd.Flags().StringToStringP("input", "i", nil, "Task input as key=value, repeatable")
+	swarmSubmitCmd.Flags().String("idempotency-key", "", "Return the task submitted with this key instead of queueing another")
+	_ = swarmSubmitCmd.MarkFlagRequired("type")
+
+	swarmTasksCmd.Flags().String("state", "", "Only list tasks in this state (queued, running, completed, failed, cancelled)")
+
+	swarmCompareCmd.Flags().Duration("since", api.DefaultComparisonWindow, "Compare the tasks finished within this long")
+	swarmCompareCmd.Flags().StringP("output", "o", "", "Markdown file to write, standard output if empty")
+
+	swarmTopologyCmd.Flags().StringP("output", "o", "", "DOT file to write, standard output if empty")
+
+	swarmKeygenCmd.Flags().Bool("keychain", false, "Store the key in the OS keychain instead of printing it")
+	swarmKeygenCmd.Flags().Bool("replace", false, "Replace the key in the keychain; data encrypted with it can no longer be read")
+
+	swarmMemoryExportCmd.Flags().StringP("output", "o", "", "File to write, standard output if empty")
+	swarmMemoryExportCmd.Flags().String("format", "", "csv or parquet")
+
+	swarmConfigCmd.AddCommand(swarmConfigValidateCmd)
+	swarmSecretsCmd.AddCommand(swarmSecretsSetCmd, swarmSecretsDeleteCmd)
+	swarmMemoryCmd.AddCommand(swarmMemorySearchCmd, swarmMemoryPinCmd, swarmMemoryUnpinCmd, swarmMemoryExportCmd)
+	swarmCmd.AddCommand(swarmStartCmd, swarmStatusCmd, swarmSubmitCmd, swarmTasksCmd, swarmCompareCmd, swarmTopologyCmd, swarmStopCmd, swarmConfigCmd, swarmSimulateCmd, swarmWhoChangedCmd, swarmKeygenCmd, swarmSecretsCmd, swarmMemoryCmd)
+	rootCmd.AddCommand(swarmCmd)
+}
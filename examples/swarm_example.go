@@ -97,6 +97,8 @@ func basicExample() {
 func memoryExample() {
 	fmt.Println("=== Example 2: Memory System ===\n")
 
+	ctx := context.Background()
+
 	// Create memory store
 	memStore := memory.NewHierarchicalMemoryStore(memory.HierarchicalMemoryConfig{
 		MaxMemories:           1000,
@@ -113,7 +115,7 @@ func memoryExample() {
 		Tags:     []string{"task", "active", "code-analysis"},
 		Priority: memory.PriorityHigh,
 	}
-	memStore.Store(workingMem)
+	memStore.Store(ctx, workingMem)
 	fmt.Println("✓ Stored working memory")
 
 	// 2. Episodic memory (event)
@@ -132,7 +134,7 @@ func memoryExample() {
 			"line": 42,
 		},
 	}
-	memStore.Store(episodicMem)
+	memStore.Store(ctx, episodicMem)
 	fmt.Println("✓ Stored episodic memory (error event)")
 
 	// 3. Semantic memory (knowledge)
@@ -142,7 +144,7 @@ func memoryExample() {
 		Tags:     []string{"knowledge", "go", "syntax"},
 		Priority: memory.PriorityNormal,
 	}
-	memStore.Store(semanticMem)
+	memStore.Store(ctx, semanticMem)
 	fmt.Println("✓ Stored semantic memory (knowledge)")
 
 	// 4. Procedural memory (how-to)
@@ -160,7 +162,7 @@ func memoryExample() {
 		Tags:     []string{"procedure", "debugging", "syntax"},
 		Priority: memory.PriorityNormal,
 	}
-	memStore.Store(proceduralMem)
+	memStore.Store(ctx, proceduralMem)
 	fmt.Println("✓ Stored procedural memory (debugging steps)")
 
 	// Query memories
@@ -170,7 +172,7 @@ func memoryExample() {
 		Tags:  []string{"error"},
 		Limit: 10,
 	}
-	results, _ := memStore.Query(query)
+	results, _ := memStore.Query(ctx, query)
 	fmt.Printf("✓ Found %d memories tagged with 'error'\n", len(results))
 
 	// Get statistics
@@ -399,7 +401,7 @@ func ruleEngineExample() {
 		Tags: []string{"error", "recovery"},
 	}
 
-	if err := ruleEngine.AddRule(errorRule); err != nil {
+	if err := ruleEngine.AddRule(context.Background(), errorRule); err != nil {
 		log.Printf("Failed to add rule: %v", err)
 		return
 	}
@@ -425,7 +427,7 @@ func ruleEngineExample() {
 		Tags: []string{"performance", "monitoring"},
 	}
 
-	if err := ruleEngine.AddRule(perfRule); err != nil {
+	if err := ruleEngine.AddRule(context.Background(), perfRule); err != nil {
 		log.Printf("Failed to add rule: %v", err)
 		return
 	}
@@ -16,6 +16,7 @@ import (
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/permission"
 	"github.com/opencode-ai/opencode/internal/session"
+	"github.com/opencode-ai/opencode/internal/swarm"
 )
 
 type App struct {
@@ -26,6 +27,12 @@ type App struct {
 
 	CoderAgent agent.Service
 
+	// Swarm runs when one is configured, the coder agent can delegate to it
+	Swarm *swarm.Swarm
+	// Knowledge adds swarm memories to the prompts of the coder agent, nil
+	// without a swarm
+	Knowledge *agent.KnowledgeInjector
+
 	LSPClients map[string]*lsp.Client
 
 	clientsMutex sync.RWMutex
@@ -49,6 +56,20 @@ func New(ctx context.Context, conn *sql.DB) (*App, error) {
 		LSPClients:  make(map[string]*lsp.Client),
 	}
 
+	if path := config.Get().Swarm.Config; path != "" {
+		swarmConfig, err := swarm.LoadFileConfig(path)
+		if err != nil {
+			logging.Error("Failed to load swarm config", "error", err)
+			return nil, err
+		}
+		app.Swarm, err = swarm.Open(swarmConfig)
+		if err != nil {
+			logging.Error("Failed to start swarm", "error", err)
+			return nil, err
+		}
+		app.Swarm.UseFileHistory(swarmHistory{files: files})
+	}
+
 	// Initialize LSP clients in the background
 	go app.initLSPClients(ctx)
 
@@ -63,12 +84,18 @@ func New(ctx context.Context, conn *sql.DB) (*App, error) {
 			app.Messages,
 			app.History,
 			app.LSPClients,
+			app.Swarm,
 		),
 	)
 	if err != nil {
 		logging.Error("Failed to create coder agent", err)
+		app.closeSwarm()
 		return nil, err
 	}
+	if app.Swarm != nil {
+		app.Knowledge = agent.NewKnowledgeInjector(app.Swarm, config.Get().Swarm.Knowledge)
+		app.CoderAgent.SetRetrievalHook(app.Knowledge.Retrieve)
+	}
 
 	return app, nil
 }
@@ -96,4 +123,16 @@ func (app *App) Shutdown() {
 		}
 		cancel()
 	}
+
+	app.closeSwarm()
+}
+
+// closeSwarm stops the swarm, if one runs
+func (app *App) closeSwarm() {
+	if app.Swarm == nil {
+		return
+	}
+	if err := app.Swarm.Close(); err != nil {
+		logging.Error("Failed to stop swarm", "error", err)
+	}
 }
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/swarm"
+)
+
+// swarmHistory lets the swarm roll tasks back with the file history of the
+// sessions
+type swarmHistory struct {
+	files history.Service
+}
+
+func (h swarmHistory) TaskVersions(ctx context.Context, taskID string) ([]swarm.FileVersion, error) {
+	files, err := h.files.ListByTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]swarm.FileVersion, len(files))
+	for i, file := range files {
+		versions[i] = fileVersion(file)
+	}
+	return versions, nil
+}
+
+func (h swarmHistory) PreviousVersion(ctx context.Context, path, version string) (swarm.FileVersion, bool, error) {
+	file, err := h.files.PreviousVersion(ctx, path, version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return swarm.FileVersion{}, false, nil
+	}
+	if err != nil {
+		return swarm.FileVersion{}, false, err
+	}
+	return fileVersion(file), true, nil
+}
+
+func (h swarmHistory) CreateVersion(ctx context.Context, sessionID, path, content string) error {
+	_, err := h.files.CreateVersion(ctx, sessionID, path, content)
+	return err
+}
+
+func fileVersion(file history.File) swarm.FileVersion {
+	version := swarm.FileVersion{
+		ID:        file.ID,
+		SessionID: file.SessionID,
+		Path:      file.Path,
+		Content:   file.Content,
+		Version:   file.Version,
+	}
+	if file.Provenance != nil {
+		version.AgentID = file.Provenance.AgentID
+		version.TaskID = file.Provenance.TaskID
+	}
+	return version
+}
@@ -2,12 +2,15 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/lsp"
+	"github.com/opencode-ai/opencode/internal/lsp/protocol"
 	"github.com/opencode-ai/opencode/internal/lsp/watcher"
+	"github.com/opencode-ai/opencode/internal/swarm"
 )
 
 func (app *App) initLSPClients(ctx context.Context) {
@@ -33,6 +36,10 @@ func (app *App) createAndStartLSPClient(ctx context.Context, name string, comman
 		return
 	}
 
+	if app.Swarm != nil {
+		lspClient.OnDiagnostics(app.reportDiagnostics(ctx, name))
+	}
+
 	// Create a longer timeout for initialization (some servers take time to start)
 	initCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
@@ -124,3 +131,47 @@ func (app *App) restartLSPClient(ctx context.Context, name string) {
 	app.createAndStartLSPClient(ctx, name, clientConfig.Command, clientConfig.Args...)
 	logging.Info("Successfully restarted LSP client", "client", name)
 }
+
+// reportDiagnostics returns a listener passing the diagnostics of an LSP
+// client to the swarm, so its rules can react to new errors
+func (app *App) reportDiagnostics(ctx context.Context, name string) lsp.DiagnosticListener {
+	return func(uri protocol.DocumentUri, diagnostics []protocol.Diagnostic) {
+		report := swarm.FileDiagnostics{
+			Path:        uri.Path(),
+			Source:      name,
+			Diagnostics: make([]swarm.Diagnostic, 0, len(diagnostics)),
+		}
+		for _, diagnostic := range diagnostics {
+			report.Diagnostics = append(report.Diagnostics, swarm.Diagnostic{
+				Severity: diagnosticSeverity(diagnostic.Severity),
+				Line:     int(diagnostic.Range.Start.Line) + 1,
+				Message:  diagnostic.Message,
+				Source:   diagnostic.Source,
+				Code:     diagnosticCode(diagnostic.Code),
+			})
+		}
+		if err := app.Swarm.ReportDiagnostics(ctx, report); err != nil && ctx.Err() == nil {
+			logging.Debug("Failed to report diagnostics to the swarm", "client", name, "error", err)
+		}
+	}
+}
+
+func diagnosticSeverity(severity protocol.DiagnosticSeverity) string {
+	switch severity {
+	case protocol.SeverityError:
+		return swarm.SeverityError
+	case protocol.SeverityWarning:
+		return swarm.SeverityWarning
+	case protocol.SeverityHint:
+		return swarm.SeverityHint
+	}
+	return swarm.SeverityInformation
+}
+
+// diagnosticCode formats a code, which servers send as a string or number
+func diagnosticCode(code interface{}) string {
+	if code == nil {
+		return ""
+	}
+	return fmt.Sprint(code)
+}
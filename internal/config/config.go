@@ -65,6 +65,40 @@ type LSPConfig struct {
 	Options  any      `json:"options"`
 }
 
+// TUIConfig defines configuration for the terminal user interface.
+type TUIConfig struct {
+	Theme       string            `json:"theme,omitempty"`
+	CustomTheme map[string]string `json:"customTheme,omitempty"`
+	// DisableMouse turns off mouse reporting so the terminal keeps its
+	// native text selection
+	DisableMouse bool `json:"disableMouse,omitempty"`
+	// Keybindings overrides the keys of TUI actions, e.g.
+	// {"app.logs": ["ctrl+y"]}
+	Keybindings map[string][]string `json:"keybindings,omitempty"`
+}
+
+// SwarmConfig defines the agent swarm the chat agent can delegate to.
+type SwarmConfig struct {
+	// Config is the swarm configuration file, in the format of
+	// `opencode swarm start -f`. No swarm runs when it is empty.
+	Config    string          `json:"config,omitempty"`
+	Knowledge KnowledgeConfig `json:"knowledge,omitempty"`
+}
+
+// KnowledgeConfig defines how swarm memories are added to chat prompts.
+type KnowledgeConfig struct {
+	// Disabled leaves memories out of new sessions, they can still be
+	// turned on per session
+	Disabled bool `json:"disabled,omitempty"`
+	// MaxTokens bounds the size of the added context, 1000 if zero
+	MaxTokens int `json:"maxTokens,omitempty"`
+	// MaxMemories bounds how many memories are added, 5 if zero
+	MaxMemories int `json:"maxMemories,omitempty"`
+	// MinScore leaves out memories matching the prompt less well, between
+	// 0 and 1
+	MinScore float64 `json:"minScore,omitempty"`
+}
+
 // Config is the main configuration structure for the application.
 type Config struct {
 	Data         Data                              `json:"data"`
@@ -76,6 +110,8 @@ type Config struct {
 	Debug        bool                              `json:"debug,omitempty"`
 	DebugLSP     bool                              `json:"debugLSP,omitempty"`
 	ContextPaths []string                          `json:"contextPaths,omitempty"`
+	TUI          TUIConfig                         `json:"tui"`
+	Swarm        SwarmConfig                       `json:"swarm,omitempty"`
 }
 
 // Application constants
@@ -201,6 +237,7 @@ func configureViper() {
 func setDefaults(debug bool) {
 	viper.SetDefault("data.directory", defaultDataDirectory)
 	viper.SetDefault("contextPaths", defaultContextPaths)
+	viper.SetDefault("tui.theme", "dark")
 
 	if debug {
 		viper.SetDefault("debug", true)
@@ -611,6 +648,12 @@ func Get() *Config {
 	return cfg
 }
 
+// Set replaces the current configuration, nil to load another one. Tests
+// use it to restore the configuration they found.
+func Set(c *Config) {
+	cfg = c
+}
+
 // WorkingDirectory returns the current working directory from the configuration.
 func WorkingDirectory() string {
 	if cfg == nil {
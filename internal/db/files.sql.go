@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const createFile = `-- name: CreateFile :one
@@ -16,20 +17,26 @@ INSERT INTO files (
     path,
     content,
     version,
+    agent_id,
+    task_id,
+    vote_id,
     created_at,
     updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+    ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
 )
-RETURNING id, session_id, path, content, version, created_at, updated_at
+RETURNING id, session_id, path, content, version, created_at, updated_at, agent_id, task_id, vote_id
 `
 
 type CreateFileParams struct {
-	ID        string `json:"id"`
-	SessionID string `json:"session_id"`
-	Path      string `json:"path"`
-	Content   string `json:"content"`
-	Version   string `json:"version"`
+	ID        string         `json:"id"`
+	SessionID string         `json:"session_id"`
+	Path      string         `json:"path"`
+	Content   string         `json:"content"`
+	Version   string         `json:"version"`
+	AgentID   sql.NullString `json:"agent_id"`
+	TaskID    sql.NullString `json:"task_id"`
+	VoteID    sql.NullString `json:"vote_id"`
 }
 
 func (q *Queries) CreateFile(ctx context.Context, arg CreateFileParams) (File, error) {
@@ -39,6 +46,9 @@ func (q *Queries) CreateFile(ctx context.Context, arg CreateFileParams) (File, e
 		arg.Path,
 		arg.Content,
 		arg.Version,
+		arg.AgentID,
+		arg.TaskID,
+		arg.VoteID,
 	)
 	var i File
 	err := row.Scan(
@@ -49,6 +59,9 @@ func (q *Queries) CreateFile(ctx context.Context, arg CreateFileParams) (File, e
 		&i.Version,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AgentID,
+		&i.TaskID,
+		&i.VoteID,
 	)
 	return i, err
 }
@@ -74,7 +87,7 @@ func (q *Queries) DeleteSessionFiles(ctx context.Context, sessionID string) erro
 }
 
 const getFile = `-- name: GetFile :one
-SELECT id, session_id, path, content, version, created_at, updated_at
+SELECT id, session_id, path, content, version, created_at, updated_at, agent_id, task_id, vote_id
 FROM files
 WHERE id = ? LIMIT 1
 `
@@ -90,12 +103,15 @@ func (q *Queries) GetFile(ctx context.Context, id string) (File, error) {
 		&i.Version,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AgentID,
+		&i.TaskID,
+		&i.VoteID,
 	)
 	return i, err
 }
 
 const getFileByPathAndSession = `-- name: GetFileByPathAndSession :one
-SELECT id, session_id, path, content, version, created_at, updated_at
+SELECT id, session_id, path, content, version, created_at, updated_at, agent_id, task_id, vote_id
 FROM files
 WHERE path = ? AND session_id = ?
 ORDER BY created_at DESC
@@ -118,12 +134,15 @@ func (q *Queries) GetFileByPathAndSession(ctx context.Context, arg GetFileByPath
 		&i.Version,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AgentID,
+		&i.TaskID,
+		&i.VoteID,
 	)
 	return i, err
 }
 
 const listFilesByPath = `-- name: ListFilesByPath :many
-SELECT id, session_id, path, content, version, created_at, updated_at
+SELECT id, session_id, path, content, version, created_at, updated_at, agent_id, task_id, vote_id
 FROM files
 WHERE path = ?
 ORDER BY created_at DESC
@@ -146,6 +165,50 @@ func (q *Queries) ListFilesByPath(ctx context.Context, path string) ([]File, err
 			&i.Version,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.AgentID,
+			&i.TaskID,
+			&i.VoteID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFilesByTask = `-- name: ListFilesByTask :many
+SELECT id, session_id, path, content, version, created_at, updated_at, agent_id, task_id, vote_id
+FROM files
+WHERE task_id = ?
+ORDER BY created_at ASC
+`
+
+func (q *Queries) ListFilesByTask(ctx context.Context, taskID sql.NullString) ([]File, error) {
+	rows, err := q.query(ctx, q.listFilesByTaskStmt, listFilesByTask, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []File{}
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.Path,
+			&i.Content,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AgentID,
+			&i.TaskID,
+			&i.VoteID,
 		); err != nil {
 			return nil, err
 		}
@@ -161,7 +224,7 @@ func (q *Queries) ListFilesByPath(ctx context.Context, path string) ([]File, err
 }
 
 const listFilesBySession = `-- name: ListFilesBySession :many
-SELECT id, session_id, path, content, version, created_at, updated_at
+SELECT id, session_id, path, content, version, created_at, updated_at, agent_id, task_id, vote_id
 FROM files
 WHERE session_id = ?
 ORDER BY created_at ASC
@@ -184,6 +247,9 @@ func (q *Queries) ListFilesBySession(ctx context.Context, sessionID string) ([]F
 			&i.Version,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.AgentID,
+			&i.TaskID,
+			&i.VoteID,
 		); err != nil {
 			return nil, err
 		}
@@ -199,7 +265,7 @@ func (q *Queries) ListFilesBySession(ctx context.Context, sessionID string) ([]F
 }
 
 const listLatestSessionFiles = `-- name: ListLatestSessionFiles :many
-SELECT f.id, f.session_id, f.path, f.content, f.version, f.created_at, f.updated_at
+SELECT f.id, f.session_id, f.path, f.content, f.version, f.created_at, f.updated_at, f.agent_id, f.task_id, f.vote_id
 FROM files f
 INNER JOIN (
     SELECT path, MAX(created_at) as max_created_at
@@ -227,6 +293,9 @@ func (q *Queries) ListLatestSessionFiles(ctx context.Context, sessionID string)
 			&i.Version,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.AgentID,
+			&i.TaskID,
+			&i.VoteID,
 		); err != nil {
 			return nil, err
 		}
@@ -242,7 +311,7 @@ func (q *Queries) ListLatestSessionFiles(ctx context.Context, sessionID string)
 }
 
 const listNewFiles = `-- name: ListNewFiles :many
-SELECT id, session_id, path, content, version, created_at, updated_at
+SELECT id, session_id, path, content, version, created_at, updated_at, agent_id, task_id, vote_id
 FROM files
 WHERE is_new = 1
 ORDER BY created_at DESC
@@ -265,6 +334,9 @@ func (q *Queries) ListNewFiles(ctx context.Context) ([]File, error) {
 			&i.Version,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.AgentID,
+			&i.TaskID,
+			&i.VoteID,
 		); err != nil {
 			return nil, err
 		}
@@ -286,7 +358,7 @@ SET
     version = ?,
     updated_at = strftime('%s', 'now')
 WHERE id = ?
-RETURNING id, session_id, path, content, version, created_at, updated_at
+RETURNING id, session_id, path, content, version, created_at, updated_at, agent_id, task_id, vote_id
 `
 
 type UpdateFileParams struct {
@@ -306,6 +378,9 @@ func (q *Queries) UpdateFile(ctx context.Context, arg UpdateFileParams) (File, e
 		&i.Version,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.AgentID,
+		&i.TaskID,
+		&i.VoteID,
 	)
 	return i, err
 }
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The swarm agent, task and vote that produced a file version
+ALTER TABLE files ADD COLUMN agent_id TEXT;
+ALTER TABLE files ADD COLUMN task_id TEXT;
+ALTER TABLE files ADD COLUMN vote_id TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE files DROP COLUMN vote_id;
+ALTER TABLE files DROP COLUMN task_id;
+ALTER TABLE files DROP COLUMN agent_id;
+-- +goose StatementEnd
@@ -22,6 +22,12 @@ FROM files
 WHERE path = ?
 ORDER BY created_at DESC;
 
+-- name: ListFilesByTask :many
+SELECT *
+FROM files
+WHERE task_id = ?
+ORDER BY created_at ASC;
+
 -- name: CreateFile :one
 INSERT INTO files (
     id,
@@ -29,10 +35,13 @@ INSERT INTO files (
     path,
     content,
     version,
+    agent_id,
+    task_id,
+    vote_id,
     created_at,
     updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
+    ?, ?, ?, ?, ?, ?, ?, ?, strftime('%s', 'now'), strftime('%s', 'now')
 )
 RETURNING *;
 
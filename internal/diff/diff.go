@@ -97,6 +97,10 @@ type StyleConfig struct {
 	HighlightStyle     string
 	RemovedHighlightBg lipgloss.Color
 	AddedHighlightBg   lipgloss.Color
+
+	// Highlighter colors the code of a line over its background, the
+	// built-in highlighter if nil
+	Highlighter func(fileName, line string, bg lipgloss.TerminalColor) string
 }
 
 // StyleOption is a function that modifies a StyleConfig
@@ -197,6 +201,12 @@ func WithHunkLineFg(color lipgloss.Color) StyleOption {
 	return func(s *StyleConfig) { s.HunkLineFg = color }
 }
 
+// WithHighlighter replaces the syntax highlighting of lines, e.g. to follow
+// the colors of a theme
+func WithHighlighter(highlighter func(fileName, line string, bg lipgloss.TerminalColor) string) StyleOption {
+	return func(s *StyleConfig) { s.Highlighter = highlighter }
+}
+
 func WithShowHeader(show bool) StyleOption {
 	return func(s *StyleConfig) { s.ShowHeader = show }
 }
@@ -609,6 +619,14 @@ func highlightLine(fileName string, line string, bg lipgloss.TerminalColor) stri
 	return buf.String()
 }
 
+// highlight colors a line with the configured highlighter
+func (s StyleConfig) highlight(fileName, line string, bg lipgloss.TerminalColor) string {
+	if s.Highlighter != nil {
+		return s.Highlighter(fileName, line, bg)
+	}
+	return highlightLine(fileName, line, bg)
+}
+
 // createStyles generates the lipgloss styles needed for rendering diffs
 func createStyles(config StyleConfig) (removedLineStyle, addedLineStyle, contextLineStyle, lineNumberStyle lipgloss.Style) {
 	removedLineStyle = lipgloss.NewStyle().Background(config.RemovedLineBg)
@@ -753,7 +771,7 @@ func renderLeftColumn(fileName string, dl *DiffLine, colWidth int, styles StyleC
 	prefix := lineNumberStyle.Render(lineNum + " " + marker)
 
 	// Apply syntax highlighting
-	content := highlightLine(fileName, dl.Content, bgStyle.GetBackground())
+	content := styles.highlight(fileName, dl.Content, bgStyle.GetBackground())
 
 	// Apply intra-line highlighting for removed lines
 	if dl.Kind == LineRemoved && len(dl.Segments) > 0 {
@@ -811,7 +829,7 @@ func renderRightColumn(fileName string, dl *DiffLine, colWidth int, styles Style
 	prefix := lineNumberStyle.Render(lineNum + " " + marker)
 
 	// Apply syntax highlighting
-	content := highlightLine(fileName, dl.Content, bgStyle.GetBackground())
+	content := styles.highlight(fileName, dl.Content, bgStyle.GetBackground())
 
 	// Apply intra-line highlighting for added lines
 	if dl.Kind == LineAdded && len(dl.Segments) > 0 {
@@ -868,6 +886,69 @@ func RenderSideBySideHunk(fileName string, h Hunk, opts ...SideBySideOption) str
 	return sb.String()
 }
 
+// RenderUnifiedHunk formats a hunk for unified display, with old and new
+// line numbers in front of each line
+func RenderUnifiedHunk(fileName string, h Hunk, opts ...SideBySideOption) string {
+	config := NewSideBySideConfig(opts...)
+
+	hunkCopy := Hunk{Lines: make([]DiffLine, len(h.Lines))}
+	copy(hunkCopy.Lines, h.Lines)
+	HighlightIntralineChanges(&hunkCopy, config.Style)
+
+	var sb strings.Builder
+	for i := range hunkCopy.Lines {
+		sb.WriteString(renderUnifiedLine(fileName, &hunkCopy.Lines[i], config.TotalWidth, config.Style) + "\n")
+	}
+	return sb.String()
+}
+
+// renderUnifiedLine formats a single line of a unified diff
+func renderUnifiedLine(fileName string, dl *DiffLine, width int, styles StyleConfig) string {
+	removedLineStyle, addedLineStyle, contextLineStyle, lineNumberStyle := createStyles(styles)
+
+	var marker string
+	var bgStyle lipgloss.Style
+	switch dl.Kind {
+	case LineRemoved:
+		marker = removedLineStyle.Foreground(styles.RemovedFg).Render("-")
+		bgStyle = removedLineStyle
+		lineNumberStyle = lineNumberStyle.Foreground(styles.RemovedFg).Background(styles.RemovedLineNumberBg)
+	case LineAdded:
+		marker = addedLineStyle.Foreground(styles.AddedFg).Render("+")
+		bgStyle = addedLineStyle
+		lineNumberStyle = lineNumberStyle.Foreground(styles.AddedFg).Background(styles.AddedLineNamerBg)
+	default:
+		marker = contextLineStyle.Render(" ")
+		bgStyle = contextLineStyle
+	}
+
+	oldNum, newNum := "", ""
+	if dl.OldLineNo > 0 {
+		oldNum = fmt.Sprintf("%d", dl.OldLineNo)
+	}
+	if dl.NewLineNo > 0 {
+		newNum = fmt.Sprintf("%d", dl.NewLineNo)
+	}
+	prefix := lineNumberStyle.Render(fmt.Sprintf("%6s %6s %s", oldNum, newNum, marker))
+
+	content := styles.highlight(fileName, dl.Content, bgStyle.GetBackground())
+	switch {
+	case dl.Kind == LineRemoved && len(dl.Segments) > 0:
+		content = applyHighlighting(content, dl.Segments, LineRemoved, styles.RemovedHighlightBg)
+	case dl.Kind == LineAdded && len(dl.Segments) > 0:
+		content = applyHighlighting(content, dl.Segments, LineAdded, styles.AddedHighlightBg)
+	}
+
+	lineText := prefix + bgStyle.Render(" ") + content
+	return bgStyle.MaxHeight(1).Width(width).Render(
+		ansi.Truncate(
+			lineText,
+			width,
+			lipgloss.NewStyle().Background(styles.HunkLineBg).Foreground(styles.HunkLineFg).Render("..."),
+		),
+	)
+}
+
 // FormatDiff creates a side-by-side formatted view of a diff
 func FormatDiff(diffText string, opts ...SideBySideOption) (string, error) {
 	diffResult, err := ParseUnifiedDiff(diffText)
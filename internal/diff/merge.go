@@ -0,0 +1,134 @@
+package diff
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/aymanbagabas/go-udiff/lcs"
+)
+
+// Conflict markers written by Merge
+const (
+	ConflictStart     = "<<<<<<<"
+	ConflictSeparator = "======="
+	ConflictEnd       = ">>>>>>>"
+)
+
+// lineHunk replaces the base lines [start, end) with lines
+type lineHunk struct {
+	start, end int
+	lines      []string
+}
+
+// Merge merges the changes two sides made to a common base, line by line
+// like diff3. Changes to the same or adjacent lines conflict unless both
+// sides made the same change; both versions are then kept between conflict
+// markers labelled with the side names. It returns the merged content and
+// the number of conflicts.
+func Merge(base, ours, theirs, oursLabel, theirsLabel string) (string, int) {
+	baseLines := splitLines(base)
+	ids := make(map[string]rune)
+	oursHunks := lineHunks(ids, baseLines, splitLines(ours))
+	theirsHunks := lineHunks(ids, baseLines, splitLines(theirs))
+
+	var merged []string
+	conflicts := 0
+	pos, i, j := 0, 0, 0
+	for i < len(oursHunks) || j < len(theirsHunks) {
+		// Group the hunks of both sides touching the first one
+		oursFrom, theirsFrom := i, j
+		var lo, hi int
+		if j == len(theirsHunks) || (i < len(oursHunks) && oursHunks[i].start <= theirsHunks[j].start) {
+			lo, hi = oursHunks[i].start, oursHunks[i].end
+			i++
+		} else {
+			lo, hi = theirsHunks[j].start, theirsHunks[j].end
+			j++
+		}
+		for {
+			if i < len(oursHunks) && oursHunks[i].start <= hi {
+				hi = max(hi, oursHunks[i].end)
+				i++
+			} else if j < len(theirsHunks) && theirsHunks[j].start <= hi {
+				hi = max(hi, theirsHunks[j].end)
+				j++
+			} else {
+				break
+			}
+		}
+
+		merged = append(merged, baseLines[pos:lo]...)
+		o := applyHunks(baseLines, lo, hi, oursHunks[oursFrom:i])
+		t := applyHunks(baseLines, lo, hi, theirsHunks[theirsFrom:j])
+		switch {
+		case theirsFrom == j, slices.Equal(o, t):
+			merged = append(merged, o...)
+		case oursFrom == i:
+			merged = append(merged, t...)
+		default:
+			conflicts++
+			merged = append(merged, ConflictStart+" "+oursLabel+"\n")
+			merged = appendTerminated(merged, o)
+			merged = append(merged, ConflictSeparator+"\n")
+			merged = appendTerminated(merged, t)
+			merged = append(merged, ConflictEnd+" "+theirsLabel+"\n")
+		}
+		pos = hi
+	}
+	merged = append(merged, baseLines[pos:]...)
+	return strings.Join(merged, ""), conflicts
+}
+
+// splitLines splits text into lines, keeping their line endings
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineHunks returns the hunks changing base into other, in order. Lines
+// are compared by the IDs given to them in ids.
+func lineHunks(ids map[string]rune, base, other []string) []lineHunk {
+	toRunes := func(lines []string) []rune {
+		runes := make([]rune, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = rune(len(ids))
+				ids[line] = id
+			}
+			runes[i] = id
+		}
+		return runes
+	}
+	diffs := lcs.DiffRunes(toRunes(base), toRunes(other))
+	hunks := make([]lineHunk, 0, len(diffs))
+	for _, d := range diffs {
+		hunks = append(hunks, lineHunk{start: d.Start, end: d.End, lines: other[d.ReplStart:d.ReplEnd]})
+	}
+	return hunks
+}
+
+// applyHunks returns the base lines [lo, hi) with the hunks applied
+func applyHunks(base []string, lo, hi int, hunks []lineHunk) []string {
+	var lines []string
+	pos := lo
+	for _, h := range hunks {
+		lines = append(lines, base[pos:h.start]...)
+		lines = append(lines, h.lines...)
+		pos = h.end
+	}
+	return append(lines, base[pos:hi]...)
+}
+
+// appendTerminated appends lines, ending the last one with a newline so a
+// conflict marker can follow
+func appendTerminated(dst, lines []string) []string {
+	dst = append(dst, lines...)
+	if n := len(dst); n > 0 && !strings.HasSuffix(dst[n-1], "\n") {
+		dst[n-1] += "\n"
+	}
+	return dst
+}
@@ -0,0 +1,58 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	base := "package m\n\nfunc A() {}\n\nfunc B() {}\n\nfunc C() {}\n"
+	tests := []struct {
+		name      string
+		ours      string
+		theirs    string
+		merged    string
+		conflicts int
+	}{
+		{
+			name:   "changes to different lines",
+			ours:   "// Package m does math\npackage m\n\nfunc A() {}\n\nfunc B() {}\n\nfunc C() {}\n",
+			theirs: "package m\n\nfunc A() {}\n\nfunc B() {}\n\nfunc C() { return }\n",
+			merged: "// Package m does math\npackage m\n\nfunc A() {}\n\nfunc B() {}\n\nfunc C() { return }\n",
+		},
+		{
+			name:   "the same change",
+			ours:   "package m\n\nfunc A() {}\n\nfunc B() { b() }\n\nfunc C() {}\n",
+			theirs: "package m\n\nfunc A() {}\n\nfunc B() { b() }\n\nfunc C() {}\n",
+			merged: "package m\n\nfunc A() {}\n\nfunc B() { b() }\n\nfunc C() {}\n",
+		},
+		{
+			name:   "one side unchanged",
+			ours:   base,
+			theirs: "package m\n\nfunc B() {}\n\nfunc C() {}\n",
+			merged: "package m\n\nfunc B() {}\n\nfunc C() {}\n",
+		},
+		{
+			name:      "conflicting changes",
+			ours:      "package m\n\nfunc A() {}\n\nfunc B() { ours() }\n\nfunc C() {}\n",
+			theirs:    "package m\n\nfunc A() {}\n\nfunc B() { theirs() }\n\nfunc C() {}\n",
+			merged:    "package m\n\nfunc A() {}\n\n<<<<<<< current\nfunc B() { ours() }\n=======\nfunc B() { theirs() }\n>>>>>>> proposed\n\nfunc C() {}\n",
+			conflicts: 1,
+		},
+		{
+			name:      "conflict without a final newline",
+			ours:      "package m\n\nfunc A() {}\n\nfunc B() {}\n\nfunc C() { ours() }",
+			theirs:    "package m\n\nfunc A() {}\n\nfunc B() {}\n\nfunc C() { theirs() }",
+			merged:    "package m\n\nfunc A() {}\n\nfunc B() {}\n\n<<<<<<< current\nfunc C() { ours() }\n=======\nfunc C() { theirs() }\n>>>>>>> proposed\n",
+			conflicts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := Merge(base, tt.ours, tt.theirs, "current", "proposed")
+			assert.Equal(t, tt.merged, merged)
+			assert.Equal(t, tt.conflicts, conflicts)
+		})
+	}
+}
@@ -25,16 +25,30 @@ type File struct {
 	Version   string
 	CreatedAt int64
 	UpdatedAt int64
+	// Provenance is set on versions made by the swarm
+	Provenance *Provenance
+}
+
+// Provenance tells which swarm agent and task made a version of a file, and
+// the vote that let the task run if there was one
+type Provenance struct {
+	AgentID string
+	TaskID  string
+	VoteID  string
 }
 
 type Service interface {
 	pubsub.Suscriber[File]
 	Create(ctx context.Context, sessionID, path, content string) (File, error)
 	CreateVersion(ctx context.Context, sessionID, path, content string) (File, error)
+	CreateSwarmVersion(ctx context.Context, sessionID, path, content string, provenance Provenance) (File, error)
 	Get(ctx context.Context, id string) (File, error)
 	GetByPathAndSession(ctx context.Context, path, sessionID string) (File, error)
 	ListBySession(ctx context.Context, sessionID string) ([]File, error)
 	ListLatestSessionFiles(ctx context.Context, sessionID string) ([]File, error)
+	WhoChanged(ctx context.Context, path string) ([]File, error)
+	ListByTask(ctx context.Context, taskID string) ([]File, error)
+	PreviousVersion(ctx context.Context, path, version string) (File, error)
 	Update(ctx context.Context, file File) (File, error)
 	Delete(ctx context.Context, id string) error
 	DeleteSessionFiles(ctx context.Context, sessionID string) error
@@ -55,10 +69,20 @@ func NewService(q *db.Queries, db *sql.DB) Service {
 }
 
 func (s *service) Create(ctx context.Context, sessionID, path, content string) (File, error) {
-	return s.createWithVersion(ctx, sessionID, path, content, InitialVersion)
+	return s.createWithVersion(ctx, sessionID, path, content, InitialVersion, nil)
 }
 
 func (s *service) CreateVersion(ctx context.Context, sessionID, path, content string) (File, error) {
+	return s.createNextVersion(ctx, sessionID, path, content, nil)
+}
+
+// CreateSwarmVersion creates the next version of a file like CreateVersion,
+// recording the swarm agent, task and vote that made it
+func (s *service) CreateSwarmVersion(ctx context.Context, sessionID, path, content string, provenance Provenance) (File, error) {
+	return s.createNextVersion(ctx, sessionID, path, content, &provenance)
+}
+
+func (s *service) createNextVersion(ctx context.Context, sessionID, path, content string, provenance *Provenance) (File, error) {
 	// Get the latest version for this path
 	files, err := s.q.ListFilesByPath(ctx, path)
 	if err != nil {
@@ -67,7 +91,7 @@ func (s *service) CreateVersion(ctx context.Context, sessionID, path, content st
 
 	if len(files) == 0 {
 		// No previous versions, create initial
-		return s.Create(ctx, sessionID, path, content)
+		return s.createWithVersion(ctx, sessionID, path, content, InitialVersion, provenance)
 	}
 
 	// Get the latest version
@@ -91,10 +115,21 @@ func (s *service) CreateVersion(ctx context.Context, sessionID, path, content st
 		nextVersion = fmt.Sprintf("v%d", latestFile.CreatedAt)
 	}
 
-	return s.createWithVersion(ctx, sessionID, path, content, nextVersion)
+	return s.createWithVersion(ctx, sessionID, path, content, nextVersion, provenance)
 }
 
-func (s *service) createWithVersion(ctx context.Context, sessionID, path, content, version string) (File, error) {
+func (s *service) createWithVersion(ctx context.Context, sessionID, path, content, version string, provenance *Provenance) (File, error) {
+	params := db.CreateFileParams{
+		SessionID: sessionID,
+		Path:      path,
+		Content:   content,
+	}
+	if provenance != nil {
+		params.AgentID = sql.NullString{String: provenance.AgentID, Valid: true}
+		params.TaskID = sql.NullString{String: provenance.TaskID, Valid: true}
+		params.VoteID = sql.NullString{String: provenance.VoteID, Valid: provenance.VoteID != ""}
+	}
+
 	// Maximum number of retries for transaction conflicts
 	const maxRetries = 3
 	var file File
@@ -112,13 +147,9 @@ func (s *service) createWithVersion(ctx context.Context, sessionID, path, conten
 		qtx := s.q.WithTx(tx)
 
 		// Try to create the file within the transaction
-		dbFile, txErr := qtx.CreateFile(ctx, db.CreateFileParams{
-			ID:        uuid.New().String(),
-			SessionID: sessionID,
-			Path:      path,
-			Content:   content,
-			Version:   version,
-		})
+		params.ID = uuid.New().String()
+		params.Version = version
+		dbFile, txErr := qtx.CreateFile(ctx, params)
 		if txErr != nil {
 			// Rollback the transaction
 			tx.Rollback()
@@ -198,6 +229,71 @@ func (s *service) ListLatestSessionFiles(ctx context.Context, sessionID string)
 	return files, nil
 }
 
+// WhoChanged returns the versions of a file the swarm made, in any
+// session, newest first
+func (s *service) WhoChanged(ctx context.Context, path string) ([]File, error) {
+	dbFiles, err := s.q.ListFilesByPath(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	files := []File{}
+	for _, dbFile := range dbFiles {
+		if file := s.fromDBItem(dbFile); file.Provenance != nil {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// ListByTask returns the versions of files a swarm task made, in any
+// session, oldest first
+func (s *service) ListByTask(ctx context.Context, taskID string) ([]File, error) {
+	dbFiles, err := s.q.ListFilesByTask(ctx, sql.NullString{String: taskID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	files := make([]File, len(dbFiles))
+	for i, dbFile := range dbFiles {
+		files[i] = s.fromDBItem(dbFile)
+	}
+	return files, nil
+}
+
+// PreviousVersion returns the version of a file before the given version,
+// in any session. It returns sql.ErrNoRows for the first version.
+func (s *service) PreviousVersion(ctx context.Context, path, version string) (File, error) {
+	dbFiles, err := s.q.ListFilesByPath(ctx, path)
+	if err != nil {
+		return File{}, err
+	}
+	// Versions of the same second are not ordered by creation, their
+	// numbers are
+	number := versionNumber(version)
+	var previous *db.File
+	for i, dbFile := range dbFiles {
+		n := versionNumber(dbFile.Version)
+		if n < number && (previous == nil || n > versionNumber(previous.Version)) {
+			previous = &dbFiles[i]
+		}
+	}
+	if previous == nil {
+		return File{}, sql.ErrNoRows
+	}
+	return s.fromDBItem(*previous), nil
+}
+
+// versionNumber orders versions, the initial version is 0 and vN is N
+func versionNumber(version string) int {
+	if version == InitialVersion {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	if err != nil || !strings.HasPrefix(version, "v") {
+		return -1
+	}
+	return n
+}
+
 func (s *service) Update(ctx context.Context, file File) (File, error) {
 	dbFile, err := s.q.UpdateFile(ctx, db.UpdateFileParams{
 		ID:      file.ID,
@@ -240,13 +336,22 @@ func (s *service) DeleteSessionFiles(ctx context.Context, sessionID string) erro
 }
 
 func (s *service) fromDBItem(item db.File) File {
+	var provenance *Provenance
+	if item.AgentID.Valid {
+		provenance = &Provenance{
+			AgentID: item.AgentID.String,
+			TaskID:  item.TaskID.String,
+			VoteID:  item.VoteID.String,
+		}
+	}
 	return File{
-		ID:        item.ID,
-		SessionID: item.SessionID,
-		Path:      item.Path,
-		Content:   item.Content,
-		Version:   item.Version,
-		CreatedAt: item.CreatedAt,
-		UpdatedAt: item.UpdatedAt,
+		ID:         item.ID,
+		SessionID:  item.SessionID,
+		Path:       item.Path,
+		Content:    item.Content,
+		Version:    item.Version,
+		CreatedAt:  item.CreatedAt,
+		UpdatedAt:  item.UpdatedAt,
+		Provenance: provenance,
 	}
 }
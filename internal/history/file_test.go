@@ -0,0 +1,82 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"github.com/opencode-ai/opencode/internal/db"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T) Service {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "opencode.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	goose.SetBaseFS(db.FS)
+	require.NoError(t, goose.SetDialect("sqlite3"))
+	require.NoError(t, goose.Up(conn, "migrations"))
+	_, err = conn.Exec(`INSERT INTO sessions (id, title, created_at, updated_at) VALUES ('session', 'test', 0, 0)`)
+	require.NoError(t, err)
+	return NewService(db.New(conn), conn)
+}
+
+func TestWhoChanged(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	_, err := s.Create(ctx, "session", "main.go", "package main\n")
+	require.NoError(t, err)
+	swarmVersion, err := s.CreateSwarmVersion(ctx, "session", "main.go", "// Package main runs\npackage main\n",
+		Provenance{AgentID: "docs", TaskID: "task-1", VoteID: "vote-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "v1", swarmVersion.Version)
+	assert.Equal(t, &Provenance{AgentID: "docs", TaskID: "task-1", VoteID: "vote-1"}, swarmVersion.Provenance)
+	_, err = s.CreateVersion(ctx, "session", "main.go", "// Package main runs opencode\npackage main\n")
+	require.NoError(t, err)
+
+	latest, err := s.GetByPathAndSession(ctx, "main.go", "session")
+	require.NoError(t, err)
+	assert.Nil(t, latest.Provenance)
+
+	changes, err := s.WhoChanged(ctx, "main.go")
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, swarmVersion.ID, changes[0].ID)
+	assert.Equal(t, "docs", changes[0].Provenance.AgentID)
+
+	changes, err = s.WhoChanged(ctx, "other.go")
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestListByTask(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+
+	initial, err := s.Create(ctx, "session", "main.go", "package main\n")
+	require.NoError(t, err)
+	swarmVersion, err := s.CreateSwarmVersion(ctx, "session", "main.go", "// Package main runs\npackage main\n",
+		Provenance{AgentID: "docs", TaskID: "task-1"})
+	require.NoError(t, err)
+	_, err = s.CreateSwarmVersion(ctx, "session", "other.go", "package other\n", Provenance{AgentID: "docs", TaskID: "task-2"})
+	require.NoError(t, err)
+
+	files, err := s.ListByTask(ctx, "task-1")
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, swarmVersion.ID, files[0].ID)
+
+	previous, err := s.PreviousVersion(ctx, "main.go", swarmVersion.Version)
+	require.NoError(t, err)
+	assert.Equal(t, initial.ID, previous.ID)
+	_, err = s.PreviousVersion(ctx, "main.go", initial.Version)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/opencode-ai/opencode/internal/logging"
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/permission"
+	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/session"
 )
 
@@ -38,13 +39,18 @@ func (e *AgentEvent) Response() message.Message {
 }
 
 type Service interface {
+	pubsub.Suscriber[ProgressEvent]
 	Run(ctx context.Context, sessionID string, content string) (<-chan AgentEvent, error)
 	Cancel(sessionID string)
 	IsSessionBusy(sessionID string) bool
 	IsBusy() bool
+	// SetRetrievalHook adds context to every prompt. Set it before the
+	// first Run.
+	SetRetrievalHook(hook RetrievalHook)
 }
 
 type agent struct {
+	*pubsub.Broker[ProgressEvent]
 	sessions session.Service
 	messages message.Service
 
@@ -53,6 +59,8 @@ type agent struct {
 
 	titleProvider provider.Provider
 
+	retrievalHook RetrievalHook
+
 	activeRequests sync.Map
 }
 
@@ -76,6 +84,7 @@ func NewAgent(
 	}
 
 	agent := &agent{
+		Broker:         pubsub.NewBroker[ProgressEvent](),
 		provider:       agentProvider,
 		messages:       messages,
 		sessions:       sessions,
@@ -110,6 +119,10 @@ func (a *agent) IsBusy() bool {
 	return busy
 }
 
+func (a *agent) SetRetrievalHook(hook RetrievalHook) {
+	a.retrievalHook = hook
+}
+
 func (a *agent) IsSessionBusy(sessionID string) bool {
 	_, busy := a.activeRequests.Load(sessionID)
 	return busy
@@ -172,9 +185,17 @@ func (a *agent) Run(ctx context.Context, sessionID string, content string) (<-ch
 			events <- a.err(fmt.Errorf("panic while running the agent"))
 		})
 
-		result := a.processGeneration(genCtx, sessionID, content)
-		if result.Err() != nil && !errors.Is(result.Err(), ErrRequestCancelled) && !errors.Is(result.Err(), context.Canceled) {
+		progress := newProgressTracker(a.Broker, sessionID)
+		progress.started()
+		result := a.processGeneration(genCtx, sessionID, content, progress)
+		switch {
+		case result.Err() == nil:
+			progress.finished(ProgressPhaseCompleted, "Done")
+		case errors.Is(result.Err(), ErrRequestCancelled) || errors.Is(result.Err(), context.Canceled):
+			progress.finished(ProgressPhaseCancelled, "Cancelled")
+		default:
 			logging.ErrorPersist(fmt.Sprintf("Generation error for session %s: %v", sessionID, result))
+			progress.finished(ProgressPhaseFailed, result.Err().Error())
 		}
 		logging.Debug("Request completed", "sessionID", sessionID)
 		a.activeRequests.Delete(sessionID)
@@ -185,7 +206,7 @@ func (a *agent) Run(ctx context.Context, sessionID string, content string) (<-ch
 	return events, nil
 }
 
-func (a *agent) processGeneration(ctx context.Context, sessionID, content string) AgentEvent {
+func (a *agent) processGeneration(ctx context.Context, sessionID, content string, progress *progressTracker) AgentEvent {
 	// List existing messages; if none, start title generation asynchronously.
 	msgs, err := a.messages.List(ctx, sessionID)
 	if err != nil {
@@ -209,7 +230,7 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 	}
 
 	// Append the new user message to the conversation history.
-	msgHistory := append(msgs, userMsg)
+	msgHistory := append(msgs, a.withRetrievedContext(ctx, sessionID, userMsg, content))
 	for {
 		// Check for cancellation before each iteration
 		select {
@@ -218,7 +239,7 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 		default:
 			// Continue processing
 		}
-		agentMessage, toolResults, err := a.streamAndHandleEvents(ctx, sessionID, msgHistory)
+		agentMessage, toolResults, err := a.streamAndHandleEvents(ctx, sessionID, msgHistory, progress)
 		if err != nil {
 			if errors.Is(err, context.Canceled) {
 				agentMessage.AddFinish(message.FinishReasonCanceled)
@@ -239,6 +260,25 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 	}
 }
 
+// withRetrievedContext returns the user message to send, with the context
+// from the retrieval hook in front of the prompt. The stored message keeps
+// the prompt as typed.
+func (a *agent) withRetrievedContext(ctx context.Context, sessionID string, userMsg message.Message, content string) message.Message {
+	if a.retrievalHook == nil {
+		return userMsg
+	}
+	retrieved, err := a.retrievalHook(ctx, sessionID, content)
+	if err != nil {
+		logging.Warn("Failed to retrieve context for the prompt", "error", err)
+		return userMsg
+	}
+	if retrieved == "" {
+		return userMsg
+	}
+	userMsg.Parts = []message.ContentPart{message.TextContent{Text: retrieved + "\n\n" + content}}
+	return userMsg
+}
+
 func (a *agent) createUserMessage(ctx context.Context, sessionID, content string) (message.Message, error) {
 	return a.messages.Create(ctx, sessionID, message.CreateMessageParams{
 		Role: message.User,
@@ -248,7 +288,7 @@ func (a *agent) createUserMessage(ctx context.Context, sessionID, content string
 	})
 }
 
-func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msgHistory []message.Message) (message.Message, *message.Message, error) {
+func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msgHistory []message.Message, progress *progressTracker) (message.Message, *message.Message, error) {
 	eventChan := a.provider.StreamResponse(ctx, msgHistory, a.tools)
 
 	assistantMsg, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
@@ -266,7 +306,7 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 
 	// Process each event in the stream.
 	for event := range eventChan {
-		if processErr := a.processEvent(ctx, sessionID, &assistantMsg, event); processErr != nil {
+		if processErr := a.processEvent(ctx, sessionID, &assistantMsg, event, progress); processErr != nil {
 			a.finishMessage(ctx, &assistantMsg, message.FinishReasonCanceled)
 			return assistantMsg, nil, processErr
 		}
@@ -293,6 +333,7 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 			goto out
 		default:
 			// Continue processing
+			progress.toolCall(toolCall.Name, i, len(toolCalls))
 			var tool tools.BaseTool
 			for _, availableTools := range a.tools {
 				if availableTools.Info().Name == toolCall.Name {
@@ -310,7 +351,12 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 				continue
 			}
 
-			toolResult, toolErr := tool.Run(ctx, tools.ToolCall{
+			// Let long running tools report what they are doing
+			name, done, total := toolCall.Name, i, len(toolCalls)
+			toolCtx := context.WithValue(ctx, tools.ProgressContextKey, tools.ProgressFunc(func(message string) {
+				progress.toolProgress(name, message, done, total)
+			}))
+			toolResult, toolErr := tool.Run(toolCtx, tools.ToolCall{
 				ID:    toolCall.ID,
 				Name:  toolCall.Name,
 				Input: toolCall.Input,
@@ -365,7 +411,7 @@ func (a *agent) finishMessage(ctx context.Context, msg *message.Message, finishR
 	_ = a.messages.Update(ctx, *msg)
 }
 
-func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg *message.Message, event provider.ProviderEvent) error {
+func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg *message.Message, event provider.ProviderEvent, progress *progressTracker) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -375,9 +421,11 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 
 	switch event.Type {
 	case provider.EventThinkingDelta:
+		progress.delta(ProgressPhaseThinking, event.Content)
 		assistantMsg.AppendReasoningContent(event.Content)
 		return a.messages.Update(ctx, *assistantMsg)
 	case provider.EventContentDelta:
+		progress.delta(ProgressPhaseStreaming, event.Content)
 		assistantMsg.AppendContent(event.Content)
 		return a.messages.Update(ctx, *assistantMsg)
 	case provider.EventToolUseStart:
@@ -408,6 +456,7 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 		if err := a.messages.Update(ctx, *assistantMsg); err != nil {
 			return fmt.Errorf("failed to update message: %w", err)
 		}
+		progress.usage(event.Response.Usage.OutputTokens)
 		return a.TrackUsage(ctx, sessionID, a.provider.Model(), event.Response.Usage)
 	}
 
@@ -0,0 +1,173 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/swarm"
+)
+
+// RetrievalHook returns context to send along with a prompt, or "" for none.
+// The context is not stored with the message.
+type RetrievalHook func(ctx context.Context, sessionID, prompt string) (string, error)
+
+const (
+	defaultKnowledgeTokens   = 1000
+	defaultKnowledgeMemories = 5
+
+	// charsPerToken estimates token counts like the progress tracker does
+	charsPerToken = 4
+
+	knowledgeHeader = "<swarm-knowledge>\nThe agent swarm remembers the following, which may be relevant to the request. Use it only where it helps.\n\n"
+	knowledgeFooter = "</swarm-knowledge>"
+)
+
+// knowledgeTypes are the memories worth adding to prompts, facts and
+// how-tos rather than raw events
+var knowledgeTypes = []swarm.MemoryType{swarm.MemoryTypeSemantic, swarm.MemoryTypeProcedural}
+
+// KnowledgeInjector adds the swarm memories relevant to a prompt to it,
+// within a token budget. Sessions can turn it on and off.
+type KnowledgeInjector struct {
+	swarm  *swarm.Swarm
+	config config.KnowledgeConfig
+
+	mu sync.RWMutex
+	// toggled holds the sessions that differ from config.Disabled
+	toggled map[string]bool
+}
+
+func NewKnowledgeInjector(s *swarm.Swarm, cfg config.KnowledgeConfig) *KnowledgeInjector {
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = defaultKnowledgeTokens
+	}
+	if cfg.MaxMemories <= 0 {
+		cfg.MaxMemories = defaultKnowledgeMemories
+	}
+	return &KnowledgeInjector{
+		swarm:   s,
+		config:  cfg,
+		toggled: make(map[string]bool),
+	}
+}
+
+// Enabled reports whether memories are added to the prompts of a session
+func (k *KnowledgeInjector) Enabled(sessionID string) bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.config.Disabled == k.toggled[sessionID]
+}
+
+// SetEnabled turns memories on or off for a session
+func (k *KnowledgeInjector) SetEnabled(sessionID string, enabled bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if enabled == !k.config.Disabled {
+		delete(k.toggled, sessionID)
+	} else {
+		k.toggled[sessionID] = true
+	}
+}
+
+// Toggle flips whether memories are added to a session and returns the new
+// state
+func (k *KnowledgeInjector) Toggle(sessionID string) bool {
+	enabled := !k.Enabled(sessionID)
+	k.SetEnabled(sessionID, enabled)
+	return enabled
+}
+
+// Retrieve is the RetrievalHook of the injector. It searches the semantic
+// and procedural memories of the swarm for the prompt and returns the best
+// that fit the token budget as a context block.
+func (k *KnowledgeInjector) Retrieve(ctx context.Context, sessionID, prompt string) (string, error) {
+	if !k.Enabled(sessionID) {
+		return "", nil
+	}
+	memories, err := k.swarm.Search(ctx, swarm.HybridQuery{
+		Text:     prompt,
+		Types:    knowledgeTypes,
+		MinScore: k.config.MinScore,
+		Limit:    k.config.MaxMemories,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	budget := k.config.MaxTokens*charsPerToken - len(knowledgeHeader) - len(knowledgeFooter)
+	var block strings.Builder
+	for _, memory := range memories {
+		text := memoryText(memory.Memory)
+		if text == "" {
+			continue
+		}
+		line := fmt.Sprintf("- [%s] %s\n", memory.Type, text)
+		if len(line) > budget {
+			// Cut the best remaining memory to fit rather than skip it,
+			// unless too little would be left of it
+			if budget < 80 {
+				break
+			}
+			line = truncateRunes(line[:len(line)-1], budget-4) + "...\n"
+		}
+		block.WriteString(line)
+		budget -= len(line)
+	}
+	if block.Len() == 0 {
+		return "", nil
+	}
+	return knowledgeHeader + block.String() + knowledgeFooter, nil
+}
+
+// memoryText renders the content of a memory on one line
+func memoryText(memory swarm.Memory) string {
+	var text string
+	switch content := memory.Content.(type) {
+	case string:
+		text = content
+	case *swarm.TaskResult:
+		text = taskResultText(content)
+	case swarm.TaskResult:
+		text = taskResultText(&content)
+	default:
+		if memory.Encrypted || content == nil {
+			return ""
+		}
+		data, err := json.Marshal(content)
+		if err != nil {
+			return ""
+		}
+		text = string(data)
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func taskResultText(result *swarm.TaskResult) string {
+	outcome := "succeeded"
+	if !result.Success {
+		outcome = "failed"
+	}
+	text := fmt.Sprintf("Task %s %s", result.TaskID, outcome)
+	if response, ok := result.Output["response"].(string); ok && response != "" {
+		text += ": " + response
+	} else if result.Error != nil {
+		text += ": " + result.Error.Error()
+	}
+	return text
+}
+
+// truncateRunes cuts s to at most n bytes without splitting a character
+func truncateRunes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
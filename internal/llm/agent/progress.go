@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/pubsub"
+)
+
+// ProgressPhase describes what a generation is currently doing
+type ProgressPhase string
+
+const (
+	ProgressPhaseStarted   ProgressPhase = "started"
+	ProgressPhaseThinking  ProgressPhase = "thinking"
+	ProgressPhaseStreaming ProgressPhase = "streaming"
+	ProgressPhaseToolCall  ProgressPhase = "tool_call"
+	ProgressPhaseCompleted ProgressPhase = "completed"
+	ProgressPhaseCancelled ProgressPhase = "cancelled"
+	ProgressPhaseFailed    ProgressPhase = "failed"
+)
+
+// progressThrottle limits how often streaming updates are published
+const progressThrottle = 100 * time.Millisecond
+
+// ProgressEvent reports the state of a running generation
+type ProgressEvent struct {
+	SessionID string
+	Phase     ProgressPhase
+	Message   string
+
+	// TokensStreamed is estimated from the streamed characters until the
+	// provider reports the real usage on completion
+	TokensStreamed int
+
+	ToolName       string
+	ToolCallsDone  int
+	ToolCallsTotal int
+
+	// Progress is between 0 and 1, or 0 when the phase is indeterminate
+	Progress float64
+}
+
+// Done returns whether the event ends the generation
+func (e ProgressEvent) Done() bool {
+	switch e.Phase {
+	case ProgressPhaseCompleted, ProgressPhaseCancelled, ProgressPhaseFailed:
+		return true
+	}
+	return false
+}
+
+// progressTracker accumulates progress for a single generation and publishes
+// it through the agent's broker
+type progressTracker struct {
+	broker    *pubsub.Broker[ProgressEvent]
+	sessionID string
+
+	streamedChars int
+	lastPublish   time.Time
+}
+
+func newProgressTracker(broker *pubsub.Broker[ProgressEvent], sessionID string) *progressTracker {
+	return &progressTracker{
+		broker:    broker,
+		sessionID: sessionID,
+	}
+}
+
+func (p *progressTracker) publish(event ProgressEvent) {
+	event.SessionID = p.sessionID
+	event.TokensStreamed = p.streamedChars / 4
+	p.lastPublish = time.Now()
+	p.broker.Publish(pubsub.UpdatedEvent, event)
+}
+
+func (p *progressTracker) started() {
+	p.publish(ProgressEvent{Phase: ProgressPhaseStarted, Message: "Sending request"})
+}
+
+// delta records streamed content, publishing at most every progressThrottle
+func (p *progressTracker) delta(phase ProgressPhase, content string) {
+	p.streamedChars += len(content)
+	if time.Since(p.lastPublish) < progressThrottle {
+		return
+	}
+	message := "Generating response"
+	if phase == ProgressPhaseThinking {
+		message = "Thinking"
+	}
+	p.publish(ProgressEvent{Phase: phase, Message: message})
+}
+
+func (p *progressTracker) toolCall(name string, done, total int) {
+	p.toolProgress(name, "Running "+name, done, total)
+}
+
+// toolProgress reports what a running tool said it is doing
+func (p *progressTracker) toolProgress(name, message string, done, total int) {
+	progress := 0.0
+	if total > 0 {
+		progress = float64(done) / float64(total)
+	}
+	p.publish(ProgressEvent{
+		Phase:          ProgressPhaseToolCall,
+		Message:        message,
+		ToolName:       name,
+		ToolCallsDone:  done,
+		ToolCallsTotal: total,
+		Progress:       progress,
+	})
+}
+
+func (p *progressTracker) usage(outputTokens int64) {
+	p.streamedChars = int(outputTokens) * 4
+}
+
+func (p *progressTracker) finished(phase ProgressPhase, message string) {
+	p.publish(ProgressEvent{Phase: phase, Message: message, Progress: 1})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/permission"
 	"github.com/opencode-ai/opencode/internal/session"
+	"github.com/opencode-ai/opencode/internal/swarm"
 )
 
 func CoderAgentTools(
@@ -17,12 +18,16 @@ func CoderAgentTools(
 	messages message.Service,
 	history history.Service,
 	lspClients map[string]*lsp.Client,
+	agentSwarm *swarm.Swarm,
 ) []tools.BaseTool {
 	ctx := context.Background()
 	otherTools := GetMcpTools(ctx, permissions)
 	if len(lspClients) > 0 {
 		otherTools = append(otherTools, tools.NewDiagnosticsTool(lspClients))
 	}
+	if agentSwarm != nil {
+		otherTools = append(otherTools, tools.NewDelegateTool(agentSwarm, permissions, history))
+	}
 	return append(
 		[]tools.BaseTool{
 			tools.NewBashTool(permissions),
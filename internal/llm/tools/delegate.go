@@ -0,0 +1,474 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/diff"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/permission"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/opencode-ai/opencode/internal/swarm"
+)
+
+type DelegateParams struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description"`
+	Files       []string `json:"files,omitempty"`
+	Priority    int      `json:"priority,omitempty"`
+	Timeout     int      `json:"timeout,omitempty"`
+}
+
+type DelegateResponseMetadata struct {
+	TaskID     string   `json:"task_id"`
+	AgentID    string   `json:"agent_id,omitempty"`
+	Success    bool     `json:"success"`
+	Duration   string   `json:"duration,omitempty"`
+	Applied    []string `json:"applied,omitempty"`
+	Rejected   []string `json:"rejected,omitempty"`
+	Conflicted []string `json:"conflicted,omitempty"`
+}
+
+type delegateTool struct {
+	swarm       *swarm.Swarm
+	permissions permission.Service
+	files       history.Service
+}
+
+const (
+	DelegateToolName        = "delegate_to_swarm"
+	defaultDelegateTimeout  = 5 * time.Minute
+	maxDelegateTimeout      = 30 * time.Minute
+	delegateToolDescription = `Delegates a task to the agent swarm running alongside opencode and waits for its result.
+
+WHEN TO USE THIS TOOL:
+- Use for work the swarm's specialized agents are configured for, like analysis, testing or documentation
+- Helpful for long running checks that should not block your own tool budget
+- Useful when the user asks to hand something to the swarm
+
+HOW TO USE:
+- Provide the task type, which picks the agents that can handle it (e.g. "analysis", "testing", "documentation")
+- Describe the task in enough detail for an agent to do it on its own
+- Optionally list the files the task is about
+- Optionally set a priority, higher runs first, and a timeout in seconds
+
+FEATURES:
+- Reports the task's progress (queued, voted on, running) while it waits
+- Returns the result the swarm agent produced
+- Cancels the swarm task when the request is cancelled
+- Offers the file edits a swarm agent proposes, like documentation updates, for approval one file at a time and applies the approved ones
+- Merges proposed edits with changes made to their files since the swarm agent read them, marking conflicting changes for review
+
+LIMITATIONS:
+- Only available when a swarm is configured
+- Fails when no swarm agent can handle the task type
+- Default timeout is 5 minutes, maximum is 30 minutes
+
+TIPS:
+- Ask for a specific, self-contained deliverable, the swarm agent does not see this conversation
+- Summarize the result for the user, they only see the raw output`
+)
+
+func NewDelegateTool(s *swarm.Swarm, permissions permission.Service, files history.Service) BaseTool {
+	return &delegateTool{
+		swarm:       s,
+		permissions: permissions,
+		files:       files,
+	}
+}
+
+func (t *delegateTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        DelegateToolName,
+		Description: delegateToolDescription,
+		Parameters: map[string]any{
+			"type": map[string]any{
+				"type":        "string",
+				"description": "The task type, matched against the capabilities of the swarm agents",
+			},
+			"description": map[string]any{
+				"type":        "string",
+				"description": "What the swarm agent should do and return",
+			},
+			"files": map[string]any{
+				"type":        "array",
+				"description": "Optional paths of the files the task is about",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+			"priority": map[string]any{
+				"type":        "number",
+				"description": "Optional priority, higher priorities run first",
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": "Optional timeout in seconds (max 1800)",
+			},
+		},
+		Required: []string{"type", "description"},
+	}
+}
+
+func (t *delegateTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params DelegateParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewTextErrorResponse("Failed to parse delegate parameters: " + err.Error()), nil
+	}
+	if params.Type == "" {
+		return NewTextErrorResponse("type parameter is required"), nil
+	}
+	if params.Description == "" {
+		return NewTextErrorResponse("description parameter is required"), nil
+	}
+
+	timeout := defaultDelegateTimeout
+	if params.Timeout > 0 {
+		timeout = min(time.Duration(params.Timeout)*time.Second, maxDelegateTimeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Subscribe first, the task may start before SubmitTask returns
+	events := t.swarm.Subscribe(ctx)
+	task := swarm.Task{
+		Type:        params.Type,
+		Description: params.Description,
+		Priority:    params.Priority,
+	}
+	if len(params.Files) > 0 {
+		task.Input = map[string]interface{}{"files": params.Files}
+	}
+	taskID, err := t.swarm.SubmitTask(ctx, task)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("Failed to submit task to the swarm: %s", err)), nil
+	}
+
+	type outcome struct {
+		result *swarm.TaskResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := t.swarm.Result(ctx, taskID)
+		done <- outcome{result, err}
+	}()
+
+	// report passes on an event about the task. It returns false once the
+	// subscription ended, because the swarm closed or ctx is done; the
+	// result tells which.
+	report := func(event pubsub.Event[swarm.TimelineEvent], ok bool) bool {
+		if ok {
+			if message := delegateProgress(taskID, event.Payload); message != "" {
+				ReportProgress(ctx, message)
+			}
+		}
+		return ok
+	}
+	for {
+		select {
+		case event, ok := <-events:
+			if !report(event, ok) {
+				events = nil
+			}
+		case out := <-done:
+			// Events recorded before the task finished are still buffered
+		drain:
+			for events != nil {
+				select {
+				case event, ok := <-events:
+					if !report(event, ok) {
+						break drain
+					}
+				default:
+					break drain
+				}
+			}
+			if out.err != nil {
+				if ctx.Err() != nil {
+					_ = t.swarm.CancelTask(taskID)
+				}
+				if errors.Is(out.err, context.DeadlineExceeded) {
+					return NewTextErrorResponse(fmt.Sprintf("Swarm task %s did not finish within %s and was cancelled", taskID, timeout)), nil
+				}
+				return NewTextErrorResponse(fmt.Sprintf("Swarm task %s failed: %s", taskID, out.err)), nil
+			}
+			patch, _ := out.result.Output[swarm.OutputPatch].(*swarm.Patch)
+			if !out.result.Success || patch == nil || len(patch.Files) == 0 {
+				return delegateResponse(out.result, nil), nil
+			}
+			outcome, err := t.applyPatch(ctx, out.result, patch)
+			if err != nil {
+				return ToolResponse{}, err
+			}
+			if err := t.swarm.ReportEditOutcome(ctx, outcome); err != nil {
+				logging.Debug("Error reporting the outcome of swarm edits", "error", err)
+			}
+			return delegateResponse(out.result, &outcome), nil
+		}
+	}
+}
+
+// applyPatch offers the files of the patch a task proposed for approval,
+// one permission request per file, and writes the approved ones, recording
+// them in the file history like the edit tool does, along with the agent,
+// task and vote that made them. Edits of files that are gone are not
+// offered.
+func (t *delegateTool) applyPatch(ctx context.Context, result *swarm.TaskResult, patch *swarm.Patch) (swarm.EditOutcome, error) {
+	outcome := swarm.EditOutcome{TaskID: result.TaskID, AgentID: result.AgentID}
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return outcome, fmt.Errorf("session ID and message ID are required for applying swarm edits")
+	}
+	provenance := history.Provenance{AgentID: result.AgentID, TaskID: result.TaskID}
+	if record, err := t.swarm.Task(result.TaskID); err == nil {
+		provenance.VoteID = record.VoteID
+	}
+	for _, edit := range patch.Files {
+		if err := t.applyEdit(ctx, sessionID, provenance, edit, &outcome); err != nil {
+			return outcome, err
+		}
+	}
+	return outcome, nil
+}
+
+// swarmEditMu makes checking a file and writing a swarm edit to it atomic
+var swarmEditMu sync.Mutex
+
+// applyEdit offers an edit for approval and writes it once approved. When
+// the file changed since the agent read it, e.g. by the edit of another
+// task, the edit is rebased onto the changes with a three-way merge, and
+// offered with conflict markers where they overlap. A file changing while
+// the user decides is not overwritten, the edit is offered again.
+func (t *delegateTool) applyEdit(ctx context.Context, sessionID string, provenance history.Provenance, edit swarm.FileEdit, outcome *swarm.EditOutcome) error {
+	agentID := provenance.AgentID
+	for {
+		content, err := os.ReadFile(edit.Path)
+		if err != nil {
+			outcome.Stale = append(outcome.Stale, edit.Path)
+			return nil
+		}
+		current := string(content)
+		proposed := edit.Proposed
+		description := fmt.Sprintf("Apply the edit swarm agent %s proposed to file %s", agentID, edit.Path)
+		var conflicts int
+		if current != edit.Original {
+			proposed, conflicts = diff.Merge(edit.Original, current, edit.Proposed, "current", "proposed by swarm agent "+agentID)
+			if proposed == current {
+				// The changes made since include the edit
+				outcome.Applied = append(outcome.Applied, edit.Path)
+				return nil
+			}
+			changes := t.changesSince(ctx, sessionID, edit.Path, current)
+			if conflicts > 0 {
+				description = fmt.Sprintf("The edit swarm agent %s proposed to file %s conflicts with %s to the same lines, approve to write both versions between conflict markers", agentID, edit.Path, changes)
+			} else {
+				description = fmt.Sprintf("Apply the edit swarm agent %s proposed to file %s, rebased onto %s", agentID, edit.Path, changes)
+			}
+		}
+
+		approved := t.requestEdit(sessionID, edit.Path, description, current, proposed)
+		if approved {
+			written, err := writeUnchanged(edit.Path, current, proposed)
+			if err != nil {
+				return err
+			}
+			if !written {
+				continue
+			}
+			t.recordEdit(ctx, sessionID, provenance, edit.Path, current, proposed)
+			outcome.Applied = append(outcome.Applied, edit.Path)
+		} else {
+			outcome.Rejected = append(outcome.Rejected, edit.Path)
+		}
+		switch {
+		case conflicts > 0:
+			outcome.Conflicted = append(outcome.Conflicted, edit.Path)
+		case current != edit.Original:
+			outcome.Rebased = append(outcome.Rebased, edit.Path)
+		}
+		return nil
+	}
+}
+
+// changesSince describes the changes that turned a file into its current
+// content, by its history in the session
+func (t *delegateTool) changesSince(ctx context.Context, sessionID, path, current string) string {
+	file, err := t.files.GetByPathAndSession(ctx, path, sessionID)
+	if err != nil || file.Content != current {
+		return "changes made outside this session"
+	}
+	if file.Version == "" || file.Version == history.InitialVersion {
+		return "an edit made in this session"
+	}
+	return fmt.Sprintf("the edit of version %s in this session", file.Version)
+}
+
+// requestEdit asks the user for permission to change a file
+func (t *delegateTool) requestEdit(sessionID, path, description, current, proposed string) bool {
+	diff, _, _ := diff.GenerateDiff(current, proposed, path)
+	rootDir := config.WorkingDirectory()
+	permissionPath := filepath.Dir(path)
+	if strings.HasPrefix(path, rootDir) {
+		permissionPath = rootDir
+	}
+	return t.permissions.Request(
+		permission.CreatePermissionRequest{
+			SessionID:   sessionID,
+			Path:        permissionPath,
+			ToolName:    DelegateToolName,
+			Action:      "write",
+			Description: description,
+			Params: EditPermissionsParams{
+				FilePath: path,
+				Diff:     diff,
+			},
+		},
+	)
+}
+
+// writeUnchanged writes content to a file unless the file no longer has
+// the expected content
+func writeUnchanged(path, expected, content string) (bool, error) {
+	swarmEditMu.Lock()
+	defer swarmEditMu.Unlock()
+	current, err := os.ReadFile(path)
+	if err != nil || string(current) != expected {
+		return false, nil
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return false, fmt.Errorf("failed to write file: %w", err)
+	}
+	return true, nil
+}
+
+// recordEdit records a written edit in the file history
+func (t *delegateTool) recordEdit(ctx context.Context, sessionID string, provenance history.Provenance, path, before, after string) {
+	file, err := t.files.GetByPathAndSession(ctx, path, sessionID)
+	if err != nil {
+		_, err = t.files.Create(ctx, sessionID, path, before)
+		if err != nil {
+			logging.Debug("Error creating file history", "error", err)
+		}
+	} else if file.Content != before {
+		// The file changed since its last version, store it as one
+		_, err = t.files.CreateVersion(ctx, sessionID, path, before)
+		if err != nil {
+			logging.Debug("Error creating file history version", "error", err)
+		}
+	}
+	_, err = t.files.CreateSwarmVersion(ctx, sessionID, path, after, provenance)
+	if err != nil {
+		logging.Debug("Error creating file history version", "error", err)
+	}
+	recordFileWrite(path)
+	recordFileRead(path)
+}
+
+// delegateProgress describes a timeline event about the task, or returns
+// "" for events about anything else
+func delegateProgress(taskID string, event swarm.TimelineEvent) string {
+	if event.Subject != taskID && event.Details["task"] != taskID {
+		return ""
+	}
+	switch event.Type {
+	case swarm.TimelineTaskSubmitted:
+		return "Queued in the swarm"
+	case swarm.TimelineVoteOpened:
+		return "Swarm agents are voting on the task"
+	case swarm.TimelineVoteDecided:
+		return "Swarm vote: " + event.Summary
+	case swarm.TimelineTaskStarted:
+		if agentID, ok := event.Details["agent"].(string); ok {
+			return "Running on swarm agent " + agentID
+		}
+		return "Running in the swarm"
+	case swarm.TimelineTaskProgress:
+		if event.Summary != "" {
+			return event.Summary
+		}
+		stage, _ := event.Details["stage"].(string)
+		if percent, ok := event.Details["percent"].(float64); ok {
+			return strings.TrimSpace(fmt.Sprintf("%s %.0f%%", stage, percent))
+		}
+		return stage
+	}
+	return ""
+}
+
+// delegateResponse turns a task result, and the outcome of the edits it
+// proposed if any, into the tool response
+func delegateResponse(result *swarm.TaskResult, outcome *swarm.EditOutcome) ToolResponse {
+	metadata := DelegateResponseMetadata{
+		TaskID:  result.TaskID,
+		AgentID: result.AgentID,
+		Success: result.Success,
+	}
+	if result.ExecutionTime > 0 {
+		metadata.Duration = result.ExecutionTime.Round(time.Millisecond).String()
+	}
+
+	if outcome != nil {
+		metadata.Applied = outcome.Applied
+		metadata.Rejected = outcome.Rejected
+		metadata.Conflicted = outcome.Conflicted
+	}
+
+	var output strings.Builder
+	if response, ok := result.Output["response"].(string); ok {
+		output.WriteString(response)
+	} else if len(result.Output) > 0 {
+		keys := make([]string, 0, len(result.Output))
+		for key := range result.Output {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&output, "%s: %v\n", key, result.Output[key])
+		}
+	}
+
+	if !result.Success {
+		message := fmt.Sprintf("Swarm task %s failed", result.TaskID)
+		if result.Error != nil {
+			message += ": " + result.Error.Error()
+		}
+		if output.Len() > 0 {
+			message += "\n\n" + output.String()
+		}
+		return WithResponseMetadata(NewTextErrorResponse(message), metadata)
+	}
+	if output.Len() == 0 {
+		output.WriteString("The swarm task completed without output")
+	}
+	if outcome != nil {
+		output.WriteString("\n\n" + delegateEditSummary(outcome))
+	}
+	return WithResponseMetadata(NewTextResponse(output.String()), metadata)
+}
+
+// delegateEditSummary tells the model which proposed edits were applied
+func delegateEditSummary(outcome *swarm.EditOutcome) string {
+	var summary strings.Builder
+	write := func(label string, paths []string) {
+		if len(paths) > 0 {
+			fmt.Fprintf(&summary, "%s:\n- %s\n", label, strings.Join(paths, "\n- "))
+		}
+	}
+	write("Applied the approved edits to", outcome.Applied)
+	write("The user rejected the edits to", outcome.Rejected)
+	write("Did not offer the edits to these files, they are gone", outcome.Stale)
+	write("These files changed since the swarm agent read them, its edits were merged with the changes", outcome.Rebased)
+	write("These files changed since the swarm agent read them and its edits conflict with the changes, approved ones now hold conflict markers to resolve", outcome.Conflicted)
+	return strings.TrimRight(summary.String(), "\n")
+}
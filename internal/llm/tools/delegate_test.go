@@ -0,0 +1,297 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/history"
+	"github.com/opencode-ai/opencode/internal/permission"
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reviewAgent answers every task with a canned review
+type reviewAgent struct {
+	*agent.BaseAgent
+}
+
+func (a *reviewAgent) CanHandleTask(task agent.Task) bool {
+	return task.Type == "review"
+}
+
+func (a *reviewAgent) ExecuteTask(ctx context.Context, task agent.Task) (*agent.TaskResult, error) {
+	return &agent.TaskResult{
+		TaskID:        task.ID,
+		Success:       true,
+		Output:        map[string]interface{}{"response": "Looks good: " + task.Description},
+		AgentID:       a.GetID(),
+		ExecutionTime: 1500 * time.Millisecond,
+		CompletedAt:   time.Now(),
+	}, nil
+}
+
+func openReviewSwarm(t *testing.T) *swarm.Swarm {
+	t.Helper()
+	agent.RegisterFactory(agent.AgentTypeAnalyzer, func(config agent.AgentConfig) (agent.Agent, error) {
+		return &reviewAgent{BaseAgent: agent.NewBaseAgent(config)}, nil
+	})
+	s, err := swarm.Open(swarm.FileConfig{Agents: []swarm.AgentFileConfig{{ID: "reviewer", Type: string(agent.AgentTypeAnalyzer)}}})
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestDelegateTool_Info(t *testing.T) {
+	tool := NewDelegateTool(nil, nil, nil)
+	info := tool.Info()
+
+	assert.Equal(t, DelegateToolName, info.Name)
+	assert.NotEmpty(t, info.Description)
+	assert.Contains(t, info.Parameters, "type")
+	assert.Contains(t, info.Parameters, "description")
+	assert.ElementsMatch(t, []string{"type", "description"}, info.Required)
+}
+
+func TestDelegateTool_Run(t *testing.T) {
+	tool := NewDelegateTool(openReviewSwarm(t), nil, nil)
+
+	t.Run("returns the task result", func(t *testing.T) {
+		var progress []string
+		ctx := context.WithValue(context.Background(), ProgressContextKey, ProgressFunc(func(message string) {
+			progress = append(progress, message)
+		}))
+		input, _ := json.Marshal(DelegateParams{Type: "review", Description: "the parser"})
+
+		response, err := tool.Run(ctx, ToolCall{Name: DelegateToolName, Input: string(input)})
+		require.NoError(t, err)
+		assert.False(t, response.IsError, response.Content)
+		assert.Equal(t, "Looks good: the parser", response.Content)
+
+		var metadata DelegateResponseMetadata
+		require.NoError(t, json.Unmarshal([]byte(response.Metadata), &metadata))
+		assert.NotEmpty(t, metadata.TaskID)
+		assert.Equal(t, "reviewer", metadata.AgentID)
+		assert.True(t, metadata.Success)
+		assert.Equal(t, "1.5s", metadata.Duration)
+
+		assert.Contains(t, progress, "Queued in the swarm")
+		assert.Contains(t, progress, "Running on swarm agent reviewer")
+	})
+
+	t.Run("fails when no agent can handle the task", func(t *testing.T) {
+		input, _ := json.Marshal(DelegateParams{Type: "deploy", Description: "ship it"})
+
+		response, err := tool.Run(context.Background(), ToolCall{Name: DelegateToolName, Input: string(input)})
+		require.NoError(t, err)
+		assert.True(t, response.IsError)
+		assert.Contains(t, response.Content, "no agent can handle the task")
+	})
+
+	t.Run("requires a description", func(t *testing.T) {
+		response, err := tool.Run(context.Background(), ToolCall{Name: DelegateToolName, Input: `{"type": "review"}`})
+		require.NoError(t, err)
+		assert.True(t, response.IsError)
+		assert.Contains(t, response.Content, "description")
+	})
+}
+
+// docsAgent proposes to document the file of its task
+type docsAgent struct {
+	*agent.BaseAgent
+}
+
+func (a *docsAgent) CanHandleTask(task agent.Task) bool {
+	return task.Type == "documentation"
+}
+
+func (a *docsAgent) ExecuteTask(ctx context.Context, task agent.Task) (*agent.TaskResult, error) {
+	var edits []agent.FileEdit
+	for _, path := range task.Input["files"].([]string) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, agent.FileEdit{Path: path, Original: string(content), Proposed: "// Package m does math\n" + string(content)})
+	}
+	return &agent.TaskResult{
+		TaskID:  task.ID,
+		Success: true,
+		Output:  map[string]interface{}{"response": "Documented", agent.OutputPatch: agent.NewPatch(filepath.Dir(edits[0].Path), edits)},
+		AgentID: a.GetID(),
+	}, nil
+}
+
+// approvals grants permission requests for the paths it approves
+type approvals struct {
+	permission.Service
+	approved map[string]bool
+}
+
+func (a *approvals) Request(opts permission.CreatePermissionRequest) bool {
+	return a.approved[opts.Params.(EditPermissionsParams).FilePath]
+}
+
+// fileVersions records the versions of files in history
+type fileVersions struct {
+	history.Service
+	versions    map[string][]string
+	provenances map[string]history.Provenance
+}
+
+func (f *fileVersions) GetByPathAndSession(ctx context.Context, path, sessionID string) (history.File, error) {
+	versions := f.versions[path]
+	if len(versions) == 0 {
+		return history.File{}, errors.New("not found")
+	}
+	return history.File{Path: path, Content: versions[len(versions)-1]}, nil
+}
+
+func (f *fileVersions) Create(ctx context.Context, sessionID, path, content string) (history.File, error) {
+	return f.CreateVersion(ctx, sessionID, path, content)
+}
+
+func (f *fileVersions) CreateVersion(ctx context.Context, sessionID, path, content string) (history.File, error) {
+	f.versions[path] = append(f.versions[path], content)
+	return history.File{Path: path, Content: content}, nil
+}
+
+func (f *fileVersions) CreateSwarmVersion(ctx context.Context, sessionID, path, content string, provenance history.Provenance) (history.File, error) {
+	if f.provenances != nil {
+		f.provenances[path] = provenance
+	}
+	return f.CreateVersion(ctx, sessionID, path, content)
+}
+
+// loadConfig loads the configuration of dir for the test and restores the
+// configuration loaded before when it ends, so other tests never see it
+func loadConfig(t *testing.T, dir string) {
+	t.Helper()
+	previous := config.Get()
+	config.Set(nil)
+	t.Cleanup(func() { config.Set(previous) })
+	_, err := config.Load(dir, false)
+	require.NoError(t, err)
+}
+
+func TestDelegateTool_Edits(t *testing.T) {
+	agent.RegisterFactory(agent.AgentTypeDocumentation, func(config agent.AgentConfig) (agent.Agent, error) {
+		return &docsAgent{BaseAgent: agent.NewBaseAgent(config)}, nil
+	})
+	s, err := swarm.Open(swarm.FileConfig{Agents: []swarm.AgentFileConfig{{ID: "docs", Type: string(agent.AgentTypeDocumentation)}}})
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+
+	dir := t.TempDir()
+	loadConfig(t, dir)
+	approved, rejected := filepath.Join(dir, "approved.go"), filepath.Join(dir, "rejected.go")
+	for _, path := range []string{approved, rejected} {
+		require.NoError(t, os.WriteFile(path, []byte("package m\n"), 0o644))
+	}
+	files := &fileVersions{versions: map[string][]string{}, provenances: map[string]history.Provenance{}}
+	tool := NewDelegateTool(s, &approvals{approved: map[string]bool{approved: true}}, files)
+
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "message")
+	input, _ := json.Marshal(DelegateParams{Type: "documentation", Description: "document m", Files: []string{approved, rejected}})
+	response, err := tool.Run(ctx, ToolCall{Name: DelegateToolName, Input: string(input)})
+	require.NoError(t, err)
+	assert.False(t, response.IsError, response.Content)
+	assert.Contains(t, response.Content, "Applied the approved edits to:\n- "+approved)
+	assert.Contains(t, response.Content, "The user rejected the edits to:\n- "+rejected)
+
+	var metadata DelegateResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(response.Metadata), &metadata))
+	assert.Equal(t, []string{approved}, metadata.Applied)
+	assert.Equal(t, []string{rejected}, metadata.Rejected)
+
+	content, err := os.ReadFile(approved)
+	require.NoError(t, err)
+	assert.Equal(t, "// Package m does math\npackage m\n", string(content))
+	content, err = os.ReadFile(rejected)
+	require.NoError(t, err)
+	assert.Equal(t, "package m\n", string(content))
+	assert.Equal(t, map[string][]string{approved: {"package m\n", "// Package m does math\npackage m\n"}}, files.versions)
+	assert.Equal(t, map[string]history.Provenance{approved: {AgentID: "docs", TaskID: metadata.TaskID}}, files.provenances)
+
+	outcomes, err := s.Query(context.Background(), swarm.MemoryQuery{Type: swarm.MemoryTypeProcedural, Tags: []string{"edits", "outcome"}})
+	require.NoError(t, err)
+	require.Len(t, outcomes, 1)
+	assert.Contains(t, outcomes[0].Content, "1 of 2 edits proposed by task "+metadata.TaskID+" were applied")
+	assert.Equal(t, []string{approved}, outcomes[0].Metadata["files"])
+}
+
+// concurrentEdits approves every edit, changing its file the first time it
+// is offered as if another task edited it while the user decided
+type concurrentEdits struct {
+	permission.Service
+	changes      map[string]string
+	descriptions []string
+}
+
+func (c *concurrentEdits) Request(opts permission.CreatePermissionRequest) bool {
+	c.descriptions = append(c.descriptions, opts.Description)
+	path := opts.Params.(EditPermissionsParams).FilePath
+	if change, ok := c.changes[path]; ok {
+		delete(c.changes, path)
+		if err := os.WriteFile(path, []byte(change), 0o644); err != nil {
+			panic(err)
+		}
+	}
+	return true
+}
+
+func TestDelegateTool_ConcurrentEdits(t *testing.T) {
+	agent.RegisterFactory(agent.AgentTypeDocumentation, func(config agent.AgentConfig) (agent.Agent, error) {
+		return &docsAgent{BaseAgent: agent.NewBaseAgent(config)}, nil
+	})
+	s, err := swarm.Open(swarm.FileConfig{Agents: []swarm.AgentFileConfig{{ID: "docs", Type: string(agent.AgentTypeDocumentation)}}})
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+
+	dir := t.TempDir()
+	loadConfig(t, dir)
+	rebased, conflicted := filepath.Join(dir, "rebased.go"), filepath.Join(dir, "conflicted.go")
+	for _, path := range []string{rebased, conflicted} {
+		require.NoError(t, os.WriteFile(path, []byte("package m\n\nfunc A() {}\n"), 0o644))
+	}
+	permissions := &concurrentEdits{changes: map[string]string{
+		rebased:    "package m\n\nfunc A() { a() }\n",
+		conflicted: "// Package m is a module\npackage m\n\nfunc A() {}\n",
+	}}
+	files := &fileVersions{versions: map[string][]string{}}
+	tool := NewDelegateTool(s, permissions, files)
+
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "message")
+	input, _ := json.Marshal(DelegateParams{Type: "documentation", Description: "document m", Files: []string{rebased, conflicted}})
+	response, err := tool.Run(ctx, ToolCall{Name: DelegateToolName, Input: string(input)})
+	require.NoError(t, err)
+	assert.False(t, response.IsError, response.Content)
+
+	// The edits are offered again, merged with the changes made meanwhile
+	require.Len(t, permissions.descriptions, 4)
+	assert.Contains(t, permissions.descriptions[1], "rebased onto changes made outside this session")
+	assert.Contains(t, permissions.descriptions[3], "conflicts with changes made outside this session to the same lines")
+
+	content, err := os.ReadFile(rebased)
+	require.NoError(t, err)
+	assert.Equal(t, "// Package m does math\npackage m\n\nfunc A() { a() }\n", string(content))
+	content, err = os.ReadFile(conflicted)
+	require.NoError(t, err)
+	assert.Equal(t, "<<<<<<< current\n// Package m is a module\n=======\n// Package m does math\n>>>>>>> proposed by swarm agent docs\npackage m\n\nfunc A() {}\n", string(content))
+
+	var metadata DelegateResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(response.Metadata), &metadata))
+	assert.Equal(t, []string{rebased, conflicted}, metadata.Applied)
+	assert.Equal(t, []string{conflicted}, metadata.Conflicted)
+	assert.Contains(t, response.Content, "its edits were merged with the changes:\n- "+rebased)
+	assert.Contains(t, response.Content, "now hold conflict markers to resolve:\n- "+conflicted)
+}
@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+// TestMain loads a configuration for a temporary working directory, which
+// tools like ls resolve relative paths against
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "tools-test-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if _, err := config.Load(dir, false); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load the test configuration:", err)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
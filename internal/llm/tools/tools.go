@@ -17,6 +17,7 @@ type toolResponseType string
 type (
 	sessionIDContextKey string
 	messageIDContextKey string
+	progressContextKey  string
 )
 
 const (
@@ -25,8 +26,13 @@ const (
 
 	SessionIDContextKey sessionIDContextKey = "session_id"
 	MessageIDContextKey messageIDContextKey = "message_id"
+	ProgressContextKey  progressContextKey  = "progress"
 )
 
+// ProgressFunc receives what a running tool is doing. Callers of Run put it
+// in the context under ProgressContextKey.
+type ProgressFunc func(message string)
+
 type ToolResponse struct {
 	Type     toolResponseType `json:"type"`
 	Content  string           `json:"content"`
@@ -82,3 +88,12 @@ func GetContextValues(ctx context.Context) (string, string) {
 	}
 	return sessionID.(string), messageID.(string)
 }
+
+// ReportProgress tells the caller of a long running tool what it is doing.
+// Call it from the goroutine running the tool; it does nothing when the
+// caller does not listen.
+func ReportProgress(ctx context.Context, message string) {
+	if report, ok := ctx.Value(ProgressContextKey).(ProgressFunc); ok {
+		report(message)
+	}
+}
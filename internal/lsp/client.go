@@ -44,6 +44,10 @@ type Client struct {
 	diagnostics   map[protocol.DocumentUri][]protocol.Diagnostic
 	diagnosticsMu sync.RWMutex
 
+	// Diagnostic listeners
+	diagnosticListeners   []DiagnosticListener
+	diagnosticListenersMu sync.RWMutex
+
 	// Files are currently opened by the LSP
 	openFiles   map[string]*OpenFileInfo
 	openFilesMu sync.RWMutex
@@ -120,6 +124,26 @@ func (c *Client) RegisterNotificationHandler(method string, handler Notification
 	c.notificationHandlers[method] = handler
 }
 
+// DiagnosticListener is called with the diagnostics a server published for
+// a file
+type DiagnosticListener func(uri protocol.DocumentUri, diagnostics []protocol.Diagnostic)
+
+// OnDiagnostics registers a listener for the diagnostics the server
+// publishes, called after the diagnostic cache is updated
+func (c *Client) OnDiagnostics(listener DiagnosticListener) {
+	c.diagnosticListenersMu.Lock()
+	defer c.diagnosticListenersMu.Unlock()
+	c.diagnosticListeners = append(c.diagnosticListeners, listener)
+}
+
+func (c *Client) notifyDiagnosticListeners(uri protocol.DocumentUri, diagnostics []protocol.Diagnostic) {
+	c.diagnosticListenersMu.RLock()
+	defer c.diagnosticListenersMu.RUnlock()
+	for _, listener := range c.diagnosticListeners {
+		listener(uri, diagnostics)
+	}
+}
+
 func (c *Client) RegisterServerRequestHandler(method string, handler ServerRequestHandler) {
 	c.serverHandlersMu.Lock()
 	defer c.serverHandlersMu.Unlock()
@@ -102,7 +102,8 @@ func HandleDiagnostics(client *Client, params json.RawMessage) {
 	}
 
 	client.diagnosticsMu.Lock()
-	defer client.diagnosticsMu.Unlock()
-
 	client.diagnostics[diagParams.URI] = diagParams.Diagnostics
+	client.diagnosticsMu.Unlock()
+
+	client.notifyDiagnosticListeners(diagParams.URI, diagParams.Diagnostics)
 }
@@ -0,0 +1,369 @@
+package swarm
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxReportIncidents bounds the incidents listed in an activity report, the
+// latest are kept
+const maxReportIncidents = 20
+
+// ReportPeriod is how often scheduled activity reports are made
+type ReportPeriod string
+
+const (
+	ReportDaily  ReportPeriod = "daily"
+	ReportWeekly ReportPeriod = "weekly"
+)
+
+// Duration is the time a report of the period covers
+func (p ReportPeriod) Duration() time.Duration {
+	if p == ReportWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// TaskActivity counts the tasks that finished in a report
+type TaskActivity struct {
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	Cancelled int `json:"cancelled"`
+}
+
+// Finished is how many tasks finished in any way
+func (a TaskActivity) Finished() int {
+	return a.Completed + a.Failed + a.Cancelled
+}
+
+// SuccessRate is the share of the completed tasks of those that completed
+// or failed, cancelled tasks aside
+func (a TaskActivity) SuccessRate() float64 {
+	if a.Completed+a.Failed == 0 {
+		return 0
+	}
+	return float64(a.Completed) / float64(a.Completed+a.Failed)
+}
+
+func (a *TaskActivity) count(state TaskState) {
+	switch state {
+	case TaskStateCompleted:
+		a.Completed++
+	case TaskStateFailed:
+		a.Failed++
+	case TaskStateCancelled:
+		a.Cancelled++
+	}
+}
+
+// TaskTypeActivity counts the tasks of a type that finished
+type TaskTypeActivity struct {
+	Type string `json:"type"`
+	TaskActivity
+}
+
+// AgentActivity counts the tasks an agent finished and what they cost
+type AgentActivity struct {
+	AgentID string `json:"agentId"`
+	TaskActivity
+	Cost float64 `json:"cost"`
+}
+
+// Incident is a timeline event worth a look: an alert, a rollback, a vote
+// vetoed or expired, or a test report with failures
+type Incident struct {
+	Time    time.Time         `json:"time"`
+	Type    TimelineEventType `json:"type"`
+	Subject string            `json:"subject"`
+	Summary string            `json:"summary"`
+}
+
+// MemoryActivity is the size of the memory store at the end of a report
+// and how it grew
+type MemoryActivity struct {
+	Total  int            `json:"total"`
+	ByType map[string]int `json:"byType,omitempty"`
+	// Growth is how many memories were added, less those removed, since
+	// GrowthSince. It is only known when the swarm sampled the store then,
+	// as it does for every scheduled report.
+	Growth      int       `json:"growth"`
+	GrowthSince time.Time `json:"growthSince,omitempty"`
+}
+
+// CostActivity is what the tasks that finished in a report cost
+type CostActivity struct {
+	InputTokens  int64   `json:"inputTokens"`
+	OutputTokens int64   `json:"outputTokens"`
+	Cost         float64 `json:"cost"`
+	// Unpriced counts the results using tokens of models without a price
+	Unpriced int `json:"unpriced,omitempty"`
+}
+
+// ActivityReport sums up what the swarm did in a time window: the tasks
+// that finished, by type and agent, the incidents of the timeline, the
+// growth of the memory store and the cost of the tokens used
+type ActivityReport struct {
+	Swarm string `json:"swarm,omitempty"`
+	// Period is the schedule the report was made for, empty for reports
+	// made on request
+	Period    ReportPeriod       `json:"period,omitempty"`
+	Since     time.Time          `json:"since"`
+	Until     time.Time          `json:"until"`
+	Tasks     TaskActivity       `json:"tasks"`
+	ByType    []TaskTypeActivity `json:"byType,omitempty"`
+	Agents    []AgentActivity    `json:"agents,omitempty"`
+	Incidents []Incident         `json:"incidents,omitempty"`
+	// MoreIncidents counts the incidents left out, older than those listed
+	MoreIncidents int            `json:"moreIncidents,omitempty"`
+	Memory        MemoryActivity `json:"memory"`
+	Cost          CostActivity   `json:"cost"`
+}
+
+// ActivityReport sums up the activity of the swarm from since to until, up
+// to now if until is zero. Only tasks still tracked and events still on the
+// timeline count.
+func (c *Coordinator) ActivityReport(since, until time.Time) ActivityReport {
+	if until.IsZero() {
+		until = c.clock.Now()
+	}
+	report := ActivityReport{Swarm: c.config.Name, Since: since, Until: until}
+
+	byType := make(map[string]*TaskTypeActivity)
+	byAgent := make(map[string]*AgentActivity)
+	for _, record := range c.tasks.list() {
+		if !record.Finished() || record.FinishedAt.Before(since) || !record.FinishedAt.Before(until) {
+			continue
+		}
+		report.Tasks.count(record.State)
+		typ := byType[record.Task.Type]
+		if typ == nil {
+			typ = &TaskTypeActivity{Type: record.Task.Type}
+			byType[record.Task.Type] = typ
+		}
+		typ.count(record.State)
+		if record.AgentID == "" {
+			continue
+		}
+		ag := byAgent[record.AgentID]
+		if ag == nil {
+			ag = &AgentActivity{AgentID: record.AgentID}
+			byAgent[record.AgentID] = ag
+		}
+		ag.count(record.State)
+		if record.Result != nil {
+			ag.Cost += c.addCost(&report.Cost, record.Result.Metadata)
+		}
+	}
+	for _, typ := range slices.Sorted(maps.Keys(byType)) {
+		report.ByType = append(report.ByType, *byType[typ])
+	}
+	for _, id := range slices.Sorted(maps.Keys(byAgent)) {
+		report.Agents = append(report.Agents, *byAgent[id])
+	}
+
+	for _, event := range c.timeline.list(TimelineFilter{Since: since, Until: until}) {
+		if !event.Timestamp.Before(until) || !isIncident(event) {
+			continue
+		}
+		report.Incidents = append(report.Incidents, Incident{
+			Time:    event.Timestamp,
+			Type:    event.Type,
+			Subject: event.Subject,
+			Summary: event.Summary,
+		})
+	}
+	if extra := len(report.Incidents) - maxReportIncidents; extra > 0 {
+		report.MoreIncidents = extra
+		report.Incidents = report.Incidents[extra:]
+	}
+
+	stats := c.memoryStore.GetStats()
+	report.Memory.Total = stats.TotalMemories
+	if len(stats.MemoriesByType) > 0 {
+		report.Memory.ByType = make(map[string]int, len(stats.MemoriesByType))
+		for typ, n := range stats.MemoriesByType {
+			report.Memory.ByType[string(typ)] = n
+		}
+	}
+	if sample, ok := c.reports.memoryAt(since); ok {
+		report.Memory.Growth = stats.TotalMemories - sample.total
+		report.Memory.GrowthSince = sample.at
+	}
+	return report
+}
+
+// addCost adds the tokens a result reports to a cost and returns what they
+// cost
+func (c *Coordinator) addCost(cost *CostActivity, metadata map[string]interface{}) float64 {
+	input, output := metadataCount(metadata["input_tokens"]), metadataCount(metadata["output_tokens"])
+	if input == 0 && output == 0 {
+		return 0
+	}
+	cost.InputTokens += input
+	cost.OutputTokens += output
+	model, _ := metadata["model"].(string)
+	price, ok := c.price(model)
+	if !ok {
+		cost.Unpriced++
+		return 0
+	}
+	dollars := price.cost(input, output)
+	cost.Cost += dollars
+	return dollars
+}
+
+// isIncident reports whether a timeline event belongs in the incidents of
+// an activity report
+func isIncident(event TimelineEvent) bool {
+	switch event.Type {
+	case TimelineAlert, TimelineRollback:
+		return true
+	case TimelineVoteDecided:
+		vetoed, _ := event.Details["vetoed"].(bool)
+		expired, _ := event.Details["expired"].(bool)
+		return vetoed || expired
+	case TimelineTestReport:
+		return detailInt(event.Details, "failed") > 0
+	}
+	return false
+}
+
+// Title names the report by its period and end
+func (r ActivityReport) Title() string {
+	switch r.Period {
+	case ReportDaily:
+		return "Daily swarm report for " + r.Until.Format(time.DateOnly)
+	case ReportWeekly:
+		return "Weekly swarm report to " + r.Until.Format(time.DateOnly)
+	}
+	return "Swarm report to " + r.Until.Format(time.DateTime)
+}
+
+// Markdown renders the report as markdown
+func (r ActivityReport) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", r.Title())
+	if r.Swarm != "" {
+		fmt.Fprintf(&b, "Swarm %s, ", r.Swarm)
+	}
+	fmt.Fprintf(&b, "from %s to %s.\n\n", r.Since.Format(time.DateTime), r.Until.Format(time.DateTime))
+
+	b.WriteString("## Tasks\n\n")
+	if r.Tasks.Finished() == 0 {
+		b.WriteString("No tasks finished.\n")
+	} else {
+		fmt.Fprintf(&b, "%d finished: %d completed, %d failed, %d cancelled. Success rate %.0f%%.\n\n",
+			r.Tasks.Finished(), r.Tasks.Completed, r.Tasks.Failed, r.Tasks.Cancelled, r.Tasks.SuccessRate()*100)
+		b.WriteString("| Type | Completed | Failed | Cancelled | Success |\n|---|---|---|---|---|\n")
+		for _, typ := range r.ByType {
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %.0f%% |\n", typ.Type, typ.Completed, typ.Failed, typ.Cancelled, typ.SuccessRate()*100)
+		}
+		if len(r.Agents) > 0 {
+			b.WriteString("\n| Agent | Completed | Failed | Cancelled | Success | Cost |\n|---|---|---|---|---|---|\n")
+			for _, ag := range r.Agents {
+				fmt.Fprintf(&b, "| %s | %d | %d | %d | %.0f%% | $%.4f |\n", ag.AgentID, ag.Completed, ag.Failed, ag.Cancelled, ag.SuccessRate()*100, ag.Cost)
+			}
+		}
+	}
+
+	b.WriteString("\n## Incidents\n\n")
+	if len(r.Incidents) == 0 {
+		b.WriteString("None.\n")
+	}
+	if r.MoreIncidents > 0 {
+		fmt.Fprintf(&b, "%d earlier incidents left out.\n\n", r.MoreIncidents)
+	}
+	for _, incident := range r.Incidents {
+		fmt.Fprintf(&b, "- %s %s %s: %s\n", incident.Time.Format(time.DateTime), incident.Type, incident.Subject, markdownLine(incident.Summary))
+	}
+
+	b.WriteString("\n## Memory\n\n")
+	fmt.Fprintf(&b, "%d memories", r.Memory.Total)
+	if !r.Memory.GrowthSince.IsZero() {
+		fmt.Fprintf(&b, ", %+d since %s", r.Memory.Growth, r.Memory.GrowthSince.Format(time.DateTime))
+	}
+	b.WriteString(".\n")
+	if len(r.Memory.ByType) > 0 {
+		b.WriteString("\n")
+		for _, typ := range slices.Sorted(maps.Keys(r.Memory.ByType)) {
+			fmt.Fprintf(&b, "- %s: %d\n", typ, r.Memory.ByType[typ])
+		}
+	}
+
+	b.WriteString("\n## Cost\n\n")
+	fmt.Fprintf(&b, "$%.4f for %d input and %d output tokens", r.Cost.Cost, r.Cost.InputTokens, r.Cost.OutputTokens)
+	if r.Cost.Unpriced > 0 {
+		fmt.Fprintf(&b, ", %d results unpriced", r.Cost.Unpriced)
+	}
+	b.WriteString(".\n")
+	return b.String()
+}
+
+var activityHTML = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(f float64) string { return fmt.Sprintf("%.0f%%", f*100) },
+	"dollars": func(f float64) string { return fmt.Sprintf("$%.4f", f) },
+	"time":    func(t time.Time) string { return t.Format(time.DateTime) },
+	"sorted": func(m map[string]int) []string {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return keys
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{if .Swarm}}Swarm {{.Swarm}}, {{end}}from {{time .Since}} to {{time .Until}}.</p>
+<h2>Tasks</h2>
+{{if .Tasks.Finished}}<p>{{.Tasks.Finished}} finished: {{.Tasks.Completed}} completed, {{.Tasks.Failed}} failed, {{.Tasks.Cancelled}} cancelled. Success rate {{percent .Tasks.SuccessRate}}.</p>
+<table>
+<tr><th>Type</th><th>Completed</th><th>Failed</th><th>Cancelled</th><th>Success</th></tr>
+{{range .ByType}}<tr><td>{{.Type}}</td><td>{{.Completed}}</td><td>{{.Failed}}</td><td>{{.Cancelled}}</td><td>{{percent .SuccessRate}}</td></tr>
+{{end}}</table>
+{{if .Agents}}<table>
+<tr><th>Agent</th><th>Completed</th><th>Failed</th><th>Cancelled</th><th>Success</th><th>Cost</th></tr>
+{{range .Agents}}<tr><td>{{.AgentID}}</td><td>{{.Completed}}</td><td>{{.Failed}}</td><td>{{.Cancelled}}</td><td>{{percent .SuccessRate}}</td><td>{{dollars .Cost}}</td></tr>
+{{end}}</table>
+{{end}}{{else}}<p>No tasks finished.</p>
+{{end}}<h2>Incidents</h2>
+{{if .MoreIncidents}}<p>{{.MoreIncidents}} earlier incidents left out.</p>
+{{end}}{{if .Incidents}}<ul>
+{{range .Incidents}}<li>{{time .Time}} {{.Type}} {{.Subject}}: {{.Summary}}</li>
+{{end}}</ul>
+{{else}}<p>None.</p>
+{{end}}<h2>Memory</h2>
+<p>{{.Memory.Total}} memories{{if not .Memory.GrowthSince.IsZero}}, {{printf "%+d" .Memory.Growth}} since {{time .Memory.GrowthSince}}{{end}}.</p>
+{{with .Memory.ByType}}<ul>
+{{range $type := sorted .}}<li>{{$type}}: {{index $.Memory.ByType $type}}</li>
+{{end}}</ul>
+{{end}}<h2>Cost</h2>
+<p>{{dollars .Cost.Cost}} for {{.Cost.InputTokens}} input and {{.Cost.OutputTokens}} output tokens{{if .Cost.Unpriced}}, {{.Cost.Unpriced}} results unpriced{{end}}.</p>
+</body>
+</html>
+`))
+
+// HTML renders the report as a standalone HTML page
+func (r ActivityReport) HTML() string {
+	var buf bytes.Buffer
+	// The template only reads fields of the report, it cannot fail
+	_ = activityHTML.Execute(&buf, r)
+	return buf.String()
+}
@@ -0,0 +1,116 @@
+package swarm
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+func TestActivityReports(t *testing.T) {
+	start := time.Date(2026, 10, 17, 7, 0, 0, 0, time.Local)
+	clk := clock.NewFake(start)
+	dir := t.TempDir()
+	c, err := NewCoordinator(CoordinatorConfig{
+		Clock:  clk,
+		Prices: map[string]TokenPrice{"small": {Input: 1, Output: 2}},
+		Reports: ReportConfig{
+			Schedules: []ReportSchedule{{Period: ReportDaily, At: 8 * time.Hour}},
+			Sinks:     []ReportSink{ReportDirSink{Dir: dir, Format: ReportMarkdown}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.startReports()
+	defer func() {
+		c.cancelFunc()
+		c.wg.Wait()
+	}()
+
+	run := func(id, typ string, result agent.TaskResult) {
+		t.Helper()
+		if _, _, err := c.tasks.enqueue(agent.Task{ID: id, Type: typ}); err != nil {
+			t.Fatal(err)
+		}
+		c.tasks.next()
+		c.tasks.start(id, "worker", nil)
+		result.TaskID, result.AgentID = id, "worker"
+		c.tasks.finish(id, &result)
+	}
+	run("task-1", "docs", agent.TaskResult{Success: true, Metadata: map[string]interface{}{"model": "small", "input_tokens": 1000, "output_tokens": 500}})
+	run("task-2", "docs", agent.TaskResult{Success: false})
+	run("task-3", "patch", agent.TaskResult{Success: true})
+	c.timeline.record(TimelineAlert, "memory", "Store full", map[string]interface{}{"severity": "critical"})
+	c.timeline.record(TimelineVoteDecided, "s1", "Task t2 approved", map[string]interface{}{"approved": true})
+	c.timeline.record(TimelineVoteDecided, "s2", "Task t3 not approved in time", map[string]interface{}{"expired": true})
+	for i := 0; i < 3; i++ {
+		if err := c.memoryStore.Store(c.ctx, memory.Memory{Type: memory.MemoryTypeEpisodic, Content: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Hour)
+	var reports []ActivityReport
+	for deadline := time.Now().Add(5 * time.Second); len(reports) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("no report made")
+		}
+		time.Sleep(time.Millisecond)
+		reports = c.ActivityReports()
+	}
+
+	report := reports[0]
+	if report.Period != ReportDaily || report.Tasks.Completed != 2 || report.Tasks.Failed != 1 {
+		t.Fatalf("report = %+v, want a daily report of 2 completed and 1 failed tasks", report)
+	}
+	if len(report.ByType) != 2 || report.ByType[0].Type != "docs" || report.ByType[0].SuccessRate() != 0.5 {
+		t.Errorf("by type = %+v", report.ByType)
+	}
+	if math.Abs(report.Cost.Cost-0.002) > 1e-9 || len(report.Agents) != 1 || math.Abs(report.Agents[0].Cost-0.002) > 1e-9 {
+		t.Errorf("cost = %+v, agents = %+v, want $0.002 by worker", report.Cost, report.Agents)
+	}
+	if len(report.Incidents) != 2 || report.Incidents[0].Subject != "memory" || report.Incidents[1].Subject != "s2" {
+		t.Errorf("incidents = %+v, want the alert and the expired vote", report.Incidents)
+	}
+	if report.Memory.Total != 3 || report.Memory.Growth != 3 || !report.Memory.GrowthSince.Equal(start) {
+		t.Errorf("memory = %+v, want 3 memories, all new since the start", report.Memory)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "daily-2026-10-17.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Daily swarm report for 2026-10-17", "| docs | 1 | 1 | 0 | 50% |", "memory: Store full", "3 memories, +3 since"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("report lacks %q:\n%s", want, data)
+		}
+	}
+	if html := report.HTML(); !strings.Contains(html, "<td>docs</td><td>1</td><td>1</td>") {
+		t.Errorf("HTML report lacks the docs tasks:\n%s", html)
+	}
+}
+
+func TestReportScheduleNext(t *testing.T) {
+	// A Saturday morning
+	now := time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC)
+	daily := ReportSchedule{Period: ReportDaily, At: 8 * time.Hour}
+	if d := daily.next(now); d != 22*time.Hour {
+		t.Errorf("next daily report in %s, want 22h", d)
+	}
+	weekly := ReportSchedule{Period: ReportWeekly, At: 8 * time.Hour, Weekday: time.Monday}
+	if d := weekly.next(now); d != 46*time.Hour {
+		t.Errorf("next weekly report in %s, want 46h", d)
+	}
+	weekly.Weekday = time.Saturday
+	if d := weekly.next(now); d != 7*24*time.Hour-2*time.Hour {
+		t.Errorf("next weekly report in %s, want in 6 days and 22h", d)
+	}
+}
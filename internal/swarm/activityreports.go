@@ -0,0 +1,243 @@
+package swarm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// maxKeptReports bounds the scheduled reports kept for browsing
+	maxKeptReports = 30
+	// maxMemorySamples bounds the samples of the memory store kept to
+	// measure its growth, two weeks of daily reports
+	maxMemorySamples = 14
+	// reportPostTimeout bounds how long a report webhook has to answer
+	reportPostTimeout = 30 * time.Second
+)
+
+// ReportFormat is the format reports are delivered in
+type ReportFormat string
+
+const (
+	ReportMarkdown ReportFormat = "markdown"
+	ReportHTML     ReportFormat = "html"
+)
+
+// render renders a report in the format, markdown unless it is html
+func (f ReportFormat) render(report ActivityReport) (body []byte, contentType, ext string) {
+	if f == ReportHTML {
+		return []byte(report.HTML()), "text/html; charset=utf-8", ".html"
+	}
+	return []byte(report.Markdown()), "text/markdown; charset=utf-8", ".md"
+}
+
+// ReportSchedule is when scheduled activity reports are made
+type ReportSchedule struct {
+	Period ReportPeriod
+	// At is the local time of day after midnight the report is made at
+	At time.Duration
+	// Weekday is the day weekly reports are made on
+	Weekday time.Weekday
+}
+
+// next returns how long after now the next report of the schedule is due
+func (s ReportSchedule) next(now time.Time) time.Duration {
+	wait := untilTimeOfDay(now, s.At)
+	if s.Period != ReportWeekly {
+		return wait
+	}
+	for now.Add(wait).Weekday() != s.Weekday {
+		wait += 24 * time.Hour
+	}
+	return wait
+}
+
+// ReportSink receives the scheduled activity reports
+type ReportSink interface {
+	Deliver(ctx context.Context, report ActivityReport) error
+}
+
+// ReportDirSink writes reports to files of a directory, named after their
+// period and end, e.g. daily-2026-03-01.md
+type ReportDirSink struct {
+	Dir    string
+	Format ReportFormat
+}
+
+// Deliver writes a report, replacing the file of an earlier report of the
+// same period and day
+func (s ReportDirSink) Deliver(ctx context.Context, report ActivityReport) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	body, _, ext := s.Format.render(report)
+	period := string(report.Period)
+	if period == "" {
+		period = "report"
+	}
+	path := filepath.Join(s.Dir, period+"-"+report.Until.Format(time.DateOnly)+ext)
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// ReportWebhookSink posts reports to a URL, as the body of the request
+type ReportWebhookSink struct {
+	URL    string
+	Format ReportFormat
+	// Client posts the reports, http.DefaultClient if nil
+	Client *http.Client
+}
+
+// Deliver posts a report
+func (s ReportWebhookSink) Deliver(ctx context.Context, report ActivityReport) error {
+	body, contentType, _ := s.Format.render(report)
+	ctx, cancel := context.WithTimeout(ctx, reportPostTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post report: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to post report: webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// ReportConfig schedules activity reports
+type ReportConfig struct {
+	Schedules []ReportSchedule
+	// Sinks receive every scheduled report. Without sinks the reports are
+	// only kept for browsing, see Coordinator.ActivityReports.
+	Sinks []ReportSink
+}
+
+// memorySample is the size of the memory store at a time
+type memorySample struct {
+	at    time.Time
+	total int
+}
+
+// activityReports keeps the latest scheduled reports, and samples of the
+// memory store taken with them to measure its growth
+type activityReports struct {
+	mu      sync.Mutex
+	reports []ActivityReport
+	samples []memorySample
+}
+
+func newActivityReports() *activityReports {
+	return &activityReports{}
+}
+
+// add keeps a report, dropping the oldest beyond maxKeptReports
+func (r *activityReports) add(report ActivityReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, report)
+	if extra := len(r.reports) - maxKeptReports; extra > 0 {
+		r.reports = r.reports[extra:]
+	}
+}
+
+// list returns the reports kept, newest first
+func (r *activityReports) list() []ActivityReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reports := make([]ActivityReport, len(r.reports))
+	for i, report := range r.reports {
+		reports[len(r.reports)-1-i] = report
+	}
+	return reports
+}
+
+// sample records the size of the memory store at a time
+func (r *activityReports) sample(at time.Time, total int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples = append(r.samples, memorySample{at: at, total: total})
+	if extra := len(r.samples) - maxMemorySamples; extra > 0 {
+		r.samples = r.samples[extra:]
+	}
+}
+
+// memoryAt returns the latest sample taken at or before a time, else the
+// earliest after it
+func (r *activityReports) memoryAt(at time.Time) (memorySample, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.samples) == 0 {
+		return memorySample{}, false
+	}
+	found := r.samples[0]
+	for _, sample := range r.samples {
+		if sample.at.After(at) {
+			break
+		}
+		found = sample
+	}
+	return found, true
+}
+
+// ActivityReports returns the scheduled reports made lately, newest first
+func (c *Coordinator) ActivityReports() []ActivityReport {
+	return c.reports.list()
+}
+
+// reportPeriodically makes the reports of a schedule and delivers them to
+// every sink. Reports that cannot be delivered are reported as alerts.
+func (c *Coordinator) reportPeriodically(schedule ReportSchedule) {
+	defer c.wg.Done()
+
+	timer := c.clock.NewTimer(schedule.next(c.clock.Now()))
+	defer timer.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case now := <-timer.C():
+			report := c.ActivityReport(now.Add(-schedule.Period.Duration()), now)
+			report.Period = schedule.Period
+			c.reports.add(report)
+			c.reports.sample(now, report.Memory.Total)
+			for _, sink := range c.reportConfig.Sinks {
+				if err := sink.Deliver(c.ctx, report); err != nil {
+					c.timeline.record(TimelineAlert, "reports", "Failed to deliver the "+string(schedule.Period)+" report: "+err.Error(), map[string]interface{}{
+						"severity": "error",
+					})
+				}
+			}
+			timer.Reset(schedule.next(now))
+		}
+	}
+}
+
+// startReports samples the memory store for the first reports and starts
+// the schedules. It is called with c.mu held.
+func (c *Coordinator) startReports() {
+	if len(c.reportConfig.Schedules) == 0 {
+		return
+	}
+	c.reports.sample(c.clock.Now(), c.memoryStore.GetStats().TotalMemories)
+	for _, schedule := range c.reportConfig.Schedules {
+		c.wg.Add(1)
+		go c.reportPeriodically(schedule)
+	}
+}
@@ -0,0 +1,372 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kinds of code analysis findings
+const (
+	FindingComplexity  = "complexity"
+	FindingDuplication = "duplication"
+	FindingTODOs       = "todos"
+)
+
+// Kinds of structural changes
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+const (
+	// maxComplexity is the cyclomatic complexity above which a function is
+	// reported
+	maxComplexity = 10
+	// duplicationWindow is the number of consecutive lines that count as
+	// duplicated when they appear twice
+	duplicationWindow = 6
+	// minDuplicatedLines and minDuplication must both be reached for a file
+	// to be reported as duplicated
+	minDuplicatedLines = 12
+	minDuplication     = 0.1
+	// minTODOs and minTODODensity, per 100 lines, must both be reached for a
+	// file to be reported for its markers
+	minTODOs       = 3
+	minTODODensity = 1.0
+)
+
+// errUnsupportedLanguage is returned by parseSyntax for languages the build
+// cannot parse
+var errUnsupportedLanguage = errors.New("unsupported language")
+
+// sourceLanguages maps the extensions of the files the analyzer reads to
+// their language
+var sourceLanguages = map[string]string{
+	".go":  "go",
+	".js":  "javascript",
+	".jsx": "javascript",
+	".mjs": "javascript",
+	".cjs": "javascript",
+	".ts":  "typescript",
+	".tsx": "tsx",
+	".py":  "python",
+}
+
+// sourceLanguage returns the language of a file, "" if the analyzer does not
+// read it
+func sourceLanguage(path string) string {
+	return sourceLanguages[strings.ToLower(filepath.Ext(path))]
+}
+
+var todoMarker = regexp.MustCompile(`\b(TODO|FIXME|XXX|HACK)\b`)
+
+// FunctionMetrics describes a named function or method
+type FunctionMetrics struct {
+	// Name is qualified with the class or receiver type of methods
+	Name       string `json:"name"`
+	Line       int    `json:"line"`
+	Lines      int    `json:"lines"`
+	Complexity int    `json:"complexity"`
+	// hash identifies the source of the function, ignoring whitespace
+	hash uint64
+}
+
+// FileMetrics describes a source file
+type FileMetrics struct {
+	Path     string `json:"path"`
+	Language string `json:"language"`
+	// Lines counts the lines that are not blank
+	Lines     int               `json:"lines"`
+	Functions []FunctionMetrics `json:"functions,omitempty"`
+	// MaxComplexity is the highest cyclomatic complexity of a function
+	MaxComplexity int `json:"max_complexity"`
+	// TODOs counts TODO, FIXME, XXX and HACK markers, in comments when the
+	// file could be parsed
+	TODOs int `json:"todos"`
+	// TODODensity is the number of markers per 100 lines
+	TODODensity float64 `json:"todo_density"`
+	// DuplicatedLines appear in the same order elsewhere in the analyzed
+	// files
+	DuplicatedLines int     `json:"duplicated_lines"`
+	Duplication     float64 `json:"duplication"`
+}
+
+// Finding is a problem the analyzer reports
+type Finding struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+	Line int    `json:"line,omitempty"`
+	// Symbol is the function of complexity findings
+	Symbol  string `json:"symbol,omitempty"`
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", f.Path, f.Line, f.Message)
+	}
+	return fmt.Sprintf("%s: %s", f.Path, f.Message)
+}
+
+// StructuralChange is a file or function added, removed or modified since
+// the base revision
+type StructuralChange struct {
+	Kind string `json:"kind"`
+	Path string `json:"path"`
+	// Function is empty for changes of whole files
+	Function string `json:"function,omitempty"`
+	// ComplexityBefore and ComplexityAfter are the complexities of the
+	// function, or the highest of the file
+	ComplexityBefore int `json:"complexity_before,omitempty"`
+	ComplexityAfter  int `json:"complexity_after,omitempty"`
+}
+
+func (c StructuralChange) String() string {
+	subject := "file " + c.Path
+	if c.Function != "" {
+		subject = c.Path + ": " + c.Function
+	}
+	switch {
+	case c.Kind == ChangeModified && c.ComplexityBefore != c.ComplexityAfter:
+		return fmt.Sprintf("modified %s (complexity %d -> %d)", subject, c.ComplexityBefore, c.ComplexityAfter)
+	case c.Kind == ChangeAdded && c.Function != "":
+		return fmt.Sprintf("added %s (complexity %d)", subject, c.ComplexityAfter)
+	}
+	return c.Kind + " " + subject
+}
+
+// CodeAnalysis is the outcome of an analysis task
+type CodeAnalysis struct {
+	Files    []FileMetrics      `json:"files"`
+	Findings []Finding          `json:"findings,omitempty"`
+	Changes  []StructuralChange `json:"changes,omitempty"`
+}
+
+// Summary describes the analysis in a few lines
+func (a *CodeAnalysis) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Analyzed %d files: %d findings", len(a.Files), len(a.Findings))
+	if a.Changes != nil {
+		fmt.Fprintf(&b, ", %d structural changes", len(a.Changes))
+	}
+	for _, change := range a.Changes {
+		b.WriteString("\n")
+		b.WriteString(change.String())
+	}
+	for _, finding := range a.Findings {
+		b.WriteString("\n")
+		b.WriteString(finding.String())
+	}
+	return b.String()
+}
+
+// sourceFile is a file to analyze
+type sourceFile struct {
+	Path    string
+	Content []byte
+}
+
+// syntaxInfo is what the analyzer reads from the syntax tree of a file
+type syntaxInfo struct {
+	Functions []FunctionMetrics
+	Comments  []string
+}
+
+// analyzeSources computes the metrics and findings of files. Duplication is
+// measured across all of them.
+func analyzeSources(ctx context.Context, files []sourceFile) (*CodeAnalysis, error) {
+	analysis := &CodeAnalysis{Files: make([]FileMetrics, 0, len(files))}
+	lines := make([][]string, len(files))
+	for i, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		metrics, fileLines, err := analyzeSource(ctx, file)
+		if err != nil {
+			return nil, err
+		}
+		analysis.Files = append(analysis.Files, metrics)
+		lines[i] = fileLines
+	}
+	measureDuplication(analysis.Files, lines)
+
+	for _, file := range analysis.Files {
+		analysis.Findings = append(analysis.Findings, fileFindings(file)...)
+	}
+	return analysis, nil
+}
+
+// analyzeSource computes the metrics of a file but its duplication, and
+// returns its non-blank lines for measuring that
+func analyzeSource(ctx context.Context, file sourceFile) (FileMetrics, []string, error) {
+	metrics := FileMetrics{
+		Path:     file.Path,
+		Language: sourceLanguage(file.Path),
+	}
+	var lines []string
+	for _, line := range strings.Split(string(file.Content), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	metrics.Lines = len(lines)
+
+	syntax, err := parseSyntax(ctx, metrics.Language, file.Content)
+	switch {
+	case errors.Is(err, errUnsupportedLanguage):
+		// Without a syntax tree, markers are counted on every line
+		for _, line := range lines {
+			metrics.TODOs += len(todoMarker.FindAllString(line, -1))
+		}
+	case err != nil:
+		return metrics, nil, fmt.Errorf("failed to parse %s: %w", file.Path, err)
+	default:
+		metrics.Functions = syntax.Functions
+		for _, comment := range syntax.Comments {
+			metrics.TODOs += len(todoMarker.FindAllString(comment, -1))
+		}
+	}
+	for _, function := range metrics.Functions {
+		metrics.MaxComplexity = max(metrics.MaxComplexity, function.Complexity)
+	}
+	if metrics.Lines > 0 {
+		metrics.TODODensity = float64(metrics.TODOs) * 100 / float64(metrics.Lines)
+	}
+	return metrics, lines, nil
+}
+
+// measureDuplication sets the duplicated lines of files: lines in a run of
+// duplicationWindow lines that appears more than once. Short lines, like
+// closing braces, are left out.
+func measureDuplication(files []FileMetrics, lines [][]string) {
+	type location struct{ file, line int }
+	significant := make([][]int, len(files))
+	windows := make(map[uint64][]location)
+	for i := range files {
+		for j, line := range lines[i] {
+			if len(line) >= 4 {
+				significant[i] = append(significant[i], j)
+			}
+		}
+		for start := 0; start+duplicationWindow <= len(significant[i]); start++ {
+			h := fnv.New64a()
+			for _, j := range significant[i][start : start+duplicationWindow] {
+				h.Write([]byte(lines[i][j]))
+				h.Write([]byte{'\n'})
+			}
+			key := h.Sum64()
+			windows[key] = append(windows[key], location{i, start})
+		}
+	}
+
+	duplicated := make([][]bool, len(files))
+	for i := range files {
+		duplicated[i] = make([]bool, len(significant[i]))
+	}
+	for _, locations := range windows {
+		if len(locations) < 2 {
+			continue
+		}
+		for _, loc := range locations {
+			for k := loc.line; k < loc.line+duplicationWindow; k++ {
+				duplicated[loc.file][k] = true
+			}
+		}
+	}
+	for i := range files {
+		for _, dup := range duplicated[i] {
+			if dup {
+				files[i].DuplicatedLines++
+			}
+		}
+		if files[i].Lines > 0 {
+			files[i].Duplication = float64(files[i].DuplicatedLines) / float64(files[i].Lines)
+		}
+	}
+}
+
+// fileFindings returns the problems of a file
+func fileFindings(file FileMetrics) []Finding {
+	var findings []Finding
+	for _, function := range file.Functions {
+		if function.Complexity > maxComplexity {
+			findings = append(findings, Finding{
+				Kind:    FindingComplexity,
+				Path:    file.Path,
+				Line:    function.Line,
+				Symbol:  function.Name,
+				Message: fmt.Sprintf("%s has a cyclomatic complexity of %d", function.Name, function.Complexity),
+			})
+		}
+	}
+	if file.DuplicatedLines >= minDuplicatedLines && file.Duplication >= minDuplication {
+		findings = append(findings, Finding{
+			Kind:    FindingDuplication,
+			Path:    file.Path,
+			Message: fmt.Sprintf("%d lines (%.0f%%) are duplicated", file.DuplicatedLines, file.Duplication*100),
+		})
+	}
+	if file.TODOs >= minTODOs && file.TODODensity >= minTODODensity {
+		findings = append(findings, Finding{
+			Kind:    FindingTODOs,
+			Path:    file.Path,
+			Message: fmt.Sprintf("%d TODO/FIXME markers, %.1f per 100 lines", file.TODOs, file.TODODensity),
+		})
+	}
+	return findings
+}
+
+// compareStructure lists the functions added, removed and modified between
+// two versions of a file. Either may be nil for added and removed files.
+func compareStructure(path string, before, after *FileMetrics) []StructuralChange {
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		return []StructuralChange{{Kind: ChangeAdded, Path: path, ComplexityAfter: after.MaxComplexity}}
+	case after == nil:
+		return []StructuralChange{{Kind: ChangeRemoved, Path: path, ComplexityBefore: before.MaxComplexity}}
+	}
+
+	old := make(map[string]FunctionMetrics, len(before.Functions))
+	for _, function := range before.Functions {
+		old[function.Name] = function
+	}
+	var changes []StructuralChange
+	seen := make(map[string]bool, len(after.Functions))
+	for _, function := range after.Functions {
+		seen[function.Name] = true
+		previous, existed := old[function.Name]
+		switch {
+		case !existed:
+			changes = append(changes, StructuralChange{Kind: ChangeAdded, Path: path, Function: function.Name, ComplexityAfter: function.Complexity})
+		case previous.hash != function.hash:
+			changes = append(changes, StructuralChange{Kind: ChangeModified, Path: path, Function: function.Name, ComplexityBefore: previous.Complexity, ComplexityAfter: function.Complexity})
+		}
+	}
+	var removed []StructuralChange
+	for _, function := range before.Functions {
+		if !seen[function.Name] {
+			removed = append(removed, StructuralChange{Kind: ChangeRemoved, Path: path, Function: function.Name, ComplexityBefore: function.Complexity})
+		}
+	}
+	sort.SliceStable(removed, func(i, j int) bool { return removed[i].Function < removed[j].Function })
+	return append(changes, removed...)
+}
+
+// functionHash identifies the source of a function, ignoring whitespace
+func functionHash(source string) uint64 {
+	h := fnv.New64a()
+	for _, field := range strings.Fields(source) {
+		h.Write([]byte(field))
+		h.Write([]byte{' '})
+	}
+	return h.Sum64()
+}
@@ -0,0 +1,258 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Task types the analyzer handles without a model
+const (
+	// TaskTypeCodeAnalysis reports the metrics and findings of files
+	TaskTypeCodeAnalysis = "code_analysis"
+	// TaskTypeStructuralChanges reports the functions added, removed and
+	// modified since a revision, along with the analysis of the files
+	TaskTypeStructuralChanges = "structural_changes"
+)
+
+const (
+	// maxAnalyzedFiles and maxAnalyzedFileSize bound the work of a task
+	maxAnalyzedFiles    = 200
+	maxAnalyzedFileSize = 1 << 20
+)
+
+// ErrNoFilesToAnalyze is returned for analysis tasks without files, given or
+// changed
+var ErrNoFilesToAnalyze = errors.New("no files to analyze")
+
+// AnalyzerAgent analyzes source code with tree-sitter. It answers
+// code_analysis and structural_changes tasks; with a provider configured it
+// also prompts its model for every other task it accepts, like a ModelAgent.
+//
+// Tasks analyze the files of their "files" input or, without one, the files
+// changed since the "base" revision, HEAD by default. CustomConfig may set
+// "dir", the repository, the working directory by default.
+type AnalyzerAgent struct {
+	*ModelAgent
+	dir string
+}
+
+func init() {
+	RegisterFactory(AgentTypeAnalyzer, func(config AgentConfig) (Agent, error) {
+		return NewAnalyzerAgent(config)
+	})
+}
+
+// NewAnalyzerAgent creates an analyzer, backed by a model if its
+// configuration has a provider
+func NewAnalyzerAgent(config AgentConfig) (*AnalyzerAgent, error) {
+	dir := customString(config, "dir")
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("agent %s: %w", config.ID, err)
+	}
+
+	var model *ModelAgent
+	if config.ProviderType != "" {
+		if model, err = NewModelAgent(config); err != nil {
+			return nil, err
+		}
+	} else {
+		if config.MaxConcurrency <= 0 {
+			config.MaxConcurrency = 1
+		}
+		base := NewBaseAgent(config)
+		model = &ModelAgent{BaseAgent: base, slots: newTaskSlots(base)}
+	}
+	return &AnalyzerAgent{ModelAgent: model, dir: dir}, nil
+}
+
+// CanHandleTask accepts analysis tasks, and the tasks of a ModelAgent when
+// the analyzer has a model
+func (a *AnalyzerAgent) CanHandleTask(task Task) bool {
+	if task.Type == TaskTypeCodeAnalysis || task.Type == TaskTypeStructuralChanges {
+		return true
+	}
+	return a.client != nil && a.ModelAgent.CanHandleTask(task)
+}
+
+// ExecuteTask analyzes code or prompts the model. The results of analysis
+// tasks have the *CodeAnalysis as their "analysis" output and a summary of
+// it as their "response".
+func (a *AnalyzerAgent) ExecuteTask(ctx context.Context, task Task) (*TaskResult, error) {
+	if task.Type != TaskTypeCodeAnalysis && task.Type != TaskTypeStructuralChanges {
+		if a.client == nil {
+			return nil, fmt.Errorf("agent %s: no model for %s tasks", a.id, task.Type)
+		}
+		return a.ModelAgent.ExecuteTask(ctx, task)
+	}
+
+	if !a.slots.acquire() {
+		return nil, fmt.Errorf("%w: %s is running %d tasks", ErrAgentBusy, a.id, a.maxConcurrency())
+	}
+	defer a.slots.release()
+
+	start := time.Now()
+	result := &TaskResult{
+		TaskID:  task.ID,
+		AgentID: a.id,
+	}
+	analysis, err := a.analyze(ctx, task)
+	result.ExecutionTime = time.Since(start)
+	result.CompletedAt = time.Now()
+	a.RecordTask(result.ExecutionTime, err == nil)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	result.Success = true
+	result.Output = map[string]interface{}{
+		"response": analysis.Summary(),
+		"analysis": analysis,
+	}
+	result.Metadata = map[string]interface{}{
+		"files":    len(analysis.Files),
+		"findings": len(analysis.Findings),
+	}
+	return result, nil
+}
+
+func (a *AnalyzerAgent) analyze(ctx context.Context, task Task) (*CodeAnalysis, error) {
+	base, _ := task.Input["base"].(string)
+	if base == "" {
+		base = "HEAD"
+	}
+	paths, err := a.taskFiles(ctx, task, base)
+	if err != nil {
+		return nil, err
+	}
+
+	current := a.readFiles(paths, func(path string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(a.dir, path))
+	})
+	analysis, err := analyzeSources(ctx, current)
+	if err != nil {
+		return nil, err
+	}
+	if task.Type != TaskTypeStructuralChanges {
+		return analysis, nil
+	}
+
+	previous := a.readFiles(paths, func(path string) ([]byte, error) {
+		return a.git(ctx, "show", base+":./"+filepath.ToSlash(path))
+	})
+	before, err := analyzeSources(ctx, previous)
+	if err != nil {
+		return nil, err
+	}
+	byPath := func(files []FileMetrics) map[string]*FileMetrics {
+		m := make(map[string]*FileMetrics, len(files))
+		for i := range files {
+			m[files[i].Path] = &files[i]
+		}
+		return m
+	}
+	oldFiles, newFiles := byPath(before.Files), byPath(analysis.Files)
+	analysis.Changes = []StructuralChange{}
+	for _, path := range paths {
+		analysis.Changes = append(analysis.Changes, compareStructure(path, oldFiles[path], newFiles[path])...)
+	}
+	return analysis, nil
+}
+
+// taskFiles returns the files of the task input, or the source files
+// changed since base, relative to the repository
+func (a *AnalyzerAgent) taskFiles(ctx context.Context, task Task, base string) ([]string, error) {
+	var paths []string
+	switch files := task.Input["files"].(type) {
+	case []string:
+		paths = files
+	case []interface{}:
+		for _, file := range files {
+			if path, ok := file.(string); ok {
+				paths = append(paths, path)
+			}
+		}
+	case string:
+		paths = []string{files}
+	}
+	if path, ok := task.Input["path"].(string); ok && path != "" {
+		paths = append(paths, path)
+	}
+
+	if len(paths) == 0 {
+		changed, err := a.git(ctx, "diff", "--name-only", "--relative", base, "--")
+		if err != nil {
+			return nil, err
+		}
+		untracked, err := a.git(ctx, "ls-files", "--others", "--exclude-standard")
+		if err != nil {
+			return nil, err
+		}
+		paths = strings.Fields(string(changed) + "\n" + string(untracked))
+	}
+
+	seen := make(map[string]bool, len(paths))
+	var files []string
+	for _, path := range paths {
+		if filepath.IsAbs(path) {
+			if rel, err := filepath.Rel(a.dir, path); err == nil {
+				path = rel
+			}
+		}
+		path = filepath.Clean(path)
+		if seen[path] || sourceLanguage(path) == "" || strings.HasPrefix(path, "..") {
+			continue
+		}
+		seen[path] = true
+		files = append(files, path)
+	}
+	if len(files) == 0 {
+		return nil, ErrNoFilesToAnalyze
+	}
+	sort.Strings(files)
+	if len(files) > maxAnalyzedFiles {
+		files = files[:maxAnalyzedFiles]
+	}
+	return files, nil
+}
+
+// readFiles reads the files that exist and are not too large
+func (a *AnalyzerAgent) readFiles(paths []string, read func(path string) ([]byte, error)) []sourceFile {
+	var files []sourceFile
+	for _, path := range paths {
+		content, err := read(path)
+		if err != nil || len(content) > maxAnalyzedFileSize {
+			continue
+		}
+		files = append(files, sourceFile{Path: path, Content: content})
+	}
+	return files
+}
+
+// git runs a git command in the repository and returns its output
+func (a *AnalyzerAgent) git(ctx context.Context, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = a.dir
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
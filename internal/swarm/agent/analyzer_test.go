@@ -0,0 +1,190 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const complexGo = `package m
+
+// TODO: split this up
+// FIXME: handle negative sizes
+// XXX: not thread safe
+func classify(n int, flags []string) string {
+	for _, flag := range flags {
+		if flag == "strict" && n < 0 {
+			return "invalid"
+		}
+	}
+	switch {
+	case n == 0:
+		return "zero"
+	case n < 10 || n > 100:
+		return "odd"
+	case n%2 == 0:
+		return "even"
+	}
+	if n > 50 {
+		if n > 75 {
+			return "large"
+		}
+	}
+	select {
+	case <-make(chan int):
+	default:
+	}
+	return "other"
+}
+
+func (s *Set[T]) Len() int { return len(s.items) }
+`
+
+func TestAnalyzeSources(t *testing.T) {
+	duplicated := strings.Repeat("\tfirst := compute(alpha)\n\tsecond := compute(beta)\n\tthird := compute(gamma)\n", 2)
+	block := "func a() {\n" + duplicated + "}\n"
+	files := []sourceFile{
+		{Path: "complex.go", Content: []byte(complexGo)},
+		{Path: "copy1.go", Content: []byte("package m\n\n" + block)},
+		{Path: "copy2.go", Content: []byte("package m\n\n" + strings.Replace(block, "func a", "func b", 1))},
+	}
+	analysis, err := analyzeSources(context.Background(), files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := analysis.Files[0]
+	want := []FunctionMetrics{
+		{Name: "classify", Line: 6, Lines: 25, Complexity: 11},
+		{Name: "Set.Len", Line: 32, Lines: 1, Complexity: 1},
+	}
+	for i := range file.Functions {
+		file.Functions[i].hash = 0
+	}
+	if !reflect.DeepEqual(file.Functions, want) {
+		t.Errorf("functions = %+v, want %+v", file.Functions, want)
+	}
+	if file.TODOs != 3 || file.MaxComplexity != 11 || file.DuplicatedLines != 0 {
+		t.Errorf("metrics = %+v", file)
+	}
+	if analysis.Files[1].DuplicatedLines != 6 || analysis.Files[2].DuplicatedLines != 6 {
+		t.Errorf("duplicated lines = %d and %d, want 6", analysis.Files[1].DuplicatedLines, analysis.Files[2].DuplicatedLines)
+	}
+
+	var findings []string
+	for _, finding := range analysis.Findings {
+		findings = append(findings, finding.String())
+	}
+	wantFindings := []string{
+		"complex.go:6: classify has a cyclomatic complexity of 11",
+		"complex.go: 3 TODO/FIXME markers, 10.0 per 100 lines",
+	}
+	if !reflect.DeepEqual(findings, wantFindings) {
+		t.Errorf("findings = %q, want %q", findings, wantFindings)
+	}
+}
+
+func TestCompareStructure(t *testing.T) {
+	before := &FileMetrics{Functions: []FunctionMetrics{
+		{Name: "kept", Complexity: 1, hash: 1},
+		{Name: "changed", Complexity: 2, hash: 2},
+		{Name: "removed", Complexity: 3, hash: 3},
+	}}
+	after := &FileMetrics{MaxComplexity: 5, Functions: []FunctionMetrics{
+		{Name: "added", Complexity: 4, hash: 4},
+		{Name: "kept", Complexity: 1, hash: 1},
+		{Name: "changed", Complexity: 5, hash: 5},
+	}}
+
+	var changes []string
+	for _, change := range compareStructure("m.go", before, after) {
+		changes = append(changes, change.String())
+	}
+	want := []string{
+		"added m.go: added (complexity 4)",
+		"modified m.go: changed (complexity 2 -> 5)",
+		"removed m.go: removed",
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %q, want %q", changes, want)
+	}
+	if got := compareStructure("m.go", nil, after); len(got) != 1 || got[0].String() != "added file m.go" {
+		t.Errorf("changes of a new file = %v", got)
+	}
+}
+
+func TestAnalyzerAgent(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	write("m.go", "package m\n\nfunc kept() {}\n\nfunc changed() {}\n\nfunc removed() {}\n")
+	write("README.md", "# m\n")
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	write("m.go", "package m\n\nfunc kept() {}\n\nfunc changed(ok bool) {\n\tif ok {\n\t}\n}\n\nfunc added() {}\n")
+	write("new.go", "package m\n")
+	write("README.md", "# m\n\nChanged\n")
+
+	ag, err := New(AgentConfig{ID: "analyzer", Type: AgentTypeAnalyzer, CustomConfig: map[string]interface{}{"dir": dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ag.CanHandleTask(Task{Type: TaskTypeStructuralChanges}) || ag.CanHandleTask(Task{Type: "analysis"}) {
+		t.Error("an analyzer without a model should handle analysis tasks only")
+	}
+
+	result, err := ag.ExecuteTask(context.Background(), Task{ID: "t1", Type: TaskTypeStructuralChanges})
+	if err != nil {
+		t.Fatal(err)
+	}
+	analysis, ok := result.Output["analysis"].(*CodeAnalysis)
+	if !result.Success || !ok {
+		t.Fatalf("result = %+v", result)
+	}
+	var changes []string
+	for _, change := range analysis.Changes {
+		changes = append(changes, change.String())
+	}
+	want := []string{
+		"modified m.go: changed (complexity 1 -> 2)",
+		"added m.go: added (complexity 1)",
+		"removed m.go: removed",
+		"added file new.go",
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %q, want %q", changes, want)
+	}
+	if len(analysis.Files) != 2 {
+		t.Errorf("analyzed %d files, want the 2 changed Go files", len(analysis.Files))
+	}
+
+	// Given files are analyzed whether they changed or not
+	result, err = ag.ExecuteTask(context.Background(), Task{ID: "t2", Type: TaskTypeCodeAnalysis, Input: map[string]interface{}{"files": []interface{}{"m.go"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if analysis := result.Output["analysis"].(*CodeAnalysis); len(analysis.Files) != 1 || analysis.Changes != nil {
+		t.Errorf("analysis = %+v", analysis)
+	}
+}
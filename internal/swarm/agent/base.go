@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
 )
 
 // BaseAgent provides common functionality for all agent implementations
@@ -16,6 +17,8 @@ type BaseAgent struct {
 	status       AgentStatus
 	capabilities []string
 	config       AgentConfig
+	// configMu guards the fields Reconfigure changes while the agent runs
+	configMu     sync.RWMutex
 	
 	// Communication
 	incomingMessages chan Message
@@ -27,6 +30,18 @@ type BaseAgent struct {
 	healthScore  float64
 	startTime    time.Time
 	
+	// Files kept between tasks
+	scratch *Scratchpad
+	
+	// pings wait for the answers to health checks, by message ID
+	pings   map[string]chan AgentMetrics
+	pingsMu sync.Mutex
+	
+	// heartbeat receives the heartbeats of the agent, see SetHeartbeatSink
+	heartbeat func(Message)
+	// clock times the heartbeats
+	clock clock.Clock
+	
 	// Lifecycle
 	ctx        context.Context
 	cancelFunc context.CancelFunc
@@ -44,6 +59,16 @@ func NewBaseAgent(config AgentConfig) *BaseAgent {
 		config.MessageBufferSize = 100
 	}
 	
+	scratchDir := config.ScratchDir
+	if scratchDir == "" {
+		scratchDir = ScratchDir(DefaultScratchRoot(), config.ID)
+	}
+	
+	clk := config.Clock
+	if clk == nil {
+		clk = clock.Real
+	}
+	
 	return &BaseAgent{
 		id:               config.ID,
 		agentType:        config.Type,
@@ -53,6 +78,9 @@ func NewBaseAgent(config AgentConfig) *BaseAgent {
 		incomingMessages: make(chan Message, config.MessageBufferSize),
 		outgoingMessages: make(chan Message, config.MessageBufferSize),
 		healthScore:      1.0,
+		scratch:          NewScratchpad(scratchDir, config.ScratchRetention),
+		pings:            make(map[string]chan AgentMetrics),
+		clock:            clk,
 		metrics: AgentMetrics{
 			TasksCompleted:   0,
 			TasksFailed:      0,
@@ -72,7 +100,11 @@ func (a *BaseAgent) Start(ctx context.Context) error {
 	defer a.statusMutex.Unlock()
 	
 	if a.status != AgentStatusStopped {
-		return fmt.Errorf("agent %s is already running", a.id)
+		return fmt.Errorf("%w: %s", ErrAgentRunning, a.id)
+	}
+	
+	if err := a.scratch.create(); err != nil {
+		return fmt.Errorf("creating scratchpad of %s: %w", a.id, err)
 	}
 	
 	a.status = AgentStatusStarting
@@ -89,6 +121,11 @@ func (a *BaseAgent) Start(ctx context.Context) error {
 		go a.monitorHealth()
 	}
 	
+	if a.config.HeartbeatInterval > 0 {
+		a.wg.Add(1)
+		go a.sendHeartbeats()
+	}
+	
 	a.status = AgentStatusIdle
 	return nil
 }
@@ -114,9 +151,19 @@ func (a *BaseAgent) Stop() error {
 	// Wait for goroutines to finish
 	a.wg.Wait()
 	
+	// Collect the files past their retention
+	if _, err := a.scratch.collect(time.Now()); err != nil {
+		return fmt.Errorf("collecting scratchpad of %s: %w", a.id, err)
+	}
+	
 	return nil
 }
 
+// Scratchpad returns the directory the agent keeps its files in
+func (a *BaseAgent) Scratchpad() *Scratchpad {
+	return a.scratch
+}
+
 // GetStatus returns the current agent status
 func (a *BaseAgent) GetStatus() AgentStatus {
 	a.statusMutex.RLock()
@@ -143,9 +190,18 @@ func (a *BaseAgent) GetType() AgentType {
 
 // GetCapabilities returns the agent's capabilities
 func (a *BaseAgent) GetCapabilities() []string {
+	a.configMu.RLock()
+	defer a.configMu.RUnlock()
 	return a.capabilities
 }
 
+// maxConcurrency returns how many tasks the agent runs at once
+func (a *BaseAgent) maxConcurrency() int {
+	a.configMu.RLock()
+	defer a.configMu.RUnlock()
+	return a.config.MaxConcurrency
+}
+
 // SendMessage sends a message from this agent
 func (a *BaseAgent) SendMessage(msg Message) error {
 	if msg.From == "" {
@@ -155,14 +211,21 @@ func (a *BaseAgent) SendMessage(msg Message) error {
 		msg.Timestamp = time.Now()
 	}
 	
+	a.statusMutex.RLock()
+	ctx := a.ctx
+	a.statusMutex.RUnlock()
+	if ctx == nil || ctx.Err() != nil {
+		return fmt.Errorf("%w: %s", ErrAgentNotRunning, a.id)
+	}
+	
 	select {
 	case a.outgoingMessages <- msg:
 		a.incrementMessagesSent()
 		return nil
-	case <-a.ctx.Done():
-		return fmt.Errorf("agent context cancelled")
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %s", ErrAgentNotRunning, a.id)
 	default:
-		return fmt.Errorf("outgoing message buffer full")
+		return fmt.Errorf("%w: %s: outgoing message buffer full", ErrAgentBusy, a.id)
 	}
 }
 
@@ -180,12 +243,16 @@ func (a *BaseAgent) GetHealthScore() float64 {
 
 // GetMetrics returns the agent's metrics
 func (a *BaseAgent) GetMetrics() AgentMetrics {
+	a.statusMutex.RLock()
+	startTime := a.startTime
+	a.statusMutex.RUnlock()
+	
 	a.metricsMutex.RLock()
 	defer a.metricsMutex.RUnlock()
 	
 	// Update uptime
 	metrics := a.metrics
-	metrics.UptimeSeconds = int64(time.Since(a.startTime).Seconds())
+	metrics.UptimeSeconds = int64(time.Since(startTime).Seconds())
 	
 	return metrics
 }
@@ -222,12 +289,20 @@ func (a *BaseAgent) handleMessage(msg Message) {
 
 // respondToHealthCheck sends health information
 func (a *BaseAgent) respondToHealthCheck(msg Message) {
+	metrics := a.GetMetrics()
+	a.pingsMu.Lock()
+	if answer, ok := a.pings[msg.ID]; ok {
+		answer <- metrics
+		delete(a.pings, msg.ID)
+	}
+	a.pingsMu.Unlock()
+	
 	response := Message{
 		ID:        uuid.New().String(),
 		From:      a.id,
 		To:        msg.From,
 		Type:      MessageTypeStatusUpdate,
-		Content:   a.GetMetrics(),
+		Content:   metrics,
 		Timestamp: time.Now(),
 		ReplyTo:   msg.ID,
 	}
@@ -235,6 +310,103 @@ func (a *BaseAgent) respondToHealthCheck(msg Message) {
 	_ = a.SendMessage(response)
 }
 
+// Pinger is implemented by agents that answer health checks, e.g. those
+// embedding BaseAgent. The coordinator pings the agents of tasks it
+// suspects of being stuck.
+type Pinger interface {
+	Ping(ctx context.Context) (AgentMetrics, error)
+}
+
+// Ping sends the agent a health check message and waits until its message
+// loop answered with the metrics of the agent
+func (a *BaseAgent) Ping(ctx context.Context) (AgentMetrics, error) {
+	msg := Message{
+		ID:        uuid.New().String(),
+		From:      "coordinator",
+		To:        a.id,
+		Type:      MessageTypeHealthCheck,
+		Timestamp: time.Now(),
+	}
+	answer := make(chan AgentMetrics, 1)
+	a.pingsMu.Lock()
+	a.pings[msg.ID] = answer
+	a.pingsMu.Unlock()
+	defer func() {
+		a.pingsMu.Lock()
+		delete(a.pings, msg.ID)
+		a.pingsMu.Unlock()
+	}()
+	
+	// Stop closes the channel only after marking the agent stopped
+	a.statusMutex.RLock()
+	if a.status == AgentStatusStopped || a.ctx == nil {
+		a.statusMutex.RUnlock()
+		return AgentMetrics{}, fmt.Errorf("%w: %s", ErrAgentNotRunning, a.id)
+	}
+	agentCtx := a.ctx
+	select {
+	case a.incomingMessages <- msg:
+	default:
+		a.statusMutex.RUnlock()
+		return AgentMetrics{}, fmt.Errorf("%w: %s: incoming message buffer full", ErrAgentBusy, a.id)
+	}
+	a.statusMutex.RUnlock()
+	
+	select {
+	case metrics := <-answer:
+		return metrics, nil
+	case <-agentCtx.Done():
+		return AgentMetrics{}, fmt.Errorf("%w: %s", ErrAgentNotRunning, a.id)
+	case <-ctx.Done():
+		return AgentMetrics{}, ctx.Err()
+	}
+}
+
+// HeartbeatSender is implemented by agents that send heartbeats, e.g. those
+// embedding BaseAgent. The registry receives the heartbeats of the agents
+// registered with it.
+type HeartbeatSender interface {
+	SetHeartbeatSink(sink func(Message))
+}
+
+// SetHeartbeatSink makes the agent send its heartbeats to sink, every
+// HeartbeatInterval while it runs
+func (a *BaseAgent) SetHeartbeatSink(sink func(Message)) {
+	a.statusMutex.Lock()
+	defer a.statusMutex.Unlock()
+	a.heartbeat = sink
+}
+
+// sendHeartbeats sends a heartbeat with the metrics of the agent when it
+// starts and every HeartbeatInterval after
+func (a *BaseAgent) sendHeartbeats() {
+	defer a.wg.Done()
+	
+	ticker := a.clock.NewTicker(a.config.HeartbeatInterval)
+	defer ticker.Stop()
+	
+	for {
+		a.statusMutex.RLock()
+		sink := a.heartbeat
+		a.statusMutex.RUnlock()
+		if sink != nil {
+			sink(Message{
+				ID:        uuid.New().String(),
+				From:      a.id,
+				Type:      MessageTypeHeartbeat,
+				Content:   a.GetMetrics(),
+				Timestamp: a.clock.Now(),
+			})
+		}
+		
+		select {
+		case <-ticker.C():
+		case <-a.ctx.Done():
+			return
+		}
+	}
+}
+
 // monitorHealth periodically checks agent health
 func (a *BaseAgent) monitorHealth() {
 	defer a.wg.Done()
@@ -273,6 +445,18 @@ func (a *BaseAgent) updateHealthScore() {
 	// - Recent activity
 }
 
+// RecordTask updates the task metrics after a task finished, for agents
+// built on BaseAgent
+func (a *BaseAgent) RecordTask(duration time.Duration, success bool) {
+	if success {
+		a.incrementTasksCompleted()
+	} else {
+		a.incrementTasksFailed()
+		a.incrementErrorCount()
+	}
+	a.updateAverageTaskTime(duration)
+}
+
 // Metric update helpers
 func (a *BaseAgent) incrementTasksCompleted() {
 	a.metricsMutex.Lock()
@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShutdownWhileMessaging(t *testing.T) {
+	a := NewBaseAgent(AgentConfig{ID: "agent", Type: AgentType("test"), HealthCheckInterval: time.Millisecond, MessageBufferSize: 1})
+
+	// Sending before Start fails instead of panicking
+	if err := a.SendMessage(Message{Type: MessageTypeBroadcast}); err == nil {
+		t.Error("SendMessage succeeded before Start")
+	}
+
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				_ = a.SendMessage(Message{Type: MessageTypeBroadcast})
+				_ = a.GetMetrics()
+				_ = a.GetHealthScore()
+				_ = a.GetStatus()
+				a.RecordTask(time.Millisecond, true)
+				ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+				_, _ = a.Ping(ctx)
+				cancel()
+			}
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	var stops sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		stops.Add(1)
+		go func() {
+			defer stops.Done()
+			if err := a.Stop(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	stops.Wait()
+	close(stop)
+	wg.Wait()
+
+	if err := a.SendMessage(Message{Type: MessageTypeBroadcast}); err == nil {
+		t.Error("SendMessage succeeded after Stop")
+	}
+}
+
+func TestPing(t *testing.T) {
+	a := NewBaseAgent(AgentConfig{ID: "agent", Type: AgentType("test")})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := a.Ping(ctx); !errors.Is(err, ErrAgentNotRunning) {
+		t.Fatalf("Ping before Start = %v, want ErrAgentNotRunning", err)
+	}
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	a.RecordTask(time.Millisecond, true)
+	metrics, err := a.Ping(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.TasksCompleted != 1 {
+		t.Errorf("Ping answered %d completed tasks, want 1", metrics.TasksCompleted)
+	}
+	if err := a.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Ping(ctx); !errors.Is(err, ErrAgentNotRunning) {
+		t.Errorf("Ping after Stop = %v, want ErrAgentNotRunning", err)
+	}
+}
@@ -0,0 +1,317 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/artifact"
+	"github.com/opencode-ai/opencode/internal/swarm/provider"
+)
+
+const (
+	// maxDocumentedFiles and maxDocumentedFileSize bound the work of a task,
+	// every file is a prompt with its whole content
+	maxDocumentedFiles    = 20
+	maxDocumentedFileSize = 128 << 10
+	// noDocChanges is the answer of the model for files that need no change
+	noDocChanges = "NO_CHANGES"
+)
+
+const documentationPrompt = `You are the documentation agent of a multi-agent software engineering swarm. You write and update documentation: the doc comments of source files and markdown documents.
+
+Rules:
+- Only change comments and documentation, never code
+- Keep the style, language and register of the existing documentation
+- Document what the code does and why, not how, and keep comments short
+- Answer with the complete updated file in a single fenced code block, or with ` + noDocChanges + ` if the file needs no change`
+
+// ErrNoFilesToDocument is returned for documentation tasks without files,
+// given or in their diff
+var ErrNoFilesToDocument = errors.New("no files to document")
+
+// DocumentationAgent drafts doc comments and markdown documentation with
+// its model. It answers every task a ModelAgent of its configuration would,
+// returning the files it would change as a proposed Patch; it never writes
+// them.
+//
+// Tasks document the files of their "files" input or, without one, the
+// files changed in their "diff" input, a unified diff. The diff is also
+// shown to the model, to update the documentation of what changed.
+// CustomConfig may set "dir", the directory relative paths are in, the
+// working directory by default.
+type DocumentationAgent struct {
+	*ModelAgent
+	dir string
+}
+
+// NewDocumentationAgent creates a documentation agent. It needs a provider.
+func NewDocumentationAgent(config AgentConfig) (*DocumentationAgent, error) {
+	config = documentationConfig(config)
+	dir := customString(config, "dir")
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("agent %s: %w", config.ID, err)
+	}
+	model, err := NewModelAgent(config)
+	if err != nil {
+		return nil, err
+	}
+	return &DocumentationAgent{ModelAgent: model, dir: dir}, nil
+}
+
+// documentationConfig gives a configuration without a system prompt the
+// prompt of documentation agents
+func documentationConfig(config AgentConfig) AgentConfig {
+	if customString(config, "systemPrompt") != "" {
+		return config
+	}
+	custom := make(map[string]interface{}, len(config.CustomConfig)+1)
+	for key, value := range config.CustomConfig {
+		custom[key] = value
+	}
+	custom["systemPrompt"] = documentationPrompt
+	config.CustomConfig = custom
+	return config
+}
+
+// Reconfigure reconfigures the agent as a ModelAgent, with the prompt of
+// documentation agents unless config has its own
+func (a *DocumentationAgent) Reconfigure(config AgentConfig) ([]string, error) {
+	return a.ModelAgent.Reconfigure(documentationConfig(config))
+}
+
+// RequiredPermissions lets only agents that may write files propose edits
+func (a *DocumentationAgent) RequiredPermissions(task Task) []Permission {
+	return []Permission{PermissionWriteFiles}
+}
+
+// ExecuteTask prompts the model for every file of the task. The result has
+// the *Patch of the changed files as its "patch" output and a summary of
+// them as its "response". The diff of the patch is attached as an artifact.
+func (a *DocumentationAgent) ExecuteTask(ctx context.Context, task Task) (*TaskResult, error) {
+	if !a.slots.acquire() {
+		return nil, fmt.Errorf("%w: %s is running %d tasks", ErrAgentBusy, a.id, a.maxConcurrency())
+	}
+	defer a.slots.release()
+
+	start := time.Now()
+	result := &TaskResult{
+		TaskID:  task.ID,
+		AgentID: a.id,
+	}
+	edits, summary, usage, err := a.document(ctx, task)
+	result.ExecutionTime = time.Since(start)
+	result.CompletedAt = time.Now()
+	a.RecordTask(result.ExecutionTime, err == nil)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	result.Success = true
+	patch := NewPatch(a.dir, edits)
+	result.Output = map[string]interface{}{
+		"response":  summary,
+		OutputPatch: patch,
+	}
+	if len(edits) > 0 {
+		result.Artifacts = []Artifact{{Name: "edits.diff", Kind: artifact.KindDiff, Data: []byte(patch.Diff)}}
+	}
+	result.Metadata = map[string]interface{}{
+		"model":         a.client.Model(),
+		"edits":         len(edits),
+		"input_tokens":  usage.InputTokens,
+		"output_tokens": usage.OutputTokens,
+	}
+	return result, nil
+}
+
+// document drafts the edits of a task and summarizes them
+func (a *DocumentationAgent) document(ctx context.Context, task Task) ([]FileEdit, string, provider.Response, error) {
+	var usage provider.Response
+	diff, _ := task.Input["diff"].(string)
+	paths := a.taskFiles(task, diff)
+	if len(paths) == 0 {
+		return nil, "", usage, ErrNoFilesToDocument
+	}
+
+	edits := []FileEdit{}
+	var summary strings.Builder
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil || len(content) > maxDocumentedFileSize {
+			fmt.Fprintf(&summary, "- %s: skipped, %s\n", a.rel(path), skipReason(err))
+			continue
+		}
+		resp, err := a.client.Complete(ctx, provider.Request{
+			System: a.systemPrompt,
+			Prompt: documentationTaskPrompt(task, a.rel(path), string(content), diff),
+		})
+		if err != nil {
+			return nil, "", usage, fmt.Errorf("%s: %w", a.rel(path), err)
+		}
+		usage.InputTokens += resp.InputTokens
+		usage.OutputTokens += resp.OutputTokens
+
+		proposed, ok := proposedContent(resp.Content, string(content))
+		switch {
+		case !ok:
+			fmt.Fprintf(&summary, "- %s: skipped, the model answered without the file\n", a.rel(path))
+		case proposed == string(content):
+			fmt.Fprintf(&summary, "- %s: no changes needed\n", a.rel(path))
+		case filepath.Ext(path) == ".go" && !sameGoCode(content, []byte(proposed)):
+			fmt.Fprintf(&summary, "- %s: skipped, the model changed code\n", a.rel(path))
+		default:
+			edits = append(edits, FileEdit{Path: path, Original: string(content), Proposed: proposed})
+			fmt.Fprintf(&summary, "- %s: documentation updated\n", a.rel(path))
+		}
+	}
+	return edits, fmt.Sprintf("Proposed documentation edits to %d of %d files:\n%s", len(edits), len(paths), summary.String()), usage, nil
+}
+
+// taskFiles returns the absolute paths of the files of the task input, or
+// of the files changed in its diff
+func (a *DocumentationAgent) taskFiles(task Task, diff string) []string {
+	var paths []string
+	switch files := task.Input["files"].(type) {
+	case []string:
+		paths = files
+	case []interface{}:
+		for _, file := range files {
+			if path, ok := file.(string); ok {
+				paths = append(paths, path)
+			}
+		}
+	case string:
+		paths = []string{files}
+	}
+	if len(paths) == 0 {
+		paths = diffFiles(diff)
+	}
+
+	seen := make(map[string]bool, len(paths))
+	var files []string
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(a.dir, path)
+		}
+		path = filepath.Clean(path)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	if len(files) > maxDocumentedFiles {
+		files = files[:maxDocumentedFiles]
+	}
+	return files
+}
+
+// rel returns path relative to the agent directory, for prompts and
+// summaries
+func (a *DocumentationAgent) rel(path string) string {
+	return relativeTo(a.dir, path)
+}
+
+func skipReason(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("larger than %d KB", maxDocumentedFileSize>>10)
+}
+
+// documentationTaskPrompt asks for the documentation of a file
+func documentationTaskPrompt(task Task, path, content, diff string) string {
+	var prompt strings.Builder
+	if task.Description != "" {
+		fmt.Fprintf(&prompt, "Task: %s\n\n", task.Description)
+	}
+	if diff != "" {
+		fmt.Fprintf(&prompt, "Update the documentation for these changes:\n```diff\n%s\n```\n\n", strings.TrimRight(diff, "\n"))
+	}
+	fmt.Fprintf(&prompt, "File %s:\n```\n%s\n```", path, strings.TrimRight(content, "\n"))
+	return prompt.String()
+}
+
+// diffFiles returns the files a unified diff changes, skipping deleted ones
+func diffFiles(diff string) []string {
+	var files []string
+	for _, line := range strings.Split(diff, "\n") {
+		path, ok := strings.CutPrefix(line, "+++ ")
+		if !ok {
+			continue
+		}
+		path, _, _ = strings.Cut(path, "\t")
+		if path == "/dev/null" {
+			continue
+		}
+		files = append(files, strings.TrimPrefix(path, "b/"))
+	}
+	return files
+}
+
+// proposedContent returns the file in the fenced block of an answer. The
+// block ends at the last fence, markdown files may contain fences of their
+// own. It reports false if the answer has no block and is not noDocChanges.
+func proposedContent(answer, original string) (string, bool) {
+	answer = strings.TrimSpace(answer)
+	if answer == noDocChanges {
+		return original, true
+	}
+	lines := strings.Split(answer, "\n")
+	open, end := -1, -1
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if open < 0 {
+				open = i
+			} else {
+				end = i
+			}
+		}
+	}
+	if open < 0 || end < 0 {
+		return "", false
+	}
+	content := strings.Join(lines[open+1:end], "\n")
+	if strings.HasSuffix(original, "\n") {
+		content += "\n"
+	}
+	return content, true
+}
+
+// sameGoCode reports whether two Go files differ in comments and layout
+// only
+func sameGoCode(a, b []byte) bool {
+	scan := func(src []byte) []string {
+		var s scanner.Scanner
+		fset := token.NewFileSet()
+		s.Init(fset.AddFile("", fset.Base(), len(src)), src, nil, 0)
+		var tokens []string
+		for {
+			_, tok, lit := s.Scan()
+			if tok == token.EOF {
+				return tokens
+			}
+			if tok == token.SEMICOLON && lit == "\n" {
+				// Inserted at line ends, which comments may move
+				continue
+			}
+			tokens = append(tokens, tok.String()+lit)
+		}
+	}
+	return slices.Equal(scan(a), scan(b))
+}
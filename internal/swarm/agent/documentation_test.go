@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/swarm/provider"
+)
+
+// scriptedClient answers prompts about a file with the answer for its name
+type scriptedClient struct {
+	answers map[string]string
+}
+
+func (c *scriptedClient) Complete(ctx context.Context, req provider.Request) (provider.Response, error) {
+	for name, answer := range c.answers {
+		if strings.Contains(req.Prompt, "File "+name+":") {
+			return provider.Response{Content: answer, InputTokens: 10, OutputTokens: 5}, nil
+		}
+	}
+	return provider.Response{Content: noDocChanges}, nil
+}
+
+func (c *scriptedClient) Model() string { return "scripted" }
+
+func TestDocumentationAgent(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"sum.go":       "package m\n\nfunc Sum(a, b int) int {\n\treturn a + b\n}\n",
+		"sub.go":       "package m\n\nfunc Sub(a, b int) int { return a - b }\n",
+		"README.md":    "# m\n",
+		"unchanged.go": "package m\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := AgentConfig{ID: "docs", Type: AgentTypeDocumentation, MaxConcurrency: 1}
+	base := NewBaseAgent(config)
+	ag := &DocumentationAgent{
+		ModelAgent: &ModelAgent{BaseAgent: base, slots: newTaskSlots(base), client: &scriptedClient{answers: map[string]string{
+			"sum.go":    "Here you go:\n```go\npackage m\n\n// Sum returns the sum of a and b\nfunc Sum(a, b int) int {\n\treturn a + b\n}\n```",
+			"sub.go":    "```go\npackage m\n\n// Sub subtracts\nfunc Sub(a, b int) int { return b - a }\n```",
+			"README.md": "```markdown\n# m\n\nUsage:\n\n```go\nm.Sum(1, 2)\n```\n```",
+		}}},
+		dir: dir,
+	}
+
+	diff := "--- a/sum.go\n+++ b/sum.go\n@@ -1 +1 @@\n--- a/old.go\n+++ /dev/null\n"
+	result, err := ag.ExecuteTask(context.Background(), Task{ID: "t1", Type: "documentation", Input: map[string]interface{}{"diff": diff}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, ok := ResultPatch(result.Output)
+	if !ok {
+		t.Fatalf("output %v has no patch", result.Output)
+	}
+	if !strings.Contains(patch.Diff, "+++ b/sum.go") {
+		t.Errorf("diff of the patch = %q, want sum.go relative to the directory", patch.Diff)
+	}
+	edits := patch.Files
+	if len(edits) != 1 || edits[0].Path != filepath.Join(dir, "sum.go") || !strings.Contains(edits[0].Proposed, "// Sum returns") {
+		t.Fatalf("edits of the diff = %+v", edits)
+	}
+	if edits[0].Original != files["sum.go"] || !strings.HasSuffix(edits[0].Proposed, "}\n") {
+		t.Errorf("edit = %+v", edits[0])
+	}
+
+	result, err = ag.ExecuteTask(context.Background(), Task{ID: "t2", Type: "documentation", Input: map[string]interface{}{
+		"files": []interface{}{"README.md", "sub.go", "unchanged.go", "missing.go"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch, _ = ResultPatch(result.Output)
+	edits = patch.Files
+	if len(edits) != 1 || !strings.HasSuffix(edits[0].Path, "README.md") || !strings.Contains(edits[0].Proposed, "m.Sum(1, 2)\n```\n") {
+		t.Fatalf("edits = %+v", edits)
+	}
+	response := result.Output["response"].(string)
+	for _, want := range []string{"edits to 1 of 4 files", "sub.go: skipped, the model changed code", "unchanged.go: no changes needed", "missing.go: skipped"} {
+		if !strings.Contains(response, want) {
+			t.Errorf("response %q does not contain %q", response, want)
+		}
+	}
+
+	if _, err := ag.ExecuteTask(context.Background(), Task{ID: "t3", Type: "documentation"}); err != ErrNoFilesToDocument {
+		t.Errorf("task without files: err = %v, want ErrNoFilesToDocument", err)
+	}
+}
+
+func TestSameGoCode(t *testing.T) {
+	src := "package m\n\nfunc f() int {\n\treturn 1\n}\n"
+	for _, tt := range []struct {
+		other string
+		same  bool
+	}{
+		{"package m\n\n// f returns one\nfunc f() int {\n\treturn 1 // always\n}\n", true},
+		{"package m\n\nfunc f() int { return 1 }\n", true},
+		{"package m\n\nfunc f() int {\n\treturn 2\n}\n", false},
+		{"package m\n\nfunc g() int {\n\treturn 1\n}\n", false},
+	} {
+		if got := sameGoCode([]byte(src), []byte(tt.other)); got != tt.same {
+			t.Errorf("sameGoCode(%q) = %v, want %v", tt.other, got, tt.same)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+)
+
+type dryRunKey struct{}
+
+// WithDryRun returns a context for running tasks in which agents that run
+// commands report what they would run and simulate a successful result
+// instead of running it
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether the task running with ctx is a dry run
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// reportDryRun reports the command a dry run skipped as progress of the
+// task
+func reportDryRun(ctx context.Context, task Task, agentID, command, dir string) {
+	ReportProgress(ctx, TaskProgress{
+		TaskID:  task.ID,
+		AgentID: agentID,
+		Stage:   "dry run",
+		Message: fmt.Sprintf("dry run: would run %s in %s", command, dir),
+		Details: map[string]interface{}{"command": command, "dir": dir, "dry_run": true},
+	})
+}
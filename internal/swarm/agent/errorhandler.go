@@ -0,0 +1,399 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/artifact"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+	"github.com/opencode-ai/opencode/internal/swarm/provider"
+)
+
+// Task types of runtime errors
+const (
+	// TaskTypeHandleError diagnoses the error of its "message" input, e.g. a
+	// log entry with a stack trace
+	TaskTypeHandleError = "handle_error"
+	// TaskTypePatch fixes an error, with a "patch" input drafting the fix
+	// if the error handler had a model
+	TaskTypePatch = "patch"
+)
+
+const (
+	// maxErrorLocations bounds the project frames shown for an error
+	maxErrorLocations = 5
+	// maxSimilarFixes bounds the past fixes looked up for an error
+	maxSimilarFixes = 3
+	// snippetContext is the number of lines shown around a frame
+	snippetContext = 5
+)
+
+const errorHandlerPrompt = `You are the error handler of a multi-agent software engineering swarm. You are given a runtime error, the source code around the frames of its stack trace and how similar errors were fixed before.
+
+Find the cause of the error and fix it with the smallest change that makes the code correct. Answer with the fix as a unified diff in a single fenced code block, with paths relative to the repository, followed by one sentence on the cause.`
+
+// ErrNoErrorMessage is returned for handle_error tasks without a message
+var ErrNoErrorMessage = errors.New("no error message to handle")
+
+// MemoryUser is implemented by agents that consult the memory of the swarm.
+// The coordinator hands them its store before starting them.
+type MemoryUser interface {
+	UseMemory(store memory.MemoryStore)
+}
+
+// PruneReviewer is implemented by agents that vote on prunes deleting many
+// memories. Only agents allowed to cast votes are asked.
+type PruneReviewer interface {
+	ReviewPrune(ctx context.Context, plan memory.PrunePlan) (approve bool, reasoning string)
+}
+
+// SourceLocation is a stack frame found in the project, with the code
+// around it
+type SourceLocation struct {
+	StackFrame
+	// Path is relative to the project directory
+	Path    string
+	Snippet string
+}
+
+// ErrorDiagnosis is what the error handler found out about an error
+type ErrorDiagnosis struct {
+	Signature string
+	Frames    []StackFrame
+	Locations []SourceLocation
+	// SimilarFixes describe how errors like this one were fixed before
+	SimilarFixes []string
+	// Patch is the fix drafted by the model, a unified diff, if any
+	Patch string
+}
+
+// Summary describes the diagnosis in a few lines
+func (d *ErrorDiagnosis) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Error: %s\n", d.Signature)
+	if len(d.Locations) == 0 {
+		fmt.Fprintf(&b, "None of the %d frames of its stack trace are in the project\n", len(d.Frames))
+	}
+	for _, location := range d.Locations {
+		fmt.Fprintf(&b, "- %s:%d", location.Path, location.Line)
+		if location.Function != "" {
+			fmt.Fprintf(&b, " in %s", location.Function)
+		}
+		b.WriteString("\n")
+	}
+	if len(d.SimilarFixes) > 0 {
+		fmt.Fprintf(&b, "%d similar errors were fixed before\n", len(d.SimilarFixes))
+	}
+	if d.Patch != "" {
+		b.WriteString("Drafted a patch\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// ErrorHandlerAgent diagnoses runtime errors. For a handle_error task it
+// finds the frames of the error's stack trace in the project, looks up how
+// similar errors were fixed before and proposes a patch task, which the
+// swarm votes on before an agent runs it. With a provider configured it
+// drafts the patch with its model and answers every other task it accepts
+// like a ModelAgent.
+//
+// CustomConfig may set "dir", the project, the working directory by
+// default.
+type ErrorHandlerAgent struct {
+	*ModelAgent
+	dir string
+
+	mu     sync.Mutex
+	memory memory.MemoryStore
+}
+
+func init() {
+	RegisterFactory(AgentTypeErrorHandler, func(config AgentConfig) (Agent, error) {
+		return NewErrorHandlerAgent(config)
+	})
+}
+
+// NewErrorHandlerAgent creates an error handler, backed by a model if its
+// configuration has a provider
+func NewErrorHandlerAgent(config AgentConfig) (*ErrorHandlerAgent, error) {
+	dir := customString(config, "dir")
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("agent %s: %w", config.ID, err)
+	}
+
+	var model *ModelAgent
+	if config.ProviderType != "" {
+		if model, err = NewModelAgent(config); err != nil {
+			return nil, err
+		}
+	} else {
+		if config.MaxConcurrency <= 0 {
+			config.MaxConcurrency = 1
+		}
+		base := NewBaseAgent(config)
+		model = &ModelAgent{BaseAgent: base, slots: newTaskSlots(base)}
+	}
+	return &ErrorHandlerAgent{ModelAgent: model, dir: dir}, nil
+}
+
+// UseMemory makes the agent look up past fixes in store
+func (a *ErrorHandlerAgent) UseMemory(store memory.MemoryStore) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.memory = store
+}
+
+// CanHandleTask accepts handle_error tasks, and the tasks of a ModelAgent
+// when the error handler has a model
+func (a *ErrorHandlerAgent) CanHandleTask(task Task) bool {
+	if task.Type == TaskTypeHandleError {
+		return true
+	}
+	return a.client != nil && task.Type != TaskTypePatch && a.ModelAgent.CanHandleTask(task)
+}
+
+// ExecuteTask diagnoses an error or prompts the model. The results of
+// handle_error tasks have the *ErrorDiagnosis as their "diagnosis" output,
+// a summary of it as their "response" and, if the error is in the project,
+// the patch task to queue as their "tasks". A drafted patch is attached as
+// a diff artifact.
+func (a *ErrorHandlerAgent) ExecuteTask(ctx context.Context, task Task) (*TaskResult, error) {
+	if task.Type != TaskTypeHandleError {
+		if a.client == nil {
+			return nil, fmt.Errorf("agent %s: no model for %s tasks", a.id, task.Type)
+		}
+		return a.ModelAgent.ExecuteTask(ctx, task)
+	}
+
+	if !a.slots.acquire() {
+		return nil, fmt.Errorf("%w: %s is running %d tasks", ErrAgentBusy, a.id, a.maxConcurrency())
+	}
+	defer a.slots.release()
+
+	start := time.Now()
+	result := &TaskResult{
+		TaskID:  task.ID,
+		AgentID: a.id,
+	}
+	diagnosis, err := a.diagnose(ctx, task)
+	result.ExecutionTime = time.Since(start)
+	result.CompletedAt = time.Now()
+	a.RecordTask(result.ExecutionTime, err == nil)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	result.Success = true
+	result.Output = map[string]interface{}{
+		"response":  diagnosis.Summary(),
+		"diagnosis": diagnosis,
+	}
+	if len(diagnosis.Locations) > 0 {
+		result.Output["tasks"] = []Task{patchTask(task, diagnosis)}
+	}
+	if diagnosis.Patch != "" {
+		result.Artifacts = []Artifact{{Name: "patch.diff", Kind: artifact.KindDiff, Data: []byte(diagnosis.Patch)}}
+	}
+	result.Metadata = map[string]interface{}{
+		"frames":        len(diagnosis.Frames),
+		"locations":     len(diagnosis.Locations),
+		"similar_fixes": len(diagnosis.SimilarFixes),
+	}
+	return result, nil
+}
+
+func (a *ErrorHandlerAgent) diagnose(ctx context.Context, task Task) (*ErrorDiagnosis, error) {
+	message, _ := task.Input["message"].(string)
+	if message == "" {
+		message = task.Description
+	}
+	if strings.TrimSpace(message) == "" {
+		return nil, ErrNoErrorMessage
+	}
+
+	diagnosis := &ErrorDiagnosis{
+		Signature: ErrorSignature(message),
+		Frames:    ParseStackTrace(message),
+	}
+	for _, frame := range diagnosis.Frames {
+		if len(diagnosis.Locations) == maxErrorLocations {
+			break
+		}
+		if location, ok := a.locate(frame); ok {
+			diagnosis.Locations = append(diagnosis.Locations, location)
+		}
+	}
+
+	fixes, err := a.similarFixes(ctx, diagnosis)
+	if err != nil {
+		return nil, err
+	}
+	diagnosis.SimilarFixes = fixes
+
+	if a.client != nil && len(diagnosis.Locations) > 0 {
+		resp, err := a.client.Complete(ctx, provider.Request{
+			System: errorHandlerPrompt,
+			Prompt: errorPrompt(message, diagnosis),
+		})
+		if err != nil {
+			return nil, err
+		}
+		diagnosis.Patch = resp.Content
+	}
+	return diagnosis, nil
+}
+
+// locate finds the file of a frame in the project. Absolute paths from
+// another checkout, e.g. of a CI machine, are matched by their longest
+// suffix of at least two elements that is a project file. Dependencies
+// are not part of the project.
+func (a *ErrorHandlerAgent) locate(frame StackFrame) (SourceLocation, bool) {
+	path := filepath.FromSlash(frame.File)
+	candidates := []string{path}
+	if filepath.IsAbs(path) {
+		candidates = candidates[:0]
+		if rel, err := filepath.Rel(a.dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			candidates = append(candidates, rel)
+		}
+		parts := strings.Split(strings.TrimPrefix(filepath.ToSlash(path), "/"), "/")
+		for i := 1; i < len(parts)-1; i++ {
+			candidates = append(candidates, filepath.Join(parts[i:]...))
+		}
+	}
+
+	for _, rel := range candidates {
+		if isDependency(rel) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(a.dir, rel))
+		if err != nil || len(content) > maxAnalyzedFileSize {
+			continue
+		}
+		return SourceLocation{StackFrame: frame, Path: filepath.ToSlash(rel), Snippet: snippet(string(content), frame.Line)}, true
+	}
+	return SourceLocation{}, false
+}
+
+// isDependency reports whether a project path is in a directory of
+// dependencies
+func isDependency(path string) bool {
+	for _, dir := range strings.Split(filepath.ToSlash(path), "/") {
+		switch dir {
+		case "vendor", "node_modules", "site-packages", "dist-packages":
+			return true
+		}
+	}
+	return false
+}
+
+// similarFixes returns how errors like the diagnosed one were fixed, from
+// the semantic memories tagged "fix"
+func (a *ErrorHandlerAgent) similarFixes(ctx context.Context, diagnosis *ErrorDiagnosis) ([]string, error) {
+	a.mu.Lock()
+	store := a.memory
+	a.mu.Unlock()
+	if store == nil {
+		return nil, nil
+	}
+
+	text := diagnosis.Signature
+	for _, location := range diagnosis.Locations {
+		text += " " + location.Path + " " + location.Function
+	}
+	found, err := memory.HybridSearch(ctx, store, memory.HybridQuery{
+		Text:     text,
+		Types:    []memory.MemoryType{memory.MemoryTypeSemantic},
+		MinScore: 0.2,
+		Limit:    20,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search past fixes: %w", err)
+	}
+	var fixes []string
+	for _, scored := range found {
+		if len(fixes) == maxSimilarFixes {
+			break
+		}
+		for _, tag := range scored.Memory.Tags {
+			if tag == "fix" {
+				fixes = append(fixes, fmt.Sprint(scored.Memory.Content))
+				break
+			}
+		}
+	}
+	return fixes, nil
+}
+
+// snippet returns the lines around line, numbered
+func snippet(content string, line int) string {
+	lines := strings.Split(content, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	from, to := max(line-snippetContext, 1), min(line+snippetContext, len(lines))
+	var b strings.Builder
+	for n := from; n <= to; n++ {
+		marker := " "
+		if n == line {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s%5d  %s\n", marker, n, lines[n-1])
+	}
+	return b.String()
+}
+
+// errorPrompt asks the model to fix an error
+func errorPrompt(message string, diagnosis *ErrorDiagnosis) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Error:\n```\n%s\n```\n", strings.TrimRight(message, "\n"))
+	for _, location := range diagnosis.Locations {
+		fmt.Fprintf(&b, "\n%s:%d:\n```\n%s```\n", location.Path, location.Line, location.Snippet)
+	}
+	if len(diagnosis.SimilarFixes) > 0 {
+		b.WriteString("\nSimilar errors were fixed like this before:\n")
+		for _, fix := range diagnosis.SimilarFixes {
+			fmt.Fprintf(&b, "- %s\n", fix)
+		}
+	}
+	return b.String()
+}
+
+// patchTask is the task fixing a diagnosed error. The swarm votes on it
+// before it runs.
+func patchTask(task Task, diagnosis *ErrorDiagnosis) Task {
+	top := diagnosis.Locations[0]
+	input := map[string]interface{}{
+		"signature": diagnosis.Signature,
+		"path":      top.Path,
+		"line":      top.Line,
+	}
+	if message, ok := task.Input["message"].(string); ok {
+		input["error"] = message
+	}
+	if len(diagnosis.SimilarFixes) > 0 {
+		input["similar_fixes"] = diagnosis.SimilarFixes
+	}
+	if diagnosis.Patch != "" {
+		input["patch"] = diagnosis.Patch
+	}
+	return Task{
+		Type:         TaskTypePatch,
+		Priority:     task.Priority,
+		Description:  fmt.Sprintf("Fix %q at %s:%d", diagnosis.Signature, top.Path, top.Line),
+		Input:        input,
+		RequiresVote: true,
+		// One patch at a time for an error reported by several tasks
+		IdempotencyKey: "patch:" + diagnosis.Signature,
+	}
+}
@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+const goPanic = `panic: runtime error: index out of range [5] with length 3
+
+goroutine 7 [running]:
+example.com/app/internal/server.(*Handler).lookup(0xc000010000, 0x5)
+	/home/ci/build/internal/server/handler.go:7 +0x1d
+net/http.HandlerFunc.ServeHTTP(0xc0000a6000, {0x7a1c20, 0xc0000e2000}, 0xc0000f4000)
+	/usr/local/go/src/net/http/server.go:2166 +0x29
+`
+
+func TestParseStackTrace(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		trace string
+		want  []StackFrame
+	}{
+		{"go", goPanic, []StackFrame{
+			{Function: "example.com/app/internal/server.(*Handler).lookup", File: "/home/ci/build/internal/server/handler.go", Line: 7},
+			{Function: "net/http.HandlerFunc.ServeHTTP", File: "/usr/local/go/src/net/http/server.go", Line: 2166},
+		}},
+		{"python", "Traceback (most recent call last):\n  File \"app/main.py\", line 3, in <module>\n    run()\n  File \"app/views.py\", line 12, in index\n    return items[5]\nIndexError: list index out of range\n", []StackFrame{
+			{Function: "index", File: "app/views.py", Line: 12},
+			{Function: "<module>", File: "app/main.py", Line: 3},
+		}},
+		{"javascript", "TypeError: Cannot read properties of undefined\n    at handle (/srv/app/src/routes.js:14:21)\n    at /srv/app/src/index.js:8:3\n    at process.processTicksAndRejections (node:internal/process/task_queues:95:5)\n", []StackFrame{
+			{Function: "handle", File: "/srv/app/src/routes.js", Line: 14},
+			{File: "/srv/app/src/index.js", Line: 8},
+		}},
+		{"java", "java.lang.NullPointerException\n\tat com.example.Main.run(Main.java:12)\n\tat com.example.Main.main(Main.java:5)\n", []StackFrame{
+			{Function: "com.example.Main.run", File: "Main.java", Line: 12},
+			{Function: "com.example.Main.main", File: "Main.java", Line: 5},
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseStackTrace(tt.trace); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("frames = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorSignature(t *testing.T) {
+	for message, want := range map[string]string{
+		goPanic: "panic: runtime error: index out of range [N] with length N",
+		"Traceback (most recent call last):\n  File \"a.py\", line 1, in <module>\nKeyError: 'id'\n": "KeyError: 'id'",
+		"\n": "",
+	} {
+		if got := ErrorSignature(message); got != want {
+			t.Errorf("ErrorSignature(%q) = %q, want %q", message, got, want)
+		}
+	}
+}
+
+func TestErrorHandlerAgent(t *testing.T) {
+	dir := t.TempDir()
+	source := "package server\n\ntype Handler struct{ items []string }\n\nfunc (h *Handler) lookup(i int) string {\n\t// i comes from the request\n\treturn h.items[i]\n}\n"
+	if err := os.MkdirAll(filepath.Join(dir, "internal", "server"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "internal", "server", "handler.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store := memory.NewHierarchicalMemoryStore(memory.HierarchicalMemoryConfig{})
+	ctx := context.Background()
+	for _, mem := range []memory.Memory{
+		{ID: "fix:1", Type: memory.MemoryTypeSemantic, Tags: []string{"fix"}, Content: "panic: runtime error: index out of range was fixed by checking the length in lookup"},
+		{ID: "finding", Type: memory.MemoryTypeSemantic, Tags: []string{"analysis"}, Content: "internal/server/handler.go: lookup has an index out of range"},
+	} {
+		if err := store.Store(ctx, mem); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ag, err := New(AgentConfig{ID: "errors", Type: AgentTypeErrorHandler, CustomConfig: map[string]interface{}{"dir": dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ag.(MemoryUser).UseMemory(store)
+	if !ag.CanHandleTask(Task{Type: TaskTypeHandleError}) || ag.CanHandleTask(Task{Type: TaskTypePatch}) {
+		t.Error("an error handler without a model should handle handle_error tasks only")
+	}
+
+	result, err := ag.ExecuteTask(ctx, Task{ID: "t1", Type: TaskTypeHandleError, Priority: 3, Input: map[string]interface{}{"message": goPanic}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diagnosis := result.Output["diagnosis"].(*ErrorDiagnosis)
+	if len(diagnosis.Frames) != 2 || len(diagnosis.Locations) != 1 {
+		t.Fatalf("diagnosis = %+v", diagnosis)
+	}
+	location := diagnosis.Locations[0]
+	if location.Path != "internal/server/handler.go" || location.Line != 7 || !strings.Contains(location.Snippet, ">    7  \treturn h.items[i]") {
+		t.Errorf("location = %+v", location)
+	}
+	if len(diagnosis.SimilarFixes) != 1 || !strings.Contains(diagnosis.SimilarFixes[0], "checking the length") {
+		t.Errorf("similar fixes = %q", diagnosis.SimilarFixes)
+	}
+
+	tasks := result.Output["tasks"].([]Task)
+	if len(tasks) != 1 {
+		t.Fatalf("tasks = %+v", tasks)
+	}
+	patch := tasks[0]
+	if patch.Type != TaskTypePatch || !patch.RequiresVote || patch.Priority != 3 || patch.Input["path"] != "internal/server/handler.go" || patch.Input["signature"] != diagnosis.Signature {
+		t.Errorf("patch task = %+v", patch)
+	}
+
+	// Errors outside the project have nothing to patch
+	result, err = ag.ExecuteTask(ctx, Task{ID: "t2", Type: TaskTypeHandleError, Input: map[string]interface{}{"message": "panic: boom\n\nmain.main()\n\t/elsewhere/main.go:3 +0x1\n"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result.Output["tasks"]; ok || !strings.Contains(result.Output["response"].(string), "None of the 1 frames") {
+		t.Errorf("output = %+v", result.Output)
+	}
+}
@@ -0,0 +1,22 @@
+package agent
+
+import "errors"
+
+// Common errors
+var (
+	// ErrAgentBusy means the agent cannot take more work right now, e.g. all
+	// its task slots or its outgoing message buffer are full. Retrying later
+	// or on another agent may succeed.
+	ErrAgentBusy = errors.New("agent busy")
+	// ErrAgentNotRunning means the agent was not started or has stopped
+	ErrAgentNotRunning = errors.New("agent not running")
+	// ErrAgentRunning means Start was called on a running agent
+	ErrAgentRunning = errors.New("agent already running")
+	// ErrAgentNotFound means no agent with the ID is registered
+	ErrAgentNotFound = errors.New("agent not found")
+	// ErrAgentExists means an agent with the same ID is already registered
+	ErrAgentExists = errors.New("agent already registered")
+	// ErrWrongAgent means an agent was reconfigured with the configuration
+	// of another
+	ErrWrongAgent = errors.New("configuration of another agent")
+)
@@ -0,0 +1,521 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/artifact"
+	"github.com/opencode-ai/opencode/internal/swarm/locks"
+)
+
+// TaskTypeCommand runs the shell command of its "command" input
+const TaskTypeCommand = "command"
+
+const (
+	// defaultCommandTimeout bounds commands of agents without a "timeout"
+	defaultCommandTimeout = 2 * time.Minute
+	// defaultMaxCommandOutput bounds the output kept of each stream of a
+	// command, in bytes
+	defaultMaxCommandOutput = 256 << 10
+	// maxProgressLines bounds the output lines streamed per command
+	maxProgressLines = 100
+	// maxProgressLineLength bounds a streamed line
+	maxProgressLineLength = 500
+	// maxCommandResponse bounds the output shown in the response
+	maxCommandResponse = 4000
+	// maxCommandLog bounds the output of each stream logged to the
+	// scratchpad, in bytes
+	maxCommandLog = 16 << 20
+)
+
+var (
+	// ErrNoCommand is returned for command tasks without a command
+	ErrNoCommand = errors.New("no command to run")
+	// ErrOutsideSandbox is returned for command tasks whose directory is
+	// not in the sandbox
+	ErrOutsideSandbox = errors.New("directory is outside the sandbox")
+	// ErrVoteRequired is returned for destructive commands that did not go
+	// through a vote
+	ErrVoteRequired = errors.New("destructive command requires a vote")
+)
+
+// VoteRequirer is implemented by agents that only run some tasks after the
+// swarm voted for them. The coordinator votes on those tasks and runs them
+// with RequiresVote set once the vote passed.
+type VoteRequirer interface {
+	RequiresVote(task Task) bool
+}
+
+// destructiveCommands match commands that delete data or are hard to undo
+var destructiveCommands = regexp.MustCompile(strings.Join([]string{
+	`\brm\s+(?:-\w*[rRf]|--recursive|--force)`,
+	`\bgit\s+(?:push\s+.*(?:--force|-f\b)|reset\s+--hard|clean\s+-\w*f|branch\s+-D|checkout\s+--\s+\.)`,
+	`\b(?:mkfs(?:\.\w+)?|fdisk|dd\s+.*\bof=|shred|wipefs)\b`,
+	`\b(?:shutdown|reboot|halt|poweroff)\b`,
+	`\bchmod\s+-R\s+0?777\b|\bchown\s+-R\b`,
+	`>\s*/dev/(?:sd|nvme|hd)`,
+	`(?i)\b(?:drop\s+(?:table|database|schema)|truncate\s+table)\b`,
+	`\b(?:kubectl\s+delete|terraform\s+destroy|docker\s+(?:system\s+prune|rm\s+-f|volume\s+rm))\b`,
+	`:\(\)\s*\{\s*:\|:&\s*\};:`,
+}, "|"))
+
+// CommandOutput is what a command printed and how it exited
+type CommandOutput struct {
+	Command  string `json:"command"`
+	Dir      string `json:"dir"`
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	// Truncated is set when output beyond the limit was dropped
+	Truncated bool `json:"truncated,omitempty"`
+	TimedOut  bool `json:"timed_out,omitempty"`
+	// DryRun is set when the command was not run, see WithDryRun
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// Summary describes the command and the end of its output
+func (o *CommandOutput) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ %s\n", o.Command)
+	output := strings.TrimSpace(o.Stdout + "\n" + o.Stderr)
+	if output != "" {
+		b.WriteString(truncateStart(output, maxCommandResponse))
+		b.WriteString("\n")
+	}
+	switch {
+	case o.DryRun:
+		b.WriteString("Dry run, not executed")
+	case o.TimedOut:
+		b.WriteString("Timed out")
+	default:
+		fmt.Fprintf(&b, "Exit code %d", o.ExitCode)
+	}
+	if o.Truncated {
+		b.WriteString(", output truncated")
+	}
+	return b.String()
+}
+
+// ExecutorAgent runs shell commands in a sandbox. A command task runs its
+// "command" input in the agent's directory, or in its "dir" input within
+// it, with only the PATH and locale of the swarm and the variables of the
+// "env" option in its environment, and a temporary home directory. The
+// command is killed after its timeout and the output kept of each stream
+// is limited, the whole output is logged to the agent's scratchpad. Output
+// lines are reported as progress while it runs. A command holds the locks
+// of its "locks" input, or else of its working directory, while it runs.
+// Destructive commands, like rm -rf, run only after the swarm voted for
+// them. In a dry run commands are reported and succeed without running.
+// With a provider configured it prompts its model for every other
+// task it accepts, like a ModelAgent.
+//
+// CustomConfig may set "dir", the sandbox, the working directory by
+// default, "timeout", "maxOutput" in bytes, "env", a comma separated list
+// of variables to pass on, and "destructive", a regular expression
+// matching more commands that need a vote.
+type ExecutorAgent struct {
+	*ModelAgent
+	dir         string
+	timeout     time.Duration
+	maxOutput   int
+	env         []string
+	destructive *regexp.Regexp
+}
+
+func init() {
+	RegisterFactory(AgentTypeExecutor, func(config AgentConfig) (Agent, error) {
+		return NewExecutorAgent(config)
+	})
+}
+
+// NewExecutorAgent creates an executor, backed by a model if its
+// configuration has a provider
+func NewExecutorAgent(config AgentConfig) (*ExecutorAgent, error) {
+	dir := customString(config, "dir")
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("agent %s: %w", config.ID, err)
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	a := &ExecutorAgent{
+		dir:       dir,
+		timeout:   defaultCommandTimeout,
+		maxOutput: defaultMaxCommandOutput,
+		env:       append([]string(nil), sandboxEnv...),
+	}
+	if s := customString(config, "timeout"); s != "" {
+		if a.timeout, err = time.ParseDuration(s); err != nil || a.timeout <= 0 {
+			return nil, fmt.Errorf("agent %s: invalid timeout %q", config.ID, s)
+		}
+	}
+	if s := customString(config, "maxOutput"); s != "" {
+		if a.maxOutput, err = strconv.Atoi(s); err != nil || a.maxOutput <= 0 {
+			return nil, fmt.Errorf("agent %s: invalid maxOutput %q", config.ID, s)
+		}
+	}
+	for _, name := range strings.Split(customString(config, "env"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			a.env = append(a.env, name)
+		}
+	}
+	if s := customString(config, "destructive"); s != "" {
+		if a.destructive, err = regexp.Compile(s); err != nil {
+			return nil, fmt.Errorf("agent %s: invalid destructive pattern: %w", config.ID, err)
+		}
+	}
+
+	if config.ProviderType != "" {
+		if a.ModelAgent, err = NewModelAgent(config); err != nil {
+			return nil, err
+		}
+	} else {
+		if config.MaxConcurrency <= 0 {
+			config.MaxConcurrency = 1
+		}
+		base := NewBaseAgent(config)
+		a.ModelAgent = &ModelAgent{BaseAgent: base, slots: newTaskSlots(base)}
+	}
+	return a, nil
+}
+
+// CanHandleTask accepts command tasks, and the tasks of a ModelAgent when
+// the executor has a model
+func (a *ExecutorAgent) CanHandleTask(task Task) bool {
+	if task.Type == TaskTypeCommand {
+		return true
+	}
+	return a.client != nil && a.ModelAgent.CanHandleTask(task)
+}
+
+// RequiresVote reports whether a task runs a destructive command
+func (a *ExecutorAgent) RequiresVote(task Task) bool {
+	if task.Type != TaskTypeCommand {
+		return false
+	}
+	command, _ := task.Input["command"].(string)
+	return destructiveCommands.MatchString(command) || (a.destructive != nil && a.destructive.MatchString(command))
+}
+
+// RequiredPermissions lets only agents that may run commands take command
+// tasks
+func (a *ExecutorAgent) RequiredPermissions(task Task) []Permission {
+	if task.Type == TaskTypeCommand {
+		return []Permission{PermissionRunCommands}
+	}
+	return nil
+}
+
+// ExecuteTask runs a command or prompts the model. The results of command
+// tasks have the *CommandOutput as their "command" output and a summary of
+// it as their "response", with the logs of the output streams attached as
+// artifacts. Commands that exit with an error fail the task.
+func (a *ExecutorAgent) ExecuteTask(ctx context.Context, task Task) (*TaskResult, error) {
+	if task.Type != TaskTypeCommand {
+		if a.client == nil {
+			return nil, fmt.Errorf("agent %s: no model for %s tasks", a.id, task.Type)
+		}
+		return a.ModelAgent.ExecuteTask(ctx, task)
+	}
+	if a.RequiresVote(task) && !task.RequiresVote {
+		return nil, fmt.Errorf("agent %s: %w", a.id, ErrVoteRequired)
+	}
+
+	if !a.slots.acquire() {
+		return nil, fmt.Errorf("%w: %s is running %d tasks", ErrAgentBusy, a.id, a.maxConcurrency())
+	}
+	defer a.slots.release()
+
+	start := time.Now()
+	result := &TaskResult{
+		TaskID:  task.ID,
+		AgentID: a.id,
+	}
+	output, artifacts, err := a.run(ctx, task)
+	result.ExecutionTime = time.Since(start)
+	result.CompletedAt = time.Now()
+	a.RecordTask(result.ExecutionTime, err == nil && output.ExitCode == 0)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	result.Success = output.ExitCode == 0 && !output.TimedOut
+	result.Output = map[string]interface{}{
+		"response": output.Summary(),
+		"command":  output,
+	}
+	result.Artifacts = artifacts
+	result.Metadata = map[string]interface{}{
+		"exit_code": output.ExitCode,
+		"truncated": output.Truncated,
+	}
+	if output.DryRun {
+		result.Metadata["dry_run"] = true
+	}
+	if output.TimedOut {
+		result.Error = fmt.Errorf("%s timed out", output.Command)
+	} else if output.ExitCode != 0 {
+		result.Error = fmt.Errorf("%s exited with code %d", output.Command, output.ExitCode)
+	}
+	return result, nil
+}
+
+// run runs the command of a task in the sandbox and returns its output and
+// the paths of its logs
+func (a *ExecutorAgent) run(ctx context.Context, task Task) (*CommandOutput, []Artifact, error) {
+	command, _ := task.Input["command"].(string)
+	if strings.TrimSpace(command) == "" {
+		return nil, nil, ErrNoCommand
+	}
+	if err := CheckPermission(ctx, PermissionRunCommands); err != nil {
+		return nil, nil, err
+	}
+	dir, err := a.workDir(task)
+	if err != nil {
+		return nil, nil, err
+	}
+	if IsDryRun(ctx) {
+		reportDryRun(ctx, task, a.id, command, dir)
+		return &CommandOutput{Command: command, Dir: dir, DryRun: true}, nil, nil
+	}
+	release, err := locks.Acquire(ctx, commandLocks(task, dir)...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+
+	timeout := a.timeout
+	if t := taskTimeout(task); t > 0 && t < timeout {
+		timeout = t
+	}
+	parent := ctx
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	home, err := os.MkdirTemp("", "opencode-sandbox-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(home)
+
+	var artifacts []Artifact
+	taskDir := TaskDir(task)
+	logs := make(map[string]*os.File, 2)
+	for _, name := range []string{"stdout", "stderr"} {
+		f, err := a.Scratchpad().Create(path.Join(taskDir, name+".log"))
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		logs[name] = f
+		artifacts = append(artifacts, Artifact{Name: name + ".log", Kind: artifact.KindLog, Path: f.Name(), Scratch: true})
+	}
+
+	stream := &progressStream{ctx: ctx, task: task.ID, agent: a.id}
+	stdout := &limitedBuffer{limit: a.maxOutput, stream: stream, name: "stdout", log: logs["stdout"]}
+	stderr := &limitedBuffer{limit: a.maxOutput, stream: stream, name: "stderr", log: logs["stderr"]}
+	cmd := shellCommand(ctx, command)
+	cmd.Dir = dir
+	cmd.Env = append(a.environ(), tempEnv(home)...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Do not wait for processes that kept the output open after a kill
+	cmd.WaitDelay = time.Second
+
+	runErr := cmd.Run()
+	stdout.flush()
+	stderr.flush()
+	output := &CommandOutput{
+		Command:   command,
+		Dir:       dir,
+		ExitCode:  -1,
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Truncated: stdout.dropped > 0 || stderr.dropped > 0,
+	}
+	if cmd.ProcessState != nil {
+		output.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	if parent.Err() != nil {
+		return nil, nil, parent.Err()
+	}
+	if ctx.Err() != nil {
+		output.TimedOut = true
+		return output, artifacts, nil
+	}
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) && !errors.Is(runErr, exec.ErrWaitDelay) {
+		return nil, nil, fmt.Errorf("failed to run %s: %w", command, runErr)
+	}
+	return output, artifacts, nil
+}
+
+// workDir resolves the "dir" input of a task, which must be in the sandbox
+func (a *ExecutorAgent) workDir(task Task) (string, error) {
+	rel, _ := task.Input["dir"].(string)
+	dir := rel
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(a.dir, dir)
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+	if inside, err := filepath.Rel(a.dir, resolved); err != nil || inside == ".." || strings.HasPrefix(inside, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrOutsideSandbox, rel)
+	}
+	return resolved, nil
+}
+
+// environ returns the variables of the swarm that commands inherit
+func (a *ExecutorAgent) environ() []string {
+	var env []string
+	for _, name := range a.env {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// commandLocks returns the resources a command task locks: its "locks"
+// input, a list or a comma separated string, or the directory it runs in
+func commandLocks(task Task, dir string) []string {
+	var resources []string
+	switch l := task.Input["locks"].(type) {
+	case string:
+		resources = strings.Split(l, ",")
+	case []string:
+		resources = l
+	case []interface{}:
+		for _, r := range l {
+			resources = append(resources, fmt.Sprint(r))
+		}
+	}
+	var cleaned []string
+	for _, r := range resources {
+		if r = strings.TrimSpace(r); r != "" {
+			cleaned = append(cleaned, r)
+		}
+	}
+	if len(cleaned) == 0 {
+		return []string{locks.Dir(dir)}
+	}
+	return cleaned
+}
+
+// taskTimeout reads the "timeout" input of a task, a duration or a number
+// of seconds
+func taskTimeout(task Task) time.Duration {
+	switch t := task.Input["timeout"].(type) {
+	case string:
+		d, _ := time.ParseDuration(t)
+		return d
+	case float64:
+		return time.Duration(t * float64(time.Second))
+	case int:
+		return time.Duration(t) * time.Second
+	}
+	return 0
+}
+
+// progressStream reports the output lines of a command as progress, up to
+// maxProgressLines
+type progressStream struct {
+	ctx   context.Context
+	task  string
+	agent string
+
+	mu    sync.Mutex
+	lines int
+}
+
+func (s *progressStream) line(name, line string) {
+	s.mu.Lock()
+	s.lines++
+	n := s.lines
+	s.mu.Unlock()
+	if n > maxProgressLines+1 {
+		return
+	}
+	if n == maxProgressLines+1 {
+		line = "... (more output is not streamed)"
+	}
+	if len(line) > maxProgressLineLength {
+		line = line[:maxProgressLineLength]
+	}
+	ReportProgress(s.ctx, TaskProgress{
+		TaskID:  s.task,
+		AgentID: s.agent,
+		Message: line,
+		Details: map[string]interface{}{"stream": name},
+	})
+}
+
+// limitedBuffer keeps the first limit bytes written to it, logs up to
+// maxCommandLog bytes and streams complete lines
+type limitedBuffer struct {
+	buf     bytes.Buffer
+	limit   int
+	dropped int
+	stream  *progressStream
+	name    string
+	partial []byte
+	log     io.Writer
+	logged  int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.partial = append(b.partial, p...)
+	for {
+		i := bytes.IndexByte(b.partial, '\n')
+		if i < 0 {
+			break
+		}
+		b.stream.line(b.name, strings.TrimRight(string(b.partial[:i]), "\r"))
+		b.partial = b.partial[i+1:]
+	}
+	if len(b.partial) > maxProgressLineLength {
+		b.stream.line(b.name, string(b.partial))
+		b.partial = nil
+	}
+
+	keep := min(len(p), b.limit-b.buf.Len())
+	b.buf.Write(p[:keep])
+	b.dropped += len(p) - keep
+	if b.log != nil && b.logged < maxCommandLog {
+		n, _ := b.log.Write(p[:min(len(p), maxCommandLog-b.logged)])
+		b.logged += n
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
+
+// flush streams the last line if it did not end with a newline
+func (b *limitedBuffer) flush() {
+	if len(b.partial) > 0 {
+		b.stream.line(b.name, string(b.partial))
+		b.partial = nil
+	}
+}
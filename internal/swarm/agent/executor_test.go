@@ -0,0 +1,181 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/locks"
+)
+
+func TestExecutorAgent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands run with sh")
+	}
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EXECUTOR_SECRET", "hunter2")
+	t.Setenv("EXECUTOR_ALLOWED", "yes")
+
+	ag, err := New(AgentConfig{ID: "exec", Type: AgentTypeExecutor, MaxConcurrency: 2, ScratchDir: t.TempDir(), CustomConfig: map[string]interface{}{
+		"dir":         dir,
+		"timeout":     "5s",
+		"maxOutput":   "64",
+		"env":         "EXECUTOR_ALLOWED",
+		"destructive": `\bnpm publish\b`,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor := ag.(*ExecutorAgent)
+
+	var mu sync.Mutex
+	var lines []string
+	ctx := WithProgress(context.Background(), func(progress TaskProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		lines = append(lines, progress.Message)
+	})
+	run := func(input map[string]interface{}) (*TaskResult, error) {
+		return ag.ExecuteTask(ctx, Task{ID: "t", Type: TaskTypeCommand, Input: input})
+	}
+
+	result, err := run(map[string]interface{}{"command": `pwd; echo "${EXECUTOR_SECRET:-none} $EXECUTOR_ALLOWED"; echo oops >&2`, "dir": "sub"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := result.Output["command"].(*CommandOutput)
+	if !result.Success || output.Stdout != filepath.Join(executor.dir, "sub")+"\nnone yes\n" || output.Stderr != "oops\n" {
+		t.Errorf("output = %+v", output)
+	}
+	mu.Lock()
+	if len(lines) != 3 || !slices.Contains(lines, "none yes") {
+		t.Errorf("progress = %q", lines)
+	}
+	mu.Unlock()
+
+	result, err = run(map[string]interface{}{"command": "seq 1 100; exit 3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output = result.Output["command"].(*CommandOutput)
+	if result.Success || output.ExitCode != 3 || len(output.Stdout) != 64 || !output.Truncated {
+		t.Errorf("output = %+v", output)
+	}
+	// The whole output is logged
+	if log, err := executor.Scratchpad().ReadFile("t/stdout.log"); err != nil || len(log) != 292 {
+		t.Errorf("logged %d bytes, %v", len(log), err)
+	}
+	if artifacts := result.Artifacts; len(artifacts) != 2 || artifacts[0].Path != filepath.Join(executor.Scratchpad().Dir(), "t", "stdout.log") || artifacts[0].Kind != "log" {
+		t.Errorf("artifacts = %+v", artifacts)
+	}
+
+	start := time.Now()
+	result, err = run(map[string]interface{}{"command": "sleep 10 & sleep 10", "timeout": "100ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output := result.Output["command"].(*CommandOutput); result.Success || !output.TimedOut || time.Since(start) > 3*time.Second {
+		t.Errorf("output = %+v after %s", output, time.Since(start))
+	}
+
+	if _, err := run(map[string]interface{}{"command": "ls", "dir": "../"}); !errors.Is(err, ErrOutsideSandbox) {
+		t.Errorf("err = %v, want %v", err, ErrOutsideSandbox)
+	}
+	if _, err := run(map[string]interface{}{"command": " "}); !errors.Is(err, ErrNoCommand) {
+		t.Errorf("err = %v, want %v", err, ErrNoCommand)
+	}
+
+	for command, destructive := range map[string]bool{
+		"rm -rf sub":                 true,
+		"git push --force origin":    true,
+		"git reset --hard HEAD~1":    true,
+		"npm publish":                true,
+		"psql -c 'DROP TABLE users'": true,
+		"rm notes.txt":               false,
+		"git push origin main":       false,
+		"go test ./...":              false,
+	} {
+		if got := executor.RequiresVote(Task{Type: TaskTypeCommand, Input: map[string]interface{}{"command": command}}); got != destructive {
+			t.Errorf("RequiresVote(%q) = %v, want %v", command, got, destructive)
+		}
+	}
+	if _, err := run(map[string]interface{}{"command": "rm -rf sub"}); !errors.Is(err, ErrVoteRequired) {
+		t.Errorf("err = %v, want %v", err, ErrVoteRequired)
+	}
+	voted := Task{ID: "t", Type: TaskTypeCommand, RequiresVote: true, Input: map[string]interface{}{"command": "rm -rf sub"}}
+	if result, err := ag.ExecuteTask(ctx, voted); err != nil || !result.Success {
+		t.Fatalf("voted command: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sub")); !os.IsNotExist(err) {
+		t.Error("the voted command did not run")
+	}
+}
+
+func TestExecutorLocks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands run with sh")
+	}
+	ag, err := New(AgentConfig{ID: "exec", Type: AgentTypeExecutor, ScratchDir: t.TempDir(), CustomConfig: map[string]interface{}{"dir": t.TempDir()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor := ag.(*ExecutorAgent)
+	manager := locks.NewManager(nil)
+	release, _ := manager.TryAcquire(locks.Owner{TaskID: "other"}, locks.Dir(executor.dir))
+	defer release()
+
+	run := func(input map[string]interface{}) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		ctx = locks.WithOwner(ctx, manager, locks.Owner{TaskID: "t", AgentID: "exec"})
+		_, err := ag.ExecuteTask(ctx, Task{ID: "t", Type: TaskTypeCommand, Input: input})
+		return err
+	}
+	// Commands wait for the lock of their directory
+	if err := run(map[string]interface{}{"command": "true"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want %v", err, context.DeadlineExceeded)
+	}
+	// unless they name the resources they lock
+	if err := run(map[string]interface{}{"command": "true", "locks": []interface{}{"file:go.mod"}}); err != nil {
+		t.Errorf("err = %v", err)
+	}
+	if held := manager.Locks(); len(held) != 1 {
+		t.Errorf("locks = %+v", held)
+	}
+}
+
+func TestExecutorDryRun(t *testing.T) {
+	dir := t.TempDir()
+	ag, err := New(AgentConfig{ID: "exec", Type: AgentTypeExecutor, ScratchDir: t.TempDir(), CustomConfig: map[string]interface{}{"dir": dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var progress []TaskProgress
+	ctx := WithDryRun(WithProgress(context.Background(), func(p TaskProgress) {
+		progress = append(progress, p)
+	}))
+
+	result, err := ag.ExecuteTask(ctx, Task{ID: "t", Type: TaskTypeCommand, Input: map[string]interface{}{"command": "touch ran"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := result.Output["command"].(*CommandOutput)
+	if !result.Success || !output.DryRun || result.Metadata["dry_run"] != true {
+		t.Errorf("result = %+v, output = %+v, want a successful dry run", result, output)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ran")); !os.IsNotExist(err) {
+		t.Error("the command ran in a dry run")
+	}
+	if len(progress) != 1 || progress[0].Details["command"] != "touch ran" {
+		t.Errorf("progress = %+v, want the command reported", progress)
+	}
+}
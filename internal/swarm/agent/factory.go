@@ -0,0 +1,50 @@
+package agent
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Factory creates an agent from its configuration
+type Factory func(config AgentConfig) (Agent, error)
+
+var (
+	factories   = make(map[AgentType]Factory)
+	factoriesMu sync.RWMutex
+)
+
+// RegisterFactory makes New create agents of a type with f, replacing any
+// factory registered for the type before
+func RegisterFactory(agentType AgentType, f Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[agentType] = f
+}
+
+// HasFactory reports whether agents of a type have a specialized
+// implementation
+func HasFactory(agentType AgentType) bool {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	_, ok := factories[agentType]
+	return ok
+}
+
+// New creates the agent described by config. Types with a registered
+// factory use it, any other agent with a provider becomes a
+// DocumentationAgent for the documentation type and a ModelAgent otherwise.
+func New(config AgentConfig) (Agent, error) {
+	factoriesMu.RLock()
+	f, ok := factories[config.Type]
+	factoriesMu.RUnlock()
+	if ok {
+		return f(config)
+	}
+	if config.ProviderType != "" {
+		if config.Type == AgentTypeDocumentation {
+			return NewDocumentationAgent(config)
+		}
+		return NewModelAgent(config)
+	}
+	return nil, fmt.Errorf("agent %s: no factory for type %q and no provider configured", config.ID, config.Type)
+}
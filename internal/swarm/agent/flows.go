@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxFlowTimes bounds the send times kept per pair of agents, enough to
+// tell the recent rate of busy pairs
+const maxFlowTimes = 256
+
+// MessageFlow counts the messages one agent sent another. To is empty for
+// broadcasts.
+type MessageFlow struct {
+	From  string
+	To    string
+	Count int
+	Last  time.Time
+	// times holds the latest send times, oldest first
+	times []time.Time
+}
+
+// Since returns how many of the latest messages were sent after t. Counts
+// of busy pairs stop at the send times kept.
+func (f MessageFlow) Since(t time.Time) int {
+	i := sort.Search(len(f.times), func(i int) bool { return f.times[i].After(t) })
+	return len(f.times) - i
+}
+
+// flowKey identifies the messages from one agent to another
+type flowKey struct {
+	from, to string
+}
+
+// flowCounter records the messages the broker let through
+type flowCounter struct {
+	flows map[flowKey]*MessageFlow
+	mu    sync.Mutex
+}
+
+func newFlowCounter() *flowCounter {
+	return &flowCounter{flows: make(map[flowKey]*MessageFlow)}
+}
+
+// record counts a message sent at the time it carries, or now
+func (fc *flowCounter) record(msg Message) {
+	at := msg.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	key := flowKey{msg.From, msg.To}
+	flow, ok := fc.flows[key]
+	if !ok {
+		flow = &MessageFlow{From: msg.From, To: msg.To}
+		fc.flows[key] = flow
+	}
+	flow.Count++
+	if at.After(flow.Last) {
+		flow.Last = at
+	}
+	// Messages may carry times out of order, keep them sorted
+	i := sort.Search(len(flow.times), func(i int) bool { return flow.times[i].After(at) })
+	flow.times = slices.Insert(flow.times, i, at)
+	if len(flow.times) > maxFlowTimes {
+		flow.times = flow.times[len(flow.times)-maxFlowTimes:]
+	}
+}
+
+// forget drops the flows from and to an agent
+func (fc *flowCounter) forget(agentID string) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	for key := range fc.flows {
+		if key.from == agentID || key.to == agentID {
+			delete(fc.flows, key)
+		}
+	}
+}
+
+// list returns copies of the flows ordered by sender and recipient
+func (fc *flowCounter) list() []MessageFlow {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	flows := make([]MessageFlow, 0, len(fc.flows))
+	for _, flow := range fc.flows {
+		f := *flow
+		f.times = append([]time.Time(nil), flow.times...)
+		flows = append(flows, f)
+	}
+	sort.Slice(flows, func(i, j int) bool {
+		if flows[i].From != flows[j].From {
+			return flows[i].From < flows[j].From
+		}
+		return flows[i].To < flows[j].To
+	})
+	return flows
+}
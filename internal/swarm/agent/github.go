@@ -0,0 +1,568 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Task types of the GitHub agent
+const (
+	// TaskTypeGitHubIssue opens an issue titled by its "title" input, or
+	// finds the open one of its "signature" input
+	TaskTypeGitHubIssue = "github_issue"
+	// TaskTypeGitHubPR pushes the *Patch of its "patch" input to a branch
+	// and opens a draft pull request for it, or pushes it to the branch of
+	// its "pull_request" input
+	TaskTypeGitHubPR = "github_pr"
+	// TaskTypeGitHubReviews reads the review comments of its "pull_request"
+	// input made since its "since" input and turns them into patch tasks
+	TaskTypeGitHubReviews = "github_reviews"
+)
+
+const (
+	// DefaultGitHubAPI is the API the GitHub agent talks to unless
+	// configured otherwise, e.g. for GitHub Enterprise
+	DefaultGitHubAPI = "https://api.github.com"
+	// DefaultGitHubTokenSecret is the secret holding the token of the
+	// GitHub agent, set with opencode swarm secrets set github
+	DefaultGitHubTokenSecret = "github"
+	// GitHubLabel labels the issues the swarm opens
+	GitHubLabel = "swarm"
+
+	// maxGitHubResponse bounds the API responses read
+	maxGitHubResponse = 10 << 20
+)
+
+var (
+	// ErrNoGitHubToken is returned when the secrets hold no GitHub token
+	ErrNoGitHubToken = errors.New("no GitHub token")
+	// ErrNoPatch is returned for github_pr tasks without a patch
+	ErrNoPatch = errors.New("no patch to open a pull request for")
+	// ErrNoPullRequest is returned for github_reviews tasks without a pull
+	// request
+	ErrNoPullRequest = errors.New("no pull request")
+)
+
+// GitHubReviewComment is a review comment on a line of a pull request
+type GitHubReviewComment struct {
+	ID     int64  `json:"id"`
+	Author string `json:"author"`
+	// Path is relative to the repository
+	Path      string    `json:"path"`
+	Line      int       `json:"line,omitempty"`
+	Body      string    `json:"body"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GitHubAgent opens issues and draft pull requests on a GitHub repository
+// and reads the reviews of its pull requests. A github_issue task opens an
+// issue labeled GitHubLabel, unless one is open for its "signature" input.
+// A github_pr task commits the files of a patch on top of the base branch
+// and opens a draft pull request. A github_reviews task turns the review
+// comments of a pull request into patch tasks that push their fix to the
+// pull request's branch.
+//
+// CustomConfig must set "repo", as owner/name, and may set "dir", the
+// checkout of the repository patches are made in, the working directory by
+// default, "base", the branch pull requests target, the default branch of
+// the repository by default, "apiURL", DefaultGitHubAPI by default, and
+// "tokenSecret", the secret holding the token, DefaultGitHubTokenSecret by
+// default.
+type GitHubAgent struct {
+	*ModelAgent
+	repo        string
+	dir         string
+	base        string
+	api         string
+	tokenSecret string
+	http        *http.Client
+}
+
+func init() {
+	RegisterFactory(AgentTypeGitHub, func(config AgentConfig) (Agent, error) {
+		return NewGitHubAgent(config)
+	})
+}
+
+// NewGitHubAgent creates a GitHub agent
+func NewGitHubAgent(config AgentConfig) (*GitHubAgent, error) {
+	repo := customString(config, "repo")
+	if owner, name, ok := strings.Cut(repo, "/"); !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("agent %s: invalid repo %q, expected owner/name", config.ID, repo)
+	}
+	dir := customString(config, "dir")
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("agent %s: %w", config.ID, err)
+	}
+	api := customString(config, "apiURL")
+	if api == "" {
+		api = DefaultGitHubAPI
+	}
+	if u, err := url.Parse(api); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("agent %s: invalid apiURL %q", config.ID, api)
+	}
+	tokenSecret := customString(config, "tokenSecret")
+	if tokenSecret == "" {
+		tokenSecret = DefaultGitHubTokenSecret
+	}
+
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = 1
+	}
+	base := NewBaseAgent(config)
+	return &GitHubAgent{
+		ModelAgent:  &ModelAgent{BaseAgent: base, slots: newTaskSlots(base)},
+		repo:        repo,
+		dir:         dir,
+		base:        customString(config, "base"),
+		api:         strings.TrimRight(api, "/"),
+		tokenSecret: tokenSecret,
+		http:        &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// CanHandleTask accepts github_issue, github_pr and github_reviews tasks
+func (a *GitHubAgent) CanHandleTask(task Task) bool {
+	switch task.Type {
+	case TaskTypeGitHubIssue, TaskTypeGitHubPR, TaskTypeGitHubReviews:
+		return true
+	}
+	return false
+}
+
+// ExecuteTask talks to GitHub. The results of github_issue tasks have the
+// "issue" number and "url" as output, along with "existing" for an issue
+// that was open already, those of github_pr tasks the "pull_request"
+// number, its "url" and "branch", and those of github_reviews tasks the
+// []GitHubReviewComment as "comments", the patch tasks to queue as "tasks"
+// and "closed" once the pull request was closed or merged.
+func (a *GitHubAgent) ExecuteTask(ctx context.Context, task Task) (*TaskResult, error) {
+	if !a.CanHandleTask(task) {
+		return nil, fmt.Errorf("agent %s: cannot handle %s tasks", a.id, task.Type)
+	}
+	if !a.slots.acquire() {
+		return nil, fmt.Errorf("%w: %s is running %d tasks", ErrAgentBusy, a.id, a.maxConcurrency())
+	}
+	defer a.slots.release()
+
+	start := time.Now()
+	result := &TaskResult{
+		TaskID:  task.ID,
+		AgentID: a.id,
+	}
+	var output map[string]interface{}
+	token, err := a.token()
+	if err == nil {
+		switch {
+		case IsDryRun(ctx) && task.Type != TaskTypeGitHubReviews:
+			// Reviews are only read, issues and pull requests would be
+			// opened
+			output = a.dryRun(ctx, task)
+		case task.Type == TaskTypeGitHubIssue:
+			output, err = a.openIssue(ctx, token, task)
+		case task.Type == TaskTypeGitHubPR:
+			output, err = a.openPullRequest(ctx, token, task)
+		default:
+			output, err = a.syncReviews(ctx, token, task)
+		}
+	}
+	result.ExecutionTime = time.Since(start)
+	result.CompletedAt = time.Now()
+	a.RecordTask(result.ExecutionTime, err == nil)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+	result.Success = true
+	result.Output = output
+	result.Metadata = map[string]interface{}{"repo": a.repo}
+	return result, nil
+}
+
+// dryRun reports the issue or pull request a task would open, without
+// opening it
+func (a *GitHubAgent) dryRun(ctx context.Context, task Task) map[string]interface{} {
+	what := "an issue"
+	if task.Type == TaskTypeGitHubPR {
+		what = "a pull request"
+	}
+	message := fmt.Sprintf("dry run: would open %s on %s", what, a.repo)
+	ReportProgress(ctx, TaskProgress{
+		TaskID:  task.ID,
+		AgentID: a.id,
+		Stage:   "dry run",
+		Message: message,
+		Details: map[string]interface{}{"repo": a.repo, "dry_run": true},
+	})
+	return map[string]interface{}{"response": message, "dry_run": true}
+}
+
+// token returns the token of the agent from the secrets manager
+func (a *GitHubAgent) token() (string, error) {
+	if a.config.Secrets == nil {
+		return "", fmt.Errorf("%w: no secrets configured", ErrNoGitHubToken)
+	}
+	token, err := a.config.Secrets.Get(a.tokenSecret)
+	if err != nil {
+		return "", fmt.Errorf("%w in secret %q: %w", ErrNoGitHubToken, a.tokenSecret, err)
+	}
+	if token == "" {
+		return "", fmt.Errorf("%w in secret %q", ErrNoGitHubToken, a.tokenSecret)
+	}
+	return token, nil
+}
+
+// signatureMarker is hidden in the body of the issue opened for an error
+// signature, to find it again
+func signatureMarker(signature string) string {
+	return fmt.Sprintf("<!-- swarm-signature: %s -->", strings.ReplaceAll(signature, "--", "- -"))
+}
+
+func (a *GitHubAgent) openIssue(ctx context.Context, token string, task Task) (map[string]interface{}, error) {
+	title, _ := task.Input["title"].(string)
+	if title == "" {
+		title = task.Description
+	}
+	if title == "" {
+		return nil, errors.New("no issue title")
+	}
+	body, _ := task.Input["body"].(string)
+	signature, _ := task.Input["signature"].(string)
+
+	type issue struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+		Body    string `json:"body"`
+	}
+	if signature != "" {
+		marker := signatureMarker(signature)
+		var open []issue
+		query := url.Values{"state": {"open"}, "labels": {GitHubLabel}, "per_page": {"100"}}
+		if err := a.call(ctx, token, http.MethodGet, a.repoPath("issues")+"?"+query.Encode(), nil, &open); err != nil {
+			return nil, err
+		}
+		for _, found := range open {
+			if strings.Contains(found.Body, marker) {
+				return map[string]interface{}{
+					"response": fmt.Sprintf("Issue #%d is open for %s: %s", found.Number, signature, found.HTMLURL),
+					"issue":    found.Number,
+					"url":      found.HTMLURL,
+					"existing": true,
+				}, nil
+			}
+		}
+		body += "\n\n" + marker
+	}
+
+	labels := []string{GitHubLabel}
+	labels = append(labels, stringList(task.Input["labels"])...)
+	var created issue
+	request := map[string]interface{}{"title": title, "body": body, "labels": labels}
+	if err := a.call(ctx, token, http.MethodPost, a.repoPath("issues"), request, &created); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"response": fmt.Sprintf("Opened issue #%d: %s", created.Number, created.HTMLURL),
+		"issue":    created.Number,
+		"url":      created.HTMLURL,
+		"existing": false,
+	}, nil
+}
+
+func (a *GitHubAgent) openPullRequest(ctx context.Context, token string, task Task) (map[string]interface{}, error) {
+	patch, ok := task.Input["patch"].(*Patch)
+	if !ok || patch == nil || len(patch.Files) == 0 {
+		return nil, ErrNoPatch
+	}
+	files := make([]map[string]interface{}, len(patch.Files))
+	for i, edit := range patch.Files {
+		rel, err := filepath.Rel(a.dir, edit.Path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s is not in the repository at %s", edit.Path, a.dir)
+		}
+		files[i] = map[string]interface{}{
+			"path":    filepath.ToSlash(rel),
+			"mode":    "100644",
+			"type":    "blob",
+			"content": edit.Proposed,
+		}
+	}
+
+	title, _ := task.Input["title"].(string)
+	if title == "" {
+		title = task.Description
+	}
+	if title == "" {
+		title = "Swarm patch"
+	}
+	body, _ := task.Input["body"].(string)
+	number := intInput(task.Input["pull_request"])
+	branch, _ := task.Input["branch"].(string)
+	if number > 0 && branch == "" {
+		return nil, fmt.Errorf("no branch for pull request #%d", number)
+	}
+	if branch == "" {
+		branch = "swarm/" + task.ID
+	}
+	base := a.base
+	if base == "" {
+		var repo struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if err := a.call(ctx, token, http.MethodGet, a.repoPath(""), nil, &repo); err != nil {
+			return nil, err
+		}
+		base = repo.DefaultBranch
+	}
+
+	// Commit on top of the base branch, or of the branch of the pull
+	// request pushed to
+	parent := base
+	if number > 0 {
+		parent = branch
+	}
+	var ref struct {
+		Object struct {
+			SHA string `json:"sha"`
+		} `json:"object"`
+	}
+	if err := a.call(ctx, token, http.MethodGet, a.repoPath("git/ref/heads/"+parent), nil, &ref); err != nil {
+		return nil, err
+	}
+	var commit struct {
+		SHA  string `json:"sha"`
+		Tree struct {
+			SHA string `json:"sha"`
+		} `json:"tree"`
+	}
+	if err := a.call(ctx, token, http.MethodGet, a.repoPath("git/commits/"+ref.Object.SHA), nil, &commit); err != nil {
+		return nil, err
+	}
+	var tree struct {
+		SHA string `json:"sha"`
+	}
+	if err := a.call(ctx, token, http.MethodPost, a.repoPath("git/trees"), map[string]interface{}{"base_tree": commit.Tree.SHA, "tree": files}, &tree); err != nil {
+		return nil, err
+	}
+	message := title
+	if body != "" {
+		message += "\n\n" + body
+	}
+	var created struct {
+		SHA string `json:"sha"`
+	}
+	if err := a.call(ctx, token, http.MethodPost, a.repoPath("git/commits"), map[string]interface{}{"message": message, "tree": tree.SHA, "parents": []string{commit.SHA}}, &created); err != nil {
+		return nil, err
+	}
+
+	output := map[string]interface{}{"branch": branch, "commit": created.SHA}
+	if number > 0 {
+		if err := a.call(ctx, token, http.MethodPatch, a.repoPath("git/refs/heads/"+branch), map[string]interface{}{"sha": created.SHA}, nil); err != nil {
+			return nil, err
+		}
+		output["pull_request"] = number
+		output["response"] = fmt.Sprintf("Pushed %s to pull request #%d", created.SHA, number)
+		return output, nil
+	}
+	if err := a.call(ctx, token, http.MethodPost, a.repoPath("git/refs"), map[string]interface{}{"ref": "refs/heads/" + branch, "sha": created.SHA}, nil); err != nil {
+		return nil, err
+	}
+	var pr struct {
+		Number  int    `json:"number"`
+		HTMLURL string `json:"html_url"`
+	}
+	request := map[string]interface{}{"title": title, "body": body, "head": branch, "base": base, "draft": true}
+	if err := a.call(ctx, token, http.MethodPost, a.repoPath("pulls"), request, &pr); err != nil {
+		return nil, err
+	}
+	output["pull_request"] = pr.Number
+	output["url"] = pr.HTMLURL
+	output["response"] = fmt.Sprintf("Opened draft pull request #%d: %s", pr.Number, pr.HTMLURL)
+	return output, nil
+}
+
+func (a *GitHubAgent) syncReviews(ctx context.Context, token string, task Task) (map[string]interface{}, error) {
+	number := intInput(task.Input["pull_request"])
+	if number <= 0 {
+		return nil, ErrNoPullRequest
+	}
+	var pr struct {
+		State  string `json:"state"`
+		Merged bool   `json:"merged"`
+		Head   struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+	}
+	path := a.repoPath(fmt.Sprintf("pulls/%d", number))
+	if err := a.call(ctx, token, http.MethodGet, path, nil, &pr); err != nil {
+		return nil, err
+	}
+
+	query := url.Values{"per_page": {"100"}}
+	switch since := task.Input["since"].(type) {
+	case time.Time:
+		if !since.IsZero() {
+			query.Set("since", since.UTC().Format(time.RFC3339))
+		}
+	case string:
+		if since != "" {
+			query.Set("since", since)
+		}
+	}
+	var raw []struct {
+		ID          int64  `json:"id"`
+		InReplyToID int64  `json:"in_reply_to_id"`
+		Path        string `json:"path"`
+		Line        int    `json:"line"`
+		Body        string `json:"body"`
+		HTMLURL     string `json:"html_url"`
+		User        struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	if err := a.call(ctx, token, http.MethodGet, path+"/comments?"+query.Encode(), nil, &raw); err != nil {
+		return nil, err
+	}
+
+	var comments []GitHubReviewComment
+	var tasks []Task
+	for _, c := range raw {
+		// Replies discuss a comment already turned into a task
+		if c.InReplyToID != 0 {
+			continue
+		}
+		comment := GitHubReviewComment{
+			ID:        c.ID,
+			Author:    c.User.Login,
+			Path:      c.Path,
+			Line:      c.Line,
+			Body:      c.Body,
+			URL:       c.HTMLURL,
+			CreatedAt: c.CreatedAt,
+		}
+		comments = append(comments, comment)
+		if pr.State == "open" {
+			tasks = append(tasks, reviewTask(a.dir, number, pr.Head.Ref, comment))
+		}
+	}
+	closed := pr.State != "open"
+	response := fmt.Sprintf("%d new review comments on pull request #%d", len(comments), number)
+	if closed {
+		response += ", which is closed"
+	}
+	return map[string]interface{}{
+		"response":     response,
+		"pull_request": number,
+		"comments":     comments,
+		"tasks":        tasks,
+		"closed":       closed,
+		"merged":       pr.Merged,
+	}, nil
+}
+
+// reviewTask is the patch task addressing a review comment. Its fix is
+// pushed to the branch of the pull request once the swarm approved it.
+func reviewTask(dir string, number int, branch string, comment GitHubReviewComment) Task {
+	first, _, _ := strings.Cut(strings.TrimSpace(comment.Body), "\n")
+	return Task{
+		Type:        TaskTypePatch,
+		Description: fmt.Sprintf("Address review comment on %s:%d: %s", comment.Path, comment.Line, first),
+		Input: map[string]interface{}{
+			"path":           filepath.Join(dir, filepath.FromSlash(comment.Path)),
+			"line":           comment.Line,
+			"review_comment": comment.Body,
+			"reviewer":       comment.Author,
+			"comment_url":    comment.URL,
+			"pull_request":   number,
+			"branch":         branch,
+		},
+		RequiresVote:   true,
+		IdempotencyKey: fmt.Sprintf("github_review:%d", comment.ID),
+	}
+}
+
+// repoPath returns the API path of a resource of the repository
+func (a *GitHubAgent) repoPath(resource string) string {
+	if resource == "" {
+		return "/repos/" + a.repo
+	}
+	return "/repos/" + a.repo + "/" + resource
+}
+
+// call sends a request to the API and decodes its JSON response into out,
+// if not nil
+func (a *GitHubAgent) call(ctx context.Context, token, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.api+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxGitHubResponse))
+	if err != nil {
+		return fmt.Errorf("GitHub %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		if apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return fmt.Errorf("GitHub %s %s: %s", method, path, apiErr.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("GitHub %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// intInput returns a numeric input, which is a float64 in tasks submitted
+// as JSON
+func intInput(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+	return 0
+}
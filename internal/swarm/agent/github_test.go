@@ -0,0 +1,213 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type staticSecrets map[string]string
+
+func (s staticSecrets) Get(name string) (string, error) {
+	if v, ok := s[name]; ok {
+		return v, nil
+	}
+	return "", errors.New("not found")
+}
+
+// fakeGitHub serves the parts of the GitHub API the agent uses and records
+// the requests it got
+type fakeGitHub struct {
+	mu       sync.Mutex
+	requests []string
+	bodies   map[string]map[string]interface{}
+	issues   []map[string]interface{}
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("Authorization") != "Bearer secret-token" {
+		http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+		return
+	}
+	key := r.Method + " " + r.URL.Path
+	f.requests = append(f.requests, key)
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	f.bodies[key] = body
+
+	reply := func(v interface{}) { _ = json.NewEncoder(w).Encode(v) }
+	switch key {
+	case "GET /repos/acme/app/issues":
+		reply(f.issues)
+	case "POST /repos/acme/app/issues":
+		issue := map[string]interface{}{"number": 7, "html_url": "https://github.com/acme/app/issues/7", "body": body["body"]}
+		f.issues = append(f.issues, issue)
+		reply(issue)
+	case "GET /repos/acme/app":
+		reply(map[string]string{"default_branch": "main"})
+	case "GET /repos/acme/app/git/ref/heads/main", "GET /repos/acme/app/git/ref/heads/swarm/fix":
+		reply(map[string]interface{}{"object": map[string]string{"sha": "base-sha"}})
+	case "GET /repos/acme/app/git/commits/base-sha":
+		reply(map[string]interface{}{"sha": "base-sha", "tree": map[string]string{"sha": "base-tree"}})
+	case "POST /repos/acme/app/git/trees":
+		reply(map[string]string{"sha": "new-tree"})
+	case "POST /repos/acme/app/git/commits":
+		reply(map[string]string{"sha": "new-sha"})
+	case "POST /repos/acme/app/git/refs", "PATCH /repos/acme/app/git/refs/heads/swarm/fix":
+		reply(map[string]string{})
+	case "POST /repos/acme/app/pulls":
+		reply(map[string]interface{}{"number": 12, "html_url": "https://github.com/acme/app/pull/12"})
+	case "GET /repos/acme/app/pulls/12":
+		reply(map[string]interface{}{"state": "open", "head": map[string]string{"ref": "swarm/fix"}})
+	case "GET /repos/acme/app/pulls/12/comments":
+		reply([]map[string]interface{}{
+			{"id": 100, "path": "main.go", "line": 3, "body": "Check the error\nIt is dropped.", "user": map[string]string{"login": "reviewer"}},
+			{"id": 101, "in_reply_to_id": 100, "path": "main.go", "line": 3, "body": "Agreed"},
+		})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestGitHubAgent(t *testing.T, dir string) (*GitHubAgent, *fakeGitHub) {
+	fake := &fakeGitHub{bodies: make(map[string]map[string]interface{})}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	a, err := NewGitHubAgent(AgentConfig{
+		ID:           "github-1",
+		Type:         AgentTypeGitHub,
+		CustomConfig: map[string]interface{}{"repo": "acme/app", "dir": dir, "apiURL": server.URL},
+		Secrets:      staticSecrets{DefaultGitHubTokenSecret: "secret-token"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, fake
+}
+
+func TestGitHubAgentIssues(t *testing.T) {
+	a, fake := newTestGitHubAgent(t, t.TempDir())
+	task := Task{ID: "t1", Type: TaskTypeGitHubIssue, Input: map[string]interface{}{
+		"title":     "Recurring error: nil map",
+		"body":      "It keeps coming back",
+		"signature": "panic: assignment to entry in nil map",
+	}}
+	result, err := a.ExecuteTask(context.Background(), task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output["issue"] != 7 || result.Output["existing"] != false {
+		t.Fatalf("output = %v, want new issue 7", result.Output)
+	}
+	labels, _ := fake.bodies["POST /repos/acme/app/issues"]["labels"].([]interface{})
+	if len(labels) != 1 || labels[0] != GitHubLabel {
+		t.Errorf("labels = %v, want %s", labels, GitHubLabel)
+	}
+
+	// The issue open for the signature is found again
+	result, err = a.ExecuteTask(context.Background(), task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output["issue"] != 7 || result.Output["existing"] != true {
+		t.Errorf("output = %v, want existing issue 7", result.Output)
+	}
+}
+
+func TestGitHubAgentDryRun(t *testing.T) {
+	a, fake := newTestGitHubAgent(t, t.TempDir())
+	ctx := WithDryRun(context.Background())
+	for _, taskType := range []string{TaskTypeGitHubIssue, TaskTypeGitHubPR} {
+		result, err := a.ExecuteTask(ctx, Task{ID: "t1", Type: taskType, Input: map[string]interface{}{"title": "Fix the nil map"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Output["dry_run"] != true {
+			t.Errorf("%s output = %v, want a dry run", taskType, result.Output)
+		}
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("requests = %v, want none in a dry run", fake.requests)
+	}
+}
+
+func TestGitHubAgentPullRequest(t *testing.T) {
+	dir := t.TempDir()
+	a, fake := newTestGitHubAgent(t, dir)
+	patch := NewPatch(dir, []FileEdit{{Path: filepath.Join(dir, "cmd", "main.go"), Original: "a\n", Proposed: "b\n"}})
+	result, err := a.ExecuteTask(context.Background(), Task{ID: "t2", Type: TaskTypeGitHubPR, Description: "Fix nil map", Input: map[string]interface{}{"patch": patch}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Output["pull_request"] != 12 || result.Output["branch"] != "swarm/t2" {
+		t.Fatalf("output = %v, want pull request 12 from swarm/t2", result.Output)
+	}
+	tree, _ := fake.bodies["POST /repos/acme/app/git/trees"]["tree"].([]interface{})
+	if len(tree) != 1 || tree[0].(map[string]interface{})["path"] != "cmd/main.go" {
+		t.Errorf("tree = %v, want cmd/main.go", tree)
+	}
+	pr := fake.bodies["POST /repos/acme/app/pulls"]
+	if pr["draft"] != true || pr["base"] != "main" || pr["head"] != "swarm/t2" {
+		t.Errorf("pull request = %v, want a draft from swarm/t2 to main", pr)
+	}
+
+	// Files outside the repository are refused
+	outside := NewPatch(dir, []FileEdit{{Path: filepath.Join(filepath.Dir(dir), "other.go"), Proposed: "x"}})
+	if _, err := a.ExecuteTask(context.Background(), Task{ID: "t3", Type: TaskTypeGitHubPR, Input: map[string]interface{}{"patch": outside}}); err == nil {
+		t.Error("patch outside the repository was pushed")
+	}
+
+	// A fix for a pull request is pushed to its branch
+	fake.requests = nil
+	_, err = a.ExecuteTask(context.Background(), Task{ID: "t4", Type: TaskTypeGitHubPR, Input: map[string]interface{}{"patch": patch, "pull_request": 12, "branch": "swarm/fix"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(fake.requests, ", "); !strings.Contains(got, "PATCH /repos/acme/app/git/refs/heads/swarm/fix") || strings.Contains(got, "POST /repos/acme/app/pulls") {
+		t.Errorf("requests = %s, want the branch updated and no new pull request", got)
+	}
+}
+
+func TestGitHubAgentReviews(t *testing.T) {
+	dir := t.TempDir()
+	a, _ := newTestGitHubAgent(t, dir)
+	result, err := a.ExecuteTask(context.Background(), Task{ID: "t5", Type: TaskTypeGitHubReviews, Input: map[string]interface{}{"pull_request": float64(12)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks, _ := result.Output["tasks"].([]Task)
+	if len(tasks) != 1 {
+		t.Fatalf("tasks = %v, want one for the comment that is no reply", tasks)
+	}
+	task := tasks[0]
+	if task.Type != TaskTypePatch || !task.RequiresVote || task.IdempotencyKey != "github_review:100" {
+		t.Errorf("task = %+v", task)
+	}
+	if task.Input["path"] != filepath.Join(dir, "main.go") || task.Input["branch"] != "swarm/fix" || task.Input["pull_request"] != 12 {
+		t.Errorf("input = %v", task.Input)
+	}
+	if result.Output["closed"] != false {
+		t.Errorf("closed = %v, want false", result.Output["closed"])
+	}
+}
+
+func TestGitHubAgentToken(t *testing.T) {
+	a, err := NewGitHubAgent(AgentConfig{ID: "github-1", CustomConfig: map[string]interface{}{"repo": "acme/app"}, Secrets: staticSecrets{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.ExecuteTask(context.Background(), Task{Type: TaskTypeGitHubIssue, Description: "x"}); !errors.Is(err, ErrNoGitHubToken) {
+		t.Errorf("err = %v, want ErrNoGitHubToken", err)
+	}
+	if _, err := NewGitHubAgent(AgentConfig{ID: "github-2", CustomConfig: map[string]interface{}{"repo": "acme"}}); err == nil {
+		t.Error("repo without owner accepted")
+	}
+}
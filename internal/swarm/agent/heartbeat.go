@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"slices"
+	"time"
+)
+
+// SetHeartbeatTimeout sets how long an agent that sent heartbeats may go
+// without one before it is unreachable, zero to never mark agents
+// unreachable
+func (r *Registry) SetHeartbeatTimeout(timeout time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.heartbeatTimeout = timeout
+}
+
+// Heartbeat records a heartbeat of a registered agent, at the time of the
+// message. Agents registered with the registry send it theirs.
+func (r *Registry) Heartbeat(msg Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.agents[msg.From]; !ok {
+		return
+	}
+	if last, ok := r.heartbeats[msg.From]; !ok || msg.Timestamp.After(last) {
+		r.heartbeats[msg.From] = msg.Timestamp
+	}
+}
+
+// CheckHeartbeats marks the agents whose last heartbeat is older than the
+// heartbeat timeout at now unreachable, and those that sent one since
+// reachable again. It returns the IDs of the agents that became
+// unreachable and of those that recovered, sorted. Agents that never sent
+// a heartbeat are not checked, nor are stopped ones, which are forgotten
+// until they send heartbeats again.
+func (r *Registry) CheckHeartbeats(now time.Time) (lost, recovered []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, last := range r.heartbeats {
+		if r.agents[id].GetStatus() == AgentStatusStopped {
+			delete(r.heartbeats, id)
+			delete(r.unreachable, id)
+			continue
+		}
+		late := r.heartbeatTimeout > 0 && now.Sub(last) > r.heartbeatTimeout
+		switch {
+		case late && !r.unreachable[id]:
+			r.unreachable[id] = true
+			lost = append(lost, id)
+		case !late && r.unreachable[id]:
+			delete(r.unreachable, id)
+			recovered = append(recovered, id)
+		}
+	}
+	slices.Sort(lost)
+	slices.Sort(recovered)
+	return lost, recovered
+}
+
+// IsUnreachable reports whether an agent missed its heartbeats
+func (r *Registry) IsUnreachable(id string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.unreachable[id]
+}
@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+func TestHeartbeats(t *testing.T) {
+	r := NewRegistry()
+	r.SetHeartbeatTimeout(3 * time.Second)
+	agents := startedAgents(t, r,
+		AgentConfig{ID: "a", Type: AgentTypeTesting},
+		AgentConfig{ID: "b", Type: AgentTypeTesting},
+		AgentConfig{ID: "quiet", Type: AgentTypeTesting},
+	)
+	task := Task{ID: "t1", Type: "testing"}
+
+	start := time.Now()
+	for _, id := range []string{"a", "b"} {
+		r.Heartbeat(Message{From: id, Type: MessageTypeHeartbeat, Timestamp: start})
+	}
+	r.Heartbeat(Message{From: "unknown", Type: MessageTypeHeartbeat, Timestamp: start})
+
+	// b misses its heartbeats, the quiet agent never sent any
+	r.Heartbeat(Message{From: "a", Type: MessageTypeHeartbeat, Timestamp: start.Add(2 * time.Second)})
+	lost, recovered := r.CheckHeartbeats(start.Add(4 * time.Second))
+	if !slices.Equal(lost, []string{"b"}) || len(recovered) != 0 {
+		t.Fatalf("lost %v and recovered %v, want b lost", lost, recovered)
+	}
+	if !r.IsUnreachable("b") || r.IsUnreachable("a") || r.IsUnreachable("quiet") {
+		t.Error("only b should be unreachable")
+	}
+	var found []string
+	for _, ag := range r.FindAgentsForTask(task) {
+		found = append(found, ag.GetID())
+	}
+	if slices.Contains(found, "b") || len(found) != 2 {
+		t.Errorf("agents found = %v, want a and quiet", found)
+	}
+	if health := r.GetHealthStatus()["b"]; !health.Unreachable || !health.LastHeartbeat.Equal(start) {
+		t.Errorf("health of b = %+v", health)
+	}
+
+	// Reported once, until it sends a heartbeat again
+	if lost, _ := r.CheckHeartbeats(start.Add(5 * time.Second)); len(lost) != 0 {
+		t.Errorf("lost %v again", lost)
+	}
+	r.Heartbeat(Message{From: "b", Type: MessageTypeHeartbeat, Timestamp: start.Add(5 * time.Second)})
+	if _, recovered := r.CheckHeartbeats(start.Add(5 * time.Second)); !slices.Equal(recovered, []string{"b"}) {
+		t.Errorf("recovered %v, want b", recovered)
+	}
+
+	// Stopped agents are not missed
+	if err := agents["a"].Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if lost, _ := r.CheckHeartbeats(start.Add(time.Minute)); !slices.Equal(lost, []string{"b"}) {
+		t.Errorf("lost %v, want only b", lost)
+	}
+}
+
+func TestBaseAgentSendsHeartbeats(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC))
+	r := NewRegistry()
+	startedAgents(t, r, AgentConfig{ID: "a", Type: AgentTypeTesting, HeartbeatInterval: time.Minute, Clock: fake})
+
+	// Heartbeats tick and are dated by the clock of the agent
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	deadline := time.Now().Add(5 * time.Second)
+	for !r.GetHealthStatus()["a"].LastHeartbeat.Equal(fake.Now()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("last heartbeat at %v, want %v", r.GetHealthStatus()["a"].LastHeartbeat, fake.Now())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,139 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/provider"
+)
+
+// Warmer is implemented by agents whose model takes a while to load. The
+// coordinator warms the agents of warm pools when they start and keeps
+// their models resident by warming them again while they are idle.
+type Warmer interface {
+	Warm(ctx context.Context) error
+}
+
+// ModelAgent answers tasks by prompting a language model. It is the agent
+// used for configured types that have no specialized implementation.
+type ModelAgent struct {
+	*BaseAgent
+	client       provider.Client
+	systemPrompt string
+	slots        *taskSlots
+}
+
+// NewModelAgent creates an agent backed by the provider and model of its
+// configuration. CustomConfig may set "baseURL", "apiKey" and
+// "systemPrompt". Without an API key the key stored in the secrets under the
+// "provider" name is used.
+func NewModelAgent(config AgentConfig) (*ModelAgent, error) {
+	client, err := newLiveClient(provider.Config{
+		Type:       config.ProviderType,
+		Model:      config.Model,
+		BaseURL:    customString(config, "baseURL"),
+		APIKey:     customString(config, "apiKey"),
+		Secrets:    config.Secrets,
+		SecretName: customString(config, "provider"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("agent %s: %w", config.ID, err)
+	}
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = 1
+	}
+
+	systemPrompt := customString(config, "systemPrompt")
+	if systemPrompt == "" {
+		systemPrompt = fmt.Sprintf("You are the %s agent of a multi-agent software engineering swarm. Complete the task you are given and answer concisely.", config.Type)
+	}
+	base := NewBaseAgent(config)
+	return &ModelAgent{
+		BaseAgent:    base,
+		client:       client,
+		systemPrompt: systemPrompt,
+		slots:        newTaskSlots(base),
+	}, nil
+}
+
+// CanHandleTask accepts tasks whose type is the agent type or one of its
+// capabilities. Agents without capabilities accept every task.
+func (a *ModelAgent) CanHandleTask(task Task) bool {
+	if len(a.GetCapabilities()) == 0 || task.Type == string(a.agentType) {
+		return true
+	}
+	for _, capability := range a.GetCapabilities() {
+		if capability == task.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// ExecuteTask sends the task to the model and returns its answer as the
+// "response" output
+func (a *ModelAgent) ExecuteTask(ctx context.Context, task Task) (*TaskResult, error) {
+	if !a.slots.acquire() {
+		return nil, fmt.Errorf("%w: %s is running %d tasks", ErrAgentBusy, a.id, a.maxConcurrency())
+	}
+	defer a.slots.release()
+
+	start := time.Now()
+	result := &TaskResult{
+		TaskID:  task.ID,
+		AgentID: a.id,
+	}
+
+	resp, err := a.client.Complete(ctx, provider.Request{
+		System: a.systemPrompt,
+		Prompt: taskPrompt(task),
+	})
+	result.ExecutionTime = time.Since(start)
+	result.CompletedAt = time.Now()
+	a.RecordTask(result.ExecutionTime, err == nil)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	result.Success = true
+	result.Output = map[string]interface{}{"response": resp.Content}
+	result.Metadata = map[string]interface{}{
+		"model":         a.client.Model(),
+		"input_tokens":  resp.InputTokens,
+		"output_tokens": resp.OutputTokens,
+	}
+	return result, nil
+}
+
+// Warm sends the model a one token prompt, which makes local providers such
+// as Ollama load the model and keep it loaded for a while
+func (a *ModelAgent) Warm(ctx context.Context) error {
+	_, err := a.client.Complete(ctx, provider.Request{
+		Prompt:    "ping",
+		MaxTokens: 1,
+	})
+	if err != nil {
+		return fmt.Errorf("agent %s: warm up %s: %w", a.id, a.client.Model(), err)
+	}
+	return nil
+}
+
+// taskPrompt renders a task as the user prompt
+func taskPrompt(task Task) string {
+	prompt := fmt.Sprintf("Task type: %s\n\n%s", task.Type, task.Description)
+	if len(task.Input) > 0 {
+		input, err := json.MarshalIndent(task.Input, "", "  ")
+		if err == nil {
+			prompt += "\n\nInput:\n" + string(input)
+		}
+	}
+	return prompt
+}
+
+func customString(config AgentConfig, key string) string {
+	s, _ := config.CustomConfig[key].(string)
+	return s
+}
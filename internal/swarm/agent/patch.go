@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/aymanbagabas/go-udiff"
+)
+
+// OutputPatch is the output of the *Patch of agents that change files
+const OutputPatch = "patch"
+
+// FileEdit is a change to a file an agent proposes rather than makes. The
+// edit applies only while the file still has its original content.
+type FileEdit struct {
+	// Path is absolute
+	Path     string `json:"path"`
+	Original string `json:"original"`
+	Proposed string `json:"proposed"`
+}
+
+// Patch is the output of agents that change files. Agents never write the
+// files, they propose a patch for an apply step to offer for review and
+// record in the file history, which keeps it revertible. Diff is what a
+// reviewer reads, Files what is applied.
+type Patch struct {
+	// Diff is the unified diff of all files, with paths relative to the
+	// directory of the agent
+	Diff string `json:"diff"`
+	// Files are the edits of the changed files, in the order of the diff
+	Files []FileEdit `json:"files"`
+}
+
+// NewPatch makes the patch of edits, with the paths of its diff relative
+// to dir
+func NewPatch(dir string, edits []FileEdit) *Patch {
+	var b strings.Builder
+	for _, edit := range edits {
+		rel := strings.TrimPrefix(filepath.ToSlash(relativeTo(dir, edit.Path)), "/")
+		b.WriteString(udiff.Unified("a/"+rel, "b/"+rel, edit.Original, edit.Proposed))
+	}
+	return &Patch{Diff: b.String(), Files: edits}
+}
+
+// Paths returns the paths of the changed files
+func (p *Patch) Paths() []string {
+	paths := make([]string, len(p.Files))
+	for i, edit := range p.Files {
+		paths[i] = edit.Path
+	}
+	return paths
+}
+
+// ResultPatch returns the patch of a result's output, if it has one
+func ResultPatch(output map[string]interface{}) (*Patch, bool) {
+	patch, ok := output[OutputPatch].(*Patch)
+	return patch, ok && patch != nil
+}
+
+// relativeTo returns path relative to dir, or path itself if it is not in
+// dir
+func relativeTo(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPermissionDenied means an agent tried something it was not granted
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Permission is something the coordinator lets an agent do
+type Permission string
+
+const (
+	// PermissionReadMemory lets an agent query the memory of the swarm
+	PermissionReadMemory Permission = "read-memory"
+	// PermissionWriteMemory lets an agent store, update and delete memories
+	PermissionWriteMemory Permission = "write-memory"
+	// PermissionWriteFiles lets an agent propose edits to project files
+	PermissionWriteFiles Permission = "write-files"
+	// PermissionRunCommands lets an agent run commands, like tests
+	PermissionRunCommands Permission = "run-commands"
+	// PermissionCastVotes lets an agent vote on the tasks of the swarm
+	PermissionCastVotes Permission = "cast-votes"
+	// PermissionSendMessages lets an agent message every other agent.
+	// "send-messages:<id or type>" only lets it message some.
+	PermissionSendMessages Permission = "send-messages"
+)
+
+// Permissions lists the known permissions, without recipient scopes
+var Permissions = []Permission{
+	PermissionReadMemory,
+	PermissionWriteMemory,
+	PermissionWriteFiles,
+	PermissionRunCommands,
+	PermissionCastVotes,
+	PermissionSendMessages,
+}
+
+// rolePermissions are the permissions of agents by their type, their role
+// in the swarm. Other types get the permissions of the empty type.
+var rolePermissions = map[AgentType][]Permission{
+	"":                     {PermissionReadMemory, PermissionCastVotes, PermissionSendMessages},
+	AgentTypeCoordinator:   {PermissionReadMemory, PermissionWriteMemory, PermissionCastVotes, PermissionSendMessages},
+	AgentTypeMemory:        {PermissionReadMemory, PermissionWriteMemory, PermissionCastVotes, PermissionSendMessages},
+	AgentTypeLearning:      {PermissionReadMemory, PermissionWriteMemory, PermissionCastVotes, PermissionSendMessages},
+	AgentTypeExecutor:      {PermissionReadMemory, PermissionRunCommands, PermissionWriteFiles, PermissionCastVotes, PermissionSendMessages},
+	AgentTypeTesting:       {PermissionReadMemory, PermissionRunCommands, PermissionCastVotes, PermissionSendMessages},
+	AgentTypeDocumentation: {PermissionReadMemory, PermissionWriteFiles, PermissionCastVotes, PermissionSendMessages},
+	AgentTypeWeb:           {PermissionReadMemory, PermissionWriteMemory, PermissionCastVotes, PermissionSendMessages},
+}
+
+// RolePermissions returns the permissions agents of a type are granted
+// unless their configuration lists others
+func RolePermissions(agentType AgentType) []Permission {
+	permissions, ok := rolePermissions[agentType]
+	if !ok {
+		permissions = rolePermissions[""]
+	}
+	return append([]Permission(nil), permissions...)
+}
+
+// ValidatePermission checks that a permission is known. Message permissions
+// may be scoped to a recipient.
+func ValidatePermission(p Permission) error {
+	if scope, ok := strings.CutPrefix(string(p), string(PermissionSendMessages)+":"); ok {
+		if scope == "" {
+			return fmt.Errorf("permission %q names no recipient", p)
+		}
+		return nil
+	}
+	for _, known := range Permissions {
+		if p == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown permission %q", p)
+}
+
+// Grants are the permissions of an agent
+type Grants map[Permission]bool
+
+// NewGrants grants permissions
+func NewGrants(permissions ...Permission) Grants {
+	grants := make(Grants, len(permissions))
+	for _, p := range permissions {
+		grants[p] = true
+	}
+	return grants
+}
+
+// Allows returns whether a permission was granted
+func (g Grants) Allows(p Permission) bool {
+	return g[p]
+}
+
+// CanMessage returns whether messages to an agent are allowed, by its ID or
+// type
+func (g Grants) CanMessage(toID string, toType AgentType) bool {
+	return g[PermissionSendMessages] ||
+		g[PermissionSendMessages+Permission(":"+toID)] ||
+		g[PermissionSendMessages+Permission(":"+string(toType))]
+}
+
+// Check returns ErrPermissionDenied unless a permission was granted
+func (g Grants) Check(agentID string, p Permission) error {
+	if !g.Allows(p) {
+		return fmt.Errorf("%w: agent %s may not %s", ErrPermissionDenied, agentID, p)
+	}
+	return nil
+}
+
+// PermissionRequirer is implemented by agents that need permissions for
+// some tasks. The coordinator does not hand them tasks they lack the
+// permissions for.
+type PermissionRequirer interface {
+	RequiredPermissions(task Task) []Permission
+}
+
+type grantsKey struct{}
+
+type grantsScope struct {
+	agentID string
+	grants  Grants
+}
+
+// WithGrants returns a context for an agent running a task with grants
+func WithGrants(ctx context.Context, agentID string, grants Grants) context.Context {
+	return context.WithValue(ctx, grantsKey{}, grantsScope{agentID: agentID, grants: grants})
+}
+
+// CheckPermission returns ErrPermissionDenied unless the agent running with
+// ctx was granted a permission. Outside a swarm, when ctx has no grants,
+// everything is allowed.
+func CheckPermission(ctx context.Context, p Permission) error {
+	scope, ok := ctx.Value(grantsKey{}).(grantsScope)
+	if !ok {
+		return nil
+	}
+	return scope.grants.Check(scope.agentID, p)
+}
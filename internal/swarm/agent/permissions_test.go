@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCheckPermission(t *testing.T) {
+	ctx := context.Background()
+	if err := CheckPermission(ctx, PermissionRunCommands); err != nil {
+		t.Errorf("outside a swarm: %v, want everything allowed", err)
+	}
+
+	grants := NewGrants(RolePermissions(AgentTypeDocumentation)...)
+	ctx = WithGrants(ctx, "docs", grants)
+	if err := CheckPermission(ctx, PermissionWriteFiles); err != nil {
+		t.Errorf("write-files: %v, want allowed", err)
+	}
+	if err := CheckPermission(ctx, PermissionRunCommands); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("run-commands: %v, want ErrPermissionDenied", err)
+	}
+
+	tester, err := New(AgentConfig{ID: "tests", Type: AgentTypeTesting, ScratchDir: t.TempDir(), CustomConfig: map[string]interface{}{"command": "true"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tester.ExecuteTask(ctx, Task{ID: "t"}); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("ExecuteTask() = %v, want ErrPermissionDenied", err)
+	}
+}
+
+func TestValidatePermission(t *testing.T) {
+	for p, valid := range map[Permission]bool{
+		PermissionCastVotes:       true,
+		"send-messages:memory":    true,
+		"send-messages:":          false,
+		"format-disk":             false,
+		PermissionSendMessages:    true,
+		PermissionRunCommands:     true,
+		"run-commands:go test ./": false,
+	} {
+		if err := ValidatePermission(p); (err == nil) != valid {
+			t.Errorf("ValidatePermission(%q) = %v, want valid = %v", p, err, valid)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package agent
+
+import "context"
+
+// TaskProgress is an update on a running task. Fields left empty keep what
+// was reported before, so an update may only add a log line.
+type TaskProgress struct {
+	TaskID  string
+	AgentID string
+	// Percent is how much of the task is done, from 0 to 100
+	Percent float64
+	// Stage names what the task is doing, e.g. "compiling"
+	Stage string
+	// Message is a log line of the task
+	Message string
+	Details map[string]interface{}
+}
+
+// ProgressReporter is implemented by agents that stream the progress of
+// their tasks. The coordinator reads the channel from when the agent starts
+// until the channel is closed or the swarm stops.
+type ProgressReporter interface {
+	Progress() <-chan TaskProgress
+}
+
+type progressKey struct{}
+
+// WithProgress returns a context for running a task that hands the progress
+// the agent reports to report
+func WithProgress(ctx context.Context, report func(TaskProgress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// ReportProgress reports the progress of the task running with ctx, if
+// whoever runs it listens
+func ReportProgress(ctx context.Context, progress TaskProgress) {
+	if report, ok := ctx.Value(progressKey{}).(func(TaskProgress)); ok {
+		report(progress)
+	}
+}
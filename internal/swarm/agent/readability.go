@@ -0,0 +1,152 @@
+package agent
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// pageNoise are the elements of a page that are not its content
+const pageNoise = "script, style, noscript, iframe, svg, canvas, nav, header, footer, aside, form, button, " +
+	"[role=navigation], [role=banner], [role=contentinfo], [aria-hidden=true]"
+
+// minContentLength is the text an article or main element needs to be
+// taken as the content of a page
+const minContentLength = 200
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// extractReadable returns the title and the main content of an HTML page,
+// as markdown with absolute links
+func extractReadable(page string, base *url.URL) (title, content string, err error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		return "", "", err
+	}
+	title = collapseSpace(doc.Find("title").First().Text())
+	if title == "" {
+		title = collapseSpace(doc.Find("h1").First().Text())
+	}
+
+	doc.Find(pageNoise).Remove()
+	if base != nil {
+		absolute(doc, "a[href]", "href", base)
+		absolute(doc, "img[src]", "src", base)
+	}
+	main := mainContent(doc)
+	fragment, err := goquery.OuterHtml(main)
+	if err != nil {
+		return "", "", err
+	}
+	markdown, err := md.NewConverter("", true, nil).ConvertString(fragment)
+	if err != nil {
+		return "", "", err
+	}
+	return title, strings.TrimSpace(blankLines.ReplaceAllString(markdown, "\n\n")), nil
+}
+
+// absolute resolves the links of an attribute against the page URL
+func absolute(doc *goquery.Document, selector, attr string, base *url.URL) {
+	doc.Find(selector).Each(func(_ int, s *goquery.Selection) {
+		value, _ := s.Attr(attr)
+		if link, err := base.Parse(value); err == nil {
+			s.SetAttr(attr, link.String())
+		}
+	})
+}
+
+// mainContent picks the element with the content of a page: its article or
+// main element, or else the element with the most paragraph text
+func mainContent(doc *goquery.Document) *goquery.Selection {
+	for _, selector := range []string{"article", "main", "[role=main]"} {
+		if s := doc.Find(selector).First(); s.Length() > 0 && len(collapseSpace(s.Text())) >= minContentLength {
+			return s
+		}
+	}
+
+	scores := make(map[*html.Node]int)
+	var best *html.Node
+	doc.Find("p, pre, li").Each(func(_ int, p *goquery.Selection) {
+		length := len(collapseSpace(p.Text()))
+		if length < 25 {
+			return
+		}
+		// Paragraphs count for their parent and half for its parent
+		parent := p.Parent()
+		for i, weight := range []int{2, 1} {
+			if parent.Length() == 0 {
+				break
+			}
+			node := parent.Get(0)
+			scores[node] += length * weight / 2
+			if best == nil || scores[node] > scores[best] {
+				best = node
+			}
+			if i == 0 {
+				parent = parent.Parent()
+			}
+		}
+	})
+	if best != nil {
+		return doc.FindNodes(best)
+	}
+	if body := doc.Find("body"); body.Length() > 0 {
+		return body
+	}
+	return doc.Selection
+}
+
+// pageLinks returns the absolute http links of a page, unwrapping the
+// redirects of search engines, without links to the page's own site
+func pageLinks(page string, base *url.URL) []string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var links []string
+	doc.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+		href, _ := a.Attr("href")
+		link, err := base.Parse(href)
+		if err != nil {
+			return
+		}
+		// e.g. //duckduckgo.com/l/?uddg=https%3A%2F%2Fpkg.go.dev
+		for _, param := range []string{"uddg", "url", "q", "u"} {
+			if target, err := url.Parse(link.Query().Get(param)); err == nil && (target.Scheme == "http" || target.Scheme == "https") {
+				link = target
+				break
+			}
+		}
+		if (link.Scheme != "http" && link.Scheme != "https") || site(link.Hostname()) == site(base.Hostname()) {
+			return
+		}
+		link.Fragment = ""
+		if s := link.String(); !seen[s] {
+			seen[s] = true
+			links = append(links, s)
+		}
+	})
+	return links
+}
+
+// site drops the subdomains of a host, html.duckduckgo.com is duckduckgo.com
+func site(host string) string {
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) > 2 {
+		labels = labels[len(labels)-2:]
+	}
+	return strings.Join(labels, ".")
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,146 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/opencode-ai/opencode/internal/swarm/provider"
+)
+
+// configChanges returns the fields, named as in configuration files, in
+// which next differs from the configuration of the agent. Defaults are
+// applied to next as they were to the agent's, secrets are not compared.
+func (a *BaseAgent) configChanges(next AgentConfig) []string {
+	a.configMu.RLock()
+	cur := a.config
+	cur.Capabilities = a.capabilities
+	a.configMu.RUnlock()
+
+	if next.MessageBufferSize <= 0 {
+		next.MessageBufferSize = 100
+	}
+	if next.MaxConcurrency <= 0 && cur.MaxConcurrency > 0 {
+		next.MaxConcurrency = 1
+	}
+
+	var changed []string
+	diff := func(field string, equal bool) {
+		if !equal {
+			changed = append(changed, field)
+		}
+	}
+	diff("type", next.Type == cur.Type)
+	diff("provider", next.ProviderType == cur.ProviderType)
+	diff("model", next.Model == cur.Model)
+	diff("maxConcurrency", next.MaxConcurrency == cur.MaxConcurrency)
+	diff("capabilities", slices.Equal(next.Capabilities, cur.Capabilities))
+	diff("permissions", slices.Equal(next.Permissions, cur.Permissions) && (next.Permissions == nil) == (cur.Permissions == nil))
+	diff("shadow", next.Shadow == cur.Shadow)
+	diff("options", len(next.CustomConfig) == 0 && len(cur.CustomConfig) == 0 || reflect.DeepEqual(next.CustomConfig, cur.CustomConfig))
+	diff("healthCheckInterval", next.HealthCheckInterval == cur.HealthCheckInterval)
+	diff("heartbeatInterval", next.HeartbeatInterval == cur.HeartbeatInterval)
+	diff("messageBufferSize", next.MessageBufferSize == cur.MessageBufferSize)
+	diff("enableLearning", next.EnableLearning == cur.EnableLearning)
+	diff("scratchDir", next.ScratchDir == cur.ScratchDir)
+	diff("scratchRetention", next.ScratchRetention == cur.ScratchRetention)
+	return changed
+}
+
+// Reconfigure applies the capabilities, the number of tasks run at once and
+// the permissions of config while the agent runs. Tasks running already
+// keep their slots. It returns the other fields that changed, such as the
+// model or options, which need the agent recycled and are left as they
+// were.
+func (a *BaseAgent) Reconfigure(config AgentConfig) ([]string, error) {
+	if config.ID != a.id {
+		return nil, fmt.Errorf("%w: %s reconfigured as %s", ErrWrongAgent, a.id, config.ID)
+	}
+
+	changed := a.configChanges(config)
+	var recycle []string
+	a.configMu.Lock()
+	defer a.configMu.Unlock()
+	for _, field := range changed {
+		switch field {
+		case "capabilities":
+			a.capabilities = config.Capabilities
+			a.config.Capabilities = config.Capabilities
+		case "maxConcurrency":
+			a.config.MaxConcurrency = max(config.MaxConcurrency, 1)
+		case "permissions":
+			a.config.Permissions = config.Permissions
+		default:
+			recycle = append(recycle, field)
+		}
+	}
+	return recycle, nil
+}
+
+// liveClient is the model client of a ModelAgent, which Reconfigure
+// replaces when the model changes
+type liveClient struct {
+	mu     sync.RWMutex
+	config provider.Config
+	client provider.Client
+}
+
+func newLiveClient(config provider.Config) (*liveClient, error) {
+	client, err := provider.New(config)
+	if err != nil {
+		return nil, err
+	}
+	return &liveClient{config: config, client: client}, nil
+}
+
+func (c *liveClient) current() provider.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.client
+}
+
+// Complete implements provider.Client
+func (c *liveClient) Complete(ctx context.Context, req provider.Request) (provider.Response, error) {
+	return c.current().Complete(ctx, req)
+}
+
+// Model implements provider.Client
+func (c *liveClient) Model() string {
+	return c.current().Model()
+}
+
+// setModel switches to a model of the same provider, for the requests
+// from now on
+func (c *liveClient) setModel(model string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	config := c.config
+	config.Model = model
+	client, err := provider.New(config)
+	if err != nil {
+		return err
+	}
+	c.config, c.client = config, client
+	return nil
+}
+
+// Reconfigure also switches the model while the agent runs, when its
+// provider and options stay the same, see BaseAgent.Reconfigure
+func (a *ModelAgent) Reconfigure(config AgentConfig) ([]string, error) {
+	if config.ID != a.id {
+		return nil, fmt.Errorf("%w: %s reconfigured as %s", ErrWrongAgent, a.id, config.ID)
+	}
+	changed := a.configChanges(config)
+	live, ok := a.client.(*liveClient)
+	if ok && slices.Contains(changed, "model") && !slices.Contains(changed, "provider") && !slices.Contains(changed, "options") {
+		if err := live.setModel(config.Model); err != nil {
+			return nil, fmt.Errorf("agent %s: %w", a.id, err)
+		}
+		a.configMu.Lock()
+		a.config.Model = config.Model
+		a.configMu.Unlock()
+	}
+	return a.BaseAgent.Reconfigure(config)
+}
@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestReconfigure(t *testing.T) {
+	config := AgentConfig{
+		ID:           "model",
+		Type:         AgentTypeExecutor,
+		ProviderType: "ollama",
+		Model:        "llama3",
+		Capabilities: []string{"testing"},
+	}
+	a, err := NewModelAgent(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unchanged, with the defaults left out
+	if recycle, err := a.Reconfigure(config); err != nil || len(recycle) != 0 {
+		t.Fatalf("Reconfigure unchanged = %v, %v", recycle, err)
+	}
+
+	next := config
+	next.Model = "qwen3"
+	next.MaxConcurrency = 3
+	next.Capabilities = []string{"testing", "documentation"}
+	recycle, err := a.Reconfigure(next)
+	if err != nil || len(recycle) != 0 {
+		t.Fatalf("Reconfigure = %v, %v, want everything applied", recycle, err)
+	}
+	if a.client.Model() != "qwen3" || a.maxConcurrency() != 3 || !a.CanHandleTask(Task{Type: "documentation"}) {
+		t.Errorf("agent runs %s, %d at once, with %v", a.client.Model(), a.maxConcurrency(), a.GetCapabilities())
+	}
+
+	// A new provider or options need a new agent
+	changed := next
+	changed.ProviderType = "lmstudio"
+	changed.Model = "mistral"
+	changed.CustomConfig = map[string]interface{}{"systemPrompt": "Be brief."}
+	recycle, err = a.Reconfigure(changed)
+	if err != nil || !slices.Equal(recycle, []string{"provider", "model", "options"}) {
+		t.Errorf("Reconfigure = %v, %v, want provider, model and options recycled", recycle, err)
+	}
+	if a.client.Model() != "qwen3" {
+		t.Errorf("model = %s, want it unchanged until recycled", a.client.Model())
+	}
+
+	other := next
+	other.ID = "other"
+	if _, err := a.Reconfigure(other); !errors.Is(err, ErrWrongAgent) {
+		t.Errorf("Reconfigure of another agent = %v, want ErrWrongAgent", err)
+	}
+}
+
+func TestReconfigureDocumentationAgent(t *testing.T) {
+	config := AgentConfig{ID: "docs", Type: AgentTypeDocumentation, ProviderType: "ollama", Model: "llama3"}
+	a, err := NewDocumentationAgent(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The prompt the agent gave itself is no change of options
+	config.Model = "qwen3"
+	if recycle, err := a.Reconfigure(config); err != nil || len(recycle) != 0 {
+		t.Errorf("Reconfigure = %v, %v, want the model switched", recycle, err)
+	}
+}
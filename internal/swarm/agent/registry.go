@@ -3,14 +3,25 @@ package agent
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 )
 
 // Registry manages all agents in the swarm
 type Registry struct {
 	agents      map[string]Agent
 	agentsByType map[AgentType][]Agent
+	// shadows are the agents that only get copies of tasks
+	shadows     map[string]bool
 	mu          sync.RWMutex
+	// jitter is the most added to selection scores at random
+	jitter      float64
+	
+	// Liveness, see CheckHeartbeats
+	heartbeats       map[string]time.Time
+	unreachable      map[string]bool
+	heartbeatTimeout time.Duration
 	
 	// Message routing
 	messageBroker *MessageBroker
@@ -21,6 +32,9 @@ func NewRegistry() *Registry {
 	return &Registry{
 		agents:        make(map[string]Agent),
 		agentsByType:  make(map[AgentType][]Agent),
+		shadows:       make(map[string]bool),
+		heartbeats:    make(map[string]time.Time),
+		unreachable:   make(map[string]bool),
 		messageBroker: NewMessageBroker(),
 	}
 }
@@ -32,7 +46,7 @@ func (r *Registry) RegisterAgent(agent Agent) error {
 	
 	id := agent.GetID()
 	if _, exists := r.agents[id]; exists {
-		return fmt.Errorf("agent with ID %s already registered", id)
+		return fmt.Errorf("%w: %s", ErrAgentExists, id)
 	}
 	
 	r.agents[id] = agent
@@ -43,9 +57,48 @@ func (r *Registry) RegisterAgent(agent Agent) error {
 	// Subscribe agent to message broker
 	r.messageBroker.Subscribe(id, agent.ReceiveMessages())
 	
+	if sender, ok := agent.(HeartbeatSender); ok {
+		sender.SetHeartbeatSink(r.Heartbeat)
+	}
+	
+	return nil
+}
+
+// RegisterShadowAgent adds an agent in shadow mode: it is started and
+// stopped with the others but never ranked for tasks. The coordinator hands
+// it copies of the tasks it can handle and only records its results.
+func (r *Registry) RegisterShadowAgent(agent Agent) error {
+	if err := r.RegisterAgent(agent); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shadows[agent.GetID()] = true
 	return nil
 }
 
+// IsShadow reports whether an agent was registered in shadow mode
+func (r *Registry) IsShadow(id string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.shadows[id]
+}
+
+// ShadowAgentsForTask returns the idle shadow agents that can handle a task
+func (r *Registry) ShadowAgentsForTask(task Task) []Agent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var agents []Agent
+	for id := range r.shadows {
+		agent := r.agents[id]
+		if !r.unreachable[id] && agent.GetStatus() == AgentStatusIdle && agent.CanHandleTask(task) {
+			agents = append(agents, agent)
+		}
+	}
+	return agents
+}
+
 // UnregisterAgent removes an agent from the registry
 func (r *Registry) UnregisterAgent(id string) error {
 	r.mu.Lock()
@@ -53,7 +106,7 @@ func (r *Registry) UnregisterAgent(id string) error {
 	
 	agent, exists := r.agents[id]
 	if !exists {
-		return fmt.Errorf("agent with ID %s not found", id)
+		return fmt.Errorf("%w: %s", ErrAgentNotFound, id)
 	}
 	
 	// Remove from type map
@@ -67,6 +120,9 @@ func (r *Registry) UnregisterAgent(id string) error {
 	}
 	
 	delete(r.agents, id)
+	delete(r.shadows, id)
+	delete(r.heartbeats, id)
+	delete(r.unreachable, id)
 	r.messageBroker.Unsubscribe(id)
 	
 	return nil
@@ -79,7 +135,7 @@ func (r *Registry) GetAgent(id string) (Agent, error) {
 	
 	agent, exists := r.agents[id]
 	if !exists {
-		return nil, fmt.Errorf("agent with ID %s not found", id)
+		return nil, fmt.Errorf("%w: %s", ErrAgentNotFound, id)
 	}
 	
 	return agent, nil
@@ -110,30 +166,39 @@ func (r *Registry) GetAllAgents() []Agent {
 	return agents
 }
 
-// FindAgentsForTask finds suitable agents for a task
+// FindAgentsForTask finds the idle agents that can handle a task, best
+// suited first, see RankAgentsForTask
 func (r *Registry) FindAgentsForTask(task Task) []Agent {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-	
-	var suitable []Agent
-	for _, agent := range r.agents {
-		if agent.GetStatus() == AgentStatusIdle && agent.CanHandleTask(task) {
-			suitable = append(suitable, agent)
-		}
-	}
-	
-	return suitable
+	agents, _ := r.RankAgentsForTask(task)
+	return agents
+}
+
+// SetMessagePolicy makes the registry refuse the messages policy returns an
+// error for
+func (r *Registry) SetMessagePolicy(policy MessagePolicy) {
+	r.messageBroker.setPolicy(policy)
 }
 
 // BroadcastMessage sends a message to all agents
-func (r *Registry) BroadcastMessage(msg Message) error {
-	return r.messageBroker.Broadcast(msg)
+func (r *Registry) BroadcastMessage(ctx context.Context, msg Message) error {
+	return r.messageBroker.Broadcast(ctx, msg)
 }
 
 // SendMessage sends a message to a specific agent
-func (r *Registry) SendMessage(toID string, msg Message) error {
+func (r *Registry) SendMessage(ctx context.Context, toID string, msg Message) error {
 	msg.To = toID
-	return r.messageBroker.Send(msg)
+	return r.messageBroker.Send(ctx, msg)
+}
+
+// MessageFlows returns the messages agents sent so far per recipient, with
+// broadcasts under an empty recipient
+func (r *Registry) MessageFlows() []MessageFlow {
+	return r.messageBroker.Flows()
+}
+
+// Subscribers returns the IDs of the agents receiving messages, sorted
+func (r *Registry) Subscribers() []string {
+	return r.messageBroker.Subscribers()
 }
 
 // StartAll starts all registered agents
@@ -154,8 +219,10 @@ func (r *Registry) StartAll(ctx context.Context) error {
 	return nil
 }
 
-// StopAll stops all registered agents
-func (r *Registry) StopAll() error {
+// StopAll stops all registered agents in parallel. If ctx ends first it
+// returns the context's error and leaves the remaining agents stopping in
+// the background.
+func (r *Registry) StopAll(ctx context.Context) error {
 	r.mu.RLock()
 	agents := make([]Agent, 0, len(r.agents))
 	for _, agent := range r.agents {
@@ -163,10 +230,22 @@ func (r *Registry) StopAll() error {
 	}
 	r.mu.RUnlock()
 	
-	var lastErr error
+	errs := make(chan error, len(agents))
 	for _, agent := range agents {
-		if err := agent.Stop(); err != nil {
-			lastErr = err
+		go func(agent Agent) {
+			errs <- agent.Stop()
+		}(agent)
+	}
+	
+	var lastErr error
+	for range agents {
+		select {
+		case err := <-errs:
+			if err != nil {
+				lastErr = err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 	}
 	
@@ -181,11 +260,13 @@ func (r *Registry) GetHealthStatus() map[string]AgentHealth {
 	status := make(map[string]AgentHealth)
 	for id, agent := range r.agents {
 		status[id] = AgentHealth{
-			ID:          id,
-			Type:        agent.GetType(),
-			Status:      agent.GetStatus(),
-			HealthScore: agent.GetHealthScore(),
-			Metrics:     agent.GetMetrics(),
+			ID:            id,
+			Type:          agent.GetType(),
+			Status:        agent.GetStatus(),
+			HealthScore:   agent.GetHealthScore(),
+			Metrics:       agent.GetMetrics(),
+			LastHeartbeat: r.heartbeats[id],
+			Unreachable:   r.unreachable[id],
 		}
 	}
 	
@@ -199,11 +280,22 @@ type AgentHealth struct {
 	Status      AgentStatus
 	HealthScore float64
 	Metrics     AgentMetrics
+	// LastHeartbeat is when the agent last sent a heartbeat, zero if never
+	LastHeartbeat time.Time
+	// Unreachable is set once the agent missed its heartbeats
+	Unreachable bool
 }
 
+// MessagePolicy decides whether a message may be sent. Broadcasts have no
+// recipient.
+type MessagePolicy func(msg Message) error
+
 // MessageBroker handles message routing between agents
 type MessageBroker struct {
 	subscribers map[string]<-chan Message
+	policy      MessagePolicy
+	// flows count the messages let through per pair of agents
+	flows       *flowCounter
 	mu          sync.RWMutex
 }
 
@@ -211,6 +303,7 @@ type MessageBroker struct {
 func NewMessageBroker() *MessageBroker {
 	return &MessageBroker{
 		subscribers: make(map[string]<-chan Message),
+		flows:       newFlowCounter(),
 	}
 }
 
@@ -226,16 +319,51 @@ func (mb *MessageBroker) Unsubscribe(agentID string) {
 	mb.mu.Lock()
 	defer mb.mu.Unlock()
 	delete(mb.subscribers, agentID)
+	mb.flows.forget(agentID)
+}
+
+// Subscribers returns the IDs of the agents subscribed to messages, sorted
+func (mb *MessageBroker) Subscribers() []string {
+	mb.mu.RLock()
+	defer mb.mu.RUnlock()
+	
+	ids := make([]string, 0, len(mb.subscribers))
+	for id := range mb.subscribers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Flows returns the messages sent so far per pair of agents
+func (mb *MessageBroker) Flows() []MessageFlow {
+	return mb.flows.list()
+}
+
+func (mb *MessageBroker) setPolicy(policy MessagePolicy) {
+	mb.mu.Lock()
+	defer mb.mu.Unlock()
+	mb.policy = policy
 }
 
 // Send routes a message to a specific agent
-func (mb *MessageBroker) Send(msg Message) error {
+func (mb *MessageBroker) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
 	mb.mu.RLock()
 	defer mb.mu.RUnlock()
 	
 	if msg.To == "" {
 		return fmt.Errorf("message must have a recipient")
 	}
+	if mb.policy != nil {
+		if err := mb.policy(msg); err != nil {
+			return err
+		}
+	}
+	mb.flows.record(msg)
 	
 	// In a real implementation, this would route to the agent's input channel
 	// For now, this is a placeholder
@@ -243,10 +371,22 @@ func (mb *MessageBroker) Send(msg Message) error {
 }
 
 // Broadcast sends a message to all subscribed agents
-func (mb *MessageBroker) Broadcast(msg Message) error {
+func (mb *MessageBroker) Broadcast(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
 	mb.mu.RLock()
 	defer mb.mu.RUnlock()
 	
+	if mb.policy != nil {
+		if err := mb.policy(msg); err != nil {
+			return err
+		}
+	}
+	msg.To = ""
+	mb.flows.record(msg)
+	
 	// In a real implementation, this would send to all agents
 	// For now, this is a placeholder
 	return nil
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package agent
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+)
+
+// sandboxEnv lists the variables commands inherit besides the configured
+// ones
+var sandboxEnv = []string{"PATH", "LANG", "LC_ALL", "TZ"}
+
+// shellCommand runs command with sh in its own process group, so that the
+// processes it starts are killed with it
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	return cmd
+}
+
+// tempEnv are the variables pointing commands to their temporary directory
+func tempEnv(dir string) []string {
+	return []string{"HOME=" + dir, "TMPDIR=" + dir}
+}
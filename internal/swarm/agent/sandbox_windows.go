@@ -0,0 +1,22 @@
+//go:build windows
+
+package agent
+
+import (
+	"context"
+	"os/exec"
+)
+
+// sandboxEnv lists the variables commands inherit besides the configured
+// ones
+var sandboxEnv = []string{"PATH", "PATHEXT", "SystemRoot", "ComSpec", "WINDIR"}
+
+// shellCommand runs command with cmd.exe
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd", "/C", command)
+}
+
+// tempEnv are the variables pointing commands to their temporary directory
+func tempEnv(dir string) []string {
+	return []string{"USERPROFILE=" + dir, "TEMP=" + dir, "TMP=" + dir}
+}
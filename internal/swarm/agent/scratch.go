@@ -0,0 +1,209 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultScratchRetention is how long the files of a scratchpad are kept
+// after they were last written
+const DefaultScratchRetention = 24 * time.Hour
+
+// ErrInvalidScratchName is returned for scratchpad file names that are not
+// local to the scratchpad
+var ErrInvalidScratchName = errors.New("invalid scratchpad file name")
+
+// ScratchFile is a file of a scratchpad
+type ScratchFile struct {
+	// Name is relative to the scratchpad, with slashes
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Scratchpad is the directory an agent keeps files in, such as the
+// artifacts its task results refer to. It outlives the agent: files are
+// only collected once they are older than the retention.
+type Scratchpad struct {
+	dir       string
+	retention time.Duration
+}
+
+// DefaultScratchRoot is where scratchpads are kept unless configured
+// otherwise, one directory per agent
+func DefaultScratchRoot() string {
+	return filepath.Join(os.TempDir(), "opencode-swarm", "scratch")
+}
+
+// ScratchDir returns the scratchpad directory of an agent under root
+func ScratchDir(root, agentID string) string {
+	return filepath.Join(root, scratchName(agentID))
+}
+
+// TaskDir returns the name of the directory of a scratchpad that holds the
+// files of a task
+func TaskDir(task Task) string {
+	if task.ID == "" {
+		return scratchName(uuid.New().String())
+	}
+	return scratchName(task.ID)
+}
+
+// scratchName turns an ID into a single path element
+func scratchName(id string) string {
+	name := strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, id)
+	if name == "" || name == "." || name == ".." {
+		name = "_" + name
+	}
+	return name
+}
+
+// NewScratchpad returns the scratchpad in dir. Zero retention means
+// DefaultScratchRetention.
+func NewScratchpad(dir string, retention time.Duration) *Scratchpad {
+	if retention <= 0 {
+		retention = DefaultScratchRetention
+	}
+	return &Scratchpad{dir: dir, retention: retention}
+}
+
+// Dir returns the directory of the scratchpad
+func (s *Scratchpad) Dir() string {
+	return s.dir
+}
+
+// Path returns the path of a file of the scratchpad. Names are relative and
+// cannot leave the scratchpad.
+func (s *Scratchpad) Path(name string) (string, error) {
+	name = filepath.FromSlash(name)
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidScratchName, name)
+	}
+	return filepath.Join(s.dir, name), nil
+}
+
+// WriteFile writes a file of the scratchpad, creating its directory, and
+// returns its path
+func (s *Scratchpad) WriteFile(name string, data []byte) (string, error) {
+	path, err := s.Path(name)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Create creates or truncates a file of the scratchpad, creating its
+// directory
+func (s *Scratchpad) Create(name string) (*os.File, error) {
+	path, err := s.Path(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+}
+
+// ReadFile reads a file of the scratchpad
+func (s *Scratchpad) ReadFile(name string) ([]byte, error) {
+	path, err := s.Path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// Remove removes a file or directory of the scratchpad
+func (s *Scratchpad) Remove(name string) error {
+	path, err := s.Path(name)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+// Files lists the files of the scratchpad by name
+func (s *Scratchpad) Files() ([]ScratchFile, error) {
+	var files []ScratchFile
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(s.dir, path)
+		files = append(files, ScratchFile{Name: filepath.ToSlash(rel), Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, err
+}
+
+// create makes the directory of the scratchpad
+func (s *Scratchpad) create() error {
+	return os.MkdirAll(s.dir, 0o700)
+}
+
+// collect removes the files last written before the retention, and the
+// directories left empty, and returns how many files were removed
+func (s *Scratchpad) collect(now time.Time) (int, error) {
+	files, err := s.Files()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := now.Add(-s.retention)
+	removed := 0
+	var errs []error
+	for _, file := range files {
+		if !file.ModTime.Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(file.Name))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		removed++
+	}
+
+	// Remove the directories left empty, deepest first
+	var dirs []string
+	_ = filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() && path != s.dir {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	for i := len(dirs) - 1; i >= 0; i-- {
+		// Fails for directories that are not empty
+		_ = os.Remove(dirs[i])
+	}
+	return removed, errors.Join(errs...)
+}
@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScratchpad(t *testing.T) {
+	root := t.TempDir()
+	dir := ScratchDir(root, "exec/1")
+	if dir != filepath.Join(root, "exec_1") {
+		t.Errorf("dir = %s", dir)
+	}
+	pad := NewScratchpad(dir, time.Hour)
+
+	for _, name := range []string{"../escape", "/etc/passwd", ""} {
+		if _, err := pad.WriteFile(name, nil); !errors.Is(err, ErrInvalidScratchName) {
+			t.Errorf("writing %q: %v", name, err)
+		}
+	}
+	path, err := pad.WriteFile("t1/report.xml", []byte("<testsuites/>"))
+	if err != nil || path != filepath.Join(dir, "t1", "report.xml") {
+		t.Fatalf("path = %s, %v", path, err)
+	}
+	if data, err := pad.ReadFile("t1/report.xml"); err != nil || string(data) != "<testsuites/>" {
+		t.Errorf("read %q, %v", data, err)
+	}
+	if _, err := pad.WriteFile("t2/out.log", []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "t2", "out.log"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := pad.Files()
+	if err != nil || len(files) != 2 || files[0].Name != "t1/report.xml" || files[0].Size != 13 || files[1].Name != "t2/out.log" {
+		t.Fatalf("files = %+v, %v", files, err)
+	}
+	// Files past the retention go with the directories they leave empty
+	if removed, err := pad.collect(time.Now()); err != nil || removed != 1 {
+		t.Errorf("removed %d, %v", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "t2")); !os.IsNotExist(err) {
+		t.Errorf("t2 was kept: %v", err)
+	}
+	if err := pad.Remove("t1"); err != nil {
+		t.Fatal(err)
+	}
+	if files, err := pad.Files(); err != nil || len(files) != 0 {
+		t.Errorf("files = %+v, %v", files, err)
+	}
+}
+
+func TestAgentScratchpad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scratch")
+	base := NewBaseAgent(AgentConfig{ID: "a", ScratchDir: dir, ScratchRetention: time.Minute})
+	if err := base.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Fatalf("scratchpad not created: %v", err)
+	}
+	pad := base.Scratchpad()
+	for _, name := range []string{"new.txt", "old.txt"} {
+		if _, err := pad.WriteFile(name, []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "old.txt"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := base.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if files, err := pad.Files(); err != nil || len(files) != 1 || files[0].Name != "new.txt" {
+		t.Errorf("files after stop = %+v, %v", files, err)
+	}
+}
@@ -0,0 +1,661 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TaskTypeSecurityAudit checks the dependencies of the project for known
+// vulnerabilities and its code for dangerous patterns
+const TaskTypeSecurityAudit = "security_audit"
+
+// Kinds of security findings
+const (
+	SecurityFindingVulnerability = "vulnerability"
+	SecurityFindingPattern       = "pattern"
+)
+
+const (
+	// DefaultVulnerabilityDatabase is the OSV API the security agent queries
+	DefaultVulnerabilityDatabase = "https://api.osv.dev"
+	// maxAuditedFiles and maxAuditedFileSize bound the files scanned for
+	// patterns
+	maxAuditedFiles    = 5000
+	maxAuditedFileSize = 1 << 20
+	// maxAuditedLineLength skips minified code
+	maxAuditedLineLength = 4096
+	// maxSecurityFindings bounds the findings of an audit, the most severe
+	// are kept
+	maxSecurityFindings = 500
+	// maxVulnerabilityDetails bounds the advisories fetched by an audit, the
+	// others are reported without a summary or severity
+	maxVulnerabilityDetails = 100
+	// osvBatchSize is the most queries the OSV API takes in a batch
+	osvBatchSize = 1000
+	// unknownSeverity scores vulnerabilities that are not rated
+	unknownSeverity = 5.0
+)
+
+// SecurityFinding is a vulnerable dependency or a dangerous line of code
+type SecurityFinding struct {
+	Kind string `json:"kind"`
+	// ID is the advisory of vulnerabilities, e.g. GHSA-xxxx-xxxx-xxxx, and
+	// the rule of patterns
+	ID string `json:"id"`
+	// Path is the lockfile of vulnerabilities and the file of patterns,
+	// relative to the project
+	Path    string `json:"path"`
+	Line    int    `json:"line,omitempty"`
+	Package string `json:"package,omitempty"`
+	Version string `json:"version,omitempty"`
+	Summary string `json:"summary"`
+	// Severity scores the finding from 0 to 10, like CVSS
+	Severity float64 `json:"severity"`
+}
+
+func (f SecurityFinding) String() string {
+	if f.Kind == SecurityFindingVulnerability {
+		return fmt.Sprintf("%s: %s@%s: %s (severity %.1f): %s", f.Path, f.Package, f.Version, f.ID, f.Severity, f.Summary)
+	}
+	return fmt.Sprintf("%s:%d: %s (severity %.1f): %s", f.Path, f.Line, f.ID, f.Severity, f.Summary)
+}
+
+// SecurityReport is the result of a security audit
+type SecurityReport struct {
+	// Manifests are the lockfiles read, relative to the project
+	Manifests    []string          `json:"manifests,omitempty"`
+	Dependencies int               `json:"dependencies"`
+	Files        int               `json:"files"`
+	Findings     []SecurityFinding `json:"findings,omitempty"`
+	// DatabaseError is why the dependencies could not be checked, if the
+	// vulnerability database failed
+	DatabaseError string `json:"database_error,omitempty"`
+}
+
+// MaxSeverity is the severity of the worst finding, 0 without findings
+func (r *SecurityReport) MaxSeverity() float64 {
+	var worst float64
+	for _, finding := range r.Findings {
+		worst = max(worst, finding.Severity)
+	}
+	return worst
+}
+
+// Summary describes the report in a few lines
+func (r *SecurityReport) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Audited %d dependencies and %d files: %d findings", r.Dependencies, r.Files, len(r.Findings))
+	if len(r.Findings) > 0 {
+		fmt.Fprintf(&b, ", max severity %.1f", r.MaxSeverity())
+	}
+	if r.DatabaseError != "" {
+		fmt.Fprintf(&b, "\nDependencies not checked: %s", r.DatabaseError)
+	}
+	for _, finding := range r.Findings[:min(len(r.Findings), 10)] {
+		b.WriteString("\n- ")
+		b.WriteString(finding.String())
+	}
+	if len(r.Findings) > 10 {
+		fmt.Fprintf(&b, "\n... and %d more", len(r.Findings)-10)
+	}
+	return b.String()
+}
+
+// securityRule is a dangerous pattern of code
+type securityRule struct {
+	id      string
+	pattern *regexp.Regexp
+	// languages limits the rule to source files of these languages, all
+	// audited files if empty
+	languages []string
+	severity  float64
+	summary   string
+}
+
+var scriptLanguages = []string{"javascript", "typescript", "tsx", "python"}
+
+var securityRules = []securityRule{
+	{
+		id:       "hardcoded-secret",
+		pattern:  regexp.MustCompile(`(?i)\b(?:password|passwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token|client[_-]?secret)\w*["']?\s*(?::=|=|:)\s*["'][^"'\s${}<>]{8,}["']`),
+		severity: 7.5,
+		summary:  "a secret is hardcoded",
+	},
+	{
+		id:       "aws-access-key",
+		pattern:  regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+		severity: 9,
+		summary:  "an AWS access key is hardcoded",
+	},
+	{
+		id:       "private-key",
+		pattern:  regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |ENCRYPTED )?PRIVATE KEY-----`),
+		severity: 9,
+		summary:  "a private key is committed",
+	},
+	{
+		id:       "tls-no-verify",
+		pattern:  regexp.MustCompile(`InsecureSkipVerify:\s*true|\bverify\s*=\s*False\b|rejectUnauthorized:\s*false`),
+		severity: 6.5,
+		summary:  "TLS certificates are not verified",
+	},
+	{
+		id:       "shell-command",
+		pattern:  regexp.MustCompile(`exec\.Command(?:Context)?\(.*"(?:ba)?sh",\s*"-c"|\bshell\s*=\s*True\b|\bchild_process\.exec(?:Sync)?\(|\bos\.system\(`),
+		severity: 6,
+		summary:  "a command runs through a shell",
+	},
+	{
+		id:        "eval",
+		pattern:   regexp.MustCompile(`(?:^|[^\w.])eval\(|\bnew Function\(`),
+		languages: scriptLanguages,
+		severity:  6,
+		summary:   "code is evaluated at runtime",
+	},
+	{
+		id:       "unsafe-deserialization",
+		pattern:  regexp.MustCompile(`\bpickle\.loads?\(|\bmarshal\.loads?\(|\byaml\.load\(`),
+		severity: 7,
+		summary:  "untrusted data may be deserialized into objects",
+	},
+	{
+		id:       "sql-formatting",
+		pattern:  regexp.MustCompile(`(?i)Sprintf\(\s*"(?:SELECT|INSERT|UPDATE|DELETE)\b[^"]*%[sv]|\bexecute\(\s*f["'](?:SELECT|INSERT|UPDATE|DELETE)\b`),
+		severity: 7,
+		summary:  "an SQL query is built by formatting, not with parameters",
+	},
+	{
+		id:       "weak-hash",
+		pattern:  regexp.MustCompile(`\b(?:md5|sha1)\.(?:New|Sum)\(|\bhashlib\.(?:md5|sha1)\(|createHash\(\s*["'](?:md5|sha1)["']`),
+		severity: 3,
+		summary:  "a weak hash function is used",
+	},
+}
+
+// auditedExtensions are the files scanned for patterns besides source code
+var auditedExtensions = map[string]bool{
+	".env":        true,
+	".ini":        true,
+	".json":       true,
+	".key":        true,
+	".pem":        true,
+	".properties": true,
+	".sh":         true,
+	".toml":       true,
+	".yaml":       true,
+	".yml":        true,
+}
+
+// skippedDirs are not audited, their code is not the project's
+var skippedDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// SecurityAgent audits the project without a model. For a security_audit
+// task it checks the modules of go.sum and the packages of package-lock.json
+// files against an OSV vulnerability database and scans the code for
+// dangerous patterns, such as hardcoded secrets. Findings are scored from 0
+// to 10.
+//
+// CustomConfig may set "dir", the project, the working directory by
+// default, and "database", the OSV API, DefaultVulnerabilityDatabase by
+// default, or "off" to only scan the code.
+type SecurityAgent struct {
+	*ModelAgent
+	dir      string
+	database string
+	http     *http.Client
+}
+
+func init() {
+	RegisterFactory(AgentTypeSecurity, func(config AgentConfig) (Agent, error) {
+		return NewSecurityAgent(config)
+	})
+}
+
+// NewSecurityAgent creates a security agent
+func NewSecurityAgent(config AgentConfig) (*SecurityAgent, error) {
+	dir := customString(config, "dir")
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("agent %s: %w", config.ID, err)
+	}
+	database := customString(config, "database")
+	switch database {
+	case "":
+		database = DefaultVulnerabilityDatabase
+	case "off":
+	default:
+		if u, err := url.Parse(database); err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("agent %s: invalid vulnerability database %q", config.ID, database)
+		}
+	}
+
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = 1
+	}
+	base := NewBaseAgent(config)
+	return &SecurityAgent{
+		ModelAgent: &ModelAgent{BaseAgent: base, slots: newTaskSlots(base)},
+		dir:        dir,
+		database:   strings.TrimRight(database, "/"),
+		http:       &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// CanHandleTask accepts security_audit tasks
+func (a *SecurityAgent) CanHandleTask(task Task) bool {
+	return task.Type == TaskTypeSecurityAudit
+}
+
+// ExecuteTask audits the project. The result has the *SecurityReport as its
+// "security" output and a summary of it as its "response".
+func (a *SecurityAgent) ExecuteTask(ctx context.Context, task Task) (*TaskResult, error) {
+	if task.Type != TaskTypeSecurityAudit {
+		return nil, fmt.Errorf("agent %s: cannot handle %s tasks", a.id, task.Type)
+	}
+	if !a.slots.acquire() {
+		return nil, fmt.Errorf("%w: %s is running %d tasks", ErrAgentBusy, a.id, a.maxConcurrency())
+	}
+	defer a.slots.release()
+
+	start := time.Now()
+	result := &TaskResult{
+		TaskID:  task.ID,
+		AgentID: a.id,
+	}
+	report, err := a.audit(ctx)
+	result.ExecutionTime = time.Since(start)
+	result.CompletedAt = time.Now()
+	a.RecordTask(result.ExecutionTime, err == nil)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	result.Success = true
+	result.Output = map[string]interface{}{
+		"response": report.Summary(),
+		"security": report,
+	}
+	result.Metadata = map[string]interface{}{
+		"dependencies": report.Dependencies,
+		"files":        report.Files,
+		"findings":     len(report.Findings),
+		"max_severity": report.MaxSeverity(),
+	}
+	return result, nil
+}
+
+// dependency is a package version pinned by a lockfile
+type dependency struct {
+	Ecosystem string
+	Name      string
+	Version   string
+	// Path is the lockfile, relative to the project
+	Path string
+}
+
+func (a *SecurityAgent) audit(ctx context.Context) (*SecurityReport, error) {
+	report := &SecurityReport{}
+	var deps []dependency
+	err := filepath.WalkDir(a.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != a.dir && skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(a.dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		switch d.Name() {
+		case "go.sum":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			report.Manifests = append(report.Manifests, rel)
+			deps = append(deps, goSumDependencies(rel, data)...)
+			return nil
+		case "package-lock.json":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			locked, err := packageLockDependencies(rel, data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", rel, err)
+			}
+			report.Manifests = append(report.Manifests, rel)
+			deps = append(deps, locked...)
+			return nil
+		}
+
+		if report.Files == maxAuditedFiles || !audited(d.Name()) {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.Size() > maxAuditedFileSize {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || bytes.IndexByte(data, 0) >= 0 {
+			return nil
+		}
+		report.Files++
+		report.Findings = append(report.Findings, ScanSecurityPatterns(rel, data)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Dependencies = len(deps)
+	if a.database != "off" && len(deps) > 0 {
+		vulns, err := a.vulnerabilities(ctx, deps)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			report.DatabaseError = err.Error()
+		}
+		report.Findings = append(report.Findings, vulns...)
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		fi, fj := report.Findings[i], report.Findings[j]
+		if fi.Severity != fj.Severity {
+			return fi.Severity > fj.Severity
+		}
+		if fi.Path != fj.Path {
+			return fi.Path < fj.Path
+		}
+		return fi.Line < fj.Line
+	})
+	if len(report.Findings) > maxSecurityFindings {
+		report.Findings = report.Findings[:maxSecurityFindings]
+	}
+	return report, nil
+}
+
+// audited reports whether a file is scanned for patterns
+func audited(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return sourceLanguage(name) != "" || auditedExtensions[ext] || strings.HasPrefix(name, ".env")
+}
+
+// ScanSecurityPatterns returns the dangerous patterns in the content of a
+// file
+func ScanSecurityPatterns(path string, content []byte) []SecurityFinding {
+	language := sourceLanguage(path)
+	var rules []securityRule
+	for _, rule := range securityRules {
+		if len(rule.languages) == 0 || contains(rule.languages, language) {
+			rules = append(rules, rule)
+		}
+	}
+
+	var findings []SecurityFinding
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxAuditedFileSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if len(text) > maxAuditedLineLength {
+			continue
+		}
+		for _, rule := range rules {
+			if rule.pattern.MatchString(text) {
+				findings = append(findings, SecurityFinding{
+					Kind:     SecurityFindingPattern,
+					ID:       rule.id,
+					Path:     path,
+					Line:     line,
+					Summary:  rule.summary,
+					Severity: rule.severity,
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// goSumDependencies returns the modules of a go.sum file whose code is
+// checksummed, not only their go.mod
+func goSumDependencies(path string, data []byte) []dependency {
+	seen := make(map[string]bool)
+	var deps []dependency
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		key := fields[0] + "@" + fields[1]
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		// OSV versions Go modules without the v prefix
+		version := strings.TrimSuffix(strings.TrimPrefix(fields[1], "v"), "+incompatible")
+		deps = append(deps, dependency{Ecosystem: "Go", Name: fields[0], Version: version, Path: path})
+	}
+	return deps
+}
+
+// npmLockDependency is a package of a lockfile version 1
+type npmLockDependency struct {
+	Version      string                       `json:"version"`
+	Dependencies map[string]npmLockDependency `json:"dependencies"`
+}
+
+// packageLockDependencies returns the packages of a package-lock.json file,
+// of any lockfile version
+func packageLockDependencies(path string, data []byte) ([]dependency, error) {
+	var lock struct {
+		Packages map[string]struct {
+			Version string `json:"version"`
+			Link    bool   `json:"link"`
+		} `json:"packages"`
+		Dependencies map[string]npmLockDependency `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var deps []dependency
+	add := func(name, version string) {
+		if name == "" || version == "" || seen[name+"@"+version] {
+			return
+		}
+		seen[name+"@"+version] = true
+		deps = append(deps, dependency{Ecosystem: "npm", Name: name, Version: version, Path: path})
+	}
+	if lock.Packages != nil {
+		// Version 2 and 3 list every installed package by its path
+		for key, pkg := range lock.Packages {
+			i := strings.LastIndex(key, "node_modules/")
+			if i < 0 || pkg.Link {
+				continue
+			}
+			add(key[i+len("node_modules/"):], pkg.Version)
+		}
+	} else {
+		var walk func(map[string]npmLockDependency)
+		walk = func(packages map[string]npmLockDependency) {
+			for name, pkg := range packages {
+				add(name, pkg.Version)
+				walk(pkg.Dependencies)
+			}
+		}
+		walk(lock.Dependencies)
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Name != deps[j].Name {
+			return deps[i].Name < deps[j].Name
+		}
+		return deps[i].Version < deps[j].Version
+	})
+	return deps, nil
+}
+
+// osvQuery asks the OSV API for the vulnerabilities of a package version
+type osvQuery struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Version string `json:"version"`
+}
+
+// osvVulnerability is an advisory of the OSV API
+type osvVulnerability struct {
+	ID               string `json:"id"`
+	Summary          string `json:"summary"`
+	Details          string `json:"details"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// osvSeverities score the severities of GitHub advisories
+var osvSeverities = map[string]float64{
+	"CRITICAL": 9.5,
+	"HIGH":     8,
+	"MODERATE": 5.5,
+	"MEDIUM":   5.5,
+	"LOW":      2.5,
+}
+
+// vulnerabilities looks up the dependencies in the vulnerability database.
+// The findings of the batches that were checked are returned along with an
+// error.
+func (a *SecurityAgent) vulnerabilities(ctx context.Context, deps []dependency) ([]SecurityFinding, error) {
+	var findings []SecurityFinding
+	details := make(map[string]*osvVulnerability)
+	for start := 0; start < len(deps); start += osvBatchSize {
+		batch := deps[start:min(start+osvBatchSize, len(deps))]
+		request := struct {
+			Queries []osvQuery `json:"queries"`
+		}{Queries: make([]osvQuery, len(batch))}
+		for i, dep := range batch {
+			request.Queries[i].Package.Name = dep.Name
+			request.Queries[i].Package.Ecosystem = dep.Ecosystem
+			request.Queries[i].Version = dep.Version
+		}
+		var response struct {
+			Results []struct {
+				Vulns []struct {
+					ID string `json:"id"`
+				} `json:"vulns"`
+			} `json:"results"`
+		}
+		if err := a.osv(ctx, http.MethodPost, "/v1/querybatch", request, &response); err != nil {
+			return findings, err
+		}
+
+		for i, result := range response.Results {
+			if i == len(batch) {
+				break
+			}
+			dep := batch[i]
+			for _, vuln := range result.Vulns {
+				finding := SecurityFinding{
+					Kind:     SecurityFindingVulnerability,
+					ID:       vuln.ID,
+					Path:     dep.Path,
+					Package:  dep.Name,
+					Version:  dep.Version,
+					Summary:  "known vulnerability",
+					Severity: unknownSeverity,
+				}
+				detail, fetched := details[vuln.ID]
+				if !fetched && len(details) < maxVulnerabilityDetails {
+					detail = &osvVulnerability{}
+					if err := a.osv(ctx, http.MethodGet, "/v1/vulns/"+url.PathEscape(vuln.ID), nil, detail); err != nil {
+						return findings, err
+					}
+					details[vuln.ID] = detail
+				}
+				if detail != nil {
+					if detail.Summary != "" {
+						finding.Summary = detail.Summary
+					} else if first, _, _ := strings.Cut(strings.TrimSpace(detail.Details), "\n"); first != "" {
+						finding.Summary = first
+					}
+					if score, ok := osvSeverities[strings.ToUpper(detail.DatabaseSpecific.Severity)]; ok {
+						finding.Severity = score
+					}
+				}
+				findings = append(findings, finding)
+			}
+		}
+	}
+	return findings, nil
+}
+
+// osv calls the OSV API, sending body as JSON if it is not nil and decoding
+// the response into out
+func (a *SecurityAgent) osv(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.database+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vulnerability database: %s %s: %s", method, path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+)
+
+// SelectionScore explains how well an idle agent suits a task. Agents with
+// the highest Total are picked first, ties go to the lowest ID.
+type SelectionScore struct {
+	AgentID string `json:"agentId"`
+	// Match is 1 when the task type is the agent type, 0 when the agent
+	// takes the task as one of its capabilities
+	Match float64 `json:"match"`
+	// Health is the health score of the agent, 0 to 1
+	Health float64 `json:"health"`
+	// Success is the share of the agent's finished tasks that succeeded, 1
+	// before it finished any
+	Success float64 `json:"success"`
+	// Jitter is the random amount added to spread tasks over agents that
+	// would otherwise tie, see Registry.SetSelectionJitter
+	Jitter float64 `json:"jitter,omitempty"`
+	Total  float64 `json:"total"`
+	// Degraded is set for agents the coordinator passed over because their
+	// health check scored too low
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+// String writes the score with its parts, e.g. for logs
+func (s SelectionScore) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %.3f (match %.0f, health %.2f, success %.2f", s.AgentID, s.Total, s.Match, s.Health, s.Success)
+	if s.Jitter != 0 {
+		fmt.Fprintf(&b, ", jitter %.3f", s.Jitter)
+	}
+	if s.Degraded {
+		b.WriteString(", degraded")
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// scoreAgent scores an agent for a task, without jitter
+func scoreAgent(agent Agent, task Task) SelectionScore {
+	score := SelectionScore{
+		AgentID: agent.GetID(),
+		Health:  agent.GetHealthScore(),
+		Success: 1,
+	}
+	if task.Type == string(agent.GetType()) {
+		score.Match = 1
+	}
+	metrics := agent.GetMetrics()
+	if finished := metrics.TasksCompleted + metrics.TasksFailed; finished > 0 {
+		score.Success = float64(metrics.TasksCompleted) / float64(finished)
+	}
+	score.Total = score.Match + score.Health + score.Success
+	return score
+}
+
+// compareScores orders the best score first, then by agent ID
+func compareScores(a, b SelectionScore) int {
+	switch {
+	case a.Total > b.Total:
+		return -1
+	case a.Total < b.Total:
+		return 1
+	}
+	return strings.Compare(a.AgentID, b.AgentID)
+}
+
+// SetSelectionJitter adds a random amount of up to jitter to the score of
+// every candidate, so agents that score about the same share tasks instead
+// of the same one always being picked. Zero, the default, keeps selection
+// deterministic.
+func (r *Registry) SetSelectionJitter(jitter float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jitter = jitter
+}
+
+// RankAgentsForTask returns the idle agents that can handle a task, best
+// first, with the scores they were ranked by. Shadow agents and agents
+// that missed their heartbeats are left out.
+func (r *Registry) RankAgentsForTask(task Task) ([]Agent, []SelectionScore) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var scores []SelectionScore
+	for id, agent := range r.agents {
+		if r.shadows[id] || r.unreachable[id] || agent.GetStatus() != AgentStatusIdle || !agent.CanHandleTask(task) {
+			continue
+		}
+		score := scoreAgent(agent, task)
+		if r.jitter > 0 {
+			score.Jitter = rand.Float64() * r.jitter
+			score.Total += score.Jitter
+		}
+		scores = append(scores, score)
+	}
+	slices.SortStableFunc(scores, compareScores)
+
+	agents := make([]Agent, len(scores))
+	for i, score := range scores {
+		agents[i] = r.agents[score.AgentID]
+	}
+	return agents, scores
+}
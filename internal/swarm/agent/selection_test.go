@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// capableAgent takes the tasks of its type and of its capabilities
+type capableAgent struct {
+	*BaseAgent
+}
+
+func (a *capableAgent) CanHandleTask(task Task) bool {
+	if task.Type == string(a.agentType) {
+		return true
+	}
+	for _, capability := range a.capabilities {
+		if capability == task.Type {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *capableAgent) ExecuteTask(ctx context.Context, task Task) (*TaskResult, error) {
+	return &TaskResult{TaskID: task.ID, AgentID: a.id, Success: true}, nil
+}
+
+func startedAgents(t *testing.T, r *Registry, configs ...AgentConfig) map[string]*capableAgent {
+	t.Helper()
+	agents := make(map[string]*capableAgent, len(configs))
+	for _, cfg := range configs {
+		ag := &capableAgent{BaseAgent: NewBaseAgent(cfg)}
+		if err := ag.Start(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = ag.Stop() })
+		if err := r.RegisterAgent(ag); err != nil {
+			t.Fatal(err)
+		}
+		agents[cfg.ID] = ag
+	}
+	return agents
+}
+
+func TestRankAgentsForTask(t *testing.T) {
+	r := NewRegistry()
+	agents := startedAgents(t, r,
+		AgentConfig{ID: "c-tester", Type: AgentTypeTesting},
+		AgentConfig{ID: "b-executor", Type: AgentTypeExecutor, Capabilities: []string{"testing"}},
+		AgentConfig{ID: "a-executor", Type: AgentTypeExecutor, Capabilities: []string{"testing"}},
+	)
+	task := Task{ID: "t1", Type: "testing"}
+
+	// The agent of the task type first, then ties by ID
+	ranked, scores := r.RankAgentsForTask(task)
+	want := []string{"c-tester", "a-executor", "b-executor"}
+	for i, ag := range ranked {
+		if ag.GetID() != want[i] || scores[i].AgentID != want[i] {
+			t.Fatalf("ranked %d = %s, want %s", i, ag.GetID(), want[i])
+		}
+	}
+	if s := scores[0]; s.Match != 1 || s.Health != 1 || s.Success != 1 || s.Total != 3 {
+		t.Errorf("score = %+v, want 3 from match, health and success", s)
+	}
+
+	// Failures lower the score of an agent
+	agents["a-executor"].RecordTask(time.Second, true)
+	agents["a-executor"].RecordTask(time.Second, false)
+	_, scores = r.RankAgentsForTask(task)
+	if scores[2].AgentID != "a-executor" || scores[2].Success != 0.5 {
+		t.Errorf("last = %v, want a-executor with half its tasks failed", scores[2])
+	}
+
+	// Jitter lets agents of the same score take turns
+	r = NewRegistry()
+	startedAgents(t, r,
+		AgentConfig{ID: "a-executor", Type: AgentTypeExecutor},
+		AgentConfig{ID: "b-executor", Type: AgentTypeExecutor},
+	)
+	r.SetSelectionJitter(0.01)
+	first := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		_, scores := r.RankAgentsForTask(Task{Type: "executor"})
+		for _, s := range scores {
+			if s.Jitter < 0 || s.Jitter >= 0.01 {
+				t.Fatalf("jitter = %v, want it below 0.01", s.Jitter)
+			}
+		}
+		first[scores[0].AgentID] = true
+	}
+	if len(first) != 2 {
+		t.Errorf("picked first = %v, want both executors", first)
+	}
+}
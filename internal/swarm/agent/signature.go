@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// maxSignatureLength bounds the key of a signature
+const maxSignatureLength = 200
+
+// Signature identifies an error across its occurrences
+type Signature struct {
+	// Key is the error message without the parts that differ between
+	// occurrences: paths, IDs, addresses and numbers
+	Key string
+	// Type is the class of the error when it names one, e.g. "panic",
+	// "KeyError" or "java.lang.NullPointerException"
+	Type string
+	// Location is the function raising the error, from its stack trace
+	Location string
+}
+
+// signatureVariables replace, in order, the parts of messages that differ
+// between occurrences of the same error
+var signatureVariables = []struct {
+	pattern *regexp.Regexp
+	// placeholder may refer to the groups of pattern, e.g. ${1}
+	placeholder string
+}{
+	{regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s'"<>]+`), "URL"},
+	{regexp.MustCompile(`\b[\w.+-]+@[\w-]+(?:\.[\w-]+)+\b`), "EMAIL"},
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), "UUID"},
+	{regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}\b`), "IP"},
+	// Absolute and relative paths, not inside words like "input/output"
+	{regexp.MustCompile(`(^|[^\w.~/\\-])(?:[A-Za-z]:\\|~?/|\.\.?/)[^\s:'"(),\[\]]+`), "${1}PATH"},
+	// Paths to files with an extension
+	{regexp.MustCompile(`\b[\w.-]+(?:/[\w.-]+)*/[\w-]+\.[A-Za-z]\w*\b`), "PATH"},
+}
+
+var (
+	// hexID matches IDs such as hashes and object IDs, told from words by
+	// having digits and letters
+	hexID = regexp.MustCompile(`\b[0-9a-fA-F]{6,}\b`)
+	// signatureNumbers are replaced last
+	signatureNumbers = regexp.MustCompile(`0x[0-9a-fA-F]+|\d+`)
+)
+
+// errorType matches the class of an error at the start of its line
+var errorType = regexp.MustCompile(`^(?:Exception in thread "[^"]*" |Uncaught )?([A-Za-z_][\w.$]*(?:Error|Exception|Fault|Interrupt|Exit))\b`)
+
+// ExtractSignature identifies an error by the first line of its message, or
+// the last one for Python tracebacks, which end with the error, its class
+// and the function raising it
+func ExtractSignature(message string) Signature {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return Signature{}
+	}
+	line := lines[0]
+	if line == "Traceback (most recent call last):" {
+		line = lines[len(lines)-1]
+	}
+
+	signature := Signature{Key: NormalizeErrorMessage(line)}
+	switch {
+	case strings.HasPrefix(line, "panic:"):
+		signature.Type = "panic"
+	case strings.HasPrefix(line, "fatal error:"):
+		signature.Type = "fatal error"
+	default:
+		if m := errorType.FindStringSubmatch(line); m != nil {
+			signature.Type = m[1]
+		}
+	}
+	signature.Location = errorLocation(ParseStackTrace(message))
+	return signature
+}
+
+// ErrorSignature returns the key of the signature of an error, see
+// ExtractSignature
+func ErrorSignature(message string) string {
+	return ExtractSignature(message).Key
+}
+
+// NormalizeErrorMessage replaces the parts of an error message that differ
+// between occurrences with placeholders, e.g. "open /tmp/a1b2c3/x.db: no
+// such file" becomes "open PATH: no such file"
+func NormalizeErrorMessage(message string) string {
+	for _, v := range signatureVariables {
+		message = v.pattern.ReplaceAllString(message, v.placeholder)
+	}
+	message = hexID.ReplaceAllStringFunc(message, func(id string) string {
+		if strings.ContainsAny(id, "0123456789") && strings.ContainsAny(strings.ToLower(id), "abcdef") {
+			return "ID"
+		}
+		return id
+	})
+	message = signatureNumbers.ReplaceAllString(message, "N")
+	if len(message) > maxSignatureLength {
+		message = message[:maxSignatureLength]
+	}
+	return message
+}
+
+// errorLocation names the innermost frame outside the runtime by its
+// function, or its file when the function is unknown, as paths differ
+// between machines
+func errorLocation(frames []StackFrame) string {
+	for _, frame := range frames {
+		if frame.Function == "panic" || strings.HasPrefix(frame.Function, "runtime.") {
+			continue
+		}
+		if frame.Function != "" {
+			return frame.Function
+		}
+		return path.Base(frame.File)
+	}
+	return ""
+}
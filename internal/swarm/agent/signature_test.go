@@ -0,0 +1,58 @@
+package agent
+
+import "testing"
+
+func TestNormalizeErrorMessage(t *testing.T) {
+	for message, want := range map[string]string{
+		"open /tmp/build-123/cache.db: no such file or directory":   "open PATH: no such file or directory",
+		`File "./app/views.py" failed`:                              `File "PATH" failed`,
+		"C:\\Users\\ci\\app.log: access denied":                     "PATH: access denied",
+		"load internal/server/handler.go:42: syntax error":          "load PATH:N: syntax error",
+		"user 3fa85f64-5717-4562-b3fc-2c963f66afa6 not found":       "user UUID not found",
+		"dial tcp 10.0.0.12:5432: connection refused":               "dial tcp IP:N: connection refused",
+		"GET https://api.example.com/v1/users/42?id=7 returned 503": "GET URL returned N",
+		"commit 9f8e7d6c5b4a not found for bob@example.com":         "commit ID not found for EMAIL",
+		"input/output error while reading the facade":               "input/output error while reading the facade",
+		"timeout after 1500ms on request 0xc000010000":              "timeout after Nms on request N",
+		"deadline exceeded":                                         "deadline exceeded",
+	} {
+		if got := NormalizeErrorMessage(message); got != want {
+			t.Errorf("NormalizeErrorMessage(%q) = %q, want %q", message, got, want)
+		}
+	}
+}
+
+func TestExtractSignature(t *testing.T) {
+	for _, tt := range []struct {
+		message string
+		want    Signature
+	}{
+		{goPanic, Signature{
+			Key:      "panic: runtime error: index out of range [N] with length N",
+			Type:     "panic",
+			Location: "example.com/app/internal/server.(*Handler).lookup",
+		}},
+		{"Traceback (most recent call last):\n  File \"/srv/app/views.py\", line 12, in index\n    return users[uid]\nKeyError: 'u-1234'\n", Signature{
+			Key:      "KeyError: 'u-N'",
+			Type:     "KeyError",
+			Location: "index",
+		}},
+		{"Exception in thread \"main\" java.lang.NullPointerException: id 77\n\tat com.example.Main.run(Main.java:12)\n", Signature{
+			Key:      "Exception in thread \"main\" java.lang.NullPointerException: id N",
+			Type:     "java.lang.NullPointerException",
+			Location: "com.example.Main.run",
+		}},
+		{"request 812 failed: upstream timeout", Signature{Key: "request N failed: upstream timeout"}},
+	} {
+		if got := ExtractSignature(tt.message); got != tt.want {
+			t.Errorf("ExtractSignature(%q) = %+v, want %+v", tt.message, got, tt.want)
+		}
+	}
+
+	// Occurrences differing in paths, IDs and numbers share a signature
+	a := ExtractSignature("open /tmp/job-1/out.json: permission denied (job 5f2c9a1e)")
+	b := ExtractSignature("open /var/tmp/job-22/out.json: permission denied (job 0b7d3e9f)")
+	if a != b {
+		t.Errorf("signatures %+v and %+v differ", a, b)
+	}
+}
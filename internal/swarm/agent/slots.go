@@ -0,0 +1,40 @@
+package agent
+
+import "sync"
+
+// taskSlots limits the tasks an agent runs at once to its MaxConcurrency
+type taskSlots struct {
+	agent *BaseAgent
+
+	mu      sync.Mutex
+	running int
+}
+
+func newTaskSlots(agent *BaseAgent) *taskSlots {
+	return &taskSlots{agent: agent}
+}
+
+// acquire takes a task slot, marking the agent busy when none are left so
+// the coordinator stops dispatching to it. It reports false if all slots
+// are taken.
+func (s *taskSlots) acquire() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running >= s.agent.maxConcurrency() {
+		return false
+	}
+	s.running++
+	if s.running >= s.agent.maxConcurrency() {
+		s.agent.SetStatus(AgentStatusBusy)
+	}
+	return true
+}
+
+func (s *taskSlots) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running--
+	if s.running < s.agent.maxConcurrency() && s.agent.GetStatus() == AgentStatusBusy {
+		s.agent.SetStatus(AgentStatusIdle)
+	}
+}
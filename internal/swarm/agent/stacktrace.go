@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// StackFrame is a frame of a stack trace
+type StackFrame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+var (
+	// goFrameFile is the location line under a Go frame:
+	//	/src/server.go:42 +0x1d
+	goFrameFile = regexp.MustCompile(`^\s+(\S+\.go):(\d+)(?: \+0x[0-9a-f]+)?$`)
+	// goFrameCall is the function line of a Go frame: main.(*S).f(0x1, ...)
+	goFrameCall = regexp.MustCompile(`^(\S+)\(.*\)$`)
+	// pythonFrame: File "app/views.py", line 12, in index
+	pythonFrame = regexp.MustCompile(`^\s*File "([^"]+)", line (\d+), in (\S+)`)
+	// jsFrame: at handle (/src/app.js:12:5) or at /src/app.js:12:5
+	jsFrame = regexp.MustCompile(`^\s*at (?:(.+?) \()?([^()\s]+):(\d+):\d+\)?$`)
+	// javaFrame: at com.example.Main.run(Main.java:12)
+	javaFrame = regexp.MustCompile(`^\s*at ([\w$.<>]+)\(([\w$]+\.(?:java|kt|scala)):(\d+)\)`)
+)
+
+// ParseStackTrace returns the frames of the Go, Python, JavaScript and JVM
+// stack traces in text, innermost first
+func ParseStackTrace(text string) []StackFrame {
+	var frames []StackFrame
+	// Python prints the innermost frame last
+	python := false
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if m := goFrameFile.FindStringSubmatch(line); m != nil {
+			frame := StackFrame{File: m[1], Line: atoi(m[2])}
+			if i > 0 {
+				if call := goFrameCall.FindStringSubmatch(strings.TrimSpace(lines[i-1])); call != nil {
+					frame.Function = call[1]
+				}
+			}
+			frames = append(frames, frame)
+		} else if m := pythonFrame.FindStringSubmatch(line); m != nil {
+			frames = append(frames, StackFrame{Function: m[3], File: m[1], Line: atoi(m[2])})
+			python = true
+		} else if m := javaFrame.FindStringSubmatch(line); m != nil {
+			frames = append(frames, StackFrame{Function: m[1], File: m[2], Line: atoi(m[3])})
+		} else if m := jsFrame.FindStringSubmatch(line); m != nil && !strings.HasPrefix(m[2], "node:") {
+			frames = append(frames, StackFrame{Function: m[1], File: strings.TrimPrefix(m[2], "file://"), Line: atoi(m[3])})
+		}
+	}
+	if python {
+		slices.Reverse(frames)
+	}
+	return frames
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
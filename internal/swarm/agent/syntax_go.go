@@ -0,0 +1,96 @@
+//go:build !cgo
+
+package agent
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// parseSyntax parses Go files with go/parser in builds without cgo, which
+// tree-sitter needs. Other languages are not parsed. Function literals
+// count towards the function they are in.
+func parseSyntax(ctx context.Context, language string, src []byte) (*syntaxInfo, error) {
+	if language != "go" {
+		return nil, errUnsupportedLanguage
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments|parser.SkipObjectResolution)
+	if file == nil {
+		return nil, err
+	}
+
+	info := &syntaxInfo{}
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			info.Comments = append(info.Comments, comment.Text)
+		}
+	}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		name := fn.Name.Name
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			name = receiverType(fn.Recv.List[0].Type) + "." + name
+		}
+		start, end := fset.Position(fn.Pos()), fset.Position(fn.End())
+		info.Functions = append(info.Functions, FunctionMetrics{
+			Name:       name,
+			Line:       start.Line,
+			Lines:      end.Line - start.Line + 1,
+			Complexity: goComplexity(fn),
+			hash:       functionHash(string(src[start.Offset:end.Offset])),
+		})
+	}
+	return info, nil
+}
+
+// goComplexity counts the paths through a function like the tree-sitter
+// parser does
+func goComplexity(fn *ast.FuncDecl) int {
+	complexity := 1
+	ast.Inspect(fn, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if n.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
+
+// receiverType returns the type name of a receiver like *Set[T]
+func receiverType(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
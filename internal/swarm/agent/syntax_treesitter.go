@@ -0,0 +1,150 @@
+//go:build cgo
+
+package agent
+
+import (
+	"context"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
+	"github.com/smacker/go-tree-sitter/typescript/typescript"
+)
+
+// syntaxRules name the nodes of a tree-sitter grammar the analyzer looks at
+type syntaxRules struct {
+	language func() *sitter.Language
+	// functions are the nodes of functions and methods
+	functions map[string]bool
+	// branches each add a path through a function
+	branches map[string]bool
+	// classes name the methods they contain
+	classes map[string]bool
+}
+
+func nodeSet(types ...string) map[string]bool {
+	set := make(map[string]bool, len(types))
+	for _, t := range types {
+		set[t] = true
+	}
+	return set
+}
+
+var (
+	jsFunctions = nodeSet("function_declaration", "generator_function_declaration", "function_expression", "function", "generator_function", "arrow_function", "method_definition")
+	jsBranches  = nodeSet("if_statement", "for_statement", "for_in_statement", "while_statement", "do_statement", "switch_case", "catch_clause", "ternary_expression")
+	jsClasses   = nodeSet("class_declaration", "class", "abstract_class_declaration")
+)
+
+var syntaxRulesByLanguage = map[string]syntaxRules{
+	"go": {
+		language:  golang.GetLanguage,
+		functions: nodeSet("function_declaration", "method_declaration", "func_literal"),
+		branches:  nodeSet("if_statement", "for_statement", "expression_case", "type_case", "communication_case"),
+	},
+	"javascript": {language: javascript.GetLanguage, functions: jsFunctions, branches: jsBranches, classes: jsClasses},
+	"typescript": {language: typescript.GetLanguage, functions: jsFunctions, branches: jsBranches, classes: jsClasses},
+	"tsx":        {language: tsx.GetLanguage, functions: jsFunctions, branches: jsBranches, classes: jsClasses},
+	"python": {
+		language:  python.GetLanguage,
+		functions: nodeSet("function_definition"),
+		branches:  nodeSet("if_statement", "elif_clause", "for_statement", "while_statement", "except_clause", "conditional_expression", "boolean_operator", "case_clause", "if_clause"),
+		classes:   nodeSet("class_definition"),
+	},
+}
+
+// logicalOperators of binary expressions add a path, as they short-circuit
+var logicalOperators = nodeSet("&&", "||", "??")
+
+// parseSyntax parses a file with tree-sitter. Anonymous functions count
+// towards the function they are in.
+func parseSyntax(ctx context.Context, language string, src []byte) (*syntaxInfo, error) {
+	rules, ok := syntaxRulesByLanguage[language]
+	if !ok {
+		return nil, errUnsupportedLanguage
+	}
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(rules.language())
+	tree, err := parser.ParseCtx(ctx, nil, src)
+	if err != nil {
+		return nil, err
+	}
+	defer tree.Close()
+
+	info := &syntaxInfo{}
+	// walk visits a node inside the function at index fn, -1 if none
+	var walk func(node *sitter.Node, fn int)
+	walk = func(node *sitter.Node, fn int) {
+		nodeType := node.Type()
+		switch {
+		case nodeType == "comment" || nodeType == "html_comment":
+			info.Comments = append(info.Comments, node.Content(src))
+			return
+		case rules.functions[nodeType]:
+			if name := functionName(node, src, rules); name != "" {
+				start, end := int(node.StartPoint().Row)+1, int(node.EndPoint().Row)+1
+				info.Functions = append(info.Functions, FunctionMetrics{
+					Name:       name,
+					Line:       start,
+					Lines:      end - start + 1,
+					Complexity: 1,
+					hash:       functionHash(node.Content(src)),
+				})
+				fn = len(info.Functions) - 1
+			}
+		case fn >= 0 && rules.branches[nodeType]:
+			info.Functions[fn].Complexity++
+		case fn >= 0 && nodeType == "binary_expression":
+			if operator := node.ChildByFieldName("operator"); operator != nil && logicalOperators[operator.Type()] {
+				info.Functions[fn].Complexity++
+			}
+		}
+		for i := 0; i < int(node.ChildCount()); i++ {
+			walk(node.Child(i), fn)
+		}
+	}
+	walk(tree.RootNode(), -1)
+	return info, nil
+}
+
+// functionName returns the name of a function node, qualified with its
+// receiver type or class, or "" for anonymous functions
+func functionName(node *sitter.Node, src []byte, rules syntaxRules) string {
+	var name string
+	if n := node.ChildByFieldName("name"); n != nil {
+		name = n.Content(src)
+	} else if parent := node.Parent(); parent != nil && parent.Type() == "variable_declarator" {
+		// const handler = () => {}
+		if n := parent.ChildByFieldName("name"); n != nil {
+			name = n.Content(src)
+		}
+	}
+	if name == "" {
+		return ""
+	}
+
+	if receiver := node.ChildByFieldName("receiver"); receiver != nil {
+		// (s *Server) or (s *Set[T])
+		fields := strings.Fields(strings.Trim(receiver.Content(src), "()"))
+		if len(fields) > 0 {
+			recv, _, _ := strings.Cut(strings.TrimLeft(fields[len(fields)-1], "*"), "[")
+			return recv + "." + name
+		}
+	}
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		if rules.functions[parent.Type()] {
+			break
+		}
+		if rules.classes[parent.Type()] {
+			if class := parent.ChildByFieldName("name"); class != nil {
+				return class.Content(src) + "." + name
+			}
+			break
+		}
+	}
+	return name
+}
@@ -0,0 +1,94 @@
+//go:build cgo
+
+package agent
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseSyntaxTreeSitter(t *testing.T) {
+	tests := []struct {
+		language string
+		source   string
+		want     []FunctionMetrics
+		comments int
+	}{
+		{
+			language: "javascript",
+			source: `// TODO: cache results
+class Parser {
+  parse(input) {
+    if (!input || input.length === 0) {
+      return null;
+    }
+    return input.map((token) => token.kind === "word" ? token.value : null);
+  }
+}
+
+const handler = async (req) => {
+  try {
+    return await fetch(req);
+  } catch (err) {
+    return req.fallback ?? null;
+  }
+};
+`,
+			want: []FunctionMetrics{
+				{Name: "Parser.parse", Line: 3, Lines: 6, Complexity: 4},
+				{Name: "handler", Line: 11, Lines: 7, Complexity: 3},
+			},
+			comments: 1,
+		},
+		{
+			language: "typescript",
+			source: `export function total(items: Item[]): number {
+  let sum = 0;
+  for (const item of items) {
+    sum += item.price > 0 ? item.price : 0;
+  }
+  return sum;
+}
+`,
+			want: []FunctionMetrics{{Name: "total", Line: 1, Lines: 7, Complexity: 3}},
+		},
+		{
+			language: "python",
+			source: `# FIXME: slow
+class Store:
+    def get(self, key):
+        if key in self.items and self.items[key]:
+            return self.items[key]
+        elif key in self.defaults:
+            return self.defaults[key]
+        return [v for v in self.values if v]
+
+def main():
+    pass
+`,
+			want: []FunctionMetrics{
+				{Name: "Store.get", Line: 3, Lines: 6, Complexity: 5},
+				{Name: "main", Line: 10, Lines: 2, Complexity: 1},
+			},
+			comments: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			info, err := parseSyntax(context.Background(), tt.language, []byte(tt.source))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range info.Functions {
+				info.Functions[i].hash = 0
+			}
+			if !reflect.DeepEqual(info.Functions, tt.want) {
+				t.Errorf("functions = %+v, want %+v", info.Functions, tt.want)
+			}
+			if len(info.Comments) != tt.comments {
+				t.Errorf("comments = %q", info.Comments)
+			}
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/artifact"
+	"github.com/opencode-ai/opencode/internal/swarm/locks"
+)
+
+// Test report formats
+const (
+	TestFormatGoJSON = "go-json"
+	TestFormatJUnit  = "junit"
+)
+
+// reportPlaceholder in the arguments of a test command is replaced with the
+// path of a file of the scratchpad the command writes its JUnit report to
+const reportPlaceholder = "{report}"
+
+// maxTestOutput caps the output kept of runs that failed without failing
+// tests
+const maxTestOutput = 4000
+
+// ErrNoTestCommand is returned when no test command is configured and none
+// was found in the project
+var ErrNoTestCommand = errors.New("no test command found")
+
+// TestCommand is how a project runs its tests
+type TestCommand struct {
+	Args []string
+	// Format of the results, the exit code alone tells the outcome if empty
+	Format string
+	// Report is the JUnit report the command writes for the junit format,
+	// relative to the project. Commands may instead pass {report} in their
+	// arguments.
+	Report string
+}
+
+func (c TestCommand) String() string {
+	return strings.Join(c.Args, " ")
+}
+
+// DiscoverTestCommand finds the test command of the project in dir by its
+// build files: go.mod, package.json, pytest configuration or a Makefile
+func DiscoverTestCommand(dir string) (TestCommand, error) {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, name))
+		return err == nil
+	}
+
+	if exists("go.mod") {
+		return TestCommand{Args: []string{"go", "test", "-json", "./..."}, Format: TestFormatGoJSON}, nil
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+		var pkg struct {
+			Scripts map[string]string `json:"scripts"`
+		}
+		// npm init writes a test script that only fails
+		if json.Unmarshal(data, &pkg) == nil && pkg.Scripts["test"] != "" && !strings.Contains(pkg.Scripts["test"], "no test specified") {
+			// jest-junit and most reporters write junit.xml by default
+			return TestCommand{Args: []string{"npm", "test"}, Format: TestFormatJUnit, Report: "junit.xml"}, nil
+		}
+	}
+	for _, name := range []string{"pytest.ini", "conftest.py", "tox.ini", "pyproject.toml"} {
+		if !exists(name) {
+			continue
+		}
+		if name == "pyproject.toml" || name == "tox.ini" {
+			data, _ := os.ReadFile(filepath.Join(dir, name))
+			if !bytes.Contains(data, []byte("pytest")) {
+				continue
+			}
+		}
+		return TestCommand{Args: []string{"pytest", "--junitxml=" + reportPlaceholder}, Format: TestFormatJUnit}, nil
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "Makefile")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(line, "test:") {
+				return TestCommand{Args: []string{"make", "test"}}, nil
+			}
+		}
+	}
+	return TestCommand{}, fmt.Errorf("%w in %s", ErrNoTestCommand, dir)
+}
+
+// TestingAgent runs the test suite of a project and reports the results.
+// CustomConfig may set "dir", the project, "command", the test command,
+// "format", "go-json" or "junit", and "report", the JUnit report the
+// command writes. Without a command the agent discovers one. The project
+// directory is locked while the tests run.
+type TestingAgent struct {
+	*BaseAgent
+	dir     string
+	command *TestCommand
+	slots   *taskSlots
+}
+
+func init() {
+	RegisterFactory(AgentTypeTesting, func(config AgentConfig) (Agent, error) {
+		return NewTestingAgent(config)
+	})
+}
+
+// NewTestingAgent creates a testing agent for the project of its
+// configuration, the working directory by default
+func NewTestingAgent(config AgentConfig) (*TestingAgent, error) {
+	if config.MaxConcurrency <= 0 {
+		config.MaxConcurrency = 1
+	}
+	dir := customString(config, "dir")
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("agent %s: %w", config.ID, err)
+	}
+
+	var command *TestCommand
+	if args := strings.Fields(customString(config, "command")); len(args) > 0 {
+		command = &TestCommand{
+			Args:   args,
+			Format: customString(config, "format"),
+			Report: customString(config, "report"),
+		}
+	}
+	if command != nil && command.Format != "" && command.Format != TestFormatGoJSON && command.Format != TestFormatJUnit {
+		return nil, fmt.Errorf("agent %s: unknown test format %q", config.ID, command.Format)
+	}
+
+	base := NewBaseAgent(config)
+	return &TestingAgent{
+		BaseAgent: base,
+		dir:       dir,
+		command:   command,
+		slots:     newTaskSlots(base),
+	}, nil
+}
+
+// CanHandleTask accepts tasks whose type is the agent type or one of its
+// capabilities
+func (a *TestingAgent) CanHandleTask(task Task) bool {
+	if task.Type == string(a.agentType) {
+		return true
+	}
+	for _, capability := range a.GetCapabilities() {
+		if capability == task.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredPermissions lets only agents that may run commands run tests
+func (a *TestingAgent) RequiredPermissions(task Task) []Permission {
+	return []Permission{PermissionRunCommands}
+}
+
+// ExecuteTask runs the tests. The result succeeds when every test passed;
+// its "report" output is the *TestReport and "response" a summary of it.
+// The output of the command and its JUnit report are attached as artifacts.
+// Failing tests fail the result but not the task, errors are returned only
+// when the tests could not be run. In a dry run the test command is
+// reported and succeeds without running.
+func (a *TestingAgent) ExecuteTask(ctx context.Context, task Task) (*TaskResult, error) {
+	if !a.slots.acquire() {
+		return nil, fmt.Errorf("%w: %s is running %d tasks", ErrAgentBusy, a.id, a.maxConcurrency())
+	}
+	defer a.slots.release()
+
+	start := time.Now()
+	result := &TaskResult{
+		TaskID:  task.ID,
+		AgentID: a.id,
+	}
+	report, artifacts, err := a.runTests(ctx, task)
+	result.ExecutionTime = time.Since(start)
+	result.CompletedAt = time.Now()
+	a.RecordTask(result.ExecutionTime, err == nil)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	result.Success = report.OK()
+	result.Output = map[string]interface{}{
+		"response": report.Summary(),
+		"report":   report,
+	}
+	result.Artifacts = artifacts
+	result.Metadata = map[string]interface{}{
+		"command": report.Command,
+		"passed":  report.Passed,
+		"failed":  report.Failed,
+		"skipped": report.Skipped,
+	}
+	if report.DryRun {
+		result.Metadata["dry_run"] = true
+	}
+	if !result.Success {
+		if report.Failed > 0 {
+			result.Error = fmt.Errorf("%d of %d tests failed", report.Failed, report.Passed+report.Failed)
+		} else {
+			result.Error = fmt.Errorf("%s exited with code %d", report.Command, report.ExitCode)
+		}
+	}
+	return result, nil
+}
+
+// runTests runs the test command and parses its results. The output of the
+// command and the reports it writes to {report} are kept in the scratchpad.
+func (a *TestingAgent) runTests(ctx context.Context, task Task) (*TestReport, []Artifact, error) {
+	if err := CheckPermission(ctx, PermissionRunCommands); err != nil {
+		return nil, nil, err
+	}
+	command, err := a.testCommand()
+	if err != nil {
+		return nil, nil, err
+	}
+	if IsDryRun(ctx) {
+		reportDryRun(ctx, task, a.id, command.String(), a.dir)
+		return &TestReport{Command: command.String(), DryRun: true}, nil, nil
+	}
+	// Commands changing the project while the tests run would spoil them
+	release, err := locks.Acquire(ctx, locks.Dir(a.dir))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+
+	taskDir := TaskDir(task)
+	log, err := a.Scratchpad().Create(path.Join(taskDir, "output.log"))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer log.Close()
+	artifacts := []Artifact{{Name: "output.log", Kind: artifact.KindLog, Path: log.Name(), Scratch: true}}
+
+	reportPath := command.Report
+	if reportPath != "" && !filepath.IsAbs(reportPath) {
+		reportPath = filepath.Join(a.dir, reportPath)
+	}
+	args := append([]string(nil), command.Args...)
+	var scratchReport string
+	for i, arg := range args {
+		if !strings.Contains(arg, reportPlaceholder) {
+			continue
+		}
+		if scratchReport == "" {
+			if scratchReport, err = a.Scratchpad().Path(path.Join(taskDir, "junit.xml")); err != nil {
+				return nil, nil, err
+			}
+		}
+		args[i] = strings.ReplaceAll(arg, reportPlaceholder, scratchReport)
+	}
+	if scratchReport != "" {
+		reportPath = scratchReport
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = a.dir
+	cmd.Stdout = io.MultiWriter(&stdout, log)
+	cmd.Stderr = io.MultiWriter(&stderr, log)
+	start := time.Now()
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+	var exitErr *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exitErr) {
+		return nil, nil, fmt.Errorf("failed to run %s: %w", command, runErr)
+	}
+
+	report := &TestReport{}
+	switch command.Format {
+	case TestFormatGoJSON:
+		*report, err = ParseGoTestJSON(&stdout)
+	case TestFormatJUnit:
+		*report, err = parseJUnitFile(reportPath, start)
+		if _, statErr := os.Stat(reportPath); statErr == nil {
+			artifacts = append(artifacts, Artifact{Name: filepath.Base(reportPath), Kind: artifact.KindReport, Path: reportPath, Scratch: reportPath == scratchReport})
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	report.Command = command.String()
+	report.ExitCode = cmd.ProcessState.ExitCode()
+	if report.ExitCode != 0 && report.Failed == 0 && len(report.Failures) == 0 {
+		output := strings.TrimSpace(stderr.String() + "\n" + stdout.String())
+		report.Output = truncateStart(output, maxTestOutput)
+	}
+	return report, artifacts, nil
+}
+
+// testCommand returns the configured test command or discovers one
+func (a *TestingAgent) testCommand() (TestCommand, error) {
+	if a.command != nil {
+		return *a.command, nil
+	}
+	return DiscoverTestCommand(a.dir)
+}
+
+// parseJUnitFile parses a JUnit report written since start. Reports the
+// command did not write, e.g. because it failed early, are left out.
+func parseJUnitFile(path string, start time.Time) (TestReport, error) {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() == 0 || info.ModTime().Before(start.Add(-time.Second)) {
+		return TestReport{}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return TestReport{}, err
+	}
+	defer f.Close()
+	return ParseJUnitXML(f)
+}
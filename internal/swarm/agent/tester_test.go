@@ -0,0 +1,189 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const goTestOutput = `{"Action":"build-output","ImportPath":"example.com/m/bad [example.com/m/bad.test]","Output":"# example.com/m/bad [example.com/m/bad.test]\n"}
+{"Action":"build-output","ImportPath":"example.com/m/bad [example.com/m/bad.test]","Output":"bad/bad.go:3:23: cannot use \"x\" (untyped string constant) as int value in return statement\n"}
+{"Action":"build-fail","ImportPath":"example.com/m/bad [example.com/m/bad.test]"}
+{"Action":"start","Package":"example.com/m/bad"}
+{"Action":"output","Package":"example.com/m/bad","Output":"FAIL\texample.com/m/bad [build failed]\n"}
+{"Action":"fail","Package":"example.com/m/bad","FailedBuild":"example.com/m/bad [example.com/m/bad.test]"}
+{"Action":"start","Package":"example.com/m/ok"}
+{"Action":"run","Package":"example.com/m/ok","Test":"TestPass"}
+{"Action":"output","Package":"example.com/m/ok","Test":"TestPass","Output":"=== RUN   TestPass\n"}
+{"Action":"pass","Package":"example.com/m/ok","Test":"TestPass"}
+{"Action":"run","Package":"example.com/m/ok","Test":"TestFail"}
+{"Action":"run","Package":"example.com/m/ok","Test":"TestFail/a"}
+{"Action":"output","Package":"example.com/m/ok","Test":"TestFail/a","Output":"    ok_test.go:7: boom\n"}
+{"Action":"output","Package":"example.com/m/ok","Test":"TestFail/a","Output":"--- FAIL: TestFail/a (0.00s)\n"}
+{"Action":"fail","Package":"example.com/m/ok","Test":"TestFail/a"}
+{"Action":"pass","Package":"example.com/m/ok","Test":"TestFail/b"}
+{"Action":"output","Package":"example.com/m/ok","Test":"TestFail","Output":"--- FAIL: TestFail (0.00s)\n"}
+{"Action":"fail","Package":"example.com/m/ok","Test":"TestFail"}
+{"Action":"skip","Package":"example.com/m/ok","Test":"TestSkip"}
+{"Action":"output","Package":"example.com/m/ok","Output":"FAIL\n"}
+{"Action":"fail","Package":"example.com/m/ok"}
+`
+
+const junitReport = `<?xml version="1.0" encoding="utf-8"?>
+<testsuites>
+  <testsuite name="pytest" tests="4">
+    <testcase classname="tests.test_parser" name="test_empty" time="0.001"/>
+    <testcase classname="tests.test_parser" name="test_nested" time="0.002">
+      <failure message="assert 1 == 2">def test_nested():
+&gt;       assert 1 == 2
+E       assert 1 == 2</failure>
+    </testcase>
+    <testcase classname="tests.test_io" name="test_read">
+      <error message="FileNotFoundError: data.txt"/>
+    </testcase>
+    <testcase classname="tests.test_io" name="test_write">
+      <skipped message="slow"/>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+
+func TestParseGoTestJSON(t *testing.T) {
+	report, err := ParseGoTestJSON(strings.NewReader(goTestOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed != 1 || report.Failed != 1 || report.Skipped != 1 {
+		t.Errorf("passed %d, failed %d, skipped %d, want 1 each", report.Passed, report.Failed, report.Skipped)
+	}
+
+	var failures []string
+	for _, failure := range report.Failures {
+		failures = append(failures, failure.String())
+	}
+	want := []string{
+		`example.com/m/bad: bad/bad.go:3:23: cannot use "x" (untyped string constant) as int value in return statement`,
+		"example.com/m/ok.TestFail: ok_test.go:7: boom",
+	}
+	if !reflect.DeepEqual(failures, want) {
+		t.Errorf("failures = %q, want %q", failures, want)
+	}
+}
+
+func TestParseJUnitXML(t *testing.T) {
+	report, err := ParseJUnitXML(strings.NewReader(junitReport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Passed != 1 || report.Failed != 2 || report.Skipped != 1 {
+		t.Errorf("passed %d, failed %d, skipped %d, want 1, 2 and 1", report.Passed, report.Failed, report.Skipped)
+	}
+	want := []TestFailure{
+		{Package: "tests.test_parser", Name: "test_nested", Message: "def test_nested():\n>       assert 1 == 2\nE       assert 1 == 2"},
+		{Package: "tests.test_io", Name: "test_read", Message: "FileNotFoundError: data.txt"},
+	}
+	if !reflect.DeepEqual(report.Failures, want) {
+		t.Errorf("failures = %+v, want %+v", report.Failures, want)
+	}
+
+	if _, err := ParseJUnitXML(strings.NewReader("not xml")); err == nil {
+		t.Error("parsed an invalid report")
+	}
+}
+
+func TestDiscoverTestCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"go", map[string]string{"go.mod": "module m", "Makefile": "test:\n"}, "go test -json ./..."},
+		{"npm", map[string]string{"package.json": `{"scripts": {"test": "jest"}}`}, "npm test"},
+		{"npm default script", map[string]string{"package.json": `{"scripts": {"test": "echo \"Error: no test specified\" && exit 1"}}`, "pytest.ini": ""}, "pytest --junitxml={report}"},
+		{"pyproject", map[string]string{"pyproject.toml": "[tool.pytest.ini_options]\n"}, "pytest --junitxml={report}"},
+		{"make", map[string]string{"pyproject.toml": "[tool.black]\n", "Makefile": "build:\n\tgo build\ntest: build\n"}, "make test"},
+		{"nothing", map[string]string{"README.md": ""}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			command, err := DiscoverTestCommand(dir)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("found %q", command)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if command.String() != tt.want {
+				t.Errorf("command = %q, want %q", command, tt.want)
+			}
+		})
+	}
+}
+
+func TestTestingAgent(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/m\n\ngo 1.21\n",
+		"m_test.go": `package m
+
+import "testing"
+
+func TestPass(t *testing.T) {}
+
+func TestFail(t *testing.T) { t.Fatal("boom") }
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ag, err := New(AgentConfig{ID: "tester", Type: AgentTypeTesting, ScratchDir: t.TempDir(), CustomConfig: map[string]interface{}{"dir": dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ag.CanHandleTask(Task{Type: "testing"}) || ag.CanHandleTask(Task{Type: "analysis"}) {
+		t.Error("the agent should handle testing tasks only")
+	}
+
+	result, err := ag.ExecuteTask(context.Background(), Task{ID: "t1", Type: "testing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success || result.Error == nil || result.Error.Error() != "1 of 2 tests failed" {
+		t.Errorf("result = %+v", result)
+	}
+	report, ok := result.Output["report"].(*TestReport)
+	if !ok {
+		t.Fatalf("report = %#v", result.Output["report"])
+	}
+	if report.Command != "go test -json ./..." || report.ExitCode != 1 || report.Passed != 1 {
+		t.Errorf("report = %+v", report)
+	}
+	if len(report.Failures) != 1 || report.Failures[0].String() != "example.com/m.TestFail: m_test.go:7: boom" {
+		t.Errorf("failures = %+v", report.Failures)
+	}
+	if len(result.Artifacts) != 1 || result.Artifacts[0].Name != "output.log" {
+		t.Fatalf("artifacts = %+v", result.Artifacts)
+	}
+	if log, err := os.ReadFile(result.Artifacts[0].Path); err != nil || !strings.Contains(string(log), `"Test":"TestFail"`) {
+		t.Errorf("output log = %q, %v", log, err)
+	}
+}
@@ -0,0 +1,272 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxFailureMessage caps the output kept per failing test, the end is kept
+// as that is where assertions and panics show up
+const maxFailureMessage = 2000
+
+// TestReport is the outcome of a test run
+type TestReport struct {
+	// Command is the command line that ran the tests
+	Command  string        `json:"command"`
+	ExitCode int           `json:"exit_code"`
+	Passed   int           `json:"passed"`
+	Failed   int           `json:"failed"`
+	Skipped  int           `json:"skipped"`
+	Failures []TestFailure `json:"failures,omitempty"`
+	// Output is the end of the command output when it failed without
+	// failing tests, e.g. because the code did not compile
+	Output string `json:"output,omitempty"`
+	// DryRun is set when the tests were not run, see WithDryRun
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// TestFailure is a failed test, or a package that failed without failing
+// tests when Name is empty
+type TestFailure struct {
+	Package string `json:"package,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// OK reports whether the command succeeded and no test failed
+func (r *TestReport) OK() bool {
+	return r.ExitCode == 0 && r.Failed == 0 && len(r.Failures) == 0
+}
+
+// Summary describes the run in a few lines
+func (r *TestReport) Summary() string {
+	var b strings.Builder
+	if r.DryRun {
+		return r.Command + ": dry run, not executed"
+	}
+	fmt.Fprintf(&b, "%s: %d passed, %d failed, %d skipped", r.Command, r.Passed, r.Failed, r.Skipped)
+	if r.ExitCode != 0 {
+		fmt.Fprintf(&b, " (exit code %d)", r.ExitCode)
+	}
+	for _, failure := range r.Failures {
+		b.WriteString("\nFAIL ")
+		b.WriteString(failure.String())
+	}
+	if r.Output != "" {
+		b.WriteString("\n\n")
+		b.WriteString(r.Output)
+	}
+	return b.String()
+}
+
+func (f TestFailure) String() string {
+	name := f.Name
+	switch {
+	case name == "":
+		name = f.Package
+	case f.Package != "":
+		name = f.Package + "." + name
+	}
+	if f.Message == "" {
+		return name
+	}
+	return name + ": " + firstLine(f.Message)
+}
+
+// goTestEvent is a line of go test -json output
+type goTestEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Output  string
+	// ImportPath names the build of build-output events, which the fail
+	// event of the package refers to as FailedBuild
+	ImportPath  string
+	FailedBuild string
+}
+
+// ParseGoTestJSON reads the output of go test -json. Subtests count with
+// their top-level test, whose failure message includes their output.
+func ParseGoTestJSON(r io.Reader) (TestReport, error) {
+	var report TestReport
+	type testKey struct{ pkg, test string }
+	output := make(map[testKey]*strings.Builder)
+	failedTests := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			// go test prints build errors and other text between the events
+			continue
+		}
+		var event goTestEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return report, fmt.Errorf("invalid go test event: %w", err)
+		}
+
+		// Package level output is kept too, for packages that fail without
+		// failing tests
+		test, _, subtest := strings.Cut(event.Test, "/")
+		key := testKey{event.Package, test}
+		if event.Action == "build-output" {
+			key = testKey{pkg: event.ImportPath}
+		}
+		switch event.Action {
+		case "output", "build-output":
+			if strings.HasPrefix(event.Output, "=== ") {
+				continue
+			}
+			if output[key] == nil {
+				output[key] = &strings.Builder{}
+			}
+			output[key].WriteString(event.Output)
+			continue
+		}
+		if subtest {
+			continue
+		}
+
+		switch event.Action {
+		case "pass":
+			if test != "" {
+				report.Passed++
+			}
+			delete(output, key)
+		case "skip":
+			if test != "" {
+				report.Skipped++
+			}
+			delete(output, key)
+		case "fail":
+			if test != "" {
+				report.Failed++
+				failedTests[event.Package] = true
+				report.Failures = append(report.Failures, TestFailure{
+					Package: event.Package,
+					Name:    test,
+					Message: failureMessage(output[key]),
+				})
+			} else if event.FailedBuild != "" {
+				build := testKey{pkg: event.FailedBuild}
+				report.Failures = append(report.Failures, TestFailure{
+					Package: event.Package,
+					Message: failureMessage(output[build]),
+				})
+				delete(output, build)
+			} else if !failedTests[event.Package] {
+				report.Failures = append(report.Failures, TestFailure{
+					Package: event.Package,
+					Message: failureMessage(output[key]),
+				})
+			}
+			delete(output, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// junitNode is a <testsuites> or <testsuite> element
+type junitNode struct {
+	Name   string      `xml:"name,attr"`
+	Suites []junitNode `xml:"testsuite"`
+	Cases  []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure"`
+	Error     *junitProblem `xml:"error"`
+	Skipped   *struct{}     `xml:"skipped"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// ParseJUnitXML reads a JUnit XML report, as written by pytest, jest-junit,
+// gotestsum and most other test runners
+func ParseJUnitXML(r io.Reader) (TestReport, error) {
+	var root junitNode
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return TestReport{}, fmt.Errorf("invalid JUnit report: %w", err)
+	}
+	var report TestReport
+	var walk func(node junitNode)
+	walk = func(node junitNode) {
+		for _, c := range node.Cases {
+			problem := c.Failure
+			if problem == nil {
+				problem = c.Error
+			}
+			switch {
+			case problem != nil:
+				report.Failed++
+				pkg := c.ClassName
+				if pkg == "" {
+					pkg = node.Name
+				}
+				message := strings.TrimSpace(problem.Text)
+				if message == "" {
+					message = problem.Message
+				}
+				report.Failures = append(report.Failures, TestFailure{
+					Package: pkg,
+					Name:    c.Name,
+					Message: truncateStart(message, maxFailureMessage),
+				})
+			case c.Skipped != nil:
+				report.Skipped++
+			default:
+				report.Passed++
+			}
+		}
+		for _, suite := range node.Suites {
+			walk(suite)
+		}
+	}
+	walk(root)
+	return report, nil
+}
+
+func failureMessage(output *strings.Builder) string {
+	if output == nil {
+		return ""
+	}
+	return truncateStart(strings.TrimSpace(output.String()), maxFailureMessage)
+}
+
+// truncateStart keeps the last n bytes of s
+func truncateStart(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	s = s[len(s)-n:]
+	// Start at a line if there is one close by
+	if i := strings.IndexByte(s, '\n'); i >= 0 && i < 200 {
+		s = s[i+1:]
+	}
+	return "..." + s
+}
+
+// firstLine returns the first line of a failure message that is not a
+// header go test or go build printed
+func firstLine(s string) string {
+	for _, line := range strings.Split(strings.TrimPrefix(s, "..."), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "# ") && !strings.HasPrefix(line, "--- ") {
+			return line
+		}
+	}
+	return ""
+}
@@ -3,6 +3,9 @@ package agent
 import (
 	"context"
 	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+	"github.com/opencode-ai/opencode/internal/swarm/provider"
 )
 
 // AgentType defines the specialization of an agent
@@ -20,6 +23,9 @@ const (
 	AgentTypeTesting        AgentType = "testing"         // Runs and validates tests
 	AgentTypeErrorHandler   AgentType = "error_handler"   // Handles errors and recovery
 	AgentTypeHealthChecker  AgentType = "health_checker"  // Monitors agent and system health
+	AgentTypeSecurity       AgentType = "security"        // Audits dependencies and code
+	AgentTypeWeb            AgentType = "web"             // Looks up documentation on the web
+	AgentTypeGitHub         AgentType = "github"          // Opens issues and pull requests on GitHub
 )
 
 // AgentStatus represents the current state of an agent
@@ -56,6 +62,11 @@ type Agent interface {
 	// Health and metrics
 	GetHealthScore() float64
 	GetMetrics() AgentMetrics
+	
+	// Reconfigure applies the changes of config the agent takes while
+	// running and returns the fields of the others, which need the agent
+	// recycled
+	Reconfigure(config AgentConfig) ([]string, error)
 }
 
 // Task represents work to be done by an agent
@@ -69,6 +80,13 @@ type Task struct {
 	Deadline    *time.Time
 	RetryCount  int
 	MaxRetries  int
+	// RequiresVote makes the swarm vote on the task even if a single agent
+	// can handle it or voting is off
+	RequiresVote bool
+	// IdempotencyKey coalesces submissions: while a task with the key is
+	// queued or running, or shortly after it completed, submitting another
+	// one returns the existing task instead
+	IdempotencyKey string
 }
 
 // TaskResult contains the outcome of a task execution
@@ -81,6 +99,24 @@ type TaskResult struct {
 	AgentID     string
 	CompletedAt time.Time
 	Metadata    map[string]interface{}
+	// Artifacts are the files the task produced, kept in the artifact
+	// store of the swarm
+	Artifacts   []Artifact
+}
+
+// Artifact is a file a task produced, like a diff, test report or log.
+// Agents attach the file at Path or the content in Data; the coordinator
+// stores it and replaces both with the ID and size of the stored artifact.
+type Artifact struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Size int64  `json:"size,omitempty"`
+	Path string `json:"path,omitempty"`
+	Data []byte `json:"-"`
+	// Scratch marks a Path in the scratchpad of the agent, a copy the swarm
+	// removes once it stored it encrypted
+	Scratch bool `json:"-"`
 }
 
 // Message represents communication between agents
@@ -107,6 +143,7 @@ const (
 	MessageTypeBroadcast      MessageType = "broadcast"
 	MessageTypeLogEntry       MessageType = "log_entry"
 	MessageTypeMemoryUpdate   MessageType = "memory_update"
+	MessageTypeHeartbeat      MessageType = "heartbeat"
 )
 
 // AgentMetrics contains performance and operational metrics
@@ -131,10 +168,24 @@ type AgentConfig struct {
 	Model           string
 	MaxConcurrency  int
 	HealthCheckInterval time.Duration
+	// HeartbeatInterval is how often the agent tells the registry it is
+	// alive, zero to not send heartbeats
+	HeartbeatInterval time.Duration
+	// Clock times the heartbeats, the system clock if nil
+	Clock clock.Clock
 	MessageBufferSize   int
 	EnableLearning  bool
 	Capabilities    []string
+	// Permissions granted to the agent, the permissions of its role when nil
+	Permissions     []Permission
+	// Shadow registers the agent in shadow mode, see
+	// Registry.RegisterShadowAgent
+	Shadow          bool
 	CustomConfig    map[string]interface{}
+	// Secrets holds the API keys of providers not set in CustomConfig
+	Secrets         provider.Secrets
+	ScratchDir      string        // Defaults to a directory per agent under the temp dir
+	ScratchRetention time.Duration // Age of the scratch files collected on Stop
 }
 
 // SwarmConfig contains configuration for the entire swarm
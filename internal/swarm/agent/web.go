@@ -0,0 +1,642 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+	"github.com/opencode-ai/opencode/internal/swarm/provider"
+)
+
+// Task types of the web agent
+const (
+	// TaskTypeFetch reads the page of its "url" input
+	TaskTypeFetch = "fetch"
+	// TaskTypeDocsLookup finds documentation about its "query" input, in
+	// the pages of its "urls" input, the cache or the results of a search
+	TaskTypeDocsLookup = "docs_lookup"
+)
+
+const (
+	// DefaultWebSearch is the search the web agent looks up documentation
+	// with, {query} is replaced by the query
+	DefaultWebSearch = "https://html.duckduckgo.com/html/?q={query}"
+	// defaultWebRateLimit is the time between requests to a host
+	defaultWebRateLimit = time.Second
+	// defaultWebCacheTTL is how long fetched pages are reused
+	defaultWebCacheTTL = 24 * time.Hour
+	// maxWebPageSize bounds the pages read, maxWebContent their content kept
+	maxWebPageSize = 5 << 20
+	maxWebContent  = 32 << 10
+	// maxLookupPages bounds the pages a lookup reads
+	maxLookupPages = 3
+	// maxLookupExcerpt bounds the content of each page in a lookup response
+	maxLookupExcerpt = 2000
+)
+
+const webPrompt = `You are the documentation researcher of a multi-agent software engineering swarm. You are given a question and the documentation pages found for it.
+
+Answer the question from the pages, quoting the relevant signatures and examples, and cite the URL of each page you used. Say so if the pages do not answer it.`
+
+var (
+	// ErrNoURL is returned for fetch tasks without a URL
+	ErrNoURL = errors.New("no URL to fetch")
+	// ErrNoQuery is returned for docs_lookup tasks without a query
+	ErrNoQuery = errors.New("no documentation to look up")
+	// ErrNoPagesFound is returned when a lookup found no page
+	ErrNoPagesFound = errors.New("no documentation pages found")
+	// ErrPrivateAddress is returned for pages on loopback, link-local,
+	// private or unspecified addresses, such as cloud metadata or the
+	// swarm's own API
+	ErrPrivateAddress = errors.New("address is not public")
+)
+
+// WebPage is the readable content of a page
+type WebPage struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+	// Content is markdown for HTML pages
+	Content   string    `json:"content"`
+	FetchedAt time.Time `json:"fetched_at"`
+	// Cached is set for pages read from memory
+	Cached bool `json:"cached,omitempty"`
+}
+
+// WebAgent reads documentation on the web. A fetch task reads the page of
+// its "url" input and a docs_lookup task finds the pages about its "query"
+// input: the pages of its "urls" input, cached pages matching the query or
+// the first results of a search. Requests to a host are rate limited and
+// the main content of HTML pages is extracted as markdown. Pages are cached
+// as semantic memories tagged "web". With a provider configured the agent
+// answers lookups from the pages with its model and prompts it for every
+// other task it accepts, like a ModelAgent.
+//
+// CustomConfig may set "search", a URL with {query}, DefaultWebSearch by
+// default or "off", "rateLimit", the time between requests to a host,
+// "cacheTTL", how long pages are reused, and "allowPrivate", "true" to
+// fetch pages on private addresses such as an intranet.
+type WebAgent struct {
+	*ModelAgent
+	search       string
+	cacheTTL     time.Duration
+	allowPrivate bool
+	http         *http.Client
+	limiter      *hostLimiter
+
+	mu     sync.Mutex
+	memory memory.MemoryStore
+}
+
+func init() {
+	RegisterFactory(AgentTypeWeb, func(config AgentConfig) (Agent, error) {
+		return NewWebAgent(config)
+	})
+}
+
+// NewWebAgent creates a web agent, backed by a model if its configuration
+// has a provider
+func NewWebAgent(config AgentConfig) (*WebAgent, error) {
+	search := customString(config, "search")
+	switch search {
+	case "":
+		search = DefaultWebSearch
+	case "off":
+	default:
+		if u, err := url.Parse(search); err != nil || u.Scheme == "" || !strings.Contains(search, "{query}") {
+			return nil, fmt.Errorf("agent %s: invalid search %q, expected a URL with {query}", config.ID, search)
+		}
+	}
+	rateLimit := defaultWebRateLimit
+	if s := customString(config, "rateLimit"); s != "" {
+		var err error
+		if rateLimit, err = time.ParseDuration(s); err != nil || rateLimit < 0 {
+			return nil, fmt.Errorf("agent %s: invalid rateLimit %q", config.ID, s)
+		}
+	}
+	cacheTTL := defaultWebCacheTTL
+	if s := customString(config, "cacheTTL"); s != "" {
+		var err error
+		if cacheTTL, err = time.ParseDuration(s); err != nil || cacheTTL < 0 {
+			return nil, fmt.Errorf("agent %s: invalid cacheTTL %q", config.ID, s)
+		}
+	}
+	allowPrivate := false
+	if s := customString(config, "allowPrivate"); s != "" {
+		var err error
+		if allowPrivate, err = strconv.ParseBool(s); err != nil {
+			return nil, fmt.Errorf("agent %s: invalid allowPrivate %q", config.ID, s)
+		}
+	}
+
+	var model *ModelAgent
+	if config.ProviderType != "" {
+		var err error
+		if model, err = NewModelAgent(config); err != nil {
+			return nil, err
+		}
+	} else {
+		if config.MaxConcurrency <= 0 {
+			config.MaxConcurrency = 1
+		}
+		base := NewBaseAgent(config)
+		model = &ModelAgent{BaseAgent: base, slots: newTaskSlots(base)}
+	}
+	return &WebAgent{
+		ModelAgent:   model,
+		search:       search,
+		cacheTTL:     cacheTTL,
+		allowPrivate: allowPrivate,
+		http:         newWebClient(allowPrivate),
+		limiter:      newHostLimiter(rateLimit),
+	}, nil
+}
+
+// newWebClient creates the client pages are fetched with. Unless private
+// addresses are allowed, it connects to public addresses only, checked
+// after names are resolved so redirects and names resolving to private
+// addresses are refused too. Such a client uses no proxy, which would
+// resolve names where the check cannot see them.
+func newWebClient(allowPrivate bool) *http.Client {
+	client := &http.Client{Timeout: 30 * time.Second}
+	if allowPrivate {
+		return client
+	}
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   publicOnly,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	client.Transport = transport
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return checkPublicHost(req.URL)
+	}
+	return client
+}
+
+// publicOnly refuses connections to addresses that are not public
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
+
+// checkPublicHost refuses URLs naming a host that is not public without
+// resolving it: localhost or an address that is not public
+func checkPublicHost(u *url.URL) error {
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	if ip := net.ParseIP(host); ip != nil && !publicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+	return nil
+}
+
+// nonPublicNets are the address blocks that are not reachable on the
+// internet, or reach the host or its networks
+var nonPublicNets = mustParseCIDRs(
+	// IPv4: this network, private, shared (CGNAT), loopback, link-local,
+	// protocol assignments, documentation, benchmarking, multicast,
+	// reserved and broadcast
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16",
+	"172.16.0.0/12", "192.0.0.0/24", "192.0.2.0/24", "192.168.0.0/16", "198.18.0.0/15",
+	"198.51.100.0/24", "203.0.113.0/24", "224.0.0.0/4", "240.0.0.0/4",
+	// IPv6: unspecified, loopback and IPv4-compatible, discard, local-use
+	// NAT64, documentation, unique local, link-local and multicast
+	"::/96", "100::/64", "64:ff9b:1::/48", "2001:db8::/32", "fc00::/7", "fe80::/10", "ff00::/8",
+)
+
+// nat64Net embeds IPv4 addresses in its last 32 bits, which are checked
+// like the IPv4 address they translate to
+var nat64Net = mustParseCIDRs("64:ff9b::/96")[0]
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = n
+	}
+	return nets
+}
+
+// publicIP reports whether ip is reachable on the internet, i.e. in none of
+// the nonPublicNets. IPv4-mapped and NAT64 addresses are checked as the
+// IPv4 address they stand for.
+func publicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	} else if nat64Net.Contains(ip) {
+		ip = ip[12:16]
+	}
+	for _, n := range nonPublicNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// UseMemory makes the agent cache pages in store
+func (a *WebAgent) UseMemory(store memory.MemoryStore) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.memory = store
+}
+
+// CanHandleTask accepts fetch and docs_lookup tasks, and the tasks of a
+// ModelAgent when the web agent has a model
+func (a *WebAgent) CanHandleTask(task Task) bool {
+	if task.Type == TaskTypeFetch || task.Type == TaskTypeDocsLookup {
+		return true
+	}
+	return a.client != nil && a.ModelAgent.CanHandleTask(task)
+}
+
+// ExecuteTask fetches pages or prompts the model. The results of fetch and
+// docs_lookup tasks have the []WebPage read as their "pages" output and
+// the pages, or the model's answer from them, as their "response".
+func (a *WebAgent) ExecuteTask(ctx context.Context, task Task) (*TaskResult, error) {
+	if task.Type != TaskTypeFetch && task.Type != TaskTypeDocsLookup {
+		if a.client == nil {
+			return nil, fmt.Errorf("agent %s: no model for %s tasks", a.id, task.Type)
+		}
+		return a.ModelAgent.ExecuteTask(ctx, task)
+	}
+
+	if !a.slots.acquire() {
+		return nil, fmt.Errorf("%w: %s is running %d tasks", ErrAgentBusy, a.id, a.maxConcurrency())
+	}
+	defer a.slots.release()
+
+	start := time.Now()
+	result := &TaskResult{
+		TaskID:  task.ID,
+		AgentID: a.id,
+	}
+	var pages []WebPage
+	var response string
+	var err error
+	if task.Type == TaskTypeFetch {
+		var page *WebPage
+		if page, err = a.fetchTask(ctx, task); err == nil {
+			pages = []WebPage{*page}
+			response = fmt.Sprintf("# %s\n%s\n\n%s", page.Title, page.URL, page.Content)
+		}
+	} else {
+		pages, response, err = a.lookup(ctx, task)
+	}
+	result.ExecutionTime = time.Since(start)
+	result.CompletedAt = time.Now()
+	a.RecordTask(result.ExecutionTime, err == nil)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+
+	cached := 0
+	for _, page := range pages {
+		if page.Cached {
+			cached++
+		}
+	}
+	result.Success = true
+	result.Output = map[string]interface{}{
+		"response": response,
+		"pages":    pages,
+	}
+	result.Metadata = map[string]interface{}{
+		"pages":  len(pages),
+		"cached": cached,
+	}
+	return result, nil
+}
+
+func (a *WebAgent) fetchTask(ctx context.Context, task Task) (*WebPage, error) {
+	rawURL, _ := task.Input["url"].(string)
+	if rawURL == "" {
+		return nil, ErrNoURL
+	}
+	return a.Fetch(ctx, rawURL)
+}
+
+// lookup finds the pages about the query of a task, and answers it from them
+// with the model if there is one
+func (a *WebAgent) lookup(ctx context.Context, task Task) ([]WebPage, string, error) {
+	query, _ := task.Input["query"].(string)
+	if query == "" {
+		query = task.Description
+	}
+	if strings.TrimSpace(query) == "" {
+		return nil, "", ErrNoQuery
+	}
+
+	urls := stringList(task.Input["urls"])
+	var pages []WebPage
+	if len(urls) == 0 {
+		pages = a.cachedPages(ctx, query)
+	}
+	if len(pages) == 0 && len(urls) == 0 && a.search != "off" {
+		results, err := a.searchResults(ctx, query)
+		if err != nil {
+			return nil, "", err
+		}
+		urls = results
+	}
+	var fetchErr error
+	for _, u := range urls {
+		if len(pages) == maxLookupPages {
+			break
+		}
+		page, err := a.Fetch(ctx, u)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, "", ctx.Err()
+			}
+			fetchErr = err
+			continue
+		}
+		pages = append(pages, *page)
+	}
+	if len(pages) == 0 {
+		if fetchErr != nil {
+			return nil, "", fmt.Errorf("%w: %w", ErrNoPagesFound, fetchErr)
+		}
+		return nil, "", ErrNoPagesFound
+	}
+
+	var b strings.Builder
+	for _, page := range pages {
+		content := page.Content
+		if len(content) > maxLookupExcerpt {
+			content = content[:maxLookupExcerpt] + "\n..."
+		}
+		fmt.Fprintf(&b, "# %s\n%s\n\n%s\n\n", page.Title, page.URL, content)
+	}
+	if a.client == nil {
+		return pages, strings.TrimSpace(b.String()), nil
+	}
+	resp, err := a.client.Complete(ctx, provider.Request{
+		System: webPrompt,
+		Prompt: fmt.Sprintf("Question: %s\n\n%s", query, b.String()),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return pages, resp.Content, nil
+}
+
+// Fetch reads a page, from the cache if it was fetched within the cache
+// TTL, and caches it
+func (a *WebAgent) Fetch(ctx context.Context, rawURL string) (*WebPage, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q, expected http or https", rawURL)
+	}
+	if !a.allowPrivate {
+		if err := checkPublicHost(u); err != nil {
+			return nil, fmt.Errorf("cannot fetch %s: %w", u, err)
+		}
+	}
+	u.Fragment = ""
+	if page := a.cachedPage(ctx, u.String()); page != nil {
+		return page, nil
+	}
+
+	body, contentType, err := a.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	page := &WebPage{URL: u.String(), FetchedAt: time.Now()}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		if page.Title, page.Content, err = extractReadable(body, u); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", u, err)
+		}
+	case strings.HasPrefix(mediaType, "text/") || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		page.Content = strings.TrimSpace(body)
+	default:
+		return nil, fmt.Errorf("cannot read %s: unsupported content type %q", u, contentType)
+	}
+	if page.Title == "" {
+		page.Title = u.String()
+	}
+	if len(page.Content) > maxWebContent {
+		page.Content = page.Content[:maxWebContent]
+	}
+	a.cache(ctx, page)
+	return page, nil
+}
+
+// get requests a page, waiting for the rate limit of its host
+func (a *WebAgent) get(ctx context.Context, u *url.URL) (body, contentType string, err error) {
+	if err := a.limiter.wait(ctx, u.Host); err != nil {
+		return "", "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("User-Agent", "opencode/1.0")
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to fetch %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("failed to fetch %s: %s", u, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxWebPageSize))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %w", u, err)
+	}
+	return string(data), resp.Header.Get("Content-Type"), nil
+}
+
+// searchResults returns the first pages a search finds for a query
+func (a *WebAgent) searchResults(ctx context.Context, query string) ([]string, error) {
+	u, err := url.Parse(strings.ReplaceAll(a.search, "{query}", url.QueryEscape(query)))
+	if err != nil {
+		return nil, err
+	}
+	body, _, err := a.get(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	links := pageLinks(body, u)
+	if len(links) > maxLookupPages {
+		links = links[:maxLookupPages]
+	}
+	return links, nil
+}
+
+func (a *WebAgent) store() memory.MemoryStore {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.memory
+}
+
+// webMemoryID identifies the cached page of a URL
+func webMemoryID(url string) string {
+	return "web:" + url
+}
+
+// cachedPage returns the page of a URL if it was cached within the TTL
+func (a *WebAgent) cachedPage(ctx context.Context, url string) *WebPage {
+	store := a.store()
+	if store == nil {
+		return nil
+	}
+	mem, err := store.Retrieve(ctx, webMemoryID(url))
+	if err != nil {
+		return nil
+	}
+	return a.freshPage(*mem)
+}
+
+// cachedPages returns the cached pages matching a query
+func (a *WebAgent) cachedPages(ctx context.Context, query string) []WebPage {
+	store := a.store()
+	if store == nil {
+		return nil
+	}
+	found, err := memory.HybridSearch(ctx, store, memory.HybridQuery{
+		Text:     query,
+		Types:    []memory.MemoryType{memory.MemoryTypeSemantic},
+		MinScore: 0.3,
+		Limit:    20,
+	})
+	if err != nil {
+		return nil
+	}
+	var pages []WebPage
+	for _, scored := range found {
+		if len(pages) == maxLookupPages {
+			break
+		}
+		if !strings.HasPrefix(scored.Memory.ID, "web:") {
+			continue
+		}
+		if page := a.freshPage(scored.Memory); page != nil {
+			pages = append(pages, *page)
+		}
+	}
+	return pages
+}
+
+// freshPage returns the page of a cached memory, nil if it is older than
+// the TTL
+func (a *WebAgent) freshPage(mem memory.Memory) *WebPage {
+	fetchedAt, _ := mem.Metadata["fetched_at"].(string)
+	at, err := time.Parse(time.RFC3339, fetchedAt)
+	if err != nil || time.Since(at) > a.cacheTTL {
+		return nil
+	}
+	url, _ := mem.Metadata["url"].(string)
+	title, _ := mem.Metadata["title"].(string)
+	content, _ := mem.Content.(string)
+	return &WebPage{URL: url, Title: title, Content: content, FetchedAt: at, Cached: true}
+}
+
+// cache stores a page as a semantic memory, replacing the page cached for
+// its URL
+func (a *WebAgent) cache(ctx context.Context, page *WebPage) {
+	store := a.store()
+	if store == nil || a.cacheTTL == 0 {
+		return
+	}
+	_ = store.Store(ctx, memory.Memory{
+		ID:       webMemoryID(page.URL),
+		Type:     memory.MemoryTypeSemantic,
+		Content:  page.Content,
+		Tags:     []string{"web", "docs"},
+		Priority: memory.PriorityLow,
+		Metadata: map[string]interface{}{
+			"url":        page.URL,
+			"title":      page.Title,
+			"fetched_at": page.FetchedAt.UTC().Format(time.RFC3339),
+		},
+	})
+}
+
+// stringList reads a task input that is a string or a list of strings
+func stringList(input interface{}) []string {
+	switch v := input.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []string:
+		return v
+	case []interface{}:
+		var list []string
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// hostLimiter spaces the requests to each host by an interval
+type hostLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+func newHostLimiter(interval time.Duration) *hostLimiter {
+	return &hostLimiter{interval: interval, next: make(map[string]time.Time)}
+}
+
+// wait blocks until a request to host may be sent
+func (l *hostLimiter) wait(ctx context.Context, host string) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.interval)
+	l.mu.Unlock()
+
+	if delay := time.Until(at); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+const docsPage = `<html><head><title>strings package - strings - Go Packages</title><script>track()</script></head>
+<body>
+<nav><a href="/">Home</a> <a href="/about">About</a></nav>
+<div class="content">
+<h2 id="Cut">func Cut</h2>
+<pre>func Cut(s, sep string) (before, after string, found bool)</pre>
+<p>Cut slices s around the first instance of sep, returning the text before and after sep.</p>
+<p>The found result reports whether sep appears in s. If sep does not appear in s, cut returns s, "", false.</p>
+<p>See <a href="/strings#Index">Index</a> for the position of sep.</p>
+</div>
+<footer>Copyright the Go Authors, all rights reserved, with a long footer paragraph.</footer>
+</body></html>`
+
+func TestExtractReadable(t *testing.T) {
+	base, _ := url.Parse("https://pkg.go.dev/strings")
+	title, content, err := extractReadable(docsPage, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title != "strings package - strings - Go Packages" {
+		t.Errorf("title = %q", title)
+	}
+	for _, want := range []string{"## func Cut", "func Cut(s, sep string)", "Cut slices s around", "[Index](https://pkg.go.dev/strings#Index)"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content does not contain %q:\n%s", want, content)
+		}
+	}
+	for _, noise := range []string{"track()", "About", "Copyright"} {
+		if strings.Contains(content, noise) {
+			t.Errorf("content contains %q:\n%s", noise, content)
+		}
+	}
+}
+
+func TestPageLinks(t *testing.T) {
+	base, _ := url.Parse("https://html.duckduckgo.com/html/?q=strings.Cut")
+	page := `<a href="//duckduckgo.com/l/?uddg=https%3A%2F%2Fpkg.go.dev%2Fstrings%23Cut&rut=x">strings</a>
+<a href="/html/?q=next">Next</a>
+<a href="https://duckduckgo.com/settings">Settings</a>
+<a href="https://go.dev/doc/">Docs</a>
+<a href="https://pkg.go.dev/strings">strings again</a>
+<a href="mailto:someone@example.com">Mail</a>`
+	got := pageLinks(page, base)
+	want := []string{"https://pkg.go.dev/strings", "https://go.dev/doc/"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("links = %q, want %q", got, want)
+	}
+}
+
+func TestWebAgent(t *testing.T) {
+	var fetches atomic.Int32
+	docs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		switch r.URL.Path {
+		case "/strings":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = w.Write([]byte(docsPage))
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer docs.Close()
+	// The search is on another site than the docs
+	docsURL := strings.Replace(docs.URL, "127.0.0.1", "localhost", 1)
+	var queries []string
+	search := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("q"))
+		_, _ = w.Write([]byte(`<a href="/l/?uddg=` + url.QueryEscape(docsURL+"/strings") + `">strings</a><a href="/next">Next</a>`))
+	}))
+	defer search.Close()
+
+	ag, err := New(AgentConfig{ID: "web", Type: AgentTypeWeb, CustomConfig: map[string]interface{}{
+		"search":       search.URL + "/?q={query}",
+		"rateLimit":    "100ms",
+		"allowPrivate": "true",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := memory.NewHierarchicalMemoryStore(memory.HierarchicalMemoryConfig{})
+	ag.(MemoryUser).UseMemory(store)
+	ctx := context.Background()
+
+	result, err := ag.ExecuteTask(ctx, Task{ID: "t1", Type: TaskTypeDocsLookup, Input: map[string]interface{}{"query": "go strings Cut"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := result.Output["pages"].([]WebPage)
+	if len(queries) != 1 || queries[0] != "go strings Cut" || len(pages) != 1 || pages[0].URL != docsURL+"/strings" || pages[0].Cached {
+		t.Fatalf("queries = %q, pages = %+v", queries, pages)
+	}
+	if response := result.Output["response"].(string); !strings.HasPrefix(response, "# strings package") || !strings.Contains(response, "Cut slices s") {
+		t.Errorf("response = %q", response)
+	}
+	cached, err := store.Query(ctx, memory.MemoryQuery{Type: memory.MemoryTypeSemantic, Tags: []string{"web"}})
+	if err != nil || len(cached) != 1 {
+		t.Fatalf("cached pages = %+v, %v", cached, err)
+	}
+
+	// Lookups and fetches of cached pages do not go to the web
+	result, err = ag.ExecuteTask(ctx, Task{ID: "t2", Type: TaskTypeDocsLookup, Description: "how does strings Cut split around sep"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages := result.Output["pages"].([]WebPage); len(queries) != 1 || len(pages) != 1 || !pages[0].Cached {
+		t.Errorf("queries = %q, pages = %+v", queries, pages)
+	}
+	result, err = ag.ExecuteTask(ctx, Task{ID: "t3", Type: TaskTypeFetch, Input: map[string]interface{}{"url": docsURL + "/strings#Cut"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages := result.Output["pages"].([]WebPage); fetches.Load() != 1 || !pages[0].Cached {
+		t.Errorf("fetches = %d, pages = %+v", fetches.Load(), pages)
+	}
+
+	// Requests to a host are spaced by the rate limit
+	start := time.Now()
+	for _, path := range []string{"/missing", "/image.png"} {
+		if _, err := ag.ExecuteTask(ctx, Task{ID: "t4", Type: TaskTypeFetch, Input: map[string]interface{}{"url": docsURL + path}}); err == nil {
+			t.Errorf("fetching %s succeeded", path)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("two requests took %s, want at least the rate limit", elapsed)
+	}
+	if _, err := ag.ExecuteTask(ctx, Task{ID: "t5", Type: TaskTypeFetch, Input: map[string]interface{}{"url": "file:///etc/passwd"}}); err == nil {
+		t.Error("fetching a file URL succeeded")
+	}
+}
+
+func TestWebAgentRefusesPrivateAddresses(t *testing.T) {
+	var requests atomic.Int32
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte("secret"))
+	}))
+	defer local.Close()
+
+	ag, err := NewWebAgent(AgentConfig{ID: "web", Type: AgentTypeWeb, CustomConfig: map[string]interface{}{
+		"search":    "off",
+		"rateLimit": "0s",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, rawURL := range []string{
+		local.URL,
+		strings.Replace(local.URL, "127.0.0.1", "localhost", 1),
+		"http://localhost:7420/v1/status",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/",
+		"http://172.16.0.1/",
+		"http://192.168.1.1/",
+		"http://[::1]:7420/",
+		"http://[fe80::1]/",
+		"http://[::ffff:127.0.0.1]/",
+		"http://[::ffff:10.0.0.1]/",
+		"http://[64:ff9b::a9fe:a9fe]/",
+		"http://[64:ff9b::7f00:1]:7420/",
+		"http://[::127.0.0.1]/",
+		"http://[fd00::1]/",
+		"http://0.0.0.0:7420/",
+		"http://0.1.2.3/",
+		"http://100.64.0.1/",
+		"http://100.127.255.254/",
+		"http://255.255.255.255/",
+	} {
+		if _, err := ag.Fetch(ctx, rawURL); !errors.Is(err, ErrPrivateAddress) {
+			t.Errorf("Fetch(%s) = %v, want ErrPrivateAddress", rawURL, err)
+		}
+	}
+
+	// Names are checked once resolved, when connecting
+	u, _ := url.Parse(local.URL)
+	if _, _, err := ag.get(ctx, u); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("get(%s) = %v, want ErrPrivateAddress", u, err)
+	}
+
+	// Redirects to private addresses are refused
+	redirect := httptest.NewRequest(http.MethodGet, "http://169.254.169.254/latest/meta-data/", nil)
+	if err := ag.http.CheckRedirect(redirect, []*http.Request{httptest.NewRequest(http.MethodGet, "https://docs.example.com/", nil)}); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("redirect = %v, want ErrPrivateAddress", err)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("the local server got %d requests", requests.Load())
+	}
+
+	if _, err := NewWebAgent(AgentConfig{ID: "web", Type: AgentTypeWeb, CustomConfig: map[string]interface{}{"allowPrivate": "maybe"}}); err == nil {
+		t.Error("an invalid allowPrivate was accepted")
+	}
+}
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"100.63.255.255", true},
+		{"100.128.0.1", true},
+		{"2606:4700::1111", true},
+		{"::ffff:8.8.8.8", true},
+		{"64:ff9b::808:808", true},
+		{"0.0.0.0", false},
+		{"100.64.0.1", false},
+		{"192.0.2.1", false},
+		{"::", false},
+		{"::ffff:192.168.0.1", false},
+		{"::ffff:100.64.0.1", false},
+		{"64:ff9b::c0a8:1", false},
+		{"64:ff9b:1::1", false},
+		{"ff02::1", false},
+	}
+	for _, tt := range tests {
+		if got := publicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("publicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+// StatusError is an error response of the API. It unwraps to the swarm
+// error named by its code, so errors.Is(err, swarm.ErrQueueFull) works the
+// same against a remote swarm.
+type StatusError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return "swarm API: " + e.Message
+}
+
+func (e *StatusError) Unwrap() error {
+	for _, c := range errorCodes {
+		if c.code == e.Code {
+			return c.err
+		}
+	}
+	return nil
+}
+
+// Client talks to the API of a running swarm
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient creates a client for the API at addr, either host:port or a
+// full URL
+func NewClient(addr string) *Client {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(addr, "/"),
+		http:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Status returns the state of the swarm
+func (c *Client) Status(ctx context.Context) (swarm.SystemStatus, error) {
+	var status swarm.SystemStatus
+	err := c.do(ctx, http.MethodGet, "/v1/status", nil, &status)
+	return status, err
+}
+
+// Tasks lists the tasks of the swarm, optionally only those in one state
+func (c *Client) Tasks(ctx context.Context, state swarm.TaskState) ([]TaskInfo, error) {
+	path := "/v1/tasks"
+	if state != "" {
+		path += "?state=" + url.QueryEscape(string(state))
+	}
+	var tasks []TaskInfo
+	err := c.do(ctx, http.MethodGet, path, nil, &tasks)
+	return tasks, err
+}
+
+// Task returns a single task
+func (c *Client) Task(ctx context.Context, id string) (TaskInfo, error) {
+	var task TaskInfo
+	err := c.do(ctx, http.MethodGet, "/v1/tasks/"+url.PathEscape(id), nil, &task)
+	return task, err
+}
+
+// Memories returns the memories matching a query such as
+// "tag:error after:24h timeout", see memory.ParseQuery
+func (c *Client) Memories(ctx context.Context, query string) ([]MemoryInfo, error) {
+	var memories []MemoryInfo
+	err := c.do(ctx, http.MethodGet, "/v1/memories?q="+url.QueryEscape(query), nil, &memories)
+	return memories, err
+}
+
+// ExportMemories writes a replica of the memory store to w in a format of
+// memory.ExportFormats
+func (c *Client) ExportMemories(ctx context.Context, format memory.ExportFormat, w io.Writer) error {
+	return c.do(ctx, http.MethodGet, "/v1/memories/export?format="+url.QueryEscape(string(format)), nil, w)
+}
+
+// Comparisons compares shadow agents to the agents they shadowed on the
+// tasks finished within since, only primary and shadow if set
+func (c *Client) Comparisons(ctx context.Context, primary, shadow string, since time.Duration) ([]swarm.Comparison, error) {
+	query := url.Values{}
+	if primary != "" {
+		query.Set("primary", primary)
+	}
+	if shadow != "" {
+		query.Set("shadow", shadow)
+	}
+	if since > 0 {
+		query.Set("since", since.String())
+	}
+	var comparisons []swarm.Comparison
+	err := c.do(ctx, http.MethodGet, "/v1/comparisons?"+query.Encode(), nil, &comparisons)
+	return comparisons, err
+}
+
+// Topology returns the graph of the agents of the swarm and how they are
+// linked
+func (c *Client) Topology(ctx context.Context) (swarm.Topology, error) {
+	var topology swarm.Topology
+	err := c.do(ctx, http.MethodGet, "/v1/topology", nil, &topology)
+	return topology, err
+}
+
+// PinMemory keeps a memory from being pruned or consolidated
+func (c *Client) PinMemory(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/v1/memories/"+url.PathEscape(id)+"/pin", nil, nil)
+}
+
+// UnpinMemory lets a pinned memory be pruned and consolidated again
+func (c *Client) UnpinMemory(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/v1/memories/"+url.PathEscape(id)+"/unpin", nil, nil)
+}
+
+// Submit queues a task and returns its ID
+func (c *Client) Submit(ctx context.Context, req SubmitRequest) (string, error) {
+	var resp SubmitResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/tasks", req, &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// SubmitBatch queues tasks in one step and returns the outcome of each.
+// The error unwraps to swarm.ErrBatchRejected when a task was not queued.
+func (c *Client) SubmitBatch(ctx context.Context, req BatchRequest) ([]BatchResult, error) {
+	var resp BatchResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/tasks/batch", req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Rejected {
+		return resp.Results, fmt.Errorf("swarm API: %w", swarm.ErrBatchRejected)
+	}
+	return resp.Results, nil
+}
+
+// Cancel cancels a queued or running task
+func (c *Client) Cancel(ctx context.Context, id string) (TaskInfo, error) {
+	var task TaskInfo
+	err := c.do(ctx, http.MethodPost, "/v1/tasks/"+url.PathEscape(id)+"/cancel", nil, &task)
+	return task, err
+}
+
+// Retry queues a finished task again
+func (c *Client) Retry(ctx context.Context, id string) (TaskInfo, error) {
+	var task TaskInfo
+	err := c.do(ctx, http.MethodPost, "/v1/tasks/"+url.PathEscape(id)+"/retry", nil, &task)
+	return task, err
+}
+
+// Stop asks the swarm to shut down
+func (c *Client) Stop(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/v1/stop", nil, nil)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	// The API takes writes only as JSON, even those without a body
+	if method != http.MethodGet {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("swarm API unreachable at %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var e errorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&e); err == nil && e.Error != "" {
+			return &StatusError{StatusCode: resp.StatusCode, Code: e.Code, Message: e.Error}
+		}
+		return &StatusError{StatusCode: resp.StatusCode, Message: resp.Status}
+	}
+	if out == nil {
+		return nil
+	}
+	if w, ok := out.(io.Writer); ok {
+		_, err := io.Copy(w, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid swarm API response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"golang.org/x/net/websocket"
+)
+
+// Messages of the event feed. Clients send MessageSubscribe, the server
+// answers with MessageSubscribed and sends MessageEvent for every event the
+// subscription matches, or MessageError for a message it cannot follow.
+const (
+	MessageSubscribe  = "subscribe"
+	MessageSubscribed = "subscribed"
+	MessageEvent      = "event"
+	MessageError      = "error"
+)
+
+// eventWriteTimeout bounds how long the feed waits for a client to take a
+// message before it drops the connection
+const eventWriteTimeout = 10 * time.Second
+
+// EventInfo is the wire form of a swarm.TimelineEvent
+type EventInfo struct {
+	Seq       int64                   `json:"seq"`
+	Type      swarm.TimelineEventType `json:"type"`
+	Timestamp time.Time               `json:"timestamp"`
+	Subject   string                  `json:"subject,omitempty"`
+	Summary   string                  `json:"summary"`
+	Details   map[string]interface{}  `json:"details,omitempty"`
+}
+
+// FeedMessage is a message of the event feed served at GET /v1/events
+type FeedMessage struct {
+	Type string `json:"type"`
+	// Events are the event types subscribed to, all when empty
+	Events []swarm.TimelineEventType `json:"events,omitempty"`
+	// AfterSeq, in a subscription, first replays the matching events after
+	// it that the timeline still keeps
+	AfterSeq int64      `json:"afterSeq,omitempty"`
+	Event    *EventInfo `json:"event,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// eventFeed serves the timeline events of the coordinator as JSON over a
+// WebSocket. A connection gets every event until it subscribes to some
+// types, or the types of the comma separated "types" query parameter.
+func (s *Server) eventFeed() http.Handler {
+	return websocket.Server{
+		Handshake: checkOrigin,
+		Handler:   s.serveEvents,
+	}
+}
+
+// checkOrigin accepts clients that are no browser and pages served by the
+// API's own host, so other web pages cannot read the swarm's events
+func checkOrigin(config *websocket.Config, r *http.Request) error {
+	u, err := requestOrigin(r)
+	if err != nil {
+		return err
+	}
+	if u != nil {
+		config.Origin = u
+	}
+	return nil
+}
+
+func (s *Server) serveEvents(ws *websocket.Conn) {
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	defer cancel()
+	// Subscribe before any replay so no event falls between the two
+	events := s.coordinator.Subscribe(ctx)
+
+	requests := make(chan FeedMessage)
+	go func() {
+		defer cancel()
+		for {
+			var msg FeedMessage
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				return
+			}
+			select {
+			case requests <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var types []swarm.TimelineEventType
+	if v := ws.Request().URL.Query().Get("types"); v != "" {
+		var names []swarm.TimelineEventType
+		for _, name := range strings.Split(v, ",") {
+			names = append(names, swarm.TimelineEventType(name))
+		}
+		var err error
+		if types, err = eventTypes(names); err != nil {
+			_ = sendFeed(ws, FeedMessage{Type: MessageError, Error: err.Error()})
+			return
+		}
+	}
+	// last is the seq of the last event replayed, live events up to it
+	// were already sent or skipped
+	var last int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-requests:
+			if msg.Type != MessageSubscribe {
+				if sendFeed(ws, FeedMessage{Type: MessageError, Error: fmt.Sprintf("unknown message type %q", msg.Type)}) != nil {
+					return
+				}
+				continue
+			}
+			subscribed, err := eventTypes(msg.Events)
+			if err != nil {
+				if sendFeed(ws, FeedMessage{Type: MessageError, Error: err.Error()}) != nil {
+					return
+				}
+				continue
+			}
+			types = subscribed
+			if sendFeed(ws, FeedMessage{Type: MessageSubscribed, Events: types, AfterSeq: msg.AfterSeq}) != nil {
+				return
+			}
+			if msg.AfterSeq <= 0 {
+				continue
+			}
+			for _, event := range s.coordinator.Timeline(swarm.TimelineFilter{}) {
+				if event.Seq <= msg.AfterSeq {
+					continue
+				}
+				last = max(last, event.Seq)
+				if matchesTypes(types, event) && sendEvent(ws, event) != nil {
+					return
+				}
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.Payload.Seq <= last || !matchesTypes(types, event.Payload) {
+				continue
+			}
+			if sendEvent(ws, event.Payload) != nil {
+				return
+			}
+		}
+	}
+}
+
+// eventTypes checks the names of event types
+func eventTypes(names []swarm.TimelineEventType) ([]swarm.TimelineEventType, error) {
+	var types []swarm.TimelineEventType
+	for _, name := range names {
+		t := swarm.TimelineEventType(strings.TrimSpace(string(name)))
+		if t == "" {
+			continue
+		}
+		if !slices.Contains(swarm.TimelineEventTypes, t) {
+			return nil, fmt.Errorf("unknown event type %q", t)
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+func matchesTypes(types []swarm.TimelineEventType, event swarm.TimelineEvent) bool {
+	return len(types) == 0 || slices.Contains(types, event.Type)
+}
+
+func sendEvent(ws *websocket.Conn, event swarm.TimelineEvent) error {
+	return sendFeed(ws, FeedMessage{Type: MessageEvent, Event: &EventInfo{
+		Seq:       event.Seq,
+		Type:      event.Type,
+		Timestamp: event.Timestamp,
+		Subject:   event.Subject,
+		Summary:   event.Summary,
+		Details:   event.Details,
+	}})
+}
+
+func sendFeed(ws *websocket.Conn, msg FeedMessage) error {
+	_ = ws.SetWriteDeadline(time.Now().Add(eventWriteTimeout))
+	return websocket.JSON.Send(ws, msg)
+}
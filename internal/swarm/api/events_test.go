@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"golang.org/x/net/websocket"
+)
+
+// dialFeed connects to the event feed of server as a page of origin
+func dialFeed(server *httptest.Server, query, origin string) (*websocket.Conn, error) {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/v1/events" + query
+	return websocket.Dial(url, "", origin)
+}
+
+// receiveFeed reads the next message of the feed, failing the test when
+// none comes in time
+func receiveFeed(t *testing.T, ws *websocket.Conn) FeedMessage {
+	t.Helper()
+	_ = ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg FeedMessage
+	if err := websocket.JSON.Receive(ws, &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestEventFeed(t *testing.T) {
+	c := newTestCoordinator(t)
+	server := httptest.NewServer(NewServer(c, nil))
+	defer server.Close()
+	submit := func(description string) {
+		t.Helper()
+		if err := c.SubmitTask(context.Background(), agent.Task{Type: "executor", Description: description}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("foreign origin", func(t *testing.T) {
+		if ws, err := dialFeed(server, "", "https://evil.example"); err == nil {
+			ws.Close()
+			t.Fatal("a foreign page connected to the feed")
+		}
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		ws, err := dialFeed(server, "?types=task_submitted,bogus", server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ws.Close()
+		if msg := receiveFeed(t, ws); msg.Type != MessageError || !strings.Contains(msg.Error, "bogus") {
+			t.Errorf("message = %+v, want an error naming the unknown type", msg)
+		}
+	})
+
+	t.Run("topic filter", func(t *testing.T) {
+		ws, err := dialFeed(server, "", server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ws.Close()
+		if err := websocket.JSON.Send(ws, FeedMessage{Type: MessageSubscribe, Events: []swarm.TimelineEventType{swarm.TimelineTaskSubmitted}}); err != nil {
+			t.Fatal(err)
+		}
+		if msg := receiveFeed(t, ws); msg.Type != MessageSubscribed {
+			t.Fatalf("message = %+v, want the subscription", msg)
+		}
+
+		// Each task is submitted and finished, only the submissions match
+		submit("first")
+		submit("second")
+		for _, want := range []string{"first", "second"} {
+			msg := receiveFeed(t, ws)
+			if msg.Type != MessageEvent || msg.Event.Type != swarm.TimelineTaskSubmitted {
+				t.Fatalf("message = %+v, want a submission", msg)
+			}
+			if !strings.Contains(msg.Event.Summary, want) {
+				t.Errorf("summary = %q, want the submission of %q", msg.Event.Summary, want)
+			}
+		}
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		events := c.Timeline(swarm.TimelineFilter{})
+		if len(events) == 0 {
+			t.Fatal("no timeline events to replay")
+		}
+		afterSeq := events[len(events)-1].Seq
+		submit("third")
+		submit("fourth")
+
+		ws, err := dialFeed(server, "?types=task_submitted", server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer ws.Close()
+		if err := websocket.JSON.Send(ws, FeedMessage{Type: MessageSubscribe, Events: []swarm.TimelineEventType{swarm.TimelineTaskSubmitted}, AfterSeq: afterSeq}); err != nil {
+			t.Fatal(err)
+		}
+		if msg := receiveFeed(t, ws); msg.Type != MessageSubscribed || msg.AfterSeq != afterSeq {
+			t.Fatalf("message = %+v, want the subscription after %d", msg, afterSeq)
+		}
+		var last int64
+		for _, want := range []string{"third", "fourth"} {
+			msg := receiveFeed(t, ws)
+			if msg.Type != MessageEvent || msg.Event.Seq <= max(afterSeq, last) {
+				t.Fatalf("message = %+v, want an event after %d", msg, max(afterSeq, last))
+			}
+			if !strings.Contains(msg.Event.Summary, want) {
+				t.Errorf("replayed %q, want the submission of %q", msg.Event.Summary, want)
+			}
+			last = msg.Event.Seq
+		}
+	})
+}
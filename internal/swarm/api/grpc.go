@@ -0,0 +1,245 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/api/swarmpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcCodes are the gRPC codes of the HTTP statuses swarm errors are served
+// with
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:         codes.InvalidArgument,
+	http.StatusNotFound:           codes.NotFound,
+	http.StatusConflict:           codes.FailedPrecondition,
+	http.StatusTooManyRequests:    codes.ResourceExhausted,
+	http.StatusServiceUnavailable: codes.Unavailable,
+}
+
+// NewGRPCServer creates a gRPC server with the streaming API of a
+// coordinator, the opencode.swarm.v1.Swarm service of swarmpb/swarm.proto
+func NewGRPCServer(c *swarm.Coordinator, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	swarmpb.RegisterSwarmServer(s, &grpcService{coordinator: c})
+	return s
+}
+
+// grpcService implements swarmpb.SwarmServer. Its streams follow the
+// timeline of the coordinator.
+type grpcService struct {
+	swarmpb.UnimplementedSwarmServer
+	coordinator *swarm.Coordinator
+}
+
+func (s *grpcService) GetStatus(ctx context.Context, req *swarmpb.GetStatusRequest) (*swarmpb.Status, error) {
+	st := s.coordinator.GetSystemStatus()
+	finished := make(map[string]int32, len(st.FinishedTasks))
+	for state, n := range st.FinishedTasks {
+		finished[string(state)] = int32(n)
+	}
+	return &swarmpb.Status{
+		Running:       st.Running,
+		Health:        string(st.SystemHealth.OverallStatus),
+		HealthScore:   st.SystemHealth.OverallScore,
+		Agents:        int32(len(st.AgentHealth)),
+		QueuedTasks:   int32(st.QueuedTasks),
+		RunningTasks:  int32(st.RunningTasks),
+		FinishedTasks: finished,
+		ActiveVotes:   int32(st.ActiveSessions),
+		Alerts:        int32(st.Alerts),
+		LastEvent:     st.LastEvent,
+	}, nil
+}
+
+func (s *grpcService) SubmitTask(ctx context.Context, req *swarmpb.SubmitTaskRequest) (*swarmpb.SubmitTaskResponse, error) {
+	if req.GetType() == "" {
+		return nil, status.Error(codes.InvalidArgument, "task type is required")
+	}
+	task := agent.Task{
+		ID:             uuid.New().String(),
+		Type:           req.GetType(),
+		Description:    req.GetDescription(),
+		Priority:       int(req.GetPriority()),
+		MaxRetries:     int(req.GetMaxRetries()),
+		Input:          req.GetInput().AsMap(),
+		IdempotencyKey: req.GetIdempotencyKey(),
+	}
+	if err := s.coordinator.SubmitTask(ctx, task); err != nil {
+		return nil, grpcError(err)
+	}
+	id := task.ID
+	if record, err := s.coordinator.GetTask(task.ID); err == nil {
+		id = record.Task.ID
+	}
+	return &swarmpb.SubmitTaskResponse{Id: id}, nil
+}
+
+func (s *grpcService) WatchEvents(req *swarmpb.WatchEventsRequest, stream grpc.ServerStreamingServer[swarmpb.Event]) error {
+	types := make([]swarm.TimelineEventType, len(req.GetTypes()))
+	for i, t := range req.GetTypes() {
+		types[i] = swarm.TimelineEventType(t)
+	}
+	return s.follow(stream.Context(), req.GetAfterSeq(), nil, func(event swarm.TimelineEvent) (bool, error) {
+		if len(types) > 0 && !slices.Contains(types, event.Type) {
+			return true, nil
+		}
+		details, err := detailsStruct(event.Details)
+		if err != nil {
+			return false, err
+		}
+		return true, stream.Send(&swarmpb.Event{
+			Seq:     event.Seq,
+			Type:    string(event.Type),
+			Time:    timestamppb.New(event.Timestamp),
+			Subject: event.Subject,
+			Summary: event.Summary,
+			Details: details,
+		})
+	})
+}
+
+func (s *grpcService) WatchTaskProgress(req *swarmpb.WatchTaskProgressRequest, stream grpc.ServerStreamingServer[swarmpb.TaskProgress]) error {
+	taskID := req.GetTaskId()
+	var finished func() (bool, error)
+	if taskID != "" {
+		// A finished task has no progress left to follow
+		finished = func() (bool, error) {
+			record, err := s.coordinator.GetTask(taskID)
+			if err != nil {
+				return false, grpcError(err)
+			}
+			return record.State != swarm.TaskStateQueued && record.State != swarm.TaskStateRunning, nil
+		}
+	}
+	return s.follow(stream.Context(), 0, finished, func(event swarm.TimelineEvent) (bool, error) {
+		if taskID != "" && event.Subject != taskID {
+			return true, nil
+		}
+		if event.Type == swarm.TimelineTaskFinished {
+			return taskID == "", nil
+		}
+		if event.Type != swarm.TimelineTaskProgress {
+			return true, nil
+		}
+		agentID, _ := event.Details["agent"].(string)
+		percent, _ := event.Details["percent"].(float64)
+		stage, _ := event.Details["stage"].(string)
+		return true, stream.Send(&swarmpb.TaskProgress{
+			Seq:     event.Seq,
+			TaskId:  event.Subject,
+			AgentId: agentID,
+			Percent: percent,
+			Stage:   stage,
+			Message: event.Summary,
+			Time:    timestamppb.New(event.Timestamp),
+		})
+	})
+}
+
+func (s *grpcService) WatchAlerts(req *swarmpb.WatchAlertsRequest, stream grpc.ServerStreamingServer[swarmpb.Alert]) error {
+	return s.follow(stream.Context(), 0, nil, func(event swarm.TimelineEvent) (bool, error) {
+		if event.Type != swarm.TimelineAlert {
+			return true, nil
+		}
+		severity := ""
+		if v, ok := event.Details["severity"]; ok && v != nil {
+			severity = fmt.Sprint(v)
+		}
+		return true, stream.Send(&swarmpb.Alert{
+			Seq:       event.Seq,
+			Time:      timestamppb.New(event.Timestamp),
+			Component: event.Subject,
+			Message:   event.Summary,
+			Severity:  severity,
+		})
+	})
+}
+
+// follow hands send the timeline events after afterSeq the timeline keeps
+// and then every new event, until the client is gone, the swarm stopped or
+// send returns false or an error. done, if not nil, is checked once the
+// subscription is set up and ends the stream early when it returns true.
+func (s *grpcService) follow(ctx context.Context, afterSeq int64, done func() (bool, error), send func(swarm.TimelineEvent) (bool, error)) error {
+	// Subscribe before replaying so no event falls between the two
+	events := s.coordinator.Subscribe(ctx)
+	if done != nil {
+		if stop, err := done(); err != nil || stop {
+			return err
+		}
+	}
+
+	last := afterSeq
+	if afterSeq > 0 {
+		for _, event := range s.coordinator.Timeline(swarm.TimelineFilter{}) {
+			if event.Seq <= afterSeq {
+				continue
+			}
+			if more, err := send(event); err != nil || !more {
+				return err
+			}
+			last = event.Seq
+		}
+	}
+	for {
+		var event pubsub.Event[swarm.TimelineEvent]
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok = <-events:
+		}
+		if !ok {
+			return nil
+		}
+		if event.Payload.Seq <= last {
+			continue
+		}
+		if more, err := send(event.Payload); err != nil || !more {
+			return err
+		}
+	}
+}
+
+// detailsStruct converts the details of an event, which hold any JSON
+// value, to a Struct
+func detailsStruct(details map[string]interface{}) (*structpb.Struct, error) {
+	if len(details) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode event details: %v", err)
+	}
+	s := &structpb.Struct{}
+	if err := s.UnmarshalJSON(data); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode event details: %v", err)
+	}
+	return s, nil
+}
+
+// grpcError converts a swarm error to a gRPC status with the code of the
+// HTTP status it is served with by the REST API
+func grpcError(err error) error {
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			if code, ok := grpcCodes[e.status]; ok {
+				return status.Error(code, err.Error())
+			}
+		}
+	}
+	return status.Error(codes.Unavailable, err.Error())
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/swarm/api/swarmpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestGRPC serves the gRPC API of c over an in-memory connection and
+// returns the server and a client of it, both closed when the test ends
+func newTestGRPC(t *testing.T, c *swarm.Coordinator) (*grpc.Server, swarmpb.SwarmClient) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := NewGRPCServer(c)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return server, swarmpb.NewSwarmClient(conn)
+}
+
+func TestGRPCUnaryCalls(t *testing.T) {
+	c := newTestCoordinator(t)
+	_, client := newTestGRPC(t, c)
+	ctx := context.Background()
+
+	if _, err := client.SubmitTask(ctx, &swarmpb.SubmitTaskRequest{Description: "no type"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("submitting a task without type: %v, want %s", err, codes.InvalidArgument)
+	}
+
+	resp, err := client.SubmitTask(ctx, &swarmpb.SubmitTaskRequest{Type: "executor", Description: "list the build directory"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetTask(resp.GetId()); err != nil {
+		t.Fatalf("submitted task %q: %v", resp.GetId(), err)
+	}
+
+	st, err := client.GetStatus(ctx, &swarmpb.GetStatusRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tasks := st.GetQueuedTasks() + st.GetRunningTasks()
+	for _, n := range st.GetFinishedTasks() {
+		tasks += n
+	}
+	if !st.GetRunning() || tasks != 1 {
+		t.Errorf("status = running %v with %d tasks, want running with 1", st.GetRunning(), tasks)
+	}
+}
+
+func TestGRPCWatchEventsCancel(t *testing.T) {
+	c := newTestCoordinator(t)
+	server, client := newTestGRPC(t, c)
+
+	submit := func(description string) {
+		t.Helper()
+		if _, err := client.SubmitTask(context.Background(), &swarmpb.SubmitTaskRequest{Type: "executor", Description: description}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	submit("first")
+	events := c.Timeline(swarm.TimelineFilter{})
+	if len(events) == 0 {
+		t.Fatal("no timeline event for the submitted task")
+	}
+	afterSeq := events[len(events)-1].Seq
+	submit("second")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.WatchEvents(ctx, &swarmpb.WatchEventsRequest{
+		Types:    []string{string(swarm.TimelineTaskSubmitted)},
+		AfterSeq: afterSeq,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.GetSeq() <= afterSeq || event.GetType() != string(swarm.TimelineTaskSubmitted) {
+		t.Errorf("replayed event %d of type %s, want the submission after %d", event.GetSeq(), event.GetType(), afterSeq)
+	}
+
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("receiving after cancel: %v, want %s", err, codes.Canceled)
+	}
+
+	// GracefulStop waits for the handlers, so it returns only once the
+	// stream stopped following the timeline
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream still running after the client canceled it")
+	}
+}
+
+func TestGRPCErrorCodes(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{swarm.ErrTaskNotFound, codes.NotFound},
+		{fmt.Errorf("task t-1: %w", swarm.ErrTaskNotFound), codes.NotFound},
+		{swarm.ErrTaskTypeRequired, codes.InvalidArgument},
+		{swarm.ErrTaskExists, codes.FailedPrecondition},
+		{swarm.ErrInvalidTaskState, codes.FailedPrecondition},
+		{swarm.ErrQueueFull, codes.ResourceExhausted},
+		{swarm.ErrCoordinatorStopped, codes.Unavailable},
+		{errors.New("disk full"), codes.Unavailable},
+	}
+	for _, tt := range tests {
+		err := grpcError(tt.err)
+		if got := status.Code(err); got != tt.want {
+			t.Errorf("grpcError(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+		if got := status.Convert(err).Message(); got != tt.err.Error() {
+			t.Errorf("grpcError(%v) message = %q", tt.err, got)
+		}
+	}
+
+	// Errors of the coordinator reach the client of a stream the same way
+	_, client := newTestGRPC(t, newTestCoordinator(t))
+	stream, err := client.WatchTaskProgress(context.Background(), &swarmpb.WatchTaskProgressRequest{TaskId: "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.NotFound {
+		t.Errorf("progress of a missing task: %v, want %s", err, codes.NotFound)
+	}
+}
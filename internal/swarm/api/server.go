@@ -0,0 +1,545 @@
+// Package api serves a small HTTP/JSON control API for a running swarm so it
+// can be inspected and steered from another process, e.g. `opencode swarm`.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+// TaskInfo is the wire form of a swarm.TaskRecord
+type TaskInfo struct {
+	ID             string                 `json:"id"`
+	Type           string                 `json:"type"`
+	Description    string                 `json:"description"`
+	Priority       int                    `json:"priority"`
+	State          swarm.TaskState        `json:"state"`
+	AgentID        string                 `json:"agentId,omitempty"`
+	VoteID         string                 `json:"voteId,omitempty"`
+	RetryCount     int                    `json:"retryCount,omitempty"`
+	IdempotencyKey string                 `json:"idempotencyKey,omitempty"`
+	Input          map[string]interface{} `json:"input,omitempty"`
+	Output         map[string]interface{} `json:"output,omitempty"`
+	Error          string                 `json:"error,omitempty"`
+	SubmittedAt    time.Time              `json:"submittedAt"`
+	StartedAt      *time.Time             `json:"startedAt,omitempty"`
+	FinishedAt     *time.Time             `json:"finishedAt,omitempty"`
+	// StuckSince is when the watchdog suspected the running task stuck
+	StuckSince *time.Time `json:"stuckSince,omitempty"`
+	// Shadows are the results of the shadow agents given copies of the
+	// task
+	Shadows []swarm.ShadowResult `json:"shadows,omitempty"`
+	// Selection are the scores of the agents the task was dispatched among
+	Selection []agent.SelectionScore `json:"selection,omitempty"`
+}
+
+// MemoryInfo describes a memory of the swarm. Encrypted memories have no
+// content.
+type MemoryInfo struct {
+	ID        string                 `json:"id"`
+	Type      memory.MemoryType      `json:"type"`
+	Namespace string                 `json:"namespace,omitempty"`
+	Priority  memory.MemoryPriority  `json:"priority"`
+	Tags      []string               `json:"tags,omitempty"`
+	Content   interface{}            `json:"content,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Encrypted bool                   `json:"encrypted,omitempty"`
+	Pinned    bool                   `json:"pinned,omitempty"`
+	// Occurrences is how many repeats a compacted memory stands for
+	Occurrences int       `json:"occurrences,omitempty"`
+	LastSeen    time.Time `json:"lastSeen,omitempty"`
+	AccessCount int       `json:"accessCount"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// SubmitRequest is the body of a task submission
+type SubmitRequest struct {
+	Type        string                 `json:"type"`
+	Description string                 `json:"description"`
+	Priority    int                    `json:"priority,omitempty"`
+	MaxRetries  int                    `json:"maxRetries,omitempty"`
+	Input       map[string]interface{} `json:"input,omitempty"`
+	// IdempotencyKey coalesces the submission with a task submitted with
+	// the same key
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+}
+
+// SubmitResponse returns the ID given to a submitted task, or the ID of
+// the task it was coalesced with
+type SubmitResponse struct {
+	ID string `json:"id"`
+}
+
+// BatchRequest is the body of a batch submission. Atomic queues no task
+// unless all of them can be queued.
+type BatchRequest struct {
+	Tasks  []SubmitRequest `json:"tasks"`
+	Atomic bool            `json:"atomic,omitempty"`
+}
+
+// BatchResult is the outcome of one task of a batch, in the order of the
+// request
+type BatchResult struct {
+	ID        string `json:"id"`
+	Coalesced bool   `json:"coalesced,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Code      string `json:"code,omitempty"`
+}
+
+// BatchResponse answers a batch submission. Rejected is set when a task
+// was not queued, an atomic batch then queued none.
+type BatchResponse struct {
+	Results  []BatchResult `json:"results"`
+	Rejected bool          `json:"rejected,omitempty"`
+}
+
+// errorResponse is the body of every failed request. Code names the swarm
+// error so clients can tell errors apart without parsing the message.
+type errorResponse struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+}
+
+// errorCodes are the wire codes of the swarm errors and the status they are
+// served with
+var errorCodes = []struct {
+	err    error
+	code   string
+	status int
+}{
+	{swarm.ErrTaskNotFound, "task_not_found", http.StatusNotFound},
+	{swarm.ErrQueueFull, "queue_full", http.StatusTooManyRequests},
+	{swarm.ErrTaskExists, "task_exists", http.StatusConflict},
+	{swarm.ErrInvalidTaskState, "invalid_task_state", http.StatusConflict},
+	{swarm.ErrCoordinatorStopped, "coordinator_stopped", http.StatusServiceUnavailable},
+	{swarm.ErrTaskTypeRequired, "task_type_required", http.StatusBadRequest},
+	{memory.ErrInvalidQuery, "invalid_query", http.StatusBadRequest},
+	{memory.ErrMemoryNotFound, "memory_not_found", http.StatusNotFound},
+	{memory.ErrUnknownExportFormat, "unknown_export_format", http.StatusBadRequest},
+	{swarm.ErrBatchRejected, "batch_rejected", http.StatusConflict},
+	{errOriginNotAllowed, "origin_not_allowed", http.StatusForbidden},
+	{errHostNotAllowed, "host_not_allowed", http.StatusForbidden},
+	{errNotJSON, "unsupported_media_type", http.StatusUnsupportedMediaType},
+}
+
+// Errors of requests web pages could make on behalf of the user
+var (
+	errOriginNotAllowed = errors.New("origin not allowed")
+	errHostNotAllowed   = errors.New("host not allowed")
+	errNotJSON          = errors.New("request body must be application/json")
+)
+
+// Server exposes a coordinator over HTTP
+type Server struct {
+	coordinator *swarm.Coordinator
+	stop        func()
+	mux         *http.ServeMux
+}
+
+// NewServer creates the API of a coordinator. stop is called when a client
+// asks the swarm to shut down.
+func NewServer(c *swarm.Coordinator, stop func()) *Server {
+	s := &Server{
+		coordinator: c,
+		stop:        stop,
+		mux:         http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /v1/status", s.handleStatus)
+	s.mux.HandleFunc("GET /v1/tasks", s.handleListTasks)
+	s.mux.HandleFunc("POST /v1/tasks", s.handleSubmitTask)
+	s.mux.HandleFunc("POST /v1/tasks/batch", s.handleSubmitBatch)
+	s.mux.HandleFunc("GET /v1/tasks/{id}", s.handleGetTask)
+	s.mux.HandleFunc("POST /v1/tasks/{id}/cancel", s.handleCancelTask)
+	s.mux.HandleFunc("POST /v1/tasks/{id}/retry", s.handleRetryTask)
+	s.mux.HandleFunc("GET /v1/memories", s.handleSearchMemories)
+	s.mux.HandleFunc("GET /v1/memories/export", s.handleExportMemories)
+	s.mux.HandleFunc("POST /v1/memories/{id}/pin", s.handlePinMemory)
+	s.mux.HandleFunc("POST /v1/memories/{id}/unpin", s.handleUnpinMemory)
+	s.mux.HandleFunc("GET /v1/comparisons", s.handleComparisons)
+	s.mux.HandleFunc("GET /v1/topology", s.handleTopology)
+	s.mux.Handle("GET /v1/events", s.eventFeed())
+	s.mux.HandleFunc("POST /v1/stop", s.handleStop)
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := checkRequest(r); err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// checkRequest rejects what other web pages could send from the user's
+// browser: requests from a foreign origin, requests through a host name
+// that is not local, which DNS rebinding could point at the API, and writes
+// a page can post without asking, i.e. anything but JSON
+func checkRequest(r *http.Request) error {
+	if !localHost(r.Host) {
+		return fmt.Errorf("%w: %s", errHostNotAllowed, r.Host)
+	}
+	if _, err := requestOrigin(r); err != nil {
+		return err
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return errNotJSON
+	}
+	return nil
+}
+
+// requestOrigin returns the origin of a request from a browser, nil for
+// other clients. Only pages served by the API's own host are allowed.
+func requestOrigin(r *http.Request) (*url.URL, error) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host != r.Host {
+		return nil, fmt.Errorf("%w: %s", errOriginNotAllowed, origin)
+	}
+	return u, nil
+}
+
+// localHost reports whether a Host header names the API by address or as
+// localhost, which no other site can resolve to it
+func localHost(host string) bool {
+	if host == "" {
+		return true
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	return host == "localhost" || strings.HasSuffix(host, ".localhost") || net.ParseIP(host) != nil
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.coordinator.GetSystemStatus())
+}
+
+func (s *Server) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	state := swarm.TaskState(r.URL.Query().Get("state"))
+	tasks := []TaskInfo{}
+	for _, record := range s.coordinator.ListTasks() {
+		if state != "" && record.State != state {
+			continue
+		}
+		tasks = append(tasks, taskInfo(record))
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// handleTopology serves the graph of the swarm as JSON, or as a Graphviz
+// graph with format=dot
+func (s *Server) handleTopology(w http.ResponseWriter, r *http.Request) {
+	topology := s.coordinator.GetTopology()
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		writeJSON(w, http.StatusOK, topology)
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		_, _ = io.WriteString(w, topology.DOT())
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid format %q", format))
+	}
+}
+
+// DefaultComparisonWindow is how far back comparisons look unless asked
+// otherwise
+const DefaultComparisonWindow = 24 * time.Hour
+
+// handleComparisons compares shadow agents to the agents they shadowed on
+// the tasks finished within since, a duration. With primary and shadow set
+// only that pair is compared, otherwise every pair seen.
+func (s *Server) handleComparisons(w http.ResponseWriter, r *http.Request) {
+	window := DefaultComparisonWindow
+	if v := r.URL.Query().Get("since"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid since %q", v))
+			return
+		}
+		window = d
+	}
+	until := time.Now()
+	since := until.Add(-window)
+
+	primary, shadow := r.URL.Query().Get("primary"), r.URL.Query().Get("shadow")
+	if primary != "" && shadow != "" {
+		writeJSON(w, http.StatusOK, []swarm.Comparison{s.coordinator.CompareAgents(primary, shadow, since, until)})
+		return
+	}
+	comparisons := []swarm.Comparison{}
+	for _, comparison := range s.coordinator.Comparisons(since, until) {
+		if (primary == "" || comparison.Primary.AgentID == primary) && (shadow == "" || comparison.Shadow.AgentID == shadow) {
+			comparisons = append(comparisons, comparison)
+		}
+	}
+	writeJSON(w, http.StatusOK, comparisons)
+}
+
+// handleSearchMemories answers the query in q, see memory.ParseQuery
+func (s *Server) handleSearchMemories(w http.ResponseWriter, r *http.Request) {
+	query, err := memory.ParseQuery(r.URL.Query().Get("q"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	memories, err := s.coordinator.GetMemoryStore().Query(r.Context(), query)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	infos := make([]MemoryInfo, 0, len(memories))
+	for _, m := range memories {
+		info := MemoryInfo{
+			ID:          m.ID,
+			Type:        m.Type,
+			Namespace:   m.Namespace,
+			Priority:    m.Priority,
+			Tags:        m.Tags,
+			Metadata:    m.Metadata,
+			Encrypted:   m.Encrypted,
+			Pinned:      m.Pinned,
+			Occurrences: m.Occurrences,
+			LastSeen:    m.LastSeen,
+			AccessCount: m.AccessCount,
+			CreatedAt:   m.CreatedAt,
+		}
+		if !m.Encrypted {
+			info.Content = m.Content
+		}
+		infos = append(infos, info)
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// exportContentTypes are the content types of the export formats
+var exportContentTypes = map[memory.ExportFormat]string{
+	memory.ExportCSV:     "text/csv",
+	memory.ExportParquet: "application/vnd.apache.parquet",
+}
+
+// handleExportMemories streams a replica of the memory store in the format
+// given by format, CSV by default
+func (s *Server) handleExportMemories(w http.ResponseWriter, r *http.Request) {
+	format := memory.ExportFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = memory.ExportCSV
+	}
+	if !slices.Contains(memory.ExportFormats, format) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: %q", memory.ErrUnknownExportFormat, format))
+		return
+	}
+	replica, err := s.coordinator.SnapshotMemory(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="memories.%s"`, format))
+	// The status is sent, an error can only cut the export short
+	_ = replica.Export(r.Context(), w, format)
+}
+
+func (s *Server) handlePinMemory(w http.ResponseWriter, r *http.Request) {
+	s.pinMemory(w, r, true)
+}
+
+func (s *Server) handleUnpinMemory(w http.ResponseWriter, r *http.Request) {
+	s.pinMemory(w, r, false)
+}
+
+// pinMemory pins or unpins the memory in the path
+func (s *Server) pinMemory(w http.ResponseWriter, r *http.Request, pinned bool) {
+	if err := s.coordinator.GetMemoryStore().Pin(r.Context(), r.PathValue("id"), pinned); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSubmitTask(w http.ResponseWriter, r *http.Request) {
+	var req SubmitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid task: %w", err))
+		return
+	}
+	if req.Type == "" {
+		writeError(w, http.StatusBadRequest, errors.New("task type is required"))
+		return
+	}
+
+	task := agent.Task{
+		ID:             uuid.New().String(),
+		Type:           req.Type,
+		Description:    req.Description,
+		Priority:       req.Priority,
+		MaxRetries:     req.MaxRetries,
+		Input:          req.Input,
+		IdempotencyKey: req.IdempotencyKey,
+	}
+	if err := s.coordinator.SubmitTask(r.Context(), task); err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	id := task.ID
+	if record, err := s.coordinator.GetTask(task.ID); err == nil {
+		id = record.Task.ID
+	}
+	writeJSON(w, http.StatusAccepted, SubmitResponse{ID: id})
+}
+
+func (s *Server) handleSubmitBatch(w http.ResponseWriter, r *http.Request) {
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid batch: %w", err))
+		return
+	}
+
+	tasks := make([]agent.Task, len(req.Tasks))
+	for i, t := range req.Tasks {
+		tasks[i] = agent.Task{
+			ID:             uuid.New().String(),
+			Type:           t.Type,
+			Description:    t.Description,
+			Priority:       t.Priority,
+			MaxRetries:     t.MaxRetries,
+			Input:          t.Input,
+			IdempotencyKey: t.IdempotencyKey,
+		}
+	}
+	results, err := s.coordinator.SubmitTasks(r.Context(), tasks, swarm.BatchOptions{Atomic: req.Atomic})
+	if err != nil && !errors.Is(err, swarm.ErrBatchRejected) {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	resp := BatchResponse{Results: make([]BatchResult, len(results)), Rejected: err != nil}
+	for i, result := range results {
+		resp.Results[i] = BatchResult{ID: result.ID, Coalesced: result.Coalesced}
+		if result.Err != nil {
+			resp.Results[i].Error = result.Err.Error()
+			resp.Results[i].Code = errorCode(result.Err)
+		}
+	}
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	record, err := s.coordinator.GetTask(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, taskInfo(record))
+}
+
+func (s *Server) handleCancelTask(w http.ResponseWriter, r *http.Request) {
+	s.taskAction(w, r, s.coordinator.CancelTask)
+}
+
+func (s *Server) handleRetryTask(w http.ResponseWriter, r *http.Request) {
+	s.taskAction(w, r, s.coordinator.RetryTask)
+}
+
+// taskAction applies an action to the task in the path and returns its new
+// state
+func (s *Server) taskAction(w http.ResponseWriter, r *http.Request, action func(string) error) {
+	if err := action(r.PathValue("id")); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.handleGetTask(w, r)
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusAccepted)
+	if s.stop != nil {
+		// Stop after the response is written so the client sees it
+		go s.stop()
+	}
+}
+
+func taskInfo(record swarm.TaskRecord) TaskInfo {
+	info := TaskInfo{
+		ID:             record.Task.ID,
+		Type:           record.Task.Type,
+		Description:    record.Task.Description,
+		Priority:       record.Task.Priority,
+		State:          record.State,
+		AgentID:        record.AgentID,
+		VoteID:         record.VoteID,
+		RetryCount:     record.Task.RetryCount,
+		IdempotencyKey: record.Task.IdempotencyKey,
+		Input:          record.Task.Input,
+		Error:          record.Error,
+		SubmittedAt:    record.SubmittedAt,
+		Selection:      record.Selection,
+		Shadows:        record.Shadows,
+	}
+	if record.Result != nil {
+		info.Output = record.Result.Output
+	}
+	if !record.StartedAt.IsZero() {
+		info.StartedAt = &record.StartedAt
+	}
+	if !record.FinishedAt.IsZero() {
+		info.FinishedAt = &record.FinishedAt
+	}
+	if !record.StuckSince.IsZero() && record.State == swarm.TaskStateRunning {
+		info.StuckSince = &record.StuckSince
+	}
+	return info
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err with its code. Swarm errors are served with their
+// own status, other errors with the given one.
+func writeError(w http.ResponseWriter, status int, err error) {
+	resp := errorResponse{Error: err.Error()}
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			resp.Code = e.code
+			status = e.status
+			break
+		}
+	}
+	writeJSON(w, status, resp)
+}
+
+// errorCode returns the wire code of a swarm error, empty for other errors
+func errorCode(err error) string {
+	for _, e := range errorCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/swarm"
+)
+
+// newTestCoordinator starts a coordinator without agents, stopped when the
+// test ends
+func newTestCoordinator(t *testing.T) *swarm.Coordinator {
+	t.Helper()
+	c, err := swarm.NewCoordinator(swarm.CoordinatorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+	return c
+}
+
+func TestServerRejectsBrowserRequests(t *testing.T) {
+	server := NewServer(newTestCoordinator(t), nil)
+	submit := `{"type": "executor", "description": "list the build directory"}`
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		host        string
+		origin      string
+		contentType string
+		body        string
+		wantStatus  int
+		wantCode    string
+	}{
+		{
+			name:       "cli status",
+			method:     http.MethodGet,
+			path:       "/v1/status",
+			host:       "127.0.0.1:7420",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "cli submission",
+			method:      http.MethodPost,
+			path:        "/v1/tasks",
+			host:        "localhost:7420",
+			contentType: "application/json",
+			body:        submit,
+			wantStatus:  http.StatusAccepted,
+		},
+		{
+			name:        "page of the api",
+			method:      http.MethodPost,
+			path:        "/v1/tasks/missing/cancel",
+			host:        "[::1]:7420",
+			origin:      "http://[::1]:7420",
+			contentType: "application/json; charset=utf-8",
+			wantStatus:  http.StatusNotFound,
+			wantCode:    "task_not_found",
+		},
+		{
+			name:        "foreign page",
+			method:      http.MethodPost,
+			path:        "/v1/tasks",
+			host:        "127.0.0.1:7420",
+			origin:      "https://evil.example",
+			contentType: "application/json",
+			body:        submit,
+			wantStatus:  http.StatusForbidden,
+			wantCode:    "origin_not_allowed",
+		},
+		{
+			name:        "simple form post",
+			method:      http.MethodPost,
+			path:        "/v1/tasks",
+			host:        "127.0.0.1:7420",
+			contentType: "text/plain",
+			body:        submit,
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantCode:    "unsupported_media_type",
+		},
+		{
+			name:       "stop without content type",
+			method:     http.MethodPost,
+			path:       "/v1/stop",
+			host:       "127.0.0.1:7420",
+			wantStatus: http.StatusUnsupportedMediaType,
+			wantCode:   "unsupported_media_type",
+		},
+		{
+			name:       "dns rebinding",
+			method:     http.MethodGet,
+			path:       "/v1/status",
+			host:       "rebind.evil.example:7420",
+			origin:     "http://rebind.evil.example:7420",
+			wantStatus: http.StatusForbidden,
+			wantCode:   "host_not_allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Host = tt.host
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var resp errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", resp.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,5 @@
+// Package swarmpb holds the gRPC streaming API of the swarm, generated from
+// swarm.proto. The service is implemented by api.NewGRPCServer.
+package swarmpb
+
+//go:generate protoc -I ../../../.. --go_out=../../../.. --go_opt=paths=source_relative --go-grpc_out=../../../.. --go-grpc_opt=paths=source_relative internal/swarm/api/swarmpb/swarm.proto
@@ -0,0 +1,844 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: internal/swarm/api/swarmpb/swarm.proto
+
+package swarmpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_internal_swarm_api_swarmpb_swarm_proto_rawDescGZIP(), []int{0}
+}
+
+type Status struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Running      bool                   `protobuf:"varint,1,opt,name=running,proto3" json:"running,omitempty"`
+	Health       string                 `protobuf:"bytes,2,opt,name=health,proto3" json:"health,omitempty"`
+	HealthScore  float64                `protobuf:"fixed64,3,opt,name=health_score,json=healthScore,proto3" json:"health_score,omitempty"`
+	Agents       int32                  `protobuf:"varint,4,opt,name=agents,proto3" json:"agents,omitempty"`
+	QueuedTasks  int32                  `protobuf:"varint,5,opt,name=queued_tasks,json=queuedTasks,proto3" json:"queued_tasks,omitempty"`
+	RunningTasks int32                  `protobuf:"varint,6,opt,name=running_tasks,json=runningTasks,proto3" json:"running_tasks,omitempty"`
+	// finished_tasks counts the finished tasks by final state
+	FinishedTasks map[string]int32 `protobuf:"bytes,7,rep,name=finished_tasks,json=finishedTasks,proto3" json:"finished_tasks,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	ActiveVotes   int32            `protobuf:"varint,8,opt,name=active_votes,json=activeVotes,proto3" json:"active_votes,omitempty"`
+	Alerts        int32            `protobuf:"varint,9,opt,name=alerts,proto3" json:"alerts,omitempty"`
+	// last_event is the seq of the last timeline event
+	LastEvent     int64 `protobuf:"varint,10,opt,name=last_event,json=lastEvent,proto3" json:"last_event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_internal_swarm_api_swarmpb_swarm_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Status) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *Status) GetHealth() string {
+	if x != nil {
+		return x.Health
+	}
+	return ""
+}
+
+func (x *Status) GetHealthScore() float64 {
+	if x != nil {
+		return x.HealthScore
+	}
+	return 0
+}
+
+func (x *Status) GetAgents() int32 {
+	if x != nil {
+		return x.Agents
+	}
+	return 0
+}
+
+func (x *Status) GetQueuedTasks() int32 {
+	if x != nil {
+		return x.QueuedTasks
+	}
+	return 0
+}
+
+func (x *Status) GetRunningTasks() int32 {
+	if x != nil {
+		return x.RunningTasks
+	}
+	return 0
+}
+
+func (x *Status) GetFinishedTasks() map[string]int32 {
+	if x != nil {
+		return x.FinishedTasks
+	}
+	return nil
+}
+
+func (x *Status) GetActiveVotes() int32 {
+	if x != nil {
+		return x.ActiveVotes
+	}
+	return 0
+}
+
+func (x *Status) GetAlerts() int32 {
+	if x != nil {
+		return x.Alerts
+	}
+	return 0
+}
+
+func (x *Status) GetLastEvent() int64 {
+	if x != nil {
+		return x.LastEvent
+	}
+	return 0
+}
+
+type SubmitTaskRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Type        string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Priority    int32                  `protobuf:"varint,3,opt,name=priority,proto3" json:"priority,omitempty"`
+	MaxRetries  int32                  `protobuf:"varint,4,opt,name=max_retries,json=maxRetries,proto3" json:"max_retries,omitempty"`
+	Input       *structpb.Struct       `protobuf:"bytes,5,opt,name=input,proto3" json:"input,omitempty"`
+	// idempotency_key coalesces the task with a task submitted with the
+	// same key
+	IdempotencyKey string `protobuf:"bytes,6,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SubmitTaskRequest) Reset() {
+	*x = SubmitTaskRequest{}
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitTaskRequest) ProtoMessage() {}
+
+func (x *SubmitTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitTaskRequest.ProtoReflect.Descriptor instead.
+func (*SubmitTaskRequest) Descriptor() ([]byte, []int) {
+	return file_internal_swarm_api_swarmpb_swarm_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SubmitTaskRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *SubmitTaskRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *SubmitTaskRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *SubmitTaskRequest) GetMaxRetries() int32 {
+	if x != nil {
+		return x.MaxRetries
+	}
+	return 0
+}
+
+func (x *SubmitTaskRequest) GetInput() *structpb.Struct {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+func (x *SubmitTaskRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type SubmitTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitTaskResponse) Reset() {
+	*x = SubmitTaskResponse{}
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitTaskResponse) ProtoMessage() {}
+
+func (x *SubmitTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitTaskResponse.ProtoReflect.Descriptor instead.
+func (*SubmitTaskResponse) Descriptor() ([]byte, []int) {
+	return file_internal_swarm_api_swarmpb_swarm_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SubmitTaskResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type WatchEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// types selects the event types, all if empty
+	Types []string `protobuf:"bytes,1,rep,name=types,proto3" json:"types,omitempty"`
+	// after_seq first replays the events after it the timeline still keeps
+	AfterSeq      int64 `protobuf:"varint,2,opt,name=after_seq,json=afterSeq,proto3" json:"after_seq,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_swarm_api_swarmpb_swarm_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WatchEventsRequest) GetTypes() []string {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *WatchEventsRequest) GetAfterSeq() int64 {
+	if x != nil {
+		return x.AfterSeq
+	}
+	return 0
+}
+
+// Event is an entry of the swarm timeline
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// seq increases by one for every event
+	Seq  int64                  `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Type string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Time *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=time,proto3" json:"time,omitempty"`
+	// subject is the task, vote session or component the event is about
+	Subject       string           `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
+	Summary       string           `protobuf:"bytes,5,opt,name=summary,proto3" json:"summary,omitempty"`
+	Details       *structpb.Struct `protobuf:"bytes,6,opt,name=details,proto3" json:"details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_internal_swarm_api_swarmpb_swarm_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Event) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Event) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *Event) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *Event) GetDetails() *structpb.Struct {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+type WatchTaskProgressRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// task_id selects a task, whose stream ends when it finished; all tasks
+	// if empty
+	TaskId        string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTaskProgressRequest) Reset() {
+	*x = WatchTaskProgressRequest{}
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTaskProgressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTaskProgressRequest) ProtoMessage() {}
+
+func (x *WatchTaskProgressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTaskProgressRequest.ProtoReflect.Descriptor instead.
+func (*WatchTaskProgressRequest) Descriptor() ([]byte, []int) {
+	return file_internal_swarm_api_swarmpb_swarm_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WatchTaskProgressRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type TaskProgress struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Seq     int64                  `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	TaskId  string                 `protobuf:"bytes,2,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	AgentId string                 `protobuf:"bytes,3,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	// percent is how much of the task is done, 0 if the agent did not say
+	Percent       float64                `protobuf:"fixed64,4,opt,name=percent,proto3" json:"percent,omitempty"`
+	Stage         string                 `protobuf:"bytes,5,opt,name=stage,proto3" json:"stage,omitempty"`
+	Message       string                 `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TaskProgress) Reset() {
+	*x = TaskProgress{}
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TaskProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskProgress) ProtoMessage() {}
+
+func (x *TaskProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskProgress.ProtoReflect.Descriptor instead.
+func (*TaskProgress) Descriptor() ([]byte, []int) {
+	return file_internal_swarm_api_swarmpb_swarm_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TaskProgress) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *TaskProgress) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *TaskProgress) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *TaskProgress) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *TaskProgress) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *TaskProgress) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *TaskProgress) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+type WatchAlertsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAlertsRequest) Reset() {
+	*x = WatchAlertsRequest{}
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchAlertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchAlertsRequest) ProtoMessage() {}
+
+func (x *WatchAlertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchAlertsRequest.ProtoReflect.Descriptor instead.
+func (*WatchAlertsRequest) Descriptor() ([]byte, []int) {
+	return file_internal_swarm_api_swarmpb_swarm_proto_rawDescGZIP(), []int{8}
+}
+
+type Alert struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Seq   int64                  `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	// component is the component, agent or vote session the alert is about
+	Component     string `protobuf:"bytes,3,opt,name=component,proto3" json:"component,omitempty"`
+	Message       string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	Severity      string `protobuf:"bytes,5,opt,name=severity,proto3" json:"severity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Alert) Reset() {
+	*x = Alert{}
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Alert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alert) ProtoMessage() {}
+
+func (x *Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_swarm_api_swarmpb_swarm_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Alert.ProtoReflect.Descriptor instead.
+func (*Alert) Descriptor() ([]byte, []int) {
+	return file_internal_swarm_api_swarmpb_swarm_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Alert) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Alert) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Alert) GetComponent() string {
+	if x != nil {
+		return x.Component
+	}
+	return ""
+}
+
+func (x *Alert) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Alert) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+var File_internal_swarm_api_swarmpb_swarm_proto protoreflect.FileDescriptor
+
+const file_internal_swarm_api_swarmpb_swarm_proto_rawDesc = "" +
+	"\n" +
+	"&internal/swarm/api/swarmpb/swarm.proto\x12\x11opencode.swarm.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x12\n" +
+	"\x10GetStatusRequest\"\xae\x03\n" +
+	"\x06Status\x12\x18\n" +
+	"\arunning\x18\x01 \x01(\bR\arunning\x12\x16\n" +
+	"\x06health\x18\x02 \x01(\tR\x06health\x12!\n" +
+	"\fhealth_score\x18\x03 \x01(\x01R\vhealthScore\x12\x16\n" +
+	"\x06agents\x18\x04 \x01(\x05R\x06agents\x12!\n" +
+	"\fqueued_tasks\x18\x05 \x01(\x05R\vqueuedTasks\x12#\n" +
+	"\rrunning_tasks\x18\x06 \x01(\x05R\frunningTasks\x12S\n" +
+	"\x0efinished_tasks\x18\a \x03(\v2,.opencode.swarm.v1.Status.FinishedTasksEntryR\rfinishedTasks\x12!\n" +
+	"\factive_votes\x18\b \x01(\x05R\vactiveVotes\x12\x16\n" +
+	"\x06alerts\x18\t \x01(\x05R\x06alerts\x12\x1d\n" +
+	"\n" +
+	"last_event\x18\n" +
+	" \x01(\x03R\tlastEvent\x1a@\n" +
+	"\x12FinishedTasksEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\xde\x01\n" +
+	"\x11SubmitTaskRequest\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bpriority\x18\x03 \x01(\x05R\bpriority\x12\x1f\n" +
+	"\vmax_retries\x18\x04 \x01(\x05R\n" +
+	"maxRetries\x12-\n" +
+	"\x05input\x18\x05 \x01(\v2\x17.google.protobuf.StructR\x05input\x12'\n" +
+	"\x0fidempotency_key\x18\x06 \x01(\tR\x0eidempotencyKey\"$\n" +
+	"\x12SubmitTaskResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"G\n" +
+	"\x12WatchEventsRequest\x12\x14\n" +
+	"\x05types\x18\x01 \x03(\tR\x05types\x12\x1b\n" +
+	"\tafter_seq\x18\x02 \x01(\x03R\bafterSeq\"\xc4\x01\n" +
+	"\x05Event\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x03R\x03seq\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12.\n" +
+	"\x04time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x18\n" +
+	"\asubject\x18\x04 \x01(\tR\asubject\x12\x18\n" +
+	"\asummary\x18\x05 \x01(\tR\asummary\x121\n" +
+	"\adetails\x18\x06 \x01(\v2\x17.google.protobuf.StructR\adetails\"3\n" +
+	"\x18WatchTaskProgressRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\xce\x01\n" +
+	"\fTaskProgress\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x03R\x03seq\x12\x17\n" +
+	"\atask_id\x18\x02 \x01(\tR\x06taskId\x12\x19\n" +
+	"\bagent_id\x18\x03 \x01(\tR\aagentId\x12\x18\n" +
+	"\apercent\x18\x04 \x01(\x01R\apercent\x12\x14\n" +
+	"\x05stage\x18\x05 \x01(\tR\x05stage\x12\x18\n" +
+	"\amessage\x18\x06 \x01(\tR\amessage\x12.\n" +
+	"\x04time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"\x14\n" +
+	"\x12WatchAlertsRequest\"\x9d\x01\n" +
+	"\x05Alert\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x03R\x03seq\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x1c\n" +
+	"\tcomponent\x18\x03 \x01(\tR\tcomponent\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\x12\x1a\n" +
+	"\bseverity\x18\x05 \x01(\tR\bseverity2\xb8\x03\n" +
+	"\x05Swarm\x12K\n" +
+	"\tGetStatus\x12#.opencode.swarm.v1.GetStatusRequest\x1a\x19.opencode.swarm.v1.Status\x12Y\n" +
+	"\n" +
+	"SubmitTask\x12$.opencode.swarm.v1.SubmitTaskRequest\x1a%.opencode.swarm.v1.SubmitTaskResponse\x12P\n" +
+	"\vWatchEvents\x12%.opencode.swarm.v1.WatchEventsRequest\x1a\x18.opencode.swarm.v1.Event0\x01\x12c\n" +
+	"\x11WatchTaskProgress\x12+.opencode.swarm.v1.WatchTaskProgressRequest\x1a\x1f.opencode.swarm.v1.TaskProgress0\x01\x12P\n" +
+	"\vWatchAlerts\x12%.opencode.swarm.v1.WatchAlertsRequest\x1a\x18.opencode.swarm.v1.Alert0\x01B<Z:github.com/opencode-ai/opencode/internal/swarm/api/swarmpbb\x06proto3"
+
+var (
+	file_internal_swarm_api_swarmpb_swarm_proto_rawDescOnce sync.Once
+	file_internal_swarm_api_swarmpb_swarm_proto_rawDescData []byte
+)
+
+func file_internal_swarm_api_swarmpb_swarm_proto_rawDescGZIP() []byte {
+	file_internal_swarm_api_swarmpb_swarm_proto_rawDescOnce.Do(func() {
+		file_internal_swarm_api_swarmpb_swarm_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_internal_swarm_api_swarmpb_swarm_proto_rawDesc), len(file_internal_swarm_api_swarmpb_swarm_proto_rawDesc)))
+	})
+	return file_internal_swarm_api_swarmpb_swarm_proto_rawDescData
+}
+
+var file_internal_swarm_api_swarmpb_swarm_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_internal_swarm_api_swarmpb_swarm_proto_goTypes = []any{
+	(*GetStatusRequest)(nil),         // 0: opencode.swarm.v1.GetStatusRequest
+	(*Status)(nil),                   // 1: opencode.swarm.v1.Status
+	(*SubmitTaskRequest)(nil),        // 2: opencode.swarm.v1.SubmitTaskRequest
+	(*SubmitTaskResponse)(nil),       // 3: opencode.swarm.v1.SubmitTaskResponse
+	(*WatchEventsRequest)(nil),       // 4: opencode.swarm.v1.WatchEventsRequest
+	(*Event)(nil),                    // 5: opencode.swarm.v1.Event
+	(*WatchTaskProgressRequest)(nil), // 6: opencode.swarm.v1.WatchTaskProgressRequest
+	(*TaskProgress)(nil),             // 7: opencode.swarm.v1.TaskProgress
+	(*WatchAlertsRequest)(nil),       // 8: opencode.swarm.v1.WatchAlertsRequest
+	(*Alert)(nil),                    // 9: opencode.swarm.v1.Alert
+	nil,                              // 10: opencode.swarm.v1.Status.FinishedTasksEntry
+	(*structpb.Struct)(nil),          // 11: google.protobuf.Struct
+	(*timestamppb.Timestamp)(nil),    // 12: google.protobuf.Timestamp
+}
+var file_internal_swarm_api_swarmpb_swarm_proto_depIdxs = []int32{
+	10, // 0: opencode.swarm.v1.Status.finished_tasks:type_name -> opencode.swarm.v1.Status.FinishedTasksEntry
+	11, // 1: opencode.swarm.v1.SubmitTaskRequest.input:type_name -> google.protobuf.Struct
+	12, // 2: opencode.swarm.v1.Event.time:type_name -> google.protobuf.Timestamp
+	11, // 3: opencode.swarm.v1.Event.details:type_name -> google.protobuf.Struct
+	12, // 4: opencode.swarm.v1.TaskProgress.time:type_name -> google.protobuf.Timestamp
+	12, // 5: opencode.swarm.v1.Alert.time:type_name -> google.protobuf.Timestamp
+	0,  // 6: opencode.swarm.v1.Swarm.GetStatus:input_type -> opencode.swarm.v1.GetStatusRequest
+	2,  // 7: opencode.swarm.v1.Swarm.SubmitTask:input_type -> opencode.swarm.v1.SubmitTaskRequest
+	4,  // 8: opencode.swarm.v1.Swarm.WatchEvents:input_type -> opencode.swarm.v1.WatchEventsRequest
+	6,  // 9: opencode.swarm.v1.Swarm.WatchTaskProgress:input_type -> opencode.swarm.v1.WatchTaskProgressRequest
+	8,  // 10: opencode.swarm.v1.Swarm.WatchAlerts:input_type -> opencode.swarm.v1.WatchAlertsRequest
+	1,  // 11: opencode.swarm.v1.Swarm.GetStatus:output_type -> opencode.swarm.v1.Status
+	3,  // 12: opencode.swarm.v1.Swarm.SubmitTask:output_type -> opencode.swarm.v1.SubmitTaskResponse
+	5,  // 13: opencode.swarm.v1.Swarm.WatchEvents:output_type -> opencode.swarm.v1.Event
+	7,  // 14: opencode.swarm.v1.Swarm.WatchTaskProgress:output_type -> opencode.swarm.v1.TaskProgress
+	9,  // 15: opencode.swarm.v1.Swarm.WatchAlerts:output_type -> opencode.swarm.v1.Alert
+	11, // [11:16] is the sub-list for method output_type
+	6,  // [6:11] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_internal_swarm_api_swarmpb_swarm_proto_init() }
+func file_internal_swarm_api_swarmpb_swarm_proto_init() {
+	if File_internal_swarm_api_swarmpb_swarm_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_internal_swarm_api_swarmpb_swarm_proto_rawDesc), len(file_internal_swarm_api_swarmpb_swarm_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_internal_swarm_api_swarmpb_swarm_proto_goTypes,
+		DependencyIndexes: file_internal_swarm_api_swarmpb_swarm_proto_depIdxs,
+		MessageInfos:      file_internal_swarm_api_swarmpb_swarm_proto_msgTypes,
+	}.Build()
+	File_internal_swarm_api_swarmpb_swarm_proto = out.File
+	file_internal_swarm_api_swarmpb_swarm_proto_goTypes = nil
+	file_internal_swarm_api_swarmpb_swarm_proto_depIdxs = nil
+}
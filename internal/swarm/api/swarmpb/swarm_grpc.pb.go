@@ -0,0 +1,303 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: internal/swarm/api/swarmpb/swarm.proto
+
+package swarmpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Swarm_GetStatus_FullMethodName         = "/opencode.swarm.v1.Swarm/GetStatus"
+	Swarm_SubmitTask_FullMethodName        = "/opencode.swarm.v1.Swarm/SubmitTask"
+	Swarm_WatchEvents_FullMethodName       = "/opencode.swarm.v1.Swarm/WatchEvents"
+	Swarm_WatchTaskProgress_FullMethodName = "/opencode.swarm.v1.Swarm/WatchTaskProgress"
+	Swarm_WatchAlerts_FullMethodName       = "/opencode.swarm.v1.Swarm/WatchAlerts"
+)
+
+// SwarmClient is the client API for Swarm service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Swarm serves a running swarm to external dashboards and CI systems. The
+// streams follow the swarm timeline; a subscriber too slow for the events
+// misses some, which a new WatchEvents call from the last seq replays.
+type SwarmClient interface {
+	// GetStatus returns the status of the swarm
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error)
+	// SubmitTask queues a task and returns its ID, or the ID of the task it
+	// was coalesced with
+	SubmitTask(ctx context.Context, in *SubmitTaskRequest, opts ...grpc.CallOption) (*SubmitTaskResponse, error)
+	// WatchEvents streams the timeline events as they are recorded
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	// WatchTaskProgress streams the progress agents report on tasks
+	WatchTaskProgress(ctx context.Context, in *WatchTaskProgressRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskProgress], error)
+	// WatchAlerts streams the alerts raised by health checks and rules
+	WatchAlerts(ctx context.Context, in *WatchAlertsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Alert], error)
+}
+
+type swarmClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSwarmClient(cc grpc.ClientConnInterface) SwarmClient {
+	return &swarmClient{cc}
+}
+
+func (c *swarmClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Status)
+	err := c.cc.Invoke(ctx, Swarm_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swarmClient) SubmitTask(ctx context.Context, in *SubmitTaskRequest, opts ...grpc.CallOption) (*SubmitTaskResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitTaskResponse)
+	err := c.cc.Invoke(ctx, Swarm_SubmitTask_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swarmClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Swarm_ServiceDesc.Streams[0], Swarm_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Swarm_WatchEventsClient = grpc.ServerStreamingClient[Event]
+
+func (c *swarmClient) WatchTaskProgress(ctx context.Context, in *WatchTaskProgressRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[TaskProgress], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Swarm_ServiceDesc.Streams[1], Swarm_WatchTaskProgress_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchTaskProgressRequest, TaskProgress]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Swarm_WatchTaskProgressClient = grpc.ServerStreamingClient[TaskProgress]
+
+func (c *swarmClient) WatchAlerts(ctx context.Context, in *WatchAlertsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Alert], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Swarm_ServiceDesc.Streams[2], Swarm_WatchAlerts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchAlertsRequest, Alert]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Swarm_WatchAlertsClient = grpc.ServerStreamingClient[Alert]
+
+// SwarmServer is the server API for Swarm service.
+// All implementations must embed UnimplementedSwarmServer
+// for forward compatibility.
+//
+// Swarm serves a running swarm to external dashboards and CI systems. The
+// streams follow the swarm timeline; a subscriber too slow for the events
+// misses some, which a new WatchEvents call from the last seq replays.
+type SwarmServer interface {
+	// GetStatus returns the status of the swarm
+	GetStatus(context.Context, *GetStatusRequest) (*Status, error)
+	// SubmitTask queues a task and returns its ID, or the ID of the task it
+	// was coalesced with
+	SubmitTask(context.Context, *SubmitTaskRequest) (*SubmitTaskResponse, error)
+	// WatchEvents streams the timeline events as they are recorded
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error
+	// WatchTaskProgress streams the progress agents report on tasks
+	WatchTaskProgress(*WatchTaskProgressRequest, grpc.ServerStreamingServer[TaskProgress]) error
+	// WatchAlerts streams the alerts raised by health checks and rules
+	WatchAlerts(*WatchAlertsRequest, grpc.ServerStreamingServer[Alert]) error
+	mustEmbedUnimplementedSwarmServer()
+}
+
+// UnimplementedSwarmServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSwarmServer struct{}
+
+func (UnimplementedSwarmServer) GetStatus(context.Context, *GetStatusRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedSwarmServer) SubmitTask(context.Context, *SubmitTaskRequest) (*SubmitTaskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitTask not implemented")
+}
+func (UnimplementedSwarmServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedSwarmServer) WatchTaskProgress(*WatchTaskProgressRequest, grpc.ServerStreamingServer[TaskProgress]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTaskProgress not implemented")
+}
+func (UnimplementedSwarmServer) WatchAlerts(*WatchAlertsRequest, grpc.ServerStreamingServer[Alert]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAlerts not implemented")
+}
+func (UnimplementedSwarmServer) mustEmbedUnimplementedSwarmServer() {}
+func (UnimplementedSwarmServer) testEmbeddedByValue()               {}
+
+// UnsafeSwarmServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SwarmServer will
+// result in compilation errors.
+type UnsafeSwarmServer interface {
+	mustEmbedUnimplementedSwarmServer()
+}
+
+func RegisterSwarmServer(s grpc.ServiceRegistrar, srv SwarmServer) {
+	// If the following call pancis, it indicates UnimplementedSwarmServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Swarm_ServiceDesc, srv)
+}
+
+func _Swarm_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwarmServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Swarm_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwarmServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Swarm_SubmitTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwarmServer).SubmitTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Swarm_SubmitTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwarmServer).SubmitTask(ctx, req.(*SubmitTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Swarm_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SwarmServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Swarm_WatchEventsServer = grpc.ServerStreamingServer[Event]
+
+func _Swarm_WatchTaskProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTaskProgressRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SwarmServer).WatchTaskProgress(m, &grpc.GenericServerStream[WatchTaskProgressRequest, TaskProgress]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Swarm_WatchTaskProgressServer = grpc.ServerStreamingServer[TaskProgress]
+
+func _Swarm_WatchAlerts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchAlertsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SwarmServer).WatchAlerts(m, &grpc.GenericServerStream[WatchAlertsRequest, Alert]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Swarm_WatchAlertsServer = grpc.ServerStreamingServer[Alert]
+
+// Swarm_ServiceDesc is the grpc.ServiceDesc for Swarm service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Swarm_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "opencode.swarm.v1.Swarm",
+	HandlerType: (*SwarmServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _Swarm_GetStatus_Handler,
+		},
+		{
+			MethodName: "SubmitTask",
+			Handler:    _Swarm_SubmitTask_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _Swarm_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchTaskProgress",
+			Handler:       _Swarm_WatchTaskProgress_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchAlerts",
+			Handler:       _Swarm_WatchAlerts_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "internal/swarm/api/swarmpb/swarm.proto",
+}
@@ -0,0 +1,346 @@
+// Package artifact keeps the files tasks produce, like diffs, test reports
+// and logs, on disk by the hash of their content, so memories and task
+// results can refer to them by ID.
+package artifact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+	"github.com/opencode-ai/opencode/internal/swarm/vault"
+)
+
+// Kinds of artifacts
+const (
+	KindDiff   = "diff"
+	KindReport = "report"
+	KindLog    = "log"
+	KindFile   = "file"
+)
+
+const (
+	// DefaultRetention is how long artifacts are kept after a task last
+	// attached them
+	DefaultRetention = 7 * 24 * time.Hour
+	// DefaultMaxSize bounds the size of an artifact, in bytes
+	DefaultMaxSize = 64 << 20
+)
+
+var (
+	// ErrNotFound means no artifact with the ID is stored. It may have
+	// been pruned.
+	ErrNotFound = errors.New("artifact not found")
+	// ErrTooLarge is returned for artifacts larger than the maximum size
+	ErrTooLarge = errors.New("artifact too large")
+)
+
+var validID = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// Artifact describes a stored artifact. Artifacts with the same content are
+// stored once, with the tasks that attached them.
+type Artifact struct {
+	// ID is the SHA-256 of the content, in hex
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Kind    string   `json:"kind"`
+	Size    int64    `json:"size"`
+	TaskIDs []string `json:"task_ids,omitempty"`
+	// CreatedAt is when the content was first stored and UsedAt when a
+	// task last attached it
+	CreatedAt time.Time `json:"created_at"`
+	UsedAt    time.Time `json:"used_at"`
+}
+
+// Config configures a Store
+type Config struct {
+	// Dir holds the artifacts, it is created if missing
+	Dir string
+	// Retention is how long artifacts are kept after they were last
+	// attached, DefaultRetention if zero
+	Retention time.Duration
+	// MaxSize bounds the size of an artifact, DefaultMaxSize if zero
+	MaxSize int64
+	// Clock timestamps artifacts, the system clock if nil
+	Clock clock.Clock
+	// Sealer, if set, encrypts the content and descriptions of artifacts.
+	// Artifacts stored in plaintext are then refused.
+	Sealer *vault.Sealer
+}
+
+// Store keeps artifacts in a directory, the content of each in a file
+// named by its ID next to a JSON file describing it
+type Store struct {
+	dir       string
+	retention time.Duration
+	maxSize   int64
+	clock     clock.Clock
+	sealer    *vault.Sealer
+
+	mu        sync.RWMutex
+	artifacts map[string]*Artifact
+}
+
+// NewStore opens the store in the directory of its configuration and loads
+// the descriptions of the artifacts in it
+func NewStore(config Config) (*Store, error) {
+	if config.Retention <= 0 {
+		config.Retention = DefaultRetention
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultMaxSize
+	}
+	if config.Clock == nil {
+		config.Clock = clock.Real
+	}
+	if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create artifact store: %w", err)
+	}
+	s := &Store{
+		dir:       config.Dir,
+		retention: config.Retention,
+		maxSize:   config.MaxSize,
+		clock:     config.Clock,
+		sealer:    config.Sealer,
+		artifacts: make(map[string]*Artifact),
+	}
+	if err := s.load(); err != nil {
+		return nil, fmt.Errorf("failed to load artifact store: %w", err)
+	}
+	return s, nil
+}
+
+// Dir returns the directory of the store
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Encrypted reports whether the store encrypts the artifacts it keeps
+func (s *Store) Encrypted() bool {
+	return s.sealer != nil
+}
+
+// load reads the descriptions of the stored artifacts. Artifacts whose
+// content is missing, or stored in plaintext in an encrypted store, are
+// skipped, and files of interrupted puts removed.
+func (s *Store) load() error {
+	return filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".put-") {
+			// Another store on the directory may still be writing it
+			if info, err := d.Info(); err == nil && time.Since(info.ModTime()) > time.Hour {
+				return os.Remove(path)
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".json" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if data, err = s.sealer.Open(data); errors.Is(err, vault.ErrNotSealed) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		var a Artifact
+		if json.Unmarshal(data, &a) != nil || !validID.MatchString(a.ID) {
+			return nil
+		}
+		if _, err := os.Stat(s.contentPath(a.ID)); err != nil {
+			return nil
+		}
+		s.artifacts[a.ID] = &a
+		return nil
+	})
+}
+
+func (s *Store) contentPath(id string) string {
+	return filepath.Join(s.dir, id[:2], id)
+}
+
+// Put stores the content read from r as an artifact attached to a task and
+// returns its description. Content that is already stored is attached to
+// the task, keeping its name and kind.
+func (s *Store) Put(r io.Reader, name, kind, taskID string) (Artifact, error) {
+	tmp, err := os.CreateTemp(s.dir, ".put-*")
+	if err != nil {
+		return Artifact{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	// The ID hashes the content, not how it is stored
+	hash := sha256.New()
+	w, err := s.sealer.NewWriter(tmp)
+	if err != nil {
+		tmp.Close()
+		return Artifact{}, err
+	}
+	size, err := io.Copy(io.MultiWriter(w, hash), io.LimitReader(r, s.maxSize+1))
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Artifact{}, err
+	}
+	if size > s.maxSize {
+		return Artifact{}, fmt.Errorf("%w: %s is over %d bytes", ErrTooLarge, name, s.maxSize)
+	}
+	id := hex.EncodeToString(hash.Sum(nil))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.clock.Now()
+	a, ok := s.artifacts[id]
+	if !ok {
+		if err := os.MkdirAll(filepath.Dir(s.contentPath(id)), 0o700); err != nil {
+			return Artifact{}, err
+		}
+		if err := os.Rename(tmp.Name(), s.contentPath(id)); err != nil {
+			return Artifact{}, err
+		}
+		a = &Artifact{ID: id, Name: name, Kind: kind, Size: size, CreatedAt: now}
+	}
+	updated := *a
+	updated.UsedAt = now
+	if taskID != "" && !slices.Contains(updated.TaskIDs, taskID) {
+		updated.TaskIDs = append(slices.Clip(updated.TaskIDs), taskID)
+	}
+	if err := s.writeDescription(&updated); err != nil {
+		return Artifact{}, err
+	}
+	s.artifacts[id] = &updated
+	return updated, nil
+}
+
+// PutFile stores a file as an artifact attached to a task, named by its
+// base name if name is empty
+func (s *Store) PutFile(path, name, kind, taskID string) (Artifact, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Artifact{}, err
+	}
+	defer f.Close()
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	return s.Put(f, name, kind, taskID)
+}
+
+func (s *Store) writeDescription(a *Artifact) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	if data, err = s.sealer.Seal(data); err != nil {
+		return err
+	}
+	return os.WriteFile(s.contentPath(a.ID)+".json", data, 0o600)
+}
+
+// Get returns the description of an artifact
+func (s *Store) Get(id string) (Artifact, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a, ok := s.artifacts[id]
+	if !ok {
+		return Artifact{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return *a, nil
+}
+
+// Open opens the content of an artifact, decrypting it if it was sealed
+func (s *Store) Open(id string) (io.ReadCloser, error) {
+	if _, err := s.Get(id); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(s.contentPath(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.sealer.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("artifact %s: %w", id, err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, f}, nil
+}
+
+// List returns the artifacts attached to a task, or every artifact for an
+// empty task ID, oldest first
+func (s *Store) List(taskID string) []Artifact {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var artifacts []Artifact
+	for _, a := range s.artifacts {
+		if taskID == "" || slices.Contains(a.TaskIDs, taskID) {
+			artifacts = append(artifacts, *a)
+		}
+	}
+	sort.Slice(artifacts, func(i, j int) bool {
+		if !artifacts[i].CreatedAt.Equal(artifacts[j].CreatedAt) {
+			return artifacts[i].CreatedAt.Before(artifacts[j].CreatedAt)
+		}
+		return artifacts[i].Name < artifacts[j].Name
+	})
+	return artifacts
+}
+
+// Prune removes the artifacts no task attached within the retention and
+// returns how many were removed
+func (s *Store) Prune() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff := s.clock.Now().Add(-s.retention)
+	removed := 0
+	var errs []error
+	for id, a := range s.artifacts {
+		if !a.UsedAt.Before(cutoff) {
+			continue
+		}
+		if err := os.Remove(s.contentPath(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		_ = os.Remove(s.contentPath(id) + ".json")
+		// Fails unless the directory is empty
+		_ = os.Remove(filepath.Dir(s.contentPath(id)))
+		delete(s.artifacts, id)
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
+// ShortID abbreviates an artifact ID for display
+func ShortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
@@ -0,0 +1,137 @@
+package artifact
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+	"github.com/opencode-ai/opencode/internal/swarm/vault"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	clk := clock.NewFake(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	s, err := NewStore(Config{Dir: dir, Retention: time.Hour, MaxSize: 16, Clock: clk})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := s.Put(strings.NewReader("<testsuites/>"), "junit.xml", KindReport, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.ID) != 64 || report.Size != 13 || report.Name != "junit.xml" {
+		t.Errorf("report = %+v", report)
+	}
+	log, err := s.Put(strings.NewReader("ok\n"), "output.log", KindLog, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The same content is stored once, attached to both tasks
+	clk.Advance(30 * time.Minute)
+	again, err := s.Put(strings.NewReader("<testsuites/>"), "report.xml", KindReport, "t2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != report.ID || again.Name != "junit.xml" || len(again.TaskIDs) != 2 || !again.UsedAt.After(report.UsedAt) {
+		t.Errorf("again = %+v", again)
+	}
+	if _, err := s.Put(strings.NewReader(strings.Repeat("x", 17)), "big.log", KindLog, "t1"); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("storing a large artifact: %v", err)
+	}
+
+	if list := s.List("t1"); len(list) != 2 || list[0].ID != report.ID || list[1].ID != log.ID {
+		t.Errorf("t1 artifacts = %+v", list)
+	}
+	if list := s.List("t2"); len(list) != 1 {
+		t.Errorf("t2 artifacts = %+v", list)
+	}
+	content, err := s.Open(log.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(content)
+	content.Close()
+	if string(data) != "ok\n" {
+		t.Errorf("content = %q", data)
+	}
+	if _, err := s.Open(strings.Repeat("0", 64)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("opening a missing artifact: %v", err)
+	}
+
+	// A reopened store finds the artifacts, and prunes those unused for the
+	// retention
+	s, err = NewStore(Config{Dir: dir, Retention: time.Hour, Clock: clk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := s.List(""); len(list) != 2 {
+		t.Fatalf("reopened artifacts = %+v", list)
+	}
+	clk.Advance(45 * time.Minute)
+	if removed, err := s.Prune(); err != nil || removed != 1 {
+		t.Errorf("pruned %d, %v", removed, err)
+	}
+	if _, err := s.Get(log.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("pruned artifact: %v", err)
+	}
+	if _, err := s.Get(report.ID); err != nil {
+		t.Errorf("artifact attached again was pruned: %v", err)
+	}
+}
+
+func TestSealedStore(t *testing.T) {
+	dir := t.TempDir()
+	// Artifacts stored before encryption was enabled are no longer trusted
+	plain, err := NewStore(Config{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, err := plain.Put(strings.NewReader("old log\n"), "old.log", KindLog, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealer, err := vault.NewSealer(vault.GenerateKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStore(Config{Dir: dir, Sealer: sealer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := s.Put(strings.NewReader("+secret token\n"), "change.diff", KindDiff, "t2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{s.contentPath(diff.ID), s.contentPath(diff.ID) + ".json"} {
+		if data, err := os.ReadFile(path); err != nil || bytes.Contains(data, []byte("secret")) || bytes.Contains(data, []byte("change.diff")) {
+			t.Errorf("%s is not encrypted: %q, %v", path, data, err)
+		}
+	}
+
+	if _, err := NewStore(Config{Dir: dir}); !errors.Is(err, vault.ErrNoKey) {
+		t.Errorf("opening the store without the key: %v, want ErrNoKey", err)
+	}
+	s, err = NewStore(Config{Dir: dir, Sealer: sealer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(old.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("plaintext artifact: %v, want ErrNotFound", err)
+	}
+	content, err := s.Open(diff.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(content)
+	content.Close()
+	if err != nil || string(data) != "+secret token\n" {
+		t.Errorf("content = %q, %v, want %q", data, err, "+secret token\n")
+	}
+}
@@ -0,0 +1,115 @@
+package swarm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/artifact"
+)
+
+// DefaultArtifactDir is where artifacts are kept unless configured
+// otherwise
+func DefaultArtifactDir() string {
+	return filepath.Join(os.TempDir(), "opencode-swarm", "artifacts")
+}
+
+// storeArtifacts keeps the artifacts a task attached in the artifact store,
+// replacing their path or content with the ID of the stored artifact.
+// Artifacts that cannot be stored are dropped, with the reason in the
+// "artifact_errors" metadata of the result. Scratchpad copies stored
+// encrypted are removed, so no plaintext copy outlives the task.
+func (c *Coordinator) storeArtifacts(result *agent.TaskResult) {
+	if len(result.Artifacts) == 0 {
+		return
+	}
+	stored := make([]agent.Artifact, 0, len(result.Artifacts))
+	var errs []string
+	for _, a := range result.Artifacts {
+		if a.Kind == "" {
+			a.Kind = artifact.KindFile
+		}
+		var info artifact.Artifact
+		var err error
+		switch {
+		case a.Path != "":
+			info, err = c.artifacts.PutFile(a.Path, a.Name, a.Kind, result.TaskID)
+		default:
+			info, err = c.artifacts.Put(bytes.NewReader(a.Data), a.Name, a.Kind, result.TaskID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", a.Name, err))
+			continue
+		}
+		if a.Scratch && a.Path != "" && c.artifacts.Encrypted() {
+			if err := os.Remove(a.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, fmt.Sprintf("%s: %v", a.Name, err))
+			}
+		}
+		if a.Name == "" {
+			a.Name = info.Name
+		}
+		stored = append(stored, agent.Artifact{ID: info.ID, Name: a.Name, Kind: a.Kind, Size: info.Size})
+	}
+	result.Artifacts = stored
+	if len(errs) > 0 {
+		if result.Metadata == nil {
+			result.Metadata = make(map[string]interface{})
+		}
+		result.Metadata["artifact_errors"] = errs
+	}
+}
+
+// artifactIDs returns the IDs of the stored artifacts of a result, for the
+// memories about it
+func artifactIDs(result *agent.TaskResult) []string {
+	var ids []string
+	for _, a := range result.Artifacts {
+		if a.ID != "" {
+			ids = append(ids, a.ID)
+		}
+	}
+	return ids
+}
+
+// pruneArtifacts removes the artifacts past their retention and records
+// it on the timeline
+func (c *Coordinator) pruneArtifacts() {
+	removed, err := c.artifacts.Prune()
+	if err != nil {
+		c.timeline.record(TimelineConsolidation, "artifacts", "Pruning artifacts failed", map[string]interface{}{
+			"removed": removed,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if removed > 0 {
+		c.timeline.record(TimelineConsolidation, "artifacts", fmt.Sprintf("Pruned %d artifacts", removed), map[string]interface{}{
+			"removed": removed,
+		})
+	}
+}
+
+// TaskArtifacts returns the stored artifacts attached to a task
+func (c *Coordinator) TaskArtifacts(taskID string) []artifact.Artifact {
+	return c.artifacts.List(taskID)
+}
+
+// OpenArtifact returns a stored artifact with its content, which the
+// caller closes
+func (c *Coordinator) OpenArtifact(id string) (artifact.Artifact, io.ReadCloser, error) {
+	info, err := c.artifacts.Get(id)
+	if err != nil {
+		return artifact.Artifact{}, nil, err
+	}
+	content, err := c.artifacts.Open(id)
+	if err != nil {
+		return artifact.Artifact{}, nil, err
+	}
+	return info, content, nil
+}
@@ -0,0 +1,147 @@
+package swarm
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/artifact"
+	"github.com/opencode-ai/opencode/internal/swarm/vault"
+)
+
+// artifactAgent attaches a log file and a diff to every result
+type artifactAgent struct {
+	*agent.BaseAgent
+	log string
+}
+
+func (a *artifactAgent) CanHandleTask(task agent.Task) bool {
+	return true
+}
+
+func (a *artifactAgent) ExecuteTask(ctx context.Context, task agent.Task) (*agent.TaskResult, error) {
+	return &agent.TaskResult{
+		TaskID:      task.ID,
+		Success:     true,
+		AgentID:     a.GetID(),
+		CompletedAt: time.Now(),
+		Artifacts: []agent.Artifact{
+			{Name: "output.log", Kind: artifact.KindLog, Path: a.log},
+			{Name: "fix.diff", Kind: artifact.KindDiff, Data: []byte("--- a/x\n+++ b/x\n")},
+			{Name: "missing.log", Kind: artifact.KindLog, Path: filepath.Join(filepath.Dir(a.log), "missing.log")},
+		},
+	}, nil
+}
+
+func TestTaskArtifacts(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "output.log")
+	if err := os.WriteFile(log, []byte("ok\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	agent.RegisterFactory(agent.AgentTypeLearning, func(config agent.AgentConfig) (agent.Agent, error) {
+		return &artifactAgent{BaseAgent: agent.NewBaseAgent(config), log: log}, nil
+	})
+
+	s, err := Open(FileConfig{
+		Agents:      []AgentFileConfig{{ID: "artifacts", Type: string(agent.AgentTypeLearning)}},
+		ArtifactDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	id, err := s.SubmitTask(ctx, Task{Type: "learn"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := s.Result(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Artifacts) != 2 || result.Artifacts[0].ID == "" || result.Artifacts[0].Path != "" || result.Artifacts[1].Data != nil {
+		t.Fatalf("artifacts = %+v", result.Artifacts)
+	}
+	if errs, _ := result.Metadata["artifact_errors"].([]string); len(errs) != 1 {
+		t.Errorf("artifact errors = %q", errs)
+	}
+
+	stored := s.Artifacts(id)
+	if len(stored) != 2 || stored[0].TaskIDs[0] != id {
+		t.Fatalf("stored = %+v", stored)
+	}
+	info, content, err := s.OpenArtifact(result.Artifacts[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(content)
+	content.Close()
+	if info.Name != "output.log" || string(data) != "ok\n" {
+		t.Errorf("artifact %+v = %q", info, data)
+	}
+
+	// The memory of the result refers to the artifacts by ID
+	for {
+		memories, err := s.Query(ctx, MemoryQuery{Type: MemoryTypeProcedural, Tags: []string{"task", "result"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(memories) == 1 {
+			if ids, _ := memories[0].Metadata["artifacts"].([]string); len(ids) != 2 || ids[1] != result.Artifacts[1].ID {
+				t.Errorf("memory metadata = %+v", memories[0].Metadata)
+			}
+			break
+		}
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			t.Fatalf("memories = %+v", memories)
+		}
+	}
+}
+
+func TestScratchArtifacts(t *testing.T) {
+	sealer, err := vault.NewSealer(vault.GenerateKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		sealer  *vault.Sealer
+		scratch bool
+		removed bool
+	}{
+		{name: "scratch copy in an encrypted store", sealer: sealer, scratch: true, removed: true},
+		{name: "file of the workspace in an encrypted store", sealer: sealer, scratch: false, removed: false},
+		{name: "scratch copy in a plaintext store", sealer: nil, scratch: true, removed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := artifact.NewStore(artifact.Config{Dir: t.TempDir(), Sealer: tt.sealer})
+			if err != nil {
+				t.Fatal(err)
+			}
+			log := filepath.Join(t.TempDir(), "stdout.log")
+			if err := os.WriteFile(log, []byte("token=secret\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			c := &Coordinator{artifacts: store}
+			result := &agent.TaskResult{TaskID: "t1", Artifacts: []agent.Artifact{
+				{Name: "stdout.log", Kind: artifact.KindLog, Path: log, Scratch: tt.scratch},
+			}}
+			c.storeArtifacts(result)
+			if len(result.Artifacts) != 1 || result.Artifacts[0].ID == "" {
+				t.Fatalf("artifacts = %+v, errors %v", result.Artifacts, result.Metadata["artifact_errors"])
+			}
+			if _, err := os.Stat(log); os.IsNotExist(err) != tt.removed {
+				t.Errorf("scratch copy removed = %v, want %v", os.IsNotExist(err), tt.removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package swarm
+
+import (
+	"fmt"
+
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+	"github.com/opencode-ai/opencode/internal/swarm/monitor"
+	"github.com/opencode-ai/opencode/internal/swarm/rules"
+)
+
+// processCIReports handles the test reports of CI runs
+func (c *Coordinator) processCIReports() {
+	defer c.wg.Done()
+
+	for {
+		select {
+		case report, ok := <-c.ciWatcher.Entries():
+			if !ok {
+				return
+			}
+			c.handleCIReport(report)
+
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// handleCIReport records a CI test report in the timeline and remembers
+// its failures. Each failure is evaluated by the rules as a test_failure
+// event and handled like an error log, so an agent that handles errors
+// gets a task for it and a failure that keeps coming back gets an issue.
+func (c *Coordinator) handleCIReport(ci monitor.CIReport) {
+	report := ci.Report
+	c.timeline.record(TimelineTestReport, ci.Source, fmt.Sprintf("%s: %d passed, %d failed, %d skipped", ci.Source, report.Passed, report.Failed, report.Skipped), map[string]interface{}{
+		"passed":  report.Passed,
+		"failed":  report.Failed,
+		"skipped": report.Skipped,
+	})
+
+	for i, failure := range report.Failures {
+		if i == maxStoredTestFailures {
+			break
+		}
+		name := failure.Package
+		if failure.Name != "" {
+			name += "." + failure.Name
+		}
+		// A report read again replaces the failures remembered of it
+		_ = c.memoryStore.Store(c.ctx, memory.Memory{
+			ID:       "ci:" + ci.Source + ":" + name,
+			Type:     memory.MemoryTypeEpisodic,
+			Content:  failure,
+			Tags:     []string{"test", "failure", "ci"},
+			Priority: memory.PriorityHigh,
+			Metadata: map[string]interface{}{
+				"source":  ci.Source,
+				"package": failure.Package,
+				"test":    failure.Name,
+			},
+		})
+
+		signature := "test failure: " + name
+		_ = c.ruleEngine.EvaluateRules(c.ctx, rules.RuleContext{
+			EventType: "test_failure",
+			EventData: map[string]interface{}{
+				"source":    ci.Source,
+				"package":   failure.Package,
+				"test":      failure.Name,
+				"message":   failure.Message,
+				"signature": signature,
+			},
+			Timestamp: ci.Time,
+		})
+
+		message := failure.String()
+		if failure.Message != "" {
+			message = name + "\n" + failure.Message
+		}
+		c.handleErrorLog(monitor.LogEntry{
+			Timestamp: ci.Time,
+			Level:     "error",
+			Source:    ci.Source,
+			Message:   message,
+		}, signature)
+	}
+}
@@ -0,0 +1,65 @@
+package swarm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/monitor"
+)
+
+func TestCIReportFailures(t *testing.T) {
+	s, err := Open(FileConfig{Agents: []AgentFileConfig{
+		{ID: "errors", Type: string(agent.AgentTypeErrorHandler), Options: map[string]string{"dir": t.TempDir()}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := s.Subscribe(ctx)
+
+	report := monitor.CIReport{
+		Source: "ci/junit.xml",
+		Time:   time.Now(),
+		Report: agent.TestReport{Passed: 3, Failed: 1, Failures: []agent.TestFailure{
+			{Package: "api.Users", Name: "TestDelete", Message: "expected 204, got 500"},
+		}},
+	}
+	// The second report is of the same failure, which is handled once
+	s.coordinator.handleCIReport(report)
+	s.coordinator.handleCIReport(report)
+
+	var reports int
+	var submitted []string
+	for reports < 2 || len(submitted) == 0 {
+		select {
+		case event := <-events:
+			switch event.Payload.Type {
+			case TimelineTestReport:
+				reports++
+				if failed := event.Payload.Details["failed"]; failed != 1 {
+					t.Errorf("failed = %v, want 1", failed)
+				}
+			case TimelineTaskSubmitted:
+				submitted = append(submitted, event.Payload.Details["type"].(string))
+			}
+		case <-ctx.Done():
+			t.Fatalf("%d reports, submitted tasks: %v", reports, submitted)
+		}
+	}
+	if len(submitted) != 1 || submitted[0] != agent.TaskTypeHandleError {
+		t.Errorf("submitted tasks = %v, want one %s", submitted, agent.TaskTypeHandleError)
+	}
+
+	failures, err := s.Query(ctx, MemoryQuery{Type: MemoryTypeEpisodic, Tags: []string{"ci"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 1 || failures[0].Metadata["test"] != "TestDelete" {
+		t.Errorf("remembered failures = %+v, want the one of TestDelete", failures)
+	}
+}
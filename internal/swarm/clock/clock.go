@@ -0,0 +1,246 @@
+// Package clock lets the swarm tell the time and wait from a clock that
+// tests and simulations can control instead of the system clock.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the current time and creates tickers and timers
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	// NewTicker ticks every d. It panics if d is not positive, like
+	// time.NewTicker.
+	NewTicker(d time.Duration) Ticker
+	NewTimer(d time.Duration) Timer
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker delivers ticks on C, dropping ticks for slow receivers
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Timer delivers a single tick on C once it expires
+type Timer interface {
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It reports whether the timer
+	// was active.
+	Stop() bool
+	// Reset makes the timer expire after d. It reports whether the timer
+	// was active.
+	Reset(d time.Duration) bool
+}
+
+// Real is the system clock
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time        { return t.t.C }
+func (t realTimer) Stop() bool                 { return t.t.Stop() }
+func (t realTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+// Fake is a clock that only moves when told to. Tickers and timers fire
+// while it is moved past their time.
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// NewFake creates a fake clock showing now
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the time the clock was last set to
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the fake time elapsed since t
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// Set moves the clock to t and fires the tickers and timers due by then.
+// Time never goes backwards, earlier times are ignored.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t.After(f.now) {
+		f.now = t
+	}
+	f.fire()
+}
+
+// Advance moves the clock forward by d and fires the tickers and timers due
+// by then
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if d > 0 {
+		f.now = f.now.Add(d)
+	}
+	f.fire()
+}
+
+// BlockUntil waits until at least n tickers and timers are waiting for the
+// clock. Tests call it before advancing so that the goroutine under test
+// has created its ticker.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+// NewTicker creates a ticker that ticks every d of fake time
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{clock: f, c: make(chan time.Time, 1), period: d}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.add(w, f.now.Add(d))
+	return fakeTicker{w}
+}
+
+// NewTimer creates a timer that fires after d of fake time
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{clock: f, c: make(chan time.Time, 1)}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.add(w, f.now.Add(d))
+	f.fire()
+	return w
+}
+
+// After waits for d of fake time, then sends the time
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// add schedules a waiter at a time. It is called with f.mu held.
+func (f *Fake) add(w *fakeWaiter, at time.Time) {
+	w.at = at
+	f.waiters = append(f.waiters, w)
+	f.cond.Broadcast()
+}
+
+// remove unschedules a waiter and reports whether it was scheduled. It is
+// called with f.mu held.
+func (f *Fake) remove(w *fakeWaiter) bool {
+	for i, waiter := range f.waiters {
+		if waiter == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fire sends the time to every waiter that is due, earliest first. Tickers
+// are rescheduled, timers removed. It is called with f.mu held.
+func (f *Fake) fire() {
+	sort.SliceStable(f.waiters, func(i, j int) bool {
+		return f.waiters[i].at.Before(f.waiters[j].at)
+	})
+	kept := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.at.After(f.now) {
+			kept = append(kept, w)
+			continue
+		}
+		select {
+		case w.c <- f.now:
+		default:
+			// Like time.Ticker, drop ticks the receiver is not ready for
+		}
+		if w.period > 0 {
+			for !w.at.After(f.now) {
+				w.at = w.at.Add(w.period)
+			}
+			kept = append(kept, w)
+		}
+	}
+	f.waiters = kept
+}
+
+// fakeWaiter is a ticker when it has a period, otherwise a timer
+type fakeWaiter struct {
+	clock  *Fake
+	c      chan time.Time
+	at     time.Time
+	period time.Duration
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.c
+}
+
+func (w *fakeWaiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	return w.clock.remove(w)
+}
+
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	active := w.clock.remove(w)
+	w.clock.add(w, w.clock.now.Add(d))
+	w.clock.fire()
+	return active
+}
+
+// fakeTicker hides the result of Stop, which tickers do not have
+type fakeTicker struct {
+	w *fakeWaiter
+}
+
+func (t fakeTicker) C() <-chan time.Time { return t.w.c }
+func (t fakeTicker) Stop()               { t.w.Stop() }
@@ -0,0 +1,120 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func received(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-c:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	fake := NewFake(epoch)
+	ticker := fake.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	fake.Advance(999 * time.Millisecond)
+	if _, ok := received(ticker.C()); ok {
+		t.Fatal("ticked early")
+	}
+	fake.Advance(time.Millisecond)
+	if got, ok := received(ticker.C()); !ok || !got.Equal(epoch.Add(time.Second)) {
+		t.Fatalf("tick = %v, %v", got, ok)
+	}
+
+	// Ticks the receiver missed are dropped
+	fake.Advance(5 * time.Second)
+	if _, ok := received(ticker.C()); !ok {
+		t.Fatal("no tick after 5s")
+	}
+	if _, ok := received(ticker.C()); ok {
+		t.Fatal("missed ticks were queued")
+	}
+	fake.Advance(time.Second)
+	if got, ok := received(ticker.C()); !ok || !got.Equal(epoch.Add(7*time.Second)) {
+		t.Fatalf("tick = %v, %v", got, ok)
+	}
+
+	ticker.Stop()
+	fake.Advance(time.Minute)
+	if _, ok := received(ticker.C()); ok {
+		t.Fatal("ticked after Stop")
+	}
+}
+
+func TestFakeTimer(t *testing.T) {
+	fake := NewFake(epoch)
+	timer := fake.NewTimer(time.Minute)
+
+	fake.Set(epoch.Add(time.Minute))
+	if got, ok := received(timer.C()); !ok || !got.Equal(epoch.Add(time.Minute)) {
+		t.Fatalf("fired = %v, %v", got, ok)
+	}
+	fake.Advance(time.Hour)
+	if _, ok := received(timer.C()); ok {
+		t.Fatal("fired twice")
+	}
+	if timer.Stop() {
+		t.Error("Stop reported an expired timer as active")
+	}
+
+	if timer.Reset(time.Second) {
+		t.Error("Reset reported an expired timer as active")
+	}
+	if !timer.Reset(time.Minute) {
+		t.Error("Reset reported a pending timer as inactive")
+	}
+	fake.Advance(time.Second)
+	if _, ok := received(timer.C()); ok {
+		t.Fatal("fired at the time it was reset from")
+	}
+	if !timer.Stop() {
+		t.Error("Stop reported a pending timer as inactive")
+	}
+	fake.Advance(time.Hour)
+	if _, ok := received(timer.C()); ok {
+		t.Fatal("fired after Stop")
+	}
+
+	if _, ok := received(fake.After(0)); !ok {
+		t.Error("After(0) did not fire right away")
+	}
+}
+
+func TestFakeSetNeverGoesBack(t *testing.T) {
+	fake := NewFake(epoch)
+	fake.Set(epoch.Add(-time.Hour))
+	if got := fake.Now(); !got.Equal(epoch) {
+		t.Errorf("now = %v, want %v", got, epoch)
+	}
+	if got := fake.Since(epoch.Add(-time.Minute)); got != time.Minute {
+		t.Errorf("since = %v, want 1m", got)
+	}
+}
+
+func TestFakeBlockUntil(t *testing.T) {
+	fake := NewFake(epoch)
+	done := make(chan time.Time)
+	go func() {
+		done <- <-fake.After(time.Minute)
+	}()
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	select {
+	case got := <-done:
+		if !got.Equal(epoch.Add(time.Minute)) {
+			t.Errorf("fired at %v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timer did not fire")
+	}
+}
@@ -0,0 +1,301 @@
+package swarm
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/llm/models"
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+)
+
+// TokenPrice is what a model costs, in dollars per million tokens
+type TokenPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// cost is what a number of tokens cost at the price
+func (p TokenPrice) cost(input, output int64) float64 {
+	return p.Input/1e6*float64(input) + p.Output/1e6*float64(output)
+}
+
+// ComparisonSide is how one of the agents of a comparison did on the tasks
+// both ran
+type ComparisonSide struct {
+	AgentID string `json:"agentId"`
+	// Models are the models the agent's results name
+	Models      []string      `json:"models,omitempty"`
+	Tasks       int           `json:"tasks"`
+	Succeeded   int           `json:"succeeded"`
+	MeanLatency time.Duration `json:"meanLatency"`
+	P95Latency  time.Duration `json:"p95Latency"`
+	// InputTokens and OutputTokens are the tokens the results report
+	InputTokens  int64 `json:"inputTokens"`
+	OutputTokens int64 `json:"outputTokens"`
+	// Cost is what the tokens of the priced models cost, in dollars
+	Cost float64 `json:"cost"`
+	// Unpriced counts the results using tokens of models without a price
+	Unpriced int `json:"unpriced,omitempty"`
+}
+
+// SuccessRate is the share of the tasks the agent succeeded at
+func (s ComparisonSide) SuccessRate() float64 {
+	if s.Tasks == 0 {
+		return 0
+	}
+	return float64(s.Succeeded) / float64(s.Tasks)
+}
+
+// Comparison evaluates a shadow agent against the agent that ran the tasks
+// it got copies of, over the tasks finished in a time window
+type Comparison struct {
+	Primary ComparisonSide `json:"primary"`
+	Shadow  ComparisonSide `json:"shadow"`
+	Since   time.Time      `json:"since"`
+	Until   time.Time      `json:"until"`
+	// SameOutcome counts the tasks both succeeded or failed at
+	SameOutcome int `json:"sameOutcome"`
+	// SameOutput counts the tasks both gave the same output for
+	SameOutput int `json:"sameOutput"`
+	// EditTasks counts the tasks either proposed file edits for
+	EditTasks int `json:"editTasks"`
+	// DiffAgreement is the mean share of the files edited in EditTasks
+	// that both edited the same way
+	DiffAgreement float64 `json:"diffAgreement"`
+}
+
+// comparisonSample is one result of an agent in a comparison
+type comparisonSample struct {
+	success  bool
+	latency  time.Duration
+	metadata map[string]interface{}
+}
+
+// CompareAgents evaluates a shadow agent against a primary agent on the
+// tasks the primary ran and the shadow got copies of, finished since and
+// before until, up to now if until is zero. Only tasks still tracked count,
+// and not those the shadow only dry-ran.
+func (c *Coordinator) CompareAgents(primaryID, shadowID string, since, until time.Time) Comparison {
+	comparison := Comparison{Since: since, Until: until}
+	if until.IsZero() {
+		comparison.Until = c.clock.Now()
+	}
+	var primary, shadow []comparisonSample
+	var agreement float64
+	for _, record := range c.tasks.list() {
+		if record.AgentID != primaryID || !finishedWithin(record, since, until) {
+			continue
+		}
+		i := slices.IndexFunc(record.Shadows, func(r ShadowResult) bool { return r.AgentID == shadowID && !r.DryRun })
+		if i < 0 {
+			continue
+		}
+		result := record.Shadows[i]
+
+		primary = append(primary, comparisonSample{
+			success:  record.Result.Success,
+			latency:  record.FinishedAt.Sub(record.StartedAt),
+			metadata: record.Result.Metadata,
+		})
+		shadow = append(shadow, comparisonSample{
+			success:  result.Success,
+			latency:  result.Duration,
+			metadata: result.Metadata,
+		})
+		if result.SameOutcome {
+			comparison.SameOutcome++
+		}
+		if result.SameOutput {
+			comparison.SameOutput++
+		}
+		if share, ok := editAgreement(record.Result.Output, result.Output); ok {
+			comparison.EditTasks++
+			agreement += share
+		}
+	}
+	comparison.Primary = c.comparisonSide(primaryID, primary)
+	comparison.Shadow = c.comparisonSide(shadowID, shadow)
+	if comparison.EditTasks > 0 {
+		comparison.DiffAgreement = agreement / float64(comparison.EditTasks)
+	}
+	return comparison
+}
+
+// Comparisons evaluates every shadow agent against every agent it shadowed
+// on tasks finished since and before until, by primary and then shadow ID
+func (c *Coordinator) Comparisons(since, until time.Time) []Comparison {
+	pairs := make(map[[2]string]bool)
+	for _, record := range c.tasks.list() {
+		if !finishedWithin(record, since, until) {
+			continue
+		}
+		for _, result := range record.Shadows {
+			if !result.DryRun {
+				pairs[[2]string{record.AgentID, result.AgentID}] = true
+			}
+		}
+	}
+	keys := slices.SortedFunc(maps.Keys(pairs), func(a, b [2]string) int {
+		if a[0] != b[0] {
+			return strings.Compare(a[0], b[0])
+		}
+		return strings.Compare(a[1], b[1])
+	})
+	comparisons := make([]Comparison, 0, len(keys))
+	for _, pair := range keys {
+		comparisons = append(comparisons, c.CompareAgents(pair[0], pair[1], since, until))
+	}
+	return comparisons
+}
+
+// finishedWithin returns whether a task finished with a result since and
+// before until, if until is set
+func finishedWithin(record TaskRecord, since, until time.Time) bool {
+	return record.Result != nil && !record.FinishedAt.Before(since) && (until.IsZero() || record.FinishedAt.Before(until))
+}
+
+// comparisonSide sums up the results of an agent in a comparison
+func (c *Coordinator) comparisonSide(agentID string, samples []comparisonSample) ComparisonSide {
+	side := ComparisonSide{AgentID: agentID, Tasks: len(samples)}
+	if len(samples) == 0 {
+		return side
+	}
+	latencies := make([]time.Duration, len(samples))
+	var total time.Duration
+	for i, sample := range samples {
+		if sample.success {
+			side.Succeeded++
+		}
+		latencies[i] = sample.latency
+		total += sample.latency
+
+		model, _ := sample.metadata["model"].(string)
+		if model != "" && !slices.Contains(side.Models, model) {
+			side.Models = append(side.Models, model)
+		}
+		input, output := metadataCount(sample.metadata["input_tokens"]), metadataCount(sample.metadata["output_tokens"])
+		side.InputTokens += input
+		side.OutputTokens += output
+		if input == 0 && output == 0 {
+			continue
+		}
+		if price, ok := c.price(model); ok {
+			side.Cost += price.cost(input, output)
+		} else {
+			side.Unpriced++
+		}
+	}
+	sort.Strings(side.Models)
+	slices.Sort(latencies)
+	side.MeanLatency = total / time.Duration(len(samples))
+	side.P95Latency = latencies[(len(latencies)*95+99)/100-1]
+	return side
+}
+
+// price returns the configured price of a model, else the price the model
+// catalog lists for it
+func (c *Coordinator) price(model string) (TokenPrice, bool) {
+	if model == "" {
+		return TokenPrice{}, false
+	}
+	if price, ok := c.prices[model]; ok {
+		return price, true
+	}
+	for id, m := range models.SupportedModels {
+		if (m.APIModel == model || string(id) == model) && (m.CostPer1MIn > 0 || m.CostPer1MOut > 0) {
+			return TokenPrice{Input: m.CostPer1MIn, Output: m.CostPer1MOut}, true
+		}
+	}
+	return TokenPrice{}, false
+}
+
+// metadataCount reads a count from result metadata, which holds integers
+// or, once decoded from JSON, floats
+func metadataCount(v interface{}) int64 {
+	switch n := v.(type) {
+	case int:
+		return int64(n)
+	case int64:
+		return n
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
+
+// editAgreement returns the share of the files the patches of two outputs
+// change that both change the same way, and whether either changes any
+func editAgreement(a, b map[string]interface{}) (float64, bool) {
+	var editsA, editsB []agent.FileEdit
+	if patch, ok := agent.ResultPatch(a); ok {
+		editsA = patch.Files
+	}
+	if patch, ok := agent.ResultPatch(b); ok {
+		editsB = patch.Files
+	}
+	proposed := make(map[string]string, len(editsA))
+	for _, edit := range editsA {
+		proposed[edit.Path] = edit.Proposed
+	}
+	files := len(proposed)
+	same := 0
+	for _, edit := range editsB {
+		theirs, ok := proposed[edit.Path]
+		if !ok {
+			files++
+		} else if theirs == edit.Proposed {
+			same++
+		}
+	}
+	if files == 0 {
+		return 0, false
+	}
+	return float64(same) / float64(files), true
+}
+
+// Markdown renders the comparison as a markdown report
+func (r Comparison) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s vs %s\n\n", r.Primary.AgentID, r.Shadow.AgentID)
+	fmt.Fprintf(&b, "Tasks finished from %s to %s that %s ran and shadow %s got copies of.\n\n",
+		r.Since.Format(time.DateTime), r.Until.Format(time.DateTime), r.Primary.AgentID, r.Shadow.AgentID)
+	if r.Primary.Tasks == 0 {
+		b.WriteString("No tasks to compare.\n")
+		return b.String()
+	}
+
+	b.WriteString("| | " + r.Primary.AgentID + " | " + r.Shadow.AgentID + " |\n")
+	b.WriteString("|---|---|---|\n")
+	row := func(label string, value func(ComparisonSide) string) {
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", label, value(r.Primary), value(r.Shadow))
+	}
+	row("Models", func(s ComparisonSide) string { return strings.Join(s.Models, ", ") })
+	row("Success rate", func(s ComparisonSide) string {
+		return fmt.Sprintf("%.0f%% (%d/%d)", s.SuccessRate()*100, s.Succeeded, s.Tasks)
+	})
+	row("Mean latency", func(s ComparisonSide) string { return s.MeanLatency.Round(time.Millisecond).String() })
+	row("p95 latency", func(s ComparisonSide) string { return s.P95Latency.Round(time.Millisecond).String() })
+	row("Tokens in/out", func(s ComparisonSide) string { return fmt.Sprintf("%d/%d", s.InputTokens, s.OutputTokens) })
+	row("Cost", func(s ComparisonSide) string {
+		cost := fmt.Sprintf("$%.4f", s.Cost)
+		if s.Unpriced > 0 {
+			cost += fmt.Sprintf(" (%d results unpriced)", s.Unpriced)
+		}
+		return cost
+	})
+
+	b.WriteString("\n## Agreement\n\n")
+	tasks := r.Primary.Tasks
+	fmt.Fprintf(&b, "- Same outcome: %.0f%% (%d/%d)\n", float64(r.SameOutcome)/float64(tasks)*100, r.SameOutcome, tasks)
+	fmt.Fprintf(&b, "- Same output: %.0f%% (%d/%d)\n", float64(r.SameOutput)/float64(tasks)*100, r.SameOutput, tasks)
+	if r.EditTasks > 0 {
+		fmt.Fprintf(&b, "- Diff agreement: %.0f%% of the edited files over %d tasks with edits\n", r.DiffAgreement*100, r.EditTasks)
+	} else {
+		b.WriteString("- Diff agreement: no task proposed edits\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,85 @@
+package swarm
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+func TestCompareAgents(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	c, err := NewCoordinator(CoordinatorConfig{Clock: clk, Prices: map[string]TokenPrice{"small": {Input: 1, Output: 2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cancelFunc()
+
+	usage := func(model string, input, output int64) map[string]interface{} {
+		return map[string]interface{}{"model": model, "input_tokens": input, "output_tokens": output}
+	}
+	edits := func(files ...string) map[string]interface{} {
+		var edits []agent.FileEdit
+		for i := 0; i < len(files); i += 2 {
+			edits = append(edits, agent.FileEdit{Path: files[i], Proposed: files[i+1]})
+		}
+		return map[string]interface{}{agent.OutputPatch: agent.NewPatch("/", edits)}
+	}
+	run := func(id, agentID string, took time.Duration, result agent.TaskResult, shadow ShadowResult) {
+		t.Helper()
+		if _, _, err := c.tasks.enqueue(agent.Task{ID: id, Type: "docs"}); err != nil {
+			t.Fatal(err)
+		}
+		c.tasks.next()
+		c.tasks.start(id, agentID, nil)
+		clk.Advance(took)
+		result.TaskID, result.AgentID = id, agentID
+		c.tasks.finish(id, &result)
+		shadow.AgentID = "twin"
+		shadow.SameOutcome = shadow.Success == result.Success
+		c.tasks.addShadow(id, shadow)
+	}
+
+	since := clk.Now()
+	// The shadow edits one of two files the same way and one more
+	run("task-1", "primary", 2*time.Second,
+		agent.TaskResult{Success: true, Output: edits("a.go", "x", "b.go", "y"), Metadata: usage("small", 1000, 500)},
+		ShadowResult{Success: true, Output: edits("a.go", "x", "c.go", "z"), Duration: time.Second, Metadata: usage("small", 100, 100)})
+	// It fails where the primary succeeds, with a model without a price
+	run("task-2", "primary", 4*time.Second,
+		agent.TaskResult{Success: true, Metadata: usage("small", 1000, 500)},
+		ShadowResult{Success: false, Duration: 3 * time.Second, Metadata: usage("mystery", 100, 100)})
+	run("task-3", "other", time.Second, agent.TaskResult{Success: true}, ShadowResult{Success: true})
+
+	report := c.CompareAgents("primary", "twin", since, time.Time{})
+	p, s := report.Primary, report.Shadow
+	if p.Tasks != 2 || p.Succeeded != 2 || s.Succeeded != 1 {
+		t.Fatalf("primary %d/%d, shadow %d succeeded, want 2/2 and 1", p.Succeeded, p.Tasks, s.Succeeded)
+	}
+	if p.MeanLatency != 3*time.Second || p.P95Latency != 4*time.Second || s.MeanLatency != 2*time.Second || s.P95Latency != 3*time.Second {
+		t.Errorf("latencies = %s/%s and %s/%s, want 3s/4s and 2s/3s", p.MeanLatency, p.P95Latency, s.MeanLatency, s.P95Latency)
+	}
+	if p.InputTokens != 2000 || math.Abs(p.Cost-0.004) > 1e-9 || p.Unpriced != 0 {
+		t.Errorf("primary used %d tokens for $%g with %d unpriced, want 2000 for $0.004", p.InputTokens, p.Cost, p.Unpriced)
+	}
+	if math.Abs(s.Cost-0.0003) > 1e-9 || s.Unpriced != 1 {
+		t.Errorf("shadow cost $%g with %d unpriced, want $0.0003 and 1", s.Cost, s.Unpriced)
+	}
+	if report.SameOutcome != 1 || report.EditTasks != 1 || math.Abs(report.DiffAgreement-1.0/3) > 1e-9 {
+		t.Errorf("agreement = %d outcomes, %d edit tasks at %g, want 1, 1 at 1/3", report.SameOutcome, report.EditTasks, report.DiffAgreement)
+	}
+	if md := report.Markdown(); !strings.Contains(md, "| Success rate | 100% (2/2) | 50% (1/2) |") || !strings.Contains(md, "Diff agreement: 33%") {
+		t.Errorf("markdown report misses the success rates or diff agreement:\n%s", md)
+	}
+
+	comparisons := c.Comparisons(since, time.Time{})
+	if len(comparisons) != 2 || comparisons[0].Primary.AgentID != "other" || comparisons[0].Primary.Tasks != 1 || comparisons[1].Primary.AgentID != "primary" {
+		t.Errorf("comparisons = %+v, want other and primary against twin", comparisons)
+	}
+	if later := c.CompareAgents("primary", "twin", clk.Now().Add(time.Second), time.Time{}); later.Primary.Tasks != 0 {
+		t.Errorf("%d tasks compared after the window, want none", later.Primary.Tasks)
+	}
+}
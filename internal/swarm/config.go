@@ -0,0 +1,1085 @@
+package swarm
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/health"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+	"github.com/opencode-ai/opencode/internal/swarm/monitor"
+	"github.com/opencode-ai/opencode/internal/swarm/notify"
+	"github.com/opencode-ai/opencode/internal/swarm/provider"
+	"github.com/opencode-ai/opencode/internal/swarm/rules"
+	"github.com/opencode-ai/opencode/internal/swarm/vault"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultAPIAddress is where a headless swarm serves its control API unless
+// configured otherwise
+const DefaultAPIAddress = "127.0.0.1:7420"
+
+// Duration is a time.Duration written as a string such as "30s" in config
+// files
+type Duration time.Duration
+
+// UnmarshalText parses a duration string
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("duration must be a string such as \"30s\": %w", err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalText writes the duration as a string
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// FileConfig is the configuration file of a swarm run without the TUI. It
+// can be written as JSON, YAML or TOML.
+type FileConfig struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty" toml:"name,omitempty"`
+	// API is the address the control API listens on
+	API string `json:"api,omitempty" yaml:"api,omitempty" toml:"api,omitempty"`
+	// GRPC is the address the gRPC streaming API listens on, if it is
+	// served
+	GRPC string `json:"grpc,omitempty" yaml:"grpc,omitempty" toml:"grpc,omitempty"`
+
+	VotingThreshold float64 `json:"votingThreshold,omitempty" yaml:"votingThreshold,omitempty" toml:"votingThreshold,omitempty"`
+	// SelectionJitter is the most added at random to the score of agents
+	// that can take a task, so agents of about the same score share tasks
+	SelectionJitter    float64 `json:"selectionJitter,omitempty" yaml:"selectionJitter,omitempty" toml:"selectionJitter,omitempty"`
+	MaxConcurrentTasks int     `json:"maxConcurrentTasks,omitempty" yaml:"maxConcurrentTasks,omitempty" toml:"maxConcurrentTasks,omitempty"`
+	TaskQueueSize      int     `json:"taskQueueSize,omitempty" yaml:"taskQueueSize,omitempty" toml:"taskQueueSize,omitempty"`
+	// IdempotencyWindow is how long a completed task answers submissions
+	// with its idempotency key
+	IdempotencyWindow Duration `json:"idempotencyWindow,omitempty" yaml:"idempotencyWindow,omitempty" toml:"idempotencyWindow,omitempty"`
+
+	// Providers are the model providers agents can refer to by name
+	Providers map[string]ProviderFileConfig `json:"providers,omitempty" yaml:"providers,omitempty" toml:"providers,omitempty"`
+	Agents    []AgentFileConfig             `json:"agents,omitempty" yaml:"agents,omitempty" toml:"agents,omitempty"`
+	// WarmPools keep extra agents of a type with their model loaded, by
+	// agent type
+	WarmPools map[string]WarmPoolFileConfig `json:"warmPools,omitempty" yaml:"warmPools,omitempty" toml:"warmPools,omitempty"`
+	// Verification has reviewers verify the results of tasks, by task type
+	Verification map[string]VerificationFileConfig `json:"verification,omitempty" yaml:"verification,omitempty" toml:"verification,omitempty"`
+	// Prices are what models cost in dollars per million tokens, by model,
+	// for comparisons of agents. Models of the model catalog need none.
+	Prices map[string]PriceFileConfig `json:"prices,omitempty" yaml:"prices,omitempty" toml:"prices,omitempty"`
+	// DryRun has executor and testing agents report the commands of tasks
+	// and simulate successful results instead of running them
+	DryRun bool `json:"dryRun,omitempty" yaml:"dryRun,omitempty" toml:"dryRun,omitempty"`
+	// Notifications post alerts, decided votes and daily digests to Slack
+	// or Discord webhooks, by name
+	Notifications map[string]NotificationFileConfig `json:"notifications,omitempty" yaml:"notifications,omitempty" toml:"notifications,omitempty"`
+	// ReviewSyncInterval is how often the review comments of the pull
+	// requests opened by github agents are turned into tasks
+	ReviewSyncInterval Duration `json:"reviewSyncInterval,omitempty" yaml:"reviewSyncInterval,omitempty" toml:"reviewSyncInterval,omitempty"`
+
+	// RulesDir holds YAML rule files, relative to the config file
+	RulesDir string `json:"rulesDir,omitempty" yaml:"rulesDir,omitempty" toml:"rulesDir,omitempty"`
+	// ScratchDir holds a scratchpad directory per agent, relative to the
+	// config file. Files older than ScratchRetention are removed when the
+	// agent stops.
+	ScratchDir       string   `json:"scratchDir,omitempty" yaml:"scratchDir,omitempty" toml:"scratchDir,omitempty"`
+	ScratchRetention Duration `json:"scratchRetention,omitempty" yaml:"scratchRetention,omitempty" toml:"scratchRetention,omitempty"`
+	// ArtifactDir holds the diffs, reports and logs tasks attach, relative
+	// to the config file. Artifacts no task attached for ArtifactRetention
+	// are removed.
+	ArtifactDir       string   `json:"artifactDir,omitempty" yaml:"artifactDir,omitempty" toml:"artifactDir,omitempty"`
+	ArtifactRetention Duration `json:"artifactRetention,omitempty" yaml:"artifactRetention,omitempty" toml:"artifactRetention,omitempty"`
+	// DecisionsDir receives an architecture decision record in markdown
+	// of every decided vote, relative to the config file, e.g.
+	// docs/decisions
+	DecisionsDir string `json:"decisionsDir,omitempty" yaml:"decisionsDir,omitempty" toml:"decisionsDir,omitempty"`
+
+	LogPaths []string `json:"logPaths,omitempty" yaml:"logPaths,omitempty" toml:"logPaths,omitempty"`
+	// LogFormat is how log lines are parsed: plain (default), json, logfmt
+	// or multiline, which keeps stack traces with their entry
+	LogFormat string `json:"logFormat,omitempty" yaml:"logFormat,omitempty" toml:"logFormat,omitempty"`
+	// LogMetadata adds static fields to the entries of the log files a
+	// path pattern matches, so events of several services tell apart
+	LogMetadata  []LogMetadataFileConfig `json:"logMetadata,omitempty" yaml:"logMetadata,omitempty" toml:"logMetadata,omitempty"`
+	ShellHistory string                  `json:"shellHistory,omitempty" yaml:"shellHistory,omitempty" toml:"shellHistory,omitempty"`
+	// Reports makes daily or weekly activity reports
+	Reports ReportsFileConfig `json:"reports,omitempty" yaml:"reports,omitempty" toml:"reports,omitempty"`
+	// CIReports reads the test reports of CI runs, turning failures into
+	// memories and error handling tasks
+	CIReports CIReportsFileConfig `json:"ciReports,omitempty" yaml:"ciReports,omitempty" toml:"ciReports,omitempty"`
+
+	Memory MemoryFileConfig `json:"memory,omitempty" yaml:"memory,omitempty" toml:"memory,omitempty"`
+	// Encryption encrypts what the swarm keeps on disk
+	Encryption EncryptionFileConfig `json:"encryption,omitempty" yaml:"encryption,omitempty" toml:"encryption,omitempty"`
+
+	HealthCheckInterval   Duration `json:"healthCheckInterval,omitempty" yaml:"healthCheckInterval,omitempty" toml:"healthCheckInterval,omitempty"`
+	AlertThreshold        float64  `json:"alertThreshold,omitempty" yaml:"alertThreshold,omitempty" toml:"alertThreshold,omitempty"`
+	ConsolidationInterval Duration `json:"consolidationInterval,omitempty" yaml:"consolidationInterval,omitempty" toml:"consolidationInterval,omitempty"`
+	// AgentHealth passes over agents whose health scores too low
+	AgentHealth AgentHealthFileConfig `json:"agentHealth,omitempty" yaml:"agentHealth,omitempty" toml:"agentHealth,omitempty"`
+	// Watchdog watches running tasks for ones that seem stuck
+	Watchdog WatchdogFileConfig `json:"watchdog,omitempty" yaml:"watchdog,omitempty" toml:"watchdog,omitempty"`
+	// Heartbeat has agents send heartbeats, marking those that miss them
+	// unreachable
+	Heartbeat HeartbeatFileConfig `json:"heartbeat,omitempty" yaml:"heartbeat,omitempty" toml:"heartbeat,omitempty"`
+}
+
+// AgentHealthFileConfig configures which agents are passed over for their
+// health, see AgentHealthConfig
+type AgentHealthFileConfig struct {
+	MinScore   float64  `json:"minScore,omitempty" yaml:"minScore,omitempty" toml:"minScore,omitempty"`
+	RetryAfter Duration `json:"retryAfter,omitempty" yaml:"retryAfter,omitempty" toml:"retryAfter,omitempty"`
+}
+
+// WatchdogFileConfig configures the watchdog of stuck tasks, see
+// WatchdogConfig
+type WatchdogFileConfig struct {
+	Interval        Duration `json:"interval,omitempty" yaml:"interval,omitempty" toml:"interval,omitempty"`
+	Factor          float64  `json:"factor,omitempty" yaml:"factor,omitempty" toml:"factor,omitempty"`
+	DefaultExpected Duration `json:"defaultExpected,omitempty" yaml:"defaultExpected,omitempty" toml:"defaultExpected,omitempty"`
+	// Action is alert (default), cancel or retry
+	Action string `json:"action,omitempty" yaml:"action,omitempty" toml:"action,omitempty"`
+}
+
+// HeartbeatFileConfig configures the heartbeats of agents, see
+// HeartbeatConfig
+type HeartbeatFileConfig struct {
+	Interval Duration `json:"interval,omitempty" yaml:"interval,omitempty" toml:"interval,omitempty"`
+	Missed   int      `json:"missed,omitempty" yaml:"missed,omitempty" toml:"missed,omitempty"`
+}
+
+// ProviderFileConfig configures a model provider
+type ProviderFileConfig struct {
+	// Type is one of the supported providers, the provider name if empty
+	Type    string `json:"type,omitempty" yaml:"type,omitempty" toml:"type,omitempty"`
+	BaseURL string `json:"baseURL,omitempty" yaml:"baseURL,omitempty" toml:"baseURL,omitempty"`
+	APIKey  string `json:"apiKey,omitempty" yaml:"apiKey,omitempty" toml:"apiKey,omitempty"`
+}
+
+// AgentFileConfig configures one agent of the swarm
+type AgentFileConfig struct {
+	ID                  string   `json:"id" yaml:"id" toml:"id"`
+	Type                string   `json:"type" yaml:"type" toml:"type"`
+	Provider            string   `json:"provider,omitempty" yaml:"provider,omitempty" toml:"provider,omitempty"`
+	Model               string   `json:"model,omitempty" yaml:"model,omitempty" toml:"model,omitempty"`
+	MaxConcurrency      int      `json:"maxConcurrency,omitempty" yaml:"maxConcurrency,omitempty" toml:"maxConcurrency,omitempty"`
+	HealthCheckInterval Duration `json:"healthCheckInterval,omitempty" yaml:"healthCheckInterval,omitempty" toml:"healthCheckInterval,omitempty"`
+	EnableLearning      bool     `json:"enableLearning,omitempty" yaml:"enableLearning,omitempty" toml:"enableLearning,omitempty"`
+	Capabilities        []string `json:"capabilities,omitempty" yaml:"capabilities,omitempty" toml:"capabilities,omitempty"`
+	// Permissions replace the permissions the agent's type grants, see
+	// agent.RolePermissions
+	Permissions []string `json:"permissions,omitempty" yaml:"permissions,omitempty" toml:"permissions,omitempty"`
+	// Options configure the agent implementation, e.g. the test command of
+	// testing agents
+	Options map[string]string `json:"options,omitempty" yaml:"options,omitempty" toml:"options,omitempty"`
+	// Shadow only hands the agent copies of tasks and records its results
+	// next to those of the agent that ran them, to try a new model or
+	// provider safely
+	Shadow bool `json:"shadow,omitempty" yaml:"shadow,omitempty" toml:"shadow,omitempty"`
+}
+
+// WarmPoolFileConfig configures the warm pool of an agent type, see
+// WarmPoolConfig
+type WarmPoolFileConfig struct {
+	Size        int      `json:"size" yaml:"size" toml:"size"`
+	KeepAlive   Duration `json:"keepAlive,omitempty" yaml:"keepAlive,omitempty" toml:"keepAlive,omitempty"`
+	IdleTimeout Duration `json:"idleTimeout,omitempty" yaml:"idleTimeout,omitempty" toml:"idleTimeout,omitempty"`
+}
+
+// VerificationFileConfig configures the verification of the results of a
+// task type, see VerificationConfig
+type VerificationFileConfig struct {
+	// Reviewer is the agent type verifying results, e.g. testing
+	Reviewer string   `json:"reviewer" yaml:"reviewer" toml:"reviewer"`
+	Timeout  Duration `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+	Required bool     `json:"required,omitempty" yaml:"required,omitempty" toml:"required,omitempty"`
+}
+
+// PriceFileConfig is the price of a model, see TokenPrice
+type PriceFileConfig struct {
+	Input  float64 `json:"input" yaml:"input" toml:"input"`
+	Output float64 `json:"output" yaml:"output" toml:"output"`
+}
+
+// NotificationFileConfig configures a notification webhook, see
+// notify.Config
+type NotificationFileConfig struct {
+	// Type is slack or discord
+	Type    string `json:"type" yaml:"type" toml:"type"`
+	Webhook string `json:"webhook" yaml:"webhook" toml:"webhook"`
+	// Events are the kinds of notifications posted, alert, vote and
+	// digest, all if empty
+	Events      []string `json:"events,omitempty" yaml:"events,omitempty" toml:"events,omitempty"`
+	MinSeverity string   `json:"minSeverity,omitempty" yaml:"minSeverity,omitempty" toml:"minSeverity,omitempty"`
+	// DigestAt is the local time of day of the digest, as 15:04
+	DigestAt   string   `json:"digestAt,omitempty" yaml:"digestAt,omitempty" toml:"digestAt,omitempty"`
+	RateLimit  int      `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty" toml:"rateLimit,omitempty"`
+	RatePeriod Duration `json:"ratePeriod,omitempty" yaml:"ratePeriod,omitempty" toml:"ratePeriod,omitempty"`
+	// Templates are Go text templates replacing the default messages, by
+	// kind of notification
+	Templates map[string]string `json:"templates,omitempty" yaml:"templates,omitempty" toml:"templates,omitempty"`
+}
+
+// ReportsFileConfig schedules activity reports, see ReportConfig
+type ReportsFileConfig struct {
+	Daily  bool `json:"daily,omitempty" yaml:"daily,omitempty" toml:"daily,omitempty"`
+	Weekly bool `json:"weekly,omitempty" yaml:"weekly,omitempty" toml:"weekly,omitempty"`
+	// At is the local time of day of the reports, as 15:04, midnight if
+	// empty
+	At string `json:"at,omitempty" yaml:"at,omitempty" toml:"at,omitempty"`
+	// Weekday is the day of the weekly report, monday if empty
+	Weekday string `json:"weekday,omitempty" yaml:"weekday,omitempty" toml:"weekday,omitempty"`
+	// Format is markdown (default) or html
+	Format string `json:"format,omitempty" yaml:"format,omitempty" toml:"format,omitempty"`
+	// Dir receives the reports as files, relative to the config file
+	Dir string `json:"dir,omitempty" yaml:"dir,omitempty" toml:"dir,omitempty"`
+	// Webhook receives the reports as the body of POST requests
+	Webhook string `json:"webhook,omitempty" yaml:"webhook,omitempty" toml:"webhook,omitempty"`
+}
+
+// LogMetadataFileConfig are the static fields of the log files matched by
+// a path pattern, see monitor.LogMetadata
+type LogMetadataFileConfig struct {
+	Path        string `json:"path" yaml:"path" toml:"path"`
+	Service     string `json:"service,omitempty" yaml:"service,omitempty" toml:"service,omitempty"`
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty" toml:"environment,omitempty"`
+	Host        string `json:"host,omitempty" yaml:"host,omitempty" toml:"host,omitempty"`
+	// Fields are any other fields, by name
+	Fields map[string]string `json:"fields,omitempty" yaml:"fields,omitempty" toml:"fields,omitempty"`
+}
+
+// CIReportsFileConfig configures the CI report watcher, see
+// monitor.CIReportWatcherConfig
+type CIReportsFileConfig struct {
+	// Dir is watched for JUnit XML and go test -json files, relative to
+	// the config file
+	Dir string `json:"dir,omitempty" yaml:"dir,omitempty" toml:"dir,omitempty"`
+	// URLs are fetched for reports each interval
+	URLs     []string `json:"urls,omitempty" yaml:"urls,omitempty" toml:"urls,omitempty"`
+	Interval Duration `json:"interval,omitempty" yaml:"interval,omitempty" toml:"interval,omitempty"`
+}
+
+// MemoryFileConfig configures the memory store
+type MemoryFileConfig struct {
+	// Backend selects the store, only "memory" for now
+	Backend        string   `json:"backend,omitempty" yaml:"backend,omitempty" toml:"backend,omitempty"`
+	MaxMemories    int      `json:"maxMemories,omitempty" yaml:"maxMemories,omitempty" toml:"maxMemories,omitempty"`
+	PruneOlderThan Duration `json:"pruneOlderThan,omitempty" yaml:"pruneOlderThan,omitempty" toml:"pruneOlderThan,omitempty"`
+	// CompactThreshold is how many identical log memories consolidation
+	// compacts into one counted memory
+	CompactThreshold int `json:"compactThreshold,omitempty" yaml:"compactThreshold,omitempty" toml:"compactThreshold,omitempty"`
+	// EncryptionKey is an AES key of 16, 24 or 32 bytes
+	EncryptionKey string `json:"encryptionKey,omitempty" yaml:"encryptionKey,omitempty" toml:"encryptionKey,omitempty"`
+	// MonitorWrites batches the memories of log entries and shell commands
+	MonitorWrites BatchFileConfig `json:"monitorWrites,omitempty" yaml:"monitorWrites,omitempty" toml:"monitorWrites,omitempty"`
+	// Promotion queues memories that helped tasks succeed for the global
+	// namespace
+	Promotion PromotionFileConfig `json:"promotion,omitempty" yaml:"promotion,omitempty" toml:"promotion,omitempty"`
+	// Tagging normalizes and adds tags to stored memories
+	Tagging TaggingFileConfig `json:"tagging,omitempty" yaml:"tagging,omitempty" toml:"tagging,omitempty"`
+	// Quotas cap the memories of a type, by type
+	Quotas map[string]QuotaFileConfig `json:"quotas,omitempty" yaml:"quotas,omitempty" toml:"quotas,omitempty"`
+	// PruneVote makes prunes deleting many memories wait for a vote
+	PruneVote PruneVoteFileConfig `json:"pruneVote,omitempty" yaml:"pruneVote,omitempty" toml:"pruneVote,omitempty"`
+}
+
+// PruneVoteFileConfig configures votes on prunes, see PruneVoteConfig
+type PruneVoteFileConfig struct {
+	MinCount    int      `json:"minCount,omitempty" yaml:"minCount,omitempty" toml:"minCount,omitempty"`
+	MinFraction float64  `json:"minFraction,omitempty" yaml:"minFraction,omitempty" toml:"minFraction,omitempty"`
+	Timeout     Duration `json:"timeout,omitempty" yaml:"timeout,omitempty" toml:"timeout,omitempty"`
+}
+
+// QuotaFileConfig caps the memories of a type, see memory.TypeQuota
+type QuotaFileConfig struct {
+	Max int `json:"max" yaml:"max" toml:"max"`
+	// Eviction is "oldest", "lru" or "priority"
+	Eviction string `json:"eviction,omitempty" yaml:"eviction,omitempty" toml:"eviction,omitempty"`
+}
+
+// TaggingFileConfig configures memory tagging, see memory.TaggingConfig
+type TaggingFileConfig struct {
+	Enabled bool `json:"enabled,omitempty" yaml:"enabled,omitempty" toml:"enabled,omitempty"`
+	// Rules add keyword rules to memory.DefaultTagRules, by tag
+	Rules map[string][]string `json:"rules,omitempty" yaml:"rules,omitempty" toml:"rules,omitempty"`
+	// Aliases add to memory.DefaultTagAliases
+	Aliases map[string]string `json:"aliases,omitempty" yaml:"aliases,omitempty" toml:"aliases,omitempty"`
+	// Classifier, if set, asks a model to pick tags for each memory not
+	// stored in a batch
+	Classifier *ClassifierFileConfig `json:"classifier,omitempty" yaml:"classifier,omitempty" toml:"classifier,omitempty"`
+}
+
+// ClassifierFileConfig selects the model classifying memories
+type ClassifierFileConfig struct {
+	Provider string `json:"provider" yaml:"provider" toml:"provider"`
+	Model    string `json:"model" yaml:"model" toml:"model"`
+}
+
+// PromotionFileConfig configures memory promotion, see PromotionConfig
+type PromotionFileConfig struct {
+	MinUses        int     `json:"minUses,omitempty" yaml:"minUses,omitempty" toml:"minUses,omitempty"`
+	MinSuccessRate float64 `json:"minSuccessRate,omitempty" yaml:"minSuccessRate,omitempty" toml:"minSuccessRate,omitempty"`
+}
+
+// BatchFileConfig configures batched memory writes, see memory.BatchConfig
+type BatchFileConfig struct {
+	FlushInterval Duration `json:"flushInterval,omitempty" yaml:"flushInterval,omitempty" toml:"flushInterval,omitempty"`
+	MaxBatch      int      `json:"maxBatch,omitempty" yaml:"maxBatch,omitempty" toml:"maxBatch,omitempty"`
+	MaxPerSecond  float64  `json:"maxPerSecond,omitempty" yaml:"maxPerSecond,omitempty" toml:"maxPerSecond,omitempty"`
+	QueueSize     int      `json:"queueSize,omitempty" yaml:"queueSize,omitempty" toml:"queueSize,omitempty"`
+	// Drop is "newest" or "oldest", the memories dropped from a full queue
+	Drop string `json:"drop,omitempty" yaml:"drop,omitempty" toml:"drop,omitempty"`
+}
+
+// EncryptionFileConfig configures the encryption of the artifacts and
+// recordings of the swarm
+type EncryptionFileConfig struct {
+	// AtRest encrypts them with the master key, and memories marked
+	// encrypted too unless memory.encryptionKey is set
+	AtRest bool `json:"atRest,omitempty" yaml:"atRest,omitempty" toml:"atRest,omitempty"`
+	// KeyEnv names the environment variable holding the master key,
+	// OPENCODE_SWARM_KEY by default. Without it the key is read from the
+	// OS keychain.
+	KeyEnv string `json:"keyEnv,omitempty" yaml:"keyEnv,omitempty" toml:"keyEnv,omitempty"`
+}
+
+// logFormats are the formats the log watcher parses
+var logFormats = []string{monitor.FormatPlain, monitor.FormatJSON, monitor.FormatLogfmt, monitor.FormatMultiline}
+
+// memoryBackends are the supported memory stores
+var memoryBackends = []string{"memory"}
+
+var agentTypes = []agent.AgentType{
+	agent.AgentTypeCoordinator,
+	agent.AgentTypeMonitor,
+	agent.AgentTypeAnalyzer,
+	agent.AgentTypeExecutor,
+	agent.AgentTypeMemory,
+	agent.AgentTypeLearning,
+	agent.AgentTypeDocumentation,
+	agent.AgentTypeTesting,
+	agent.AgentTypeErrorHandler,
+	agent.AgentTypeHealthChecker,
+	agent.AgentTypeSecurity,
+	agent.AgentTypeWeb,
+	agent.AgentTypeGitHub,
+}
+
+// LoadFileConfig reads a swarm configuration file. The format follows the
+// extension: .json, .yaml, .yml or .toml. References to environment
+// variables as ${NAME} or ${NAME:-default} are expanded before parsing, and
+// the result is validated.
+func LoadFileConfig(path string) (FileConfig, error) {
+	var cfg FileConfig
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read swarm config: %w", err)
+	}
+	data, err = expandEnv(data)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid swarm config %s: %w", path, err)
+	}
+	if err := decodeFileConfig(filepath.Ext(path), data, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid swarm config %s: %w", path, err)
+	}
+
+	if cfg.API == "" {
+		cfg.API = DefaultAPIAddress
+	}
+	if cfg.RulesDir != "" && !filepath.IsAbs(cfg.RulesDir) {
+		cfg.RulesDir = filepath.Join(filepath.Dir(path), cfg.RulesDir)
+	}
+	if cfg.ScratchDir != "" && !filepath.IsAbs(cfg.ScratchDir) {
+		cfg.ScratchDir = filepath.Join(filepath.Dir(path), cfg.ScratchDir)
+	}
+	if cfg.ArtifactDir != "" && !filepath.IsAbs(cfg.ArtifactDir) {
+		cfg.ArtifactDir = filepath.Join(filepath.Dir(path), cfg.ArtifactDir)
+	}
+	if cfg.DecisionsDir != "" && !filepath.IsAbs(cfg.DecisionsDir) {
+		cfg.DecisionsDir = filepath.Join(filepath.Dir(path), cfg.DecisionsDir)
+	}
+	if cfg.Reports.Dir != "" && !filepath.IsAbs(cfg.Reports.Dir) {
+		cfg.Reports.Dir = filepath.Join(filepath.Dir(path), cfg.Reports.Dir)
+	}
+	if cfg.CIReports.Dir != "" && !filepath.IsAbs(cfg.CIReports.Dir) {
+		cfg.CIReports.Dir = filepath.Join(filepath.Dir(path), cfg.CIReports.Dir)
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("invalid swarm config %s:\n%w", path, err)
+	}
+	return cfg, nil
+}
+
+// decodeFileConfig parses data in the format of ext, rejecting unknown
+// fields so typos do not go unnoticed
+func decodeFileConfig(ext string, data []byte, cfg *FileConfig) error {
+	switch strings.ToLower(ext) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		return dec.Decode(cfg)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		return nil
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil {
+			var strict *toml.StrictMissingError
+			if errors.As(err, &strict) {
+				var errs []error
+				for _, e := range strict.Errors {
+					line, _ := e.Position()
+					errs = append(errs, fmt.Errorf("unknown field %q on line %d", strings.Join(e.Key(), "."), line))
+				}
+				return errors.Join(errs...)
+			}
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q, use .json, .yaml, .yml or .toml", ext)
+	}
+}
+
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnv replaces ${NAME} and ${NAME:-default} with the value of the
+// environment variable. Variables that are unset and have no default are
+// reported together.
+func expandEnv(data []byte) ([]byte, error) {
+	var missing []string
+	expanded := envReference.ReplaceAllFunc(data, func(ref []byte) []byte {
+		m := envReference.FindSubmatch(ref)
+		if value, ok := os.LookupEnv(string(m[1])); ok {
+			return []byte(value)
+		}
+		if m[2] != nil {
+			return m[3]
+		}
+		missing = append(missing, string(m[1]))
+		return ref
+	})
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("environment variables not set: %s", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// Validate reports every problem of the configuration at once
+func (f FileConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(f.VotingThreshold >= 0 && f.VotingThreshold <= 1, "votingThreshold must be between 0 and 1")
+	check(f.AlertThreshold >= 0 && f.AlertThreshold <= 1, "alertThreshold must be between 0 and 1")
+	check(f.SelectionJitter >= 0, "selectionJitter cannot be negative")
+	check(f.AgentHealth.MinScore >= 0 && f.AgentHealth.MinScore <= 1, "agentHealth.minScore must be between 0 and 1")
+	check(f.AgentHealth.RetryAfter >= 0, "agentHealth.retryAfter cannot be negative")
+	check(f.Watchdog.Interval >= 0, "watchdog.interval cannot be negative")
+	check(f.Watchdog.Factor == 0 || f.Watchdog.Factor >= 1, "watchdog.factor must be at least 1")
+	check(f.Watchdog.DefaultExpected >= 0, "watchdog.defaultExpected cannot be negative")
+	if err := ValidateStuckAction(StuckAction(f.Watchdog.Action)); err != nil {
+		errs = append(errs, fmt.Errorf("watchdog.action: %w", err))
+	}
+	check(f.Heartbeat.Interval >= 0, "heartbeat.interval cannot be negative")
+	check(f.Heartbeat.Missed >= 0, "heartbeat.missed cannot be negative")
+	check(f.MaxConcurrentTasks >= 0, "maxConcurrentTasks cannot be negative")
+	check(f.TaskQueueSize >= 0, "taskQueueSize cannot be negative")
+	check(f.IdempotencyWindow >= 0, "idempotencyWindow cannot be negative")
+	check(f.HealthCheckInterval >= 0, "healthCheckInterval cannot be negative")
+	check(f.ConsolidationInterval >= 0, "consolidationInterval cannot be negative")
+	check(f.ScratchRetention >= 0, "scratchRetention cannot be negative")
+	check(f.ArtifactRetention >= 0, "artifactRetention cannot be negative")
+	check(f.ReviewSyncInterval >= 0, "reviewSyncInterval cannot be negative")
+
+	providers := make([]string, 0, len(f.Providers))
+	for name := range f.Providers {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+	for _, name := range providers {
+		typ := f.Providers[name].providerType(name)
+		check(provider.Supported(typ), "provider %s: unknown type %q, expected one of %s", name, typ, strings.Join(provider.Types(), ", "))
+	}
+
+	seen := make(map[string]bool)
+	for i, a := range f.Agents {
+		label := fmt.Sprintf("agent %d", i+1)
+		if a.ID != "" {
+			label = "agent " + a.ID
+		}
+		check(a.ID != "", "%s: id is required", label)
+		check(a.ID == "" || !seen[a.ID], "%s: duplicate id", label)
+		seen[a.ID] = true
+		check(isAgentType(a.Type), "%s: unknown type %q", label, a.Type)
+		if a.Provider != "" {
+			_, defined := f.Providers[a.Provider]
+			check(defined || provider.Supported(a.Provider), "%s: unknown provider %q", label, a.Provider)
+		}
+		check(a.MaxConcurrency >= 0, "%s: maxConcurrency cannot be negative", label)
+		for _, p := range a.Permissions {
+			if err := agent.ValidatePermission(agent.Permission(p)); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", label, err))
+			}
+		}
+		if !agent.HasFactory(agent.AgentType(a.Type)) {
+			check(a.Provider != "" && a.Model != "", "%s: provider and model are required for %s agents", label, a.Type)
+		}
+	}
+
+	pools := make([]string, 0, len(f.WarmPools))
+	for typ := range f.WarmPools {
+		pools = append(pools, typ)
+	}
+	sort.Strings(pools)
+	for _, typ := range pools {
+		pool := f.WarmPools[typ]
+		label := "warmPools." + typ
+		configured := false
+		for _, a := range f.Agents {
+			configured = configured || (a.Type == typ && !a.Shadow)
+		}
+		check(configured, "%s: no agent of type %q is configured", label, typ)
+		check(pool.Size >= 0, "%s: size cannot be negative", label)
+		check(pool.KeepAlive >= 0, "%s: keepAlive cannot be negative", label)
+		check(pool.IdleTimeout >= 0, "%s: idleTimeout cannot be negative", label)
+	}
+	for _, typ := range slices.Sorted(maps.Keys(f.Verification)) {
+		verification := f.Verification[typ]
+		label := "verification." + typ
+		check(verification.Reviewer != "", "%s: reviewer is required", label)
+		check(verification.Timeout >= 0, "%s: timeout cannot be negative", label)
+		if verification.Reviewer == "" {
+			continue
+		}
+		configured := false
+		for _, a := range f.Agents {
+			configured = configured || a.Type == verification.Reviewer || slices.Contains(a.Capabilities, verification.Reviewer)
+		}
+		check(configured, "%s: no agent of type %q is configured", label, verification.Reviewer)
+	}
+	for _, model := range slices.Sorted(maps.Keys(f.Prices)) {
+		price := f.Prices[model]
+		check(price.Input >= 0 && price.Output >= 0, "prices.%s: prices cannot be negative", model)
+	}
+	for _, name := range slices.Sorted(maps.Keys(f.Notifications)) {
+		config, err := f.Notifications[name].notifyConfig(name)
+		if err == nil {
+			err = config.Validate()
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notifications.%s: %w", name, err))
+		}
+	}
+
+	if f.RulesDir != "" {
+		if defs, err := rules.LoadDefinitionDir(f.RulesDir); err != nil {
+			errs = append(errs, fmt.Errorf("rulesDir: %w", err))
+		} else {
+			for _, def := range defs {
+				if err := def.Validate(); err != nil {
+					errs = append(errs, fmt.Errorf("rulesDir: %w", err))
+				}
+			}
+		}
+	}
+
+	for _, p := range f.LogPaths {
+		check(strings.TrimSpace(p) != "", "logPaths cannot contain empty paths")
+	}
+	check(f.LogFormat == "" || contains(logFormats, f.LogFormat), "logFormat: unknown format %q, expected one of %s", f.LogFormat, strings.Join(logFormats, ", "))
+	for i, m := range f.LogMetadata {
+		if strings.TrimSpace(m.Path) == "" {
+			check(false, "logMetadata[%d]: path is required", i)
+		} else if _, err := filepath.Match(m.Path, ""); err != nil {
+			check(false, "logMetadata[%d]: invalid path pattern %q", i, m.Path)
+		}
+		check(len(logMetadataFields(m)) > 0, "logMetadata[%d]: no fields for %s", i, m.Path)
+	}
+	if _, err := f.Reports.reportConfig(); err != nil {
+		errs = append(errs, fmt.Errorf("reports.%w", err))
+	}
+	for _, u := range f.CIReports.URLs {
+		check(strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://"), "ciReports.urls: %q is not an http or https URL", u)
+	}
+	check(f.CIReports.Interval >= 0, "ciReports.interval cannot be negative")
+
+	backend := f.Memory.Backend
+	check(backend == "" || contains(memoryBackends, backend), "memory.backend: unknown backend %q, expected one of %s", backend, strings.Join(memoryBackends, ", "))
+	check(f.Memory.MaxMemories >= 0, "memory.maxMemories cannot be negative")
+	check(f.Memory.PruneOlderThan >= 0, "memory.pruneOlderThan cannot be negative")
+	check(f.Memory.CompactThreshold >= 0, "memory.compactThreshold cannot be negative")
+	switch len(f.Memory.EncryptionKey) {
+	case 0, 16, 24, 32:
+	default:
+		errs = append(errs, fmt.Errorf("memory.encryptionKey must be 16, 24 or 32 bytes long"))
+	}
+	batch := f.Memory.MonitorWrites
+	check(batch.FlushInterval >= 0, "memory.monitorWrites.flushInterval cannot be negative")
+	check(batch.MaxBatch >= 0, "memory.monitorWrites.maxBatch cannot be negative")
+	check(batch.MaxPerSecond >= 0, "memory.monitorWrites.maxPerSecond cannot be negative")
+	check(batch.QueueSize >= 0, "memory.monitorWrites.queueSize cannot be negative")
+	if err := memory.ValidateDropPolicy(memory.DropPolicy(batch.Drop)); err != nil {
+		errs = append(errs, fmt.Errorf("memory.monitorWrites.drop: %w", err))
+	}
+	tagging := f.Memory.Tagging
+	for _, tag := range slices.Sorted(maps.Keys(tagging.Rules)) {
+		check(memory.NormalizeTag(tag) != "", "memory.tagging.rules: tags cannot be empty")
+		check(len(tagging.Rules[tag]) > 0, "memory.tagging.rules.%s: no keywords", tag)
+	}
+	if c := tagging.Classifier; c != nil {
+		_, defined := f.Providers[c.Provider]
+		check(defined || provider.Supported(c.Provider), "memory.tagging.classifier: unknown provider %q", c.Provider)
+		check(c.Model != "", "memory.tagging.classifier: model is required")
+		check(tagging.Enabled, "memory.tagging.classifier: tagging is not enabled")
+	}
+	for _, name := range slices.Sorted(maps.Keys(f.Memory.Quotas)) {
+		quota := f.Memory.Quotas[name]
+		check(slices.Contains(memory.MemoryTypes, memory.MemoryType(name)), "memory.quotas: unknown memory type %q", name)
+		check(quota.Max > 0, "memory.quotas.%s.max must be positive", name)
+		if err := memory.ValidateEvictionPolicy(memory.EvictionPolicy(quota.Eviction)); err != nil {
+			errs = append(errs, fmt.Errorf("memory.quotas.%s.eviction: %w", name, err))
+		}
+	}
+	pruneVote := f.Memory.PruneVote
+	check(pruneVote.MinCount >= 0, "memory.pruneVote.minCount cannot be negative")
+	check(pruneVote.MinFraction >= 0 && pruneVote.MinFraction <= 1, "memory.pruneVote.minFraction must be between 0 and 1")
+	check(pruneVote.Timeout >= 0, "memory.pruneVote.timeout cannot be negative")
+	promotion := f.Memory.Promotion
+	check(promotion.MinUses >= 0, "memory.promotion.minUses cannot be negative")
+	check(promotion.MinSuccessRate >= 0 && promotion.MinSuccessRate <= 1, "memory.promotion.minSuccessRate must be between 0 and 1")
+
+	return errors.Join(errs...)
+}
+
+func (p ProviderFileConfig) providerType(name string) string {
+	if p.Type != "" {
+		return p.Type
+	}
+	return name
+}
+
+// permissions converts configured permissions, keeping nil so agents
+// without any configured get the permissions of their role
+func permissions(names []string) []agent.Permission {
+	if names == nil {
+		return nil
+	}
+	permissions := make([]agent.Permission, len(names))
+	for i, name := range names {
+		permissions[i] = agent.Permission(name)
+	}
+	return permissions
+}
+
+func isAgentType(t string) bool {
+	for _, known := range agentTypes {
+		if string(known) == t {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// agentConfig converts the configuration of one agent. Named providers
+// resolve to their type, with the connection details passed along for the
+// agent.
+func (f FileConfig) agentConfig(a AgentFileConfig) agent.AgentConfig {
+	cfg := agent.AgentConfig{
+		ID:                  a.ID,
+		Type:                agent.AgentType(a.Type),
+		ProviderType:        a.Provider,
+		Model:               a.Model,
+		MaxConcurrency:      a.MaxConcurrency,
+		HealthCheckInterval: time.Duration(a.HealthCheckInterval),
+		EnableLearning:      a.EnableLearning,
+		Capabilities:        a.Capabilities,
+		Permissions:         permissions(a.Permissions),
+		Shadow:              a.Shadow,
+		ScratchRetention:    time.Duration(f.ScratchRetention),
+		Secrets:             vault.NewSecrets(vault.DefaultSecretsFile()),
+	}
+	if f.ScratchDir != "" {
+		cfg.ScratchDir = agent.ScratchDir(f.ScratchDir, a.ID)
+	}
+	if p, ok := f.Providers[a.Provider]; ok {
+		cfg.ProviderType = p.providerType(a.Provider)
+		cfg.CustomConfig = map[string]interface{}{
+			"provider": a.Provider,
+			"baseURL":  p.BaseURL,
+			"apiKey":   p.APIKey,
+		}
+	}
+	for key, value := range a.Options {
+		if cfg.CustomConfig == nil {
+			cfg.CustomConfig = make(map[string]interface{}, len(a.Options))
+		}
+		cfg.CustomConfig[key] = value
+	}
+	return cfg
+}
+
+// Sealer returns the sealer encrypting the data of the swarm at rest, nil
+// unless encryption.atRest is set
+func (f FileConfig) Sealer() (*vault.Sealer, error) {
+	if !f.Encryption.AtRest {
+		return nil, nil
+	}
+	key, err := vault.MasterKey(f.Encryption.KeyEnv)
+	if err != nil {
+		return nil, err
+	}
+	return vault.NewSealer(key)
+}
+
+// CoordinatorConfig converts the file configuration for NewCoordinator.
+// Set the Sealer of the result from Sealer to encrypt at rest.
+func (f FileConfig) CoordinatorConfig() CoordinatorConfig {
+	var key []byte
+	if f.Memory.EncryptionKey != "" {
+		key = []byte(f.Memory.EncryptionKey)
+	}
+
+	agents := make([]agent.AgentConfig, 0, len(f.Agents))
+	for _, a := range f.Agents {
+		agents = append(agents, f.agentConfig(a))
+	}
+	var pools map[agent.AgentType]WarmPoolConfig
+	for typ, pool := range f.WarmPools {
+		if pools == nil {
+			pools = make(map[agent.AgentType]WarmPoolConfig, len(f.WarmPools))
+		}
+		pools[agent.AgentType(typ)] = WarmPoolConfig{
+			Size:        pool.Size,
+			KeepAlive:   time.Duration(pool.KeepAlive),
+			IdleTimeout: time.Duration(pool.IdleTimeout),
+		}
+	}
+
+	// Validate reports the notifications that do not convert
+	var notifications []notify.Config
+	for _, name := range slices.Sorted(maps.Keys(f.Notifications)) {
+		if config, err := f.Notifications[name].notifyConfig(name); err == nil {
+			notifications = append(notifications, config)
+		}
+	}
+
+	// Validate reports a schedule that does not convert
+	reports, _ := f.Reports.reportConfig()
+
+	var verification map[string]VerificationConfig
+	for typ, v := range f.Verification {
+		if verification == nil {
+			verification = make(map[string]VerificationConfig, len(f.Verification))
+		}
+		verification[typ] = VerificationConfig{
+			Reviewer: agent.AgentType(v.Reviewer),
+			Timeout:  time.Duration(v.Timeout),
+			Required: v.Required,
+		}
+	}
+
+	var prices map[string]TokenPrice
+	for model, p := range f.Prices {
+		if prices == nil {
+			prices = make(map[string]TokenPrice, len(f.Prices))
+		}
+		prices[model] = TokenPrice{Input: p.Input, Output: p.Output}
+	}
+
+	return CoordinatorConfig{
+		SwarmConfig: agent.SwarmConfig{
+			Name:                f.Name,
+			Agents:              agents,
+			VotingThreshold:     f.VotingThreshold,
+			MaxConcurrentTasks:  f.MaxConcurrentTasks,
+			HealthCheckInterval: time.Duration(f.HealthCheckInterval),
+		},
+		SelectionJitter: f.SelectionJitter,
+		MemoryConfig: memory.HierarchicalMemoryConfig{
+			MaxMemories:      f.Memory.MaxMemories,
+			PruneOlderThan:   time.Duration(f.Memory.PruneOlderThan),
+			EncryptionKey:    key,
+			Tagging:          f.Memory.Tagging.taggingConfig(),
+			CompactThreshold: f.Memory.CompactThreshold,
+			Quotas:           f.Memory.quotas(),
+		},
+		TagClassifier: f.tagClassifier(),
+		HealthConfig: health.HealthMonitorConfig{
+			CheckInterval:  time.Duration(f.HealthCheckInterval),
+			AlertThreshold: f.AlertThreshold,
+		},
+		LogPaths:              f.LogPaths,
+		LogFormat:             f.LogFormat,
+		LogMetadata:           logMetadata(f.LogMetadata),
+		ShellHistory:          f.ShellHistory,
+		CIReports:             f.CIReports.watcherConfig(),
+		Reports:               reports,
+		TaskQueueSize:         f.TaskQueueSize,
+		IdempotencyWindow:     time.Duration(f.IdempotencyWindow),
+		ConsolidationInterval: time.Duration(f.ConsolidationInterval),
+		RulesDir:              f.RulesDir,
+		ArtifactDir:           f.ArtifactDir,
+		ArtifactRetention:     time.Duration(f.ArtifactRetention),
+		DecisionsDir:          f.DecisionsDir,
+		MonitorWrites:         f.Memory.MonitorWrites.batchConfig(),
+		WarmPools:             pools,
+		Promotion:             f.Memory.Promotion.promotionConfig(),
+		PruneVote:             f.Memory.PruneVote.pruneVoteConfig(),
+		AgentHealth:           f.AgentHealth.agentHealthConfig(),
+		Watchdog:              f.Watchdog.watchdogConfig(),
+		Heartbeat:             f.Heartbeat.heartbeatConfig(),
+		Verification:          verification,
+		Prices:                prices,
+		DryRun:                f.DryRun,
+		Notifications:         notifications,
+		ReviewSyncInterval:    time.Duration(f.ReviewSyncInterval),
+	}
+}
+
+// quotas converts the type quotas, nil if there are none
+func (m MemoryFileConfig) quotas() map[memory.MemoryType]memory.TypeQuota {
+	if len(m.Quotas) == 0 {
+		return nil
+	}
+	quotas := make(map[memory.MemoryType]memory.TypeQuota, len(m.Quotas))
+	for name, q := range m.Quotas {
+		quotas[memory.MemoryType(name)] = memory.TypeQuota{Max: q.Max, Eviction: memory.EvictionPolicy(q.Eviction)}
+	}
+	return quotas
+}
+
+// taggingConfig adds the configured rules and aliases to the defaults, nil
+// unless tagging is enabled
+func (t TaggingFileConfig) taggingConfig() *memory.TaggingConfig {
+	if !t.Enabled {
+		return nil
+	}
+	config := &memory.TaggingConfig{
+		Rules:   slices.Clone(memory.DefaultTagRules),
+		Aliases: maps.Clone(memory.DefaultTagAliases),
+	}
+	for _, tag := range slices.Sorted(maps.Keys(t.Rules)) {
+		config.Rules = append(config.Rules, memory.TagRule{Tag: memory.NormalizeTag(tag), Keywords: t.Rules[tag]})
+	}
+	for alias, tag := range t.Aliases {
+		config.Aliases[memory.NormalizeTag(alias)] = memory.NormalizeTag(tag)
+	}
+	return config
+}
+
+// tagClassifier returns the provider of the tag classifier, resolved like
+// the providers of agents
+func (f FileConfig) tagClassifier() *provider.Config {
+	c := f.Memory.Tagging.Classifier
+	if !f.Memory.Tagging.Enabled || c == nil {
+		return nil
+	}
+	cfg := &provider.Config{
+		Type:       c.Provider,
+		Model:      c.Model,
+		Secrets:    vault.NewSecrets(vault.DefaultSecretsFile()),
+		SecretName: c.Provider,
+	}
+	if p, ok := f.Providers[c.Provider]; ok {
+		cfg.Type = p.providerType(c.Provider)
+		cfg.BaseURL = p.BaseURL
+		cfg.APIKey = p.APIKey
+	}
+	return cfg
+}
+
+func (p PromotionFileConfig) promotionConfig() PromotionConfig {
+	return PromotionConfig{MinUses: p.MinUses, MinSuccessRate: p.MinSuccessRate}
+}
+
+func (p PruneVoteFileConfig) pruneVoteConfig() PruneVoteConfig {
+	return PruneVoteConfig{MinCount: p.MinCount, MinFraction: p.MinFraction, Timeout: time.Duration(p.Timeout)}
+}
+
+func (a AgentHealthFileConfig) agentHealthConfig() AgentHealthConfig {
+	return AgentHealthConfig{MinScore: a.MinScore, RetryAfter: time.Duration(a.RetryAfter)}
+}
+
+// notifyConfig converts the configuration of a notification webhook
+func (n NotificationFileConfig) notifyConfig(name string) (notify.Config, error) {
+	config := notify.Config{
+		Name:        name,
+		Type:        notify.Platform(n.Type),
+		URL:         n.Webhook,
+		MinSeverity: n.MinSeverity,
+		RateLimit:   n.RateLimit,
+		RatePeriod:  time.Duration(n.RatePeriod),
+	}
+	for _, event := range n.Events {
+		config.Kinds = append(config.Kinds, notify.Kind(event))
+	}
+	if len(n.Templates) > 0 {
+		config.Templates = make(map[notify.Kind]string, len(n.Templates))
+		for kind, text := range n.Templates {
+			config.Templates[notify.Kind(kind)] = text
+		}
+	}
+	if n.DigestAt != "" {
+		at, err := time.Parse("15:04", n.DigestAt)
+		if err != nil {
+			return config, errors.New("digestAt must be a time of day such as \"09:00\"")
+		}
+		config.DigestAt = time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute
+	}
+	return config, nil
+}
+
+// weekdays are the days of weekly reports, by name
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// reportConfig converts the report schedule and its sinks
+func (r ReportsFileConfig) reportConfig() (ReportConfig, error) {
+	var config ReportConfig
+	var at time.Duration
+	if r.At != "" {
+		t, err := time.Parse("15:04", r.At)
+		if err != nil {
+			return config, errors.New("at must be a time of day such as \"08:00\"")
+		}
+		at = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	weekday := time.Monday
+	if r.Weekday != "" {
+		day, ok := weekdays[strings.ToLower(r.Weekday)]
+		if !ok {
+			return config, fmt.Errorf("weekday: unknown day %q", r.Weekday)
+		}
+		weekday = day
+	}
+	format := ReportFormat(r.Format)
+	switch format {
+	case "":
+		format = ReportMarkdown
+	case ReportMarkdown, ReportHTML:
+	default:
+		return config, fmt.Errorf("format: unknown format %q, expected %s or %s", r.Format, ReportMarkdown, ReportHTML)
+	}
+	if r.Webhook != "" && !strings.HasPrefix(r.Webhook, "http://") && !strings.HasPrefix(r.Webhook, "https://") {
+		return config, fmt.Errorf("webhook: %q is not an http or https URL", r.Webhook)
+	}
+
+	if r.Daily {
+		config.Schedules = append(config.Schedules, ReportSchedule{Period: ReportDaily, At: at})
+	}
+	if r.Weekly {
+		config.Schedules = append(config.Schedules, ReportSchedule{Period: ReportWeekly, At: at, Weekday: weekday})
+	}
+	if r.Dir != "" {
+		config.Sinks = append(config.Sinks, ReportDirSink{Dir: r.Dir, Format: format})
+	}
+	if r.Webhook != "" {
+		config.Sinks = append(config.Sinks, ReportWebhookSink{URL: r.Webhook, Format: format})
+	}
+	return config, nil
+}
+
+// logMetadata converts the static fields of log files
+func logMetadata(configs []LogMetadataFileConfig) []monitor.LogMetadata {
+	var metadata []monitor.LogMetadata
+	for _, m := range configs {
+		metadata = append(metadata, monitor.LogMetadata{Pattern: m.Path, Fields: logMetadataFields(m)})
+	}
+	return metadata
+}
+
+// logMetadataFields merges the service, environment and host of log files
+// into their other fields
+func logMetadataFields(m LogMetadataFileConfig) map[string]string {
+	fields := make(map[string]string, len(m.Fields)+3)
+	for key, value := range m.Fields {
+		fields[key] = value
+	}
+	for key, value := range map[string]string{"service": m.Service, "environment": m.Environment, "host": m.Host} {
+		if value != "" {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// watcherConfig converts the CI report configuration, nil unless it has a
+// directory or URLs
+func (r CIReportsFileConfig) watcherConfig() *monitor.CIReportWatcherConfig {
+	if r.Dir == "" && len(r.URLs) == 0 {
+		return nil
+	}
+	return &monitor.CIReportWatcherConfig{
+		Dir:      r.Dir,
+		URLs:     r.URLs,
+		Interval: time.Duration(r.Interval),
+	}
+}
+
+func (w WatchdogFileConfig) watchdogConfig() WatchdogConfig {
+	return WatchdogConfig{
+		Interval:        time.Duration(w.Interval),
+		Factor:          w.Factor,
+		DefaultExpected: time.Duration(w.DefaultExpected),
+		Action:          StuckAction(w.Action),
+	}
+}
+
+func (h HeartbeatFileConfig) heartbeatConfig() HeartbeatConfig {
+	return HeartbeatConfig{Interval: time.Duration(h.Interval), Missed: h.Missed}
+}
+
+// missed returns how many heartbeats in a row an agent may miss
+func (h HeartbeatFileConfig) missed() int {
+	return h.heartbeatConfig().missed()
+}
+
+func (b BatchFileConfig) batchConfig() memory.BatchConfig {
+	return memory.BatchConfig{
+		FlushInterval: time.Duration(b.FlushInterval),
+		MaxBatch:      b.MaxBatch,
+		MaxPerSecond:  b.MaxPerSecond,
+		QueueSize:     b.QueueSize,
+		Drop:          memory.DropPolicy(b.Drop),
+	}
+}
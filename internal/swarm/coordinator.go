@@ -2,15 +2,27 @@ package swarm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/artifact"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
 	"github.com/opencode-ai/opencode/internal/swarm/health"
+	"github.com/opencode-ai/opencode/internal/swarm/locks"
 	"github.com/opencode-ai/opencode/internal/swarm/memory"
 	"github.com/opencode-ai/opencode/internal/swarm/monitor"
+	"github.com/opencode-ai/opencode/internal/swarm/notify"
+	"github.com/opencode-ai/opencode/internal/swarm/provider"
 	"github.com/opencode-ai/opencode/internal/swarm/rules"
+	"github.com/opencode-ai/opencode/internal/swarm/vault"
 	"github.com/opencode-ai/opencode/internal/swarm/voting"
 )
 
@@ -21,21 +33,79 @@ type Coordinator struct {
 	// Core components
 	registry      *agent.Registry
 	memoryStore   memory.MemoryStore
+	// monitorMemory batches the memories of log entries and commands
+	monitorMemory *memory.BatchWriter
+	artifacts     *artifact.Store
+	locks         *locks.Manager
+	grants        *agentGrants
+	// warmPools keep agents of slow providers loaded, by agent type
+	warmPools     map[agent.AgentType]*warmPool
+	// promotions queue session memories for the global namespace
+	promotions    *promotions
+	pruneVote     PruneVoteConfig
+	healthGate    *healthGate
+	// watchdog suspects running tasks of being stuck, every watchInterval
+	watchdog      *watchdog
+	watchInterval time.Duration
+	// heartbeatInterval is how often the configured agents send heartbeats
+	// and the registry checks them
+	heartbeatInterval time.Duration
+	// verification has reviewers verify results, by task type
+	verification  map[string]VerificationConfig
+	shadowStats   *shadowStats
+	// prices are the configured prices of models, by model
+	prices        map[string]TokenPrice
+	// fileHistory is where RollbackTask finds the changes of tasks
+	fileHistory   FileHistory
 	votingSystem  *voting.DemocraticVotingSystem
 	ruleEngine    *rules.RuleEngine
 	healthMonitor *health.HealthMonitor
 	
 	// Monitoring
 	logWatcher     *monitor.LogWatcher
+	logFormat      string
+	logMetadata    []monitor.LogMetadata
+	// logDrops are the log watcher's counts at the last health check
+	logDrops       monitor.LogDropStats
 	historyWatcher *monitor.ShellHistoryWatcher
+	ciWatcher      *monitor.CIReportWatcher
 	
 	// Task management
-	taskQueue     chan agent.Task
+	tasks         *taskTracker
 	taskResults   chan *agent.TaskResult
+	// finishing counts finished tasks whose outcome is still being
+	// recorded
+	finishing     atomic.Int64
+	
+	// IDs of the rules loaded from the rules directory
+	fileRules     []string
+	rulesMu       sync.Mutex
+	
+	// Error and warning counts of the files with diagnostics
+	diagnostics   *diagnosticCounts
+	// Errors logged lately, handled once
+	recentErrors  *recentErrors
+	
+	// Chronological record of swarm decisions
+	timeline      *timeline
+	notifiers     []*notify.Connector
+	pullRequests  *pullRequests
+	reviewSyncInterval time.Duration
+	// decisionsDir receives a decision record of every decided vote,
+	// none if empty
+	decisionsDir string
+	reportConfig ReportConfig
+	// Scheduled activity reports, and memory samples to measure growth
+	reports      *activityReports
+	clock         clock.Clock
+	recorder      *SimRecorder
+	consolidationInterval time.Duration
 	
 	// Lifecycle
 	ctx        context.Context
 	cancelFunc context.CancelFunc
+	// stopped is closed once shutdown finished
+	stopped    chan struct{}
 	wg         sync.WaitGroup
 	mu         sync.Mutex
 	running    bool
@@ -44,41 +114,209 @@ type Coordinator struct {
 // CoordinatorConfig contains configuration for the coordinator
 type CoordinatorConfig struct {
 	SwarmConfig    agent.SwarmConfig
+	// SelectionJitter spreads tasks over agents of about the same score,
+	// see agent.Registry.SetSelectionJitter
+	SelectionJitter float64
+	// MemoryConfig configures the memory store. Memories are stored in a
+	// namespace of the session, memory.SessionNamespace with a random ID,
+	// unless it names another.
 	MemoryConfig   memory.HierarchicalMemoryConfig
 	HealthConfig   health.HealthMonitorConfig
 	LogPaths       []string
+	// LogFormat is the format of the log files, see monitor.LogWatcherConfig
+	LogFormat      string
+	// LogMetadata adds static fields, e.g. the service logging, to the
+	// entries of log files by path pattern
+	LogMetadata    []monitor.LogMetadata
 	ShellHistory   string
+	// CIReports, if set, reads the test reports of CI runs. Failures are
+	// remembered and handled like error logs.
+	CIReports      *monitor.CIReportWatcherConfig
 	TaskQueueSize  int
+	// IdempotencyWindow is how long a completed task answers submissions
+	// with its idempotency key, DefaultIdempotencyWindow if zero
+	IdempotencyWindow time.Duration
+	
+	// ConsolidationInterval is how often memories are consolidated.
+	// Zero disables periodic consolidation.
+	ConsolidationInterval time.Duration
+	
+	// RulesDir holds YAML rule files loaded at creation
+	RulesDir string
+	
+	// ArtifactDir holds the artifacts of tasks, a directory under the temp
+	// dir if empty. Artifacts no task attached within ArtifactRetention are
+	// pruned with the memories.
+	ArtifactDir       string
+	ArtifactRetention time.Duration
+	
+	// Clock timestamps tasks and timeline events, the system clock if nil
+	Clock clock.Clock
+	
+	// Recorder, if set, receives the logs, tasks and task results the
+	// swarm sees so they can be replayed with Simulate
+	Recorder *SimRecorder
+	
+	// Sealer, if set, encrypts the artifacts of tasks, and memories marked
+	// encrypted unless the memory configuration has its own key
+	Sealer *vault.Sealer
+	
+	// MonitorWrites batches the memories of log entries and shell
+	// commands, timed by Clock unless it has its own
+	MonitorWrites memory.BatchConfig
+	
+	// WarmPools keep extra agents of a type started with their model
+	// loaded, by agent type
+	WarmPools map[agent.AgentType]WarmPoolConfig
+	
+	// Promotion queues memories that helped tasks succeed for promotion
+	// to the global namespace
+	Promotion PromotionConfig
+	
+	// PruneVote makes prunes deleting many memories wait for a vote
+	PruneVote PruneVoteConfig
+	
+	// AgentHealth passes over agents whose health check scores too low
+	AgentHealth AgentHealthConfig
+	
+	// Watchdog watches running tasks for ones that seem stuck
+	Watchdog WatchdogConfig
+	
+	// Heartbeat has agents send heartbeats, marking those that miss them
+	// unreachable
+	Heartbeat HeartbeatConfig
+	
+	// Verification has reviewers verify the results of task types, by
+	// task type
+	Verification map[string]VerificationConfig
+	
+	// Prices are what models cost, by model, for comparisons of agents.
+	// Models of the model catalog are priced unless configured here.
+	Prices map[string]TokenPrice
+	
+	// DryRun has agents report and simulate the commands of tasks instead
+	// of running them, see agent.WithDryRun
+	DryRun bool
+	
+	// Notifications post alerts, decided votes and daily digests to chat
+	// webhooks, timed by Clock unless they have their own
+	Notifications []notify.Config
+	
+	// ReviewSyncInterval is how often the review comments of the pull
+	// requests a GitHub agent opened are turned into tasks, zero to not
+	// read them
+	ReviewSyncInterval time.Duration
+	
+	// DecisionsDir, if set, receives an architecture decision record in
+	// markdown of every decided vote
+	DecisionsDir string
+	
+	// Reports schedules activity reports and where they are delivered
+	Reports ReportConfig
+	
+	// TagClassifier, if set, is the model memory.ModelTagger asks for
+	// tags when MemoryConfig.Tagging is set
+	TagClassifier *provider.Config
 }
 
 // NewCoordinator creates a new swarm coordinator
 func NewCoordinator(config CoordinatorConfig) (*Coordinator, error) {
 	ctx, cancel := context.WithCancel(context.Background())
+	if config.DryRun {
+		ctx = agent.WithDryRun(ctx)
+	}
 	
 	if config.TaskQueueSize <= 0 {
 		config.TaskQueueSize = 1000
 	}
+	if config.Clock == nil {
+		config.Clock = clock.Real
+	}
+	if config.HealthConfig.Clock == nil {
+		config.HealthConfig.Clock = config.Clock
+	}
+	if config.MonitorWrites.Clock == nil {
+		config.MonitorWrites.Clock = config.Clock
+	}
+	
+	if config.MemoryConfig.Namespace == "" {
+		config.MemoryConfig.Namespace = memory.SessionNamespace(uuid.New().String()[:8])
+	}
+	if config.TagClassifier != nil && config.MemoryConfig.Tagging != nil {
+		client, err := provider.New(*config.TagClassifier)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("memory tag classifier: %w", err)
+		}
+		// The model picks from the tags of the rules
+		tagging := *config.MemoryConfig.Tagging
+		tagger := memory.ModelTagger{Client: client}
+		for _, rule := range tagging.Rules {
+			tagger.Tags = append(tagger.Tags, rule.Tag)
+		}
+		tagging.Classifier = tagger
+		config.MemoryConfig.Tagging = &tagging
+	}
+	if config.Sealer != nil && config.MemoryConfig.EncryptionKey == nil {
+		config.MemoryConfig.EncryptionKey = config.Sealer.Key("memory", 32)
+	}
+	
+	// Large prunes wait for a vote of the coordinator, created below
+	var coordinator *Coordinator
+	if config.PruneVote.enabled() {
+		config.MemoryConfig.ApprovePrune = func(ctx context.Context, plan memory.PrunePlan) error {
+			return coordinator.approvePrune(ctx, plan)
+		}
+	}
+	
+	warmPools, err := newWarmPools(config.WarmPools)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	notifiers, err := newConnectors(config.Notifications, config.Clock)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
 	
 	// Initialize components
 	registry := agent.NewRegistry()
+	registry.SetSelectionJitter(config.SelectionJitter)
+	registry.SetHeartbeatTimeout(config.Heartbeat.timeout())
 	memoryStore := memory.NewHierarchicalMemoryStore(config.MemoryConfig)
-	votingSystem := voting.NewDemocraticVotingSystem()
+	votingSystem := voting.NewDemocraticVotingSystemWithClock(config.Clock)
 	ruleEngine := rules.NewRuleEngine(rules.RuleEngineConfig{
 		MaxHistory:    10000,
 		EnableHistory: true,
 		ParallelExec:  true,
 	})
 	healthMonitor := health.NewHealthMonitor(config.HealthConfig)
+	if config.ArtifactDir == "" {
+		config.ArtifactDir = DefaultArtifactDir()
+	}
+	artifacts, err := artifact.NewStore(artifact.Config{
+		Dir:       config.ArtifactDir,
+		Retention: config.ArtifactRetention,
+		Clock:     config.Clock,
+		Sealer:    config.Sealer,
+	})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
 	
 	// Initialize monitoring
 	var logWatcher *monitor.LogWatcher
 	var historyWatcher *monitor.ShellHistoryWatcher
-	var err error
 	
 	if len(config.LogPaths) > 0 {
 		logWatcher, err = monitor.NewLogWatcher(monitor.LogWatcherConfig{
-			Paths:      config.LogPaths,
-			BufferSize: 1000,
+			Paths:       config.LogPaths,
+			BufferSize:  1000,
+			ParseFormat: config.LogFormat,
+			Clock:       config.Clock,
+			Metadata:    config.LogMetadata,
 		})
 		if err != nil {
 			cancel()
@@ -87,46 +325,124 @@ func NewCoordinator(config CoordinatorConfig) (*Coordinator, error) {
 	}
 	
 	if config.ShellHistory != "" {
-		historyWatcher, err = monitor.NewShellHistoryWatcher(config.ShellHistory, 100)
+		historyWatcher, err = monitor.NewShellHistoryWatcherWithClock(config.ShellHistory, 100, config.Clock)
 		if err != nil {
 			cancel()
 			return nil, fmt.Errorf("failed to create history watcher: %w", err)
 		}
 	}
 	
-	coordinator := &Coordinator{
+	var ciWatcher *monitor.CIReportWatcher
+	if config.CIReports != nil {
+		ciConfig := *config.CIReports
+		if ciConfig.Clock == nil {
+			ciConfig.Clock = config.Clock
+		}
+		ciWatcher, err = monitor.NewCIReportWatcher(ciConfig)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to create CI report watcher: %w", err)
+		}
+	}
+	
+	coordinator = &Coordinator{
 		config:         config.SwarmConfig,
 		registry:       registry,
 		memoryStore:    memoryStore,
+		monitorMemory:  memory.NewBatchWriter(memoryStore, config.MonitorWrites),
+		artifacts:      artifacts,
+		locks:          locks.NewManager(config.Clock),
+		grants:         newAgentGrants(),
+		warmPools:      warmPools,
+		promotions:     newPromotions(config.Promotion),
+		pruneVote:      config.PruneVote,
+		healthGate:     newHealthGate(config.AgentHealth),
+		watchdog:       newWatchdog(config.Watchdog),
+		watchInterval:  config.Watchdog.Interval,
+		heartbeatInterval: config.Heartbeat.Interval,
+		verification:   config.Verification,
+		shadowStats:    newShadowStats(),
+		prices:         config.Prices,
 		votingSystem:   votingSystem,
 		ruleEngine:     ruleEngine,
 		healthMonitor:  healthMonitor,
 		logWatcher:     logWatcher,
+		logFormat:      config.LogFormat,
+		logMetadata:    config.LogMetadata,
 		historyWatcher: historyWatcher,
-		taskQueue:      make(chan agent.Task, config.TaskQueueSize),
+		ciWatcher:      ciWatcher,
+		tasks:          newTaskTracker(config.TaskQueueSize, config.IdempotencyWindow, config.Clock),
 		taskResults:    make(chan *agent.TaskResult, config.TaskQueueSize),
+		timeline:       newTimeline(config.Clock),
+		diagnostics:    newDiagnosticCounts(),
+		recentErrors:   newRecentErrors(),
+		clock:          config.Clock,
+		recorder:       config.Recorder,
+		notifiers:      notifiers,
+		pullRequests:   newPullRequests(),
+		reviewSyncInterval: config.ReviewSyncInterval,
+		decisionsDir:   config.DecisionsDir,
+		reportConfig:   config.Reports,
+		reports:        newActivityReports(),
+		consolidationInterval: config.ConsolidationInterval,
 		ctx:            ctx,
 		cancelFunc:     cancel,
+		stopped:        make(chan struct{}),
+	}
+	
+	healthMonitor.AddAlertSink(health.AlertSinkFunc(coordinator.recordAlert))
+	if logWatcher != nil {
+		coordinator.watchLogHealth(logWatcher)
+	}
+	registry.SetMessagePolicy(coordinator.messagePolicy)
+	
+	if config.RulesDir != "" {
+		if err := coordinator.loadRules(config.RulesDir); err != nil {
+			cancel()
+			return nil, err
+		}
 	}
 	
 	return coordinator, nil
 }
 
-// Start initializes and starts the swarm
+// Start initializes and starts the swarm. A stopped swarm cannot be
+// started again.
 func (c *Coordinator) Start() error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	
 	if c.running {
-		return fmt.Errorf("coordinator already running")
+		c.mu.Unlock()
+		return ErrCoordinatorRunning
+	}
+	if c.ctx.Err() != nil {
+		c.mu.Unlock()
+		return ErrCoordinatorStopped
 	}
 	
+	err := c.start()
+	c.running = err == nil
+	logWatcher, historyWatcher := c.logWatcher, c.historyWatcher
+	c.mu.Unlock()
+	
+	if err != nil {
+		// Undo the partial start outside the lock, the goroutines
+		// already started may be waiting for it
+		_ = c.shutdown(logWatcher, historyWatcher)
+		return err
+	}
+	return nil
+}
+
+// start starts the components and goroutines of the swarm. It is called
+// with c.mu held.
+func (c *Coordinator) start() error {
 	// Start health monitor
 	if err := c.healthMonitor.Start(); err != nil {
 		return fmt.Errorf("failed to start health monitor: %w", err)
 	}
 	
 	// Start monitoring
+	c.monitorMemory.Start()
 	if c.logWatcher != nil {
 		if err := c.logWatcher.Start(); err != nil {
 			return fmt.Errorf("failed to start log watcher: %w", err)
@@ -134,7 +450,7 @@ func (c *Coordinator) Start() error {
 		
 		// Process log entries
 		c.wg.Add(1)
-		go c.processLogEntries()
+		go c.processLogEntries(c.logWatcher)
 	}
 	
 	if c.historyWatcher != nil {
@@ -147,6 +463,14 @@ func (c *Coordinator) Start() error {
 		go c.processHistoryEntries()
 	}
 	
+	if c.ciWatcher != nil {
+		if err := c.ciWatcher.Start(); err != nil {
+			return fmt.Errorf("failed to start CI report watcher: %w", err)
+		}
+		c.wg.Add(1)
+		go c.processCIReports()
+	}
+	
 	// Start task processing
 	c.wg.Add(1)
 	go c.processTaskQueue()
@@ -155,84 +479,326 @@ func (c *Coordinator) Start() error {
 	c.wg.Add(1)
 	go c.processTaskResults()
 	
-	// Start agents
+	// Record recoveries performed by the health monitor
+	c.wg.Add(1)
+	go c.processRecoveryActions()
+	
+	if c.consolidationInterval > 0 {
+		c.wg.Add(1)
+		go c.consolidateMemoryPeriodically()
+	}
+	
+	if c.watchInterval > 0 {
+		c.wg.Add(1)
+		go c.watchTasks(c.watchInterval)
+	}
+	
+	if c.heartbeatInterval > 0 {
+		c.wg.Add(1)
+		go c.watchHeartbeats(c.heartbeatInterval)
+	}
+	
+	c.startNotifications()
+	
+	if c.reviewSyncInterval > 0 {
+		c.wg.Add(1)
+		go c.syncReviewsPeriodically(c.reviewSyncInterval)
+	}
+	
+	if c.decisionsDir != "" {
+		events := c.Subscribe(c.ctx)
+		c.wg.Add(1)
+		go c.recordDecisions(events)
+	}
+	
+	c.startReports()
+	
+	// Create the configured agents, then start every registered agent
+	if err := c.createConfiguredAgents(); err != nil {
+		return err
+	}
 	if err := c.registry.StartAll(c.ctx); err != nil {
 		return fmt.Errorf("failed to start agents: %w", err)
 	}
+	for _, ag := range c.registry.GetAllAgents() {
+		c.watchProgress(ag)
+	}
+	if err := c.startWarmPools(); err != nil {
+		return err
+	}
 	
 	// Load default rules
 	if err := c.loadDefaultRules(); err != nil {
 		return fmt.Errorf("failed to load rules: %w", err)
 	}
 	
-	c.running = true
+	c.pruneArtifacts()
+	
 	return nil
 }
 
-// Stop gracefully shuts down the swarm
+// Stop gracefully shuts down the swarm. It is safe to call concurrently
+// and more than once; every call returns after the swarm has stopped.
 func (c *Coordinator) Stop() error {
 	c.mu.Lock()
 	if !c.running {
 		c.mu.Unlock()
+		if c.ctx.Err() != nil {
+			// Stopped or stopping, wait for it to finish
+			<-c.stopped
+		}
 		return nil
 	}
 	c.running = false
+	logWatcher, historyWatcher := c.logWatcher, c.historyWatcher
 	c.mu.Unlock()
 	
+	return c.shutdown(logWatcher, historyWatcher)
+}
+
+// shutdown stops everything start started. Goroutines of the coordinator
+// finish before the components they read from are stopped, and channels are
+// closed only once nothing can send on them.
+func (c *Coordinator) shutdown(logWatcher *monitor.LogWatcher, historyWatcher *monitor.ShellHistoryWatcher) error {
+	defer close(c.stopped)
+	
 	// Stop components
 	c.cancelFunc()
 	
+	// Wait for goroutines, including running tasks, which see their
+	// context cancelled
+	c.wg.Wait()
+	
 	// Stop agents
-	if err := c.registry.StopAll(); err != nil {
-		return err
-	}
+	err := c.registry.StopAll(context.Background())
 	
-	// Stop monitoring
-	if c.logWatcher != nil {
-		_ = c.logWatcher.Stop()
+	// Stop monitoring, keeping what it saw
+	if logWatcher != nil {
+		_ = logWatcher.Stop()
 	}
-	if c.historyWatcher != nil {
-		_ = c.historyWatcher.Stop()
+	if historyWatcher != nil {
+		_ = historyWatcher.Stop()
 	}
+	if c.ciWatcher != nil {
+		_ = c.ciWatcher.Stop()
+	}
+	_ = c.monitorMemory.Close(context.Background())
 	
 	// Stop health monitor
 	_ = c.healthMonitor.Stop()
 	
-	// Wait for goroutines
-	c.wg.Wait()
-	
 	// Close channels
-	close(c.taskQueue)
 	close(c.taskResults)
+	c.timeline.broker.Shutdown()
 	
+	return err
+}
+
+// SubmitTask adds a task to the queue. Tasks without an ID are given one.
+// A task with the idempotency key of a queued, running or recently
+// completed task is coalesced with it instead: its ID refers to that task,
+// so its result is the result of that task. Tasks without a type are
+// refused with ErrTaskTypeRequired, as SubmitTasks does.
+func (c *Coordinator) SubmitTask(ctx context.Context, task agent.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.ctx.Err() != nil {
+		return ErrCoordinatorStopped
+	}
+	if task.Type == "" {
+		return ErrTaskTypeRequired
+	}
+	id, coalesced, err := c.tasks.enqueue(task)
+	if err != nil {
+		return err
+	}
+	c.recordSubmitted(task, id, coalesced)
 	return nil
 }
 
-// SubmitTask adds a task to the queue
-func (c *Coordinator) SubmitTask(task agent.Task) error {
-	select {
-	case c.taskQueue <- task:
-		return nil
-	case <-c.ctx.Done():
-		return fmt.Errorf("coordinator stopped")
-	default:
-		return fmt.Errorf("task queue full")
+// SubmitResult is the outcome of one task of a batch. ID is the task's ID,
+// or the ID of the task it was coalesced with.
+type SubmitResult struct {
+	ID        string
+	Coalesced bool
+	Err       error
+}
+
+// BatchOptions configure SubmitTasks
+type BatchOptions struct {
+	// Atomic queues no task of the batch unless all of them can be queued
+	Atomic bool
+}
+
+// SubmitTasks validates and queues a batch of tasks in one step, in order.
+// Tasks of the batch with the same idempotency key are coalesced with the
+// first of them, like tasks submitted one by one. The results hold the
+// outcome of every task; the error is ErrBatchRejected when a task could
+// not be queued, in which case an atomic batch queued none of them and the
+// results of the tasks that could have been queued have no error.
+func (c *Coordinator) SubmitTasks(ctx context.Context, tasks []agent.Task, opts BatchOptions) ([]SubmitResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if c.ctx.Err() != nil {
+		return nil, ErrCoordinatorStopped
+	}
+	
+	tasks = slices.Clone(tasks)
+	results := make([]SubmitResult, len(tasks))
+	invalid := false
+	for i := range tasks {
+		if tasks[i].ID == "" {
+			tasks[i].ID = uuid.New().String()
+		}
+		if tasks[i].Type == "" {
+			results[i] = SubmitResult{ID: tasks[i].ID, Err: ErrTaskTypeRequired}
+			invalid = true
+		}
+	}
+	if invalid && opts.Atomic {
+		return results, ErrBatchRejected
+	}
+	
+	valid := make([]agent.Task, 0, len(tasks))
+	for i, task := range tasks {
+		if results[i].Err == nil {
+			valid = append(valid, task)
+		}
+	}
+	queued, ok := c.tasks.enqueueBatch(valid, opts.Atomic)
+	j := 0
+	for i, task := range tasks {
+		if results[i].Err != nil {
+			continue
+		}
+		results[i] = queued[j]
+		j++
+		switch {
+		case results[i].Err != nil:
+		case opts.Atomic && !ok:
+			// It could have been queued, the batch was not
+			results[i].ID = task.ID
+		default:
+			c.recordSubmitted(task, results[i].ID, results[i].Coalesced)
+		}
+	}
+	if invalid || !ok {
+		return results, ErrBatchRejected
 	}
+	return results, nil
 }
 
-// GetTaskResult waits for a task result
-func (c *Coordinator) GetTaskResult(taskID string, timeout time.Duration) (*agent.TaskResult, error) {
-	ctx, cancel := context.WithTimeout(c.ctx, timeout)
-	defer cancel()
+// recordSubmitted records a queued or coalesced task on the timeline and
+// for simulations
+func (c *Coordinator) recordSubmitted(task agent.Task, id string, coalesced bool) {
+	if !coalesced {
+		task.ID = id
+	}
+	c.record(SimEvent{At: c.clock.Now(), Type: SimEventTask, Task: simTask(task)})
+	if coalesced {
+		c.timeline.record(TimelineTaskSubmitted, id, task.Description, map[string]interface{}{
+			"type":            task.Type,
+			"idempotency_key": task.IdempotencyKey,
+			"coalesced":       true,
+		})
+		return
+	}
+	c.timeline.record(TimelineTaskSubmitted, id, task.Description, map[string]interface{}{
+		"type":     task.Type,
+		"priority": task.Priority,
+	})
+}
+
+// ListTasks returns the queued, running and recently finished tasks, newest
+// first
+func (c *Coordinator) ListTasks() []TaskRecord {
+	return c.tasks.list()
+}
+
+// GetTask returns the state of a submitted task
+func (c *Coordinator) GetTask(taskID string) (TaskRecord, error) {
+	return c.tasks.get(taskID)
+}
+
+// CancelTask removes a queued task or cancels the context of a running one
+func (c *Coordinator) CancelTask(taskID string) error {
+	if err := c.tasks.cancel(taskID); err != nil {
+		return err
+	}
+	if record, err := c.tasks.get(taskID); err == nil && record.State == TaskStateCancelled {
+		c.recordTaskFinished(record)
+	}
+	return nil
+}
+
+// RetryTask queues a failed, cancelled or completed task again
+func (c *Coordinator) RetryTask(taskID string) error {
+	if c.ctx.Err() != nil {
+		return ErrCoordinatorStopped
+	}
+	if err := c.tasks.retry(taskID); err != nil {
+		return err
+	}
+	if record, err := c.tasks.get(taskID); err == nil {
+		c.timeline.record(TimelineTaskSubmitted, taskID, record.Task.Description, map[string]interface{}{
+			"type":     record.Task.Type,
+			"priority": record.Task.Priority,
+			"retry":    record.Task.RetryCount,
+		})
+	}
+	return nil
+}
+
+// Timeline returns the recorded swarm events matching the filter, oldest
+// first
+func (c *Coordinator) Timeline(filter TimelineFilter) []TimelineEvent {
+	return c.timeline.list(filter)
+}
+
+// Subscribe returns the timeline events recorded from now on, until ctx is
+// done or the swarm stopped. Events a slow subscriber is not ready for are
+// dropped, Timeline still has them.
+func (c *Coordinator) Subscribe(ctx context.Context) <-chan pubsub.Event[TimelineEvent] {
+	return c.timeline.broker.Subscribe(ctx)
+}
+
+// SetTaskPriority changes the priority of a queued task. Higher priorities
+// are dispatched first.
+func (c *Coordinator) SetTaskPriority(taskID string, priority int) error {
+	return c.tasks.setPriority(taskID, priority)
+}
+
+// GetTaskResult waits until a task finished and returns its result. Use a
+// context with a deadline to bound the wait.
+func (c *Coordinator) GetTaskResult(ctx context.Context, taskID string) (*agent.TaskResult, error) {
+	// Poll in real time, tasks finish on other goroutines whatever the
+	// clock shows
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
 	
 	for {
-		select {
-		case result := <-c.taskResults:
-			if result.TaskID == taskID {
-				return result, nil
+		record, err := c.tasks.get(taskID)
+		if err != nil {
+			return nil, err
+		}
+		switch record.State {
+		case TaskStateCompleted, TaskStateFailed:
+			if record.Result != nil {
+				return record.Result, nil
 			}
+			return nil, &TaskError{TaskID: taskID, Reason: record.Error, Err: ErrTaskFailed}
+		case TaskStateCancelled:
+			return nil, &TaskError{TaskID: taskID, Err: ErrTaskCancelled}
+		}
+		
+		select {
+		case <-ticker.C:
 		case <-ctx.Done():
-			return nil, fmt.Errorf("timeout waiting for task result")
+			return nil, ctx.Err()
+		case <-c.ctx.Done():
+			return nil, ErrCoordinatorStopped
 		}
 	}
 }
@@ -241,28 +807,28 @@ func (c *Coordinator) GetTaskResult(taskID string, timeout time.Duration) (*agen
 func (c *Coordinator) processTaskQueue() {
 	defer c.wg.Done()
 	
+	// Warm pools are maintained here so an idle pool is never evicted
+	// while a task for it is being dispatched
+	var warmTick <-chan time.Time
+	if interval := c.warmInterval(); interval > 0 {
+		ticker := c.clock.NewTicker(interval)
+		defer ticker.Stop()
+		warmTick = ticker.C()
+	}
+	
 	for {
 		select {
-		case task, ok := <-c.taskQueue:
-			if !ok {
-				return
-			}
-			
-			// Find suitable agents
-			agents := c.registry.FindAgentsForTask(task)
-			
-			if len(agents) == 0 {
-				// No agents available, requeue or fail
-				continue
+		case <-c.tasks.ready:
+			for {
+				task, ok := c.tasks.next()
+				if !ok {
+					break
+				}
+				c.dispatchTask(task)
 			}
 			
-			// If multiple agents can handle it, use democratic voting
-			if len(agents) > 1 && c.config.VotingThreshold > 0 {
-				c.handleTaskWithVoting(task, agents)
-			} else {
-				// Assign to first available agent
-				go c.executeTask(agents[0], task)
-			}
+		case <-warmTick:
+			c.maintainWarmPools()
 			
 		case <-c.ctx.Done():
 			return
@@ -270,21 +836,132 @@ func (c *Coordinator) processTaskQueue() {
 	}
 }
 
+// dispatchTask hands a task to a suitable agent
+func (c *Coordinator) dispatchTask(task agent.Task) {
+	c.useWarmPools(task)
+	
+	// Find suitable agents, best first, passing over degraded ones
+	agents, scores := c.registry.RankAgentsForTask(task)
+	agents = c.passOverDegraded(agents, scores)
+	c.tasks.setSelection(task.ID, scores)
+	
+	if len(agents) == 0 {
+		c.failTask(task.ID, "no agent can handle the task")
+		return
+	}
+	
+	// Agents may only run tasks they were granted the permissions for
+	agents, denied := c.grants.permitted(task, agents)
+	if len(agents) == 0 {
+		c.failTask(task.ID, fmt.Sprintf("no agent that can handle the task may %s", denied))
+		return
+	}
+	
+	// Agents may only run some tasks after a vote
+	for _, ag := range agents {
+		if requirer, ok := ag.(agent.VoteRequirer); ok && requirer.RequiresVote(task) {
+			task.RequiresVote = true
+		}
+	}
+	
+	// If multiple agents can handle it, or the task asks for it, use
+	// democratic voting
+	if task.RequiresVote || (len(agents) > 1 && c.votingThreshold() > 0) {
+		c.handleTaskWithVoting(task, agents)
+	} else {
+		// Assign to first available agent
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.executeTask(agents[0], task)
+		}()
+	}
+}
+
+// VoteTagDestructive tags the votes on tasks an agent requires a vote for,
+// such as destructive commands
+const VoteTagDestructive = "destructive"
+
+// voteTags labels the vote on a task with the task type, and as destructive
+// when an agent requires the vote
+func voteTags(task agent.Task, agents []agent.Agent) []string {
+	var tags []string
+	if task.Type != "" {
+		tags = append(tags, string(task.Type))
+	}
+	for _, ag := range agents {
+		if requirer, ok := ag.(agent.VoteRequirer); ok && requirer.RequiresVote(task) {
+			return append(tags, VoteTagDestructive)
+		}
+	}
+	return tags
+}
+
 // executeTask executes a task on an agent
 func (c *Coordinator) executeTask(ag agent.Agent, task agent.Task) {
 	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Minute)
 	defer cancel()
+	ctx = agent.WithProgress(ctx, c.recordProgress)
+	ctx = locks.WithOwner(ctx, c.locks, locks.Owner{TaskID: task.ID, AgentID: ag.GetID()})
+	grants := c.grants.of(ag)
+	ctx = agent.WithGrants(ctx, ag.GetID(), grants)
+	if c.promotions.enabled() {
+		ctx = withPromotionTask(ctx, task.ID)
+	}
 	
+	c.tasks.start(task.ID, ag.GetID(), cancel)
+	c.timeline.record(TimelineTaskStarted, task.ID, task.Description, map[string]interface{}{
+		"agent": ag.GetID(),
+	})
+	startedAt := c.clock.Now()
 	result, err := ag.ExecuteTask(ctx, task)
+	// Locks the agent did not release end with the task
+	c.locks.ReleaseAll(task.ID)
+	if errors.Is(err, agent.ErrAgentBusy) && ag.GetStatus() != agent.AgentStatusIdle {
+		// The agent filled up after it was picked, let another one take it.
+		// Agents that stay idle would be picked again, so they fail the task.
+		c.tasks.requeue(task.ID)
+		c.timeline.record(TimelineTaskRequeued, task.ID, task.Description, map[string]interface{}{
+			"agent": ag.GetID(),
+		})
+		return
+	}
 	if err != nil {
 		result = &agent.TaskResult{
 			TaskID:      task.ID,
 			Success:     false,
 			Error:       err,
 			AgentID:     ag.GetID(),
-			CompletedAt: time.Now(),
+			CompletedAt: c.clock.Now(),
+		}
+	}
+	// Patches of agents that may not write files are not passed on
+	if _, ok := result.Output[agent.OutputPatch]; ok {
+		if err := grants.Check(ag.GetID(), agent.PermissionWriteFiles); err != nil {
+			delete(result.Output, agent.OutputPatch)
+			result.Success = false
+			result.Error = err
+		}
+	}
+	c.verifyResult(ag, task, result)
+	c.storeArtifacts(result)
+	c.countMemoryUses(task.ID, result.Success)
+	
+	// Finished tasks are still being handled until the result was learned
+	// from
+	c.finishing.Add(1)
+	c.tasks.finish(task.ID, result)
+	c.record(SimEvent{At: c.clock.Now(), Type: SimEventResult, Result: simResult(result)})
+	if record, err := c.tasks.get(task.ID); err == nil {
+		c.recordTaskFinished(record)
+		if record.State == TaskStateCompleted {
+			c.watchdog.learn(task.Type, c.clock.Now().Sub(startedAt))
 		}
 	}
+	if c.watchdog.finished(task.ID) {
+		_ = c.RetryTask(task.ID)
+	}
+	c.runShadows(task, result)
 	
 	// Store result in memory
 	c.storeTaskResult(result)
@@ -293,32 +970,46 @@ func (c *Coordinator) executeTask(ag agent.Agent, task agent.Task) {
 	select {
 	case c.taskResults <- result:
 	case <-c.ctx.Done():
+		c.finishing.Add(-1)
 	}
 }
 
 // handleTaskWithVoting uses democratic voting for task decisions
 func (c *Coordinator) handleTaskWithVoting(task agent.Task, agents []agent.Agent) {
+	voters := c.grants.voters(agents)
+	if len(voters) == 0 {
+		c.failTask(task.ID, "no agent that can handle the task may cast-votes")
+		return
+	}
+	
 	// Create a vote on how to handle the task
 	proposal := voting.VoteProposal{
 		Description: fmt.Sprintf("Should we execute task: %s", task.Description),
 		Context: map[string]interface{}{
 			"task": task,
 		},
-		Deadline: time.Now().Add(30 * time.Second),
+		Tags:     voteTags(task, agents),
+		Deadline: c.clock.Now().Add(30 * time.Second),
 	}
 	
 	session, err := c.votingSystem.CreateVoteSession(
 		proposal,
 		voting.VoteTypeMajority,
-		len(agents),
+		len(voters),
 		nil,
 	)
 	if err != nil {
+		c.failTask(task.ID, err.Error())
 		return
 	}
+	c.tasks.setVote(task.ID, session.ID)
+	c.timeline.record(TimelineVoteOpened, session.ID, proposal.Description, map[string]interface{}{
+		"task":   task.ID,
+		"voters": len(voters),
+	})
 	
 	// Collect votes from agents (simplified - would need actual agent input)
-	for _, ag := range agents {
+	for _, ag := range voters {
 		vote := voting.Vote{
 			AgentID:    ag.GetID(),
 			Decision:   ag.CanHandleTask(task),
@@ -333,64 +1024,531 @@ func (c *Coordinator) handleTaskWithVoting(task agent.Task, agents []agent.Agent
 	defer cancel()
 	
 	result, err := c.votingSystem.WaitForResult(ctx, session.ID)
+	if err == nil {
+		decision := "rejected"
+		if result.Decision {
+			decision = "approved"
+		}
+		c.timeline.record(TimelineVoteDecided, session.ID, fmt.Sprintf("Task %s %s", task.ID, decision), map[string]interface{}{
+			"task":     task.ID,
+			"approved": result.Decision,
+			"yes":      result.YesVotes,
+			"no":       result.NoVotes,
+			"vetoed":   result.Vetoed,
+			"vetoedBy": result.VetoedBy,
+		})
+	} else {
+		c.timeline.record(TimelineVoteDecided, session.ID, fmt.Sprintf("Task %s not approved in time", task.ID), map[string]interface{}{
+			"task":    task.ID,
+			"expired": true,
+			"error":   err.Error(),
+		})
+	}
 	if err == nil && result.Decision {
 		// Execute on the agent with highest confidence
 		bestAgent := agents[0]
 		c.executeTask(bestAgent, task)
+	} else {
+		c.failTask(task.ID, "rejected by vote")
 	}
 }
 
-// processTaskResults handles task results
-func (c *Coordinator) processTaskResults() {
-	defer c.wg.Done()
-	
-	for {
-		select {
-		case result, ok := <-c.taskResults:
-			if !ok {
-				return
-			}
-			
-			// Analyze and learn from results
-			c.learnFromResult(result)
-			
-		case <-c.ctx.Done():
-			return
-		}
+// failTask finishes a task that never ran
+func (c *Coordinator) failTask(taskID, reason string) {
+	c.finishing.Add(1)
+	defer c.finishing.Add(-1)
+	c.tasks.fail(taskID, reason)
+	if record, err := c.tasks.get(taskID); err == nil {
+		c.recordTaskFinished(record)
 	}
 }
 
-// processLogEntries handles log monitoring
-func (c *Coordinator) processLogEntries() {
+// recordTaskFinished adds the outcome of a task to the timeline
+func (c *Coordinator) recordTaskFinished(record TaskRecord) {
+	details := map[string]interface{}{
+		"state": string(record.State),
+	}
+	if record.AgentID != "" {
+		details["agent"] = record.AgentID
+	}
+	if record.Error != "" {
+		details["error"] = record.Error
+	}
+	c.timeline.record(TimelineTaskFinished, record.Task.ID, fmt.Sprintf("%s: %s", record.State, record.Task.Description), details)
+}
+
+// recordProgress adds the progress an agent reported on a task to its
+// record and the timeline
+func (c *Coordinator) recordProgress(progress agent.TaskProgress) {
+	c.tasks.progress(progress)
+	
+	details := map[string]interface{}{
+		"agent": progress.AgentID,
+	}
+	if progress.Percent > 0 {
+		details["percent"] = progress.Percent
+	}
+	if progress.Stage != "" {
+		details["stage"] = progress.Stage
+	}
+	for key, value := range progress.Details {
+		details[key] = value
+	}
+	c.timeline.record(TimelineTaskProgress, progress.TaskID, progress.Message, details)
+}
+
+// watchProgress records the progress an agent streams until it closes the
+// channel or the swarm stops
+func (c *Coordinator) watchProgress(ag agent.Agent) {
+	reporter, ok := ag.(agent.ProgressReporter)
+	if !ok {
+		return
+	}
+	updates := reporter.Progress()
+	if updates == nil {
+		return
+	}
+	
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			select {
+			case progress, ok := <-updates:
+				if !ok {
+					return
+				}
+				if progress.AgentID == "" {
+					progress.AgentID = ag.GetID()
+				}
+				c.recordProgress(progress)
+			case <-c.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// recordAlert adds a health alert to the timeline
+func (c *Coordinator) recordAlert(alert health.HealthAlert) {
+	c.timeline.record(TimelineAlert, alert.ComponentID, alert.Check.Message, map[string]interface{}{
+		"status":   string(alert.Status),
+		"severity": string(alert.Severity),
+		"score":    alert.Check.Score,
+	})
+}
+
+// loadRules adds the rules defined in a directory. Their notifications are
+// recorded as timeline alerts.
+func (c *Coordinator) loadRules(dir string) error {
+	defs, err := rules.LoadDefinitionDir(dir)
+	if err != nil {
+		return err
+	}
+	return c.replaceFileRules(defs)
+}
+
+// replaceFileRules swaps the rules loaded from the rules directory for defs.
+// Nothing changes if any definition is invalid or clashes with a rule that
+// was not loaded from the directory.
+func (c *Coordinator) replaceFileRules(defs []rules.RuleDefinition) error {
+	c.rulesMu.Lock()
+	defer c.rulesMu.Unlock()
+	
+	old := make(map[string]bool, len(c.fileRules))
+	for _, id := range c.fileRules {
+		old[id] = true
+	}
+	
+	opts := rules.BuildOptions{
+		NotificationSink: timelineNotifier{c.timeline},
+		TaskSubmitter:    ruleTaskSubmitter{c},
+		MemorySearcher:   ruleMemorySearcher{c},
+		VoteRequester:    ruleVoteRequester{c},
+	}
+	built := make([]rules.Rule, 0, len(defs))
+	for _, def := range defs {
+		rule, err := def.Build(opts)
+		if err != nil {
+			return err
+		}
+		if _, err := c.ruleEngine.GetRule(rule.ID); err == nil && !old[rule.ID] {
+			return fmt.Errorf("%w: %s", rules.ErrRuleExists, rule.ID)
+		}
+		built = append(built, rule)
+	}
+	
+	for _, id := range c.fileRules {
+		_ = c.ruleEngine.RemoveRule(c.ctx, id)
+	}
+	c.fileRules = c.fileRules[:0]
+	for _, rule := range built {
+		if err := c.ruleEngine.AddRule(c.ctx, rule); err != nil {
+			return fmt.Errorf("rule %s: %w", rule.ID, err)
+		}
+		c.fileRules = append(c.fileRules, rule.ID)
+	}
+	return nil
+}
+
+// ruleTaskSubmitter queues the tasks of submit_task rules
+type ruleTaskSubmitter struct {
+	coordinator *Coordinator
+}
+
+func (s ruleTaskSubmitter) SubmitRuleTask(ctx context.Context, task rules.RuleTask) error {
+	return s.coordinator.SubmitTask(ctx, ruleTask(task))
+}
+
+// SubmitRuleTasks queues the tasks a rule submits together, all or none
+func (s ruleTaskSubmitter) SubmitRuleTasks(ctx context.Context, tasks []rules.RuleTask) error {
+	batch := make([]agent.Task, len(tasks))
+	for i, task := range tasks {
+		batch[i] = ruleTask(task)
+	}
+	results, err := s.coordinator.SubmitTasks(ctx, batch, BatchOptions{Atomic: true})
+	if errors.Is(err, ErrBatchRejected) {
+		for _, result := range results {
+			if result.Err != nil {
+				return fmt.Errorf("%w: %w", err, result.Err)
+			}
+		}
+	}
+	return err
+}
+
+func ruleTask(task rules.RuleTask) agent.Task {
+	return agent.Task{
+		Type:           task.Type,
+		Description:    task.Description,
+		Priority:       task.Priority,
+		Input:          task.Input,
+		IdempotencyKey: task.IdempotencyKey,
+	}
+}
+
+// ruleMemorySearcher counts the memories of memory conditions in the
+// memory store
+type ruleMemorySearcher struct {
+	coordinator *Coordinator
+}
+
+func (s ruleMemorySearcher) CountMemories(ctx context.Context, query string) (int, error) {
+	parsed, err := memory.ParseQuery(query)
+	if err != nil {
+		return 0, err
+	}
+	found, err := s.coordinator.memoryStore.Query(ctx, parsed)
+	if err != nil {
+		return 0, err
+	}
+	return len(found), nil
+}
+
+// createConfiguredAgents creates and registers the agents of the swarm
+// configuration. Agents registered by ID beforehand are left alone.
+func (c *Coordinator) createConfiguredAgents() error {
+	for _, cfg := range c.config.Agents {
+		if _, err := c.registry.GetAgent(cfg.ID); err == nil {
+			continue
+		}
+		ag, err := c.newAgent(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create agent: %w", err)
+		}
+		if err := c.registerAgent(cfg, ag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerAgent registers a configured agent, in shadow mode if configured
+func (c *Coordinator) registerAgent(cfg agent.AgentConfig, ag agent.Agent) error {
+	if cfg.Shadow {
+		return c.registry.RegisterShadowAgent(ag)
+	}
+	return c.registry.RegisterAgent(ag)
+}
+
+// newAgent creates a configured agent with its permissions, handing it the
+// memory store as far as it may use it
+func (c *Coordinator) newAgent(cfg agent.AgentConfig) (agent.Agent, error) {
+	if cfg.Shadow {
+		cfg = shadowConfig(cfg)
+	}
+	if cfg.HeartbeatInterval == 0 {
+		cfg.HeartbeatInterval = c.heartbeatInterval
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = c.clock
+	}
+	ag, err := agent.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c.grants.set(cfg)
+	if user, ok := ag.(agent.MemoryUser); ok {
+		user.UseMemory(permittedMemory{store: c.memoryStore, agentID: cfg.ID, grants: c.grants, promotions: c.promotions})
+	}
+	return ag, nil
+}
+
+// addConfiguredAgent creates, registers and starts an agent added to the
+// configuration of a running swarm
+func (c *Coordinator) addConfiguredAgent(cfg agent.AgentConfig) error {
+	ag, err := c.newAgent(cfg)
+	if err != nil {
+		return err
+	}
+	
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	if err := c.registerAgent(cfg, ag); err != nil {
+		return err
+	}
+	if c.running {
+		if err := ag.Start(c.ctx); err != nil {
+			_ = c.registry.UnregisterAgent(cfg.ID)
+			return fmt.Errorf("failed to start agent %s: %w", cfg.ID, err)
+		}
+		c.watchProgress(ag)
+	}
+	c.config.Agents = append(c.config.Agents, cfg)
+	return nil
+}
+
+// ReconfigureAgent applies a new configuration to a registered agent. The
+// agent takes what it can while running, see agent.Agent.Reconfigure; if
+// other fields changed it is recycled: a new agent is created, registered
+// and started in its place and the old one stopped. It returns the fields
+// that needed the agent recycled.
+func (c *Coordinator) ReconfigureAgent(cfg agent.AgentConfig) ([]string, error) {
+	old, err := c.registry.GetAgent(cfg.ID)
+	if err != nil {
+		return nil, err
+	}
+	next := cfg
+	if next.Shadow {
+		next = shadowConfig(next)
+	}
+	if next.HeartbeatInterval == 0 {
+		next.HeartbeatInterval = c.heartbeatInterval
+	}
+	recycle, err := old.Reconfigure(next)
+	if err != nil {
+		return nil, err
+	}
+	if len(recycle) == 0 {
+		c.grants.set(next)
+		c.setAgentConfig(cfg)
+		return nil, nil
+	}
+	
+	ag, err := c.newAgent(cfg)
+	if err != nil {
+		return recycle, err
+	}
+	c.mu.Lock()
+	if err := c.registry.UnregisterAgent(cfg.ID); err != nil {
+		c.mu.Unlock()
+		return recycle, err
+	}
+	if err := c.registerAgent(cfg, ag); err != nil {
+		c.mu.Unlock()
+		return recycle, err
+	}
+	if c.running {
+		if err := ag.Start(c.ctx); err != nil {
+			c.mu.Unlock()
+			return recycle, fmt.Errorf("failed to start agent %s: %w", cfg.ID, err)
+		}
+		c.watchProgress(ag)
+	}
+	c.mu.Unlock()
+	c.setAgentConfig(cfg)
+	
+	// Tasks the old agent is running finish on it
+	if err := old.Stop(); err != nil {
+		return recycle, fmt.Errorf("failed to stop agent %s: %w", cfg.ID, err)
+	}
+	return recycle, nil
+}
+
+// setAgentConfig replaces the configuration of an agent
+func (c *Coordinator) setAgentConfig(cfg agent.AgentConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, a := range c.config.Agents {
+		if a.ID == cfg.ID {
+			c.config.Agents[i] = cfg
+			return
+		}
+	}
+	c.config.Agents = append(c.config.Agents, cfg)
+}
+
+// votingThreshold returns the share of votes needed to run a task that
+// several agents can handle
+func (c *Coordinator) votingThreshold() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.config.VotingThreshold
+}
+
+// setVotingThreshold changes the voting threshold of a running swarm
+func (c *Coordinator) setVotingThreshold(threshold float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config.VotingThreshold = threshold
+}
+
+// setLogPaths changes the watched log files, creating the log watcher if the
+// swarm started without one
+func (c *Coordinator) setLogPaths(paths []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	if c.logWatcher != nil {
+		return c.logWatcher.SetPaths(paths)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	
+	logWatcher, err := monitor.NewLogWatcher(monitor.LogWatcherConfig{
+		Paths:       paths,
+		BufferSize:  1000,
+		ParseFormat: c.logFormat,
+		Clock:       c.clock,
+		Metadata:    c.logMetadata,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create log watcher: %w", err)
+	}
+	if c.running {
+		if err := logWatcher.Start(); err != nil {
+			return fmt.Errorf("failed to start log watcher: %w", err)
+		}
+	}
+	c.logWatcher = logWatcher
+	c.watchLogHealth(logWatcher)
+	if c.running {
+		c.wg.Add(1)
+		go c.processLogEntries(logWatcher)
+	}
+	return nil
+}
+
+// setLogMetadata changes the static fields added to log entries by path
+func (c *Coordinator) setLogMetadata(metadata []monitor.LogMetadata) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.logWatcher != nil {
+		if err := c.logWatcher.SetMetadata(metadata); err != nil {
+			return err
+		}
+	}
+	c.logMetadata = metadata
+	return nil
+}
+
+// processRecoveryActions records recoveries performed by the health monitor
+func (c *Coordinator) processRecoveryActions() {
 	defer c.wg.Done()
 	
 	for {
 		select {
-		case entry, ok := <-c.logWatcher.Entries():
+		case action := <-c.healthMonitor.RecoveryActions():
+			details := map[string]interface{}{
+				"action": string(action.ActionType),
+			}
+			for k, v := range action.Parameters {
+				details[k] = v
+			}
+			c.timeline.record(TimelineRecovery, action.ComponentID,
+				fmt.Sprintf("Recovered %s (%s)", action.ComponentID, action.ActionType), details)
+			
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// consolidateMemoryPeriodically consolidates memories on an interval
+func (c *Coordinator) consolidateMemoryPeriodically() {
+	defer c.wg.Done()
+	
+	ticker := c.clock.NewTicker(c.consolidationInterval)
+	defer ticker.Stop()
+	
+	for {
+		select {
+		case <-ticker.C():
+			_ = c.ConsolidateMemory(c.ctx)
+			c.pruneArtifacts()
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// ConsolidateMemory merges related memories and records the outcome on the
+// timeline
+func (c *Coordinator) ConsolidateMemory(ctx context.Context) error {
+	stats := c.memoryStore.GetStats()
+	before, merged := stats.TotalMemories, stats.MergedMemories
+	if err := c.memoryStore.Consolidate(ctx); err != nil {
+		c.timeline.record(TimelineConsolidation, "memory", "Consolidation failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return fmt.Errorf("failed to consolidate memory: %w", err)
+	}
+	stats = c.memoryStore.GetStats()
+	after := stats.TotalMemories
+	c.timeline.record(TimelineConsolidation, "memory",
+		fmt.Sprintf("Consolidated %d memories into %d", before, after), map[string]interface{}{
+			"before":    before,
+			"after":     after,
+			"compacted": stats.MergedMemories - merged,
+		})
+	return nil
+}
+
+// processTaskResults handles task results
+func (c *Coordinator) processTaskResults() {
+	defer c.wg.Done()
+	
+	for {
+		select {
+		case result, ok := <-c.taskResults:
 			if !ok {
 				return
 			}
 			
-			// Store in memory
-			mem := memory.Memory{
-				Type:     memory.MemoryTypeEpisodic,
-				Content:  entry,
-				Tags:     []string{"log", entry.Level},
-				Priority: memory.PriorityNormal,
-			}
-			_ = c.memoryStore.Store(mem)
+			// Analyze and learn from results
+			c.learnFromResult(result)
+			c.finishing.Add(-1)
 			
-			// Evaluate rules
-			ruleCtx := rules.RuleContext{
-				EventType: "log_entry",
-				EventData: map[string]interface{}{
-					"level":   entry.Level,
-					"message": entry.Message,
-					"source":  entry.Source,
-				},
-				Timestamp: entry.Timestamp,
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// processLogEntries handles log monitoring
+func (c *Coordinator) processLogEntries(logWatcher *monitor.LogWatcher) {
+	defer c.wg.Done()
+	
+	for {
+		select {
+		case entry, ok := <-logWatcher.Entries():
+			if !ok {
+				return
 			}
-			_ = c.ruleEngine.EvaluateRules(c.ctx, ruleCtx)
+			c.handleLogEntry(entry)
 			
 		case <-c.ctx.Done():
 			return
@@ -398,6 +1556,90 @@ func (c *Coordinator) processLogEntries() {
 	}
 }
 
+// logTagFields are the fields of log entries their memories are tagged
+// with, as key:value
+var logTagFields = []string{"service", "environment", "host"}
+
+// handleLogEntry remembers a log entry and evaluates the rules against it
+func (c *Coordinator) handleLogEntry(entry monitor.LogEntry) {
+	// Entries are recorded when they were read so replays keep their order,
+	// with the time they logged when it differs
+	log := &SimLog{
+		Level:   entry.Level,
+		Source:  entry.Source,
+		Message: entry.Message,
+		Fields:  entry.Fields,
+	}
+	at := entry.IngestedAt
+	if at.IsZero() {
+		at = entry.Timestamp
+	} else if !entry.Timestamp.Equal(at) {
+		logged := entry.Timestamp
+		log.Logged = &logged
+	}
+	c.record(SimEvent{At: at, Type: SimEventLog, Log: log})
+	
+	// Store in memory, tagged with the service, environment and host of
+	// the log when it has them
+	tags := []string{"log", entry.Level}
+	for _, key := range logTagFields {
+		if value, ok := entry.Fields[key].(string); ok && value != "" {
+			tags = append(tags, key+":"+value)
+		}
+	}
+	// Errors are identified by their signature, so their occurrences are
+	// grouped even when their messages differ in paths, IDs or numbers
+	isError := isErrorEntry(entry)
+	var signature agent.Signature
+	fingerprint := entry.Message
+	if isError {
+		signature = agent.ExtractSignature(entry.Message)
+		fingerprint = signature.Key + "\x00" + signature.Location
+	}
+	mem := memory.Memory{
+		Type:     memory.MemoryTypeEpisodic,
+		Content:  entry,
+		Tags:     tags,
+		Priority: memory.PriorityNormal,
+		// Dated when logged, so time range queries find the entries of a
+		// period even when they were read later
+		CreatedAt: entry.Timestamp,
+		// Floods of the same line or error are compacted into one counted
+		// memory
+		Fingerprint: entry.Level + "\x00" + entry.Source + "\x00" + fingerprint,
+	}
+	c.monitorMemory.Write(mem)
+	
+	// Evaluate rules
+	ruleCtx := rules.RuleContext{
+		EventType: "log_entry",
+		EventData: map[string]interface{}{
+			"level":   entry.Level,
+			"message": entry.Message,
+			"source":  entry.Source,
+			"error":   isError,
+		},
+		Timestamp: entry.Timestamp,
+	}
+	// The fields of the entry, such as the metadata of its file or how sure
+	// an inferred level is, are there for rules too
+	for key, value := range entry.Fields {
+		if _, ok := ruleCtx.EventData[key]; !ok {
+			ruleCtx.EventData[key] = value
+		}
+	}
+	if isError {
+		ruleCtx.EventData["signature"] = signature.Key
+		ruleCtx.EventData["error_type"] = signature.Type
+		ruleCtx.EventData["error_location"] = signature.Location
+	}
+	_ = c.ruleEngine.EvaluateRules(c.ctx, ruleCtx)
+	
+	if signature.Key != "" {
+		c.handleErrorLog(entry, signature.Key)
+	}
+}
+
 // processHistoryEntries handles shell history monitoring
 func (c *Coordinator) processHistoryEntries() {
 	defer c.wg.Done()
@@ -416,7 +1658,7 @@ func (c *Coordinator) processHistoryEntries() {
 				Tags:     []string{"shell", "command"},
 				Priority: memory.PriorityNormal,
 			}
-			_ = c.memoryStore.Store(mem)
+			c.monitorMemory.Write(mem)
 			
 		case <-c.ctx.Done():
 			return
@@ -448,8 +1690,94 @@ func (c *Coordinator) storeTaskResult(result *agent.TaskResult) {
 			"success":  result.Success,
 		},
 	}
+	if ids := artifactIDs(result); len(ids) > 0 {
+		mem.Metadata["artifacts"] = ids
+	}
+	
+	_ = c.memoryStore.Store(c.ctx, mem)
+	
+	if report, ok := result.Output["report"].(*agent.TestReport); ok {
+		c.storeTestFailures(result, report)
+	}
+	if analysis, ok := result.Output["analysis"].(*agent.CodeAnalysis); ok {
+		c.storeFindings(result, analysis)
+	}
+	if report, ok := result.Output["security"].(*agent.SecurityReport); ok {
+		c.storeSecurityFindings(result, report)
+	}
+	c.storeFix(result)
+	c.queueFollowUps(result)
+	c.handleGitHubResult(result)
+}
+
+// storeFindings remembers the findings of a code analysis as facts about
+// the code. A finding replaces the one of an earlier analysis about the
+// same thing, and findings of analyzed files that were not found again are
+// forgotten.
+func (c *Coordinator) storeFindings(result *agent.TaskResult, analysis *agent.CodeAnalysis) {
+	analyzed := make(map[string]bool, len(analysis.Files))
+	for _, file := range analysis.Files {
+		analyzed[file.Path] = true
+	}
+	current := make(map[string]bool, len(analysis.Findings))
+	for _, finding := range analysis.Findings {
+		id := fmt.Sprintf("finding:%s:%s:%s", finding.Kind, finding.Path, finding.Symbol)
+		current[id] = true
+		mem := memory.Memory{
+			ID:       id,
+			Type:     memory.MemoryTypeSemantic,
+			Content:  finding.String(),
+			Tags:     []string{"analysis", finding.Kind},
+			Priority: memory.PriorityNormal,
+			Metadata: map[string]interface{}{
+				"task_id": result.TaskID,
+				"path":    finding.Path,
+				"line":    finding.Line,
+				"symbol":  finding.Symbol,
+			},
+		}
+		_ = c.memoryStore.Store(c.ctx, mem)
+	}
 	
-	_ = c.memoryStore.Store(mem)
+	stored, err := c.memoryStore.Query(c.ctx, memory.MemoryQuery{Type: memory.MemoryTypeSemantic, Tags: []string{"analysis"}})
+	if err != nil {
+		return
+	}
+	for _, mem := range stored {
+		path, _ := mem.Metadata["path"].(string)
+		if analyzed[path] && !current[mem.ID] {
+			_ = c.memoryStore.Delete(c.ctx, mem.ID)
+		}
+	}
+}
+
+// maxStoredTestFailures caps the failures remembered per test run
+const maxStoredTestFailures = 50
+
+// storeTestFailures remembers each failure of a test run, so later tasks
+// can look up how a test failed before
+func (c *Coordinator) storeTestFailures(result *agent.TaskResult, report *agent.TestReport) {
+	for i, failure := range report.Failures {
+		if i == maxStoredTestFailures {
+			break
+		}
+		mem := memory.Memory{
+			Type:     memory.MemoryTypeEpisodic,
+			Content:  failure,
+			Tags:     []string{"test", "failure"},
+			Priority: memory.PriorityHigh,
+			Metadata: map[string]interface{}{
+				"task_id": result.TaskID,
+				"command": report.Command,
+				"package": failure.Package,
+				"test":    failure.Name,
+			},
+		}
+		if ids := artifactIDs(result); len(ids) > 0 {
+			mem.Metadata["artifacts"] = ids
+		}
+		_ = c.memoryStore.Store(c.ctx, mem)
+	}
 }
 
 // learnFromResult analyzes task results for learning
@@ -461,7 +1789,7 @@ func (c *Coordinator) learnFromResult(result *agent.TaskResult) {
 		Limit: 10,
 	}
 	
-	similar, _ := c.memoryStore.Query(query)
+	similar, _ := c.memoryStore.Query(c.ctx, query)
 	
 	// Analyze patterns (simplified)
 	successRate := 0.0
@@ -480,6 +1808,9 @@ func (c *Coordinator) learnFromResult(result *agent.TaskResult) {
 	// Update agent health based on performance
 	if result.Success {
 		// Positive reinforcement
+		if c.healthGate.enabled() {
+			c.recoverAgent(result.AgentID)
+		}
 	} else {
 		// Negative feedback, may trigger recovery
 		c.healthMonitor.UpdateCheck(health.HealthCheck{
@@ -511,7 +1842,7 @@ func (c *Coordinator) loadDefaultRules() error {
 		Tags: []string{"error", "recovery"},
 	}
 	
-	if err := c.ruleEngine.AddRule(errorRule); err != nil {
+	if err := c.ruleEngine.AddRule(c.ctx, errorRule); err != nil {
 		return err
 	}
 	
@@ -531,7 +1862,7 @@ func (c *Coordinator) loadDefaultRules() error {
 		Tags: []string{"log", "analysis"},
 	}
 	
-	return c.ruleEngine.AddRule(logRule)
+	return c.ruleEngine.AddRule(c.ctx, logRule)
 }
 
 // GetRegistry returns the agent registry
@@ -544,6 +1875,32 @@ func (c *Coordinator) GetMemoryStore() memory.MemoryStore {
 	return c.memoryStore
 }
 
+// SnapshotMemory returns a read-only replica of the memory store for
+// analytics, see memory.Replica
+func (c *Coordinator) SnapshotMemory(ctx context.Context) (*memory.Replica, error) {
+	snapshotter, ok := c.memoryStore.(memory.Snapshotter)
+	if !ok {
+		return nil, memory.ErrSnapshotUnsupported
+	}
+	return snapshotter.Snapshot(ctx)
+}
+
+// GetArtifactStore returns the store of task artifacts
+func (c *Coordinator) GetArtifactStore() *artifact.Store {
+	return c.artifacts
+}
+
+// GetLockManager returns the locks tasks hold on shared resources
+func (c *Coordinator) GetLockManager() *locks.Manager {
+	return c.locks
+}
+
+// Locks returns the locks tasks hold on shared resources, with the tasks
+// waiting for them
+func (c *Coordinator) Locks() []locks.Lock {
+	return c.locks.Locks()
+}
+
 // GetVotingSystem returns the voting system
 func (c *Coordinator) GetVotingSystem() *voting.DemocraticVotingSystem {
 	return c.votingSystem
@@ -559,15 +1916,56 @@ func (c *Coordinator) GetHealthMonitor() *health.HealthMonitor {
 	return c.healthMonitor
 }
 
+// State returns the state of the swarm derived from its timeline
+func (c *Coordinator) State() SwarmState {
+	return c.timeline.snapshot()
+}
+
+// Health sums up the health of the swarm for status bars and dashboards
+func (c *Coordinator) Health() SwarmHealth {
+	checks := c.healthMonitor.GetAllChecks()
+	h := SwarmHealth{
+		SystemHealth:   c.healthMonitor.GetSystemHealth(),
+		CriticalAlerts: c.timeline.snapshot().CriticalAlerts,
+		Checks:         make([]health.HealthCheck, 0, len(checks)),
+	}
+	for _, id := range slices.Sorted(maps.Keys(checks)) {
+		h.Checks = append(h.Checks, *checks[id])
+	}
+	return h
+}
+
+// SwarmHealth is the health of the swarm at a glance
+type SwarmHealth struct {
+	health.SystemHealth
+	// CriticalAlerts counts the critical alerts raised, see SwarmState
+	CriticalAlerts int
+	// Checks are the latest checks of the components, by component ID
+	Checks []health.HealthCheck
+}
+
 // GetSystemStatus returns overall system status
 func (c *Coordinator) GetSystemStatus() SystemStatus {
+	c.mu.Lock()
+	running := c.running
+	c.mu.Unlock()
+	state := c.timeline.snapshot()
+	
 	return SystemStatus{
-		Running:       c.running,
+		Running:       running,
 		AgentHealth:   c.registry.GetHealthStatus(),
 		SystemHealth:  c.healthMonitor.GetSystemHealth(),
 		MemoryStats:   c.memoryStore.GetStats(),
-		ActiveSessions: len(c.votingSystem.GetActiveSessions()),
-		QueuedTasks:   len(c.taskQueue),
+		MonitorWrites: c.monitorMemory.Stats(),
+		ActiveSessions: len(state.Votes),
+		QueuedTasks:   state.Count(TaskStateQueued),
+		RunningTasks:  state.Count(TaskStateRunning),
+		FinishedTasks: state.Finished,
+		Alerts:        state.Alerts,
+		LastEvent:     state.Seq,
+		Locks:         c.locks.Locks(),
+		WarmPools:     c.warmPoolStats(),
+		Shadows:       c.shadowStats.snapshot(),
 	}
 }
 
@@ -577,6 +1975,22 @@ type SystemStatus struct {
 	AgentHealth    map[string]agent.AgentHealth
 	SystemHealth   health.SystemHealth
 	MemoryStats    memory.MemoryStats
+	// MonitorWrites counts the memories of monitors written and dropped
+	MonitorWrites  memory.BatchStats
+	// ActiveSessions, the task counts and Alerts are derived from the
+	// timeline, see SwarmState
 	ActiveSessions int
 	QueuedTasks    int
+	RunningTasks   int
+	// FinishedTasks counts the finished tasks by final state
+	FinishedTasks  map[TaskState]int
+	Alerts         int
+	// LastEvent is the sequence number of the last timeline event
+	LastEvent      int64
+	// Locks are the locks tasks hold on shared resources
+	Locks          []locks.Lock
+	// WarmPools are the stats of the warm pools by agent type
+	WarmPools      map[agent.AgentType]WarmPoolStats
+	// Shadows sum up the results of the shadow agents by agent ID
+	Shadows        map[string]ShadowStats
 }
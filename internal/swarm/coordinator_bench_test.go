@@ -0,0 +1,105 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+	"github.com/opencode-ai/opencode/internal/swarm/perfbudget"
+)
+
+// benchAgent completes every task immediately and reports it on done
+type benchAgent struct {
+	*agent.BaseAgent
+	done chan<- string
+}
+
+func (a *benchAgent) CanHandleTask(task agent.Task) bool {
+	return true
+}
+
+func (a *benchAgent) ExecuteTask(ctx context.Context, task agent.Task) (*agent.TaskResult, error) {
+	a.done <- task.ID
+	return &agent.TaskResult{
+		TaskID:      task.ID,
+		Success:     true,
+		AgentID:     a.GetID(),
+		CompletedAt: time.Now(),
+	}, nil
+}
+
+// dispatchBurst is how many tasks the dispatch benchmarks submit before
+// waiting for them to reach an agent, keeping the queue at a realistic depth
+const dispatchBurst = 64
+
+// benchmarkDispatch submits b.N tasks to a swarm of agents in bursts and
+// waits until every task has reached an agent
+func benchmarkDispatch(b *testing.B, agents int, votingThreshold float64) {
+	coordinator, err := NewCoordinator(CoordinatorConfig{
+		SwarmConfig: agent.SwarmConfig{VotingThreshold: votingThreshold},
+		// Eviction from a full memory store is measured by the memory
+		// benchmarks
+		MemoryConfig: memory.HierarchicalMemoryConfig{MaxMemories: b.N + 1},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	done := make(chan string, dispatchBurst)
+	for i := 0; i < agents; i++ {
+		err := coordinator.GetRegistry().RegisterAgent(&benchAgent{
+			BaseAgent: agent.NewBaseAgent(agent.AgentConfig{
+				ID:   fmt.Sprintf("bench-%d", i),
+				Type: agent.AgentType("bench"),
+			}),
+			done: done,
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := coordinator.Start(); err != nil {
+		b.Fatal(err)
+	}
+	defer coordinator.Stop()
+
+	ctx := context.Background()
+	task := agent.Task{Type: "bench", Description: "benchmark task"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for submitted := 0; submitted < b.N; {
+		burst := min(dispatchBurst, b.N-submitted)
+		for i := 0; i < burst; i++ {
+			task.ID = fmt.Sprintf("task-%d", submitted+i)
+			if err := coordinator.SubmitTask(ctx, task); err != nil {
+				b.Fatal(err)
+			}
+		}
+		for i := 0; i < burst; i++ {
+			select {
+			case <-done:
+			case <-time.After(10 * time.Second):
+				b.Fatalf("only %d of %d tasks dispatched", submitted+i, b.N)
+			}
+		}
+		submitted += burst
+	}
+}
+
+func BenchmarkDispatchTask(b *testing.B) {
+	benchmarkDispatch(b, 1, 0)
+}
+
+func BenchmarkDispatchTaskWithVoting(b *testing.B) {
+	benchmarkDispatch(b, 3, 0.5)
+}
+
+func TestPerformanceBudgets(t *testing.T) {
+	perfbudget.Enforce(t,
+		perfbudget.Budget{Name: "DispatchTask", Bench: BenchmarkDispatchTask, MaxTime: 200 * time.Microsecond, MaxAllocs: 64},
+		perfbudget.Budget{Name: "DispatchTaskWithVoting", Bench: BenchmarkDispatchTaskWithVoting, MaxTime: 300 * time.Microsecond, MaxAllocs: 96},
+	)
+}
@@ -0,0 +1,217 @@
+package swarm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/opencode-ai/opencode/internal/swarm/voting"
+)
+
+// maxDecisionSlug bounds the part of a decision record's file name taken
+// from its proposal
+const maxDecisionSlug = 60
+
+var (
+	// decisionFile matches the file names of decision records, whose
+	// number orders them
+	decisionFile = regexp.MustCompile(`^(\d+)-.*\.md$`)
+	slugInvalid  = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// recordDecisions writes a decision record to the decisions directory for
+// every vote decided on the timeline
+func (c *Coordinator) recordDecisions(events <-chan pubsub.Event[TimelineEvent]) {
+	defer c.wg.Done()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if event.Payload.Type != TimelineVoteDecided {
+				continue
+			}
+			session, err := c.votingSystem.GetSession(event.Payload.Subject)
+			if err == nil {
+				_, err = writeDecisionRecord(c.decisionsDir, session, event.Payload)
+			}
+			if err != nil {
+				c.timeline.record(TimelineAlert, "decisions", "Failed to write the decision record of vote "+event.Payload.Subject+": "+err.Error(), map[string]interface{}{
+					"severity": "error",
+				})
+			}
+		}
+	}
+}
+
+// writeDecisionRecord writes the decision record of a vote session to dir,
+// numbered after the records already there, and returns its path
+func writeDecisionRecord(dir string, session *voting.VoteSession, decided TimelineEvent) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create decisions directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read decisions directory: %w", err)
+	}
+	number := 1
+	for _, entry := range entries {
+		if m := decisionFile.FindStringSubmatch(entry.Name()); m != nil {
+			if n, err := strconv.Atoi(m[1]); err == nil && n >= number {
+				number = n + 1
+			}
+		}
+	}
+
+	title := decisionTitle(session.Proposal.Description)
+	path := filepath.Join(dir, fmt.Sprintf("%04d-%s.md", number, decisionSlug(title, session.ID)))
+	if err := os.WriteFile(path, []byte(DecisionRecord(number, session, decided)), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write decision record: %w", err)
+	}
+	return path, nil
+}
+
+// DecisionRecord renders a decided vote session as an architecture decision
+// record: the proposal, its options, the votes with their reasoning and
+// the outcome. decided is the timeline event of the decision, which tells
+// votes that expired.
+func DecisionRecord(number int, session *voting.VoteSession, decided TimelineEvent) string {
+	proposal := session.Proposal
+	result, _ := voteResult(session)
+	expired, _ := decided.Details["expired"].(bool)
+
+	status := "Rejected"
+	switch {
+	case expired || result == nil:
+		status = "Expired"
+	case result.Vetoed:
+		status = "Vetoed"
+	case result.Decision:
+		status = "Accepted"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %d. %s\n\n", number, decisionTitle(proposal.Description))
+	fmt.Fprintf(&b, "- Date: %s\n", decided.Timestamp.Format(time.DateOnly))
+	fmt.Fprintf(&b, "- Status: %s\n", status)
+	fmt.Fprintf(&b, "- Vote: %s (%s)\n", session.ID, session.VoteType)
+	if proposal.ProposedBy != "" {
+		fmt.Fprintf(&b, "- Proposed by: %s\n", proposal.ProposedBy)
+	}
+	if len(proposal.Tags) > 0 {
+		fmt.Fprintf(&b, "- Tags: %s\n", strings.Join(proposal.Tags, ", "))
+	}
+
+	b.WriteString("\n## Context\n\n")
+	b.WriteString(strings.TrimSpace(proposal.Description))
+	b.WriteString("\n")
+	if len(proposal.Context) > 0 {
+		b.WriteString("\n")
+		keys := make([]string, 0, len(proposal.Context))
+		for key := range proposal.Context {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, "- %s: %s\n", key, markdownLine(fmt.Sprint(proposal.Context[key])))
+		}
+	}
+
+	b.WriteString("\n## Options\n\n")
+	if len(proposal.Options) == 0 {
+		b.WriteString("- Approve\n- Reject\n")
+	}
+	for _, option := range proposal.Options {
+		fmt.Fprintf(&b, "- %s\n", markdownLine(option))
+	}
+
+	b.WriteString("\n## Votes\n\n")
+	votes := session.GetVotes()
+	if len(votes) == 0 {
+		b.WriteString("No votes were cast.\n")
+	} else {
+		b.WriteString("| Agent | Vote | Confidence | Reasoning |\n|---|---|---|---|\n")
+		for _, vote := range votes {
+			choice := "no"
+			if vote.Decision {
+				choice = "yes"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %.2f | %s |\n", tableCell(vote.AgentID), choice, vote.Confidence, tableCell(vote.Reasoning))
+		}
+	}
+
+	b.WriteString("\n## Decision\n\n")
+	switch status {
+	case "Expired":
+		b.WriteString("The vote expired before it was decided, so the proposal was not carried out.\n")
+		if reason := detailString(decided.Details, "error"); reason != "" {
+			fmt.Fprintf(&b, "\n%s\n", markdownLine(reason))
+		}
+		return b.String()
+	case "Vetoed":
+		fmt.Fprintf(&b, "Vetoed by %s", result.VetoedBy)
+	case "Accepted":
+		b.WriteString("Accepted")
+	default:
+		b.WriteString("Rejected")
+	}
+	fmt.Fprintf(&b, " with %d yes and %d no votes (%.0f%% yes, average confidence %.2f).\n",
+		result.YesVotes, result.NoVotes, result.YesPercentage*100, result.Confidence)
+	if result.Vetoed {
+		for _, reason := range result.Reasoning {
+			if strings.HasPrefix(reason, "veto by ") {
+				fmt.Fprintf(&b, "\n%s\n", markdownLine(reason))
+			}
+		}
+	}
+	return b.String()
+}
+
+// voteResult returns the result of a completed session
+func voteResult(session *voting.VoteSession) (*voting.VoteResult, bool) {
+	if !session.IsCompleted() {
+		return nil, false
+	}
+	return session.Result, session.Result != nil
+}
+
+// decisionTitle is the first line of a proposal
+func decisionTitle(description string) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(description), "\n")
+	if title == "" {
+		return "Untitled decision"
+	}
+	return title
+}
+
+// decisionSlug turns a title into a file name, the session ID if nothing of
+// the title is left
+func decisionSlug(title, sessionID string) string {
+	slug := strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(slug) > maxDecisionSlug {
+		slug = strings.TrimRight(slug[:maxDecisionSlug], "-")
+	}
+	if slug == "" {
+		slug = slugInvalid.ReplaceAllString(strings.ToLower(sessionID), "-")
+	}
+	return slug
+}
+
+// markdownLine keeps a value on one line of a list
+func markdownLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// tableCell keeps a value in one cell of a table
+func tableCell(s string) string {
+	return strings.ReplaceAll(markdownLine(s), "|", `\|`)
+}
@@ -0,0 +1,83 @@
+package swarm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/voting"
+)
+
+func TestDecisionRecords(t *testing.T) {
+	vs := voting.NewDemocraticVotingSystem()
+	session, err := vs.CreateVoteSession(voting.VoteProposal{
+		ID:          "p1",
+		Description: "Switch the cache to Redis\n\nThe in-process cache does not survive restarts.",
+		ProposedBy:  "analyzer",
+		Options:     []string{"Redis", "Keep the in-process cache"},
+		Context:     map[string]interface{}{"task": "task-1"},
+		Deadline:    time.Now().Add(time.Minute),
+	}, voting.VoteTypeMajority, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, vote := range []voting.Vote{
+		{AgentID: "a", Decision: true, Confidence: 0.9, Reasoning: "restarts | lose the cache"},
+		{AgentID: "b", Decision: false, Confidence: 0.5, Reasoning: "one more service"},
+		{AgentID: "c", Decision: true, Confidence: 0.7},
+	} {
+		if err := vs.CastVote(session.ID, vote); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "decisions")
+	decided := TimelineEvent{Type: TimelineVoteDecided, Timestamp: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), Subject: session.ID}
+	path, err := writeDecisionRecord(dir, session, decided)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "0001-switch-the-cache-to-redis.md" {
+		t.Errorf("path = %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := string(data)
+	for _, want := range []string{
+		"# 1. Switch the cache to Redis\n",
+		"- Date: 2026-03-01\n- Status: Accepted\n",
+		"- task: task-1\n",
+		"- Redis\n- Keep the in-process cache\n",
+		`| a | yes | 0.90 | restarts \| lose the cache |`,
+		"Accepted with 2 yes and 1 no votes (67% yes",
+	} {
+		if !strings.Contains(record, want) {
+			t.Errorf("record lacks %q:\n%s", want, record)
+		}
+	}
+
+	// Records are numbered after the ones in the directory
+	expired, err := vs.CreateVoteSession(voting.VoteProposal{ID: "p2", Description: "Drop the staging database"}, voting.VoteTypeMajority, 3, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decided = TimelineEvent{Type: TimelineVoteDecided, Timestamp: decided.Timestamp, Subject: expired.ID, Details: map[string]interface{}{"expired": true}}
+	path, err = writeDecisionRecord(dir, expired, decided)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "0002-drop-the-staging-database.md" {
+		t.Errorf("path = %s", path)
+	}
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record := string(data); !strings.Contains(record, "- Status: Expired\n") || !strings.Contains(record, "No votes were cast.") {
+		t.Errorf("unexpected record of an expired vote:\n%s", record)
+	}
+}
@@ -0,0 +1,127 @@
+package swarm
+
+import (
+	"context"
+	"sync"
+
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+	"github.com/opencode-ai/opencode/internal/swarm/rules"
+)
+
+// Diagnostic severities
+const (
+	SeverityError       = "error"
+	SeverityWarning     = "warning"
+	SeverityInformation = "information"
+	SeverityHint        = "hint"
+)
+
+// Diagnostic is a problem a language server found in a file
+type Diagnostic struct {
+	Severity string
+	// Line is 1-based
+	Line    int
+	Message string
+	Source  string
+	Code    string
+}
+
+// FileDiagnostics are the diagnostics of one file. Every report replaces
+// the previous one for the file.
+type FileDiagnostics struct {
+	Path string
+	// Source names the reporter, e.g. the language server
+	Source      string
+	Diagnostics []Diagnostic
+}
+
+// Count returns the number of errors and warnings
+func (d FileDiagnostics) Count() (errors, warnings int) {
+	for _, diagnostic := range d.Diagnostics {
+		switch diagnostic.Severity {
+		case SeverityError:
+			errors++
+		case SeverityWarning:
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+// diagnosticCount is the number of errors and warnings last reported for a
+// file
+type diagnosticCount struct {
+	errors, warnings int
+}
+
+// diagnosticCounts remembers the last counts per file to tell new problems
+// from known ones
+type diagnosticCounts struct {
+	mu     sync.Mutex
+	byPath map[string]diagnosticCount
+}
+
+func newDiagnosticCounts() *diagnosticCounts {
+	return &diagnosticCounts{byPath: make(map[string]diagnosticCount)}
+}
+
+// update stores the counts of a file and returns the previous ones
+func (d *diagnosticCounts) update(path string, count diagnosticCount) diagnosticCount {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	previous := d.byPath[path]
+	if count == (diagnosticCount{}) {
+		delete(d.byPath, path)
+	} else {
+		d.byPath[path] = count
+	}
+	return previous
+}
+
+// HandleDiagnostics takes the diagnostics reported for a file. When its
+// error or warning count changed, they are stored as an episodic memory and
+// the rules are evaluated against a "diagnostics" event with the fields
+// path, source, errors, warnings, new_errors and new_warnings. New counts
+// are the increase since the previous report, zero when problems were
+// fixed.
+func (c *Coordinator) HandleDiagnostics(ctx context.Context, report FileDiagnostics) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.ctx.Err() != nil {
+		return ErrCoordinatorStopped
+	}
+
+	errors, warnings := report.Count()
+	previous := c.diagnostics.update(report.Path, diagnosticCount{errors, warnings})
+	if previous.errors == errors && previous.warnings == warnings {
+		return nil
+	}
+
+	mem := memory.Memory{
+		Type:     memory.MemoryTypeEpisodic,
+		Content:  report,
+		Tags:     []string{"lsp", "diagnostics"},
+		Priority: memory.PriorityNormal,
+		Metadata: map[string]interface{}{"path": report.Path},
+	}
+	if errors > previous.errors {
+		mem.Priority = memory.PriorityHigh
+	}
+	if err := c.memoryStore.Store(c.ctx, mem); err != nil {
+		return err
+	}
+
+	return c.ruleEngine.EvaluateRules(c.ctx, rules.RuleContext{
+		EventType: "diagnostics",
+		EventData: map[string]interface{}{
+			"path":         report.Path,
+			"source":       report.Source,
+			"errors":       errors,
+			"warnings":     warnings,
+			"new_errors":   max(errors-previous.errors, 0),
+			"new_warnings": max(warnings-previous.warnings, 0),
+		},
+		Timestamp: c.clock.Now(),
+	})
+}
@@ -0,0 +1,87 @@
+package swarm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+)
+
+const newErrorsRule = `
+id: new-errors
+enabled: true
+condition: {type: field, field: new_errors, operator: ">=", value: 3}
+actions:
+  - type: submit_task
+    task_type: docs
+    description: Fix the errors in ${path}
+    priority: 5
+`
+
+func TestReportDiagnostics(t *testing.T) {
+	agent.RegisterFactory(agent.AgentTypeDocumentation, func(config agent.AgentConfig) (agent.Agent, error) {
+		return &echoAgent{BaseAgent: agent.NewBaseAgent(config)}, nil
+	})
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "diagnostics.yaml"), []byte(newErrorsRule), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(FileConfig{
+		Agents:   []AgentFileConfig{{ID: "echo", Type: string(agent.AgentTypeDocumentation)}},
+		RulesDir: dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := s.Subscribe(ctx)
+
+	errors := func(n int) FileDiagnostics {
+		report := FileDiagnostics{Path: "main.go", Source: "gopls"}
+		for i := 0; i < n; i++ {
+			report.Diagnostics = append(report.Diagnostics, Diagnostic{Severity: SeverityError, Line: i + 1, Message: "undefined: x"})
+		}
+		report.Diagnostics = append(report.Diagnostics, Diagnostic{Severity: SeverityHint, Line: 1, Message: "unused"})
+		return report
+	}
+	// Two new errors, then three more, then the same again
+	for _, n := range []int{2, 5, 5} {
+		if err := s.ReportDiagnostics(ctx, errors(n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	memories, err := s.Query(ctx, MemoryQuery{Type: MemoryTypeEpisodic, Tags: []string{"lsp", "diagnostics"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memories) != 2 {
+		t.Errorf("%d diagnostics memories, want 2 for the changes", len(memories))
+	}
+
+	var submitted []string
+	for {
+		select {
+		case event := <-events:
+			if event.Payload.Type == TimelineTaskSubmitted {
+				submitted = append(submitted, event.Payload.Summary)
+			}
+			if event.Payload.Type != TimelineTaskFinished {
+				continue
+			}
+		case <-ctx.Done():
+			t.Fatalf("submitted tasks: %v", submitted)
+		}
+		break
+	}
+	if len(submitted) != 1 || submitted[0] != "Fix the errors in main.go" {
+		t.Errorf("submitted tasks: %v", submitted)
+	}
+}
@@ -0,0 +1,85 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+// EditOutcome is what became of the edits a task proposed, see
+// agent.FileEdit. The lists hold the paths of the edits.
+type EditOutcome struct {
+	TaskID   string
+	AgentID  string
+	Applied  []string
+	Rejected []string
+	// Stale edits were not offered, their file is gone or unreadable
+	Stale []string
+	// Rebased edits were offered merged with the changes made to their file
+	// since the agent read it, Conflicted ones with conflict markers where
+	// the changes overlap. Both are also Applied or Rejected.
+	Rebased    []string
+	Conflicted []string
+}
+
+// Summary describes the outcome in one line
+func (o EditOutcome) Summary() string {
+	total := len(o.Applied) + len(o.Rejected) + len(o.Stale)
+	summary := fmt.Sprintf("%d of %d edits proposed by task %s were applied", len(o.Applied), total, o.TaskID)
+	if len(o.Rejected) > 0 {
+		summary += "; rejected: " + strings.Join(o.Rejected, ", ")
+	}
+	if len(o.Stale) > 0 {
+		summary += "; stale: " + strings.Join(o.Stale, ", ")
+	}
+	if len(o.Rebased) > 0 {
+		summary += "; rebased on concurrent changes: " + strings.Join(o.Rebased, ", ")
+	}
+	if len(o.Conflicted) > 0 {
+		summary += "; conflicting with concurrent changes: " + strings.Join(o.Conflicted, ", ")
+	}
+	return summary
+}
+
+// HandleEditOutcome remembers how the edits a task proposed were received,
+// so later tasks can learn what is accepted, along with the files changed
+// and the vote that let the task run
+func (c *Coordinator) HandleEditOutcome(ctx context.Context, outcome EditOutcome) error {
+	tags := []string{"edits", "outcome"}
+	if len(outcome.Applied) > 0 {
+		tags = append(tags, "accepted")
+	}
+	if len(outcome.Rejected) > 0 {
+		tags = append(tags, "rejected")
+	}
+	if len(outcome.Conflicted) > 0 {
+		tags = append(tags, "conflict")
+	}
+	voteID := ""
+	if record, err := c.tasks.get(outcome.TaskID); err == nil {
+		voteID = record.VoteID
+	}
+	mem := memory.Memory{
+		Type:     memory.MemoryTypeProcedural,
+		Content:  outcome.Summary(),
+		Tags:     tags,
+		Priority: memory.PriorityHigh,
+		Metadata: map[string]interface{}{
+			"task_id":    outcome.TaskID,
+			"agent_id":   outcome.AgentID,
+			"vote_id":    voteID,
+			"files":      outcome.Applied,
+			"applied":    len(outcome.Applied),
+			"rejected":   len(outcome.Rejected),
+			"stale":      len(outcome.Stale),
+			"rebased":    len(outcome.Rebased),
+			"conflicted": len(outcome.Conflicted),
+		},
+	}
+	if err := c.memoryStore.Store(ctx, mem); err != nil {
+		return fmt.Errorf("failed to store edit outcome: %w", err)
+	}
+	return nil
+}
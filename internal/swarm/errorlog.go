@@ -0,0 +1,193 @@
+package swarm
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+	"github.com/opencode-ai/opencode/internal/swarm/monitor"
+)
+
+const (
+	// errorRepeatWindow is how long an error is not handled again after it
+	// was logged
+	errorRepeatWindow = 10 * time.Minute
+	// maxRecentErrors bounds the errors remembered for errorRepeatWindow
+	maxRecentErrors = 1000
+	// maxFixLength bounds the description of a fix kept in memory
+	maxFixLength = 2000
+)
+
+// errorLevels are the log levels of errors
+var errorLevels = map[string]bool{
+	"ERR":      true,
+	"ERROR":    true,
+	"FATAL":    true,
+	"CRITICAL": true,
+	"PANIC":    true,
+}
+
+// errorPrefixes start the messages of crashes that are logged without a
+// level
+var errorPrefixes = []string{"panic:", "fatal error:", "Traceback (most recent call last):", "Uncaught "}
+
+// minInferredErrorConfidence is how sure an inferred error level must be for
+// the entry to be handled as an error, so a line merely mentioning a
+// failure queues no task
+const minInferredErrorConfidence = 0.7
+
+// isErrorEntry reports whether a log entry is about an error, by its level
+// or, for plain lines, a level or crash at the start of the message
+func isErrorEntry(entry monitor.LogEntry) bool {
+	if errorLevels[strings.ToUpper(entry.Level)] {
+		inferred, _ := entry.Fields[monitor.FieldLevelInferred].(bool)
+		confidence, _ := entry.Fields[monitor.FieldLevelConfidence].(float64)
+		if !inferred || confidence >= minInferredErrorConfidence {
+			return true
+		}
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(entry.Message), "\n")
+	for _, prefix := range errorPrefixes {
+		if strings.HasPrefix(first, prefix) {
+			return true
+		}
+	}
+	// e.g. 2024/01/02 15:04:05 [ERROR] failed
+	fields := strings.Fields(first)
+	for _, field := range fields[:min(len(fields), 4)] {
+		if errorLevels[strings.ToUpper(strings.Trim(field, "[]:"))] {
+			return true
+		}
+	}
+	return false
+}
+
+// recentErrors remembers when errors were last handled, by signature, so a
+// repeated error is handled once, and how often they were handled
+type recentErrors struct {
+	mu      sync.Mutex
+	seen    map[string]time.Time
+	handled map[string]int
+}
+
+func newRecentErrors() *recentErrors {
+	return &recentErrors{seen: make(map[string]time.Time), handled: make(map[string]int)}
+}
+
+// first reports whether an error was not seen within errorRepeatWindow
+// before now, and remembers it
+func (r *recentErrors) first(signature string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if last, ok := r.seen[signature]; ok && now.Sub(last) < errorRepeatWindow {
+		return false
+	}
+	if len(r.seen) >= maxRecentErrors {
+		for s, last := range r.seen {
+			if now.Sub(last) >= errorRepeatWindow {
+				delete(r.seen, s)
+				delete(r.handled, s)
+			}
+		}
+	}
+	r.seen[signature] = now
+	r.handled[signature]++
+	return true
+}
+
+// times returns how often an error was handled, coming back after
+// errorRepeatWindow each time
+func (r *recentErrors) times(signature string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.handled[signature]
+}
+
+// handleErrorLog queues a handle_error task for an error log entry if an
+// agent handles errors, and a github_issue task for an error that keeps
+// coming back. Errors repeated within errorRepeatWindow are ignored.
+func (c *Coordinator) handleErrorLog(entry monitor.LogEntry, signature string) {
+	if !c.recentErrors.first(signature, c.clock.Now()) {
+		return
+	}
+	task := agent.Task{
+		Type:        agent.TaskTypeHandleError,
+		Description: "Handle error: " + signature,
+		Input: map[string]interface{}{
+			"level":     entry.Level,
+			"message":   entry.Message,
+			"source":    entry.Source,
+			"signature": signature,
+		},
+		IdempotencyKey: "handle_error:" + signature,
+	}
+	if c.canHandle(task) {
+		_ = c.SubmitTask(c.ctx, task)
+	}
+	if c.recentErrors.times(signature) == recurringErrorTimes {
+		c.openErrorIssue(entry, signature)
+	}
+}
+
+// canHandle reports whether an agent that is not a shadow can take a task
+func (c *Coordinator) canHandle(task agent.Task) bool {
+	for _, ag := range c.registry.GetAllAgents() {
+		if !c.registry.IsShadow(ag.GetID()) && ag.CanHandleTask(task) {
+			return true
+		}
+	}
+	return false
+}
+
+// queueFollowUps queues the tasks a result asks for as its "tasks" output,
+// recording the task they were spawned by
+func (c *Coordinator) queueFollowUps(result *agent.TaskResult) {
+	tasks, _ := result.Output["tasks"].([]agent.Task)
+	for _, task := range tasks {
+		if task.ID == "" {
+			task.ID = uuid.New().String()
+		}
+		if c.SubmitTask(c.ctx, task) == nil {
+			c.tasks.setSpawnedBy(task.ID, result.TaskID)
+		}
+	}
+}
+
+// storeFix remembers how a patch task fixed an error, for the error
+// handler to look up when the error comes back. A fix replaces the one
+// remembered for the same error.
+func (c *Coordinator) storeFix(result *agent.TaskResult) {
+	if !result.Success {
+		return
+	}
+	record, err := c.tasks.get(result.TaskID)
+	if err != nil || record.Task.Type != agent.TaskTypePatch {
+		return
+	}
+	signature, _ := record.Task.Input["signature"].(string)
+	if signature == "" {
+		return
+	}
+	fix := fmt.Sprint(result.Output["response"])
+	if len(fix) > maxFixLength {
+		fix = fix[:maxFixLength]
+	}
+	mem := memory.Memory{
+		ID:       "fix:" + signature,
+		Type:     memory.MemoryTypeSemantic,
+		Content:  fmt.Sprintf("%s was fixed by task %s: %s", signature, result.TaskID, fix),
+		Tags:     []string{"fix", "error"},
+		Priority: memory.PriorityHigh,
+		Metadata: map[string]interface{}{
+			"task_id":   result.TaskID,
+			"agent_id":  result.AgentID,
+			"signature": signature,
+			"path":      record.Task.Input["path"],
+		},
+	}
+	_ = c.memoryStore.Store(c.ctx, mem)
+}
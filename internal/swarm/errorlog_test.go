@@ -0,0 +1,79 @@
+package swarm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/monitor"
+)
+
+func TestErrorLogPatches(t *testing.T) {
+	agent.RegisterFactory(agent.AgentTypeExecutor, func(config agent.AgentConfig) (agent.Agent, error) {
+		return &echoAgent{BaseAgent: agent.NewBaseAgent(config)}, nil
+	})
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {\n\tpanic(\"boom\")\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(FileConfig{Agents: []AgentFileConfig{
+		{ID: "errors", Type: string(agent.AgentTypeErrorHandler), Options: map[string]string{"dir": dir}},
+		{ID: "patcher", Type: string(agent.AgentTypeExecutor)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := s.Subscribe(ctx)
+
+	entry := monitor.LogEntry{
+		Timestamp: time.Now(),
+		Level:     "INFO",
+		Source:    "app.log",
+		Message:   "panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t" + filepath.Join(dir, "main.go") + ":4 +0x25",
+	}
+	for _, e := range []monitor.LogEntry{entry, entry, {Level: "INFO", Message: "listening on :8080"}} {
+		s.coordinator.handleLogEntry(e)
+	}
+
+	// The fix is remembered once the voted patch ran
+	var submitted []string
+	voted := false
+	for {
+		select {
+		case event := <-events:
+			switch event.Payload.Type {
+			case TimelineTaskSubmitted:
+				submitted = append(submitted, event.Payload.Details["type"].(string))
+			case TimelineVoteDecided:
+				voted = true
+			}
+			continue
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			t.Fatalf("submitted tasks: %v", submitted)
+		}
+		fixes, err := s.Query(ctx, MemoryQuery{Type: MemoryTypeSemantic, Tags: []string{"fix"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(fixes) == 1 {
+			break
+		}
+	}
+
+	want := []string{agent.TaskTypeHandleError, agent.TaskTypePatch}
+	if len(submitted) != len(want) || submitted[0] != want[0] || submitted[1] != want[1] {
+		t.Errorf("submitted tasks = %v, want one of each of %v", submitted, want)
+	}
+	if !voted {
+		t.Error("the patch ran without a vote")
+	}
+}
@@ -0,0 +1,81 @@
+package swarm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Common errors. Errors returned by the coordinator wrap these, test for
+// them with errors.Is.
+var (
+	// ErrQueueFull means the task queue is at its limit. Submitting again
+	// later may succeed.
+	ErrQueueFull = errors.New("task queue full")
+	// ErrTaskNotFound means no task with the ID was submitted, or it was
+	// forgotten after finishing
+	ErrTaskNotFound = errors.New("task not found")
+	// ErrTaskExists means a task with the same ID is still queued or running
+	ErrTaskExists = errors.New("task already submitted")
+	// ErrInvalidTaskState means the task is in a state the operation does
+	// not apply to, e.g. cancelling a completed task
+	ErrInvalidTaskState = errors.New("invalid task state")
+	// ErrTaskFailed means the task finished without success
+	ErrTaskFailed = errors.New("task failed")
+	// ErrTaskTypeRequired means a task was submitted without a type
+	ErrTaskTypeRequired = errors.New("task type is required")
+	// ErrBatchRejected means some tasks of a batch were not queued, see
+	// SubmitTasks
+	ErrBatchRejected = errors.New("task batch rejected")
+	// ErrPromotionNotFound means no memory with the ID is waiting for
+	// promotion, see PromotionCandidates
+	ErrPromotionNotFound = errors.New("promotion candidate not found")
+	// ErrPruneRejected means a prune deleting many memories was voted
+	// down or not approved in time, see PruneVoteConfig
+	ErrPruneRejected = errors.New("memory prune rejected")
+	// ErrResultRejected means the reviewer verifying the result of a task
+	// failed it, see VerificationConfig
+	ErrResultRejected = errors.New("result rejected")
+	// ErrResultUnverified means no reviewer verified the result of a task
+	// that requires it
+	ErrResultUnverified = errors.New("result not verified")
+	// ErrNoFileHistory means the coordinator has no file history to roll
+	// tasks back with, see UseFileHistory
+	ErrNoFileHistory = errors.New("no file history")
+	// ErrNoTaskChanges means the file history has no change the task made
+	// to roll back
+	ErrNoTaskChanges = errors.New("task changed no files")
+	// ErrTaskCancelled means the task was cancelled before it finished
+	ErrTaskCancelled = errors.New("task cancelled")
+	// ErrCoordinatorStopped means the coordinator was stopped and accepts
+	// no more work
+	ErrCoordinatorStopped = errors.New("coordinator stopped")
+	// ErrCoordinatorRunning means Start was called twice
+	ErrCoordinatorRunning = errors.New("coordinator already running")
+	// ErrCoordinatorNotRunning means the coordinator was not started yet or
+	// is stopping
+	ErrCoordinatorNotRunning = errors.New("coordinator not running")
+)
+
+// TaskError describes an error about a single task. Use errors.As to get
+// the task and its state; Err is one of the common errors.
+type TaskError struct {
+	TaskID string
+	State  TaskState
+	Reason string
+	Err    error
+}
+
+func (e *TaskError) Error() string {
+	msg := fmt.Sprintf("%v: %s", e.Err, e.TaskID)
+	if e.State != "" {
+		msg += fmt.Sprintf(" is %s", e.State)
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,75 @@
+package swarm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+)
+
+func TestCommandTasks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands run with sh")
+	}
+	// Other tests replace the executor
+	agent.RegisterFactory(agent.AgentTypeExecutor, func(config agent.AgentConfig) (agent.Agent, error) {
+		return agent.NewExecutorAgent(config)
+	})
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "build"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(FileConfig{Agents: []AgentFileConfig{
+		{ID: "exec", Type: string(agent.AgentTypeExecutor), Options: map[string]string{"dir": dir}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	events := s.Subscribe(ctx)
+
+	run := func(command string) (progress []string, voted bool) {
+		id, err := s.SubmitTask(ctx, Task{Type: agent.TaskTypeCommand, Description: command, Input: map[string]interface{}{"command": command}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := s.Result(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Success {
+			t.Fatalf("%s failed: %v", command, result.Error)
+		}
+		for {
+			select {
+			case event := <-events:
+				switch event.Payload.Type {
+				case TimelineTaskProgress:
+					progress = append(progress, event.Payload.Summary)
+				case TimelineVoteDecided:
+					voted = true
+				}
+			case <-time.After(50 * time.Millisecond):
+				return progress, voted
+			}
+		}
+	}
+
+	if progress, voted := run("echo one; echo two"); voted || len(progress) != 2 || progress[0] != "one" || progress[1] != "two" {
+		t.Errorf("progress = %q, voted = %v, want two lines without a vote", progress, voted)
+	}
+	if _, voted := run("rm -rf build"); !voted {
+		t.Error("the destructive command ran without a vote")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "build")); !os.IsNotExist(err) {
+		t.Error("the voted command did not run")
+	}
+}
@@ -0,0 +1,184 @@
+package swarm
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/monitor"
+)
+
+const (
+	// recurringErrorTimes is how often an error is handled, coming back
+	// after errorRepeatWindow each time, before an issue is opened for it
+	recurringErrorTimes = 3
+	// maxIssueMessage bounds the error message quoted in an issue
+	maxIssueMessage = 4000
+)
+
+// pullRequests are the pull requests the swarm opened, with the time their
+// review comments were last read
+type pullRequests struct {
+	mu     sync.Mutex
+	synced map[int]time.Time
+}
+
+func newPullRequests() *pullRequests {
+	return &pullRequests{synced: make(map[int]time.Time)}
+}
+
+// track starts following the reviews of a pull request
+func (p *pullRequests) track(number int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.synced[number]; !ok {
+		p.synced[number] = time.Time{}
+	}
+}
+
+// forget stops following a closed pull request
+func (p *pullRequests) forget(number int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.synced, number)
+}
+
+// setSynced records that the comments of a followed pull request were read
+// up to a time
+func (p *pullRequests) setSynced(number int, at time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if last, ok := p.synced[number]; ok && at.After(last) {
+		p.synced[number] = at
+	}
+}
+
+// list returns the followed pull requests by number, with the time their
+// comments were last read
+func (p *pullRequests) list() ([]int, map[int]time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	numbers := make([]int, 0, len(p.synced))
+	synced := make(map[int]time.Time, len(p.synced))
+	for number, at := range p.synced {
+		numbers = append(numbers, number)
+		synced[number] = at
+	}
+	sort.Ints(numbers)
+	return numbers, synced
+}
+
+// openErrorIssue queues a github_issue task for an error that keeps coming
+// back, if an agent opens issues. The agent finds the issue already open
+// for the error by its signature.
+func (c *Coordinator) openErrorIssue(entry monitor.LogEntry, signature string) {
+	message := entry.Message
+	if len(message) > maxIssueMessage {
+		message = message[:maxIssueMessage]
+	}
+	body := fmt.Sprintf("The swarm handled this error %d times, at least %s apart, and it keeps coming back.\n\nSource: %s\nLevel: %s\n\n```\n%s\n```",
+		recurringErrorTimes, errorRepeatWindow, entry.Source, entry.Level, message)
+	task := agent.Task{
+		Type:        agent.TaskTypeGitHubIssue,
+		Description: "Open an issue for recurring error: " + signature,
+		Input: map[string]interface{}{
+			"title":     "Recurring error: " + signature,
+			"body":      body,
+			"signature": signature,
+			"labels":    []string{"bug"},
+		},
+		IdempotencyKey: "github_issue:" + signature,
+	}
+	if c.canHandle(task) {
+		_ = c.SubmitTask(c.ctx, task)
+	}
+}
+
+// handleGitHubResult queues a github_pr task for the patch of a patch task
+// the swarm voted for, and follows the pull requests opened and the
+// reviews read by the GitHub agent
+func (c *Coordinator) handleGitHubResult(result *agent.TaskResult) {
+	if !result.Success {
+		return
+	}
+	record, err := c.tasks.get(result.TaskID)
+	if err != nil {
+		return
+	}
+	task := record.Task
+	switch task.Type {
+	case agent.TaskTypePatch:
+		patch, ok := agent.ResultPatch(result.Output)
+		if !ok || record.VoteID == "" {
+			return
+		}
+		pr := agent.Task{
+			Type:        agent.TaskTypeGitHubPR,
+			Description: "Open a pull request for task " + task.ID,
+			Input: map[string]interface{}{
+				"title":   task.Description,
+				"body":    fmt.Sprintf("%s\n\nPatch of swarm task %s, approved by vote %s.", result.Output["response"], task.ID, record.VoteID),
+				"patch":   patch,
+				"task_id": task.ID,
+			},
+			IdempotencyKey: "github_pr:" + task.ID,
+		}
+		// Fixes of review comments go to the pull request reviewed
+		if number, ok := task.Input["pull_request"]; ok {
+			pr.Description = fmt.Sprintf("Push the fix of task %s to pull request #%v", task.ID, number)
+			pr.Input["pull_request"] = number
+			pr.Input["branch"] = task.Input["branch"]
+		}
+		if c.canHandle(pr) {
+			_ = c.SubmitTask(c.ctx, pr)
+		}
+	case agent.TaskTypeGitHubPR:
+		if number := detailInt(result.Output, "pull_request"); number > 0 {
+			c.pullRequests.track(number)
+		}
+	case agent.TaskTypeGitHubReviews:
+		number := detailInt(result.Output, "pull_request")
+		if closed, _ := result.Output["closed"].(bool); closed {
+			c.pullRequests.forget(number)
+			return
+		}
+		if at, ok := task.Input["synced_at"].(time.Time); ok {
+			c.pullRequests.setSynced(number, at)
+		}
+	}
+}
+
+// syncReviewsPeriodically queues a github_reviews task for every pull
+// request the swarm opened each interval, reading the comments made since
+// the last one that succeeded. The patch tasks of the comments are queued
+// with the results.
+func (c *Coordinator) syncReviewsPeriodically(interval time.Duration) {
+	defer c.wg.Done()
+	ticker := c.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C():
+			numbers, synced := c.pullRequests.list()
+			for _, number := range numbers {
+				task := agent.Task{
+					Type:        agent.TaskTypeGitHubReviews,
+					Description: fmt.Sprintf("Read the reviews of pull request #%d", number),
+					Input: map[string]interface{}{
+						"pull_request": number,
+						"since":        synced[number],
+						"synced_at":    now,
+					},
+					IdempotencyKey: fmt.Sprintf("github_reviews:%d:%d", number, now.Unix()),
+				}
+				if c.canHandle(task) {
+					_ = c.SubmitTask(c.ctx, task)
+				}
+			}
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
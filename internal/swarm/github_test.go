@@ -0,0 +1,95 @@
+package swarm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+	"github.com/opencode-ai/opencode/internal/swarm/monitor"
+)
+
+// githubStub takes the tasks of a GitHub agent without running them
+type githubStub struct {
+	*agent.BaseAgent
+}
+
+func (a *githubStub) CanHandleTask(task agent.Task) bool {
+	switch task.Type {
+	case agent.TaskTypeGitHubIssue, agent.TaskTypeGitHubPR, agent.TaskTypeGitHubReviews:
+		return true
+	}
+	return false
+}
+
+func (a *githubStub) ExecuteTask(ctx context.Context, task agent.Task) (*agent.TaskResult, error) {
+	return nil, ctx.Err()
+}
+
+// queuedOfType returns the queued tasks of a type
+func queuedOfType(c *Coordinator, taskType string) []agent.Task {
+	var tasks []agent.Task
+	for _, record := range c.ListTasks() {
+		if record.Task.Type == taskType && record.State == TaskStateQueued {
+			tasks = append(tasks, record.Task)
+		}
+	}
+	return tasks
+}
+
+func TestGitHubFollowUps(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	c, err := NewCoordinator(CoordinatorConfig{Clock: clk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cancelFunc()
+	stub := &githubStub{agent.NewBaseAgent(agent.AgentConfig{ID: "github-1", Type: agent.AgentTypeGitHub})}
+	if err := c.GetRegistry().RegisterAgent(stub); err != nil {
+		t.Fatal(err)
+	}
+
+	// An error handled for the third time gets an issue
+	entry := monitor.LogEntry{Level: "ERROR", Source: "app.log", Message: "db: connection refused"}
+	for i := 1; i <= recurringErrorTimes; i++ {
+		if issues := queuedOfType(c, agent.TaskTypeGitHubIssue); len(issues) != 0 {
+			t.Fatalf("issue queued after the error was handled %d times", i-1)
+		}
+		c.handleErrorLog(entry, "db: connection refused")
+		clk.Advance(errorRepeatWindow)
+	}
+	issues := queuedOfType(c, agent.TaskTypeGitHubIssue)
+	if len(issues) != 1 || issues[0].Input["signature"] != "db: connection refused" {
+		t.Fatalf("issues = %v, want one for the signature", issues)
+	}
+
+	// The patch of a voted patch task gets a pull request
+	patch := &agent.Patch{Files: []agent.FileEdit{{Path: "/repo/main.go", Proposed: "package main\n"}}}
+	for _, id := range []string{"voted", "unvoted"} {
+		if err := c.SubmitTask(c.ctx, agent.Task{ID: id, Type: agent.TaskTypePatch, Description: "Fix " + id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.tasks.setVote("voted", "vote-1")
+	for _, id := range []string{"voted", "unvoted"} {
+		c.handleGitHubResult(&agent.TaskResult{TaskID: id, Success: true, Output: map[string]interface{}{agent.OutputPatch: patch}})
+	}
+	prs := queuedOfType(c, agent.TaskTypeGitHubPR)
+	if len(prs) != 1 || prs[0].Input["task_id"] != "voted" || prs[0].Input["patch"] != patch {
+		t.Fatalf("pull request tasks = %v, want one for the voted patch", prs)
+	}
+
+	// Pull requests opened are followed until they are closed
+	c.handleGitHubResult(&agent.TaskResult{TaskID: prs[0].ID, Success: true, Output: map[string]interface{}{"pull_request": 12}})
+	if numbers, _ := c.pullRequests.list(); len(numbers) != 1 || numbers[0] != 12 {
+		t.Fatalf("followed pull requests = %v, want 12", numbers)
+	}
+	if err := c.SubmitTask(c.ctx, agent.Task{ID: "reviews", Type: agent.TaskTypeGitHubReviews, Input: map[string]interface{}{"pull_request": 12}}); err != nil {
+		t.Fatal(err)
+	}
+	c.handleGitHubResult(&agent.TaskResult{TaskID: "reviews", Success: true, Output: map[string]interface{}{"pull_request": 12, "closed": true}})
+	if numbers, _ := c.pullRequests.list(); len(numbers) != 0 {
+		t.Errorf("followed pull requests = %v, want none once closed", numbers)
+	}
+}
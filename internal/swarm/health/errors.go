@@ -0,0 +1,13 @@
+package health
+
+import "errors"
+
+// Common errors
+var (
+	// ErrComponentNotFound means no component with the ID is checked
+	ErrComponentNotFound = errors.New("component not found")
+	// ErrMonitorStopped means the monitor was stopped and cannot start again
+	ErrMonitorStopped = errors.New("health monitor stopped")
+	// ErrMonitorRunning means Start was called twice
+	ErrMonitorRunning = errors.New("health monitor already running")
+)
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
 )
 
 // HealthStatus represents the health state of a component
@@ -34,10 +36,17 @@ type HealthMonitor struct {
 	mu            sync.RWMutex
 	checkInterval time.Duration
 	alertThreshold float64
+	clock         clock.Clock
 	
 	// Recovery strategies
 	recoveryStrategies map[string]RecoveryStrategy
 	
+	// Probes report the health of their component on every check
+	probes map[string]Probe
+	
+	// Sinks notified of every alert, e.g. the TUI notification layer
+	alertSinks []AlertSink
+	
 	// Event channels
 	alertChan   chan HealthAlert
 	recoveryChan chan RecoveryAction
@@ -45,6 +54,11 @@ type HealthMonitor struct {
 	ctx        context.Context
 	cancelFunc context.CancelFunc
 	wg         sync.WaitGroup
+	
+	// started and stopped are guarded by mu. Once stopped, alerts are no
+	// longer sent to the closed channels.
+	started bool
+	stopped bool
 }
 
 // HealthAlert represents a health alert
@@ -56,6 +70,20 @@ type HealthAlert struct {
 	Timestamp   time.Time
 }
 
+// AlertSink receives alerts as they are raised. Sinks are called while the
+// monitor holds its lock, so they must not block.
+type AlertSink interface {
+	HandleAlert(alert HealthAlert)
+}
+
+// AlertSinkFunc adapts a function to the AlertSink interface
+type AlertSinkFunc func(alert HealthAlert)
+
+// HandleAlert calls f
+func (f AlertSinkFunc) HandleAlert(alert HealthAlert) {
+	f(alert)
+}
+
 // AlertSeverity defines alert importance
 type AlertSeverity string
 
@@ -99,6 +127,9 @@ type HealthMonitorConfig struct {
 	AlertThreshold float64
 	AlertBuffer    int
 	RecoveryBuffer int
+	// Clock drives the check interval and timestamps, the system clock if
+	// nil
+	Clock          clock.Clock
 }
 
 // NewHealthMonitor creates a new health monitor
@@ -115,6 +146,9 @@ func NewHealthMonitor(config HealthMonitorConfig) *HealthMonitor {
 	if config.RecoveryBuffer <= 0 {
 		config.RecoveryBuffer = 100
 	}
+	if config.Clock == nil {
+		config.Clock = clock.Real
+	}
 	
 	ctx, cancel := context.WithCancel(context.Background())
 	
@@ -122,7 +156,9 @@ func NewHealthMonitor(config HealthMonitorConfig) *HealthMonitor {
 		checks:             make(map[string]*HealthCheck),
 		checkInterval:      config.CheckInterval,
 		alertThreshold:     config.AlertThreshold,
+		clock:              config.Clock,
 		recoveryStrategies: make(map[string]RecoveryStrategy),
+		probes:             make(map[string]Probe),
 		alertChan:          make(chan HealthAlert, config.AlertBuffer),
 		recoveryChan:       make(chan RecoveryAction, config.RecoveryBuffer),
 		ctx:                ctx,
@@ -130,18 +166,43 @@ func NewHealthMonitor(config HealthMonitorConfig) *HealthMonitor {
 	}
 }
 
-// Start begins health monitoring
+// Start begins health monitoring. A stopped monitor cannot be started
+// again.
 func (hm *HealthMonitor) Start() error {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	
+	if hm.stopped {
+		return ErrMonitorStopped
+	}
+	if hm.started {
+		return ErrMonitorRunning
+	}
+	hm.started = true
+	
 	hm.wg.Add(2)
 	go hm.monitorLoop()
 	go hm.recoveryLoop()
 	return nil
 }
 
-// Stop stops health monitoring
+// Stop stops health monitoring and closes the alert and recovery channels.
+// Checks can still be updated afterwards, but raise no alerts. Calling Stop
+// again has no effect.
 func (hm *HealthMonitor) Stop() error {
+	hm.mu.Lock()
+	if hm.stopped {
+		hm.mu.Unlock()
+		return nil
+	}
+	hm.stopped = true
+	hm.mu.Unlock()
+	
 	hm.cancelFunc()
 	hm.wg.Wait()
+	
+	// The loops have exited and triggerAlert checks stopped under the
+	// lock, so nothing sends on the channels any more
 	close(hm.alertChan)
 	close(hm.recoveryChan)
 	return nil
@@ -156,16 +217,28 @@ func (hm *HealthMonitor) RegisterCheck(componentID string) {
 		ComponentID: componentID,
 		Status:      HealthStatusHealthy,
 		Score:       1.0,
-		Timestamp:   time.Now(),
+		Timestamp:   hm.clock.Now(),
 	}
 }
 
+// Probe reports the health of a component
+type Probe func() HealthCheck
+
+// RegisterProbe adds a component whose health is reported by a probe every
+// check interval, instead of by calls to UpdateCheck
+func (hm *HealthMonitor) RegisterProbe(componentID string, probe Probe) {
+	hm.RegisterCheck(componentID)
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	hm.probes[componentID] = probe
+}
+
 // UpdateCheck updates a health check result
 func (hm *HealthMonitor) UpdateCheck(check HealthCheck) {
 	hm.mu.Lock()
 	defer hm.mu.Unlock()
 	
-	check.Timestamp = time.Now()
+	check.Timestamp = hm.clock.Now()
 	hm.checks[check.ComponentID] = &check
 	
 	// Trigger alert if unhealthy
@@ -174,6 +247,17 @@ func (hm *HealthMonitor) UpdateCheck(check HealthCheck) {
 	}
 }
 
+// RaiseAlert raises an alert for a check that is not monitored, e.g. a
+// finding of an audit. The check is not recorded.
+func (hm *HealthMonitor) RaiseAlert(check HealthCheck) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	if check.Timestamp.IsZero() {
+		check.Timestamp = hm.clock.Now()
+	}
+	hm.triggerAlert(check)
+}
+
 // GetCheck retrieves the latest health check for a component
 func (hm *HealthMonitor) GetCheck(componentID string) (*HealthCheck, error) {
 	hm.mu.RLock()
@@ -181,12 +265,32 @@ func (hm *HealthMonitor) GetCheck(componentID string) (*HealthCheck, error) {
 	
 	check, exists := hm.checks[componentID]
 	if !exists {
-		return nil, fmt.Errorf("component not found: %s", componentID)
+		return nil, fmt.Errorf("%w: %s", ErrComponentNotFound, componentID)
 	}
 	
 	return check, nil
 }
 
+// Score returns the score of the latest health check of a component, and
+// whether it has one
+func (hm *HealthMonitor) Score(componentID string) (float64, bool) {
+	hm.mu.RLock()
+	defer hm.mu.RUnlock()
+	check, exists := hm.checks[componentID]
+	if !exists {
+		return 0, false
+	}
+	return check.Score, true
+}
+
+// RemoveCheck stops monitoring a component, e.g. one that recovered
+func (hm *HealthMonitor) RemoveCheck(componentID string) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	delete(hm.checks, componentID)
+	delete(hm.probes, componentID)
+}
+
 // GetAllChecks returns all health checks
 func (hm *HealthMonitor) GetAllChecks() map[string]*HealthCheck {
 	hm.mu.RLock()
@@ -208,6 +312,24 @@ func (hm *HealthMonitor) RegisterRecoveryStrategy(componentID string, strategy R
 	hm.recoveryStrategies[componentID] = strategy
 }
 
+// AddAlertSink registers a sink that receives every alert
+func (hm *HealthMonitor) AddAlertSink(sink AlertSink) {
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	hm.alertSinks = append(hm.alertSinks, sink)
+}
+
+// SetAlertThreshold changes the score below which checks raise alerts.
+// Zero or less restores the default.
+func (hm *HealthMonitor) SetAlertThreshold(threshold float64) {
+	if threshold <= 0 {
+		threshold = 0.5
+	}
+	hm.mu.Lock()
+	defer hm.mu.Unlock()
+	hm.alertThreshold = threshold
+}
+
 // Alerts returns the alert channel
 func (hm *HealthMonitor) Alerts() <-chan HealthAlert {
 	return hm.alertChan
@@ -222,12 +344,12 @@ func (hm *HealthMonitor) RecoveryActions() <-chan RecoveryAction {
 func (hm *HealthMonitor) monitorLoop() {
 	defer hm.wg.Done()
 	
-	ticker := time.NewTicker(hm.checkInterval)
+	ticker := hm.clock.NewTicker(hm.checkInterval)
 	defer ticker.Stop()
 	
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			hm.performHealthChecks()
 		case <-hm.ctx.Done():
 			return
@@ -238,19 +360,32 @@ func (hm *HealthMonitor) monitorLoop() {
 // performHealthChecks checks all registered components
 func (hm *HealthMonitor) performHealthChecks() {
 	hm.mu.RLock()
-	checks := make([]*HealthCheck, 0, len(hm.checks))
+	probes := make(map[string]Probe, len(hm.probes))
+	for id, probe := range hm.probes {
+		probes[id] = probe
+	}
+	hm.mu.RUnlock()
+	for id, probe := range probes {
+		check := probe()
+		check.ComponentID = id
+		hm.UpdateCheck(check)
+	}
+	
+	// Copy the checks, they are shared with readers of GetCheck
+	hm.mu.RLock()
+	checks := make([]HealthCheck, 0, len(hm.checks))
 	for _, check := range hm.checks {
-		checks = append(checks, check)
+		checks = append(checks, *check)
 	}
 	hm.mu.RUnlock()
 	
 	for _, check := range checks {
 		// Check if stale (no updates in 2x interval)
-		if time.Since(check.Timestamp) > 2*hm.checkInterval {
+		if hm.clock.Since(check.Timestamp) > 2*hm.checkInterval {
 			check.Status = HealthStatusUnhealthy
 			check.Score = 0.3
 			check.Message = "Component not responding"
-			hm.UpdateCheck(*check)
+			hm.UpdateCheck(check)
 		}
 	}
 }
@@ -278,9 +413,16 @@ func (hm *HealthMonitor) triggerAlert(check HealthCheck) {
 		Status:      check.Status,
 		Check:       check,
 		Severity:    severity,
-		Timestamp:   time.Now(),
+		Timestamp:   hm.clock.Now(),
 	}
 	
+	for _, sink := range hm.alertSinks {
+		sink.HandleAlert(alert)
+	}
+	
+	if hm.stopped {
+		return
+	}
 	select {
 	case hm.alertChan <- alert:
 	default:
@@ -310,7 +452,7 @@ func (hm *HealthMonitor) handleAlert(alert HealthAlert) {
 			action := RecoveryAction{
 				ComponentID: alert.ComponentID,
 				ActionType:  RecoveryActionRestart,
-				Timestamp:   time.Now(),
+				Timestamp:   hm.clock.Now(),
 			}
 			
 			select {
@@ -368,7 +510,7 @@ func (hm *HealthMonitor) GetSystemHealth() SystemHealth {
 		DegradedCount:    statusCounts[HealthStatusDegraded],
 		UnhealthyCount:   statusCounts[HealthStatusUnhealthy],
 		CriticalCount:    statusCounts[HealthStatusCritical],
-		LastUpdated:      time.Now(),
+		LastUpdated:      hm.clock.Now(),
 	}
 }
 
@@ -0,0 +1,126 @@
+package health
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+func TestShutdownWhileUpdating(t *testing.T) {
+	hm := NewHealthMonitor(HealthMonitorConfig{CheckInterval: time.Millisecond, AlertBuffer: 1})
+	hm.AddAlertSink(AlertSinkFunc(func(HealthAlert) {}))
+	if err := hm.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("component-%d", i)
+			hm.RegisterCheck(id)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				// Below the threshold, so every update raises an alert
+				hm.UpdateCheck(HealthCheck{ComponentID: id, Status: HealthStatusDegraded, Score: 0.1})
+				if check, err := hm.GetCheck(id); err == nil {
+					_ = check.Score
+				}
+				_ = hm.GetAllChecks()
+				_ = hm.GetSystemHealth()
+			}
+		}(i)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	var stops sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		stops.Add(1)
+		go func() {
+			defer stops.Done()
+			if err := hm.Stop(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	stops.Wait()
+
+	// Updates after Stop must not send on the closed alert channel
+	time.Sleep(5 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+
+	for range hm.Alerts() {
+	}
+	if err := hm.Start(); err == nil {
+		t.Error("Start succeeded after Stop")
+	}
+}
+
+func TestStaleComponent(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	hm := NewHealthMonitor(HealthMonitorConfig{CheckInterval: time.Minute, Clock: fake})
+	alerts := make(chan HealthAlert, 1)
+	hm.AddAlertSink(AlertSinkFunc(func(alert HealthAlert) { alerts <- alert }))
+	hm.RegisterCheck("agent")
+	if err := hm.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer hm.Stop()
+
+	// Wait for the monitor loop's ticker before moving the clock
+	fake.BlockUntil(1)
+	fake.Advance(2 * time.Minute)
+	select {
+	case alert := <-alerts:
+		t.Fatalf("alert for a component updated %v ago: %+v", 2*time.Minute, alert)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	fake.Advance(time.Minute)
+	select {
+	case alert := <-alerts:
+		if alert.ComponentID != "agent" || alert.Status != HealthStatusUnhealthy {
+			t.Errorf("alert = %+v", alert)
+		}
+		if want := fake.Now(); !alert.Timestamp.Equal(want) {
+			t.Errorf("alert at %v, want %v", alert.Timestamp, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no alert for a stale component")
+	}
+}
+
+func TestProbe(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	hm := NewHealthMonitor(HealthMonitorConfig{CheckInterval: time.Minute, Clock: fake})
+	alerts := make(chan HealthAlert, 1)
+	hm.AddAlertSink(AlertSinkFunc(func(alert HealthAlert) { alerts <- alert }))
+	hm.RegisterProbe("logs", func() HealthCheck {
+		return HealthCheck{Status: HealthStatusUnhealthy, Score: 0.1, Message: "dropping lines"}
+	})
+	if err := hm.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer hm.Stop()
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	select {
+	case alert := <-alerts:
+		if alert.ComponentID != "logs" || alert.Check.Message != "dropping lines" {
+			t.Errorf("alert = %+v", alert)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no alert for a probe reporting a low score")
+	}
+}
@@ -0,0 +1,132 @@
+package swarm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/health"
+)
+
+// DefaultHealthRetry is how long an agent passed over for its health waits
+// for a task to show it recovered unless configured otherwise
+const DefaultHealthRetry = 5 * time.Minute
+
+// AgentHealthConfig keeps new tasks from agents whose health check, as
+// recorded by the health monitor when their tasks fail, scores too low. An
+// agent that is the only one able to take a task still gets it.
+type AgentHealthConfig struct {
+	// MinScore is the health score below which agents are passed over,
+	// zero to pass over none
+	MinScore float64
+	// RetryAfter is how long a passed over agent waits before it gets a
+	// task again. Once such a task succeeds the agent has recovered.
+	// DefaultHealthRetry if zero.
+	RetryAfter time.Duration
+}
+
+// healthGate decides which degraded agents are passed over
+type healthGate struct {
+	mu     sync.Mutex
+	config AgentHealthConfig
+	// waiting holds when the degraded agents last got a task, or were
+	// first passed over
+	waiting map[string]time.Time
+}
+
+func newHealthGate(config AgentHealthConfig) *healthGate {
+	return &healthGate{config: config, waiting: make(map[string]time.Time)}
+}
+
+// setConfig changes the thresholds, for tasks dispatched from now on
+func (g *healthGate) setConfig(config AgentHealthConfig) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.config = config
+}
+
+func (g *healthGate) enabled() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.config.MinScore > 0
+}
+
+// skip reports whether an agent with a health score is passed over. A
+// degraded agent is let through once every RetryAfter.
+func (g *healthGate) skip(agentID string, score float64, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if score >= g.config.MinScore {
+		delete(g.waiting, agentID)
+		return false
+	}
+	retry := g.config.RetryAfter
+	if retry <= 0 {
+		retry = DefaultHealthRetry
+	}
+	since, ok := g.waiting[agentID]
+	if ok && now.Sub(since) >= retry {
+		g.waiting[agentID] = now
+		return false
+	}
+	if !ok {
+		g.waiting[agentID] = now
+	}
+	return true
+}
+
+// recovered forgets an agent whose task succeeded
+func (g *healthGate) recovered(agentID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.waiting, agentID)
+}
+
+// passOverDegraded removes the agents whose health check scores below the
+// configured minimum from the candidates of a task and marks their scores,
+// unless that leaves none
+func (c *Coordinator) passOverDegraded(agents []agent.Agent, scores []agent.SelectionScore) []agent.Agent {
+	if !c.healthGate.enabled() {
+		return agents
+	}
+	now := c.clock.Now()
+	skipped := 0
+	for i := range scores {
+		score, ok := c.healthMonitor.Score(scores[i].AgentID)
+		if ok && c.healthGate.skip(scores[i].AgentID, score, now) {
+			scores[i].Degraded = true
+			skipped++
+		}
+	}
+	if skipped == 0 {
+		return agents
+	}
+	if skipped == len(scores) {
+		// Nobody else can take the task
+		for i := range scores {
+			scores[i].Degraded = false
+		}
+		return agents
+	}
+
+	healthy := agents[:0]
+	for i, ag := range agents {
+		if !scores[i].Degraded {
+			healthy = append(healthy, ag)
+		}
+	}
+	return healthy
+}
+
+// recoverAgent clears the health check of an agent whose task succeeded, so
+// it is no longer passed over
+func (c *Coordinator) recoverAgent(agentID string) {
+	if _, ok := c.healthMonitor.Score(agentID); !ok {
+		return
+	}
+	c.healthMonitor.RemoveCheck(agentID)
+	c.healthGate.recovered(agentID)
+	c.timeline.record(TimelineRecovery, agentID, "Agent "+agentID+" recovered", map[string]interface{}{
+		"action": string(health.RecoveryActionReset),
+	})
+}
@@ -0,0 +1,76 @@
+package swarm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+	"github.com/opencode-ai/opencode/internal/swarm/health"
+)
+
+func TestPassOverDegraded(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	c, err := NewCoordinator(CoordinatorConfig{Clock: clk, AgentHealth: AgentHealthConfig{MinScore: 0.5, RetryAfter: time.Minute}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cancelFunc()
+	for _, id := range []string{"a", "b"} {
+		ag := &echoAgent{BaseAgent: agent.NewBaseAgent(agent.AgentConfig{ID: id, Type: agent.AgentTypeExecutor})}
+		if err := ag.Start(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.GetRegistry().RegisterAgent(ag); err != nil {
+			t.Fatal(err)
+		}
+	}
+	candidates := func() ([]string, []agent.SelectionScore) {
+		agents, scores := c.registry.RankAgentsForTask(agent.Task{Type: string(agent.AgentTypeExecutor)})
+		var ids []string
+		for _, ag := range c.passOverDegraded(agents, scores) {
+			ids = append(ids, ag.GetID())
+		}
+		return ids, scores
+	}
+	degrade := func(id string) {
+		c.healthMonitor.UpdateCheck(health.HealthCheck{ComponentID: id, Status: health.HealthStatusDegraded, Score: 0.2})
+	}
+
+	if ids, _ := candidates(); len(ids) != 2 {
+		t.Fatalf("candidates = %v, want both healthy agents", ids)
+	}
+
+	// A degraded agent is passed over until it may retry
+	degrade("a")
+	ids, scores := candidates()
+	if len(ids) != 1 || ids[0] != "b" || !scores[0].Degraded || scores[0].AgentID != "a" {
+		t.Fatalf("candidates = %v with scores %v, want b with a degraded", ids, scores)
+	}
+	clk.Advance(30 * time.Second)
+	if ids, _ := candidates(); len(ids) != 1 {
+		t.Fatalf("candidates = %v, want a still passed over", ids)
+	}
+	clk.Advance(30 * time.Second)
+	if ids, _ := candidates(); len(ids) != 2 {
+		t.Fatalf("candidates = %v, want a retried", ids)
+	}
+	if ids, _ := candidates(); len(ids) != 1 {
+		t.Fatalf("candidates = %v, want a passed over again after its retry", ids)
+	}
+
+	// A succeeding task recovers it
+	c.recoverAgent("a")
+	if ids, _ := candidates(); len(ids) != 2 {
+		t.Fatalf("candidates = %v, want a recovered", ids)
+	}
+
+	// Agents nobody else can stand in for still get tasks
+	degrade("a")
+	degrade("b")
+	ids, scores = candidates()
+	if len(ids) != 2 || scores[0].Degraded || scores[1].Degraded {
+		t.Errorf("candidates = %v with scores %v, want both when all are degraded", ids, scores)
+	}
+}
@@ -0,0 +1,80 @@
+package swarm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/health"
+)
+
+// DefaultMissedHeartbeats is how many heartbeats in a row an agent may miss
+// before it is unreachable, unless configured otherwise
+const DefaultMissedHeartbeats = 3
+
+// HeartbeatConfig makes the configured agents send heartbeats to the
+// registry. Agents that miss Missed of them in a row are unreachable: their
+// health check turns unhealthy, raising an alert, and they get no tasks
+// until they send heartbeats again.
+type HeartbeatConfig struct {
+	// Interval is how often agents send heartbeats and are checked, zero to
+	// not send any
+	Interval time.Duration
+	// Missed is how many heartbeats in a row an agent may miss,
+	// DefaultMissedHeartbeats if zero
+	Missed int
+}
+
+// missed returns how many heartbeats in a row an agent may miss
+func (h HeartbeatConfig) missed() int {
+	if h.Missed <= 0 {
+		return DefaultMissedHeartbeats
+	}
+	return h.Missed
+}
+
+// timeout returns how long an agent may go without a heartbeat, zero if
+// agents send none
+func (h HeartbeatConfig) timeout() time.Duration {
+	return h.Interval * time.Duration(h.missed())
+}
+
+// watchHeartbeats checks the heartbeats of the agents every interval
+func (c *Coordinator) watchHeartbeats(interval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := c.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			c.checkHeartbeats()
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// checkHeartbeats updates the health checks of the agents that became
+// unreachable or recovered since the last check
+func (c *Coordinator) checkHeartbeats() {
+	lost, recovered := c.registry.CheckHeartbeats(c.clock.Now())
+	for _, id := range lost {
+		c.healthMonitor.UpdateCheck(health.HealthCheck{
+			ComponentID: id,
+			Status:      health.HealthStatusUnhealthy,
+			Score:       0,
+			Message:     fmt.Sprintf("Agent %s missed its heartbeats and is unreachable", id),
+			Details:     map[string]interface{}{"agent": id, "unreachable": true},
+		})
+	}
+	for _, id := range recovered {
+		c.healthMonitor.UpdateCheck(health.HealthCheck{
+			ComponentID: id,
+			Status:      health.HealthStatusHealthy,
+			Score:       1,
+			Message:     fmt.Sprintf("Agent %s sends heartbeats again", id),
+			Details:     map[string]interface{}{"agent": id},
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package swarm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+	"github.com/opencode-ai/opencode/internal/swarm/health"
+)
+
+func TestHeartbeats(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	c, err := NewCoordinator(CoordinatorConfig{Clock: clk, Heartbeat: HeartbeatConfig{Interval: 10 * time.Second}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cancelFunc()
+
+	ag := &hangingAgent{BaseAgent: agent.NewBaseAgent(agent.AgentConfig{ID: "worker", Type: agent.AgentTypeExecutor})}
+	if err := ag.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer ag.Stop()
+	if err := c.GetRegistry().RegisterAgent(ag); err != nil {
+		t.Fatal(err)
+	}
+	task := agent.Task{ID: "build-1", Type: "build"}
+	beat := func() {
+		c.GetRegistry().Heartbeat(agent.Message{From: "worker", Type: agent.MessageTypeHeartbeat, Timestamp: clk.Now()})
+	}
+
+	// Three missed heartbeats make the agent unreachable
+	beat()
+	clk.Advance(30 * time.Second)
+	c.checkHeartbeats()
+	if len(c.GetRegistry().FindAgentsForTask(task)) != 1 {
+		t.Fatal("agent unreachable after 3 intervals")
+	}
+	clk.Advance(time.Second)
+	c.checkHeartbeats()
+	if len(c.GetRegistry().FindAgentsForTask(task)) != 0 {
+		t.Error("unreachable agent still gets tasks")
+	}
+	check, err := c.healthMonitor.GetCheck("worker")
+	if err != nil || check.Status != health.HealthStatusUnhealthy || check.Details["unreachable"] != true {
+		t.Errorf("health check = %+v, %v, want the agent unhealthy", check, err)
+	}
+	if status := c.GetSystemStatus(); !status.AgentHealth["worker"].Unreachable {
+		t.Errorf("agent health = %+v, want it unreachable", status.AgentHealth["worker"])
+	}
+
+	beat()
+	c.checkHeartbeats()
+	if len(c.GetRegistry().FindAgentsForTask(task)) != 1 {
+		t.Error("agent gets no tasks after sending a heartbeat again")
+	}
+	if check, err := c.healthMonitor.GetCheck("worker"); err != nil || check.Status != health.HealthStatusHealthy {
+		t.Errorf("health check = %+v, %v, want the agent healthy again", check, err)
+	}
+}
@@ -0,0 +1,286 @@
+// Package locks serializes the access of tasks to shared resources, like
+// the files an agent modifies or the directory a command runs in. A task
+// asking for a lock another task holds waits for it, unless waiting would
+// deadlock, and its locks are released when it finishes.
+package locks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+// ErrDeadlock is returned instead of waiting for a lock held by a task that
+// waits, directly or not, for a lock of the task asking
+var ErrDeadlock = errors.New("deadlock")
+
+// File is the resource name of a file
+func File(path string) string {
+	return "file:" + filepath.Clean(path)
+}
+
+// Dir is the resource name of a directory, e.g. the one a command runs in
+func Dir(path string) string {
+	return "dir:" + filepath.Clean(path)
+}
+
+// Owner is the task holding or waiting for a lock
+type Owner struct {
+	TaskID  string `json:"taskId"`
+	AgentID string `json:"agentId,omitempty"`
+}
+
+// Lock describes a held lock
+type Lock struct {
+	Resource   string    `json:"resource"`
+	Owner      Owner     `json:"owner"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+	// Waiting are the tasks waiting for the lock, in the order they get it
+	Waiting []Owner `json:"waiting,omitempty"`
+}
+
+type holder struct {
+	owner Owner
+	// count is how often the owner acquired the lock without releasing it
+	count   int
+	since   time.Time
+	waiters []*waiter
+}
+
+type waiter struct {
+	owner Owner
+	ready chan struct{}
+}
+
+// Manager holds the locks of a swarm. Locks are exclusive and reentrant:
+// a task may acquire a lock it holds again, and releases it as often.
+type Manager struct {
+	clock clock.Clock
+
+	mu   sync.Mutex
+	held map[string]*holder
+	// waiting maps the tasks waiting for a lock to its resource
+	waiting map[string]string
+}
+
+// NewManager creates a lock manager, timestamping locks with clk or the
+// system clock if nil
+func NewManager(clk clock.Clock) *Manager {
+	if clk == nil {
+		clk = clock.Real
+	}
+	return &Manager{
+		clock:   clk,
+		held:    make(map[string]*holder),
+		waiting: make(map[string]string),
+	}
+}
+
+// Acquire locks a resource for a task, waiting until the task holding it
+// releases it or ctx is done. Waiting tasks get the lock first come, first
+// served. Waiting for a task that waits for the asking task fails with
+// ErrDeadlock. Call release once done with the resource.
+func (m *Manager) Acquire(ctx context.Context, owner Owner, resource string) (release func(), err error) {
+	m.mu.Lock()
+	h, ok := m.held[resource]
+	if !ok {
+		m.held[resource] = &holder{owner: owner, count: 1, since: m.clock.Now()}
+		m.mu.Unlock()
+		return m.releaser(owner, resource), nil
+	}
+	if h.owner.TaskID == owner.TaskID {
+		h.count++
+		m.mu.Unlock()
+		return m.releaser(owner, resource), nil
+	}
+	if cycle := m.waitsFor(h.owner.TaskID, owner.TaskID); cycle != nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s waits for %s held by %s", ErrDeadlock, owner.TaskID, resource, describe(cycle))
+	}
+	w := &waiter{owner: owner, ready: make(chan struct{})}
+	h.waiters = append(h.waiters, w)
+	m.waiting[owner.TaskID] = resource
+	m.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return m.releaser(owner, resource), nil
+	case <-ctx.Done():
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case <-w.ready:
+		// Handed the lock while giving up
+		m.release(owner, resource)
+	default:
+		if h, ok := m.held[resource]; ok {
+			h.waiters = slices.DeleteFunc(h.waiters, func(other *waiter) bool { return other == w })
+		}
+		if m.waiting[owner.TaskID] == resource {
+			delete(m.waiting, owner.TaskID)
+		}
+	}
+	return nil, ctx.Err()
+}
+
+// waitsFor follows the locks tasks wait for from a task and returns the
+// tasks on the way to target, or nil if it is not reached
+func (m *Manager) waitsFor(from, target string) []string {
+	path := []string{from}
+	for task := from; len(path) <= len(m.waiting)+1; {
+		if task == target {
+			return path
+		}
+		resource, ok := m.waiting[task]
+		if !ok {
+			return nil
+		}
+		h, ok := m.held[resource]
+		if !ok {
+			return nil
+		}
+		task = h.owner.TaskID
+		path = append(path, task)
+	}
+	return nil
+}
+
+func describe(cycle []string) string {
+	s := cycle[0]
+	for _, task := range cycle[1:] {
+		s += ", waiting for " + task
+	}
+	return s
+}
+
+// TryAcquire locks a resource for a task if no other task holds it
+func (m *Manager) TryAcquire(owner Owner, resource string) (release func(), ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, held := m.held[resource]
+	switch {
+	case !held:
+		m.held[resource] = &holder{owner: owner, count: 1, since: m.clock.Now()}
+	case h.owner.TaskID == owner.TaskID:
+		h.count++
+	default:
+		return nil, false
+	}
+	return m.releaser(owner, resource), true
+}
+
+// releaser returns a function releasing a lock once, however often it is
+// called
+func (m *Manager) releaser(owner Owner, resource string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			m.release(owner, resource)
+		})
+	}
+}
+
+// release releases a lock once, handing it to the first waiting task when
+// the owner no longer holds it
+func (m *Manager) release(owner Owner, resource string) {
+	h, ok := m.held[resource]
+	if !ok || h.owner.TaskID != owner.TaskID {
+		return
+	}
+	if h.count--; h.count > 0 {
+		return
+	}
+	if len(h.waiters) == 0 {
+		delete(m.held, resource)
+		return
+	}
+	next := h.waiters[0]
+	h.waiters = h.waiters[1:]
+	h.owner = next.owner
+	h.count = 1
+	h.since = m.clock.Now()
+	delete(m.waiting, next.owner.TaskID)
+	close(next.ready)
+}
+
+// ReleaseAll releases the locks a task still holds, e.g. once it finished
+func (m *Manager) ReleaseAll(taskID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for resource, h := range m.held {
+		if h.owner.TaskID == taskID {
+			h.count = 1
+			m.release(h.owner, resource)
+		}
+	}
+}
+
+// Locks returns the held locks by resource
+func (m *Manager) Locks() []Lock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	locks := make([]Lock, 0, len(m.held))
+	for resource, h := range m.held {
+		lock := Lock{Resource: resource, Owner: h.owner, AcquiredAt: h.since}
+		for _, w := range h.waiters {
+			lock.Waiting = append(lock.Waiting, w.owner)
+		}
+		locks = append(locks, lock)
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Resource < locks[j].Resource })
+	return locks
+}
+
+type scopeKey struct{}
+
+type scope struct {
+	manager *Manager
+	owner   Owner
+}
+
+// WithOwner returns a context for running a task that takes the locks the
+// task acquires from m
+func WithOwner(ctx context.Context, m *Manager, owner Owner) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope{manager: m, owner: owner})
+}
+
+// Acquire locks resources for the task running with ctx and returns the
+// function releasing them. The resources are locked in order, so tasks
+// acquiring the same resources together do not deadlock. Outside a swarm,
+// when ctx has no owner, nothing is locked.
+func Acquire(ctx context.Context, resources ...string) (release func(), err error) {
+	s, ok := ctx.Value(scopeKey{}).(scope)
+	if !ok {
+		return func() {}, nil
+	}
+	resources = slices.Clone(resources)
+	slices.Sort(resources)
+	resources = slices.Compact(resources)
+
+	releases := make([]func(), 0, len(resources))
+	release = func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+	for _, resource := range resources {
+		r, err := s.manager.Acquire(ctx, s.owner, resource)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		releases = append(releases, r)
+	}
+	return release, nil
+}
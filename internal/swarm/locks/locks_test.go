@@ -0,0 +1,150 @@
+package locks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func acquired(t *testing.T, done <-chan error) bool {
+	t.Helper()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+		return true
+	case <-time.After(20 * time.Millisecond):
+		return false
+	}
+}
+
+func TestManagerAcquire(t *testing.T) {
+	m := NewManager(nil)
+	ctx := context.Background()
+	a, b := Owner{TaskID: "a", AgentID: "executor"}, Owner{TaskID: "b", AgentID: "tester"}
+
+	release, err := m.Acquire(ctx, a, File("main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Locks are reentrant
+	again, err := m.Acquire(ctx, a, File("./main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.TryAcquire(b, File("main.go")); ok {
+		t.Fatal("TryAcquire took a held lock")
+	}
+
+	done := make(chan error, 1)
+	var releaseB func()
+	go func() {
+		var err error
+		releaseB, err = m.Acquire(ctx, b, File("main.go"))
+		done <- err
+	}()
+	if acquired(t, done) {
+		t.Fatal("acquired a held lock")
+	}
+	locks := m.Locks()
+	if len(locks) != 1 || locks[0].Resource != "file:main.go" || locks[0].Owner != a || len(locks[0].Waiting) != 1 || locks[0].Waiting[0] != b {
+		t.Fatalf("Locks() = %+v", locks)
+	}
+
+	again()
+	again()
+	if acquired(t, done) {
+		t.Fatal("acquired a lock released once of twice")
+	}
+	release()
+	if !acquired(t, done) {
+		t.Fatal("lock not handed to the waiting task")
+	}
+	if locks := m.Locks(); len(locks) != 1 || locks[0].Owner != b {
+		t.Fatalf("Locks() = %+v", locks)
+	}
+	releaseB()
+	if locks := m.Locks(); len(locks) != 0 {
+		t.Fatalf("Locks() = %+v", locks)
+	}
+}
+
+func TestManagerDeadlock(t *testing.T) {
+	m := NewManager(nil)
+	ctx := context.Background()
+	a, b := Owner{TaskID: "a"}, Owner{TaskID: "b"}
+
+	if _, err := m.Acquire(ctx, a, "x"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Acquire(ctx, b, "y"); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.Acquire(ctx, a, "y")
+		done <- err
+	}()
+	if acquired(t, done) {
+		t.Fatal("acquired a held lock")
+	}
+	// b waiting for x would wait for itself
+	if _, err := m.Acquire(ctx, b, "x"); !errors.Is(err, ErrDeadlock) {
+		t.Fatalf("Acquire = %v, want ErrDeadlock", err)
+	}
+
+	// Once b finishes a gets its lock
+	m.ReleaseAll("b")
+	if !acquired(t, done) {
+		t.Fatal("lock not handed over by ReleaseAll")
+	}
+}
+
+func TestManagerAcquireCancelled(t *testing.T) {
+	m := NewManager(nil)
+	release, _ := m.Acquire(context.Background(), Owner{TaskID: "a"}, "x")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.Acquire(ctx, Owner{TaskID: "b"}, "x")
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire = %v", err)
+	}
+	if locks := m.Locks(); len(locks) != 1 || len(locks[0].Waiting) != 0 {
+		t.Fatalf("Locks() = %+v", locks)
+	}
+	release()
+	if locks := m.Locks(); len(locks) != 0 {
+		t.Fatalf("Locks() = %+v", locks)
+	}
+}
+
+func TestAcquireWithContext(t *testing.T) {
+	// Without an owner nothing is locked
+	release, err := Acquire(context.Background(), "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	m := NewManager(nil)
+	ctx := WithOwner(context.Background(), m, Owner{TaskID: "a"})
+	release, err = Acquire(ctx, Dir("/src"), File("/src/main.go"), Dir("/src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locks := m.Locks(); len(locks) != 2 || locks[0].Resource != "dir:/src" || locks[1].Resource != "file:/src/main.go" {
+		t.Fatalf("Locks() = %+v", locks)
+	}
+	release()
+	if locks := m.Locks(); len(locks) != 0 {
+		t.Fatalf("Locks() = %+v", locks)
+	}
+}
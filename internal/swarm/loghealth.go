@@ -0,0 +1,55 @@
+package swarm
+
+import (
+	"fmt"
+
+	"github.com/opencode-ai/opencode/internal/swarm/health"
+	"github.com/opencode-ai/opencode/internal/swarm/monitor"
+)
+
+// logWatcherComponent is the health check of the log watcher
+const logWatcherComponent = "log-watcher"
+
+// logHealth reports the share of log lines the log watcher dropped since
+// the last check because the swarm did not read them fast enough
+func (c *Coordinator) logHealth() health.HealthCheck {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.logWatcher == nil {
+		return health.HealthCheck{Status: health.HealthStatusHealthy, Score: 1}
+	}
+	stats := c.logWatcher.DropStats()
+	read := stats.Read - c.logDrops.Read
+	dropped := stats.Dropped - c.logDrops.Dropped
+	c.logDrops = stats
+
+	check := health.HealthCheck{
+		Status:  health.HealthStatusHealthy,
+		Score:   1,
+		Message: fmt.Sprintf("%d log lines read", read),
+		Details: map[string]interface{}{
+			"read":             read,
+			"dropped":          dropped,
+			"buffered":         stats.Buffered,
+			"total_dropped":    stats.Dropped,
+			"dropped_by_level": stats.DroppedByLevel,
+		},
+	}
+	if dropped == 0 {
+		return check
+	}
+	check.Score = 1 - float64(dropped)/float64(read)
+	check.Status = health.HealthStatusDegraded
+	if check.Score < 0.5 {
+		check.Status = health.HealthStatusUnhealthy
+	}
+	check.Message = fmt.Sprintf("dropped %d of %d log lines", dropped, read)
+	return check
+}
+
+// watchLogHealth makes the health monitor check a new log watcher. It is
+// called with c.mu held, or before the coordinator is shared.
+func (c *Coordinator) watchLogHealth(logWatcher *monitor.LogWatcher) {
+	c.logDrops = logWatcher.DropStats()
+	c.healthMonitor.RegisterProbe(logWatcherComponent, c.logHealth)
+}
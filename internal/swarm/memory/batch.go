@@ -0,0 +1,277 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+// BatchStorer is implemented by stores that store several memories at once
+// more cheaply than one after the other
+type BatchStorer interface {
+	StoreBatch(ctx context.Context, memories []Memory) error
+}
+
+// DropPolicy decides which memories a full batch writer drops
+type DropPolicy string
+
+const (
+	// DropNewest drops the memories written while the queue is full
+	DropNewest DropPolicy = "newest"
+	// DropOldest drops the oldest queued memory to make room
+	DropOldest DropPolicy = "oldest"
+)
+
+// Batch writer defaults
+const (
+	DefaultFlushInterval = time.Second
+	DefaultMaxBatch      = 256
+	DefaultQueueSize     = 10000
+)
+
+// BatchConfig configures a BatchWriter
+type BatchConfig struct {
+	// FlushInterval is how long memories wait for a batch to fill,
+	// DefaultFlushInterval if zero
+	FlushInterval time.Duration
+	// MaxBatch bounds the memories stored at once, DefaultMaxBatch if zero.
+	// Full batches are stored without waiting for the interval.
+	MaxBatch int
+	// MaxPerSecond limits the memories stored per second, unlimited if
+	// zero. Memories over the limit stay queued.
+	MaxPerSecond float64
+	// QueueSize bounds the queued memories, DefaultQueueSize if zero
+	QueueSize int
+	// Drop selects the memories dropped from a full queue, DropNewest if
+	// empty
+	Drop DropPolicy
+	// Clock times flushes and the rate limit, the system clock if nil
+	Clock clock.Clock
+}
+
+// BatchStats counts what a batch writer did
+type BatchStats struct {
+	Written int64
+	Dropped int64
+	Batches int64
+	Queued  int
+	// Errors counts memories the store failed to keep
+	Errors int64
+}
+
+// BatchWriter queues memories and stores them in batches, so frequent
+// writers like the log watcher take the store's lock once per batch instead
+// of once per memory. When the queue is full memories are dropped.
+type BatchWriter struct {
+	store  MemoryStore
+	config BatchConfig
+
+	mu     sync.Mutex
+	queue  []Memory
+	stats  BatchStats
+	tokens float64
+	refill time.Time
+
+	// storeMu serializes flushes so batches are stored in order
+	storeMu sync.Mutex
+	full    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	started sync.Once
+	closed  sync.Once
+}
+
+// NewBatchWriter creates a writer storing to store. Start it to flush in the
+// background.
+func NewBatchWriter(store MemoryStore, config BatchConfig) *BatchWriter {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+	if config.MaxBatch <= 0 {
+		config.MaxBatch = DefaultMaxBatch
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	if config.Drop == "" {
+		config.Drop = DropNewest
+	}
+	if config.Clock == nil {
+		config.Clock = clock.Real
+	}
+	return &BatchWriter{
+		store:   store,
+		config:  config,
+		tokens:  float64(config.MaxBatch),
+		refill:  config.Clock.Now(),
+		full:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// ValidateDropPolicy checks that a drop policy is known
+func ValidateDropPolicy(policy DropPolicy) error {
+	switch policy {
+	case "", DropNewest, DropOldest:
+		return nil
+	}
+	return fmt.Errorf("unknown drop policy %q, expected %s or %s", policy, DropNewest, DropOldest)
+}
+
+// Write queues a memory and returns whether it was kept. Memories without
+// a creation time get the time they were written.
+func (w *BatchWriter) Write(mem Memory) bool {
+	if mem.CreatedAt.IsZero() {
+		mem.CreatedAt = w.config.Clock.Now()
+	}
+	w.mu.Lock()
+	if len(w.queue) >= w.config.QueueSize {
+		w.stats.Dropped++
+		if w.config.Drop == DropNewest {
+			w.mu.Unlock()
+			return false
+		}
+		w.queue = w.queue[1:]
+	}
+	w.queue = append(w.queue, mem)
+	full := len(w.queue) >= w.config.MaxBatch
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.full <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+// Start flushes queued memories every flush interval, and whenever a batch
+// is full, until Close
+func (w *BatchWriter) Start() {
+	w.started.Do(w.start)
+}
+
+func (w *BatchWriter) start() {
+	go func() {
+		defer close(w.stopped)
+		ticker := w.config.Clock.NewTicker(w.config.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C():
+			case <-w.full:
+			case <-w.done:
+				return
+			}
+			// Keep up with writers filling batches faster than they
+			// are stored
+			for {
+				stored, err := w.flush(context.Background(), true)
+				if err != nil || stored < w.config.MaxBatch {
+					break
+				}
+			}
+		}
+	}()
+}
+
+// Flush stores every queued memory, ignoring the rate limit
+func (w *BatchWriter) Flush(ctx context.Context) error {
+	for {
+		w.mu.Lock()
+		empty := len(w.queue) == 0
+		w.mu.Unlock()
+		if empty {
+			return nil
+		}
+		if _, err := w.flush(ctx, false); err != nil {
+			return err
+		}
+	}
+}
+
+// Close stops flushing in the background and stores the queued memories.
+// It is safe to call more than once.
+func (w *BatchWriter) Close(ctx context.Context) error {
+	w.closed.Do(func() {
+		close(w.done)
+	})
+	// Writers that never started have nothing to wait for
+	w.started.Do(func() { close(w.stopped) })
+	select {
+	case <-w.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return w.Flush(ctx)
+}
+
+// Stats returns what the writer did so far
+func (w *BatchWriter) Stats() BatchStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	stats := w.stats
+	stats.Queued = len(w.queue)
+	return stats
+}
+
+// flush stores one batch, as large as the rate limit allows if limited, and
+// returns its size
+func (w *BatchWriter) flush(ctx context.Context, limited bool) (int, error) {
+	w.storeMu.Lock()
+	defer w.storeMu.Unlock()
+
+	w.mu.Lock()
+	n := min(len(w.queue), w.config.MaxBatch)
+	if limited && w.config.MaxPerSecond > 0 {
+		n = min(n, w.takeTokens(n))
+	}
+	if n == 0 {
+		w.mu.Unlock()
+		return 0, nil
+	}
+	// Writes append past the batch, so it is taken without copying
+	batch := w.queue[:n:n]
+	w.queue = w.queue[n:]
+	if len(w.queue) == 0 {
+		w.queue = nil
+	}
+	w.mu.Unlock()
+
+	var err error
+	if storer, ok := w.store.(BatchStorer); ok {
+		err = storer.StoreBatch(ctx, batch)
+	} else {
+		for _, mem := range batch {
+			if err = w.store.Store(ctx, mem); err != nil {
+				break
+			}
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stats.Batches++
+	if err != nil {
+		w.stats.Errors += int64(n)
+		return n, fmt.Errorf("failed to store memories: %w", err)
+	}
+	w.stats.Written += int64(n)
+	return n, nil
+}
+
+// takeTokens takes up to n memories from the token bucket, which refills at
+// MaxPerSecond up to one batch. It is called with mu held.
+func (w *BatchWriter) takeTokens(n int) int {
+	now := w.config.Clock.Now()
+	w.tokens = min(float64(w.config.MaxBatch), w.tokens+now.Sub(w.refill).Seconds()*w.config.MaxPerSecond)
+	w.refill = now
+	taken := min(n, int(w.tokens))
+	w.tokens -= float64(taken)
+	return taken
+}
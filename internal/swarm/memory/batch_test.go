@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+func TestBatchWriter(t *testing.T) {
+	ctx := context.Background()
+	store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{})
+	fake := clock.NewFake(time.Unix(0, 0))
+	w := NewBatchWriter(store, BatchConfig{FlushInterval: time.Second, MaxBatch: 10, Clock: fake})
+	w.Start()
+	fake.BlockUntil(1)
+
+	for i := 0; i < 3; i++ {
+		w.Write(Memory{Type: MemoryTypeEpisodic, Content: fmt.Sprintf("line %d", i)})
+	}
+	if stats := store.GetStats(); stats.TotalMemories != 0 {
+		t.Fatalf("%d memories stored before the flush interval", stats.TotalMemories)
+	}
+	fake.Advance(time.Second)
+	waitFor(t, func() bool { return store.GetStats().TotalMemories == 3 })
+
+	// A full batch is stored without waiting for the interval
+	for i := 0; i < 10; i++ {
+		w.Write(Memory{Type: MemoryTypeEpisodic, Content: fmt.Sprintf("burst %d", i)})
+	}
+	waitFor(t, func() bool { return store.GetStats().TotalMemories == 13 })
+
+	w.Write(Memory{Type: MemoryTypeEpisodic, Content: "last"})
+	if err := w.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	found, err := store.Query(ctx, MemoryQuery{SearchText: "last"})
+	if err != nil || len(found) != 1 {
+		t.Fatalf("Query() after Close() = %v, %v, want the queued memory stored", found, err)
+	}
+	if !found[0].CreatedAt.Equal(fake.Now()) {
+		t.Errorf("CreatedAt = %v, want the time it was written", found[0].CreatedAt)
+	}
+	if stats := w.Stats(); stats.Written != 14 || stats.Dropped != 0 || stats.Queued != 0 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestBatchWriterDrop(t *testing.T) {
+	for _, tt := range []struct {
+		policy DropPolicy
+		want   []string
+	}{
+		{DropNewest, []string{"0", "1"}},
+		{DropOldest, []string{"2", "3"}},
+	} {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			ctx := context.Background()
+			store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{})
+			w := NewBatchWriter(store, BatchConfig{QueueSize: 2, Drop: tt.policy})
+			for i := 0; i < 4; i++ {
+				w.Write(Memory{ID: fmt.Sprint(i), Type: MemoryTypeEpisodic})
+			}
+			if err := w.Close(ctx); err != nil {
+				t.Fatal(err)
+			}
+			for _, id := range tt.want {
+				if _, err := store.Retrieve(ctx, id); err != nil {
+					t.Errorf("memory %s: %v, want it kept", id, err)
+				}
+			}
+			if stats := w.Stats(); stats.Dropped != 2 {
+				t.Errorf("Dropped = %d, want 2", stats.Dropped)
+			}
+		})
+	}
+}
+
+func TestBatchWriterRateLimit(t *testing.T) {
+	ctx := context.Background()
+	store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{})
+	fake := clock.NewFake(time.Unix(0, 0))
+	w := NewBatchWriter(store, BatchConfig{MaxBatch: 10, MaxPerSecond: 5, Clock: fake})
+	for i := 0; i < 30; i++ {
+		w.Write(Memory{Type: MemoryTypeEpisodic})
+	}
+
+	// The bucket starts with one batch, then refills at five a second
+	for _, step := range []struct {
+		advance time.Duration
+		want    int
+	}{
+		{0, 10},
+		{0, 10},
+		{time.Second, 15},
+		{2 * time.Second, 25},
+	} {
+		fake.Advance(step.advance)
+		if _, err := w.flush(ctx, true); err != nil {
+			t.Fatal(err)
+		}
+		if got := store.GetStats().TotalMemories; got != step.want {
+			t.Fatalf("stored %d after %v, want %d", got, step.advance, step.want)
+		}
+	}
+	// Flushing on shutdown ignores the limit
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.GetStats().TotalMemories; got != 30 {
+		t.Errorf("stored %d after Flush(), want 30", got)
+	}
+}
+
+func TestValidateDropPolicy(t *testing.T) {
+	for policy, valid := range map[DropPolicy]bool{"": true, DropNewest: true, DropOldest: true, "random": false} {
+		if err := ValidateDropPolicy(policy); (err == nil) != valid {
+			t.Errorf("ValidateDropPolicy(%q) = %v, want valid = %v", policy, err, valid)
+		}
+	}
+}
+
+// waitFor polls until done holds, for work done by a writer's goroutine
+func waitFor(t *testing.T, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultCompactThreshold is how many times a memory must repeat before
+// consolidation compacts it, unless configured otherwise
+const DefaultCompactThreshold = 50
+
+// occurrences is how many memories a memory stands for
+func (m *Memory) occurrences() int {
+	if m.Occurrences > 0 {
+		return m.Occurrences
+	}
+	return 1
+}
+
+// lastSeen is when the last memory a memory stands for was stored
+func (m *Memory) lastSeen() time.Time {
+	if m.LastSeen.IsZero() {
+		return m.CreatedAt
+	}
+	return m.LastSeen
+}
+
+// compact merges the memories sharing a fingerprint into one once they
+// stand for at least compactThreshold memories. Pinned memories are left
+// alone. It returns how many memories were merged away.
+func (hms *HierarchicalMemoryStore) compact(ctx context.Context) (int, error) {
+	groups := make(map[string][]*Memory)
+	err := hms.memories.scan(ctx, func(memory *Memory) bool {
+		if memory.Fingerprint != "" && !memory.Pinned {
+			groups[memory.Fingerprint] = append(groups[memory.Fingerprint], memory)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	merged := 0
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		total := 0
+		for _, memory := range group {
+			total += memory.occurrences()
+		}
+		if total < hms.compactThreshold {
+			continue
+		}
+		merged += hms.compactGroup(group)
+	}
+	hms.merged.Add(int64(merged))
+	return merged, nil
+}
+
+// compactGroup merges a group of memories into the one standing for the
+// most, the newest of equals. Memories that changed since the scan stay.
+func (hms *HierarchicalMemoryStore) compactGroup(group []*Memory) int {
+	keep := group[0]
+	for _, memory := range group[1:] {
+		if memory.occurrences() > keep.occurrences() ||
+			(memory.occurrences() == keep.occurrences() && memory.CreatedAt.After(keep.CreatedAt)) {
+			keep = memory
+		}
+	}
+
+	var removed []*Memory
+	for _, memory := range group {
+		if memory != keep && hms.remove(memory.ID, memory) {
+			removed = append(removed, memory)
+		}
+	}
+	if len(removed) == 0 {
+		return 0
+	}
+
+	shard := hms.memories.shard(keep.ID)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	current, exists := shard.memories[keep.ID]
+	if !exists {
+		// Deleted meanwhile, the repeats go with it
+		return len(removed)
+	}
+	// Change a copy, scans may be reading the stored memory
+	compacted := *current
+	compacted.Occurrences = current.occurrences()
+	compacted.LastSeen = current.lastSeen()
+	for _, memory := range removed {
+		compacted.Occurrences += memory.occurrences()
+		compacted.AccessCount += memory.AccessCount
+		if memory.CreatedAt.Before(compacted.CreatedAt) {
+			compacted.CreatedAt = memory.CreatedAt
+		}
+		if memory.lastSeen().After(compacted.LastSeen) {
+			compacted.LastSeen = memory.lastSeen()
+		}
+		if memory.Priority > compacted.Priority {
+			compacted.Priority = memory.Priority
+		}
+	}
+	shard.memories[keep.ID] = &compacted
+	return len(removed)
+}
@@ -0,0 +1,22 @@
+package memory
+
+import "errors"
+
+// Common errors
+var (
+	// ErrMemoryNotFound means no memory with the ID is stored. It may have
+	// been evicted.
+	ErrMemoryNotFound = errors.New("memory not found")
+	// ErrDecryption means an encrypted memory could not be decrypted, e.g.
+	// because the store was opened with another key
+	ErrDecryption = errors.New("decryption failed")
+	// ErrInvalidQuery means a textual query could not be parsed, see
+	// ParseQuery
+	ErrInvalidQuery = errors.New("invalid memory query")
+	// ErrUnknownExportFormat means a replica was exported to a format
+	// other than ExportFormats
+	ErrUnknownExportFormat = errors.New("unknown export format")
+	// ErrSnapshotUnsupported means the store cannot take replicas, see
+	// Snapshotter
+	ErrSnapshotUnsupported = errors.New("memory store does not support snapshots")
+)
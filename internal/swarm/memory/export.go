@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// ExportFormat is a file format replicas export to
+type ExportFormat string
+
+const (
+	ExportCSV     ExportFormat = "csv"
+	ExportParquet ExportFormat = "parquet"
+)
+
+// ExportFormats are the formats Export supports
+var ExportFormats = []ExportFormat{ExportCSV, ExportParquet}
+
+// exportBatch is how many rows are written to a parquet file at once
+const exportBatch = 1024
+
+// exportRow is a memory as exported, one column per field. Content and
+// metadata are JSON unless the content is text, and empty for encrypted
+// memories. Memories never accessed have a null LastAccessed.
+type exportRow struct {
+	ID           string     `parquet:"id"`
+	Type         string     `parquet:"type"`
+	Namespace    string     `parquet:"namespace"`
+	Priority     int64      `parquet:"priority"`
+	Tags         []string   `parquet:"tags,list"`
+	Content      string     `parquet:"content"`
+	Metadata     string     `parquet:"metadata"`
+	Encrypted    bool       `parquet:"encrypted"`
+	Pinned       bool       `parquet:"pinned"`
+	AccessCount  int64      `parquet:"access_count"`
+	Occurrences  int64      `parquet:"occurrences"`
+	CreatedAt    time.Time  `parquet:"created_at,timestamp"`
+	LastAccessed *time.Time `parquet:"last_accessed,optional"`
+	LastSeen     time.Time  `parquet:"last_seen,timestamp"`
+}
+
+var csvHeader = []string{
+	"id", "type", "namespace", "priority", "tags", "content", "metadata",
+	"encrypted", "pinned", "access_count", "occurrences", "created_at",
+	"last_accessed", "last_seen",
+}
+
+func newExportRow(memory *Memory) exportRow {
+	row := exportRow{
+		ID:          memory.ID,
+		Type:        string(memory.Type),
+		Namespace:   memory.Namespace,
+		Priority:    int64(memory.Priority),
+		Tags:        memory.Tags,
+		Content:     contentText(memory),
+		Encrypted:   memory.Encrypted,
+		Pinned:      memory.Pinned,
+		AccessCount: int64(memory.AccessCount),
+		Occurrences: int64(memory.occurrences()),
+		CreatedAt:   memory.CreatedAt,
+		LastSeen:    memory.lastSeen(),
+	}
+	if len(memory.Metadata) > 0 {
+		if data, err := json.Marshal(memory.Metadata); err == nil {
+			row.Metadata = string(data)
+		} else {
+			row.Metadata = fmt.Sprint(memory.Metadata)
+		}
+	}
+	if !memory.LastAccessed.IsZero() {
+		lastAccessed := memory.LastAccessed
+		row.LastAccessed = &lastAccessed
+	}
+	return row
+}
+
+// Export writes the memories of the replica to w, one row per memory
+// ordered by ID
+func (r *Replica) Export(ctx context.Context, w io.Writer, format ExportFormat) error {
+	switch format {
+	case ExportCSV:
+		return r.exportCSV(ctx, w)
+	case ExportParquet:
+		return r.exportParquet(ctx, w)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownExportFormat, format)
+	}
+}
+
+func (r *Replica) exportCSV(ctx context.Context, w io.Writer) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(csvHeader); err != nil {
+		return err
+	}
+	var err error
+	eachErr := r.Each(ctx, func(memory Memory) bool {
+		row := newExportRow(&memory)
+		lastAccessed := ""
+		if row.LastAccessed != nil {
+			lastAccessed = row.LastAccessed.Format(time.RFC3339Nano)
+		}
+		err = out.Write([]string{
+			row.ID,
+			row.Type,
+			row.Namespace,
+			strconv.FormatInt(row.Priority, 10),
+			strings.Join(row.Tags, ","),
+			row.Content,
+			row.Metadata,
+			strconv.FormatBool(row.Encrypted),
+			strconv.FormatBool(row.Pinned),
+			strconv.FormatInt(row.AccessCount, 10),
+			strconv.FormatInt(row.Occurrences, 10),
+			row.CreatedAt.Format(time.RFC3339Nano),
+			lastAccessed,
+			row.LastSeen.Format(time.RFC3339Nano),
+		})
+		return err == nil
+	})
+	if eachErr != nil {
+		return eachErr
+	}
+	if err != nil {
+		return err
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func (r *Replica) exportParquet(ctx context.Context, w io.Writer) error {
+	out := parquet.NewGenericWriter[exportRow](w)
+	rows := make([]exportRow, 0, exportBatch)
+	flush := func() error {
+		if _, err := out.Write(rows); err != nil {
+			return err
+		}
+		rows = rows[:0]
+		return nil
+	}
+
+	var err error
+	eachErr := r.Each(ctx, func(memory Memory) bool {
+		rows = append(rows, newExportRow(&memory))
+		if len(rows) == exportBatch {
+			err = flush()
+		}
+		return err == nil
+	})
+	if eachErr != nil {
+		return eachErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	return out.Close()
+}
@@ -1,37 +1,72 @@
 package memory
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/json"
 	"fmt"
+	"hash/maphash"
 	"io"
+	"math"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-// HierarchicalMemoryStore implements a hierarchical memory system
+// HierarchicalMemoryStore implements a hierarchical memory system. Its
+// memories are sharded, and queries scan snapshots of the shards, so
+// queries and writes rarely wait for each other.
 type HierarchicalMemoryStore struct {
-	memories    map[string]*Memory
+	memories    *shardedMemories
+	count       atomic.Int64
 	hierarchy   *HierarchicalNode
-	mu          sync.RWMutex
+	// pruneMu makes stores over capacity prune one at a time
+	pruneMu     sync.Mutex
 	encryptionKey []byte
+	// tagging normalizes and adds tags, if configured
+	tagging     *tagging
+	// merged counts the memories compaction merged away
+	merged      atomic.Int64
+	// quotas cap the memories of some types, it is not changed after
+	// creation
+	quotas      map[MemoryType]*quota
+	approvePrune PruneApprover
 	
 	// Configuration
+	namespace        string
 	maxMemories      int
 	consolidationInterval time.Duration
 	pruneOlderThan   time.Duration
+	compactThreshold int
 }
 
 // HierarchicalMemoryConfig configures the memory store
 type HierarchicalMemoryConfig struct {
 	MaxMemories           int
+	// Namespace is given to memories stored without one
+	Namespace             string
 	ConsolidationInterval time.Duration
 	PruneOlderThan        time.Duration
 	EncryptionKey         []byte
+	// Tagging, if set, normalizes the tags of stored memories and adds
+	// tags to them. Queries then normalize their tags too.
+	Tagging               *TaggingConfig
+	// CompactThreshold is how many memories sharing a Fingerprint
+	// consolidation merges into one counted memory,
+	// DefaultCompactThreshold if zero
+	CompactThreshold      int
+	// Quotas cap how many memories of a type are kept, each type evicting
+	// its own memories by its policy. Memories of types with a quota are
+	// not pruned to keep the store under MaxMemories.
+	Quotas                map[MemoryType]TypeQuota
+	// ApprovePrune, if set, approves prunes before they delete memories
+	ApprovePrune          PruneApprover
 }
 
 // NewHierarchicalMemoryStore creates a new hierarchical memory store
@@ -45,92 +80,166 @@ func NewHierarchicalMemoryStore(config HierarchicalMemoryConfig) *HierarchicalMe
 	if config.PruneOlderThan <= 0 {
 		config.PruneOlderThan = 30 * 24 * time.Hour // 30 days
 	}
+	if config.CompactThreshold <= 0 {
+		config.CompactThreshold = DefaultCompactThreshold
+	}
 	
 	return &HierarchicalMemoryStore{
-		memories:              make(map[string]*Memory),
+		memories:              newShardedMemories(),
 		hierarchy:             &HierarchicalNode{ID: "root", Type: MemoryTypeSemantic, Level: 0},
+		namespace:             config.Namespace,
 		maxMemories:           config.MaxMemories,
 		consolidationInterval: config.ConsolidationInterval,
 		pruneOlderThan:        config.PruneOlderThan,
 		encryptionKey:         config.EncryptionKey,
+		tagging:               newTagging(config.Tagging),
+		compactThreshold:      config.CompactThreshold,
+		quotas:                newQuotas(config.Quotas),
+		approvePrune:          config.ApprovePrune,
 	}
 }
 
 // Store adds a memory to the store
-func (hms *HierarchicalMemoryStore) Store(memory Memory) error {
-	hms.mu.Lock()
-	defer hms.mu.Unlock()
+func (hms *HierarchicalMemoryStore) Store(ctx context.Context, memory Memory) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	stored, err := hms.prepare(ctx, memory, true)
+	if err != nil {
+		return err
+	}
+	shard := hms.memories.shard(stored.ID)
+	shard.mu.Lock()
+	hms.insert(shard, stored)
+	shard.mu.Unlock()
+	
+	hms.pruneOverCapacity()
+	return nil
+}
+
+// StoreBatch stores memories locking each shard once, stopping at the
+// first error
+func (hms *HierarchicalMemoryStore) StoreBatch(ctx context.Context, memories []Memory) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	var byShard [memoryShards][]*Memory
+	for _, memory := range memories {
+		stored, err := hms.prepare(ctx, memory, false)
+		if err != nil {
+			return err
+		}
+		i := maphash.String(hms.memories.seed, stored.ID) % memoryShards
+		byShard[i] = append(byShard[i], stored)
+	}
+	for i, stored := range byShard {
+		if len(stored) == 0 {
+			continue
+		}
+		shard := &hms.memories.shards[i]
+		shard.mu.Lock()
+		for _, memory := range stored {
+			hms.insert(shard, memory)
+		}
+		shard.mu.Unlock()
+	}
+	
+	hms.pruneOverCapacity()
+	return nil
+}
+
+// insert adds a memory to its shard. It is called with the shard's lock
+// held.
+func (hms *HierarchicalMemoryStore) insert(shard *memoryShard, memory *Memory) {
+	if stored, exists := shard.memories[memory.ID]; !exists {
+		hms.count.Add(1)
+	} else {
+		hms.countType(stored.Type, -1)
+	}
+	hms.countType(memory.Type, 1)
+	shard.memories[memory.ID] = memory
 	
+	// Add to hierarchy
+	hms.addToHierarchy(memory)
+}
+
+// prepare gives a memory to be stored an ID, namespace, creation time,
+// tags and encrypted content as needed. classify asks the tag classifier.
+func (hms *HierarchicalMemoryStore) prepare(ctx context.Context, memory Memory, classify bool) (*Memory, error) {
 	if memory.ID == "" {
 		memory.ID = uuid.New().String()
 	}
+	if memory.Namespace == "" {
+		memory.Namespace = hms.namespace
+	}
 	
 	if memory.CreatedAt.IsZero() {
 		memory.CreatedAt = time.Now()
 	}
+	if hms.tagging != nil {
+		memory.Tags = hms.tagging.tags(ctx, &memory, classify)
+	}
 	
 	// Encrypt if requested
 	if memory.Encrypted && hms.encryptionKey != nil {
 		encrypted, err := hms.encrypt(memory.Content)
 		if err != nil {
-			return fmt.Errorf("encryption failed: %w", err)
+			return nil, fmt.Errorf("encryption failed: %w", err)
 		}
 		memory.Content = encrypted
 	}
 	
-	hms.memories[memory.ID] = &memory
-	
-	// Add to hierarchy
-	hms.addToHierarchy(&memory)
-	
-	// Check if we need to prune
-	if len(hms.memories) > hms.maxMemories {
-		hms.pruneOldest()
-	}
-	
-	return nil
+	return &memory, nil
 }
 
 // Retrieve gets a memory by ID
-func (hms *HierarchicalMemoryStore) Retrieve(id string) (*Memory, error) {
-	hms.mu.RLock()
-	defer hms.mu.RUnlock()
+func (hms *HierarchicalMemoryStore) Retrieve(ctx context.Context, id string) (*Memory, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	
-	memory, exists := hms.memories[id]
+	shard := hms.memories.shard(id)
+	shard.mu.Lock()
+	stored, exists := shard.memories[id]
 	if !exists {
-		return nil, fmt.Errorf("memory not found: %s", id)
+		shard.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrMemoryNotFound, id)
 	}
 	
-	// Update access statistics
-	memory.AccessCount++
-	memory.LastAccessed = time.Now()
+	// Update access statistics on a copy, scans may be reading the
+	// stored memory
+	accessed := *stored
+	accessed.AccessCount++
+	accessed.LastAccessed = time.Now()
+	shard.memories[id] = &accessed
+	shard.mu.Unlock()
 	
+	memory := accessed
 	// Decrypt if needed
 	if memory.Encrypted && hms.encryptionKey != nil {
 		decrypted, err := hms.decrypt(memory.Content)
 		if err != nil {
-			return nil, fmt.Errorf("decryption failed: %w", err)
+			return nil, fmt.Errorf("%w: %w", ErrDecryption, err)
 		}
-		
-		// Return a copy with decrypted content
-		decryptedMemory := *memory
-		decryptedMemory.Content = decrypted
-		return &decryptedMemory, nil
+		memory.Content = decrypted
 	}
 	
-	return memory, nil
+	return &memory, nil
 }
 
-// Update modifies an existing memory
-func (hms *HierarchicalMemoryStore) Update(id string, memory Memory) error {
-	hms.mu.Lock()
-	defer hms.mu.Unlock()
-	
-	if _, exists := hms.memories[id]; !exists {
-		return fmt.Errorf("memory not found: %s", id)
+// Update modifies an existing memory. It keeps whether the memory is
+// pinned, which only Pin changes, and its namespace unless given another.
+func (hms *HierarchicalMemoryStore) Update(ctx context.Context, id string, memory Memory) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 	
 	memory.ID = id
+	if hms.tagging != nil {
+		memory.Tags = hms.tagging.tags(ctx, &memory, false)
+	}
 	
 	if memory.Encrypted && hms.encryptionKey != nil {
 		encrypted, err := hms.encrypt(memory.Content)
@@ -140,43 +249,105 @@ func (hms *HierarchicalMemoryStore) Update(id string, memory Memory) error {
 		memory.Content = encrypted
 	}
 	
-	hms.memories[id] = &memory
+	shard := hms.memories.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	
+	stored, exists := shard.memories[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrMemoryNotFound, id)
+	}
+	
+	memory.Pinned = stored.Pinned
+	if memory.Namespace == "" {
+		memory.Namespace = stored.Namespace
+	}
+	hms.countType(stored.Type, -1)
+	hms.countType(memory.Type, 1)
+	shard.memories[id] = &memory
 	return nil
 }
 
 // Delete removes a memory
-func (hms *HierarchicalMemoryStore) Delete(id string) error {
-	hms.mu.Lock()
-	defer hms.mu.Unlock()
+func (hms *HierarchicalMemoryStore) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	hms.remove(id, nil)
+	return nil
+}
+
+// Pin sets whether a memory is pinned
+func (hms *HierarchicalMemoryStore) Pin(ctx context.Context, id string, pinned bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	shard := hms.memories.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
 	
-	delete(hms.memories, id)
+	stored, exists := shard.memories[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrMemoryNotFound, id)
+	}
+	
+	// Change a copy, scans may be reading the stored memory. A prune that
+	// marked the memory before leaves the copy alone.
+	changed := *stored
+	changed.Pinned = pinned
+	shard.memories[id] = &changed
 	return nil
 }
 
+// remove deletes a memory, if given only while it is still that memory.
+// It returns whether the memory was deleted.
+func (hms *HierarchicalMemoryStore) remove(id string, memory *Memory) bool {
+	shard := hms.memories.shard(id)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	
+	stored, exists := shard.memories[id]
+	if !exists || (memory != nil && stored != memory) {
+		return false
+	}
+	delete(shard.memories, id)
+	hms.count.Add(-1)
+	hms.countType(stored.Type, -1)
+	return true
+}
+
 // Query searches for memories matching criteria
-func (hms *HierarchicalMemoryStore) Query(query MemoryQuery) ([]Memory, error) {
-	hms.mu.RLock()
-	defer hms.mu.RUnlock()
+func (hms *HierarchicalMemoryStore) Query(ctx context.Context, query MemoryQuery) ([]Memory, error) {
+	if hms.tagging != nil && len(query.Tags) > 0 {
+		tags := make([]string, len(query.Tags))
+		for i, tag := range query.Tags {
+			tags[i] = hms.tagging.normalize(tag)
+		}
+		query.Tags = tags
+	}
 	
 	var results []Memory
 	
-	for _, memory := range hms.memories {
-		if hms.matchesQuery(memory, query) {
+	err := hms.memories.scan(ctx, func(memory *Memory) bool {
+		if matchesQuery(memory, query) {
 			results = append(results, *memory)
 			if len(results) >= query.Limit && query.Limit > 0 {
-				break
+				return false
 			}
 		}
+		return true
+	})
+	if err != nil {
+		return nil, err
 	}
 	
 	return results, nil
 }
 
 // VectorSearch performs similarity search using vectors
-func (hms *HierarchicalMemoryStore) VectorSearch(vector []float64, limit int) ([]Memory, error) {
-	hms.mu.RLock()
-	defer hms.mu.RUnlock()
-	
+func (hms *HierarchicalMemoryStore) VectorSearch(ctx context.Context, vector []float64, limit int) ([]Memory, error) {
 	// Calculate cosine similarity for all memories with vectors
 	type scoredMemory struct {
 		memory *Memory
@@ -184,23 +355,26 @@ func (hms *HierarchicalMemoryStore) VectorSearch(vector []float64, limit int) ([
 	}
 	
 	var scored []scoredMemory
-	for _, memory := range hms.memories {
+	err := hms.memories.scan(ctx, func(memory *Memory) bool {
 		if len(memory.Vector) > 0 {
 			similarity := cosineSimilarity(vector, memory.Vector)
 			scored = append(scored, scoredMemory{memory, similarity})
 		}
+		return true
+	})
+	if err != nil {
+		return nil, err
 	}
 	
-	// Sort by score (descending)
-	// Simple bubble sort for now
-	for i := 0; i < len(scored); i++ {
-		for j := i + 1; j < len(scored); j++ {
-			if scored[j].score > scored[i].score {
-				scored[i], scored[j] = scored[j], scored[i]
-			}
-		}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 	
+	// Sort by score (descending)
+	sort.Slice(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+	
 	// Return top results
 	var results []Memory
 	for i := 0; i < len(scored) && i < limit; i++ {
@@ -211,16 +385,22 @@ func (hms *HierarchicalMemoryStore) VectorSearch(vector []float64, limit int) ([
 }
 
 // Consolidate merges and organizes memories
-func (hms *HierarchicalMemoryStore) Consolidate() error {
-	hms.mu.Lock()
-	defer hms.mu.Unlock()
+func (hms *HierarchicalMemoryStore) Consolidate(ctx context.Context) error {
+	// Merge floods of identical memories, e.g. repeated log lines
+	if _, err := hms.compact(ctx); err != nil {
+		return err
+	}
 	
 	// Group similar episodic memories into semantic memories
 	episodicMemories := make([]*Memory, 0)
-	for _, memory := range hms.memories {
-		if memory.Type == MemoryTypeEpisodic {
+	err := hms.memories.scan(ctx, func(memory *Memory) bool {
+		if memory.Type == MemoryTypeEpisodic && !memory.Pinned {
 			episodicMemories = append(episodicMemories, memory)
 		}
+		return true
+	})
+	if err != nil {
+		return err
 	}
 	
 	// Consolidate episodic memories (simplified version)
@@ -230,29 +410,50 @@ func (hms *HierarchicalMemoryStore) Consolidate() error {
 }
 
 // Prune removes memories based on criteria
-func (hms *HierarchicalMemoryStore) Prune(criteria PruneCriteria) error {
-	hms.mu.Lock()
-	defer hms.mu.Unlock()
-	
+func (hms *HierarchicalMemoryStore) Prune(ctx context.Context, criteria PruneCriteria) error {
 	cutoffTime := time.Now().Add(-criteria.MaxAge)
-	toDelete := make([]string, 0)
-	
-	for id, memory := range hms.memories {
-		// Skip if it has a preserved tag
-		if hasAnyTag(memory.Tags, criteria.PreserveTags) {
-			continue
+	toDelete := make([]*Memory, 0)
+	total := 0
+	
+	// Nothing is deleted until every memory was checked, so a cancelled
+	// prune leaves the store untouched
+	err := hms.memories.scan(ctx, func(memory *Memory) bool {
+		total++
+		// Skip if it is pinned or has a preserved tag
+		if memory.Pinned || hasAnyTag(memory.Tags, criteria.PreserveTags) {
+			return true
 		}
 		
 		// Check criteria
 		if memory.CreatedAt.Before(cutoffTime) ||
 			memory.AccessCount < criteria.MinAccessCount {
-			toDelete = append(toDelete, id)
+			toDelete = append(toDelete, memory)
 		}
+		return true
+	})
+	if err != nil {
+		return err
 	}
 	
-	// Delete marked memories
-	for _, id := range toDelete {
-		delete(hms.memories, id)
+	if hms.approvePrune != nil && len(toDelete) > 0 {
+		plan := PrunePlan{
+			Criteria: criteria,
+			Deleting: len(toDelete),
+			Total:    total,
+			ByType:   make(map[MemoryType]int),
+		}
+		for _, memory := range toDelete {
+			plan.ByType[memory.Type]++
+		}
+		if err := hms.approvePrune(ctx, plan); err != nil {
+			return err
+		}
+	}
+	
+	// Delete marked memories, unless they changed since, e.g. were
+	// accessed
+	for _, memory := range toDelete {
+		hms.remove(memory.ID, memory)
 	}
 	
 	return nil
@@ -260,18 +461,21 @@ func (hms *HierarchicalMemoryStore) Prune(criteria PruneCriteria) error {
 
 // GetStats returns statistics about the memory store
 func (hms *HierarchicalMemoryStore) GetStats() MemoryStats {
-	hms.mu.RLock()
-	defer hms.mu.RUnlock()
-	
 	stats := MemoryStats{
-		TotalMemories:  len(hms.memories),
 		MemoriesByType: make(map[MemoryType]int),
 	}
 	
 	var totalAccess int
 	var oldest, newest time.Time
 	
-	for _, memory := range hms.memories {
+	_ = hms.memories.scan(context.Background(), func(memory *Memory) bool {
+		stats.TotalMemories++
+		if memory.Pinned {
+			stats.PinnedMemories++
+		}
+		if memory.Occurrences > 1 {
+			stats.CompactedMemories++
+		}
 		stats.MemoriesByType[memory.Type]++
 		totalAccess += memory.AccessCount
 		
@@ -281,12 +485,14 @@ func (hms *HierarchicalMemoryStore) GetStats() MemoryStats {
 		if newest.IsZero() || memory.CreatedAt.After(newest) {
 			newest = memory.CreatedAt
 		}
-	}
+		return true
+	})
 	
-	if len(hms.memories) > 0 {
-		stats.AverageAccessCount = float64(totalAccess) / float64(len(hms.memories))
+	if stats.TotalMemories > 0 {
+		stats.AverageAccessCount = float64(totalAccess) / float64(stats.TotalMemories)
 	}
 	
+	stats.MergedMemories = hms.merged.Load()
 	stats.OldestMemory = oldest
 	stats.NewestMemory = newest
 	
@@ -295,32 +501,74 @@ func (hms *HierarchicalMemoryStore) GetStats() MemoryStats {
 
 // Helper methods
 
+// contextCheckInterval is how many items long scans process between checks
+// for cancellation
+const contextCheckInterval = 256
+
+// checkContext returns the context's error every contextCheckInterval items
+func checkContext(ctx context.Context, i int) error {
+	if i%contextCheckInterval != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
+
 func (hms *HierarchicalMemoryStore) addToHierarchy(memory *Memory) {
 	// Simplified hierarchy addition
 	// In a real implementation, this would use semantic clustering
 }
 
-func (hms *HierarchicalMemoryStore) pruneOldest() {
-	// Find and remove oldest, least accessed memories
+// pruneOverCapacity evicts memories of types over their quota, then
+// removes the oldest memories never accessed while the store holds more
+// than maxMemories. Pinned memories may keep it over.
+func (hms *HierarchicalMemoryStore) pruneOverCapacity() {
+	if hms.count.Load() <= int64(hms.maxMemories) && !hms.overQuota() {
+		return
+	}
+	hms.pruneMu.Lock()
+	defer hms.pruneMu.Unlock()
+	hms.pruneOverQuota()
+	for hms.count.Load() > int64(hms.maxMemories) {
+		if !hms.pruneOldest() {
+			return
+		}
+	}
+}
+
+// pruneOldest removes the oldest memory never accessed, not pinned and of
+// a type without a quota, and reports whether there was one
+func (hms *HierarchicalMemoryStore) pruneOldest() bool {
+	// Each shard is only read briefly, so it is not worth a snapshot
 	var oldest *Memory
-	for _, memory := range hms.memories {
-		if oldest == nil || memory.CreatedAt.Before(oldest.CreatedAt) {
-			if memory.AccessCount == 0 {
-				oldest = memory
+	for i := range hms.memories.shards {
+		shard := &hms.memories.shards[i]
+		shard.mu.RLock()
+		for _, memory := range shard.memories {
+			if oldest == nil || memory.CreatedAt.Before(oldest.CreatedAt) {
+				if memory.AccessCount == 0 && !memory.Pinned && hms.quotas[memory.Type] == nil {
+					oldest = memory
+				}
 			}
 		}
+		shard.mu.RUnlock()
 	}
 	
-	if oldest != nil {
-		delete(hms.memories, oldest.ID)
+	if oldest == nil {
+		return false
 	}
+	hms.remove(oldest.ID, oldest)
+	return true
 }
 
-func (hms *HierarchicalMemoryStore) matchesQuery(memory *Memory, query MemoryQuery) bool {
+func matchesQuery(memory *Memory, query MemoryQuery) bool {
 	if query.Type != "" && memory.Type != query.Type {
 		return false
 	}
 	
+	if query.Namespace != "" && memory.Namespace != query.Namespace {
+		return false
+	}
+	
 	if memory.Priority < query.MinPriority {
 		return false
 	}
@@ -331,14 +579,55 @@ func (hms *HierarchicalMemoryStore) matchesQuery(memory *Memory, query MemoryQue
 	
 	if query.TimeRange != nil {
 		if memory.CreatedAt.Before(query.TimeRange.Start) ||
-			memory.CreatedAt.After(query.TimeRange.End) {
+			(!query.TimeRange.End.IsZero() && memory.CreatedAt.After(query.TimeRange.End)) {
 			return false
 		}
 	}
 	
+	if query.SearchText != "" && !containsText(memory, query.SearchText) {
+		return false
+	}
+	
 	return true
 }
 
+// containsText reports whether the content, tags or metadata of a memory
+// contain text, ignoring case. Encrypted content is not searched.
+func containsText(memory *Memory, text string) bool {
+	text = strings.ToLower(text)
+	
+	for _, tag := range memory.Tags {
+		if strings.Contains(strings.ToLower(tag), text) {
+			return true
+		}
+	}
+	for key, value := range memory.Metadata {
+		if strings.Contains(strings.ToLower(key), text) ||
+			strings.Contains(strings.ToLower(fmt.Sprint(value)), text) {
+			return true
+		}
+	}
+	
+	return strings.Contains(strings.ToLower(contentText(memory)), text)
+}
+
+// contentText returns the content of a memory as text, JSON for content
+// that is not a string. Encrypted content has no text.
+func contentText(memory *Memory) string {
+	if memory.Encrypted {
+		return ""
+	}
+	content, ok := memory.Content.(string)
+	if !ok {
+		data, err := json.Marshal(memory.Content)
+		if err != nil {
+			return ""
+		}
+		content = string(data)
+	}
+	return content
+}
+
 func (hms *HierarchicalMemoryStore) encrypt(data interface{}) ([]byte, error) {
 	plaintext, err := json.Marshal(data)
 	if err != nil {
@@ -414,7 +703,7 @@ func cosineSimilarity(a, b []float64) float64 {
 		return 0
 	}
 	
-	return dotProduct / (normA * normB)
+	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
 }
 
 func hasAnyTag(tags, searchTags []string) bool {
@@ -0,0 +1,242 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/perfbudget"
+)
+
+const (
+	benchMemories  = 10000
+	benchVectorDim = 64
+)
+
+var benchTags = []string{"build", "test", "deploy", "lint", "review", "security", "docs", "refactor"}
+
+// newBenchStore returns a store filled with n memories of every type, half
+// of them with embeddings
+func newBenchStore(b *testing.B, n int) *HierarchicalMemoryStore {
+	b.Helper()
+	store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{MaxMemories: n * 2})
+	rng := rand.New(rand.NewSource(1))
+	types := []MemoryType{MemoryTypeWorking, MemoryTypeEpisodic, MemoryTypeSemantic, MemoryTypeProcedural}
+	ctx := context.Background()
+	for i := 0; i < n; i++ {
+		mem := Memory{
+			ID:        fmt.Sprintf("mem-%d", i),
+			Type:      types[i%len(types)],
+			Content:   fmt.Sprintf("task %d finished with outcome %d", i, rng.Intn(100)),
+			Tags:      []string{benchTags[i%len(benchTags)], benchTags[(i/len(benchTags))%len(benchTags)]},
+			Priority:  MemoryPriority(i % 4),
+			CreatedAt: time.Now().Add(-time.Duration(i) * time.Second),
+		}
+		if i%2 == 0 {
+			mem.Vector = randomVector(rng)
+		}
+		if err := store.Store(ctx, mem); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return store
+}
+
+func randomVector(rng *rand.Rand) []float64 {
+	v := make([]float64, benchVectorDim)
+	for i := range v {
+		v[i] = rng.Float64()*2 - 1
+	}
+	return v
+}
+
+func BenchmarkStore(b *testing.B) {
+	store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{MaxMemories: b.N + 1})
+	ctx := context.Background()
+	mem := Memory{
+		Type:    MemoryTypeEpisodic,
+		Content: "task finished",
+		Tags:    []string{"test"},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mem.ID = ""
+		if err := store.Store(ctx, mem); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkStoreAtCapacity stores into a full store, so every memory
+// stored evicts another
+func BenchmarkStoreAtCapacity(b *testing.B) {
+	store := newBenchStore(b, benchMemories)
+	store.maxMemories = benchMemories
+	ctx := context.Background()
+	mem := Memory{
+		Type:    MemoryTypeEpisodic,
+		Content: "task finished",
+		Tags:    []string{"test"},
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		mem.ID = ""
+		if err := store.Store(ctx, mem); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkMonitorWrites compares storing every log entry as it arrives
+// with batching, while agents read the store. It reports the cost per entry
+// including storing it.
+func BenchmarkMonitorWrites(b *testing.B) {
+	b.Run("direct", func(b *testing.B) {
+		store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{MaxMemories: b.N + 1})
+		ctx := context.Background()
+		defer readContinuously(store)()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if err := store.Store(ctx, Memory{Type: MemoryTypeEpisodic, Content: "task finished", Tags: []string{"log"}}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("batched", benchmarkBatchWriter)
+}
+
+func benchmarkBatchWriter(b *testing.B) {
+	store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{MaxMemories: b.N + 1})
+	w := NewBatchWriter(store, BatchConfig{QueueSize: b.N + 1})
+	w.Start()
+	defer readContinuously(store)()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w.Write(Memory{Type: MemoryTypeEpisodic, Content: "task finished", Tags: []string{"log"}})
+	}
+	if err := w.Close(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+	b.StopTimer()
+	if stats := w.Stats(); stats.Written != int64(b.N) {
+		b.Fatalf("wrote %d memories, want %d", stats.Written, b.N)
+	}
+}
+
+// readContinuously reads the store from another goroutine until stopped
+func readContinuously(store *HierarchicalMemoryStore) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ctx := context.Background()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			store.Retrieve(ctx, "missing")
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// BenchmarkStoreDuringQueries stores while another goroutine searches the
+// text of every memory
+func BenchmarkStoreDuringQueries(b *testing.B) {
+	store := newBenchStore(b, benchMemories)
+	store.maxMemories = benchMemories + b.N
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for ctx.Err() == nil {
+			_, _ = store.Query(ctx, MemoryQuery{SearchText: "outcome 42"})
+		}
+	}()
+	mem := Memory{Type: MemoryTypeEpisodic, Content: "task finished", Tags: []string{"test"}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := store.Store(context.Background(), mem); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+	cancel()
+	<-stopped
+}
+
+func BenchmarkQuery(b *testing.B) {
+	store := newBenchStore(b, benchMemories)
+	ctx := context.Background()
+	query := MemoryQuery{
+		Type:        MemoryTypeEpisodic,
+		Tags:        []string{"security"},
+		MinPriority: PriorityNormal,
+		Limit:       20,
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := store.Query(ctx, query); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkQueryText(b *testing.B) {
+	store := newBenchStore(b, benchMemories)
+	ctx := context.Background()
+	query := MemoryQuery{SearchText: "outcome 42"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := store.Query(ctx, query); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVectorSearch(b *testing.B) {
+	store := newBenchStore(b, benchMemories)
+	ctx := context.Background()
+	vector := randomVector(rand.New(rand.NewSource(2)))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := store.VectorSearch(ctx, vector, 10); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestPerformanceBudgets(t *testing.T) {
+	perfbudget.Enforce(t,
+		perfbudget.Budget{Name: "Store", Bench: BenchmarkStore, MaxTime: 20 * time.Microsecond, MaxAllocs: 4},
+		perfbudget.Budget{Name: "StoreAtCapacity", Bench: BenchmarkStoreAtCapacity, MaxTime: 1 * time.Millisecond, MaxAllocs: 4},
+		perfbudget.Budget{Name: "StoreDuringQueries", Bench: BenchmarkStoreDuringQueries, MaxTime: 100 * time.Microsecond, MaxAllocs: 4},
+		perfbudget.Budget{Name: "BatchWriter", Bench: benchmarkBatchWriter, MaxTime: 50 * time.Microsecond, MaxAllocs: -1},
+		perfbudget.Budget{Name: "Query", Bench: BenchmarkQuery, MaxTime: 500 * time.Microsecond, MaxAllocs: 16},
+		perfbudget.Budget{Name: "QueryText", Bench: BenchmarkQueryText, MaxTime: 40 * time.Millisecond, MaxAllocs: -1},
+		perfbudget.Budget{Name: "VectorSearch", Bench: BenchmarkVectorSearch, MaxTime: 10 * time.Millisecond, MaxAllocs: 32},
+	)
+}
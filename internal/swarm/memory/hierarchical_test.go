@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPinnedMemories(t *testing.T) {
+	store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{MaxMemories: 2})
+	ctx := context.Background()
+	old := time.Now().Add(-time.Hour)
+	for _, id := range []string{"conventions", "scratch"} {
+		if err := store.Store(ctx, Memory{ID: id, Type: MemoryTypeSemantic, CreatedAt: old}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Pin(ctx, "conventions", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Pin(ctx, "missing", true); !errors.Is(err, ErrMemoryNotFound) {
+		t.Errorf("Pin(missing) = %v, want ErrMemoryNotFound", err)
+	}
+
+	// Updates keep the pin
+	if err := store.Update(ctx, "conventions", Memory{Type: MemoryTypeSemantic, Content: "tabs", CreatedAt: old}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Capacity pruning passes over the pinned memory, even though it is
+	// the oldest
+	if err := store.Store(ctx, Memory{ID: "new", Type: MemoryTypeWorking}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Retrieve(ctx, "scratch"); !errors.Is(err, ErrMemoryNotFound) {
+		t.Errorf("scratch was kept, want it pruned over capacity")
+	}
+
+	if err := store.Prune(ctx, PruneCriteria{MaxAge: time.Minute, MinAccessCount: 5}); err != nil {
+		t.Fatal(err)
+	}
+	memories, err := store.Query(ctx, MemoryQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memories) != 1 || memories[0].ID != "conventions" || !memories[0].Pinned {
+		t.Fatalf("memories after prune = %+v, want only the pinned one", memories)
+	}
+	if stats := store.GetStats(); stats.PinnedMemories != 1 {
+		t.Errorf("pinned memories = %d, want 1", stats.PinnedMemories)
+	}
+
+	// Unpinned, it is pruned like any other
+	if err := store.Pin(ctx, "conventions", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Prune(ctx, PruneCriteria{MaxAge: time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	if stats := store.GetStats(); stats.TotalMemories != 0 {
+		t.Errorf("memories = %d, want the unpinned memory pruned", stats.TotalMemories)
+	}
+}
+
+func TestCompactRepeats(t *testing.T) {
+	store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{CompactThreshold: 3})
+	ctx := context.Background()
+	first := time.Now().Add(-time.Hour)
+	flood := []Memory{
+		{ID: "a", Fingerprint: "disk full", Content: "disk full", CreatedAt: first},
+		{ID: "b", Fingerprint: "disk full", Content: "disk full", CreatedAt: first.Add(time.Minute)},
+		{ID: "c", Fingerprint: "disk full", Content: "disk full", CreatedAt: first.Add(2 * time.Minute)},
+		{ID: "once", Fingerprint: "started", Content: "started", CreatedAt: first},
+		{ID: "twice1", Fingerprint: "retrying", Content: "retrying", CreatedAt: first},
+		{ID: "twice2", Fingerprint: "retrying", Content: "retrying", CreatedAt: first},
+	}
+	if err := store.StoreBatch(ctx, flood); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Consolidate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	stats := store.GetStats()
+	if stats.TotalMemories != 4 || stats.CompactedMemories != 1 || stats.MergedMemories != 2 {
+		t.Fatalf("stats = %+v, want the flood compacted and the rest below the threshold", stats)
+	}
+	compacted, err := store.Retrieve(ctx, "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compacted.Occurrences != 3 || !compacted.CreatedAt.Equal(first) || !compacted.LastSeen.Equal(first.Add(2*time.Minute)) {
+		t.Errorf("compacted = %d occurrences from %v to %v, want 3 over the flood", compacted.Occurrences, compacted.CreatedAt, compacted.LastSeen)
+	}
+
+	// Later repeats are added to the compacted memory
+	more := []Memory{
+		{ID: "d", Fingerprint: "disk full", Content: "disk full", CreatedAt: first.Add(3 * time.Minute)},
+		{ID: "e", Fingerprint: "retrying", Content: "retrying", CreatedAt: first},
+	}
+	if err := store.StoreBatch(ctx, more); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Consolidate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if compacted, err := store.Retrieve(ctx, "c"); err != nil || compacted.Occurrences != 4 {
+		t.Errorf("compacted = %+v, %v, want 4 occurrences", compacted, err)
+	}
+	if stats := store.GetStats(); stats.TotalMemories != 3 || stats.CompactedMemories != 2 || stats.MergedMemories != 5 {
+		t.Errorf("stats = %+v, want both floods compacted", stats)
+	}
+}
+
+func TestTypeQuotas(t *testing.T) {
+	store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{
+		MaxMemories: 5,
+		Quotas: map[MemoryType]TypeQuota{
+			MemoryTypeEpisodic: {Max: 2},
+			MemoryTypeSemantic: {Max: 2, Eviction: EvictLeastRecentlyUsed},
+		},
+	})
+	ctx := context.Background()
+	start := time.Now().Add(-time.Hour)
+	store1 := func(id string, memoryType MemoryType, age int) {
+		t.Helper()
+		err := store.Store(ctx, Memory{ID: id, Type: memoryType, CreatedAt: start.Add(time.Duration(age) * time.Minute)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	exists := func(id string) bool {
+		_, err := store.Retrieve(ctx, id)
+		return err == nil
+	}
+
+	store1("how-to-build", MemoryTypeProcedural, 0)
+	store1("fact1", MemoryTypeSemantic, 1)
+	store1("fact2", MemoryTypeSemantic, 2)
+	// fact1 is used, so fact2 was used longest ago
+	if !exists("fact1") {
+		t.Fatal("fact1 missing")
+	}
+	store1("fact3", MemoryTypeSemantic, 3)
+	if exists("fact2") || !exists("fact3") {
+		t.Errorf("semantic quota evicted the wrong memory")
+	}
+
+	// A flood of logs only evicts logs, even with the store at capacity
+	for i := 0; i < 10; i++ {
+		store1(fmt.Sprintf("log%d", i), MemoryTypeEpisodic, 10+i)
+	}
+	stats := store.GetStats()
+	if stats.MemoriesByType[MemoryTypeEpisodic] != 2 || !exists("log9") || !exists("log8") {
+		t.Errorf("episodic memories = %d, want the 2 newest logs", stats.MemoriesByType[MemoryTypeEpisodic])
+	}
+	if !exists("how-to-build") || !exists("fact1") || !exists("fact3") {
+		t.Errorf("log flood evicted knowledge")
+	}
+
+	// Changing the type of a memory moves it to the other quota
+	if err := store.Update(ctx, "log9", Memory{Type: MemoryTypeProcedural}); err != nil {
+		t.Fatal(err)
+	}
+	store1("log10", MemoryTypeEpisodic, 20)
+	if !exists("log8") || !exists("log10") {
+		t.Errorf("episodic quota evicted a log while under quota")
+	}
+}
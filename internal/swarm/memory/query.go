@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// priorityNames are the names of priorities in queries
+var priorityNames = map[string]MemoryPriority{
+	"low":      PriorityLow,
+	"normal":   PriorityNormal,
+	"high":     PriorityHigh,
+	"critical": PriorityCritical,
+}
+
+// ParseQuery parses a textual query such as
+//
+//	type:episodic tag:error after:2024-01-01 "connection timeout"
+//
+// Terms are type:<type>, ns:<namespace>, tag:<tag> (repeatable, any tag matches),
+// priority:<min priority>, limit:<n>, and after:<time> and before:<time>
+// where a time is a date, an RFC 3339 time or a duration ago such as 24h.
+// The other words and quoted phrases, joined by spaces, are the text
+// searched for.
+func ParseQuery(s string) (MemoryQuery, error) {
+	return parseQuery(s, time.Now())
+}
+
+func parseQuery(s string, now time.Time) (MemoryQuery, error) {
+	var query MemoryQuery
+	words, err := splitQuery(s)
+	if err != nil {
+		return query, err
+	}
+
+	var text []string
+	for _, word := range words {
+		if word.quoted {
+			text = append(text, word.text)
+			continue
+		}
+		key, value, ok := strings.Cut(word.text, ":")
+		if !ok || value == "" {
+			text = append(text, word.text)
+			continue
+		}
+
+		key = strings.ToLower(key)
+		switch key {
+		case "type":
+			switch t := MemoryType(strings.ToLower(value)); t {
+			case MemoryTypeWorking, MemoryTypeEpisodic, MemoryTypeSemantic, MemoryTypeProcedural:
+				query.Type = t
+			default:
+				return query, fmt.Errorf("%w: unknown type %q", ErrInvalidQuery, value)
+			}
+		case "ns":
+			query.Namespace = value
+		case "tag":
+			query.Tags = append(query.Tags, value)
+		case "priority":
+			priority, ok := priorityNames[strings.ToLower(value)]
+			if !ok {
+				return query, fmt.Errorf("%w: unknown priority %q, expected low, normal, high or critical", ErrInvalidQuery, value)
+			}
+			query.MinPriority = priority
+		case "limit":
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit < 0 {
+				return query, fmt.Errorf("%w: limit must be a number, got %q", ErrInvalidQuery, value)
+			}
+			query.Limit = limit
+		case "after", "before":
+			t, err := parseQueryTime(value, now)
+			if err != nil {
+				return query, fmt.Errorf("%w: %s: %v", ErrInvalidQuery, key, err)
+			}
+			if query.TimeRange == nil {
+				query.TimeRange = &TimeRange{}
+			}
+			if key == "after" {
+				query.TimeRange.Start = t
+			} else {
+				query.TimeRange.End = t
+			}
+		default:
+			// Not a term, e.g. a URL or "error: timeout"
+			text = append(text, word.text)
+		}
+	}
+	query.SearchText = strings.Join(text, " ")
+	return query, nil
+}
+
+// parseQueryTime parses a date, an RFC 3339 time or a duration before now
+func parseQueryTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, now.Location()); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("expected a date such as 2024-01-01, an RFC 3339 time or a duration such as 24h, got %q", value)
+}
+
+// queryWord is a word of a query, or a quoted phrase
+type queryWord struct {
+	text   string
+	quoted bool
+}
+
+// splitQuery splits a query at spaces outside of double quotes
+func splitQuery(s string) ([]queryWord, error) {
+	var words []queryWord
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return words, nil
+		}
+		if s[0] == '"' {
+			end := strings.IndexByte(s[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated quote", ErrInvalidQuery)
+			}
+			if phrase := s[1 : end+1]; phrase != "" {
+				words = append(words, queryWord{text: phrase, quoted: true})
+			}
+			s = s[end+2:]
+			continue
+		}
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		words = append(words, queryWord{text: s[:end]})
+		s = s[end:]
+	}
+}
+
+// String writes the query in the form ParseQuery reads. Vectors and
+// children are left out, and so are double quotes in the search text,
+// which queries cannot express.
+func (q MemoryQuery) String() string {
+	var terms []string
+	if q.Type != "" {
+		terms = append(terms, "type:"+string(q.Type))
+	}
+	if q.Namespace != "" {
+		terms = append(terms, "ns:"+q.Namespace)
+	}
+	for _, tag := range q.Tags {
+		terms = append(terms, "tag:"+tag)
+	}
+	if q.MinPriority > PriorityLow {
+		for name, priority := range priorityNames {
+			if priority == q.MinPriority {
+				terms = append(terms, "priority:"+name)
+			}
+		}
+	}
+	if q.TimeRange != nil {
+		if !q.TimeRange.Start.IsZero() {
+			terms = append(terms, "after:"+q.TimeRange.Start.Format(time.RFC3339))
+		}
+		if !q.TimeRange.End.IsZero() {
+			terms = append(terms, "before:"+q.TimeRange.End.Format(time.RFC3339))
+		}
+	}
+	if q.Limit > 0 {
+		terms = append(terms, "limit:"+strconv.Itoa(q.Limit))
+	}
+	if q.SearchText != "" {
+		terms = append(terms, `"`+strings.ReplaceAll(q.SearchText, `"`, "")+`"`)
+	}
+	return strings.Join(terms, " ")
+}
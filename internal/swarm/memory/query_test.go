@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	query, err := parseQuery(`type:episodic ns:global tag:error tag:lsp after:2024-01-01 before:24h priority:high limit:5 "connection timeout" db`, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Type != MemoryTypeEpisodic || query.Namespace != GlobalNamespace || len(query.Tags) != 2 || query.Tags[1] != "lsp" ||
+		query.MinPriority != PriorityHigh || query.Limit != 5 || query.SearchText != "connection timeout db" {
+		t.Errorf("query = %+v", query)
+	}
+	if r := query.TimeRange; r == nil || !r.Start.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || !r.End.Equal(now.Add(-24*time.Hour)) {
+		t.Errorf("time range = %+v", query.TimeRange)
+	}
+
+	// String writes what ParseQuery reads
+	again, err := parseQuery(query.String(), now)
+	if err != nil || again.String() != query.String() {
+		t.Errorf("parsed %q as %q, %v", query.String(), again.String(), err)
+	}
+
+	// Words that are not terms are searched for
+	if query, _ := parseQuery("http://localhost:8080 error:", now); query.SearchText != "http://localhost:8080 error:" {
+		t.Errorf("search text = %q", query.SearchText)
+	}
+
+	for _, s := range []string{"type:dream", "priority:urgent", "limit:many", "after:yesterday", `"open`} {
+		if _, err := parseQuery(s, now); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("parseQuery(%q) = %v, want ErrInvalidQuery", s, err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// EvictionPolicy picks the memory a type quota removes
+type EvictionPolicy string
+
+const (
+	// EvictOldest removes the memory created first, e.g. for logs
+	EvictOldest EvictionPolicy = "oldest"
+	// EvictLeastRecentlyUsed removes the memory accessed longest ago, a
+	// memory never accessed counting as accessed when created
+	EvictLeastRecentlyUsed EvictionPolicy = "lru"
+	// EvictLowestPriority removes the memory of the lowest priority, the
+	// oldest of equals
+	EvictLowestPriority EvictionPolicy = "priority"
+)
+
+// ValidateEvictionPolicy checks that an eviction policy is known
+func ValidateEvictionPolicy(policy EvictionPolicy) error {
+	switch policy {
+	case "", EvictOldest, EvictLeastRecentlyUsed, EvictLowestPriority:
+		return nil
+	}
+	return fmt.Errorf("unknown eviction policy %q, expected %s, %s or %s", policy, EvictOldest, EvictLeastRecentlyUsed, EvictLowestPriority)
+}
+
+// MemoryTypes are the types of memories
+var MemoryTypes = []MemoryType{MemoryTypeWorking, MemoryTypeEpisodic, MemoryTypeSemantic, MemoryTypeProcedural}
+
+// TypeQuota caps how many memories of one type a store keeps
+type TypeQuota struct {
+	Max int
+	// Eviction picks the memory removed over the cap, EvictOldest if empty
+	Eviction EvictionPolicy
+}
+
+// quota tracks the memories of a type with a TypeQuota
+type quota struct {
+	TypeQuota
+	count atomic.Int64
+}
+
+func newQuotas(config map[MemoryType]TypeQuota) map[MemoryType]*quota {
+	if len(config) == 0 {
+		return nil
+	}
+	quotas := make(map[MemoryType]*quota, len(config))
+	for memoryType, q := range config {
+		if q.Max <= 0 {
+			continue
+		}
+		if q.Eviction == "" {
+			q.Eviction = EvictOldest
+		}
+		quotas[memoryType] = &quota{TypeQuota: q}
+	}
+	return quotas
+}
+
+// countType adds delta to the count of a type with a quota
+func (hms *HierarchicalMemoryStore) countType(memoryType MemoryType, delta int64) {
+	if q := hms.quotas[memoryType]; q != nil {
+		q.count.Add(delta)
+	}
+}
+
+// pruneOverQuota evicts memories of the types over their quota. Pinned
+// memories may keep a type over. It is called with pruneMu held.
+func (hms *HierarchicalMemoryStore) pruneOverQuota() {
+	for memoryType, q := range hms.quotas {
+		for q.count.Load() > int64(q.Max) {
+			if !hms.evict(memoryType, q.Eviction) {
+				break
+			}
+		}
+	}
+}
+
+// overQuota reports whether a type is over its quota
+func (hms *HierarchicalMemoryStore) overQuota() bool {
+	for _, q := range hms.quotas {
+		if q.count.Load() > int64(q.Max) {
+			return true
+		}
+	}
+	return false
+}
+
+// evict removes the memory of a type the policy picks, and reports whether
+// there was one that is not pinned
+func (hms *HierarchicalMemoryStore) evict(memoryType MemoryType, policy EvictionPolicy) bool {
+	var victim *Memory
+	for i := range hms.memories.shards {
+		shard := &hms.memories.shards[i]
+		shard.mu.RLock()
+		for _, memory := range shard.memories {
+			if memory.Type != memoryType || memory.Pinned {
+				continue
+			}
+			if victim == nil || evictsBefore(policy, memory, victim) {
+				victim = memory
+			}
+		}
+		shard.mu.RUnlock()
+	}
+
+	if victim == nil {
+		return false
+	}
+	// A victim changed meanwhile stays, the next round picks again
+	hms.remove(victim.ID, victim)
+	return true
+}
+
+// evictsBefore reports whether the policy evicts a before b
+func evictsBefore(policy EvictionPolicy, a, b *Memory) bool {
+	switch policy {
+	case EvictLeastRecentlyUsed:
+		return lastUsed(a).Before(lastUsed(b))
+	case EvictLowestPriority:
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+// lastUsed is when a memory was last accessed, or created if never
+func lastUsed(memory *Memory) time.Time {
+	if memory.LastAccessed.IsZero() {
+		return memory.CreatedAt
+	}
+	return memory.LastAccessed
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Snapshotter is implemented by stores that can copy themselves for
+// analytics without blocking writes for long
+type Snapshotter interface {
+	Snapshot(ctx context.Context) (*Replica, error)
+}
+
+// Replica is a read-only copy of a memory store at one point in time. It
+// is meant for heavy reads such as pattern mining and reports, which would
+// otherwise compete with agents for the store's locks. Reading a replica
+// does not count as accessing its memories, and encrypted memories stay
+// encrypted.
+type Replica struct {
+	takenAt  time.Time
+	memories []*Memory
+	index    map[string]*Memory
+}
+
+// Snapshot returns a replica of the store. All shards are locked together
+// while their memories are collected, so the replica never shows half of a
+// write to several shards. Stored memories are never modified, so the
+// replica shares them instead of copying their contents.
+func (hms *HierarchicalMemoryStore) Snapshot(ctx context.Context) (*Replica, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s := hms.memories
+	for i := range s.shards {
+		s.shards[i].mu.RLock()
+	}
+	size := 0
+	for i := range s.shards {
+		size += len(s.shards[i].memories)
+	}
+	memories := make([]*Memory, 0, size)
+	for i := range s.shards {
+		for _, memory := range s.shards[i].memories {
+			memories = append(memories, memory)
+		}
+	}
+	for i := range s.shards {
+		s.shards[i].mu.RUnlock()
+	}
+
+	// Sorting and indexing happen after the locks are released
+	sort.Slice(memories, func(i, j int) bool {
+		return memories[i].ID < memories[j].ID
+	})
+	index := make(map[string]*Memory, len(memories))
+	for _, memory := range memories {
+		index[memory.ID] = memory
+	}
+	return &Replica{takenAt: time.Now(), memories: memories, index: index}, nil
+}
+
+// TakenAt returns when the replica was taken
+func (r *Replica) TakenAt() time.Time {
+	return r.takenAt
+}
+
+// Len returns how many memories the replica holds
+func (r *Replica) Len() int {
+	return len(r.memories)
+}
+
+// Get returns a copy of a memory
+func (r *Replica) Get(id string) (*Memory, error) {
+	memory, ok := r.index[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMemoryNotFound, id)
+	}
+	copied := *memory
+	return &copied, nil
+}
+
+// Each calls fn with a copy of every memory, ordered by ID, until it
+// returns false
+func (r *Replica) Each(ctx context.Context, fn func(Memory) bool) error {
+	for i, memory := range r.memories {
+		if err := checkContext(ctx, i); err != nil {
+			return err
+		}
+		if !fn(*memory) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Query returns the memories matching a query, ordered by ID. Unlike the
+// store's, tags are matched as given.
+func (r *Replica) Query(ctx context.Context, query MemoryQuery) ([]Memory, error) {
+	var results []Memory
+	err := r.Each(ctx, func(memory Memory) bool {
+		if matchesQuery(&memory, query) {
+			results = append(results, memory)
+		}
+		return query.Limit <= 0 || len(results) < query.Limit
+	})
+	return results, err
+}
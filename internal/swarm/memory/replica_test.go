@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestReplica(t *testing.T) {
+	store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{EncryptionKey: make([]byte, 32)})
+	ctx := context.Background()
+	memories := []Memory{
+		{ID: "a", Type: MemoryTypeEpisodic, Content: "disk full", Tags: []string{"log", "error"}},
+		{ID: "b", Type: MemoryTypeSemantic, Content: map[string]interface{}{"fix": "rotate logs"}},
+		{ID: "c", Type: MemoryTypeSemantic, Content: "api key", Encrypted: true},
+	}
+	if err := store.StoreBatch(ctx, memories); err != nil {
+		t.Fatal(err)
+	}
+
+	replica, err := store.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Later writes and reads of the store do not change the replica
+	if err := store.Store(ctx, Memory{ID: "d", Content: "later"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Retrieve(ctx, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if replica.Len() != 3 {
+		t.Errorf("replica holds %d memories, want 3", replica.Len())
+	}
+	if _, err := replica.Get("d"); !errors.Is(err, ErrMemoryNotFound) {
+		t.Errorf("Get(d) = %v, want ErrMemoryNotFound", err)
+	}
+	b, err := replica.Get("b")
+	if err != nil || b.AccessCount != 0 {
+		t.Errorf("Get(b) = %+v, %v, want it unaccessed", b, err)
+	}
+	found, err := replica.Query(ctx, MemoryQuery{Tags: []string{"error"}})
+	if err != nil || len(found) != 1 || found[0].ID != "a" {
+		t.Errorf("Query(error) = %+v, %v, want a", found, err)
+	}
+
+	var buf bytes.Buffer
+	if err := replica.Export(ctx, &buf, ExportCSV); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 4 || records[1][0] != "a" || records[1][4] != "log,error" || records[2][5] != `{"fix":"rotate logs"}` || records[3][5] != "" {
+		t.Errorf("csv = %q, want a header and the memories without encrypted content", records)
+	}
+
+	buf.Reset()
+	if err := replica.Export(ctx, &buf, ExportParquet); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := parquet.Read[exportRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0].Content != "disk full" || rows[0].Occurrences != 1 || rows[0].CreatedAt.IsZero() || rows[0].LastAccessed != nil {
+		t.Errorf("parquet rows = %+v, want the memories", rows)
+	}
+
+	if err := replica.Export(ctx, &buf, "xlsx"); !errors.Is(err, ErrUnknownExportFormat) {
+		t.Errorf("Export(xlsx) = %v, want ErrUnknownExportFormat", err)
+	}
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// defaultVectorWeight weighs vector similarity against keyword matches when
+// both the query and a memory have a vector
+const defaultVectorWeight = 0.5
+
+// HybridQuery searches memories by keywords and, when given, by embedding
+type HybridQuery struct {
+	// Text is matched word by word against content, tags and metadata
+	Text string
+	// Vector is compared to the embeddings of memories that have one
+	Vector []float64
+	// Types limits the search, every type if empty
+	Types []MemoryType
+	// VectorWeight is the share of the score from vector similarity,
+	// between 0 and 1. Zero means 0.5.
+	VectorWeight float64
+	// MinScore drops memories scoring lower, between 0 and 1
+	MinScore float64
+	Limit    int
+}
+
+// ScoredMemory is a memory found by HybridSearch with its score between 0
+// and 1
+type ScoredMemory struct {
+	Memory
+	Score float64
+}
+
+// HybridSearch finds the memories of a store best matching the query, best
+// first. Keyword scores are the share of query words a memory contains;
+// memories and queries with embeddings also score by cosine similarity.
+// Ties go to higher priorities, then to newer memories.
+func HybridSearch(ctx context.Context, store MemoryStore, query HybridQuery) ([]ScoredMemory, error) {
+	terms := searchTerms(query.Text)
+	if len(terms) == 0 && len(query.Vector) == 0 {
+		return nil, nil
+	}
+	weight := query.VectorWeight
+	if weight <= 0 || weight > 1 {
+		weight = defaultVectorWeight
+	}
+
+	types := query.Types
+	if len(types) == 0 {
+		types = []MemoryType{""}
+	}
+	var scored []ScoredMemory
+	for _, memoryType := range types {
+		candidates, err := store.Query(ctx, MemoryQuery{Type: memoryType})
+		if err != nil {
+			return nil, err
+		}
+		for _, memory := range candidates {
+			score := keywordScore(&memory, terms)
+			if len(query.Vector) > 0 && len(memory.Vector) > 0 {
+				similarity := max(cosineSimilarity(query.Vector, memory.Vector), 0)
+				if len(terms) == 0 {
+					score = similarity
+				} else {
+					score = weight*similarity + (1-weight)*score
+				}
+			}
+			if score > 0 && score >= query.MinScore {
+				scored = append(scored, ScoredMemory{Memory: memory, Score: score})
+			}
+		}
+	}
+
+	sort.Slice(scored, func(i, j int) bool {
+		a, b := scored[i], scored[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	if query.Limit > 0 && len(scored) > query.Limit {
+		scored = scored[:query.Limit]
+	}
+	return scored, nil
+}
+
+// keywordScore returns the share of terms found in a memory
+func keywordScore(memory *Memory, terms []string) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	found := 0
+	for _, term := range terms {
+		if containsText(memory, term) {
+			found++
+		}
+	}
+	return float64(found) / float64(len(terms))
+}
+
+// stopWords are too common to tell memories apart
+var stopWords = map[string]bool{
+	"and": true, "are": true, "but": true, "can": true, "for": true,
+	"from": true, "has": true, "have": true, "how": true, "not": true,
+	"the": true, "this": true, "that": true, "was": true, "what": true,
+	"when": true, "where": true, "which": true, "why": true, "with": true,
+	"you": true, "your": true,
+}
+
+// searchTerms splits text into distinct lower case words of three letters
+// or more, leaving out stop words
+func searchTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-'
+	})
+	seen := make(map[string]bool)
+	var terms []string
+	for _, word := range words {
+		word = strings.Trim(word, "-_")
+		if len(word) < 3 || stopWords[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+	}
+	return terms
+}
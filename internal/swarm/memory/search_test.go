@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHybridSearch(t *testing.T) {
+	store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{})
+	ctx := context.Background()
+	now := time.Now()
+	memories := []Memory{
+		{ID: "flaky", Type: MemoryTypeSemantic, Content: "The parser tests are flaky on Windows", CreatedAt: now},
+		{ID: "parser", Type: MemoryTypeProcedural, Content: "Regenerate the parser with go generate", Priority: PriorityHigh, CreatedAt: now},
+		{ID: "parser-old", Type: MemoryTypeProcedural, Content: "Regenerate the parser by hand", CreatedAt: now.Add(-time.Hour)},
+		{ID: "log", Type: MemoryTypeEpisodic, Content: "parser tests failed", CreatedAt: now},
+		{ID: "vector", Type: MemoryTypeSemantic, Content: "unrelated words", Vector: []float64{1, 0}, CreatedAt: now},
+	}
+	for _, mem := range memories {
+		if err := store.Store(ctx, mem); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func(found []ScoredMemory) []string {
+		var ids []string
+		for _, mem := range found {
+			ids = append(ids, mem.ID)
+		}
+		return ids
+	}
+	search := func(query HybridQuery) []ScoredMemory {
+		t.Helper()
+		scored, err := HybridSearch(ctx, store, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return scored
+	}
+
+	tests := []struct {
+		name  string
+		query HybridQuery
+		want  []string
+	}{
+		{
+			name: "keywords",
+			// "the" and "are" are stop words, "parser" and "flaky" count
+			query: HybridQuery{Text: "Why are the parser tests flaky?", Types: []MemoryType{MemoryTypeSemantic, MemoryTypeProcedural}},
+			want:  []string{"flaky", "parser", "parser-old"},
+		},
+		{
+			name:  "every type",
+			query: HybridQuery{Text: "parser tests", Limit: 2},
+			want:  []string{"flaky", "log"},
+		},
+		{
+			name:  "minimum score",
+			query: HybridQuery{Text: "flaky parser", Types: []MemoryType{MemoryTypeProcedural}, MinScore: 0.6},
+			want:  nil,
+		},
+		{
+			name:  "vector",
+			query: HybridQuery{Vector: []float64{2, 0}},
+			want:  []string{"vector"},
+		},
+		{
+			name:  "nothing to search for",
+			query: HybridQuery{Text: "the and"},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ids(search(tt.query))
+			if len(got) != len(tt.want) {
+				t.Fatalf("found %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("found %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	scored := search(HybridQuery{Text: "parser", Vector: []float64{1, 0}, VectorWeight: 0.25})
+	for _, mem := range scored {
+		if mem.ID == "vector" && mem.Score != 0.25 {
+			t.Errorf("vector match without keywords scored %v, want 0.25", mem.Score)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"hash/maphash"
+	"sync"
+)
+
+// memoryShards is how many shards the memories of a store are spread
+// over, each with its own lock
+const memoryShards = 16
+
+// memoryShard holds the memories whose IDs hash to it. Memories in a shard
+// are never modified, changes replace them, so a memory read under the
+// lock may be used after releasing it.
+type memoryShard struct {
+	mu       sync.RWMutex
+	memories map[string]*Memory
+}
+
+// shardedMemories spreads memories over shards so that writes to one shard
+// do not wait for reads of another
+type shardedMemories struct {
+	seed   maphash.Seed
+	shards [memoryShards]memoryShard
+}
+
+func newShardedMemories() *shardedMemories {
+	s := &shardedMemories{seed: maphash.MakeSeed()}
+	for i := range s.shards {
+		s.shards[i].memories = make(map[string]*Memory)
+	}
+	return s
+}
+
+// shard returns the shard of a memory ID
+func (s *shardedMemories) shard(id string) *memoryShard {
+	return &s.shards[maphash.String(s.seed, id)%memoryShards]
+}
+
+// snapshotPool recycles the buffers scans copy the memories of a shard to
+var snapshotPool = sync.Pool{
+	New: func() interface{} { return new([]*Memory) },
+}
+
+// scan calls fn with every memory until it returns false. Each shard is
+// copied under its lock and scanned after releasing it, so long scans do
+// not block writes. Memories stored or removed during a scan may or may
+// not be seen.
+func (s *shardedMemories) scan(ctx context.Context, fn func(*Memory) bool) error {
+	buf := snapshotPool.Get().(*[]*Memory)
+	defer func() {
+		clear(*buf)
+		snapshotPool.Put(buf)
+	}()
+
+	scanned := 0
+	for i := range s.shards {
+		shard := &s.shards[i]
+		snapshot := (*buf)[:0]
+		shard.mu.RLock()
+		for _, memory := range shard.memories {
+			snapshot = append(snapshot, memory)
+		}
+		shard.mu.RUnlock()
+		*buf = snapshot
+
+		for _, memory := range snapshot {
+			if err := checkContext(ctx, scanned); err != nil {
+				return err
+			}
+			scanned++
+			if !fn(memory) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestScanDoesNotBlockWrites(t *testing.T) {
+	store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{})
+	ctx := context.Background()
+	for i := 0; i < 100; i++ {
+		if err := store.Store(ctx, Memory{ID: fmt.Sprint(i), Type: MemoryTypeEpisodic}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Writing from within a scan would deadlock if it held a lock
+	scanned := 0
+	err := store.memories.scan(ctx, func(memory *Memory) bool {
+		scanned++
+		if err := store.Store(ctx, Memory{ID: "during-" + memory.ID}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := store.Retrieve(ctx, memory.ID); err != nil {
+			t.Fatal(err)
+		}
+		return scanned < 10
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := store.GetStats().TotalMemories; got != 110 {
+		t.Errorf("TotalMemories = %d, want 110", got)
+	}
+}
+
+// TestConcurrentAccess is meant for the race detector
+func TestConcurrentAccess(t *testing.T) {
+	store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{MaxMemories: 50})
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				id := fmt.Sprintf("%d-%d", w, i)
+				_ = store.Store(ctx, Memory{ID: id, Type: MemoryTypeEpisodic, Vector: []float64{1, float64(i)}})
+				_, _ = store.Retrieve(ctx, id)
+				_, _ = store.Query(ctx, MemoryQuery{Type: MemoryTypeEpisodic, Limit: 5})
+				_, _ = store.VectorSearch(ctx, []float64{1, 0}, 3)
+				if i%10 == 0 {
+					_ = store.Prune(ctx, PruneCriteria{MinAccessCount: 1})
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if stats, count := store.GetStats(), store.count.Load(); int64(stats.TotalMemories) != count {
+		t.Errorf("TotalMemories = %d, but %d counted", stats.TotalMemories, count)
+	}
+}
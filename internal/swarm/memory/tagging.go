@@ -0,0 +1,195 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/provider"
+)
+
+// Tagger suggests tags for a memory about to be stored
+type Tagger interface {
+	Tag(ctx context.Context, memory Memory) ([]string, error)
+}
+
+// TagRule adds Tag to memories whose content or metadata contain any of
+// the keywords, ignoring case
+type TagRule struct {
+	Tag      string
+	Keywords []string
+}
+
+// DefaultTagRules are the keyword rules used unless configured otherwise
+var DefaultTagRules = []TagRule{
+	{Tag: "error", Keywords: []string{"error", "exception", "panic:", "fatal", "traceback", "segmentation fault"}},
+	{Tag: "performance", Keywords: []string{"slow", "latency", "timed out", "timeout", "memory leak", "out of memory", "high cpu", "throughput"}},
+	{Tag: "security", Keywords: []string{"vulnerability", "cve-", "injection", "xss", "unauthorized", "permission denied", "leaked secret"}},
+	{Tag: "test", Keywords: []string{"--- fail", "test failed", "assertion failed", "flaky"}},
+	{Tag: "build", Keywords: []string{"build failed", "compilation failed", "undefined:", "syntax error"}},
+}
+
+// DefaultTagAliases map the spellings of tags seen in the wild to the tag
+// queries use
+var DefaultTagAliases = map[string]string{
+	"err":     "error",
+	"errors":  "error",
+	"warn":    "warning",
+	"perf":    "performance",
+	"sec":     "security",
+	"tests":   "test",
+	"testing": "test",
+	"fixes":   "fix",
+}
+
+// classifyTimeout bounds a classification so a slow model does not hold up
+// stores
+const classifyTimeout = 30 * time.Second
+
+// TaggingConfig normalizes the tags of stored memories and adds tags from
+// keyword rules and an optional classifier
+type TaggingConfig struct {
+	// Rules are the keyword rules, DefaultTagRules if nil
+	Rules []TagRule
+	// Aliases rename tags after they are lower cased, DefaultTagAliases if
+	// nil
+	Aliases map[string]string
+	// Classifier, if set, suggests more tags, e.g. a ModelTagger. It only
+	// sees memories stored one at a time that are not encrypted, batches
+	// are tagged by the rules alone. Its errors leave the memory with the
+	// other tags.
+	Classifier Tagger
+}
+
+// tagging applies a TaggingConfig
+type tagging struct {
+	rules      []TagRule
+	aliases    map[string]string
+	classifier Tagger
+}
+
+func newTagging(config *TaggingConfig) *tagging {
+	if config == nil {
+		return nil
+	}
+	t := &tagging{
+		rules:      config.Rules,
+		aliases:    config.Aliases,
+		classifier: config.Classifier,
+	}
+	if t.rules == nil {
+		t.rules = DefaultTagRules
+	}
+	if t.aliases == nil {
+		t.aliases = DefaultTagAliases
+	}
+	return t
+}
+
+// NormalizeTag lower cases a tag and joins its words with dashes
+func NormalizeTag(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	return strings.Join(strings.FieldsFunc(tag, func(r rune) bool {
+		return r == ' ' || r == '_' || r == '\t'
+	}), "-")
+}
+
+// normalize returns a tag as queries use it
+func (t *tagging) normalize(tag string) string {
+	tag = NormalizeTag(tag)
+	if alias, ok := t.aliases[tag]; ok {
+		return alias
+	}
+	return tag
+}
+
+// tags returns the normalized tags of a memory with those of the rules
+// and, if classify is set, of the classifier
+func (t *tagging) tags(ctx context.Context, memory *Memory, classify bool) []string {
+	tags := make([]string, 0, len(memory.Tags))
+	add := func(tag string) {
+		if tag = t.normalize(tag); tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range memory.Tags {
+		add(tag)
+	}
+
+	text := strings.ToLower(contentText(memory))
+	if text == "" {
+		return tags
+	}
+	for key, value := range memory.Metadata {
+		text += "\n" + strings.ToLower(fmt.Sprint(key, " ", value))
+	}
+	for _, rule := range t.rules {
+		for _, keyword := range rule.Keywords {
+			if strings.Contains(text, strings.ToLower(keyword)) {
+				add(rule.Tag)
+				break
+			}
+		}
+	}
+
+	if classify && t.classifier != nil {
+		ctx, cancel := context.WithTimeout(ctx, classifyTimeout)
+		defer cancel()
+		if suggested, err := t.classifier.Tag(ctx, *memory); err == nil {
+			for _, tag := range suggested {
+				add(tag)
+			}
+		}
+	}
+	return tags
+}
+
+// ModelTagger asks a model which of a fixed set of tags apply to a memory,
+// so it cannot invent tags queries would not know about
+type ModelTagger struct {
+	Client provider.Client
+	// Tags the model picks from, the tags of DefaultTagRules if empty
+	Tags []string
+}
+
+// maxClassifiedContent bounds how much of a memory is sent to the model
+const maxClassifiedContent = 4000
+
+// Tag implements Tagger
+func (m ModelTagger) Tag(ctx context.Context, memory Memory) ([]string, error) {
+	allowed := m.Tags
+	if len(allowed) == 0 {
+		for _, rule := range DefaultTagRules {
+			allowed = append(allowed, rule.Tag)
+		}
+	}
+	content := contentText(&memory)
+	if content == "" {
+		return nil, nil
+	}
+	if len(content) > maxClassifiedContent {
+		content = content[:maxClassifiedContent]
+	}
+
+	resp, err := m.Client.Complete(ctx, provider.Request{
+		System: "You tag memories of a software project. Answer with the tags that apply, " +
+			"separated by commas, chosen only from: " + strings.Join(allowed, ", ") +
+			". Answer none if no tag applies.",
+		Prompt:    fmt.Sprintf("Memory type: %s\n\n%s", memory.Type, content),
+		MaxTokens: 50,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("classify memory: %w", err)
+	}
+
+	var tags []string
+	for _, answer := range strings.Split(resp.Content, ",") {
+		tag := NormalizeTag(strings.Trim(answer, " .\n"))
+		if slices.Contains(allowed, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
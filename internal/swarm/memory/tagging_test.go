@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/swarm/provider"
+)
+
+// fakeModel answers every prompt the same
+type fakeModel struct {
+	answer  string
+	prompts int
+}
+
+func (m *fakeModel) Complete(ctx context.Context, req provider.Request) (provider.Response, error) {
+	m.prompts++
+	return provider.Response{Content: m.answer}, nil
+}
+
+func (m *fakeModel) Model() string {
+	return "fake"
+}
+
+func TestTagging(t *testing.T) {
+	model := &fakeModel{answer: "Security, sarcasm."}
+	store := NewHierarchicalMemoryStore(HierarchicalMemoryConfig{
+		EncryptionKey: make([]byte, 32),
+		Tagging:       &TaggingConfig{Classifier: ModelTagger{Client: model}},
+	})
+	ctx := context.Background()
+
+	err := store.Store(ctx, Memory{ID: "log", Tags: []string{"log", "ERROR", "Errors", "Shell Command"}, Content: "GET /api timed out after 30s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem, err := store.Retrieve(ctx, "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"log", "error", "shell-command", "performance", "security"}
+	if !slices.Equal(mem.Tags, want) {
+		t.Errorf("tags = %v, want %v", mem.Tags, want)
+	}
+
+	// Queries normalize their tags the same way
+	found, err := store.Query(ctx, MemoryQuery{Tags: []string{"Perf"}})
+	if err != nil || len(found) != 1 {
+		t.Errorf("Query(Perf) = %v, %v, want the log", found, err)
+	}
+
+	// Batches and encrypted memories are not classified, and the rules do
+	// not read encrypted content
+	err = store.StoreBatch(ctx, []Memory{{ID: "batch", Content: "panic: nil map"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Store(ctx, Memory{ID: "secret", Content: "fatal error", Encrypted: true}); err != nil {
+		t.Fatal(err)
+	}
+	if model.prompts != 1 {
+		t.Errorf("classified %d memories, want 1", model.prompts)
+	}
+	for id, want := range map[string][]string{"batch": {"error"}, "secret": {}} {
+		mem, err := store.Retrieve(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(mem.Tags, want) {
+			t.Errorf("tags of %s = %v, want %v", id, mem.Tags, want)
+		}
+	}
+}
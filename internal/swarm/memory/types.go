@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"context"
 	"time"
 )
 
@@ -14,6 +15,15 @@ const (
 	MemoryTypeProcedural MemoryType = "procedural" // How-to knowledge
 )
 
+// GlobalNamespace holds the knowledge of a project that outlives the
+// sessions of its swarms
+const GlobalNamespace = "global"
+
+// SessionNamespace is the namespace of the memories of one swarm session
+func SessionNamespace(id string) string {
+	return "session/" + id
+}
+
 // MemoryPriority defines importance levels
 type MemoryPriority int
 
@@ -28,6 +38,8 @@ const (
 type Memory struct {
 	ID          string
 	Type        MemoryType
+	// Namespace scopes the memory, e.g. to a session or GlobalNamespace
+	Namespace   string
 	Content     interface{}
 	Metadata    map[string]interface{}
 	Vector      []float64 // Embedding for semantic search
@@ -38,6 +50,17 @@ type Memory struct {
 	CreatedAt   time.Time
 	ExpiresAt   *time.Time
 	Encrypted   bool
+	// Pinned memories are never pruned or consolidated, see
+	// MemoryStore.Pin
+	Pinned      bool
+	// Fingerprint identifies memories that repeat, e.g. the same log
+	// line. Consolidation compacts memories sharing one.
+	Fingerprint string
+	// Occurrences is how many memories a compacted memory stands for,
+	// zero meaning one. CreatedAt is then when the first was stored and
+	// LastSeen when the last was.
+	Occurrences int
+	LastSeen    time.Time
 	Parent      string // For hierarchical organization
 	Children    []string
 }
@@ -45,6 +68,7 @@ type Memory struct {
 // MemoryQuery represents a query for memories
 type MemoryQuery struct {
 	Type         MemoryType
+	Namespace    string
 	Tags         []string
 	SearchText   string
 	Vector       []float64
@@ -54,27 +78,33 @@ type MemoryQuery struct {
 	IncludeChildren bool
 }
 
-// TimeRange defines a time period
+// TimeRange defines a time period. A zero Start or End leaves that side
+// open.
 type TimeRange struct {
 	Start time.Time
 	End   time.Time
 }
 
-// MemoryStore defines the interface for memory storage
+// MemoryStore defines the interface for memory storage. Every operation
+// returns the context's error once it is cancelled; scans over the whole
+// store stop early without changing anything.
 type MemoryStore interface {
 	// CRUD operations
-	Store(memory Memory) error
-	Retrieve(id string) (*Memory, error)
-	Update(id string, memory Memory) error
-	Delete(id string) error
+	Store(ctx context.Context, memory Memory) error
+	Retrieve(ctx context.Context, id string) (*Memory, error)
+	Update(ctx context.Context, id string, memory Memory) error
+	Delete(ctx context.Context, id string) error
 	
 	// Query operations
-	Query(query MemoryQuery) ([]Memory, error)
-	VectorSearch(vector []float64, limit int) ([]Memory, error)
+	Query(ctx context.Context, query MemoryQuery) ([]Memory, error)
+	VectorSearch(ctx context.Context, vector []float64, limit int) ([]Memory, error)
 	
 	// Maintenance operations
-	Consolidate() error
-	Prune(criteria PruneCriteria) error
+	Consolidate(ctx context.Context) error
+	Prune(ctx context.Context, criteria PruneCriteria) error
+	// Pin protects a memory from pruning and consolidation, or lifts the
+	// protection. Pinned memories are only removed by Delete.
+	Pin(ctx context.Context, id string, pinned bool) error
 	
 	// Statistics
 	GetStats() MemoryStats
@@ -88,9 +118,36 @@ type PruneCriteria struct {
 	PreserveTags   []string
 }
 
+// PrunePlan describes the memories a prune is about to delete
+type PrunePlan struct {
+	Criteria PruneCriteria
+	// Deleting is how many memories the prune deletes, Total how many are
+	// stored
+	Deleting int
+	Total    int
+	ByType   map[MemoryType]int
+}
+
+// Fraction returns the share of the stored memories the prune deletes
+func (p PrunePlan) Fraction() float64 {
+	if p.Total == 0 {
+		return 0
+	}
+	return float64(p.Deleting) / float64(p.Total)
+}
+
+// PruneApprover is asked before a prune deletes memories, and may block,
+// e.g. for a vote. An error cancels the prune, which returns it.
+type PruneApprover func(ctx context.Context, plan PrunePlan) error
+
 // MemoryStats contains statistics about the memory store
 type MemoryStats struct {
 	TotalMemories      int
+	PinnedMemories     int
+	// CompactedMemories are memories standing for repeats
+	CompactedMemories  int
+	// MergedMemories is how many repeats compaction merged away
+	MergedMemories     int64
 	MemoriesByType     map[MemoryType]int
 	TotalSize          int64
 	AverageAccessCount float64
@@ -0,0 +1,323 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+const (
+	// DefaultCIReportInterval is how often reports are looked for, unless
+	// configured otherwise
+	DefaultCIReportInterval = 30 * time.Second
+	// maxCIReportSize bounds the size of a report read
+	maxCIReportSize = 64 * 1024 * 1024
+	// ciFetchTimeout bounds how long a report URL has to answer
+	ciFetchTimeout = 30 * time.Second
+)
+
+// CIReport is a test report read from a CI artifact
+type CIReport struct {
+	// Source is the path or URL the report was read from
+	Source string
+	Time   time.Time
+	Report agent.TestReport
+}
+
+// CIReportWatcherConfig configures the CI report watcher
+type CIReportWatcherConfig struct {
+	// Dir is watched for JUnit XML (*.xml) and go test -json (*.json,
+	// *.jsonl) reports
+	Dir string
+	// URLs are fetched for reports each interval, a report is read again
+	// once it changed
+	URLs []string
+	// Interval is how often Dir and URLs are checked,
+	// DefaultCIReportInterval if zero
+	Interval time.Duration
+	// BufferSize bounds the reports waiting to be read, 100 if zero
+	BufferSize int
+	// Client fetches the URLs, http.DefaultClient if nil
+	Client *http.Client
+	// Clock times the checks, the system clock if nil
+	Clock clock.Clock
+}
+
+// CIReportWatcher reads the test reports of CI runs, from files dropped into
+// a directory or from URLs. A file is read once its size and modification
+// time stayed the same between two checks, so files still being written are
+// not read, and again whenever it changes. Reports that do not parse are
+// skipped until they change.
+type CIReportWatcher struct {
+	config  CIReportWatcherConfig
+	entries chan CIReport
+
+	mu sync.Mutex
+	// files are the size and modification time of the files of Dir, with
+	// whether that version was read
+	files map[string]ciFile
+	// urls are the validators and digest of the last report of each URL
+	urls    map[string]ciURL
+	lastErr error
+
+	ctx        context.Context
+	cancelFunc context.CancelFunc
+	wg         sync.WaitGroup
+	stopOnce   sync.Once
+}
+
+type ciFile struct {
+	size    int64
+	modTime time.Time
+	read    bool
+}
+
+type ciURL struct {
+	etag         string
+	lastModified string
+	digest       [sha256.Size]byte
+}
+
+// NewCIReportWatcher creates a CI report watcher for a directory, URLs or
+// both
+func NewCIReportWatcher(config CIReportWatcherConfig) (*CIReportWatcher, error) {
+	if config.Dir == "" && len(config.URLs) == 0 {
+		return nil, errors.New("CI report watcher needs a directory or URLs")
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultCIReportInterval
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 100
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.Clock == nil {
+		config.Clock = clock.Real
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &CIReportWatcher{
+		config:     config,
+		entries:    make(chan CIReport, config.BufferSize),
+		files:      make(map[string]ciFile),
+		urls:       make(map[string]ciURL),
+		ctx:        ctx,
+		cancelFunc: cancel,
+	}, nil
+}
+
+// Start begins checking for reports
+func (w *CIReportWatcher) Start() error {
+	if w.config.Dir != "" {
+		if info, err := os.Stat(w.config.Dir); err != nil {
+			return fmt.Errorf("failed to watch CI reports: %w", err)
+		} else if !info.IsDir() {
+			return fmt.Errorf("failed to watch CI reports: %s is not a directory", w.config.Dir)
+		}
+	}
+	w.wg.Add(1)
+	go w.monitor()
+	return nil
+}
+
+// Stop stops checking for reports
+func (w *CIReportWatcher) Stop() error {
+	w.stopOnce.Do(func() {
+		w.cancelFunc()
+		w.wg.Wait()
+		close(w.entries)
+	})
+	return nil
+}
+
+// Entries returns the channel of the reports read
+func (w *CIReportWatcher) Entries() <-chan CIReport {
+	return w.entries
+}
+
+// Err returns the error of the last report that could not be read, nil if
+// the last check read everything
+func (w *CIReportWatcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr
+}
+
+// monitor checks for reports each interval
+func (w *CIReportWatcher) monitor() {
+	defer w.wg.Done()
+
+	ticker := w.config.Clock.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			w.check()
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// check reads the reports of the directory and URLs that are new or changed
+func (w *CIReportWatcher) check() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var errs []error
+	if w.config.Dir != "" {
+		errs = append(errs, w.checkDir()...)
+	}
+	for _, url := range w.config.URLs {
+		if err := w.checkURL(url); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	w.lastErr = errors.Join(errs...)
+}
+
+// checkDir reads the report files that did not change since the last check
+// and were not read in that version
+func (w *CIReportWatcher) checkDir() []error {
+	dirEntries, err := os.ReadDir(w.config.Dir)
+	if err != nil {
+		return []error{fmt.Errorf("failed to read CI report directory: %w", err)}
+	}
+	var errs []error
+	present := make(map[string]bool, len(dirEntries))
+	for _, entry := range dirEntries {
+		if entry.IsDir() || !isCIReportFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(w.config.Dir, entry.Name())
+		present[path] = true
+		last, seen := w.files[path]
+		if !seen || last.size != info.Size() || !last.modTime.Equal(info.ModTime()) {
+			// New or still changing, read it once it settles
+			w.files[path] = ciFile{size: info.Size(), modTime: info.ModTime()}
+			continue
+		}
+		if last.read {
+			continue
+		}
+		last.read = true
+		w.files[path] = last
+		if err := w.readFile(path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for path := range w.files {
+		if !present[path] {
+			delete(w.files, path)
+		}
+	}
+	return errs
+}
+
+func (w *CIReportWatcher) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read CI report %s: %w", path, err)
+	}
+	return w.parse(path, data)
+}
+
+// checkURL fetches a report unless the server answers it did not change
+func (w *CIReportWatcher) checkURL(url string) error {
+	ctx, cancel := context.WithTimeout(w.ctx, ciFetchTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fetch CI report %s: %w", url, err)
+	}
+	last := w.urls[url]
+	if last.etag != "" {
+		req.Header.Set("If-None-Match", last.etag)
+	}
+	if last.lastModified != "" {
+		req.Header.Set("If-Modified-Since", last.lastModified)
+	}
+	resp, err := w.config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch CI report %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch CI report %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCIReportSize))
+	if err != nil {
+		return fmt.Errorf("failed to fetch CI report %s: %w", url, err)
+	}
+
+	// Servers without validators answer with the same report until it
+	// changes
+	digest := sha256.Sum256(data)
+	if digest == last.digest {
+		return nil
+	}
+	w.urls[url] = ciURL{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		digest:       digest,
+	}
+	return w.parse(url, data)
+}
+
+// parse reads a JUnit XML or go test -json report, told apart by their
+// first character, and queues it. Reports are dropped when the buffer is
+// full.
+func (w *CIReportWatcher) parse(source string, data []byte) error {
+	var report agent.TestReport
+	var err error
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '<' {
+		report, err = agent.ParseJUnitXML(bytes.NewReader(data))
+	} else {
+		report, err = agent.ParseGoTestJSON(bytes.NewReader(data))
+	}
+	if err != nil {
+		return fmt.Errorf("invalid CI report %s: %w", source, err)
+	}
+	if report.Passed+report.Failed+report.Skipped == 0 && len(report.Failures) == 0 {
+		return fmt.Errorf("invalid CI report %s: no tests found", source)
+	}
+	if report.Command == "" {
+		report.Command = source
+	}
+	select {
+	case w.entries <- CIReport{Source: source, Time: w.config.Clock.Now(), Report: report}:
+	default:
+		// Buffer full, skip
+	}
+	return nil
+}
+
+// isCIReportFile reports whether a file name is of a report format read
+func isCIReportFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xml", ".json", ".jsonl":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package monitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+const junitReport = `<testsuites>
+  <testsuite name="api">
+    <testcase classname="api.Users" name="TestCreate"/>
+    <testcase classname="api.Users" name="TestDelete"><failure message="expected 204">got 500</failure></testcase>
+  </testsuite>
+</testsuites>`
+
+const goTestReport = `{"Action":"run","Package":"example.com/store","Test":"TestGet"}
+{"Action":"output","Package":"example.com/store","Test":"TestGet","Output":"store_test.go:12: missing key\n"}
+{"Action":"fail","Package":"example.com/store","Test":"TestGet"}
+{"Action":"fail","Package":"example.com/store"}
+`
+
+func newTestCIWatcher(t *testing.T, config CIReportWatcherConfig) *CIReportWatcher {
+	t.Helper()
+	config.Clock = clock.NewFake(time.Unix(0, 0))
+	w, err := NewCIReportWatcher(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+// nextReport returns the report read, failing if there is none
+func nextReport(t *testing.T, w *CIReportWatcher) CIReport {
+	t.Helper()
+	select {
+	case report := <-w.Entries():
+		return report
+	default:
+		t.Fatalf("no report read, last error: %v", w.Err())
+		return CIReport{}
+	}
+}
+
+func noReport(t *testing.T, w *CIReportWatcher) {
+	t.Helper()
+	select {
+	case report := <-w.Entries():
+		t.Fatalf("unexpected report of %s", report.Source)
+	default:
+	}
+}
+
+func TestCIReportDir(t *testing.T) {
+	dir := t.TempDir()
+	w := newTestCIWatcher(t, CIReportWatcherConfig{Dir: dir})
+	junit := filepath.Join(dir, "junit.xml")
+	if err := os.WriteFile(junit, []byte(junitReport), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a report"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Files are read once they stay the same between two checks
+	w.check()
+	noReport(t, w)
+	w.check()
+	report := nextReport(t, w)
+	if report.Source != junit || report.Report.Passed != 1 || report.Report.Failed != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if failure := report.Report.Failures[0]; failure.Package != "api.Users" || failure.Name != "TestDelete" || failure.Message != "got 500" {
+		t.Fatalf("unexpected failure %+v", failure)
+	}
+	w.check()
+	noReport(t, w)
+
+	// A go test -json report is told apart by its content
+	gotest := filepath.Join(dir, "go-test.json")
+	if err := os.WriteFile(gotest, []byte(goTestReport), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w.check()
+	w.check()
+	report = nextReport(t, w)
+	if report.Source != gotest || report.Report.Failed != 1 || report.Report.Failures[0].Name != "TestGet" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	// Reports without tests are skipped with an error
+	if err := os.WriteFile(filepath.Join(dir, "empty.json"), []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w.check()
+	w.check()
+	noReport(t, w)
+	if w.Err() == nil {
+		t.Fatal("expected an error for the report without tests")
+	}
+}
+
+func TestCIReportURL(t *testing.T) {
+	body := junitReport
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		etag := `"1"`
+		if body != junitReport {
+			etag = `"2"`
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		rw.Header().Set("ETag", etag)
+		_, _ = rw.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	w := newTestCIWatcher(t, CIReportWatcherConfig{URLs: []string{server.URL}})
+	w.check()
+	report := nextReport(t, w)
+	if report.Source != server.URL || report.Report.Failed != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	// The server answers unchanged reports with 304
+	w.check()
+	noReport(t, w)
+	if requests != 2 {
+		t.Fatalf("expected 2 requests, got %d", requests)
+	}
+
+	body = goTestReport
+	w.check()
+	if report := nextReport(t, w); report.Report.Failures[0].Name != "TestGet" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestCIReportWatcherConfig(t *testing.T) {
+	if _, err := NewCIReportWatcher(CIReportWatcherConfig{}); err == nil {
+		t.Fatal("expected an error without a directory or URLs")
+	}
+	w := newTestCIWatcher(t, CIReportWatcherConfig{Dir: filepath.Join(t.TempDir(), "missing")})
+	if err := w.Start(); err == nil {
+		t.Fatal("expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"bytes"
+	"os"
+)
+
+const (
+	// defaultMmapThreshold is how much unread data makes the watcher map a
+	// file instead of reading it
+	defaultMmapThreshold = 16 * 1024 * 1024
+	// mappedBatchLines is how many lines of a mapped file are parsed and
+	// buffered at a time, so a backfill of a huge file is never held in
+	// memory as strings all at once
+	mappedBatchLines = 4096
+)
+
+// readMapped buffers the entries of a file between offset and size by
+// mapping it into memory, and returns size. Multiline entries are never
+// split between batches. It fails when the file cannot be mapped.
+func (lw *LogWatcher) readMapped(file *os.File, path string, offset, size int64) (int64, error) {
+	data, err := mapFile(file, size)
+	if err != nil {
+		return offset, err
+	}
+	defer unmapFile(data)
+
+	lines := make([]string, 0, mappedBatchLines)
+	for rest := data[offset:]; len(rest) > 0; {
+		line := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			line, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = nil
+		}
+		// Like bufio.ScanLines, drop the carriage return of CRLF
+		text := string(bytes.TrimSuffix(line, []byte{'\r'}))
+
+		if len(lines) >= mappedBatchLines && !(lw.format == FormatMultiline && isContinuation(text)) {
+			lw.bufferEntries(lw.parse(path, lines))
+			lines = lines[:0]
+		}
+		lines = append(lines, text)
+	}
+	lw.bufferEntries(lw.parse(path, lines))
+	return size, nil
+}
@@ -7,45 +7,132 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
 )
 
 // LogEntry represents a parsed log entry
 type LogEntry struct {
+	// Timestamp is when the line was logged, or read if it has no time
 	Timestamp time.Time
-	Level     string
-	Source    string
-	Message   string
-	Fields    map[string]interface{}
+	// IngestedAt is when the line was read
+	IngestedAt time.Time
+	Level      string
+	Source     string
+	Message    string
+	Fields     map[string]interface{}
 }
 
-// LogWatcher monitors log files for changes
+// LogWatcher monitors log files for changes. Entries wait in a bounded
+// buffer until they are read, so reading files never waits for the reader
+// of Entries. When the buffer is full entries are dropped, the oldest
+// low-severity entry first.
 type LogWatcher struct {
 	paths       []string
+	format      string
+	clock       clock.Clock
 	watcher     *fsnotify.Watcher
 	entries     chan LogEntry
+	
+	// Entries read but not yet sent, at most bufferSize
+	buffer      []LogEntry
+	bufferSize  int
+	buffered    chan struct{}
+	drops       LogDropStats
+	bufferMu    sync.Mutex
+	
 	ctx         context.Context
 	cancelFunc  context.CancelFunc
 	wg          sync.WaitGroup
+	stopOnce    sync.Once
 	fileOffsets map[string]int64
 	mu          sync.Mutex
+	
+	// Writes to a file within debounce of its first are read together
+	debounce    time.Duration
+	// readBuf is reused for reading files, only by processEvents
+	readBuf     []byte
+	// Unread data of at least mmapThreshold bytes is read by mapping
+	mmapThreshold int64
+	backfill    bool
+	// metadata are the static fields of the files, guarded by mu
+	metadata    []LogMetadata
+}
+
+// LogDropStats counts the log entries a watcher read and dropped
+type LogDropStats struct {
+	Read    int64
+	Dropped int64
+	// DroppedByLevel counts the dropped entries of each level
+	DroppedByLevel map[string]int64
+	// Buffered is how many entries wait to be read from Entries
+	Buffered    int
+	LastDropped time.Time
 }
 
 // LogWatcherConfig configures the log watcher
 type LogWatcherConfig struct {
 	Paths       []string
+	// BufferSize bounds the entries waiting to be read, 1000 if zero
 	BufferSize  int
-	ParseFormat string // "json", "logfmt", "plain"
+	ParseFormat string // "plain" (default), "json", "logfmt" or "multiline"
+	Clock       clock.Clock // timestamps entries, the system clock if nil
+	// Debounce is how long writes to files are collected before reading
+	// them, 50ms if zero
+	Debounce    time.Duration
+	// Backfill reads the files matched at Start from their beginning
+	// instead of their end
+	Backfill    bool
+	// MmapThreshold is how much unread data of a file makes the watcher
+	// map it into memory instead of reading it, 16MiB if zero. Mapping is
+	// not supported on Windows.
+	MmapThreshold int64
+	// Metadata adds static fields to the entries of the files matched by
+	// path patterns, see LogMetadata
+	Metadata    []LogMetadata
 }
 
+// LogMetadata are static fields, such as the service, environment or host
+// logging, added to the entries of the files Pattern matches. Fields parsed
+// from a line win over them; of two patterns matching a file, the later
+// wins.
+type LogMetadata struct {
+	Pattern string
+	Fields  map[string]string
+}
+
+const (
+	defaultLogDebounce = 50 * time.Millisecond
+	// logReadChunk is the size of the reads of log files. Lines longer
+	// than maxLogLine are skipped.
+	logReadChunk = 64 * 1024
+	maxLogLine   = 1024 * 1024
+)
+
 // NewLogWatcher creates a new log watcher
 func NewLogWatcher(config LogWatcherConfig) (*LogWatcher, error) {
 	if config.BufferSize <= 0 {
 		config.BufferSize = 1000
 	}
+	if config.Clock == nil {
+		config.Clock = clock.Real
+	}
+	if config.Debounce <= 0 {
+		config.Debounce = defaultLogDebounce
+	}
+	if config.MmapThreshold <= 0 {
+		config.MmapThreshold = defaultMmapThreshold
+	}
+	if !validFormat(config.ParseFormat) {
+		return nil, fmt.Errorf("unknown log format %q, expected plain, json, logfmt or multiline", config.ParseFormat)
+	}
+	if err := validMetadata(config.Metadata); err != nil {
+		return nil, err
+	}
 	
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -56,11 +143,20 @@ func NewLogWatcher(config LogWatcherConfig) (*LogWatcher, error) {
 	
 	lw := &LogWatcher{
 		paths:       config.Paths,
+		format:      config.ParseFormat,
+		clock:       config.Clock,
 		watcher:     watcher,
-		entries:     make(chan LogEntry, config.BufferSize),
+		entries:     make(chan LogEntry),
+		bufferSize:  config.BufferSize,
+		buffered:    make(chan struct{}, 1),
+		drops:       LogDropStats{DroppedByLevel: make(map[string]int64)},
 		ctx:         ctx,
 		cancelFunc:  cancel,
 		fileOffsets: make(map[string]int64),
+		debounce:    config.Debounce,
+		mmapThreshold: config.MmapThreshold,
+		backfill:    config.Backfill,
+		metadata:    config.Metadata,
 	}
 	
 	return lw, nil
@@ -68,6 +164,8 @@ func NewLogWatcher(config LogWatcherConfig) (*LogWatcher, error) {
 
 // Start begins monitoring log files
 func (lw *LogWatcher) Start() error {
+	backfill := make(map[string]struct{})
+	
 	// Add all paths to the watcher
 	for _, path := range lw.paths {
 		// Expand glob patterns
@@ -77,9 +175,12 @@ func (lw *LogWatcher) Start() error {
 		}
 		
 		for _, match := range matches {
-			if err := lw.addFile(match); err != nil {
+			if err := lw.addFile(match, lw.backfill); err != nil {
 				return err
 			}
+			if lw.backfill {
+				backfill[match] = struct{}{}
+			}
 		}
 		
 		// Watch directory for new files matching pattern
@@ -90,23 +191,28 @@ func (lw *LogWatcher) Start() error {
 	}
 	
 	// Start the event processing loop
-	lw.wg.Add(1)
-	go lw.processEvents()
+	lw.wg.Add(2)
+	go lw.processEvents(backfill)
+	go lw.sendEntries()
 	
 	return nil
 }
 
-// Stop stops the log watcher
+// Stop stops the log watcher and closes the entries channel. Calling Stop
+// again has no effect.
 func (lw *LogWatcher) Stop() error {
-	lw.cancelFunc()
-	lw.wg.Wait()
-	
-	if err := lw.watcher.Close(); err != nil {
-		return err
-	}
-	
-	close(lw.entries)
-	return nil
+	var err error
+	lw.stopOnce.Do(func() {
+		lw.cancelFunc()
+		lw.wg.Wait()
+		
+		err = lw.watcher.Close()
+		
+		// sendEntries, the only sender, has exited. Buffered entries
+		// are discarded.
+		close(lw.entries)
+	})
+	return err
 }
 
 // Entries returns the channel of log entries
@@ -114,8 +220,104 @@ func (lw *LogWatcher) Entries() <-chan LogEntry {
 	return lw.entries
 }
 
-// addFile starts monitoring a specific file
-func (lw *LogWatcher) addFile(path string) error {
+// DropStats returns how many entries were read and dropped so far
+func (lw *LogWatcher) DropStats() LogDropStats {
+	lw.bufferMu.Lock()
+	defer lw.bufferMu.Unlock()
+	stats := lw.drops
+	stats.DroppedByLevel = make(map[string]int64, len(lw.drops.DroppedByLevel))
+	for level, n := range lw.drops.DroppedByLevel {
+		stats.DroppedByLevel[level] = n
+	}
+	stats.Buffered = len(lw.buffer)
+	return stats
+}
+
+// severeLevels are the levels never shed while lower ones are buffered
+var severeLevels = map[string]bool{
+	"WARN": true, "WARNING": true, "ERROR": true, "ERR": true,
+	"CRITICAL": true, "CRIT": true, "FATAL": true, "PANIC": true,
+}
+
+// bufferEntries adds the entries read from a file for sendEntries
+func (lw *LogWatcher) bufferEntries(entries []LogEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	lw.bufferMu.Lock()
+	for _, entry := range entries {
+		lw.bufferEntry(entry)
+	}
+	lw.bufferMu.Unlock()
+	
+	select {
+	case lw.buffered <- struct{}{}:
+	default:
+	}
+}
+
+// bufferEntry adds an entry to the buffer, dropping one if the buffer is full:
+// the oldest low-severity entry, else the entry itself if it is of low
+// severity, else the oldest entry. It is called with bufferMu held.
+func (lw *LogWatcher) bufferEntry(entry LogEntry) {
+	lw.drops.Read++
+	if len(lw.buffer) >= lw.bufferSize {
+		shed := -1
+		for i, buffered := range lw.buffer {
+			if !severeLevels[strings.ToUpper(buffered.Level)] {
+				shed = i
+				break
+			}
+		}
+		if shed < 0 && !severeLevels[strings.ToUpper(entry.Level)] {
+			lw.dropped(entry)
+			return
+		}
+		shed = max(shed, 0)
+		lw.dropped(lw.buffer[shed])
+		lw.buffer = append(lw.buffer[:shed], lw.buffer[shed+1:]...)
+	}
+	lw.buffer = append(lw.buffer, entry)
+}
+
+// dropped counts a dropped entry. It is called with bufferMu held.
+func (lw *LogWatcher) dropped(entry LogEntry) {
+	lw.drops.Dropped++
+	lw.drops.DroppedByLevel[entry.Level]++
+	lw.drops.LastDropped = lw.clock.Now()
+}
+
+// sendEntries sends buffered entries to Entries, oldest first
+func (lw *LogWatcher) sendEntries() {
+	defer lw.wg.Done()
+	
+	for {
+		lw.bufferMu.Lock()
+		if len(lw.buffer) == 0 {
+			lw.bufferMu.Unlock()
+			select {
+			case <-lw.buffered:
+				continue
+			case <-lw.ctx.Done():
+				return
+			}
+		}
+		entry := lw.buffer[0]
+		lw.buffer[0] = LogEntry{}
+		lw.buffer = lw.buffer[1:]
+		lw.bufferMu.Unlock()
+		
+		select {
+		case lw.entries <- entry:
+		case <-lw.ctx.Done():
+			return
+		}
+	}
+}
+
+// addFile starts monitoring a specific file, from its beginning if
+// fromStart is set and from its end otherwise
+func (lw *LogWatcher) addFile(path string, fromStart bool) error {
 	lw.mu.Lock()
 	defer lw.mu.Unlock()
 	
@@ -126,6 +328,9 @@ func (lw *LogWatcher) addFile(path string) error {
 	}
 	
 	lw.fileOffsets[path] = info.Size()
+	if fromStart {
+		lw.fileOffsets[path] = 0
+	}
 	
 	if err := lw.watcher.Add(path); err != nil {
 		return fmt.Errorf("failed to watch file %s: %w", path, err)
@@ -134,10 +339,17 @@ func (lw *LogWatcher) addFile(path string) error {
 	return nil
 }
 
-// processEvents handles file system events
-func (lw *LogWatcher) processEvents() {
+// processEvents handles file system events. Busy logs are written many
+// times a second, so writes are collected per file and read once the
+// debounce started by the first of them fires. The files to backfill are
+// read first.
+func (lw *LogWatcher) processEvents(backfill map[string]struct{}) {
 	defer lw.wg.Done()
 	
+	lw.handleFileWrites(backfill)
+	
+	written := make(map[string]struct{})
+	var debounce <-chan time.Time
 	for {
 		select {
 		case event, ok := <-lw.watcher.Events:
@@ -146,11 +358,21 @@ func (lw *LogWatcher) processEvents() {
 			}
 			
 			if event.Op&fsnotify.Write == fsnotify.Write {
-				lw.handleFileWrite(event.Name)
+				written[event.Name] = struct{}{}
+				// Not reset by later writes, so a file written
+				// continuously is still read every debounce
+				if debounce == nil {
+					debounce = lw.clock.After(lw.debounce)
+				}
 			} else if event.Op&fsnotify.Create == fsnotify.Create {
 				lw.handleFileCreate(event.Name)
 			}
 			
+		case <-debounce:
+			debounce = nil
+			lw.handleFileWrites(written)
+			clear(written)
+			
 		case err, ok := <-lw.watcher.Errors:
 			if !ok {
 				return
@@ -164,68 +386,195 @@ func (lw *LogWatcher) processEvents() {
 	}
 }
 
-// handleFileWrite processes new data written to a file
-func (lw *LogWatcher) handleFileWrite(path string) {
+// handleFileWrites reads the data written to files since they were last
+// read
+func (lw *LogWatcher) handleFileWrites(paths map[string]struct{}) {
+	offsets := make(map[string]int64, len(paths))
 	lw.mu.Lock()
-	offset, exists := lw.fileOffsets[path]
+	for path := range paths {
+		if offset, exists := lw.fileOffsets[path]; exists {
+			offsets[path] = offset
+		}
+	}
 	lw.mu.Unlock()
 	
-	if !exists {
-		return
+	for path, offset := range offsets {
+		offsets[path] = lw.readFile(path, offset)
 	}
 	
+	lw.mu.Lock()
+	for path, offset := range offsets {
+		// Files no longer watched since are not added back
+		if _, exists := lw.fileOffsets[path]; exists {
+			lw.fileOffsets[path] = offset
+		}
+	}
+	lw.mu.Unlock()
+}
+
+// readFile buffers the entries written to a file after offset and returns
+// the offset read up to
+func (lw *LogWatcher) readFile(path string, offset int64) int64 {
 	file, err := os.Open(path)
 	if err != nil {
-		return
+		return offset
 	}
 	defer file.Close()
 	
+	// Large backlogs, such as backfills, are scanned in place instead
+	// of being copied through read calls
+	if info, err := file.Stat(); err == nil && info.Size()-offset >= lw.mmapThreshold {
+		if newOffset, err := lw.readMapped(file, path, offset, info.Size()); err == nil {
+			return newOffset
+		}
+	}
+	
 	// Seek to last known position
 	if _, err := file.Seek(offset, io.SeekStart); err != nil {
-		return
+		return offset
 	}
 	
+	// Read everything written so far, so multiline entries are joined
+	// within a write
+	if lw.readBuf == nil {
+		lw.readBuf = make([]byte, logReadChunk)
+	}
+	var lines []string
 	scanner := bufio.NewScanner(file)
+	scanner.Buffer(lw.readBuf, maxLogLine)
 	for scanner.Scan() {
-		line := scanner.Text()
-		entry := lw.parseLine(line, path)
-		
-		select {
-		case lw.entries <- entry:
-		case <-lw.ctx.Done():
-			return
+		lines = append(lines, scanner.Text())
+	}
+	
+	lw.bufferEntries(lw.parse(path, lines))
+	
+	newOffset, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return offset
+	}
+	return newOffset
+}
+
+// handleFileCreate handles newly created files
+func (lw *LogWatcher) handleFileCreate(path string) {
+	lw.mu.Lock()
+	matched := matchesAny(lw.paths, path)
+	lw.mu.Unlock()
+	
+	if matched {
+		_ = lw.addFile(path, false)
+	}
+}
+
+// SetPaths replaces the watched path patterns while running. Files matched
+// only by removed patterns are no longer read, files matched by new patterns
+// are read from their current end.
+func (lw *LogWatcher) SetPaths(paths []string) error {
+	for _, pattern := range paths {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid path pattern %s: %w", pattern, err)
 		}
 	}
 	
-	// Update offset
-	newOffset, _ := file.Seek(0, io.SeekCurrent)
 	lw.mu.Lock()
-	lw.fileOffsets[path] = newOffset
+	old := lw.paths
+	lw.paths = paths
+	for path := range lw.fileOffsets {
+		if !matchesAny(paths, path) {
+			delete(lw.fileOffsets, path)
+			_ = lw.watcher.Remove(path)
+		}
+	}
 	lw.mu.Unlock()
+	
+	for _, pattern := range paths {
+		if matchesAny(old, pattern) {
+			continue
+		}
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			lw.mu.Lock()
+			_, watched := lw.fileOffsets[match]
+			lw.mu.Unlock()
+			if watched {
+				continue
+			}
+			if err := lw.addFile(match, false); err != nil {
+				return err
+			}
+		}
+		
+		dir := filepath.Dir(pattern)
+		if err := lw.watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch directory %s: %w", dir, err)
+		}
+	}
+	return nil
 }
 
-// handleFileCreate handles newly created files
-func (lw *LogWatcher) handleFileCreate(path string) {
-	// Check if this file matches any of our patterns
-	for _, pattern := range lw.paths {
-		matched, err := filepath.Match(pattern, path)
-		if err == nil && matched {
-			_ = lw.addFile(path)
-			break
+// SetMetadata replaces the static fields added to the entries of files
+// while running. Entries already read keep theirs.
+func (lw *LogWatcher) SetMetadata(metadata []LogMetadata) error {
+	if err := validMetadata(metadata); err != nil {
+		return err
+	}
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	lw.metadata = metadata
+	return nil
+}
+
+// validMetadata checks the path patterns of metadata
+func validMetadata(metadata []LogMetadata) error {
+	for _, m := range metadata {
+		if _, err := filepath.Match(m.Pattern, ""); err != nil {
+			return fmt.Errorf("invalid metadata path pattern %s: %w", m.Pattern, err)
 		}
 	}
+	return nil
 }
 
-// parseLine parses a log line into a LogEntry
-func (lw *LogWatcher) parseLine(line string, source string) LogEntry {
-	// Basic parsing - could be enhanced with structured log parsing
-	return LogEntry{
-		Timestamp: time.Now(),
-		Level:     "INFO",
-		Source:    source,
-		Message:   line,
-		Fields:    make(map[string]interface{}),
+// parse turns the lines read from a file into entries, with the metadata of
+// the file added to their fields
+func (lw *LogWatcher) parse(path string, lines []string) []LogEntry {
+	entries := parseLines(lw.format, path, lines, lw.clock.Now())
+
+	lw.mu.Lock()
+	var fields map[string]string
+	for _, m := range lw.metadata {
+		if !matchesAny([]string{m.Pattern}, path) {
+			continue
+		}
+		if fields == nil {
+			fields = make(map[string]string)
+		}
+		for key, value := range m.Fields {
+			fields[key] = value
+		}
 	}
+	lw.mu.Unlock()
+
+	for _, entry := range entries {
+		for key, value := range fields {
+			if _, ok := entry.Fields[key]; !ok {
+				entry.Fields[key] = value
+			}
+		}
+	}
+	return entries
+}
+
+// matchesAny reports whether path matches one of the patterns
+func matchesAny(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if pattern == path {
+			return true
+		}
+		if matched, err := filepath.Match(pattern, path); err == nil && matched {
+			return true
+		}
+	}
+	return false
 }
 
 // ShellHistoryWatcher monitors shell history
@@ -235,12 +584,20 @@ type ShellHistoryWatcher struct {
 	ctx         context.Context
 	cancelFunc  context.CancelFunc
 	wg          sync.WaitGroup
+	stopOnce    sync.Once
 	lastOffset  int64
 	mu          sync.Mutex
+	clock       clock.Clock
 }
 
 // NewShellHistoryWatcher creates a new shell history watcher
 func NewShellHistoryWatcher(historyFile string, bufferSize int) (*ShellHistoryWatcher, error) {
+	return NewShellHistoryWatcherWithClock(historyFile, bufferSize, clock.Real)
+}
+
+// NewShellHistoryWatcherWithClock creates a shell history watcher that
+// polls the history file on the ticks of clk
+func NewShellHistoryWatcherWithClock(historyFile string, bufferSize int, clk clock.Clock) (*ShellHistoryWatcher, error) {
 	if bufferSize <= 0 {
 		bufferSize = 100
 	}
@@ -260,6 +617,7 @@ func NewShellHistoryWatcher(historyFile string, bufferSize int) (*ShellHistoryWa
 		ctx:         ctx,
 		cancelFunc:  cancel,
 		lastOffset:  offset,
+		clock:       clk,
 	}, nil
 }
 
@@ -270,11 +628,14 @@ func (shw *ShellHistoryWatcher) Start() error {
 	return nil
 }
 
-// Stop stops the shell history watcher
+// Stop stops the shell history watcher and closes the entries channel.
+// Calling Stop again has no effect.
 func (shw *ShellHistoryWatcher) Stop() error {
-	shw.cancelFunc()
-	shw.wg.Wait()
-	close(shw.entries)
+	shw.stopOnce.Do(func() {
+		shw.cancelFunc()
+		shw.wg.Wait()
+		close(shw.entries)
+	})
 	return nil
 }
 
@@ -287,12 +648,12 @@ func (shw *ShellHistoryWatcher) Entries() <-chan string {
 func (shw *ShellHistoryWatcher) monitor() {
 	defer shw.wg.Done()
 	
-	ticker := time.NewTicker(1 * time.Second)
+	ticker := shw.clock.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 	
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			shw.readNewEntries()
 		case <-shw.ctx.Done():
 			return
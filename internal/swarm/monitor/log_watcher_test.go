@@ -0,0 +1,306 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+func TestShutdownWhileWriting(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(logFile, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A small buffer keeps the watcher dropping entries
+	lw, err := NewLogWatcher(LogWatcherConfig{Paths: []string{filepath.Join(dir, "*.log")}, BufferSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lw.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		f, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer f.Close()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			fmt.Fprintf(f, "line %d\n", i)
+			if i%100 == 0 {
+				// New files are picked up while running
+				_ = os.WriteFile(filepath.Join(dir, fmt.Sprintf("new-%d.log", i)), []byte("x\n"), 0o644)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			_ = lw.SetPaths([]string{filepath.Join(dir, fmt.Sprintf("%s*.log", []string{"", "app", "new"}[i%3]))})
+		}
+	}()
+
+	// Read a few entries, then stop while the writer keeps going
+	for i := 0; i < 5; i++ {
+		select {
+		case <-lw.Entries():
+		case <-time.After(5 * time.Second):
+			t.Fatal("no log entries")
+		}
+	}
+	var stops sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		stops.Add(1)
+		go func() {
+			defer stops.Done()
+			if err := lw.Stop(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	stops.Wait()
+	close(stop)
+	wg.Wait()
+
+	for range lw.Entries() {
+	}
+}
+
+func TestSheddingLowSeverityFirst(t *testing.T) {
+	lw, err := NewLogWatcher(LogWatcherConfig{BufferSize: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lw.Stop()
+
+	var entries []LogEntry
+	for i, level := range []string{"INFO", "ERROR", "debug", "WARN", "ERROR", "INFO", "FATAL"} {
+		entries = append(entries, LogEntry{Level: level, Message: fmt.Sprint(i)})
+	}
+	lw.bufferEntries(entries)
+
+	var kept []string
+	for _, entry := range lw.buffer {
+		kept = append(kept, entry.Message)
+	}
+	// The info and debug entries go first, then the entry that does not
+	// fit in a buffer of errors, then the oldest error
+	if want := []string{"3", "4", "6"}; fmt.Sprint(kept) != fmt.Sprint(want) {
+		t.Errorf("buffered %v, want %v", kept, want)
+	}
+	stats := lw.DropStats()
+	if stats.Read != 7 || stats.Dropped != 4 || stats.Buffered != 3 {
+		t.Errorf("DropStats() = %+v", stats)
+	}
+	if want := map[string]int64{"INFO": 2, "debug": 1, "ERROR": 1}; fmt.Sprint(stats.DroppedByLevel) != fmt.Sprint(want) {
+		t.Errorf("DroppedByLevel = %v, want %v", stats.DroppedByLevel, want)
+	}
+}
+
+func TestCoalescingWrites(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(logFile, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fake := clock.NewFake(time.Unix(0, 0))
+	lw, err := NewLogWatcher(LogWatcherConfig{Paths: []string{logFile}, Clock: fake, Debounce: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lw.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer lw.Stop()
+
+	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for i := 0; i < 3; i++ {
+		fmt.Fprintf(f, "line %d\n", i)
+	}
+
+	// The first write starts the debounce and nothing is read before it
+	fake.BlockUntil(1)
+	select {
+	case entry := <-lw.Entries():
+		t.Fatalf("read %q before the debounce", entry.Message)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	fake.Advance(50 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		select {
+		case entry := <-lw.Entries():
+			if want := fmt.Sprintf("line %d", i); entry.Message != want {
+				t.Errorf("entry %d = %q, want %q", i, entry.Message, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("writes not read after the debounce")
+		}
+	}
+}
+
+func TestMappedReadMatchesScanner(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	var data []byte
+	for i := 0; i < 3*mappedBatchLines; i++ {
+		data = fmt.Appendf(data, "line %d\r\n", i)
+		if i%7 == 0 {
+			// Stack traces cross the batches of mapped reads
+			data = append(data, "\tat frame\n\n"...)
+		}
+	}
+	data = append(data, "partial"...)
+	if err := os.WriteFile(logFile, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(threshold int64) []LogEntry {
+		lw, err := NewLogWatcher(LogWatcherConfig{BufferSize: len(data), ParseFormat: FormatMultiline, MmapThreshold: threshold})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer lw.Stop()
+		if offset := lw.readFile(logFile, 0); offset != int64(len(data)) {
+			t.Fatalf("read up to %d, want %d", offset, len(data))
+		}
+		return lw.buffer
+	}
+	scanned, mapped := read(int64(len(data))+1), read(1)
+	if len(mapped) != len(scanned) {
+		t.Fatalf("mapped %d entries, scanned %d", len(mapped), len(scanned))
+	}
+	for i := range scanned {
+		if mapped[i].Message != scanned[i].Message {
+			t.Fatalf("entry %d mapped as %q, scanned as %q", i, mapped[i].Message, scanned[i].Message)
+		}
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(logFile, []byte("old\nolder\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lw, err := NewLogWatcher(LogWatcherConfig{Paths: []string{logFile}, Backfill: true, MmapThreshold: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lw.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer lw.Stop()
+
+	for _, want := range []string{"old", "older"} {
+		select {
+		case entry := <-lw.Entries():
+			if entry.Message != want {
+				t.Errorf("backfilled %q, want %q", entry.Message, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("existing lines not backfilled")
+		}
+	}
+}
+
+func TestLogMetadata(t *testing.T) {
+	dir := t.TempDir()
+	api, worker := filepath.Join(dir, "api.log"), filepath.Join(dir, "worker.log")
+	if err := os.WriteFile(api, []byte("msg=started host=api-1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(worker, []byte("msg=started\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	lw, err := NewLogWatcher(LogWatcherConfig{
+		Paths:       []string{filepath.Join(dir, "*.log")},
+		ParseFormat: FormatLogfmt,
+		Backfill:    true,
+		Metadata: []LogMetadata{
+			{Pattern: filepath.Join(dir, "*.log"), Fields: map[string]string{"environment": "prod", "service": "unknown", "host": "web-1"}},
+			{Pattern: api, Fields: map[string]string{"service": "api"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lw.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer lw.Stop()
+
+	entries := make(map[string]LogEntry)
+	for len(entries) < 2 {
+		select {
+		case entry := <-lw.Entries():
+			entries[entry.Source] = entry
+		case <-time.After(5 * time.Second):
+			t.Fatal("existing lines not backfilled")
+		}
+	}
+	// The later pattern wins, the fields of the line win over both
+	if got := entries[api].Fields; got["service"] != "api" || got["environment"] != "prod" || got["host"] != "api-1" {
+		t.Errorf("api fields = %v", got)
+	}
+	if got := entries[worker].Fields; got["service"] != "unknown" || got["host"] != "web-1" {
+		t.Errorf("worker fields = %v", got)
+	}
+
+	if err := lw.SetMetadata([]LogMetadata{{Pattern: "[", Fields: map[string]string{"service": "x"}}}); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
+
+func TestHistoryShutdown(t *testing.T) {
+	history := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(history, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	shw, err := NewShellHistoryWatcher(history, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shw.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(history, []byte("ls\ncd /tmp\ngo test ./...\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stops sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		stops.Add(1)
+		go func() {
+			defer stops.Done()
+			_ = shw.Stop()
+		}()
+	}
+	stops.Wait()
+	for range shw.Entries() {
+	}
+}
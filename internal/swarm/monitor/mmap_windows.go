@@ -0,0 +1,18 @@
+//go:build windows
+
+package monitor
+
+import (
+	"errors"
+	"os"
+)
+
+// mapFile is not supported on Windows, large files are read like others
+func mapFile(file *os.File, size int64) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
+
+// unmapFile releases a mapping made by mapFile
+func unmapFile(data []byte) error {
+	return nil
+}
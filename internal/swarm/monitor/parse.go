@@ -0,0 +1,222 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Log formats understood by the log watcher
+const (
+	// FormatPlain keeps every line as the message of its own entry
+	FormatPlain = "plain"
+	// FormatJSON reads one JSON object per line
+	FormatJSON = "json"
+	// FormatLogfmt reads key=value pairs, e.g. level=warn msg="disk full"
+	FormatLogfmt = "logfmt"
+	// FormatMultiline appends indented lines, such as stack traces, to the
+	// entry of the line before them
+	FormatMultiline = "multiline"
+)
+
+// Keys that hold the level and message of structured log lines
+var (
+	levelKeys   = []string{"level", "lvl", "severity"}
+	messageKeys = []string{"msg", "message"}
+)
+
+// validFormat reports whether format is a known log format. Empty means
+// plain.
+func validFormat(format string) bool {
+	switch format {
+	case "", FormatPlain, FormatJSON, FormatLogfmt, FormatMultiline:
+		return true
+	}
+	return false
+}
+
+// parseLines turns the lines read from source at now into log entries.
+// Lines that are not valid in a structured format are kept as plain entries.
+func parseLines(format, source string, lines []string, now time.Time) []LogEntry {
+	entries := make([]LogEntry, 0, len(lines))
+	for _, line := range lines {
+		if format == FormatMultiline && len(entries) > 0 && isContinuation(line) {
+			last := &entries[len(entries)-1]
+			last.Message += "\n" + line
+			continue
+		}
+		entries = append(entries, parseLine(format, source, line, now))
+	}
+	return entries
+}
+
+// parseLine parses a single log line read at now. The entry is dated at the
+// time the line logged, when it has one, else at now. The level of lines
+// without one is inferred from their message, see InferLevel.
+func parseLine(format, source, line string, now time.Time) LogEntry {
+	entry := LogEntry{
+		Timestamp:  now,
+		IngestedAt: now,
+		Level:      "INFO",
+		Source:     source,
+		Message:    line,
+		Fields:     make(map[string]interface{}),
+	}
+
+	var fields map[string]interface{}
+	switch format {
+	case FormatJSON:
+		fields = parseJSONFields(line)
+	case FormatLogfmt:
+		fields = parseLogfmtFields(line)
+	}
+	if fields == nil {
+		if logged, ok := parseLineTime(line, now); ok {
+			setTime(&entry, logged)
+		}
+		inferLevel(&entry)
+		return entry
+	}
+
+	level, hasLevel := takeString(fields, levelKeys)
+	if msg, ok := takeString(fields, messageKeys); ok {
+		entry.Message = msg
+	}
+	entry.Fields = fields
+	if logged, ok := fieldTime(fields, now); ok {
+		setTime(&entry, logged)
+	}
+	if hasLevel && level != "" {
+		entry.Level = strings.ToUpper(level)
+	} else {
+		inferLevel(&entry)
+	}
+	return entry
+}
+
+// isContinuation reports whether a line continues the entry before it
+func isContinuation(line string) bool {
+	if line == "" {
+		return false
+	}
+	r := rune(line[0])
+	return unicode.IsSpace(r) || strings.HasPrefix(line, "Caused by:")
+}
+
+// parseJSONFields returns the fields of a line holding a JSON object, or nil
+func parseJSONFields(line string) map[string]interface{} {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(trimmed), &fields); err != nil || fields == nil {
+		return nil
+	}
+	return fields
+}
+
+// parseLogfmtFields returns the key=value pairs of a logfmt line, or nil if
+// it has none. Values may be double quoted with Go escapes; keys without a
+// value are true.
+func parseLogfmtFields(line string) map[string]interface{} {
+	fields := make(map[string]interface{})
+	pairs := 0
+	for i := 0; i < len(line); {
+		// Skip spaces
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+		if i >= len(line) {
+			break
+		}
+
+		start := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' {
+			i++
+		}
+		key := line[start:i]
+		if i >= len(line) || line[i] == ' ' {
+			if key != "" {
+				fields[key] = true
+			}
+			continue
+		}
+
+		// line[i] is '='
+		i++
+		var value string
+		if i < len(line) && line[i] == '"' {
+			end := closingQuote(line, i)
+			if end < 0 {
+				// Unterminated quote, keep the rest as the value
+				value = line[i+1:]
+				i = len(line)
+			} else {
+				raw := line[i : end+1]
+				if unquoted, err := unquote(raw); err == nil {
+					value = unquoted
+				} else {
+					value = raw[1 : len(raw)-1]
+				}
+				i = end + 1
+			}
+		} else {
+			start := i
+			for i < len(line) && line[i] != ' ' {
+				i++
+			}
+			value = line[start:i]
+		}
+		if key != "" {
+			fields[key] = value
+			pairs++
+		}
+	}
+	if pairs == 0 {
+		return nil
+	}
+	return fields
+}
+
+// closingQuote returns the index of the quote closing the one at open, or
+// -1
+func closingQuote(s string, open int) int {
+	for i := open + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote decodes a double quoted logfmt value
+func unquote(s string) (string, error) {
+	var value string
+	if err := json.Unmarshal([]byte(s), &value); err != nil {
+		return "", fmt.Errorf("invalid quoted value: %w", err)
+	}
+	return value, nil
+}
+
+// takeString removes the first of keys present in fields and returns its
+// value as a string
+func takeString(fields map[string]interface{}, keys []string) (string, bool) {
+	for _, key := range keys {
+		value, ok := fields[key]
+		if !ok {
+			continue
+		}
+		delete(fields, key)
+		if s, ok := value.(string); ok {
+			return s, true
+		}
+		return fmt.Sprint(value), true
+	}
+	return "", false
+}
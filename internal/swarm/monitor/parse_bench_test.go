@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/perfbudget"
+)
+
+// quietLines are plain lines without a level or a hint of one, most of
+// what a busy log holds
+var quietLines = []string{
+	"line 1042",
+	"listening on :8080",
+	"GET /api/tasks 200 12ms",
+	"worker 3 picked up job 8812 from the queue",
+}
+
+// noisyLines hint at their level in every way InferLevel looks for
+var noisyLines = []string{
+	"2024/01/02 15:04:05 [ERROR] connection refused",
+	"panic: runtime error: index out of range",
+	`10.0.0.1 - - [02/Jan/2024:15:04:05 +0000] "GET /api HTTP/1.1" 502 173`,
+	"build finished with 0 errors, but the upload failed",
+}
+
+func benchmarkParseLines(b *testing.B, lines []string) {
+	now := time.Now()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parseLine(FormatPlain, "app.log", lines[i%len(lines)], now)
+	}
+}
+
+func BenchmarkParseQuietLine(b *testing.B) {
+	benchmarkParseLines(b, quietLines)
+}
+
+func BenchmarkParseNoisyLine(b *testing.B) {
+	benchmarkParseLines(b, noisyLines)
+}
+
+func TestPerformanceBudgets(t *testing.T) {
+	perfbudget.Enforce(t,
+		perfbudget.Budget{Name: "ParseQuietLine", Bench: BenchmarkParseQuietLine, MaxTime: 5 * time.Microsecond, MaxAllocs: 1},
+		perfbudget.Budget{Name: "ParseNoisyLine", Bench: BenchmarkParseNoisyLine, MaxTime: 100 * time.Microsecond, MaxAllocs: 8},
+	)
+}
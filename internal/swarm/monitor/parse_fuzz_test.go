@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// The fuzz targets run their seeds as part of go test. Fuzz one with e.g.
+//
+//	go test -run='^$' -fuzz=FuzzParseLogfmt ./internal/swarm/monitor/
+
+func FuzzParseJSON(f *testing.F) {
+	for _, seed := range []string{
+		`{"level":"warn","msg":"disk almost full","used":0.93}`,
+		`{"severity":"ERROR","message":"request failed","status":500,"tags":["api"]}`,
+		`{"level":3,"msg":null}`,
+		`{"msg":{"nested":true}}`,
+		`  {"level":"info"}  `,
+		`{"level":"info"`,
+		`[1,2,3]`,
+		`null`,
+		`{}`,
+		``,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		entry := parseLine(FormatJSON, "app.log", line, time.Now())
+		checkEntry(t, entry)
+		if parseJSONFields(line) == nil && entry.Message != line {
+			t.Fatalf("message %q, want the unstructured line %q", entry.Message, line)
+		}
+	})
+}
+
+func FuzzParseLogfmt(f *testing.F) {
+	for _, seed := range []string{
+		`level=warn msg="disk almost full" used=0.93`,
+		`ts=2024-01-02T15:04:05Z lvl=error msg="quoted \"value\" here" retry`,
+		`msg="unterminated`,
+		`msg="bad escape \q"`,
+		`=value key=`,
+		`key==value "=" ==`,
+		`plain text without pairs`,
+		`   `,
+		``,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, line string) {
+		entry := parseLine(FormatLogfmt, "app.log", line, time.Now())
+		checkEntry(t, entry)
+		if parseLogfmtFields(line) == nil && entry.Message != line {
+			t.Fatalf("message %q, want the unstructured line %q", entry.Message, line)
+		}
+	})
+}
+
+func FuzzParseMultiline(f *testing.F) {
+	for _, seed := range []string{
+		"panic: runtime error\n\tgoroutine 1 [running]:\n\tmain.main()\nnext entry",
+		"Exception in thread \"main\" java.lang.NullPointerException\n    at Main.main(Main.java:3)\nCaused by: java.io.IOException\n    ... 1 more",
+		"\tleading continuation\nfirst",
+		"\n\n \n",
+		"",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, text string) {
+		lines := strings.Split(text, "\n")
+		entries := parseLines(FormatMultiline, "app.log", lines, time.Now())
+		if len(entries) > len(lines) {
+			t.Fatalf("%d entries from %d lines", len(entries), len(lines))
+		}
+
+		// Joining entries must give back every line, in order
+		messages := make([]string, len(entries))
+		for i, entry := range entries {
+			checkEntry(t, entry)
+			messages[i] = entry.Message
+		}
+		if joined := strings.Join(messages, "\n"); joined != text {
+			t.Fatalf("entries %q do not add up to %q", messages, text)
+		}
+	})
+}
+
+// checkEntry verifies the invariants of every parsed entry
+func checkEntry(t *testing.T, entry LogEntry) {
+	t.Helper()
+	if entry.Source != "app.log" {
+		t.Fatalf("source = %q", entry.Source)
+	}
+	if entry.Level == "" {
+		t.Fatal("empty level")
+	}
+	if entry.Fields == nil {
+		t.Fatal("nil fields")
+	}
+	if entry.Timestamp.IsZero() {
+		t.Fatal("zero timestamp")
+	}
+	if entry.Timestamp.After(entry.IngestedAt.Add(maxClockSkew)) {
+		t.Fatalf("timestamp %v is too far after the ingest time %v", entry.Timestamp, entry.IngestedAt)
+	}
+	for _, keys := range [][]string{levelKeys, messageKeys} {
+		for _, key := range keys {
+			if _, ok := entry.Fields[key]; ok {
+				t.Fatalf("field %q was not taken out of the fields", key)
+			}
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package monitor
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Fields of the entries whose level was inferred from their message, as
+// they had none of their own
+const (
+	// FieldLevelInferred is true on entries with an inferred level
+	FieldLevelInferred = "level_inferred"
+	// FieldLevelConfidence is how sure the inference is, from 0 to 1
+	FieldLevelConfidence = "level_confidence"
+)
+
+// severityRule infers a level from messages matching pattern. Rules without
+// a level hide what they match from the others.
+type severityRule struct {
+	pattern    string
+	level      string
+	confidence float64
+}
+
+// crashHeader infers a level from the lines starting with prefix
+type crashHeader struct {
+	prefix     string
+	level      string
+	confidence float64
+}
+
+var (
+	// levelToken matches a level written near the start of a line, e.g.
+	// "2024/01/02 15:04:05 [ERROR] failed" or "WARN: disk almost full"
+	levelToken = regexp.MustCompile(`^[\[(<]?(?i:(trace|debug|info|notice|warn|warning|error|err|crit|critical|fatal|panic))[\])>:]?$`)
+
+	// crashHeaders are the headers of crashes, which beat level tokens.
+	// The first that a line starts with wins.
+	crashHeaders = []crashHeader{
+		{"panic:", "PANIC", 0.95},
+		{"fatal error:", "PANIC", 0.95},
+		{"Traceback (most recent call last):", "ERROR", 0.9},
+		{"Exception in thread ", "ERROR", 0.9},
+		{"Uncaught ", "ERROR", 0.9},
+	}
+
+	// severityRules are tried after the level tokens, the first that
+	// matches wins. Their patterns have no capturing groups.
+	severityRules = []severityRule{
+		// Mentions that tell of no problem, e.g. "0 errors"
+		{`\b(?:no|0|zero|without)\s+(?:errors?|failures?|warnings?)\b`, "", 0},
+		{`\bpanic(?:ked)?\b`, "PANIC", 0.8},
+		{`\bfatal\b`, "FATAL", 0.75},
+		// HTTP 5xx, e.g. `"GET / HTTP/1.1" 502 173` or status=503
+		{`(?:HTTP/\d(?:\.\d)?"?\s+|\bstatus(?:_?code)?["']?\s*[=:]\s*["']?|\bcode\s*[=:]\s*)5\d\d\b`, "ERROR", 0.7},
+		{`\b(?:exception|traceback|segmentation fault|out of memory)\b`, "ERROR", 0.7},
+		{`\b(?:errors?|failed|failure|cannot|unable to)\b`, "ERROR", 0.6},
+		{`\b(?:warn|warning|deprecated)\b`, "WARN", 0.5},
+	}
+
+	// severityPattern matches every severity rule in one pass, the rule
+	// at group i+1 of its matches
+	severityPattern = alternation(`(?i)`, severityRules)
+)
+
+func alternation(flags string, rules []severityRule) *regexp.Regexp {
+	groups := make([]string, len(rules))
+	for i, rule := range rules {
+		groups[i] = "(" + rule.pattern + ")"
+	}
+	return regexp.MustCompile(flags + strings.Join(groups, "|"))
+}
+
+// crashLevel finds the first of the crashHeaders a line of message starts
+// with
+func crashLevel(message string) (crashHeader, bool) {
+	best := len(crashHeaders)
+	for rest := message; rest != ""; {
+		var line string
+		line, rest, _ = strings.Cut(rest, "\n")
+		for i, header := range crashHeaders[:best] {
+			if strings.HasPrefix(line, header.prefix) {
+				best = i
+				break
+			}
+		}
+	}
+	if best == len(crashHeaders) {
+		return crashHeader{}, false
+	}
+	return crashHeaders[best], true
+}
+
+// severityLevel finds the first of the severityRules that matches text
+func severityLevel(text string) (severityRule, bool) {
+	best := len(severityRules)
+	for _, m := range severityPattern.FindAllStringSubmatchIndex(text, -1) {
+		for i := range severityRules {
+			if m[2*i+2] >= 0 {
+				if severityRules[i].level != "" {
+					best = min(best, i)
+				}
+				break
+			}
+		}
+	}
+	if best == len(severityRules) {
+		return severityRule{}, false
+	}
+	return severityRules[best], true
+}
+
+// levelHints are words, in lower case, that every message some rule or
+// level token matches has, by their first letter. Most lines have none and
+// skip the patterns.
+var levelHints = hintsByLetter(
+	"err", "warn", "fatal", "panic", "fail", "crit", "trace", "debug", "info",
+	"notice", "exception", "uncaught", "segmentation fault", "out of memory",
+	"cannot", "unable to", "deprecated", "http/", "status", "code",
+)
+
+func hintsByLetter(hints ...string) (byLetter [26][]string) {
+	for _, hint := range hints {
+		byLetter[hint[0]-'a'] = append(byLetter[hint[0]-'a'], hint)
+	}
+	return byLetter
+}
+
+// mentionsLevel reports whether message has one of the levelHints, in any
+// case, without allocating
+func mentionsLevel(message string) bool {
+	for i := 0; i < len(message); i++ {
+		c := message[i] | 0x20 // lower case of ASCII letters
+		if c < 'a' || c > 'z' {
+			continue
+		}
+		for _, hint := range levelHints[c-'a'] {
+			if len(message)-i >= len(hint) && strings.EqualFold(message[i:i+len(hint)], hint) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// levelAliases normalizes the level tokens of plain lines
+var levelAliases = map[string]string{
+	"WARNING": "WARN",
+	"ERR":     "ERROR",
+	"CRIT":    "CRITICAL",
+}
+
+// InferLevel guesses the level of a message logged without one: the header
+// of a crash, a level written near the start of its first line, else
+// heuristics for HTTP 5xx responses and words like "error", "panic" or
+// "warn". It returns false when nothing in the message hints at a level.
+func InferLevel(message string) (level string, confidence float64, ok bool) {
+	if !mentionsLevel(message) {
+		return "", 0, false
+	}
+	if header, ok := crashLevel(message); ok {
+		return header.level, header.confidence, true
+	}
+
+	first, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	fields := strings.Fields(first)
+	for _, field := range fields[:min(len(fields), 4)] {
+		// Bare words only count in capitals, "error" may start a sentence
+		if m := levelToken.FindStringSubmatch(field); m != nil && (field != m[1] || field == strings.ToUpper(field)) {
+			level = strings.ToUpper(m[1])
+			if alias, ok := levelAliases[level]; ok {
+				level = alias
+			}
+			return level, 0.9, true
+		}
+	}
+
+	if rule, ok := severityLevel(message); ok {
+		return rule.level, rule.confidence, true
+	}
+	return "", 0, false
+}
+
+// inferLevel sets the level of an entry logged without one from its
+// message, and marks it as inferred in its fields
+func inferLevel(entry *LogEntry) {
+	level, confidence, ok := InferLevel(entry.Message)
+	if !ok {
+		return
+	}
+	entry.Level = level
+	entry.Fields[FieldLevelInferred] = true
+	entry.Fields[FieldLevelConfidence] = confidence
+}
@@ -0,0 +1,61 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInferLevel(t *testing.T) {
+	for _, tt := range []struct {
+		message    string
+		level      string
+		confidence float64
+	}{
+		{"2024/01/02 15:04:05 [ERROR] connection refused", "ERROR", 0.9},
+		{"WARNING: disk almost full", "WARN", 0.9},
+		{"INFO retrying after an error", "INFO", 0.9},
+		{"panic: runtime error: index out of range\n\tgoroutine 1 [running]:", "PANIC", 0.95},
+		{"Traceback (most recent call last):", "ERROR", 0.9},
+		{"worker panicked while handling job 12", "PANIC", 0.8},
+		{`10.0.0.1 - - [02/Jan/2024:15:04:05 +0000] "GET /api HTTP/1.1" 502 173`, "ERROR", 0.7},
+		{"request done status=503 path=/api", "ERROR", 0.7},
+		{"Error connecting to the database", "ERROR", 0.6},
+		{"the config key old_name is deprecated", "WARN", 0.5},
+		{"cache warning, then the worker panicked", "PANIC", 0.8},
+		{"no errors in the batch, but the upload failed", "ERROR", 0.6},
+		{"build finished with 0 errors and no warnings", "", 0},
+		{`"GET /api HTTP/1.1" 200 173`, "", 0},
+		{"listening on :8080", "", 0},
+	} {
+		level, confidence, ok := InferLevel(tt.message)
+		if level != tt.level || confidence != tt.confidence || ok != (tt.level != "") {
+			t.Errorf("InferLevel(%q) = %q, %v, %v, want %q, %v", tt.message, level, confidence, ok, tt.level, tt.confidence)
+		}
+	}
+}
+
+func TestParseLineInfersLevel(t *testing.T) {
+	now := time.Now()
+
+	entry := parseLine(FormatPlain, "app.log", "upstream failed with status=500", now)
+	if entry.Level != "ERROR" || entry.Fields[FieldLevelInferred] != true || entry.Fields[FieldLevelConfidence] != 0.7 {
+		t.Errorf("plain entry = %+v, want an inferred error", entry)
+	}
+
+	// Structured lines without a level are inferred from their message
+	entry = parseLine(FormatJSON, "app.log", `{"msg":"cache warning: evicting","size":12}`, now)
+	if entry.Level != "WARN" || entry.Fields[FieldLevelInferred] != true || entry.Fields["size"] != 12.0 {
+		t.Errorf("JSON entry = %+v, want an inferred warning", entry)
+	}
+
+	// An explicit level is kept, however the message reads
+	entry = parseLine(FormatLogfmt, "app.log", `level=info msg="0 errors, 1 failure retried"`, now)
+	if entry.Level != "INFO" || entry.Fields[FieldLevelInferred] != nil {
+		t.Errorf("logfmt entry = %+v, want its own level", entry)
+	}
+
+	entry = parseLine(FormatPlain, "app.log", "listening on :8080", now)
+	if entry.Level != "INFO" || len(entry.Fields) != 0 {
+		t.Errorf("plain entry = %+v, want INFO without inference", entry)
+	}
+}
@@ -0,0 +1,148 @@
+package monitor
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxClockSkew is how far in the future of the time it was read a
+	// logged time may be. Later times come from clocks running ahead and
+	// are replaced by the time the line was read.
+	maxClockSkew = 5 * time.Minute
+	// FieldClockSkew holds how many seconds a logged time was ahead of the
+	// time its line was read, on entries whose time was replaced
+	FieldClockSkew = "clock_skew"
+	// timestampSearch bounds the part of a line searched for a timestamp
+	timestampSearch = 128
+)
+
+// timeKeys hold the time of structured log lines
+var timeKeys = []string{"time", "ts", "timestamp", "@timestamp"}
+
+// timeLayout parses the timestamps pattern finds in a line with the first
+// of layouts that fits
+type timeLayout struct {
+	pattern *regexp.Regexp
+	layouts []string
+	// noYear layouts take the year of the time the line was read
+	noYear bool
+}
+
+var timeLayouts = []timeLayout{
+	// RFC 3339 and its relatives, e.g. 2024-01-02T15:04:05.123Z or
+	// 2024-01-02 15:04:05,123, optionally after a syslog priority
+	{
+		pattern: regexp.MustCompile(`^(?:<\d{1,3}>\d? ?)?\[?(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)`),
+		layouts: []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999Z0700", "2006-01-02 15:04:05.999999999Z07:00", "2006-01-02 15:04:05.999999999Z0700", "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999"},
+	},
+	// Go's log package, e.g. 2024/01/02 15:04:05
+	{
+		pattern: regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?)`),
+		layouts: []string{"2006/01/02 15:04:05.999999999"},
+	},
+	// Syslog, e.g. Jan  2 15:04:05
+	{
+		pattern: regexp.MustCompile(`^(?:<\d{1,3}>)?([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2})`),
+		layouts: []string{time.Stamp},
+		noYear:  true,
+	},
+	// Apache and nginx access logs, e.g. [02/Jan/2024:15:04:05 -0700]
+	{
+		pattern: regexp.MustCompile(`\[(\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4})\]`),
+		layouts: []string{"02/Jan/2006:15:04:05 -0700"},
+	},
+}
+
+// parseLineTime returns the time logged at the start of a plain line, or,
+// for access logs, in its beginning. Times without a zone are local.
+func parseLineTime(line string, now time.Time) (time.Time, bool) {
+	if len(line) > timestampSearch {
+		line = line[:timestampSearch]
+	}
+	for _, l := range timeLayouts {
+		m := l.pattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		value := strings.Replace(m[1], ",", ".", 1)
+		for _, layout := range l.layouts {
+			t, err := time.ParseInLocation(layout, value, time.Local)
+			if err != nil {
+				continue
+			}
+			if l.noYear {
+				t = withYear(t, now)
+			}
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// withYear dates a time logged without a year in the year it was read, or
+// the year before if that puts it in the future, e.g. a December line read
+// in January
+func withYear(t, now time.Time) time.Time {
+	dated := time.Date(now.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if dated.After(now.Add(maxClockSkew)) {
+		dated = dated.AddDate(-1, 0, 0)
+	}
+	return dated
+}
+
+// fieldTime returns the time held by a field of a structured line: a
+// timestamp such as RFC 3339, or a Unix time in seconds or milliseconds
+func fieldTime(fields map[string]interface{}, now time.Time) (time.Time, bool) {
+	for _, key := range timeKeys {
+		switch value := fields[key].(type) {
+		case string:
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				return t, true
+			}
+			if t, ok := parseLineTime(value, now); ok {
+				return t, true
+			}
+			if n, err := strconv.ParseFloat(value, 64); err == nil {
+				if t, ok := unixTime(n); ok {
+					return t, true
+				}
+			}
+		case float64:
+			if t, ok := unixTime(value); ok {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// unixTime reads a Unix time in seconds or milliseconds, telling them
+// apart by size. Numbers of neither size are no times.
+func unixTime(n float64) (time.Time, bool) {
+	switch {
+	case n >= 1e9 && n < 1e11:
+		sec, frac := math.Modf(n)
+		return time.Unix(int64(sec), int64(frac*1e9)), true
+	case n >= 1e12 && n < 1e14:
+		return time.UnixMilli(int64(n)), true
+	}
+	return time.Time{}, false
+}
+
+// setTime dates an entry at the time it logged, unless that is further in
+// the future than maxClockSkew, in which case it keeps the time it was read
+// and records the skew. Times before the Unix epoch are no real log times.
+func setTime(entry *LogEntry, logged time.Time) {
+	if logged.Year() < 1970 {
+		return
+	}
+	if skew := logged.Sub(entry.IngestedAt); skew > maxClockSkew {
+		entry.Fields[FieldClockSkew] = skew.Seconds()
+		return
+	}
+	entry.Timestamp = logged
+}
@@ -0,0 +1,82 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLineTime(t *testing.T) {
+	now := time.Date(2026, time.January, 3, 12, 0, 0, 0, time.UTC)
+	local := func(year int, month time.Month, day, hour, min, sec, nsec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, nsec, time.Local)
+	}
+
+	for _, tt := range []struct {
+		line string
+		want time.Time
+	}{
+		{"2025-12-30T10:11:12.5Z starting", time.Date(2025, time.December, 30, 10, 11, 12, 5e8, time.UTC)},
+		{"2025-12-30T10:11:12+02:00 starting", time.Date(2025, time.December, 30, 8, 11, 12, 0, time.UTC)},
+		{"[2025-12-30 10:11:12,250] ERROR failed", local(2025, time.December, 30, 10, 11, 12, 25e7)},
+		{"<34>1 2025-12-30T10:11:12Z host app - - - started", time.Date(2025, time.December, 30, 10, 11, 12, 0, time.UTC)},
+		{"2025/12/30 10:11:12 listening", local(2025, time.December, 30, 10, 11, 12, 0)},
+		{"Jan  2 08:00:00 host sshd[1]: accepted", local(2026, time.January, 2, 8, 0, 0, 0)},
+		// Syslog has no year, December is last year's when read in January
+		{"Dec 31 23:59:59 host cron[2]: job", local(2025, time.December, 31, 23, 59, 59, 0)},
+		{`10.0.0.1 - - [02/Jan/2026:15:04:05 -0700] "GET / HTTP/1.1" 200 5`, time.Date(2026, time.January, 2, 22, 4, 5, 0, time.UTC)},
+	} {
+		got, ok := parseLineTime(tt.line, now)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("parseLineTime(%q) = %v, %v, want %v", tt.line, got, ok, tt.want)
+		}
+	}
+
+	for _, line := range []string{"listening on :8080", "took 2025 ms", "version 2025-12-30"} {
+		if got, ok := parseLineTime(line, now); ok {
+			t.Errorf("parseLineTime(%q) = %v, want no time", line, got)
+		}
+	}
+}
+
+func TestParseLineTimestamp(t *testing.T) {
+	now := time.Date(2026, time.January, 3, 12, 0, 0, 0, time.UTC)
+	logged := time.Date(2026, time.January, 3, 11, 0, 0, 0, time.UTC)
+
+	entry := parseLine(FormatPlain, "app.log", "2026-01-03T11:00:00Z ERROR failed", now)
+	if !entry.Timestamp.Equal(logged) || !entry.IngestedAt.Equal(now) {
+		t.Errorf("plain entry dated %v, ingested %v, want %v and %v", entry.Timestamp, entry.IngestedAt, logged, now)
+	}
+
+	for _, line := range []string{
+		`{"time":"2026-01-03T11:00:00Z","msg":"done"}`,
+		`{"ts":1767438000,"msg":"done"}`,
+		`{"@timestamp":1767438000000,"msg":"done"}`,
+	} {
+		entry = parseLine(FormatJSON, "app.log", line, now)
+		if !entry.Timestamp.Equal(logged) {
+			t.Errorf("JSON entry %s dated %v, want %v", line, entry.Timestamp, logged)
+		}
+	}
+
+	entry = parseLine(FormatLogfmt, "app.log", `ts=2026-01-03T11:00:00Z level=info msg=done`, now)
+	if !entry.Timestamp.Equal(logged) || entry.Fields["ts"] != "2026-01-03T11:00:00Z" {
+		t.Errorf("logfmt entry = %+v, want it dated %v", entry, logged)
+	}
+
+	// Clocks running ahead are not trusted
+	entry = parseLine(FormatPlain, "app.log", "2026-01-03T13:00:00Z started", now)
+	if !entry.Timestamp.Equal(now) || entry.Fields[FieldClockSkew] != 3600.0 {
+		t.Errorf("skewed entry = %+v, want it dated when read with an hour of skew", entry)
+	}
+
+	// A little skew is tolerated
+	entry = parseLine(FormatPlain, "app.log", "2026-01-03T12:01:00Z started", now)
+	if !entry.Timestamp.Equal(now.Add(time.Minute)) || entry.Fields[FieldClockSkew] != nil {
+		t.Errorf("entry = %+v, want it dated when logged", entry)
+	}
+
+	entry = parseLine(FormatPlain, "app.log", "listening on :8080", now)
+	if !entry.Timestamp.Equal(now) {
+		t.Errorf("entry without time dated %v, want %v", entry.Timestamp, now)
+	}
+}
@@ -0,0 +1,192 @@
+package swarm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+	"github.com/opencode-ai/opencode/internal/swarm/notify"
+)
+
+// newConnectors creates the notification connectors of the configuration,
+// timed by clk unless they have a clock of their own
+func newConnectors(configs []notify.Config, clk clock.Clock) ([]*notify.Connector, error) {
+	connectors := make([]*notify.Connector, 0, len(configs))
+	for _, config := range configs {
+		if config.Clock == nil {
+			config.Clock = clk
+		}
+		connector, err := notify.NewConnector(config)
+		if err != nil {
+			return nil, err
+		}
+		connectors = append(connectors, connector)
+	}
+	return connectors, nil
+}
+
+// NotificationStats returns what the notification connectors did with their
+// messages, by connector name
+func (c *Coordinator) NotificationStats() map[string]notify.Stats {
+	stats := make(map[string]notify.Stats, len(c.notifiers))
+	for _, connector := range c.notifiers {
+		stats[connector.Name()] = connector.Stats()
+	}
+	return stats
+}
+
+// startNotifications starts posting notifications to every connector. It is
+// called with c.mu held.
+func (c *Coordinator) startNotifications() {
+	for _, connector := range c.notifiers {
+		// Subscribe now so no event recorded while starting is missed
+		events := c.Subscribe(c.ctx)
+		c.wg.Add(2)
+		go func() {
+			defer c.wg.Done()
+			connector.Run(c.ctx)
+		}()
+		go c.notify(connector, events)
+	}
+}
+
+// notify hands a connector the alerts and decided votes of the timeline,
+// and a digest every day at its time of day
+func (c *Coordinator) notify(connector *notify.Connector, events <-chan pubsub.Event[TimelineEvent]) {
+	defer c.wg.Done()
+
+	// digests stays nil, and never ready, for connectors without a digest
+	var timer clock.Timer
+	var digests <-chan time.Time
+	if connector.Wants(notify.KindDigest) {
+		timer = c.clock.NewTimer(untilTimeOfDay(c.clock.Now(), connector.DigestAt()))
+		defer timer.Stop()
+		digests = timer.C()
+	}
+	digest := notify.Digest{Since: c.clock.Now()}
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			countDigest(&digest, event.Payload)
+			c.notifyEvent(connector, event.Payload)
+		case now := <-digests:
+			c.sendDigest(connector, digest, now)
+			digest = notify.Digest{Since: now}
+			timer.Reset(untilTimeOfDay(now, connector.DigestAt()))
+		}
+	}
+}
+
+// notifyEvent posts an alert or a decided vote to a connector that wants
+// it. Messages the connector drops are counted in its stats.
+func (c *Coordinator) notifyEvent(connector *notify.Connector, event TimelineEvent) {
+	switch event.Type {
+	case TimelineAlert:
+		severity := detailString(event.Details, "severity")
+		if !connector.WantsAlert(severity) {
+			return
+		}
+		_ = connector.Notify(notify.KindAlert, notify.Alert{
+			Component: event.Subject,
+			Message:   event.Summary,
+			Severity:  severity,
+			Status:    detailString(event.Details, "status"),
+			Time:      event.Timestamp,
+		})
+	case TimelineVoteDecided:
+		if !connector.Wants(notify.KindVote) {
+			return
+		}
+		vetoed, _ := event.Details["vetoed"].(bool)
+		_ = connector.Notify(notify.KindVote, notify.Vote{
+			SessionID: event.Subject,
+			Summary:   event.Summary,
+			Decision:  voteDecision(event.Details),
+			Yes:       detailInt(event.Details, "yes"),
+			No:        detailInt(event.Details, "no"),
+			Vetoed:    vetoed,
+			Time:      event.Timestamp,
+		})
+	}
+}
+
+// voteDecision tells from the details of a decided vote whether it was
+// approved, rejected or expired
+func voteDecision(details map[string]interface{}) string {
+	if expired, _ := details["expired"].(bool); expired {
+		return "expired"
+	}
+	if approved, _ := details["approved"].(bool); approved {
+		return "approved"
+	}
+	return "rejected"
+}
+
+// sendDigest completes a digest with the current state of the swarm and
+// posts it
+func (c *Coordinator) sendDigest(connector *notify.Connector, digest notify.Digest, now time.Time) {
+	state := c.State()
+	digest.Date = now.Format("2006-01-02")
+	digest.Queued = state.Count(TaskStateQueued)
+	digest.Running = state.Count(TaskStateRunning)
+	digest.OpenVotes = len(state.Votes)
+	_ = connector.Notify(notify.KindDigest, digest)
+}
+
+// countDigest counts an event in a digest
+func countDigest(digest *notify.Digest, event TimelineEvent) {
+	switch event.Type {
+	case TimelineTaskFinished:
+		switch TaskState(detailString(event.Details, "state")) {
+		case TaskStateCompleted:
+			digest.Completed++
+		case TaskStateFailed:
+			digest.Failed++
+		case TaskStateCancelled:
+			digest.Cancelled++
+		}
+	case TimelineAlert:
+		digest.Alerts++
+	case TimelineVoteDecided:
+		digest.Votes++
+	}
+}
+
+// untilTimeOfDay returns how long after now the next day reaches a time of
+// day, in the location of now
+func untilTimeOfDay(now time.Time, at time.Duration) time.Duration {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next := midnight.Add(at)
+	if !next.After(now) {
+		next = midnight.AddDate(0, 0, 1).Add(at)
+	}
+	return next.Sub(now)
+}
+
+// detailString returns a detail of an event as a string, empty if it is
+// missing
+func detailString(details map[string]interface{}, key string) string {
+	v, ok := details[key]
+	if !ok || v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// detailInt returns a numeric detail of an event, which is a float64 once
+// the event went through JSON
+func detailInt(details map[string]interface{}, key string) int {
+	switch v := details[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
@@ -0,0 +1,83 @@
+package swarm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+	"github.com/opencode-ai/opencode/internal/swarm/notify"
+)
+
+func TestNotifications(t *testing.T) {
+	texts := make(chan string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct{ Text string }
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		texts <- payload.Text
+	}))
+	defer server.Close()
+
+	start := time.Date(2026, 10, 17, 8, 0, 0, 0, time.Local)
+	clk := clock.NewFake(start)
+	c, err := NewCoordinator(CoordinatorConfig{
+		Clock: clk,
+		Notifications: []notify.Config{{
+			Name:     "ops",
+			Type:     notify.Slack,
+			URL:      server.URL,
+			DigestAt: 9 * time.Hour,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.startNotifications()
+	defer func() {
+		c.cancelFunc()
+		c.wg.Wait()
+	}()
+	next := func() string {
+		t.Helper()
+		select {
+		case text := <-texts:
+			return text
+		case <-time.After(5 * time.Second):
+			t.Fatal("nothing posted")
+			return ""
+		}
+	}
+
+	c.timeline.record(TimelineTaskFinished, "t1", "Task t1 completed", map[string]interface{}{"state": string(TaskStateCompleted)})
+	c.timeline.record(TimelineAlert, "agent-1", "Agent unhealthy", map[string]interface{}{"severity": "warning"})
+	c.timeline.record(TimelineAlert, "memory", "Store full", map[string]interface{}{"severity": "critical"})
+	if text := next(); text != "[critical] memory: Store full" {
+		t.Errorf("alert = %q, want only the critical alert", text)
+	}
+	c.timeline.record(TimelineVoteDecided, "s1", "Task t2 approved", map[string]interface{}{"approved": true, "yes": 2, "no": 1})
+	if text := next(); text != "Vote approved: Task t2 approved (2 yes, 1 no)" {
+		t.Errorf("vote = %q", text)
+	}
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Hour)
+	digest := next()
+	for _, want := range []string{"2026-10-17", "1 completed", "Alerts: 2", "votes decided: 1"} {
+		if !strings.Contains(digest, want) {
+			t.Errorf("digest %q lacks %q", digest, want)
+		}
+	}
+}
+
+func TestUntilTimeOfDay(t *testing.T) {
+	now := time.Date(2026, 10, 17, 10, 30, 0, 0, time.UTC)
+	if d := untilTimeOfDay(now, 12*time.Hour); d != 90*time.Minute {
+		t.Errorf("until noon = %s, want 1h30m", d)
+	}
+	if d := untilTimeOfDay(now, 9*time.Hour); d != 22*time.Hour+30*time.Minute {
+		t.Errorf("until 9:00 = %s, want the next day", d)
+	}
+}
@@ -0,0 +1,375 @@
+// Package notify posts swarm notifications to chat webhooks. A Connector
+// renders alerts, vote summaries and digests with text templates and posts
+// them to a Slack or Discord incoming webhook, at most as often as its rate
+// limit allows.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+const (
+	// DefaultRateLimit is how many messages a connector posts per rate
+	// period, unless configured otherwise
+	DefaultRateLimit = 10
+	// DefaultRatePeriod is the period of the rate limit, unless configured
+	// otherwise
+	DefaultRatePeriod = time.Minute
+	// DefaultMinSeverity is the least severity of the alerts posted,
+	// unless configured otherwise
+	DefaultMinSeverity = "critical"
+
+	// queueSize is how many rendered messages wait to be posted
+	queueSize = 100
+	// postTimeout bounds how long a webhook has to answer
+	postTimeout = 10 * time.Second
+	// discordMaxLength is the most characters Discord takes in a message
+	discordMaxLength = 2000
+)
+
+var (
+	// ErrRateLimited is returned for messages over the rate limit, which
+	// are dropped
+	ErrRateLimited = errors.New("notification rate limit reached")
+	// ErrQueueFull is returned for messages dropped because the webhook
+	// does not keep up
+	ErrQueueFull = errors.New("notification queue full")
+)
+
+// Platform is the chat service a webhook belongs to
+type Platform string
+
+const (
+	Slack   Platform = "slack"
+	Discord Platform = "discord"
+)
+
+// Kind is the kind of a notification
+type Kind string
+
+const (
+	// KindAlert notifies of an alert of the swarm
+	KindAlert Kind = "alert"
+	// KindVote summarizes a vote that was decided
+	KindVote Kind = "vote"
+	// KindDigest is the daily digest of the swarm
+	KindDigest Kind = "digest"
+)
+
+// Kinds are all kinds of notifications
+var Kinds = []Kind{KindAlert, KindVote, KindDigest}
+
+// severities ranks the severities of health alerts and of rule
+// notifications
+var severities = map[string]int{
+	"info":     0,
+	"warn":     1,
+	"warning":  1,
+	"error":    2,
+	"critical": 3,
+}
+
+// DefaultTemplates are the templates of the kinds of notifications a
+// connector has no template for. They are rendered with an Alert, a Vote
+// and a Digest.
+var DefaultTemplates = map[Kind]string{
+	KindAlert: `[{{.Severity}}] {{.Component}}: {{.Message}}`,
+	KindVote:  `Vote {{.Decision}}: {{.Summary}} ({{.Yes}} yes, {{.No}} no{{if .Vetoed}}, vetoed{{end}})`,
+	KindDigest: `Swarm digest for {{.Date}}
+Tasks: {{.Completed}} completed, {{.Failed}} failed, {{.Cancelled}} cancelled
+Now: {{.Queued}} queued, {{.Running}} running, {{.OpenVotes}} open votes
+Alerts: {{.Alerts}}, votes decided: {{.Votes}}`,
+}
+
+// Config configures a connector
+type Config struct {
+	// Name identifies the connector in errors
+	Name string
+	Type Platform
+	// URL is the incoming webhook messages are posted to
+	URL string
+	// Kinds are the kinds of notifications posted, all if empty
+	Kinds []Kind
+	// MinSeverity is the least severity of the alerts posted: info, warn,
+	// warning, error or critical. DefaultMinSeverity if empty.
+	MinSeverity string
+	// DigestAt is the time of day after midnight the digest is posted at
+	DigestAt time.Duration
+	// Templates replace the default templates, by kind
+	Templates map[Kind]string
+	// RateLimit messages are posted per RatePeriod at most, the rest are
+	// dropped. DefaultRateLimit and DefaultRatePeriod if zero.
+	RateLimit  int
+	RatePeriod time.Duration
+	// Client posts the messages, http.DefaultClient if nil
+	Client *http.Client
+	// Clock times the rate limit, the system clock if nil
+	Clock clock.Clock
+}
+
+// Validate checks the configuration
+func (c Config) Validate() error {
+	switch c.Type {
+	case Slack, Discord:
+	default:
+		return fmt.Errorf("unknown type %q, expected %s or %s", c.Type, Slack, Discord)
+	}
+	if c.URL == "" {
+		return errors.New("webhook URL is required")
+	}
+	for _, kind := range c.Kinds {
+		if !slices.Contains(Kinds, kind) {
+			return fmt.Errorf("unknown kind %q", kind)
+		}
+	}
+	if _, ok := severities[c.MinSeverity]; c.MinSeverity != "" && !ok {
+		return fmt.Errorf("unknown severity %q", c.MinSeverity)
+	}
+	if c.DigestAt < 0 || c.DigestAt >= 24*time.Hour {
+		return errors.New("digestAt must be a time of day")
+	}
+	if c.RateLimit < 0 || c.RatePeriod < 0 {
+		return errors.New("rate limit cannot be negative")
+	}
+	for kind, text := range c.Templates {
+		if !slices.Contains(Kinds, kind) {
+			return fmt.Errorf("template of unknown kind %q", kind)
+		}
+		if _, err := template.New(string(kind)).Parse(text); err != nil {
+			return fmt.Errorf("template %s: %w", kind, err)
+		}
+	}
+	return nil
+}
+
+// Alert is what the alert template is rendered with
+type Alert struct {
+	Component string
+	Message   string
+	// Severity is empty for alerts that have none
+	Severity string
+	Status   string
+	Time     time.Time
+}
+
+// Vote is what the vote template is rendered with
+type Vote struct {
+	SessionID string
+	Summary   string
+	// Decision is approved, rejected or expired
+	Decision string
+	Yes      int
+	No       int
+	Vetoed   bool
+	Time     time.Time
+}
+
+// Digest is what the digest template is rendered with. The counts are of
+// the time since the last digest, or since the swarm started, except the
+// current ones of queued and running tasks and open votes.
+type Digest struct {
+	// Date is the day of the digest, as 2006-01-02
+	Date      string
+	Since     time.Time
+	Completed int
+	Failed    int
+	Cancelled int
+	Alerts    int
+	Votes     int
+	Queued    int
+	Running   int
+	OpenVotes int
+}
+
+// Stats counts what a connector did with its messages
+type Stats struct {
+	Sent int `json:"sent"`
+	// Dropped messages were over the rate limit or did not fit the queue
+	Dropped int `json:"dropped"`
+	// Failed messages were not accepted by the webhook
+	Failed int `json:"failed"`
+	// LastError is the error of the last failed message
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Connector posts notifications to a webhook. Notify queues messages and
+// Run posts them.
+type Connector struct {
+	config    Config
+	templates map[Kind]*template.Template
+	queue     chan string
+
+	mu          sync.Mutex
+	windowStart time.Time
+	windowCount int
+	stats       Stats
+}
+
+// NewConnector creates a connector with a valid configuration
+func NewConnector(config Config) (*Connector, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("notification %s: %w", config.Name, err)
+	}
+	if config.MinSeverity == "" {
+		config.MinSeverity = DefaultMinSeverity
+	}
+	if config.RateLimit == 0 {
+		config.RateLimit = DefaultRateLimit
+	}
+	if config.RatePeriod == 0 {
+		config.RatePeriod = DefaultRatePeriod
+	}
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.Clock == nil {
+		config.Clock = clock.Real
+	}
+	templates := make(map[Kind]*template.Template, len(Kinds))
+	for _, kind := range Kinds {
+		text, ok := config.Templates[kind]
+		if !ok {
+			text = DefaultTemplates[kind]
+		}
+		// Validate parsed the configured templates already
+		templates[kind] = template.Must(template.New(string(kind)).Parse(text))
+	}
+	return &Connector{
+		config:    config,
+		templates: templates,
+		queue:     make(chan string, queueSize),
+	}, nil
+}
+
+// Name returns the name of the connector
+func (c *Connector) Name() string {
+	return c.config.Name
+}
+
+// DigestAt returns the time of day the digest is posted at
+func (c *Connector) DigestAt() time.Duration {
+	return c.config.DigestAt
+}
+
+// Wants reports whether the connector posts a kind of notifications
+func (c *Connector) Wants(kind Kind) bool {
+	return len(c.config.Kinds) == 0 || slices.Contains(c.config.Kinds, kind)
+}
+
+// WantsAlert reports whether the connector posts alerts of a severity.
+// Alerts without a known severity count as warnings.
+func (c *Connector) WantsAlert(severity string) bool {
+	rank, ok := severities[strings.ToLower(severity)]
+	if !ok {
+		rank = severities["warning"]
+	}
+	return c.Wants(KindAlert) && rank >= severities[c.config.MinSeverity]
+}
+
+// Notify renders a notification of a kind with its data, an Alert, Vote or
+// Digest, and queues it to be posted. Messages over the rate limit are
+// dropped with ErrRateLimited.
+func (c *Connector) Notify(kind Kind, data interface{}) error {
+	tmpl, ok := c.templates[kind]
+	if !ok {
+		return fmt.Errorf("unknown kind %q", kind)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to render %s notification: %w", kind, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.config.Clock.Now()
+	if now.Sub(c.windowStart) >= c.config.RatePeriod {
+		c.windowStart = now
+		c.windowCount = 0
+	}
+	if c.windowCount >= c.config.RateLimit {
+		c.stats.Dropped++
+		return ErrRateLimited
+	}
+	select {
+	case c.queue <- buf.String():
+		c.windowCount++
+		return nil
+	default:
+		c.stats.Dropped++
+		return ErrQueueFull
+	}
+}
+
+// Run posts the queued messages until the context is done
+func (c *Connector) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case text := <-c.queue:
+			err := c.post(ctx, text)
+			c.mu.Lock()
+			if err != nil {
+				c.stats.Failed++
+				c.stats.LastError = err.Error()
+			} else {
+				c.stats.Sent++
+			}
+			c.mu.Unlock()
+		}
+	}
+}
+
+// Stats returns what the connector did with its messages so far
+func (c *Connector) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// post sends a message to the webhook in the payload of its platform
+func (c *Connector) post(ctx context.Context, text string) error {
+	var payload interface{}
+	switch c.config.Type {
+	case Discord:
+		if runes := []rune(text); len(runes) > discordMaxLength {
+			text = string(runes[:discordMaxLength-1]) + "…"
+		}
+		payload = map[string]string{"content": text}
+	default:
+		payload = map[string]string{"text": text}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, postTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post to %s: %w", c.config.Name, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to %s: %w", c.config.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to post to %s: webhook answered %s", c.config.Name, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+// webhook collects the payloads posted to it
+func webhook(t *testing.T) (*httptest.Server, chan map[string]string) {
+	payloads := make(chan map[string]string, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		payloads <- payload
+	}))
+	t.Cleanup(server.Close)
+	return server, payloads
+}
+
+func receive(t *testing.T, payloads chan map[string]string) map[string]string {
+	t.Helper()
+	select {
+	case payload := <-payloads:
+		return payload
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing posted")
+		return nil
+	}
+}
+
+func TestConnectorPayloads(t *testing.T) {
+	server, payloads := webhook(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	slack, err := NewConnector(Config{Name: "ops", Type: Slack, URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go slack.Run(ctx)
+	if err := slack.Notify(KindAlert, Alert{Component: "memory", Message: "store full", Severity: "critical"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := receive(t, payloads)["text"]; got != "[critical] memory: store full" {
+		t.Errorf("slack text = %q", got)
+	}
+
+	discord, err := NewConnector(Config{
+		Name:      "dev",
+		Type:      Discord,
+		URL:       server.URL,
+		Templates: map[Kind]string{KindVote: "{{.Summary}}: {{.Decision}}"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go discord.Run(ctx)
+	if err := discord.Notify(KindVote, Vote{Summary: "Task t1", Decision: "approved"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := receive(t, payloads)["content"]; got != "Task t1: approved" {
+		t.Errorf("discord content = %q", got)
+	}
+	if err := discord.Notify(KindVote, Vote{Summary: strings.Repeat("x", 3000)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := []rune(receive(t, payloads)["content"]); len(got) != discordMaxLength {
+		t.Errorf("discord content has %d characters, want %d", len(got), discordMaxLength)
+	}
+}
+
+func TestConnectorRateLimit(t *testing.T) {
+	server, payloads := webhook(t)
+	clk := clock.NewFake(time.Now())
+	c, err := NewConnector(Config{Name: "ops", Type: Slack, URL: server.URL, RateLimit: 2, RatePeriod: time.Minute, Clock: clk})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	alert := Alert{Component: "agent-1", Message: "down", Severity: "critical"}
+	for i := 0; i < 2; i++ {
+		if err := c.Notify(KindAlert, alert); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Notify(KindAlert, alert); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("third alert: err = %v, want ErrRateLimited", err)
+	}
+	clk.Advance(time.Minute)
+	if err := c.Notify(KindAlert, alert); err != nil {
+		t.Fatalf("alert of the next period: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		receive(t, payloads)
+	}
+	// Sent is counted once the webhook answered
+	deadline := time.Now().Add(5 * time.Second)
+	for c.Stats().Sent < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stats := c.Stats(); stats.Sent != 3 || stats.Dropped != 1 {
+		t.Errorf("stats = %+v, want 3 sent and 1 dropped", stats)
+	}
+}
+
+func TestConnectorFilters(t *testing.T) {
+	c, err := NewConnector(Config{Name: "ops", Type: Slack, URL: "http://localhost", Kinds: []Kind{KindAlert}, MinSeverity: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for severity, want := range map[string]bool{"critical": true, "error": true, "warning": false, "": false} {
+		if got := c.WantsAlert(severity); got != want {
+			t.Errorf("WantsAlert(%q) = %v, want %v", severity, got, want)
+		}
+	}
+	if c.Wants(KindVote) || c.Wants(KindDigest) {
+		t.Error("connector of alerts wants votes or digests")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{Name: "ops", Type: Slack, URL: "http://localhost"}
+	if err := valid.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for name, config := range map[string]Config{
+		"type":     {Type: "teams", URL: "http://localhost"},
+		"url":      {Type: Slack},
+		"kind":     {Type: Slack, URL: "http://localhost", Kinds: []Kind{"weekly"}},
+		"severity": {Type: Slack, URL: "http://localhost", MinSeverity: "fatal"},
+		"digestAt": {Type: Discord, URL: "http://localhost", DigestAt: 25 * time.Hour},
+		"template": {Type: Slack, URL: "http://localhost", Templates: map[Kind]string{KindAlert: "{{.Message"}},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("%s: invalid config passed", name)
+		}
+	}
+}
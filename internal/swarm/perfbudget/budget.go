@@ -0,0 +1,84 @@
+// Package perfbudget enforces performance budgets on benchmarks so that
+// regressions in the swarm's hot paths fail the test suite instead of going
+// unnoticed.
+//
+// Budgets are checked by ordinary tests that run the benchmarks through
+// testing.Benchmark. Allocation budgets are exact; time budgets are generous
+// because they depend on the machine and can be scaled with
+// SWARM_PERF_BUDGET_SCALE, e.g. 2 on a slow CI runner. The checks are
+// skipped with -short, under the race detector, and when
+// SWARM_PERF_BUDGET_SCALE is 0.
+package perfbudget
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// ScaleEnv multiplies every time budget
+const ScaleEnv = "SWARM_PERF_BUDGET_SCALE"
+
+// Budget bounds the cost of one operation of a benchmark
+type Budget struct {
+	Name  string
+	Bench func(b *testing.B)
+	// MaxTime per operation, zero for no limit
+	MaxTime time.Duration
+	// MaxAllocs per operation, negative for no limit
+	MaxAllocs int64
+}
+
+// Enforce runs every benchmark and fails t for each budget it exceeds
+func Enforce(t *testing.T, budgets ...Budget) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("performance budgets are not checked with -short")
+	}
+	if raceEnabled {
+		t.Skip("performance budgets are not checked under the race detector")
+	}
+	scale, err := timeScale()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scale == 0 {
+		t.Skipf("performance budgets disabled by %s=0", ScaleEnv)
+	}
+
+	for _, budget := range budgets {
+		t.Run(budget.Name, func(t *testing.T) {
+			result := testing.Benchmark(budget.Bench)
+			if result.N == 0 {
+				t.Fatal("benchmark failed")
+			}
+			perOp := time.Duration(result.NsPerOp())
+			allocs := result.AllocsPerOp()
+			t.Logf("%v/op, %d allocs/op", perOp, allocs)
+
+			if budget.MaxTime > 0 {
+				limit := time.Duration(float64(budget.MaxTime) * scale)
+				if perOp > limit {
+					t.Errorf("took %v per operation, budget is %v", perOp, limit)
+				}
+			}
+			if budget.MaxAllocs >= 0 && allocs > budget.MaxAllocs {
+				t.Errorf("made %d allocations per operation, budget is %d", allocs, budget.MaxAllocs)
+			}
+		})
+	}
+}
+
+func timeScale() (float64, error) {
+	v := os.Getenv(ScaleEnv)
+	if v == "" {
+		return 1, nil
+	}
+	scale, err := strconv.ParseFloat(v, 64)
+	if err != nil || scale < 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected a non-negative number", ScaleEnv, v)
+	}
+	return scale, nil
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package perfbudget
+
+const raceEnabled = false
@@ -0,0 +1,219 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+// agentGrants holds the permissions of the agents of the swarm
+type agentGrants struct {
+	mu     sync.RWMutex
+	grants map[string]agent.Grants
+}
+
+func newAgentGrants() *agentGrants {
+	return &agentGrants{grants: make(map[string]agent.Grants)}
+}
+
+// set grants an agent the permissions of its configuration, or those of its
+// role when it lists none
+func (g *agentGrants) set(cfg agent.AgentConfig) {
+	permissions := cfg.Permissions
+	if permissions == nil {
+		permissions = agent.RolePermissions(cfg.Type)
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.grants[cfg.ID] = agent.NewGrants(permissions...)
+}
+
+// remove forgets the permissions of an agent that was removed
+func (g *agentGrants) remove(id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.grants, id)
+}
+
+// of returns the grants of an agent. Agents registered without a
+// configuration have the permissions of their role.
+func (g *agentGrants) of(ag agent.Agent) agent.Grants {
+	g.mu.RLock()
+	grants, ok := g.grants[ag.GetID()]
+	g.mu.RUnlock()
+	if ok {
+		return grants
+	}
+	return agent.NewGrants(agent.RolePermissions(ag.GetType())...)
+}
+
+// permitted returns the agents granted every permission they require for a
+// task, and the first permission an agent was denied
+func (g *agentGrants) permitted(task agent.Task, agents []agent.Agent) ([]agent.Agent, agent.Permission) {
+	return filterAgents(agents, func(ag agent.Agent) agent.Permission {
+		return g.missing(ag, task)
+	})
+}
+
+// missing returns a permission an agent requires for a task but was not
+// granted
+func (g *agentGrants) missing(ag agent.Agent, task agent.Task) agent.Permission {
+	requirer, ok := ag.(agent.PermissionRequirer)
+	if !ok {
+		return ""
+	}
+	grants := g.of(ag)
+	for _, p := range requirer.RequiredPermissions(task) {
+		if !grants.Allows(p) {
+			return p
+		}
+	}
+	return ""
+}
+
+// voters returns the agents that may vote
+func (g *agentGrants) voters(agents []agent.Agent) []agent.Agent {
+	voters, _ := filterAgents(agents, func(ag agent.Agent) agent.Permission {
+		if !g.of(ag).Allows(agent.PermissionCastVotes) {
+			return agent.PermissionCastVotes
+		}
+		return ""
+	})
+	return voters
+}
+
+// filterAgents drops the agents denied a permission, returning the first
+// one. Agents are only copied when some are dropped.
+func filterAgents(agents []agent.Agent, denied func(agent.Agent) agent.Permission) ([]agent.Agent, agent.Permission) {
+	for i, ag := range agents {
+		first := denied(ag)
+		if first == "" {
+			continue
+		}
+		kept := append([]agent.Agent(nil), agents[:i]...)
+		for _, ag := range agents[i+1:] {
+			if denied(ag) == "" {
+				kept = append(kept, ag)
+			}
+		}
+		return kept, first
+	}
+	return agents, ""
+}
+
+// messagePolicy lets agents message the agents they were granted to
+func (c *Coordinator) messagePolicy(msg agent.Message) error {
+	from, err := c.registry.GetAgent(msg.From)
+	if err != nil {
+		// Messages of the coordinator and tools are not agents'
+		return nil
+	}
+	grants := c.grants.of(from)
+	if msg.To == "" {
+		return grants.Check(msg.From, agent.PermissionSendMessages)
+	}
+	var toType agent.AgentType
+	if to, err := c.registry.GetAgent(msg.To); err == nil {
+		toType = to.GetType()
+	}
+	if !grants.CanMessage(msg.To, toType) {
+		return fmt.Errorf("%w: agent %s may not message %s", agent.ErrPermissionDenied, msg.From, msg.To)
+	}
+	return nil
+}
+
+// permittedMemory is the memory store as an agent may use it
+type permittedMemory struct {
+	store   memory.MemoryStore
+	agentID string
+	grants  *agentGrants
+	// promotions count the memories retrieved by tasks, if set
+	promotions *promotions
+}
+
+func (m permittedMemory) check(p agent.Permission) error {
+	m.grants.mu.RLock()
+	grants, ok := m.grants.grants[m.agentID]
+	m.grants.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: agent %s may not %s", agent.ErrPermissionDenied, m.agentID, p)
+	}
+	return grants.Check(m.agentID, p)
+}
+
+func (m permittedMemory) Store(ctx context.Context, mem memory.Memory) error {
+	if err := m.check(agent.PermissionWriteMemory); err != nil {
+		return err
+	}
+	return m.store.Store(ctx, mem)
+}
+
+func (m permittedMemory) Retrieve(ctx context.Context, id string) (*memory.Memory, error) {
+	if err := m.check(agent.PermissionReadMemory); err != nil {
+		return nil, err
+	}
+	mem, err := m.store.Retrieve(ctx, id)
+	if err == nil {
+		m.promotions.retrieved(ctx, mem)
+	}
+	return mem, err
+}
+
+func (m permittedMemory) Update(ctx context.Context, id string, mem memory.Memory) error {
+	if err := m.check(agent.PermissionWriteMemory); err != nil {
+		return err
+	}
+	return m.store.Update(ctx, id, mem)
+}
+
+func (m permittedMemory) Delete(ctx context.Context, id string) error {
+	if err := m.check(agent.PermissionWriteMemory); err != nil {
+		return err
+	}
+	return m.store.Delete(ctx, id)
+}
+
+func (m permittedMemory) Query(ctx context.Context, query memory.MemoryQuery) ([]memory.Memory, error) {
+	if err := m.check(agent.PermissionReadMemory); err != nil {
+		return nil, err
+	}
+	return m.store.Query(ctx, query)
+}
+
+func (m permittedMemory) VectorSearch(ctx context.Context, vector []float64, limit int) ([]memory.Memory, error) {
+	if err := m.check(agent.PermissionReadMemory); err != nil {
+		return nil, err
+	}
+	return m.store.VectorSearch(ctx, vector, limit)
+}
+
+func (m permittedMemory) Consolidate(ctx context.Context) error {
+	if err := m.check(agent.PermissionWriteMemory); err != nil {
+		return err
+	}
+	return m.store.Consolidate(ctx)
+}
+
+func (m permittedMemory) Prune(ctx context.Context, criteria memory.PruneCriteria) error {
+	if err := m.check(agent.PermissionWriteMemory); err != nil {
+		return err
+	}
+	return m.store.Prune(ctx, criteria)
+}
+
+func (m permittedMemory) Pin(ctx context.Context, id string, pinned bool) error {
+	if err := m.check(agent.PermissionWriteMemory); err != nil {
+		return err
+	}
+	return m.store.Pin(ctx, id, pinned)
+}
+
+func (m permittedMemory) GetStats() memory.MemoryStats {
+	if m.check(agent.PermissionReadMemory) != nil {
+		return memory.MemoryStats{}
+	}
+	return m.store.GetStats()
+}
@@ -0,0 +1,70 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+func TestPermissions(t *testing.T) {
+	// Other tests replace the executor
+	agent.RegisterFactory(agent.AgentTypeExecutor, func(config agent.AgentConfig) (agent.Agent, error) {
+		return agent.NewExecutorAgent(config)
+	})
+
+	config := FileConfig{Agents: []AgentFileConfig{
+		{ID: "exec", Type: string(agent.AgentTypeExecutor), Permissions: []string{"read-memory", "rm-rf"}},
+	}}
+	if err := config.Validate(); err == nil || !strings.Contains(err.Error(), "rm-rf") {
+		t.Errorf("Validate() = %v, want the unknown permission named", err)
+	}
+
+	config.Agents[0].Permissions = []string{"read-memory", "cast-votes", "send-messages:slow"}
+	s, err := Open(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	id, err := s.SubmitTask(ctx, Task{Type: agent.TaskTypeCommand, Input: map[string]interface{}{"command": "true"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Result(ctx, id); err == nil || !strings.Contains(err.Error(), "run-commands") {
+		t.Errorf("Result() = %v, want the task failed for lack of run-commands", err)
+	}
+
+	c := s.coordinator
+	messages := []struct {
+		msg     agent.Message
+		allowed bool
+	}{
+		{agent.Message{From: "exec", To: "mem"}, true},
+		{agent.Message{From: "exec", To: "other"}, false},
+		{agent.Message{From: "exec"}, false},
+		{agent.Message{From: "coordinator", To: "other"}, true},
+	}
+	if err := c.GetRegistry().RegisterAgent(newSlowAgent("mem")); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range messages {
+		if err := c.messagePolicy(m.msg); (err == nil) != m.allowed {
+			t.Errorf("message from %s to %q: %v, allowed = %v", m.msg.From, m.msg.To, err, m.allowed)
+		}
+	}
+
+	store := permittedMemory{store: c.GetMemoryStore(), agentID: "exec", grants: c.grants}
+	if _, err := store.Query(ctx, memory.MemoryQuery{}); err != nil {
+		t.Errorf("Query() = %v, want reading allowed", err)
+	}
+	if err := store.Store(ctx, memory.Memory{ID: "m"}); !errors.Is(err, agent.ErrPermissionDenied) {
+		t.Errorf("Store() = %v, want ErrPermissionDenied", err)
+	}
+}
@@ -0,0 +1,87 @@
+package swarm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+)
+
+// progressAgent streams the progress of its tasks and finishes them once
+// released
+type progressAgent struct {
+	*agent.BaseAgent
+	progress chan agent.TaskProgress
+	release  chan struct{}
+}
+
+func (a *progressAgent) Progress() <-chan agent.TaskProgress {
+	return a.progress
+}
+
+func (a *progressAgent) CanHandleTask(task agent.Task) bool {
+	return true
+}
+
+func (a *progressAgent) ExecuteTask(ctx context.Context, task agent.Task) (*agent.TaskResult, error) {
+	a.progress <- agent.TaskProgress{TaskID: task.ID, Percent: 40, Stage: "compiling", Message: "compiling 4 packages"}
+	a.progress <- agent.TaskProgress{TaskID: task.ID, Message: "ok ./internal"}
+	<-a.release
+	return &agent.TaskResult{TaskID: task.ID, Success: true, AgentID: a.GetID(), CompletedAt: time.Now()}, nil
+}
+
+func TestStreamedProgress(t *testing.T) {
+	c, err := NewCoordinator(CoordinatorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ag := &progressAgent{
+		BaseAgent: agent.NewBaseAgent(agent.AgentConfig{ID: "builder", Type: agent.AgentType("build")}),
+		progress:  make(chan agent.TaskProgress),
+		release:   make(chan struct{}),
+	}
+	if err := c.GetRegistry().RegisterAgent(ag); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := c.Subscribe(ctx)
+	task := agent.Task{ID: "build-1", Type: "build"}
+	if err := c.SubmitTask(ctx, task); err != nil {
+		t.Fatal(err)
+	}
+
+	var record TaskRecord
+	for record.Progress.Percent == 0 || len(record.Progress.Log) < 2 {
+		if ctx.Err() != nil {
+			t.Fatalf("progress = %+v, want it recorded", record.Progress)
+		}
+		time.Sleep(time.Millisecond)
+		if record, err = c.GetTask(task.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The log line without a stage keeps the stage before it
+	if p := record.Progress; p.Percent != 40 || p.Stage != "compiling" || p.Log[0] != "compiling 4 packages" || p.Log[1] != "ok ./internal" {
+		t.Errorf("progress = %+v", p)
+	}
+
+	for event := range events {
+		if event.Payload.Type == TimelineTaskProgress {
+			if event.Payload.Details["agent"] != "builder" || event.Payload.Details["stage"] != "compiling" {
+				t.Errorf("timeline details = %v", event.Payload.Details)
+			}
+			break
+		}
+	}
+	close(ag.release)
+	if _, err := c.GetTaskResult(ctx, task.ID); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,285 @@
+package swarm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+// DefaultPromotionSuccessRate is the share of the tasks using a memory that
+// must succeed for it to be queued for promotion unless configured
+// otherwise
+const DefaultPromotionSuccessRate = 0.8
+
+// PromotionConfig queues the memories of a session that helped tasks
+// succeed for promotion to memory.GlobalNamespace, where they outlive the
+// session once approved. A task used a memory when its agent retrieved it
+// while running the task.
+type PromotionConfig struct {
+	// MinUses is how many finished tasks must have used a memory before it
+	// is queued. Zero disables promotion.
+	MinUses int
+	// MinSuccessRate is the share of those tasks that must have succeeded,
+	// DefaultPromotionSuccessRate if zero
+	MinSuccessRate float64
+}
+
+// PromotionCandidate is a memory waiting for a human to approve its
+// promotion to the global namespace
+type PromotionCandidate struct {
+	MemoryID  string
+	Namespace string
+	Type      memory.MemoryType
+	Tags      []string
+	// Preview is the start of the content, empty for encrypted memories
+	Preview string
+	// Uses counts the finished tasks that used the memory, Successes those
+	// that succeeded
+	Uses      int
+	Successes int
+	// AccessCount is how often the memory was retrieved by the time a task
+	// last used it
+	AccessCount int
+	QueuedAt    time.Time
+}
+
+// SuccessRate is the share of the tasks using the memory that succeeded
+func (p PromotionCandidate) SuccessRate() float64 {
+	if p.Uses == 0 {
+		return 0
+	}
+	return float64(p.Successes) / float64(p.Uses)
+}
+
+// maxPreview is how many characters of content candidates keep
+const maxPreview = 200
+
+// memoryUse counts the finished tasks that used a memory
+type memoryUse struct {
+	uses      int
+	successes int
+}
+
+// promotions tracks the memories used by tasks and the promotion queue
+type promotions struct {
+	mu     sync.Mutex
+	config PromotionConfig
+	// running are the memories retrieved by running tasks, by task ID
+	running map[string]map[string]PromotionCandidate
+	uses    map[string]*memoryUse
+	queue   map[string]PromotionCandidate
+	// decided are the memories approved or rejected, never queued again
+	decided map[string]bool
+}
+
+func newPromotions(config PromotionConfig) *promotions {
+	return &promotions{
+		config:  config,
+		running: make(map[string]map[string]PromotionCandidate),
+		uses:    make(map[string]*memoryUse),
+		queue:   make(map[string]PromotionCandidate),
+		decided: make(map[string]bool),
+	}
+}
+
+// setConfig changes the thresholds, for memories used from now on
+func (p *promotions) setConfig(config PromotionConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = config
+}
+
+func (p *promotions) enabled() bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.config.MinUses > 0
+}
+
+type promotionTaskKey struct{}
+
+// withPromotionTask returns a context for running a task whose memory
+// retrievals count towards promotions
+func withPromotionTask(ctx context.Context, taskID string) context.Context {
+	return context.WithValue(ctx, promotionTaskKey{}, taskID)
+}
+
+// retrieved notes that the task running with ctx retrieved a memory.
+// Memories already in the global namespace are not tracked.
+func (p *promotions) retrieved(ctx context.Context, mem *memory.Memory) {
+	taskID, ok := ctx.Value(promotionTaskKey{}).(string)
+	if p == nil || !ok || mem.Namespace == memory.GlobalNamespace {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	used := p.running[taskID]
+	if used == nil {
+		used = make(map[string]PromotionCandidate)
+		p.running[taskID] = used
+	}
+	candidate := PromotionCandidate{
+		MemoryID:    mem.ID,
+		Namespace:   mem.Namespace,
+		Type:        mem.Type,
+		Tags:        mem.Tags,
+		AccessCount: mem.AccessCount,
+	}
+	if !mem.Encrypted {
+		candidate.Preview = contentPreview(mem.Content)
+	}
+	used[mem.ID] = candidate
+}
+
+// contentPreview is the content of a memory on one line, cut at maxPreview
+// characters
+func contentPreview(content interface{}) string {
+	text, ok := content.(string)
+	if !ok {
+		data, err := json.Marshal(content)
+		if err != nil {
+			return ""
+		}
+		text = string(data)
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > maxPreview {
+		text = string(runes[:maxPreview-1]) + "…"
+	}
+	return text
+}
+
+// finished counts the memories a task used towards their promotion and
+// returns those it queued
+func (p *promotions) finished(taskID string, success bool, now time.Time) []PromotionCandidate {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	used, ok := p.running[taskID]
+	if !ok {
+		return nil
+	}
+	delete(p.running, taskID)
+
+	minRate := p.config.MinSuccessRate
+	if minRate == 0 {
+		minRate = DefaultPromotionSuccessRate
+	}
+	var queued []PromotionCandidate
+	for id, candidate := range used {
+		if p.decided[id] {
+			continue
+		}
+		total := p.uses[id]
+		if total == nil {
+			total = &memoryUse{}
+			p.uses[id] = total
+		}
+		total.uses++
+		if success {
+			total.successes++
+		}
+		candidate.Uses = total.uses
+		candidate.Successes = total.successes
+
+		if existing, ok := p.queue[id]; ok {
+			// Keep the queued candidate current
+			candidate.QueuedAt = existing.QueuedAt
+			p.queue[id] = candidate
+			continue
+		}
+		if p.config.MinUses > 0 && candidate.Uses >= p.config.MinUses && candidate.SuccessRate() >= minRate {
+			candidate.QueuedAt = now
+			p.queue[id] = candidate
+			queued = append(queued, candidate)
+		}
+	}
+	sort.Slice(queued, func(i, j int) bool { return queued[i].MemoryID < queued[j].MemoryID })
+	return queued
+}
+
+// decide takes a memory off the queue for good
+func (p *promotions) decide(memoryID string) (PromotionCandidate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	candidate, ok := p.queue[memoryID]
+	if !ok {
+		return candidate, fmt.Errorf("%w: %s", ErrPromotionNotFound, memoryID)
+	}
+	delete(p.queue, memoryID)
+	delete(p.uses, memoryID)
+	p.decided[memoryID] = true
+	return candidate, nil
+}
+
+// PromotionCandidates returns the memories waiting for review, oldest
+// first
+func (c *Coordinator) PromotionCandidates() []PromotionCandidate {
+	c.promotions.mu.Lock()
+	candidates := make([]PromotionCandidate, 0, len(c.promotions.queue))
+	for _, candidate := range c.promotions.queue {
+		candidates = append(candidates, candidate)
+	}
+	c.promotions.mu.Unlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if !a.QueuedAt.Equal(b.QueuedAt) {
+			return a.QueuedAt.Before(b.QueuedAt)
+		}
+		return a.MemoryID < b.MemoryID
+	})
+	return candidates
+}
+
+// ApprovePromotion moves a queued memory to the global namespace
+func (c *Coordinator) ApprovePromotion(memoryID string) error {
+	candidate, err := c.promotions.decide(memoryID)
+	if err != nil {
+		return err
+	}
+	mem, err := c.memoryStore.Retrieve(c.ctx, memoryID)
+	if err != nil {
+		return fmt.Errorf("failed to promote memory: %w", err)
+	}
+	mem.Namespace = memory.GlobalNamespace
+	if err := c.memoryStore.Update(c.ctx, memoryID, *mem); err != nil {
+		return fmt.Errorf("failed to promote memory: %w", err)
+	}
+	c.timeline.record(TimelinePromotion, memoryID, "Promoted memory to the global namespace", map[string]interface{}{
+		"from":      candidate.Namespace,
+		"uses":      candidate.Uses,
+		"successes": candidate.Successes,
+	})
+	return nil
+}
+
+// RejectPromotion takes a memory off the queue, it stays in its session
+// and is not queued again
+func (c *Coordinator) RejectPromotion(memoryID string) error {
+	if _, err := c.promotions.decide(memoryID); err != nil {
+		return err
+	}
+	c.timeline.record(TimelinePromotion, memoryID, "Rejected promotion of memory", nil)
+	return nil
+}
+
+// countMemoryUses credits the memories a finished task used and records
+// those it queued for promotion
+func (c *Coordinator) countMemoryUses(taskID string, success bool) {
+	for _, candidate := range c.promotions.finished(taskID, success, c.clock.Now()) {
+		c.timeline.record(TimelinePromotion, candidate.MemoryID,
+			fmt.Sprintf("Queued memory for promotion, used by %d tasks", candidate.Uses), map[string]interface{}{
+				"namespace": candidate.Namespace,
+				"uses":      candidate.Uses,
+				"successes": candidate.Successes,
+			})
+	}
+}
@@ -0,0 +1,88 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+func TestPromotion(t *testing.T) {
+	c, err := NewCoordinator(CoordinatorConfig{Promotion: PromotionConfig{MinUses: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cancelFunc()
+
+	ctx := context.Background()
+	store := c.GetMemoryStore()
+	for _, id := range []string{"conventions", "flaky"} {
+		if err := store.Store(ctx, memory.Memory{ID: id, Type: memory.MemoryTypeSemantic, Content: "use " + id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if mem, _ := store.Retrieve(ctx, "conventions"); !strings.HasPrefix(mem.Namespace, "session/") {
+		t.Fatalf("namespace = %q, want the session's", mem.Namespace)
+	}
+
+	c.grants.set(agent.AgentConfig{ID: "reader", Permissions: []agent.Permission{agent.PermissionReadMemory}})
+	reader := permittedMemory{store: store, agentID: "reader", grants: c.grants, promotions: c.promotions}
+	run := func(taskID string, success bool, ids ...string) {
+		t.Helper()
+		ctx := withPromotionTask(ctx, taskID)
+		for _, id := range ids {
+			if _, err := reader.Retrieve(ctx, id); err != nil {
+				t.Fatal(err)
+			}
+		}
+		c.countMemoryUses(taskID, success)
+	}
+
+	// Memories are queued once used by enough tasks that mostly succeeded
+	run("t1", true, "conventions", "flaky")
+	run("t2", true, "conventions", "conventions")
+	run("t3", false, "flaky")
+	candidates := c.PromotionCandidates()
+	if len(candidates) != 1 || candidates[0].MemoryID != "conventions" || candidates[0].Uses != 2 || candidates[0].Preview != "use conventions" {
+		t.Fatalf("candidates = %+v, want conventions used twice", candidates)
+	}
+
+	// Retrieving outside of tasks does not count
+	if _, err := reader.Retrieve(ctx, "flaky"); err != nil {
+		t.Fatal(err)
+	}
+	c.countMemoryUses("t4", true)
+	if candidates := c.PromotionCandidates(); len(candidates) != 1 {
+		t.Fatalf("candidates = %+v, want only conventions", candidates)
+	}
+
+	if err := c.ApprovePromotion("conventions"); err != nil {
+		t.Fatal(err)
+	}
+	global, err := store.Query(ctx, memory.MemoryQuery{Namespace: memory.GlobalNamespace})
+	if err != nil || len(global) != 1 || global[0].ID != "conventions" {
+		t.Fatalf("global memories = %+v, %v, want conventions", global, err)
+	}
+	if err := c.ApprovePromotion("conventions"); !errors.Is(err, ErrPromotionNotFound) {
+		t.Errorf("approving again = %v, want ErrPromotionNotFound", err)
+	}
+
+	// Rejected memories are not queued again. Four of five tasks using
+	// flaky succeeded by now.
+	run("t5", true, "flaky")
+	run("t6", true, "flaky")
+	run("t7", true, "flaky")
+	if candidates := c.PromotionCandidates(); len(candidates) != 1 || candidates[0].MemoryID != "flaky" {
+		t.Fatalf("candidates = %+v, want flaky", candidates)
+	}
+	if err := c.RejectPromotion("flaky"); err != nil {
+		t.Fatal(err)
+	}
+	run("t8", true, "flaky")
+	if candidates := c.PromotionCandidates(); len(candidates) != 0 {
+		t.Errorf("candidates = %+v, want none after the rejection", candidates)
+	}
+}
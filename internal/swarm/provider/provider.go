@@ -0,0 +1,228 @@
+// Package provider gives swarm agents access to language models. Every
+// supported provider serves the OpenAI compatible chat completions API, so a
+// single client covers them all.
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// endpoint is where a provider is reached unless configured otherwise
+type endpoint struct {
+	baseURL string
+	// hostEnv overrides the host of local providers, e.g. OLLAMA_HOST
+	hostEnv string
+	// keyEnv holds the API key of hosted providers
+	keyEnv string
+}
+
+var endpoints = map[string]endpoint{
+	"openrouter":  {baseURL: "https://openrouter.ai/api/v1", keyEnv: "OPENROUTER_API_KEY"},
+	"huggingface": {baseURL: "https://router.huggingface.co/v1", keyEnv: "HUGGINGFACE_API_KEY"},
+	"ollama":      {baseURL: "http://localhost:11434/v1", hostEnv: "OLLAMA_HOST"},
+	"lmstudio":    {baseURL: "http://localhost:1234/v1", hostEnv: "LMSTUDIO_HOST"},
+	"jan":         {baseURL: "http://localhost:1337/v1", hostEnv: "JAN_HOST"},
+}
+
+// Types returns the supported provider types
+func Types() []string {
+	types := make([]string, 0, len(endpoints))
+	for t := range endpoints {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Supported reports whether t is a supported provider type
+func Supported(t string) bool {
+	_, ok := endpoints[t]
+	return ok
+}
+
+// Secrets looks up API keys by name, e.g. in the OS keychain
+type Secrets interface {
+	Get(name string) (string, error)
+}
+
+// Config selects a provider and model
+type Config struct {
+	Type  string
+	Model string
+	// BaseURL and APIKey default to the provider's public endpoint and the
+	// API key in Secrets under SecretName, the provider type if empty, or
+	// else in the provider's environment variable
+	BaseURL    string
+	APIKey     string
+	Secrets    Secrets
+	SecretName string
+	Timeout    time.Duration
+}
+
+// Request is a single prompt to a model
+type Request struct {
+	System    string
+	Prompt    string
+	MaxTokens int
+}
+
+// Response is the answer of a model
+type Response struct {
+	Content      string
+	InputTokens  int64
+	OutputTokens int64
+}
+
+// Client sends prompts to a model
+type Client interface {
+	Complete(ctx context.Context, req Request) (Response, error)
+	Model() string
+}
+
+// New creates a client for the configured provider
+func New(cfg Config) (Client, error) {
+	ep, ok := endpoints[cfg.Type]
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider %q, expected one of %s", cfg.Type, strings.Join(Types(), ", "))
+	}
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("provider %s: model is required", cfg.Type)
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = ep.baseURL
+		if host := os.Getenv(ep.hostEnv); ep.hostEnv != "" && host != "" {
+			baseURL = strings.TrimSuffix(host, "/") + "/v1"
+		}
+	}
+	apiKey := cfg.APIKey
+	if apiKey == "" && ep.keyEnv != "" {
+		apiKey = lookupKey(cfg, ep)
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+
+	return &chatClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   cfg.Model,
+		http:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// lookupKey finds the API key of a hosted provider in the secrets, or else
+// in the environment
+func lookupKey(cfg Config, ep endpoint) string {
+	if cfg.Secrets != nil {
+		name := cfg.SecretName
+		if name == "" {
+			name = cfg.Type
+		}
+		if key, err := cfg.Secrets.Get(name); err == nil && key != "" {
+			return key
+		}
+	}
+	return os.Getenv(ep.keyEnv)
+}
+
+// chatClient calls the chat completions endpoint
+type chatClient struct {
+	baseURL string
+	apiKey  string
+	model   string
+	http    *http.Client
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model     string        `json:"model"`
+	Messages  []chatMessage `json:"messages"`
+	MaxTokens int           `json:"max_tokens,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int64 `json:"prompt_tokens"`
+		CompletionTokens int64 `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *chatClient) Model() string {
+	return c.model
+}
+
+func (c *chatClient) Complete(ctx context.Context, req Request) (Response, error) {
+	body := chatRequest{Model: c.model, MaxTokens: req.MaxTokens}
+	if req.System != "" {
+		body.Messages = append(body.Messages, chatMessage{Role: "system", Content: req.System})
+	}
+	body.Messages = append(body.Messages, chatMessage{Role: "user", Content: req.Prompt})
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return Response{}, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return Response{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return Response{}, fmt.Errorf("model request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to read model response: %w", err)
+	}
+	var parsed chatResponse
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		if resp.StatusCode >= 300 {
+			return Response{}, fmt.Errorf("model request failed: %s", resp.Status)
+		}
+		return Response{}, fmt.Errorf("invalid model response: %w", err)
+	}
+	if parsed.Error != nil {
+		return Response{}, fmt.Errorf("model request failed: %s", parsed.Error.Message)
+	}
+	if resp.StatusCode >= 300 {
+		return Response{}, fmt.Errorf("model request failed: %s", resp.Status)
+	}
+	if len(parsed.Choices) == 0 {
+		return Response{}, fmt.Errorf("model returned no choices")
+	}
+
+	return Response{
+		Content:      parsed.Choices[0].Message.Content,
+		InputTokens:  parsed.Usage.PromptTokens,
+		OutputTokens: parsed.Usage.CompletionTokens,
+	}, nil
+}
@@ -0,0 +1,128 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+	"github.com/opencode-ai/opencode/internal/swarm/voting"
+)
+
+// DefaultPruneVoteTimeout is how long a vote on a prune stays open unless
+// configured otherwise
+const DefaultPruneVoteTimeout = 5 * time.Minute
+
+// VoteTagMemoryPrune tags the votes on prunes
+const VoteTagMemoryPrune = "memory_prune"
+
+// PruneVoteConfig makes prunes deleting many memories wait for a vote, so
+// misconfigured criteria cannot wipe the knowledge of the swarm. Agents
+// implementing agent.PruneReviewer vote; without them the user has to
+// approve the prune in the vote review.
+type PruneVoteConfig struct {
+	// MinCount is how many deletions need a vote, zero to not count them
+	MinCount int
+	// MinFraction is the share of the stored memories whose deletion needs
+	// a vote, zero to not count it
+	MinFraction float64
+	// Timeout is how long the vote stays open, DefaultPruneVoteTimeout if
+	// zero. A prune not approved by then is rejected.
+	Timeout time.Duration
+}
+
+// enabled reports whether any prune needs a vote
+func (p PruneVoteConfig) enabled() bool {
+	return p.MinCount > 0 || p.MinFraction > 0
+}
+
+// needsVote reports whether a prune is large enough to need a vote
+func (p PruneVoteConfig) needsVote(plan memory.PrunePlan) bool {
+	return (p.MinCount > 0 && plan.Deleting >= p.MinCount) ||
+		(p.MinFraction > 0 && plan.Fraction() >= p.MinFraction)
+}
+
+// approvePrune lets prunes below the thresholds through and holds the
+// others until the swarm or the user voted on them
+func (c *Coordinator) approvePrune(ctx context.Context, plan memory.PrunePlan) error {
+	config := c.pruneVote
+	if !config.needsVote(plan) {
+		return nil
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultPruneVoteTimeout
+	}
+
+	var reviewers []agent.PruneReviewer
+	for _, ag := range c.grants.voters(c.registry.GetAllAgents()) {
+		if reviewer, ok := ag.(agent.PruneReviewer); ok {
+			reviewers = append(reviewers, reviewer)
+		}
+	}
+	// Without reviewers the vote of the user decides
+	minVoters := len(reviewers)
+	if minVoters == 0 {
+		minVoters = 1
+	}
+
+	proposal := voting.VoteProposal{
+		Description: fmt.Sprintf("Should we delete %d of %d memories (%.0f%%)", plan.Deleting, plan.Total, plan.Fraction()*100),
+		ProposedBy:  "memory",
+		Context: map[string]interface{}{
+			"criteria": plan.Criteria,
+			"deleting": plan.Deleting,
+			"total":    plan.Total,
+			"byType":   plan.ByType,
+		},
+		Tags:     []string{VoteTagMemoryPrune, VoteTagDestructive},
+		Deadline: c.clock.Now().Add(timeout),
+	}
+	session, err := c.votingSystem.CreateVoteSession(proposal, voting.VoteTypeMajority, minVoters, nil)
+	if err != nil {
+		return err
+	}
+	c.timeline.record(TimelineVoteOpened, session.ID, proposal.Description, map[string]interface{}{
+		"deleting": plan.Deleting,
+		"voters":   len(reviewers),
+	})
+
+	for _, reviewer := range reviewers {
+		approve, reasoning := reviewer.ReviewPrune(ctx, plan)
+		_ = c.votingSystem.CastVote(session.ID, voting.Vote{
+			AgentID:    reviewer.(agent.Agent).GetID(),
+			Decision:   approve,
+			Confidence: 1.0,
+			Reasoning:  reasoning,
+		})
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := c.votingSystem.WaitForResult(waitCtx, session.ID)
+	if err != nil {
+		c.timeline.record(TimelineVoteDecided, session.ID, "Prune of memories not approved in time", map[string]interface{}{
+			"deleting": plan.Deleting,
+			"expired":  true,
+		})
+		return fmt.Errorf("%w: no decision on deleting %d memories: %w", ErrPruneRejected, plan.Deleting, err)
+	}
+
+	decision := "rejected"
+	if result.Decision {
+		decision = "approved"
+	}
+	c.timeline.record(TimelineVoteDecided, session.ID, fmt.Sprintf("Prune of %d memories %s", plan.Deleting, decision), map[string]interface{}{
+		"deleting": plan.Deleting,
+		"approved": result.Decision,
+		"yes":      result.YesVotes,
+		"no":       result.NoVotes,
+		"vetoed":   result.Vetoed,
+		"vetoedBy": result.VetoedBy,
+	})
+	if !result.Decision {
+		return fmt.Errorf("%w: deleting %d memories", ErrPruneRejected, plan.Deleting)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+	"github.com/opencode-ai/opencode/internal/swarm/voting"
+)
+
+// pruneReviewer approves prunes deleting at most max memories
+type pruneReviewer struct {
+	*echoAgent
+	max int
+}
+
+func (a *pruneReviewer) ReviewPrune(ctx context.Context, plan memory.PrunePlan) (bool, string) {
+	return plan.Deleting <= a.max, fmt.Sprintf("at most %d", a.max)
+}
+
+func TestPruneVote(t *testing.T) {
+	c, err := NewCoordinator(CoordinatorConfig{PruneVote: PruneVoteConfig{MinCount: 5, Timeout: 5 * time.Second}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cancelFunc()
+
+	ctx := context.Background()
+	store := c.GetMemoryStore()
+	old := time.Now().Add(-time.Hour)
+	storeOld := func(n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if err := store.Store(ctx, memory.Memory{Type: memory.MemoryTypeEpisodic, CreatedAt: old}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	prune := func() error {
+		return store.Prune(ctx, memory.PruneCriteria{MaxAge: time.Minute})
+	}
+
+	// Small prunes need no vote
+	storeOld(4)
+	if err := prune(); err != nil {
+		t.Fatal(err)
+	}
+	if stats := store.GetStats(); stats.TotalMemories != 0 {
+		t.Fatalf("%d memories left, want the small prune done", stats.TotalMemories)
+	}
+
+	// Reviewers vote on large ones
+	reviewer := &pruneReviewer{
+		echoAgent: &echoAgent{BaseAgent: agent.NewBaseAgent(agent.AgentConfig{ID: "librarian", Type: agent.AgentTypeMemory})},
+		max:       5,
+	}
+	if err := c.GetRegistry().RegisterAgent(reviewer); err != nil {
+		t.Fatal(err)
+	}
+	storeOld(10)
+	if err := prune(); !errors.Is(err, ErrPruneRejected) {
+		t.Fatalf("prune = %v, want ErrPruneRejected", err)
+	}
+	if stats := store.GetStats(); stats.TotalMemories != 10 {
+		t.Fatalf("%d memories left, want none deleted", stats.TotalMemories)
+	}
+
+	// Without reviewers the user decides in the vote review
+	if err := c.GetRegistry().UnregisterAgent("librarian"); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			sessions := c.GetVotingSystem().ListSessions(voting.SessionFilter{Status: voting.SessionOpen, Tag: VoteTagMemoryPrune})
+			if len(sessions) > 0 {
+				_ = c.GetVotingSystem().CastVote(sessions[0].ID, voting.Vote{AgentID: voting.HumanVoterID, Decision: true})
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	if err := prune(); err != nil {
+		t.Fatal(err)
+	}
+	if stats := store.GetStats(); stats.TotalMemories != 0 {
+		t.Errorf("%d memories left, want the approved prune done", stats.TotalMemories)
+	}
+}
@@ -0,0 +1,56 @@
+package swarm
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+)
+
+func TestReconfigureAgent(t *testing.T) {
+	cfg := agent.AgentConfig{ID: "reviewer", Type: agent.AgentType("review"), ProviderType: "ollama", Model: "llama3"}
+	c, err := NewCoordinator(CoordinatorConfig{SwarmConfig: agent.SwarmConfig{Agents: []agent.AgentConfig{cfg}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+	old, err := c.GetRegistry().GetAgent("reviewer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A new model and capabilities are taken while running
+	cfg.Model = "qwen3"
+	cfg.Capabilities = []string{"review", "documentation"}
+	recycled, err := c.ReconfigureAgent(cfg)
+	if err != nil || len(recycled) != 0 {
+		t.Fatalf("ReconfigureAgent = %v, %v, want it applied live", recycled, err)
+	}
+	if ag, _ := c.GetRegistry().GetAgent("reviewer"); ag != old || !slices.Equal(ag.GetCapabilities(), cfg.Capabilities) {
+		t.Errorf("agent = %v with %v, want the same agent reconfigured", ag, ag.GetCapabilities())
+	}
+
+	// New options need a new agent
+	cfg.CustomConfig = map[string]interface{}{"systemPrompt": "Review briefly."}
+	recycled, err = c.ReconfigureAgent(cfg)
+	if err != nil || !slices.Equal(recycled, []string{"options"}) {
+		t.Fatalf("ReconfigureAgent = %v, %v, want options recycled", recycled, err)
+	}
+	ag, err := c.GetRegistry().GetAgent("reviewer")
+	if err != nil || ag == old {
+		t.Fatalf("agent = %v, %v, want a new agent", ag, err)
+	}
+	if ag.GetStatus() != agent.AgentStatusIdle || old.GetStatus() != agent.AgentStatusStopped {
+		t.Errorf("new agent %s and old %s, want the new one started and the old stopped", ag.GetStatus(), old.GetStatus())
+	}
+	if agents := c.config.Agents; len(agents) != 1 || agents[0].CustomConfig == nil {
+		t.Errorf("configured agents = %+v, want the new configuration", agents)
+	}
+
+	if _, err := c.ReconfigureAgent(agent.AgentConfig{ID: "missing"}); err == nil {
+		t.Error("ReconfigureAgent of an unknown agent succeeded")
+	}
+}
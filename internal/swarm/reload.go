@@ -0,0 +1,765 @@
+package swarm
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/opencode-ai/opencode/internal/swarm/rules"
+)
+
+// reloadDebounce groups the bursts of events editors produce when saving
+const reloadDebounce = 200 * time.Millisecond
+
+// ConfigChange is one difference between the running configuration and the
+// configuration file
+type ConfigChange struct {
+	Field   string
+	Old     string
+	New     string
+	Applied bool
+	// Reason explains why a change was not applied
+	Reason string
+	// Recycled are the fields of an agent that changed in ways it could not
+	// take while running, so it was replaced by a new agent
+	Recycled []string
+}
+
+// ReloadReport describes the outcome of reloading a configuration file
+type ReloadReport struct {
+	Path    string
+	Time    time.Time
+	Changes []ConfigChange
+	// Err is set when the file could not be loaded, nothing was applied
+	Err error
+}
+
+// Rejected returns the changes that were not applied
+func (r ReloadReport) Rejected() []ConfigChange {
+	var rejected []ConfigChange
+	for _, change := range r.Changes {
+		if !change.Applied {
+			rejected = append(rejected, change)
+		}
+	}
+	return rejected
+}
+
+// String renders the report as a diff, applied changes marked with ~ and
+// rejected ones with !
+func (r ReloadReport) String() string {
+	var b strings.Builder
+	switch {
+	case r.Err != nil:
+		fmt.Fprintf(&b, "config reload failed: %v", r.Err)
+		return b.String()
+	case len(r.Changes) == 0:
+		fmt.Fprintf(&b, "config reloaded from %s: no changes", r.Path)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "config reloaded from %s:", r.Path)
+	for _, change := range r.Changes {
+		mark, note := "~", "applied"
+		if !change.Applied {
+			mark, note = "!", change.Reason
+		} else if len(change.Recycled) > 0 {
+			note = "applied, recycled for " + strings.Join(change.Recycled, ", ")
+		}
+		fmt.Fprintf(&b, "\n  %s %s: %s -> %s (%s)", mark, change.Field, orNone(change.Old), orNone(change.New), note)
+	}
+	return b.String()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "(none)"
+	}
+	return s
+}
+
+// ConfigWatcher reloads a swarm configuration file when it or a rule file
+// changes and applies what can change without a restart: thresholds, log
+// paths, rules and new agents. Other changes are reported as rejected until
+// the swarm is restarted.
+type ConfigWatcher struct {
+	coordinator *Coordinator
+	path        string
+	watcher     *fsnotify.Watcher
+	reports     chan ReloadReport
+
+	mu      sync.Mutex
+	current FileConfig
+	rules   []rules.RuleDefinition
+
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// NewConfigWatcher creates a watcher for the file at path, which the
+// coordinator was started with as current
+func NewConfigWatcher(c *Coordinator, path string, current FileConfig) (*ConfigWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	w := &ConfigWatcher{
+		coordinator: c,
+		path:        path,
+		watcher:     watcher,
+		reports:     make(chan ReloadReport, 10),
+		current:     current,
+		done:        make(chan struct{}),
+	}
+	if current.RulesDir != "" {
+		w.rules, _ = rules.LoadDefinitionDir(current.RulesDir)
+	}
+	return w, nil
+}
+
+// Start begins watching. The directory of the file is watched rather than
+// the file so editors that replace the file on save are noticed.
+func (w *ConfigWatcher) Start() error {
+	if err := w.watcher.Add(filepath.Dir(w.path)); err != nil {
+		return fmt.Errorf("failed to watch %s: %w", w.path, err)
+	}
+	w.watchRulesDir(w.current.RulesDir)
+
+	w.wg.Add(1)
+	go w.loop()
+	return nil
+}
+
+// Stop stops watching and closes the report channel. Calling Stop again has
+// no effect.
+func (w *ConfigWatcher) Stop() error {
+	var err error
+	w.stopOnce.Do(func() {
+		close(w.done)
+		err = w.watcher.Close()
+		w.wg.Wait()
+		close(w.reports)
+	})
+	return err
+}
+
+// Reports returns the outcome of every automatic reload
+func (w *ConfigWatcher) Reports() <-chan ReloadReport {
+	return w.reports
+}
+
+func (w *ConfigWatcher) watchRulesDir(dir string) {
+	if dir != "" {
+		_ = w.watcher.Add(dir)
+	}
+}
+
+func (w *ConfigWatcher) loop() {
+	defer w.wg.Done()
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if w.relevant(event) {
+				debounce = w.coordinator.clock.After(reloadDebounce)
+			}
+		case _, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+		case <-debounce:
+			debounce = nil
+			report := w.Reload()
+			select {
+			case w.reports <- report:
+			default:
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// relevant reports whether an event touches the config file or a rule file
+func (w *ConfigWatcher) relevant(event fsnotify.Event) bool {
+	if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 {
+		return false
+	}
+	if filepath.Clean(event.Name) == filepath.Clean(w.path) {
+		return true
+	}
+
+	w.mu.Lock()
+	dir := w.current.RulesDir
+	w.mu.Unlock()
+	ext := strings.ToLower(filepath.Ext(event.Name))
+	return dir != "" && filepath.Dir(event.Name) == filepath.Clean(dir) && (ext == ".yaml" || ext == ".yml")
+}
+
+// Reload reads the file now and applies the safe changes
+func (w *ConfigWatcher) Reload() ReloadReport {
+	report := ReloadReport{Path: w.path, Time: w.coordinator.clock.Now()}
+	next, err := LoadFileConfig(w.path)
+	if err != nil {
+		report.Err = err
+		return report
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	report.Changes = w.apply(next)
+	return report
+}
+
+// apply makes the running swarm match next where it can and records what
+// was applied in the current configuration
+func (w *ConfigWatcher) apply(next FileConfig) []ConfigChange {
+	cur := &w.current
+	c := w.coordinator
+	var changes []ConfigChange
+
+	restart := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, ConfigChange{Field: field, Old: old, New: new, Reason: "requires restart"})
+		}
+	}
+	applied := func(field, old, new string, err error, recycled ...string) bool {
+		change := ConfigChange{Field: field, Old: old, New: new, Applied: err == nil, Recycled: recycled}
+		if err != nil {
+			change.Reason = err.Error()
+		}
+		changes = append(changes, change)
+		return err == nil
+	}
+
+	restart("name", cur.Name, next.Name)
+	restart("api", cur.API, next.API)
+	restart("grpc", cur.GRPC, next.GRPC)
+	restart("maxConcurrentTasks", fmt.Sprint(cur.MaxConcurrentTasks), fmt.Sprint(next.MaxConcurrentTasks))
+	restart("taskQueueSize", fmt.Sprint(cur.TaskQueueSize), fmt.Sprint(next.TaskQueueSize))
+	restart("idempotencyWindow", durationString(cur.IdempotencyWindow), durationString(next.IdempotencyWindow))
+	restart("logFormat", cur.LogFormat, next.LogFormat)
+	restart("shellHistory", cur.ShellHistory, next.ShellHistory)
+	restart("reports", reportsSummary(cur.Reports), reportsSummary(next.Reports))
+	restart("ciReports", ciReportsSummary(cur.CIReports), ciReportsSummary(next.CIReports))
+	restart("healthCheckInterval", durationString(cur.HealthCheckInterval), durationString(next.HealthCheckInterval))
+	restart("consolidationInterval", durationString(cur.ConsolidationInterval), durationString(next.ConsolidationInterval))
+	restart("scratchDir", cur.ScratchDir, next.ScratchDir)
+	restart("scratchRetention", durationString(cur.ScratchRetention), durationString(next.ScratchRetention))
+	restart("artifactDir", cur.ArtifactDir, next.ArtifactDir)
+	restart("artifactRetention", durationString(cur.ArtifactRetention), durationString(next.ArtifactRetention))
+	restart("decisionsDir", cur.DecisionsDir, next.DecisionsDir)
+	restart("memory.backend", cur.Memory.Backend, next.Memory.Backend)
+	restart("memory.maxMemories", fmt.Sprint(cur.Memory.MaxMemories), fmt.Sprint(next.Memory.MaxMemories))
+	restart("memory.pruneOlderThan", durationString(cur.Memory.PruneOlderThan), durationString(next.Memory.PruneOlderThan))
+	restart("memory.compactThreshold", fmt.Sprint(cur.Memory.CompactThreshold), fmt.Sprint(next.Memory.CompactThreshold))
+	if cur.Memory.EncryptionKey != next.Memory.EncryptionKey {
+		restart("memory.encryptionKey", secret(cur.Memory.EncryptionKey), "changed")
+	}
+	restart("memory.monitorWrites", batchSummary(cur.Memory.MonitorWrites), batchSummary(next.Memory.MonitorWrites))
+	restart("memory.pruneVote", pruneVoteSummary(cur.Memory.PruneVote), pruneVoteSummary(next.Memory.PruneVote))
+	restart("memory.quotas", quotaSummary(cur.Memory.Quotas), quotaSummary(next.Memory.Quotas))
+	restart("memory.tagging", taggingSummary(cur.Memory.Tagging), taggingSummary(next.Memory.Tagging))
+	restart("encryption", encryptionSummary(cur.Encryption), encryptionSummary(next.Encryption))
+	restart("warmPools", warmPoolSummary(cur.WarmPools), warmPoolSummary(next.WarmPools))
+	restart("verification", verificationSummary(cur.Verification), verificationSummary(next.Verification))
+	restart("prices", priceSummary(cur.Prices), priceSummary(next.Prices))
+	restart("dryRun", fmt.Sprint(cur.DryRun), fmt.Sprint(next.DryRun))
+	restart("reviewSyncInterval", durationString(cur.ReviewSyncInterval), durationString(next.ReviewSyncInterval))
+	if !reflect.DeepEqual(cur.Notifications, next.Notifications) {
+		// Webhooks are secret and templates too long to show
+		old, changed := notificationSummary(cur.Notifications), notificationSummary(next.Notifications)
+		if old == changed {
+			changed = "webhooks or templates changed"
+		}
+		restart("notifications", old, changed)
+	}
+	w.diffProviders(next, restart)
+	w.diffAgents(next, restart, applied)
+
+	if next.VotingThreshold != cur.VotingThreshold {
+		c.setVotingThreshold(next.VotingThreshold)
+		applied("votingThreshold", fmt.Sprint(cur.VotingThreshold), fmt.Sprint(next.VotingThreshold), nil)
+		cur.VotingThreshold = next.VotingThreshold
+	}
+	if next.SelectionJitter != cur.SelectionJitter {
+		c.registry.SetSelectionJitter(next.SelectionJitter)
+		applied("selectionJitter", fmt.Sprint(cur.SelectionJitter), fmt.Sprint(next.SelectionJitter), nil)
+		cur.SelectionJitter = next.SelectionJitter
+	}
+	if next.Memory.Promotion != cur.Memory.Promotion {
+		c.promotions.setConfig(next.Memory.Promotion.promotionConfig())
+		applied("memory.promotion", promotionSummary(cur.Memory.Promotion), promotionSummary(next.Memory.Promotion), nil)
+		cur.Memory.Promotion = next.Memory.Promotion
+	}
+	if next.AgentHealth != cur.AgentHealth {
+		c.healthGate.setConfig(next.AgentHealth.agentHealthConfig())
+		applied("agentHealth", agentHealthSummary(cur.AgentHealth), agentHealthSummary(next.AgentHealth), nil)
+		cur.AgentHealth = next.AgentHealth
+	}
+	restart("watchdog.interval", durationString(cur.Watchdog.Interval), durationString(next.Watchdog.Interval))
+	if limits := next.Watchdog; limits.Factor != cur.Watchdog.Factor || limits.DefaultExpected != cur.Watchdog.DefaultExpected || limits.Action != cur.Watchdog.Action {
+		// The interval stays as the watchdog was started with
+		limits.Interval = cur.Watchdog.Interval
+		c.watchdog.setConfig(limits.watchdogConfig())
+		applied("watchdog", watchdogSummary(cur.Watchdog), watchdogSummary(limits), nil)
+		cur.Watchdog = limits
+	}
+	restart("heartbeat.interval", durationString(cur.Heartbeat.Interval), durationString(next.Heartbeat.Interval))
+	if next.Heartbeat.Missed != cur.Heartbeat.Missed {
+		// Agents keep the interval they were created with
+		limits := HeartbeatConfig{Interval: time.Duration(cur.Heartbeat.Interval), Missed: next.Heartbeat.Missed}
+		c.registry.SetHeartbeatTimeout(limits.timeout())
+		applied("heartbeat.missed", fmt.Sprint(cur.Heartbeat.missed()), fmt.Sprint(next.Heartbeat.missed()), nil)
+		cur.Heartbeat.Missed = next.Heartbeat.Missed
+	}
+	if next.AlertThreshold != cur.AlertThreshold {
+		c.healthMonitor.SetAlertThreshold(next.AlertThreshold)
+		applied("alertThreshold", fmt.Sprint(cur.AlertThreshold), fmt.Sprint(next.AlertThreshold), nil)
+		cur.AlertThreshold = next.AlertThreshold
+	}
+	// Metadata first, so files of new log paths are read with theirs
+	if !reflect.DeepEqual(cur.LogMetadata, next.LogMetadata) && !(len(cur.LogMetadata) == 0 && len(next.LogMetadata) == 0) {
+		err := c.setLogMetadata(logMetadata(next.LogMetadata))
+		if applied("logMetadata", logMetadataSummary(cur.LogMetadata), logMetadataSummary(next.LogMetadata), err) {
+			cur.LogMetadata = next.LogMetadata
+		}
+	}
+	if !reflect.DeepEqual(cur.LogPaths, next.LogPaths) && !(len(cur.LogPaths) == 0 && len(next.LogPaths) == 0) {
+		err := c.setLogPaths(next.LogPaths)
+		if applied("logPaths", strings.Join(cur.LogPaths, ", "), strings.Join(next.LogPaths, ", "), err) {
+			cur.LogPaths = next.LogPaths
+		}
+	}
+
+	var defs []rules.RuleDefinition
+	var err error
+	if next.RulesDir != "" {
+		defs, err = rules.LoadDefinitionDir(next.RulesDir)
+	}
+	if err == nil && next.RulesDir == cur.RulesDir && reflect.DeepEqual(defs, w.rules) {
+		return changes
+	}
+	if err == nil {
+		err = c.replaceFileRules(defs)
+	}
+	if next.RulesDir != cur.RulesDir {
+		applied("rulesDir", cur.RulesDir, next.RulesDir, err)
+	}
+	if applied("rules", ruleIDs(w.rules), ruleIDs(defs), err) {
+		if next.RulesDir != cur.RulesDir {
+			w.watchRulesDir(next.RulesDir)
+			if cur.RulesDir != "" {
+				_ = w.watcher.Remove(cur.RulesDir)
+			}
+		}
+		cur.RulesDir = next.RulesDir
+		w.rules = defs
+	}
+	return changes
+}
+
+func (w *ConfigWatcher) diffProviders(next FileConfig, restart func(field, old, new string)) {
+	names := make(map[string]bool)
+	for name := range w.current.Providers {
+		names[name] = true
+	}
+	for name := range next.Providers {
+		names[name] = true
+	}
+	for _, name := range sortedKeys(names) {
+		old, hadOld := w.current.Providers[name]
+		updated, hasNew := next.Providers[name]
+		if hadOld == hasNew && old == updated {
+			continue
+		}
+		var oldDesc, newDesc string
+		if hadOld {
+			oldDesc = providerSummary(name, old)
+		}
+		if hasNew {
+			newDesc = providerSummary(name, updated)
+			if hadOld && old.APIKey != updated.APIKey {
+				newDesc += ", apiKey changed"
+			}
+		}
+		restart("providers."+name, oldDesc, newDesc)
+	}
+}
+
+// diffAgents starts agents added to the configuration and reconfigures
+// changed ones, recycling those that cannot take their changes while
+// running. Removed agents need a restart.
+func (w *ConfigWatcher) diffAgents(next FileConfig, restart func(field, old, new string), applied func(field, old, new string, err error, recycled ...string) bool) {
+	old := make(map[string]AgentFileConfig)
+	for _, a := range w.current.Agents {
+		old[a.ID] = a
+	}
+	ids := make(map[string]bool)
+	for id := range old {
+		ids[id] = true
+	}
+	nextByID := make(map[string]AgentFileConfig)
+	for _, a := range next.Agents {
+		nextByID[a.ID] = a
+		ids[a.ID] = true
+	}
+
+	for _, id := range sortedKeys(ids) {
+		a, hadOld := old[id]
+		b, hasNew := nextByID[id]
+		if hadOld && hasNew && reflect.DeepEqual(a, b) {
+			continue
+		}
+		if !hadOld {
+			err := w.coordinator.addConfiguredAgent(next.agentConfig(b))
+			if applied("agents."+id, "", agentSummary(b), err) {
+				w.current.Agents = append(w.current.Agents, b)
+			}
+			continue
+		}
+		if hasNew {
+			recycled, err := w.coordinator.ReconfigureAgent(next.agentConfig(b))
+			newDesc := agentSummary(b)
+			if newDesc == agentSummary(a) {
+				// Options and intervals are not summarized
+				newDesc = "options or intervals changed"
+			}
+			if applied("agents."+id, agentSummary(a), newDesc, err, recycled...) {
+				for i := range w.current.Agents {
+					if w.current.Agents[i].ID == id {
+						w.current.Agents[i] = b
+					}
+				}
+			}
+			continue
+		}
+		restart("agents."+id, agentSummary(a), "")
+	}
+}
+
+func providerSummary(name string, p ProviderFileConfig) string {
+	summary := p.providerType(name)
+	if p.BaseURL != "" {
+		summary += " " + p.BaseURL
+	}
+	return summary
+}
+
+func agentSummary(a AgentFileConfig) string {
+	summary := a.Type
+	if a.Provider != "" {
+		summary += " on " + a.Provider
+	}
+	if a.Model != "" {
+		summary += "/" + a.Model
+	}
+	if len(a.Capabilities) > 0 {
+		summary += " [" + strings.Join(a.Capabilities, ", ") + "]"
+	}
+	if a.MaxConcurrency > 1 {
+		summary += fmt.Sprintf(" x%d", a.MaxConcurrency)
+	}
+	if a.Permissions != nil {
+		summary += " may " + strings.Join(a.Permissions, ", ")
+	}
+	if a.Shadow {
+		summary += " (shadow)"
+	}
+	return summary
+}
+
+func taggingSummary(t TaggingFileConfig) string {
+	if !t.Enabled {
+		return "off"
+	}
+	summary := "on"
+	if len(t.Rules) > 0 {
+		summary += fmt.Sprintf(", rules %v", t.Rules)
+	}
+	if len(t.Aliases) > 0 {
+		summary += fmt.Sprintf(", aliases %v", t.Aliases)
+	}
+	if c := t.Classifier; c != nil {
+		summary += fmt.Sprintf(", classifier %s/%s", c.Provider, c.Model)
+	}
+	return summary
+}
+
+func quotaSummary(quotas map[string]QuotaFileConfig) string {
+	if len(quotas) == 0 {
+		return "none"
+	}
+	types := make([]string, 0, len(quotas))
+	for typ := range quotas {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	parts := make([]string, len(types))
+	for i, typ := range types {
+		quota := quotas[typ]
+		parts[i] = fmt.Sprintf("%s %d", typ, quota.Max)
+		if quota.Eviction != "" {
+			parts[i] += " evicting by " + quota.Eviction
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func pruneVoteSummary(p PruneVoteFileConfig) string {
+	var parts []string
+	if p.MinCount > 0 {
+		parts = append(parts, fmt.Sprintf("%d memories", p.MinCount))
+	}
+	if p.MinFraction > 0 {
+		parts = append(parts, fmt.Sprintf("%g of the store", p.MinFraction))
+	}
+	if len(parts) == 0 {
+		return "off"
+	}
+	summary := "from " + strings.Join(parts, " or ")
+	if p.Timeout > 0 {
+		summary += ", open for " + durationString(p.Timeout)
+	}
+	return summary
+}
+
+func agentHealthSummary(a AgentHealthFileConfig) string {
+	if a.MinScore <= 0 {
+		return "off"
+	}
+	summary := fmt.Sprintf("below %g", a.MinScore)
+	if a.RetryAfter > 0 {
+		summary += ", retried after " + durationString(a.RetryAfter)
+	}
+	return summary
+}
+
+func watchdogSummary(w WatchdogFileConfig) string {
+	factor := w.Factor
+	if factor == 0 {
+		factor = DefaultStuckFactor
+	}
+	summary := fmt.Sprintf("%gx the longest run", factor)
+	if w.DefaultExpected > 0 {
+		summary += ", " + durationString(w.DefaultExpected) + " until learned"
+	}
+	action := w.Action
+	if action == "" {
+		action = string(StuckAlert)
+	}
+	return summary + ", " + action
+}
+
+func promotionSummary(p PromotionFileConfig) string {
+	if p.MinUses == 0 {
+		return "off"
+	}
+	summary := fmt.Sprintf("%d uses", p.MinUses)
+	if p.MinSuccessRate > 0 {
+		summary += fmt.Sprintf(", %g success rate", p.MinSuccessRate)
+	}
+	return summary
+}
+
+func warmPoolSummary(pools map[string]WarmPoolFileConfig) string {
+	types := make([]string, 0, len(pools))
+	for typ := range pools {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	parts := make([]string, len(types))
+	for i, typ := range types {
+		pool := pools[typ]
+		parts[i] = fmt.Sprintf("%s x%d", typ, pool.Size)
+		if pool.KeepAlive > 0 {
+			parts[i] += " kept alive every " + durationString(pool.KeepAlive)
+		}
+		if pool.IdleTimeout > 0 {
+			parts[i] += " evicted after " + durationString(pool.IdleTimeout)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func verificationSummary(verification map[string]VerificationFileConfig) string {
+	types := make([]string, 0, len(verification))
+	for typ := range verification {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	parts := make([]string, len(types))
+	for i, typ := range types {
+		v := verification[typ]
+		parts[i] = fmt.Sprintf("%s by %s", typ, v.Reviewer)
+		if v.Timeout > 0 {
+			parts[i] += " within " + durationString(v.Timeout)
+		}
+		if v.Required {
+			parts[i] += ", required"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func priceSummary(prices map[string]PriceFileConfig) string {
+	models := make([]string, 0, len(prices))
+	for model := range prices {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	parts := make([]string, len(models))
+	for i, model := range models {
+		parts[i] = fmt.Sprintf("%s $%g/$%g", model, prices[model].Input, prices[model].Output)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// reportsSummary describes when activity reports are made and where they
+// go, without the webhook, which may hold a secret
+func reportsSummary(r ReportsFileConfig) string {
+	var periods []string
+	if r.Daily {
+		periods = append(periods, "daily")
+	}
+	if r.Weekly {
+		periods = append(periods, "weekly on "+cmp.Or(r.Weekday, "monday"))
+	}
+	if len(periods) == 0 {
+		return ""
+	}
+	summary := strings.Join(periods, " and ") + " at " + cmp.Or(r.At, "00:00") + " as " + cmp.Or(r.Format, "markdown")
+	var sinks []string
+	if r.Dir != "" {
+		sinks = append(sinks, r.Dir)
+	}
+	if r.Webhook != "" {
+		sinks = append(sinks, "a webhook")
+	}
+	if len(sinks) > 0 {
+		summary += " to " + strings.Join(sinks, " and ")
+	}
+	return summary
+}
+
+// logMetadataSummary describes the static fields of log files, e.g.
+// "/var/log/api/*.log: environment=prod, service=api"
+func logMetadataSummary(metadata []LogMetadataFileConfig) string {
+	parts := make([]string, len(metadata))
+	for i, m := range metadata {
+		fields := logMetadataFields(m)
+		pairs := make([]string, 0, len(fields))
+		for key, value := range fields {
+			pairs = append(pairs, key+"="+value)
+		}
+		sort.Strings(pairs)
+		parts[i] = m.Path + ": " + strings.Join(pairs, ", ")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ciReportsSummary describes where CI reports are read from
+func ciReportsSummary(r CIReportsFileConfig) string {
+	var sources []string
+	if r.Dir != "" {
+		sources = append(sources, r.Dir)
+	}
+	sources = append(sources, r.URLs...)
+	summary := strings.Join(sources, ", ")
+	if summary != "" && r.Interval > 0 {
+		summary += " every " + durationString(r.Interval)
+	}
+	return summary
+}
+
+func notificationSummary(notifications map[string]NotificationFileConfig) string {
+	names := make([]string, 0, len(notifications))
+	for name := range notifications {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		n := notifications[name]
+		events := "all"
+		if len(n.Events) > 0 {
+			events = strings.Join(n.Events, "+")
+		}
+		parts[i] = fmt.Sprintf("%s on %s: %s", name, n.Type, events)
+		if n.MinSeverity != "" {
+			parts[i] += " from " + n.MinSeverity
+		}
+		if n.DigestAt != "" {
+			parts[i] += " digest at " + n.DigestAt
+		}
+		if n.RateLimit > 0 {
+			parts[i] += fmt.Sprintf(" at most %d", n.RateLimit)
+			if n.RatePeriod > 0 {
+				parts[i] += " per " + durationString(n.RatePeriod)
+			}
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func batchSummary(b BatchFileConfig) string {
+	summary := fmt.Sprintf("every %s or %d memories", durationString(b.FlushInterval), b.MaxBatch)
+	if b.MaxPerSecond > 0 {
+		summary += fmt.Sprintf(", %g a second", b.MaxPerSecond)
+	}
+	return summary + fmt.Sprintf(", queue %d dropping %s", b.QueueSize, b.Drop)
+}
+
+func encryptionSummary(e EncryptionFileConfig) string {
+	if !e.AtRest {
+		return "off"
+	}
+	if e.KeyEnv != "" {
+		return "at rest, key from $" + e.KeyEnv
+	}
+	return "at rest"
+}
+
+func ruleIDs(defs []rules.RuleDefinition) string {
+	ids := make([]string, len(defs))
+	for i, def := range defs {
+		ids[i] = def.ID
+	}
+	return strings.Join(ids, ", ")
+}
+
+func durationString(d Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return time.Duration(d).String()
+}
+
+// secret hides a value while still showing whether it was set
+func secret(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "set"
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,171 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+// FileVersion is a version of a file in the file history of opencode
+// sessions
+type FileVersion struct {
+	ID        string
+	SessionID string
+	// Path is absolute
+	Path    string
+	Content string
+	Version string
+	// AgentID and TaskID are the swarm agent and task that made the
+	// version, if the swarm made it
+	AgentID string
+	TaskID  string
+}
+
+// FileHistory is the file history the patches of tasks are recorded in when
+// they are applied, history.Service in opencode
+type FileHistory interface {
+	// TaskVersions returns the versions of files a task made, oldest first
+	TaskVersions(ctx context.Context, taskID string) ([]FileVersion, error)
+	// PreviousVersion returns the version of a file before the given one,
+	// and false if there is none
+	PreviousVersion(ctx context.Context, path, version string) (FileVersion, bool, error)
+	// CreateVersion records a new version of a file in a session
+	CreateVersion(ctx context.Context, sessionID, path, content string) error
+}
+
+// Rollback is what rolling back the changes of a task did. The lists hold
+// the paths of the files the task changed.
+type Rollback struct {
+	TaskID  string
+	AgentID string
+	// Restored files hold their content from before the task again
+	Restored []string
+	// Changed files were changed since the task and are left alone
+	Changed []string
+	// Unversioned files have no version from before the task to restore
+	Unversioned []string
+}
+
+// Summary describes the rollback in one line
+func (r Rollback) Summary() string {
+	total := len(r.Restored) + len(r.Changed) + len(r.Unversioned)
+	summary := fmt.Sprintf("Rolled back %d of %d files changed by task %s", len(r.Restored), total, r.TaskID)
+	if len(r.Changed) > 0 {
+		summary += "; changed since: " + strings.Join(r.Changed, ", ")
+	}
+	if len(r.Unversioned) > 0 {
+		summary += "; no earlier version: " + strings.Join(r.Unversioned, ", ")
+	}
+	return summary
+}
+
+// UseFileHistory sets the file history RollbackTask restores files from
+func (c *Coordinator) UseFileHistory(files FileHistory) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fileHistory = files
+}
+
+// RollbackTask restores the files a task changed to their versions from
+// before the task, by the versions the file history recorded for it, and
+// remembers the rollback as an episodic memory. Files changed since the
+// task are left alone. The restored content is recorded as a new version
+// in the session the task's change was applied in.
+func (c *Coordinator) RollbackTask(ctx context.Context, taskID string) (Rollback, error) {
+	c.mu.Lock()
+	files := c.fileHistory
+	c.mu.Unlock()
+	if files == nil {
+		return Rollback{}, ErrNoFileHistory
+	}
+	versions, err := files.TaskVersions(ctx, taskID)
+	if err != nil {
+		return Rollback{}, fmt.Errorf("failed to look up the changes of task %s: %w", taskID, err)
+	}
+	if len(versions) == 0 {
+		return Rollback{}, &TaskError{TaskID: taskID, Err: ErrNoTaskChanges}
+	}
+
+	// A task applied more than once made several versions of a file. The
+	// version before its first is restored, if the file still has its
+	// last.
+	var paths []string
+	first := make(map[string]FileVersion)
+	last := make(map[string]FileVersion)
+	for _, version := range versions {
+		if _, ok := first[version.Path]; !ok {
+			first[version.Path] = version
+			paths = append(paths, version.Path)
+		}
+		last[version.Path] = version
+	}
+
+	rollback := Rollback{TaskID: taskID, AgentID: versions[0].AgentID}
+	for _, path := range paths {
+		before, ok, err := files.PreviousVersion(ctx, path, first[path].Version)
+		if err != nil {
+			return rollback, fmt.Errorf("failed to look up the version of %s before task %s: %w", path, taskID, err)
+		}
+		if !ok {
+			rollback.Unversioned = append(rollback.Unversioned, path)
+			continue
+		}
+		restored, err := restoreFile(path, last[path].Content, before.Content)
+		if err != nil {
+			return rollback, err
+		}
+		if !restored {
+			rollback.Changed = append(rollback.Changed, path)
+			continue
+		}
+		if err := files.CreateVersion(ctx, last[path].SessionID, path, before.Content); err != nil {
+			return rollback, fmt.Errorf("failed to record the rollback of %s: %w", path, err)
+		}
+		rollback.Restored = append(rollback.Restored, path)
+	}
+
+	c.timeline.record(TimelineRollback, taskID, rollback.Summary(), map[string]interface{}{
+		"agent":       rollback.AgentID,
+		"restored":    rollback.Restored,
+		"changed":     rollback.Changed,
+		"unversioned": rollback.Unversioned,
+	})
+	mem := memory.Memory{
+		Type:     memory.MemoryTypeEpisodic,
+		Content:  rollback.Summary(),
+		Tags:     []string{"rollback", "edits"},
+		Priority: memory.PriorityHigh,
+		Metadata: map[string]interface{}{
+			"task_id":     taskID,
+			"agent_id":    rollback.AgentID,
+			"files":       rollback.Restored,
+			"restored":    len(rollback.Restored),
+			"changed":     len(rollback.Changed),
+			"unversioned": len(rollback.Unversioned),
+		},
+	}
+	if err := c.memoryStore.Store(ctx, mem); err != nil {
+		return rollback, fmt.Errorf("failed to store rollback: %w", err)
+	}
+	return rollback, nil
+}
+
+// restoreFile writes content to a file that still holds expected, keeping
+// its mode. It returns false for files that are gone or hold other content.
+func restoreFile(path, expected, content string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, nil
+	}
+	current, err := os.ReadFile(path)
+	if err != nil || string(current) != expected {
+		return false, nil
+	}
+	if err := os.WriteFile(path, []byte(content), info.Mode().Perm()); err != nil {
+		return false, fmt.Errorf("failed to restore %s: %w", path, err)
+	}
+	return true, nil
+}
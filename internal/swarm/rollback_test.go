@@ -0,0 +1,109 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+// fakeHistory keeps file versions in order of creation
+type fakeHistory struct {
+	versions []FileVersion
+}
+
+func (h *fakeHistory) TaskVersions(_ context.Context, taskID string) ([]FileVersion, error) {
+	var versions []FileVersion
+	for _, v := range h.versions {
+		if v.TaskID == taskID {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+func (h *fakeHistory) PreviousVersion(_ context.Context, path, version string) (FileVersion, bool, error) {
+	var previous FileVersion
+	found := false
+	for _, v := range h.versions {
+		if v.Version == version && v.Path == path {
+			return previous, found, nil
+		}
+		if v.Path == path {
+			previous, found = v, true
+		}
+	}
+	return FileVersion{}, false, nil
+}
+
+func (h *fakeHistory) CreateVersion(_ context.Context, sessionID, path, content string) error {
+	h.versions = append(h.versions, FileVersion{SessionID: sessionID, Path: path, Content: content, Version: "restored"})
+	return nil
+}
+
+func TestRollbackTask(t *testing.T) {
+	c, err := NewCoordinator(CoordinatorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cancelFunc()
+	ctx := context.Background()
+
+	if _, err := c.RollbackTask(ctx, "task-1"); !errors.Is(err, ErrNoFileHistory) {
+		t.Fatalf("rollback without history = %v, want ErrNoFileHistory", err)
+	}
+
+	dir := t.TempDir()
+	restored, changed, created := filepath.Join(dir, "a.go"), filepath.Join(dir, "b.go"), filepath.Join(dir, "c.go")
+	for path, content := range map[string]string{restored: "a2", changed: "edited by hand", created: "c1"} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := &fakeHistory{versions: []FileVersion{
+		{SessionID: "s", Path: restored, Content: "a0", Version: "initial"},
+		{SessionID: "s", Path: restored, Content: "a1", Version: "v1", AgentID: "docs", TaskID: "task-1"},
+		{SessionID: "s", Path: restored, Content: "a2", Version: "v2", AgentID: "docs", TaskID: "task-1"},
+		{SessionID: "s", Path: changed, Content: "b0", Version: "initial"},
+		{SessionID: "s", Path: changed, Content: "b1", Version: "v1", AgentID: "docs", TaskID: "task-1"},
+		{SessionID: "s", Path: created, Content: "c1", Version: "initial", AgentID: "docs", TaskID: "task-1"},
+	}}
+	c.UseFileHistory(files)
+
+	if _, err := c.RollbackTask(ctx, "task-2"); !errors.Is(err, ErrNoTaskChanges) {
+		t.Fatalf("rollback of a task without changes = %v, want ErrNoTaskChanges", err)
+	}
+
+	rollback, err := c.RollbackTask(ctx, "task-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rollback.Restored) != 1 || rollback.Restored[0] != restored ||
+		len(rollback.Changed) != 1 || rollback.Changed[0] != changed ||
+		len(rollback.Unversioned) != 1 || rollback.Unversioned[0] != created {
+		t.Fatalf("rollback = %+v, want a restored, b changed and c unversioned", rollback)
+	}
+	if content, _ := os.ReadFile(restored); string(content) != "a0" {
+		t.Errorf("a.go = %q, want its content from before the task", content)
+	}
+	if info, _ := os.Stat(restored); info.Mode().Perm() != 0o600 {
+		t.Errorf("a.go mode = %v, want it kept", info.Mode().Perm())
+	}
+	if content, _ := os.ReadFile(changed); string(content) != "edited by hand" {
+		t.Errorf("b.go = %q, want it left alone", content)
+	}
+	if last := files.versions[len(files.versions)-1]; last.Path != restored || last.Content != "a0" {
+		t.Errorf("last version = %+v, want the restored a.go", last)
+	}
+
+	memories, err := c.memoryStore.Query(ctx, memory.MemoryQuery{Type: memory.MemoryTypeEpisodic, Tags: []string{"rollback"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memories) != 1 || memories[0].Metadata["task_id"] != "task-1" || memories[0].Metadata["agent_id"] != "docs" {
+		t.Errorf("rollback memories = %+v, want one of task-1 by docs", memories)
+	}
+}
@@ -0,0 +1,353 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RuleDefinition is the YAML form of a rule
+type RuleDefinition struct {
+	ID          string              `yaml:"id"`
+	Name        string              `yaml:"name,omitempty"`
+	Description string              `yaml:"description,omitempty"`
+	Priority    int                 `yaml:"priority,omitempty"`
+	Enabled     bool                `yaml:"enabled"`
+	Tags        []string            `yaml:"tags,omitempty"`
+	Condition   ConditionDefinition `yaml:"condition"`
+	Actions     []ActionDefinition  `yaml:"actions"`
+}
+
+// ConditionDefinition describes a condition. Type is one of "always",
+// "event_type", "field", "memory" or "all". Field conditions compare with
+// "==", "!=", ">", "<", ">=", "<=" or "contains". Memory conditions compare
+// the number of memories matching the query the same way, except with
+// contains, and match any number above zero without an operator. All
+// conditions match when all of their conditions do.
+type ConditionDefinition struct {
+	Type       string                `yaml:"type"`
+	EventType  string                `yaml:"event_type,omitempty"`
+	Field      string                `yaml:"field,omitempty"`
+	Query      string                `yaml:"query,omitempty"`
+	Operator   string                `yaml:"operator,omitempty"`
+	Value      interface{}           `yaml:"value,omitempty"`
+	Conditions []ConditionDefinition `yaml:"conditions,omitempty"`
+}
+
+// ActionDefinition describes an action. Type is one of "log", "notify",
+// "submit_task" or "require_vote".
+type ActionDefinition struct {
+	Type    string `yaml:"type"`
+	Message string `yaml:"message,omitempty"`
+	Level   string `yaml:"level,omitempty"`
+	Title   string `yaml:"title,omitempty"`
+
+	// TaskType, Description and Priority describe the task of a
+	// submit_task action. IdempotencyKey, with event fields like the
+	// description, keeps the rule from queueing the same task repeatedly.
+	TaskType       string `yaml:"task_type,omitempty"`
+	Description    string `yaml:"description,omitempty"`
+	Priority       int    `yaml:"priority,omitempty"`
+	IdempotencyKey string `yaml:"idempotency_key,omitempty"`
+
+	// A require_vote action runs Actions once a vote of MinVoters on the
+	// Description passes within Timeout
+	MinVoters int                `yaml:"min_voters,omitempty"`
+	Timeout   time.Duration      `yaml:"timeout,omitempty"`
+	Actions   []ActionDefinition `yaml:"actions,omitempty"`
+}
+
+// BuildOptions supplies the runtime dependencies of conditions and actions
+type BuildOptions struct {
+	// NotificationSink receives notifications from notify actions
+	NotificationSink NotificationSink
+	// TaskSubmitter queues the tasks of submit_task actions
+	TaskSubmitter TaskSubmitter
+	// MemorySearcher counts the memories of memory conditions
+	MemorySearcher MemorySearcher
+	// VoteRequester holds the actions of require_vote actions for a vote
+	VoteRequester VoteRequester
+}
+
+// ParseRuleDefinitions parses a YAML document holding a single rule or a
+// list of rules
+func ParseRuleDefinitions(data []byte) ([]RuleDefinition, error) {
+	var list []RuleDefinition
+	if err := yaml.Unmarshal(data, &list); err == nil {
+		return list, nil
+	}
+
+	var single RuleDefinition
+	if err := yaml.Unmarshal(data, &single); err != nil {
+		return nil, fmt.Errorf("invalid rule definition: %w", err)
+	}
+	return []RuleDefinition{single}, nil
+}
+
+// LoadDefinitionDir parses every .yaml and .yml file in dir, in name order
+func LoadDefinitionDir(dir string) ([]RuleDefinition, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var defs []RuleDefinition
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rule file: %w", err)
+		}
+		parsed, err := ParseRuleDefinitions(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		defs = append(defs, parsed...)
+	}
+	return defs, nil
+}
+
+// MarshalRuleDefinition renders a rule definition as YAML
+func MarshalRuleDefinition(def RuleDefinition) ([]byte, error) {
+	return yaml.Marshal(def)
+}
+
+// Validate checks that the definition can be built into a rule
+func (d RuleDefinition) Validate() error {
+	_, err := d.Build(BuildOptions{})
+	return err
+}
+
+// Build creates the rule described by the definition
+func (d RuleDefinition) Build(opts BuildOptions) (Rule, error) {
+	if d.ID == "" {
+		return Rule{}, fmt.Errorf("rule ID cannot be empty")
+	}
+
+	condition, err := d.Condition.build(opts)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %s: %w", d.ID, err)
+	}
+
+	if len(d.Actions) == 0 {
+		return Rule{}, fmt.Errorf("rule %s: rule must have at least one action", d.ID)
+	}
+	actions := make([]Action, 0, len(d.Actions))
+	for i, def := range d.Actions {
+		action, err := def.build(opts)
+		if err != nil {
+			return Rule{}, fmt.Errorf("rule %s: action %d: %w", d.ID, i+1, err)
+		}
+		actions = append(actions, action)
+	}
+
+	name := d.Name
+	if name == "" {
+		name = d.ID
+	}
+	return Rule{
+		ID:          d.ID,
+		Name:        name,
+		Description: d.Description,
+		Priority:    d.Priority,
+		Enabled:     d.Enabled,
+		Condition:   condition,
+		Actions:     actions,
+		Tags:        d.Tags,
+	}, nil
+}
+
+func (d ConditionDefinition) build(opts BuildOptions) (Condition, error) {
+	switch d.Type {
+	case "always":
+		return &AlwaysCondition{}, nil
+	case "event_type":
+		if d.EventType == "" {
+			return nil, fmt.Errorf("event_type condition needs event_type")
+		}
+		return &EventTypeCondition{EventType: d.EventType}, nil
+	case "field":
+		if d.Field == "" {
+			return nil, fmt.Errorf("field condition needs field")
+		}
+		switch d.Operator {
+		case "==", "!=", ">", "<", ">=", "<=", "contains":
+		default:
+			return nil, fmt.Errorf("unknown operator: %s", d.Operator)
+		}
+		return &FieldCondition{Field: d.Field, Operator: d.Operator, Value: d.Value}, nil
+	case "memory":
+		if d.Query == "" {
+			return nil, fmt.Errorf("memory condition needs query")
+		}
+		switch d.Operator {
+		case "":
+		case "==", "!=", ">", "<", ">=", "<=":
+			if _, ok := toNumber(d.Value); !ok {
+				return nil, fmt.Errorf("memory condition compares with %v, not a number", d.Value)
+			}
+		default:
+			return nil, fmt.Errorf("unknown operator: %s", d.Operator)
+		}
+		return &MemoryCondition{Query: d.Query, Operator: d.Operator, Value: d.Value, Searcher: opts.MemorySearcher}, nil
+	case "all":
+		if len(d.Conditions) == 0 {
+			return nil, fmt.Errorf("all condition needs conditions")
+		}
+		conditions := make([]Condition, 0, len(d.Conditions))
+		for i, def := range d.Conditions {
+			condition, err := def.build(opts)
+			if err != nil {
+				return nil, fmt.Errorf("condition %d: %w", i+1, err)
+			}
+			conditions = append(conditions, condition)
+		}
+		return &AllCondition{Conditions: conditions}, nil
+	case "":
+		return nil, fmt.Errorf("rule must have a condition")
+	default:
+		return nil, fmt.Errorf("unknown condition type: %s", d.Type)
+	}
+}
+
+func (d ActionDefinition) build(opts BuildOptions) (Action, error) {
+	switch d.Type {
+	case "log":
+		return &LogAction{Message: d.Message}, nil
+	case "notify":
+		switch d.Level {
+		case "", "info", "success", "warn", "error":
+		default:
+			return nil, fmt.Errorf("unknown notification level: %s", d.Level)
+		}
+		return &NotifyAction{
+			Level:   d.Level,
+			Title:   d.Title,
+			Message: d.Message,
+			Sink:    opts.NotificationSink,
+		}, nil
+	case "submit_task":
+		if d.TaskType == "" {
+			return nil, fmt.Errorf("submit_task action needs task_type")
+		}
+		return &SubmitTaskAction{
+			TaskType:       d.TaskType,
+			Description:    d.Description,
+			Priority:       d.Priority,
+			IdempotencyKey: d.IdempotencyKey,
+			Submitter:      opts.TaskSubmitter,
+		}, nil
+	case "require_vote":
+		if d.Description == "" {
+			return nil, fmt.Errorf("require_vote action needs description")
+		}
+		if d.MinVoters < 0 || d.Timeout < 0 {
+			return nil, fmt.Errorf("require_vote action needs a positive min_voters and timeout")
+		}
+		if len(d.Actions) == 0 {
+			return nil, fmt.Errorf("require_vote action needs actions")
+		}
+		actions := make([]Action, 0, len(d.Actions))
+		for i, def := range d.Actions {
+			action, err := def.build(opts)
+			if err != nil {
+				return nil, fmt.Errorf("action %d: %w", i+1, err)
+			}
+			actions = append(actions, action)
+		}
+		return &RequireVoteAction{
+			Description: d.Description,
+			MinVoters:   d.MinVoters,
+			Timeout:     d.Timeout,
+			Actions:     actions,
+			Requester:   opts.VoteRequester,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown action type: %q", d.Type)
+	}
+}
+
+// DefinitionFromRule converts a rule back to its YAML form. It fails for
+// rules using conditions or actions that cannot be written as YAML, such
+// as callbacks.
+func DefinitionFromRule(rule *Rule) (RuleDefinition, error) {
+	def := RuleDefinition{
+		ID:          rule.ID,
+		Name:        rule.Name,
+		Description: rule.Description,
+		Priority:    rule.Priority,
+		Enabled:     rule.Enabled,
+		Tags:        rule.Tags,
+	}
+
+	condition, err := conditionDefinition(rule.Condition)
+	if err != nil {
+		return def, err
+	}
+	def.Condition = condition
+
+	def.Actions, err = actionDefinitions(rule.Actions)
+	return def, err
+}
+
+// actionDefinitions converts actions back to their YAML form
+func actionDefinitions(actions []Action) ([]ActionDefinition, error) {
+	var defs []ActionDefinition
+	for _, action := range actions {
+		switch a := action.(type) {
+		case *LogAction:
+			defs = append(defs, ActionDefinition{Type: "log", Message: a.Message})
+		case *NotifyAction:
+			defs = append(defs, ActionDefinition{Type: "notify", Level: a.Level, Title: a.Title, Message: a.Message})
+		case *SubmitTaskAction:
+			defs = append(defs, ActionDefinition{Type: "submit_task", TaskType: a.TaskType, Description: a.Description, Priority: a.Priority, IdempotencyKey: a.IdempotencyKey})
+		case *RequireVoteAction:
+			inner, err := actionDefinitions(a.Actions)
+			if err != nil {
+				return nil, err
+			}
+			defs = append(defs, ActionDefinition{Type: "require_vote", Description: a.Description, MinVoters: a.MinVoters, Timeout: a.Timeout, Actions: inner})
+		default:
+			return nil, fmt.Errorf("action %q cannot be edited as YAML", action.String())
+		}
+	}
+	return defs, nil
+}
+
+// conditionDefinition converts a condition back to its YAML form
+func conditionDefinition(condition Condition) (ConditionDefinition, error) {
+	switch c := condition.(type) {
+	case *AlwaysCondition:
+		return ConditionDefinition{Type: "always"}, nil
+	case *EventTypeCondition:
+		return ConditionDefinition{Type: "event_type", EventType: c.EventType}, nil
+	case *FieldCondition:
+		return ConditionDefinition{Type: "field", Field: c.Field, Operator: c.Operator, Value: c.Value}, nil
+	case *MemoryCondition:
+		return ConditionDefinition{Type: "memory", Query: c.Query, Operator: c.Operator, Value: c.Value}, nil
+	case *AllCondition:
+		def := ConditionDefinition{Type: "all"}
+		for _, sub := range c.Conditions {
+			subDef, err := conditionDefinition(sub)
+			if err != nil {
+				return def, err
+			}
+			def.Conditions = append(def.Conditions, subDef)
+		}
+		return def, nil
+	}
+	return ConditionDefinition{}, fmt.Errorf("condition %q cannot be edited as YAML", condition.String())
+}
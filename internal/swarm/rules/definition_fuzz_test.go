@@ -0,0 +1,147 @@
+package rules
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// discardSink drops notifications raised while fuzzing
+type discardSink struct{}
+
+func (discardSink) Notify(Notification) {}
+
+// discardSubmitter drops tasks submitted while fuzzing
+type discardSubmitter struct{}
+
+func (discardSubmitter) SubmitRuleTask(context.Context, RuleTask) error { return nil }
+
+// emptySearcher finds no memories while fuzzing
+type emptySearcher struct{}
+
+func (emptySearcher) CountMemories(context.Context, string) (int, error) { return 0, nil }
+
+// FuzzRuleDefinition parses a YAML rule, builds it and evaluates it against
+// an event decoded from YAML, so conditions compare arbitrary values. Fuzz
+// it with
+//
+//	go test -run='^$' -fuzz=FuzzRuleDefinition ./internal/swarm/rules/
+func FuzzRuleDefinition(f *testing.F) {
+	seeds := []struct{ rule, event string }{
+		{`
+id: disk
+condition: {type: field, field: used, operator: "==", value: 0.93}
+actions: [{type: notify, level: warn, title: Disk}]
+enabled: true
+`, `used: 0.93`},
+		{`
+- id: errors
+  enabled: true
+  condition: {type: event_type, event_type: log_entry}
+  actions: [{type: notify, level: error}]
+- id: always
+  enabled: true
+  condition: {type: always}
+  actions: [{type: notify}]
+`, `{}`},
+		{`
+id: lists
+enabled: true
+condition: {type: field, field: tags, operator: "!=", value: [a, b]}
+actions: [{type: notify}]
+`, `tags: [a, b]`},
+		{`
+id: maps
+enabled: true
+condition: {type: field, field: meta, operator: "==", value: {k: v}}
+actions: [{type: notify}]
+`, `meta: {k: v}`},
+		{`
+id: new-errors
+enabled: true
+condition: {type: field, field: new_errors, operator: ">=", value: 3}
+actions: [{type: submit_task, task_type: analysis, description: "Check ${path}", priority: 2}]
+`, `{new_errors: 4, path: main.go}`},
+		{`
+id: tagged
+enabled: true
+condition: {type: field, field: tags, operator: contains, value: lsp}
+actions: [{type: notify}]
+`, `tags: [lsp, diagnostics]`},
+		{`
+id: unfixed
+enabled: true
+condition:
+  type: all
+  conditions:
+    - {type: field, field: error, operator: "==", value: true}
+    - {type: memory, query: 'tag:fix "${signature}"', operator: "<", value: 1}
+actions: [{type: notify}]
+`, `{error: true, signature: disk}`},
+		{`id: broken
+condition: {type: field, operator: "~="}`, ``},
+		{`[`, `: :`},
+		{``, ``},
+	}
+	for _, seed := range seeds {
+		f.Add(seed.rule, seed.event)
+	}
+
+	f.Fuzz(func(t *testing.T, ruleYAML, eventYAML string) {
+		defs, err := ParseRuleDefinitions([]byte(ruleYAML))
+		if err != nil {
+			return
+		}
+		var event map[string]interface{}
+		_ = yaml.Unmarshal([]byte(eventYAML), &event)
+
+		engine := NewRuleEngine(RuleEngineConfig{})
+		ctx := context.Background()
+		for _, def := range defs {
+			if printsToStdout(def) {
+				continue
+			}
+			rule, err := def.Build(BuildOptions{NotificationSink: discardSink{}, TaskSubmitter: discardSubmitter{}, MemorySearcher: emptySearcher{}})
+			if err != nil {
+				if def.Validate() == nil {
+					t.Fatalf("Validate accepted a rule Build rejects: %v", err)
+				}
+				continue
+			}
+			rule.Enabled = true
+			_ = engine.AddRule(ctx, rule)
+
+			// Built rules must survive a round trip through YAML
+			back, err := DefinitionFromRule(&rule)
+			if err != nil {
+				t.Fatalf("rule built from YAML cannot be written back: %v", err)
+			}
+			if _, err := MarshalRuleDefinition(back); err != nil {
+				t.Fatalf("failed to marshal rule: %v", err)
+			}
+		}
+
+		ruleCtx := RuleContext{
+			AgentID:   "agent",
+			EventType: "log_entry",
+			EventData: event,
+			Timestamp: time.Now(),
+		}
+		if err := engine.EvaluateRules(ctx, ruleCtx); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// printsToStdout reports whether a rule has log actions, which print every
+// message and would flood the output of the fuzzing workers
+func printsToStdout(def RuleDefinition) bool {
+	for _, action := range def.Actions {
+		if action.Type == "log" {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,10 @@ package rules
 import (
 	"context"
 	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -45,13 +49,28 @@ type RuleContext struct {
 // RuleEngine manages and executes rules
 type RuleEngine struct {
 	rules      map[string]*Rule
+	// enabled are the enabled rules in evaluation order. It is replaced,
+	// never modified, so evaluations read it without copying.
+	enabled    []*Rule
 	mu         sync.RWMutex
 	middleware []RuleMiddleware
 	
-	// Rule execution history
+	// Rule execution history, a ring once it holds maxHistory executions
 	history    []RuleExecution
+	// oldest is the index of the oldest execution in a full history
+	oldest     int
 	historyMu  sync.RWMutex
 	maxHistory int
+	stats      map[string]*RuleStats
+}
+
+// RuleStats summarizes the executions of a rule
+type RuleStats struct {
+	Evaluations int
+	Fired       int
+	Failures    int
+	LastFired   time.Time
+	LastError   error
 }
 
 // RuleExecution records rule execution
@@ -89,24 +108,29 @@ func NewRuleEngine(config RuleEngineConfig) *RuleEngine {
 		middleware: make([]RuleMiddleware, 0),
 		history:    make([]RuleExecution, 0),
 		maxHistory: config.MaxHistory,
+		stats:      make(map[string]*RuleStats),
 	}
 }
 
 // AddRule registers a new rule
-func (re *RuleEngine) AddRule(rule Rule) error {
+func (re *RuleEngine) AddRule(ctx context.Context, rule Rule) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
 	re.mu.Lock()
 	defer re.mu.Unlock()
 	
 	if rule.ID == "" {
-		return fmt.Errorf("rule ID cannot be empty")
+		return fmt.Errorf("%w: rule ID cannot be empty", ErrInvalidRule)
 	}
 	
 	if rule.Condition == nil {
-		return fmt.Errorf("rule must have a condition")
+		return fmt.Errorf("%w: rule must have a condition", ErrInvalidRule)
 	}
 	
 	if len(rule.Actions) == 0 {
-		return fmt.Errorf("rule must have at least one action")
+		return fmt.Errorf("%w: rule must have at least one action", ErrInvalidRule)
 	}
 	
 	rule.UpdatedAt = time.Now()
@@ -115,36 +139,89 @@ func (re *RuleEngine) AddRule(rule Rule) error {
 	}
 	
 	re.rules[rule.ID] = &rule
+	re.sortEnabled()
 	return nil
 }
 
 // RemoveRule deletes a rule
-func (re *RuleEngine) RemoveRule(ruleID string) error {
+func (re *RuleEngine) RemoveRule(ctx context.Context, ruleID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
 	re.mu.Lock()
 	defer re.mu.Unlock()
 	
 	if _, exists := re.rules[ruleID]; !exists {
-		return fmt.Errorf("rule not found: %s", ruleID)
+		return fmt.Errorf("%w: %s", ErrRuleNotFound, ruleID)
 	}
 	
 	delete(re.rules, ruleID)
+	re.sortEnabled()
 	return nil
 }
 
 // UpdateRule modifies an existing rule
-func (re *RuleEngine) UpdateRule(rule Rule) error {
+func (re *RuleEngine) UpdateRule(ctx context.Context, rule Rule) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
 	re.mu.Lock()
 	defer re.mu.Unlock()
 	
 	if _, exists := re.rules[rule.ID]; !exists {
-		return fmt.Errorf("rule not found: %s", rule.ID)
+		return fmt.Errorf("%w: %s", ErrRuleNotFound, rule.ID)
 	}
 	
 	rule.UpdatedAt = time.Now()
 	re.rules[rule.ID] = &rule
+	re.sortEnabled()
 	return nil
 }
 
+// SetRuleEnabled enables or disables a rule
+func (re *RuleEngine) SetRuleEnabled(ctx context.Context, ruleID string, enabled bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	re.mu.Lock()
+	defer re.mu.Unlock()
+	
+	rule, exists := re.rules[ruleID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrRuleNotFound, ruleID)
+	}
+	
+	updated := *rule
+	updated.Enabled = enabled
+	updated.UpdatedAt = time.Now()
+	re.rules[ruleID] = &updated
+	re.sortEnabled()
+	return nil
+}
+
+// sortEnabled replaces the enabled rules after a change. It is called with
+// re.mu held.
+func (re *RuleEngine) sortEnabled() {
+	enabled := make([]*Rule, 0, len(re.rules))
+	for _, rule := range re.rules {
+		if rule.Enabled {
+			enabled = append(enabled, rule)
+		}
+	}
+	// Sort by priority (higher first), then by ID so that rules of equal
+	// priority always run in the same order
+	sort.Slice(enabled, func(i, j int) bool {
+		if enabled[i].Priority != enabled[j].Priority {
+			return enabled[i].Priority > enabled[j].Priority
+		}
+		return enabled[i].ID < enabled[j].ID
+	})
+	re.enabled = enabled
+}
+
 // GetRule retrieves a rule by ID
 func (re *RuleEngine) GetRule(ruleID string) (*Rule, error) {
 	re.mu.RLock()
@@ -152,7 +229,7 @@ func (re *RuleEngine) GetRule(ruleID string) (*Rule, error) {
 	
 	rule, exists := re.rules[ruleID]
 	if !exists {
-		return nil, fmt.Errorf("rule not found: %s", ruleID)
+		return nil, fmt.Errorf("%w: %s", ErrRuleNotFound, ruleID)
 	}
 	
 	return rule, nil
@@ -171,29 +248,19 @@ func (re *RuleEngine) GetAllRules() []*Rule {
 	return rules
 }
 
-// EvaluateRules evaluates all rules against a context
+// EvaluateRules evaluates all rules against a context. Cancelling ctx
+// stops evaluation before the next rule and returns the context's error.
 func (re *RuleEngine) EvaluateRules(ctx context.Context, ruleCtx RuleContext) error {
 	re.mu.RLock()
-	rules := make([]*Rule, 0, len(re.rules))
-	for _, rule := range re.rules {
-		if rule.Enabled {
-			rules = append(rules, rule)
-		}
-	}
+	rules, middleware := re.enabled, re.middleware
 	re.mu.RUnlock()
 	
-	// Sort by priority (higher first)
-	for i := 0; i < len(rules); i++ {
-		for j := i + 1; j < len(rules); j++ {
-			if rules[j].Priority > rules[i].Priority {
-				rules[i], rules[j] = rules[j], rules[i]
-			}
-		}
-	}
-	
 	// Evaluate each rule
 	for _, rule := range rules {
-		if err := re.evaluateRule(ctx, rule, ruleCtx); err != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := re.evaluateRule(ctx, rule, middleware, ruleCtx); err != nil {
 			// Log error but continue with other rules
 			continue
 		}
@@ -203,7 +270,7 @@ func (re *RuleEngine) EvaluateRules(ctx context.Context, ruleCtx RuleContext) er
 }
 
 // evaluateRule evaluates a single rule
-func (re *RuleEngine) evaluateRule(ctx context.Context, rule *Rule, ruleCtx RuleContext) error {
+func (re *RuleEngine) evaluateRule(ctx context.Context, rule *Rule, middleware []RuleMiddleware, ruleCtx RuleContext) error {
 	startTime := time.Now()
 	
 	execution := RuleExecution{
@@ -213,7 +280,7 @@ func (re *RuleEngine) evaluateRule(ctx context.Context, rule *Rule, ruleCtx Rule
 	}
 	
 	// Run middleware before
-	for _, mw := range re.middleware {
+	for _, mw := range middleware {
 		if err := mw.Before(ctx, rule, ruleCtx); err != nil {
 			execution.Error = err
 			re.recordExecution(execution)
@@ -238,19 +305,17 @@ func (re *RuleEngine) evaluateRule(ctx context.Context, rule *Rule, ruleCtx Rule
 	}
 	
 	// Execute actions
-	for _, action := range rule.Actions {
-		if err := action.Execute(ctx, ruleCtx); err != nil {
-			execution.Error = err
-			execution.Duration = time.Since(startTime)
-			re.recordExecution(execution)
-			
-			// Run middleware after (with error)
-			for _, mw := range re.middleware {
-				_ = mw.After(ctx, rule, ruleCtx, err)
-			}
-			
-			return err
+	if err := runActions(ctx, ruleCtx, rule.Actions); err != nil {
+		execution.Error = err
+		execution.Duration = time.Since(startTime)
+		re.recordExecution(execution)
+		
+		// Run middleware after (with error)
+		for _, mw := range middleware {
+			_ = mw.After(ctx, rule, ruleCtx, err)
 		}
+		
+		return err
 	}
 	
 	execution.Success = true
@@ -258,18 +323,40 @@ func (re *RuleEngine) evaluateRule(ctx context.Context, rule *Rule, ruleCtx Rule
 	re.recordExecution(execution)
 	
 	// Run middleware after (success)
-	for _, mw := range re.middleware {
+	for _, mw := range middleware {
 		_ = mw.After(ctx, rule, ruleCtx, nil)
 	}
 	
 	return nil
 }
 
+// runActions executes actions in order until one fails
+func runActions(ctx context.Context, ruleCtx RuleContext, actions []Action) error {
+	for i := 0; i < len(actions); i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var err error
+		if n := submitTaskRun(actions[i:]); n > 1 {
+			// Tasks submitted together are queued all or none
+			err = submitTasks(ctx, ruleCtx, actions[i:i+n])
+			i += n - 1
+		} else {
+			err = actions[i].Execute(ctx, ruleCtx)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // AddMiddleware adds middleware to the engine
 func (re *RuleEngine) AddMiddleware(mw RuleMiddleware) {
 	re.mu.Lock()
 	defer re.mu.Unlock()
-	re.middleware = append(re.middleware, mw)
+	// Replaced like the enabled rules, evaluations may be reading it
+	re.middleware = append(re.middleware[:len(re.middleware):len(re.middleware)], mw)
 }
 
 // recordExecution saves rule execution history
@@ -277,12 +364,34 @@ func (re *RuleEngine) recordExecution(execution RuleExecution) {
 	re.historyMu.Lock()
 	defer re.historyMu.Unlock()
 	
-	re.history = append(re.history, execution)
+	if len(re.history) < re.maxHistory {
+		re.history = append(re.history, execution)
+	} else {
+		// Overwrite the oldest execution instead of copying the history
+		re.history[re.oldest] = execution
+		re.oldest = (re.oldest + 1) % len(re.history)
+	}
 	
-	// Trim history if needed
-	if len(re.history) > re.maxHistory {
-		re.history = re.history[len(re.history)-re.maxHistory:]
+	stats, exists := re.stats[execution.RuleID]
+	if !exists {
+		stats = &RuleStats{}
+		re.stats[execution.RuleID] = stats
+	}
+	stats.Evaluations++
+	if execution.Fired {
+		stats.Fired++
+		stats.LastFired = execution.Timestamp
 	}
+	if execution.Error != nil {
+		stats.Failures++
+		stats.LastError = execution.Error
+	}
+}
+
+// executionAt returns the i-th oldest execution. It is called with
+// re.historyMu held.
+func (re *RuleEngine) executionAt(i int) *RuleExecution {
+	return &re.history[(re.oldest+i)%len(re.history)]
 }
 
 // GetHistory returns rule execution history
@@ -295,11 +404,49 @@ func (re *RuleEngine) GetHistory(limit int) []RuleExecution {
 	}
 	
 	history := make([]RuleExecution, limit)
-	copy(history, re.history[len(re.history)-limit:])
+	for i := range history {
+		history[i] = *re.executionAt(len(re.history) - limit + i)
+	}
 	
 	return history
 }
 
+// GetRuleHistory returns the most recent executions of a rule, oldest first
+func (re *RuleEngine) GetRuleHistory(ruleID string, limit int) []RuleExecution {
+	re.historyMu.RLock()
+	defer re.historyMu.RUnlock()
+	
+	var history []RuleExecution
+	for i := len(re.history) - 1; i >= 0; i-- {
+		execution := re.executionAt(i)
+		if execution.RuleID != ruleID {
+			continue
+		}
+		history = append(history, *execution)
+		if limit > 0 && len(history) >= limit {
+			break
+		}
+	}
+	
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return history
+}
+
+// GetRuleStats returns execution statistics for every rule that has been
+// evaluated
+func (re *RuleEngine) GetRuleStats() map[string]RuleStats {
+	re.historyMu.RLock()
+	defer re.historyMu.RUnlock()
+	
+	stats := make(map[string]RuleStats, len(re.stats))
+	for id, s := range re.stats {
+		stats[id] = *s
+	}
+	return stats
+}
+
 // Common condition implementations
 
 // AlwaysCondition always evaluates to true
@@ -339,21 +486,189 @@ func (fc *FieldCondition) Evaluate(ctx context.Context, context RuleContext) (bo
 		return false, nil
 	}
 	
+	// DeepEqual, as == panics on lists and maps decoded from YAML or JSON
 	switch fc.Operator {
 	case "==":
-		return fieldValue == fc.Value, nil
+		return reflect.DeepEqual(fieldValue, fc.Value), nil
 	case "!=":
-		return fieldValue != fc.Value, nil
-	// Add more operators as needed
+		return !reflect.DeepEqual(fieldValue, fc.Value), nil
+	case ">", "<", ">=", "<=":
+		// Values that are not numbers never match
+		a, ok := toNumber(fieldValue)
+		if !ok {
+			return false, nil
+		}
+		b, ok := toNumber(fc.Value)
+		if !ok {
+			return false, nil
+		}
+		return compareNumbers(a, fc.Operator, b), nil
+	case "contains":
+		return containsValue(fieldValue, fc.Value), nil
 	default:
 		return false, fmt.Errorf("unknown operator: %s", fc.Operator)
 	}
 }
 
+// toNumber converts the numbers found in event data and YAML to float64
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// compareNumbers compares two numbers with "==", "!=", ">", "<", ">=" or
+// "<="
+func compareNumbers(a float64, operator string, b float64) bool {
+	switch operator {
+	case "==":
+		return a == b
+	case "!=":
+		return a != b
+	case ">":
+		return a > b
+	case "<":
+		return a < b
+	case ">=":
+		return a >= b
+	default:
+		return a <= b
+	}
+}
+
+// containsValue reports whether a string contains a substring, or a list an
+// element
+func containsValue(container, v interface{}) bool {
+	switch c := container.(type) {
+	case string:
+		s, ok := v.(string)
+		return ok && strings.Contains(c, s)
+	case []interface{}:
+		for _, element := range c {
+			if reflect.DeepEqual(element, v) {
+				return true
+			}
+		}
+	case []string:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		for _, element := range c {
+			if element == s {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (fc *FieldCondition) String() string {
 	return fmt.Sprintf("%s %s %v", fc.Field, fc.Operator, fc.Value)
 }
 
+// AllCondition matches when all of its conditions do. They are evaluated in
+// order until one does not match, so cheap ones like event types go first.
+type AllCondition struct {
+	Conditions []Condition
+}
+
+func (ac *AllCondition) Evaluate(ctx context.Context, context RuleContext) (bool, error) {
+	for _, condition := range ac.Conditions {
+		matched, err := condition.Evaluate(ctx, context)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (ac *AllCondition) String() string {
+	parts := make([]string, len(ac.Conditions))
+	for i, condition := range ac.Conditions {
+		parts[i] = condition.String()
+	}
+	return "(" + strings.Join(parts, " && ") + ")"
+}
+
+// MemorySearcher counts the memories matching a query, e.g. the swarm
+// coordinator searching its memory store
+type MemorySearcher interface {
+	CountMemories(ctx context.Context, query string) (int, error)
+}
+
+// MemoryCondition compares how many memories match a query with a count,
+// e.g. to act on errors only while no fix for them is remembered. The query
+// has the syntax of memory.ParseQuery, and references to event fields in
+// it, like ${signature}, are replaced with their values. Double quotes are
+// left out of the values, so quoted references stay one phrase. Without an
+// operator the condition matches when any memory does.
+type MemoryCondition struct {
+	Query    string
+	Operator string // "", "==", "!=", ">", "<", ">=", "<="
+	Value    interface{}
+	Searcher MemorySearcher
+}
+
+func (mc *MemoryCondition) Evaluate(ctx context.Context, context RuleContext) (bool, error) {
+	if mc.Searcher == nil {
+		return false, fmt.Errorf("memory condition has no searcher")
+	}
+	count, err := mc.Searcher.CountMemories(ctx, mc.query(context))
+	if err != nil {
+		return false, err
+	}
+	if mc.Operator == "" {
+		return count > 0, nil
+	}
+	value, ok := toNumber(mc.Value)
+	if !ok {
+		return false, fmt.Errorf("memory condition compares with %v, not a number", mc.Value)
+	}
+	return compareNumbers(float64(count), mc.Operator, value), nil
+}
+
+// query returns the query of the condition for an event
+func (mc *MemoryCondition) query(context RuleContext) string {
+	return os.Expand(mc.Query, func(field string) string {
+		if value, ok := context.EventData[field]; ok {
+			return strings.ReplaceAll(fmt.Sprint(value), `"`, "")
+		}
+		return ""
+	})
+}
+
+func (mc *MemoryCondition) String() string {
+	if mc.Operator == "" {
+		return fmt.Sprintf("memories(%s) > 0", mc.Query)
+	}
+	return fmt.Sprintf("memories(%s) %s %v", mc.Query, mc.Operator, mc.Value)
+}
+
 // LogAction logs a message
 type LogAction struct {
 	Message string
@@ -369,6 +684,212 @@ func (la *LogAction) String() string {
 	return fmt.Sprintf("log: %s", la.Message)
 }
 
+// Notification is a user facing message posted by a NotifyAction
+type Notification struct {
+	Level     string // "info", "warn" or "error"
+	Title     string
+	Message   string
+	AgentID   string
+	EventType string
+	Timestamp time.Time
+}
+
+// NotificationSink receives notifications, e.g. the TUI notification layer
+type NotificationSink interface {
+	Notify(notification Notification)
+}
+
+// NotifyAction posts a notification to a sink
+type NotifyAction struct {
+	Level   string
+	Title   string
+	Message string
+	Sink    NotificationSink
+}
+
+func (na *NotifyAction) Execute(ctx context.Context, context RuleContext) error {
+	if na.Sink == nil {
+		return fmt.Errorf("notify action has no sink")
+	}
+	level := na.Level
+	if level == "" {
+		level = "info"
+	}
+	na.Sink.Notify(Notification{
+		Level:     level,
+		Title:     na.Title,
+		Message:   na.Message,
+		AgentID:   context.AgentID,
+		EventType: context.EventType,
+		Timestamp: time.Now(),
+	})
+	return nil
+}
+
+func (na *NotifyAction) String() string {
+	return fmt.Sprintf("notify[%s]: %s", na.Level, na.Title)
+}
+
+// RuleTask is a task submitted by a SubmitTaskAction
+type RuleTask struct {
+	Type        string
+	Description string
+	Priority    int
+	// Input holds the data of the event that fired the rule
+	Input       map[string]interface{}
+	// IdempotencyKey coalesces the tasks the rule submits for the same
+	// event fields
+	IdempotencyKey string
+}
+
+// TaskSubmitter queues tasks for rules, e.g. the swarm coordinator
+type TaskSubmitter interface {
+	SubmitRuleTask(ctx context.Context, task RuleTask) error
+}
+
+// SubmitTaskAction submits a task. References to event fields in the
+// description and idempotency key, like ${path}, are replaced with their
+// values.
+type SubmitTaskAction struct {
+	TaskType    string
+	Description string
+	Priority    int
+	IdempotencyKey string
+	Submitter   TaskSubmitter
+}
+
+// BatchTaskSubmitter is implemented by task submitters that queue several
+// tasks at once, all or none. Consecutive submit_task actions of a rule
+// with the same such submitter are submitted together.
+type BatchTaskSubmitter interface {
+	TaskSubmitter
+	SubmitRuleTasks(ctx context.Context, tasks []RuleTask) error
+}
+
+func (sa *SubmitTaskAction) Execute(ctx context.Context, context RuleContext) error {
+	if sa.Submitter == nil {
+		return fmt.Errorf("submit_task action has no submitter")
+	}
+	return sa.Submitter.SubmitRuleTask(ctx, sa.task(context))
+}
+
+// task returns the task the action submits for an event
+func (sa *SubmitTaskAction) task(context RuleContext) RuleTask {
+	expand := func(field string) string {
+		if value, ok := context.EventData[field]; ok {
+			return fmt.Sprint(value)
+		}
+		return ""
+	}
+	return RuleTask{
+		Type:        sa.TaskType,
+		Description: os.Expand(sa.Description, expand),
+		Priority:    sa.Priority,
+		Input:       context.EventData,
+		IdempotencyKey: os.Expand(sa.IdempotencyKey, expand),
+	}
+}
+
+// submitTaskRun returns how many of the leading actions submit tasks to
+// the same batch submitter
+func submitTaskRun(actions []Action) int {
+	first, ok := actions[0].(*SubmitTaskAction)
+	if !ok {
+		return 0
+	}
+	// Submitters are compared below, which only works for comparable types
+	if _, ok := first.Submitter.(BatchTaskSubmitter); !ok || !reflect.TypeOf(first.Submitter).Comparable() {
+		return 1
+	}
+	n := 1
+	for _, action := range actions[1:] {
+		sa, ok := action.(*SubmitTaskAction)
+		if !ok || sa.Submitter != first.Submitter {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// submitTasks submits the tasks of a run of submit_task actions as one
+// batch
+func submitTasks(ctx context.Context, context RuleContext, actions []Action) error {
+	tasks := make([]RuleTask, len(actions))
+	for i, action := range actions {
+		tasks[i] = action.(*SubmitTaskAction).task(context)
+	}
+	submitter := actions[0].(*SubmitTaskAction).Submitter.(BatchTaskSubmitter)
+	return submitter.SubmitRuleTasks(ctx, tasks)
+}
+
+func (sa *SubmitTaskAction) String() string {
+	return fmt.Sprintf("submit_task[%s]: %s", sa.TaskType, sa.Description)
+}
+
+// VoteRequest asks for a vote on the actions of a rule
+type VoteRequest struct {
+	Description string
+	// MinVoters is how many votes decide, one if zero
+	MinVoters int
+	// Timeout is how long the vote stays open, a default of the requester
+	// if zero
+	Timeout   time.Duration
+	AgentID   string
+	EventType string
+	EventData map[string]interface{}
+}
+
+// VoteRequester holds actions of rules until a vote approves them, e.g. the
+// swarm coordinator. RequestVote opens the vote and returns without waiting
+// for it; run is called once the vote passes, and never if it fails or
+// times out.
+type VoteRequester interface {
+	RequestVote(ctx context.Context, request VoteRequest, run func(ctx context.Context) error) error
+}
+
+// RequireVoteAction runs its actions only after a vote approves them, so
+// rules can automate risky steps without taking them alone. The rule does
+// not wait for the vote. References to event fields in the description,
+// like ${path}, are replaced with their values.
+type RequireVoteAction struct {
+	Description string
+	MinVoters   int
+	Timeout     time.Duration
+	Actions     []Action
+	Requester   VoteRequester
+}
+
+func (va *RequireVoteAction) Execute(ctx context.Context, ruleCtx RuleContext) error {
+	if va.Requester == nil {
+		return fmt.Errorf("require_vote action has no requester")
+	}
+	request := VoteRequest{
+		Description: os.Expand(va.Description, func(field string) string {
+			if value, ok := ruleCtx.EventData[field]; ok {
+				return fmt.Sprint(value)
+			}
+			return ""
+		}),
+		MinVoters: va.MinVoters,
+		Timeout:   va.Timeout,
+		AgentID:   ruleCtx.AgentID,
+		EventType: ruleCtx.EventType,
+		EventData: ruleCtx.EventData,
+	}
+	return va.Requester.RequestVote(ctx, request, func(ctx context.Context) error {
+		return runActions(ctx, ruleCtx, va.Actions)
+	})
+}
+
+func (va *RequireVoteAction) String() string {
+	parts := make([]string, len(va.Actions))
+	for i, action := range va.Actions {
+		parts[i] = action.String()
+	}
+	return fmt.Sprintf("require_vote: %s [%s]", va.Description, strings.Join(parts, "; "))
+}
+
 // CallbackAction executes a callback function
 type CallbackAction struct {
 	Callback func(context.Context, RuleContext) error
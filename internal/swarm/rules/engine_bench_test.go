@@ -0,0 +1,86 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/perfbudget"
+)
+
+const benchRules = 100
+
+// newBenchEngine returns an engine with benchRules enabled rules, one in
+// ten of which fire on "task_completed" events
+func newBenchEngine(b *testing.B) *RuleEngine {
+	b.Helper()
+	engine := NewRuleEngine(RuleEngineConfig{EnableHistory: true})
+	ctx := context.Background()
+	noop := &CallbackAction{Callback: func(context.Context, RuleContext) error { return nil }}
+	for i := 0; i < benchRules; i++ {
+		var condition Condition = &EventTypeCondition{EventType: fmt.Sprintf("event_%d", i)}
+		if i%10 == 0 {
+			condition = &FieldCondition{Field: "status", Operator: "==", Value: "completed"}
+		}
+		err := engine.AddRule(ctx, Rule{
+			ID:        fmt.Sprintf("rule-%d", i),
+			Name:      fmt.Sprintf("Rule %d", i),
+			Priority:  i % 7,
+			Enabled:   true,
+			Condition: condition,
+			Actions:   []Action{noop},
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	return engine
+}
+
+func BenchmarkEvaluateRules(b *testing.B) {
+	engine := newBenchEngine(b)
+	ctx := context.Background()
+	ruleCtx := RuleContext{
+		AgentID:   "agent-1",
+		EventType: "task_completed",
+		EventData: map[string]interface{}{"status": "completed"},
+		Timestamp: time.Now(),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := engine.EvaluateRules(ctx, ruleCtx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEvaluateRulesNoneFire evaluates an event no rule fires on, the
+// common case for log entries
+func BenchmarkEvaluateRulesNoneFire(b *testing.B) {
+	engine := newBenchEngine(b)
+	ctx := context.Background()
+	ruleCtx := RuleContext{
+		AgentID:   "agent-1",
+		EventType: "log_entry",
+		EventData: map[string]interface{}{"level": "INFO"},
+		Timestamp: time.Now(),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := engine.EvaluateRules(ctx, ruleCtx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestPerformanceBudgets(t *testing.T) {
+	perfbudget.Enforce(t,
+		perfbudget.Budget{Name: "EvaluateRules", Bench: BenchmarkEvaluateRules, MaxTime: 200 * time.Microsecond, MaxAllocs: 1},
+		perfbudget.Budget{Name: "EvaluateRulesNoneFire", Bench: BenchmarkEvaluateRulesNoneFire, MaxTime: 200 * time.Microsecond, MaxAllocs: 1},
+	)
+}
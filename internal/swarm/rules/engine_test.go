@@ -0,0 +1,277 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryWrapsAround(t *testing.T) {
+	engine := NewRuleEngine(RuleEngineConfig{MaxHistory: 3})
+	ctx := context.Background()
+	for _, id := range []string{"a", "b"} {
+		err := engine.AddRule(ctx, Rule{ID: id, Enabled: true, Condition: &AlwaysCondition{}, Actions: []Action{&CallbackAction{
+			Callback: func(context.Context, RuleContext) error { return nil },
+		}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Each event runs a, then b
+	for i := 0; i < 4; i++ {
+		if err := engine.EvaluateRules(ctx, RuleContext{EventType: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got []string
+	for _, execution := range engine.GetHistory(0) {
+		got = append(got, execution.RuleID+execution.Context.EventType)
+	}
+	if want := []string{"b2", "a3", "b3"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("GetHistory() = %v, want %v", got, want)
+	}
+	got = nil
+	for _, execution := range engine.GetRuleHistory("b", 0) {
+		got = append(got, execution.Context.EventType)
+	}
+	if want := []string{"2", "3"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("GetRuleHistory() = %v, want %v", got, want)
+	}
+	if stats := engine.GetRuleStats()["a"]; stats.Evaluations != 4 {
+		t.Errorf("Evaluations = %d, want 4 after the history wrapped", stats.Evaluations)
+	}
+}
+
+func TestDisabledRulesAreSkipped(t *testing.T) {
+	engine := NewRuleEngine(RuleEngineConfig{})
+	ctx := context.Background()
+	var order []string
+	for _, rule := range []Rule{{ID: "low", Priority: 1}, {ID: "high", Priority: 5}, {ID: "off", Priority: 9}} {
+		id := rule.ID
+		rule.Enabled = id != "off"
+		rule.Condition = &AlwaysCondition{}
+		rule.Actions = []Action{&CallbackAction{Callback: func(context.Context, RuleContext) error {
+			order = append(order, id)
+			return nil
+		}}}
+		if err := engine.AddRule(ctx, rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := engine.SetRuleEnabled(ctx, "low", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.SetRuleEnabled(ctx, "off", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.EvaluateRules(ctx, RuleContext{}); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(order) != "[off high]" {
+		t.Errorf("ran %v, want [off high]", order)
+	}
+}
+
+// batchSubmitter records the batches it is given
+type batchSubmitter struct {
+	batches *[][]string
+}
+
+func (s batchSubmitter) SubmitRuleTask(ctx context.Context, task RuleTask) error {
+	*s.batches = append(*s.batches, []string{task.Description})
+	return nil
+}
+
+func (s batchSubmitter) SubmitRuleTasks(ctx context.Context, tasks []RuleTask) error {
+	var batch []string
+	for _, task := range tasks {
+		batch = append(batch, task.Description)
+	}
+	*s.batches = append(*s.batches, batch)
+	return nil
+}
+
+func TestSubmitTasksTogether(t *testing.T) {
+	var batches [][]string
+	submitter := batchSubmitter{batches: &batches}
+	engine := NewRuleEngine(RuleEngineConfig{})
+	ctx := context.Background()
+	err := engine.AddRule(ctx, Rule{ID: "remediate", Enabled: true, Condition: &AlwaysCondition{}, Actions: []Action{
+		&SubmitTaskAction{TaskType: "analyze", Description: "analyze ${path}", Submitter: submitter},
+		&SubmitTaskAction{TaskType: "patch", Description: "patch ${path}", Submitter: submitter},
+		&LogAction{Message: "remediating"},
+		&SubmitTaskAction{TaskType: "test", Description: "test ${path}", Submitter: submitter},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.EvaluateRules(ctx, RuleContext{EventData: map[string]interface{}{"path": "main.go"}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[[analyze main.go patch main.go] [test main.go]]"; fmt.Sprint(batches) != want {
+		t.Errorf("batches = %v, want %v", batches, want)
+	}
+}
+
+// countSearcher finds a memory per word of the query past the third
+type countSearcher struct {
+	queries *[]string
+}
+
+func (s countSearcher) CountMemories(ctx context.Context, query string) (int, error) {
+	*s.queries = append(*s.queries, query)
+	return len(strings.Fields(query)) - 3, nil
+}
+
+func TestMemoryCondition(t *testing.T) {
+	defs, err := ParseRuleDefinitions([]byte(`
+id: unfixed
+enabled: true
+condition:
+  type: all
+  conditions:
+    - {type: event_type, event_type: log_entry}
+    - {type: memory, query: 'tag:fix after:1h "${signature}"', operator: "==", value: 0}
+actions: [{type: notify}]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var queries []string
+	rule, err := defs[0].Build(BuildOptions{MemorySearcher: countSearcher{queries: &queries}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		event RuleContext
+		want  bool
+	}{
+		{RuleContext{EventType: "log_entry", EventData: map[string]interface{}{"signature": "disk"}}, true},
+		{RuleContext{EventType: "log_entry", EventData: map[string]interface{}{"signature": `say "disk full"`}}, false},
+		{RuleContext{EventType: "task_failed", EventData: map[string]interface{}{"signature": "disk"}}, false},
+	} {
+		fired, err := rule.Condition.Evaluate(ctx, tc.event)
+		if err != nil || fired != tc.want {
+			t.Errorf("Evaluate(%v) = %v, %v, want %v", tc.event, fired, err, tc.want)
+		}
+	}
+	// The memory store is not searched for other events
+	if want := `[tag:fix after:1h "disk" tag:fix after:1h "say disk full"]`; fmt.Sprint(queries) != want {
+		t.Errorf("queries = %v, want %v", queries, want)
+	}
+
+	back, err := DefinitionFromRule(&rule)
+	if err != nil || len(back.Condition.Conditions) != 2 || back.Condition.Conditions[1].Query != defs[0].Condition.Conditions[1].Query {
+		t.Errorf("DefinitionFromRule() = %+v, %v, want the memory condition", back.Condition, err)
+	}
+
+	for _, condition := range []string{
+		`{type: memory}`,
+		`{type: memory, query: tag:fix, operator: contains, value: 1}`,
+		`{type: memory, query: tag:fix, operator: ">", value: many}`,
+		`{type: all}`,
+	} {
+		defs, err := ParseRuleDefinitions([]byte("{id: bad, condition: " + condition + ", actions: [{type: notify}]}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := defs[0].Validate(); err == nil {
+			t.Errorf("Validate(%s) = nil, want an error", condition)
+		}
+	}
+}
+
+// approvingRequester records vote requests and approves those with a
+// description
+type approvingRequester struct {
+	requests *[]VoteRequest
+}
+
+func (r approvingRequester) RequestVote(ctx context.Context, request VoteRequest, run func(context.Context) error) error {
+	*r.requests = append(*r.requests, request)
+	if request.Description == "Restart " {
+		return nil
+	}
+	return run(ctx)
+}
+
+func TestRequireVote(t *testing.T) {
+	defs, err := ParseRuleDefinitions([]byte(`
+id: restart
+enabled: true
+condition: {type: always}
+actions:
+  - type: require_vote
+    description: Restart ${service}
+    min_voters: 2
+    timeout: 10m
+    actions:
+      - {type: submit_task, task_type: restart, description: "restart ${service}"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var requests []VoteRequest
+	var batches [][]string
+	rule, err := defs[0].Build(BuildOptions{
+		TaskSubmitter: batchSubmitter{batches: &batches},
+		VoteRequester: approvingRequester{requests: &requests},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := NewRuleEngine(RuleEngineConfig{})
+	ctx := context.Background()
+	if err := engine.AddRule(ctx, rule); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, service := range []interface{}{"db", nil} {
+		data := map[string]interface{}{}
+		if service != nil {
+			data["service"] = service
+		}
+		if err := engine.EvaluateRules(ctx, RuleContext{EventData: data}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(requests) != 2 || requests[0].Description != "Restart db" || requests[0].MinVoters != 2 || requests[0].Timeout != 10*time.Minute {
+		t.Errorf("requests = %+v, want votes on restarting db and nothing", requests)
+	}
+	// Only the approved vote runs the actions
+	if want := "[[restart db]]"; fmt.Sprint(batches) != want {
+		t.Errorf("batches = %v, want %v", batches, want)
+	}
+
+	back, err := DefinitionFromRule(&rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := MarshalRuleDefinition(back)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseRuleDefinitions(data)
+	if err != nil || again[0].Actions[0].Timeout != 10*time.Minute || len(again[0].Actions[0].Actions) != 1 {
+		t.Errorf("round trip = %+v, %v, want the require_vote action", again, err)
+	}
+
+	for _, action := range []string{
+		`{type: require_vote, actions: [{type: notify}]}`,
+		`{type: require_vote, description: Restart}`,
+		`{type: require_vote, description: Restart, actions: [{type: dance}]}`,
+	} {
+		defs, err := ParseRuleDefinitions([]byte("{id: bad, condition: {type: always}, actions: [" + action + "]}"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := defs[0].Validate(); err == nil {
+			t.Errorf("Validate(%s) = nil, want an error", action)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package rules
+
+import "errors"
+
+// Common errors
+var (
+	// ErrRuleNotFound means no rule with the ID is registered
+	ErrRuleNotFound = errors.New("rule not found")
+	// ErrRuleExists means a rule with the same ID is already registered
+	ErrRuleExists = errors.New("rule already exists")
+	// ErrInvalidRule means a rule is missing its ID, condition or actions
+	ErrInvalidRule = errors.New("invalid rule")
+)
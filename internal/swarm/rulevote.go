@@ -0,0 +1,115 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/rules"
+	"github.com/opencode-ai/opencode/internal/swarm/voting"
+)
+
+// DefaultRuleVoteTimeout is how long a vote on the actions of a rule stays
+// open unless the rule says otherwise
+const DefaultRuleVoteTimeout = 5 * time.Minute
+
+// VoteTagRule tags the votes require_vote actions of rules open
+const VoteTagRule = "rule"
+
+// ruleVoteRequester opens the votes of require_vote actions. Anyone may
+// vote, the user in the vote review and agents through the API, and the
+// actions run once the majority of the first MinVoters votes approves them.
+// Votes that are not decided in time expire, rejecting them. Votes are only
+// opened while the swarm runs, which waits for their actions when stopping.
+type ruleVoteRequester struct {
+	coordinator *Coordinator
+}
+
+func (r ruleVoteRequester) RequestVote(ctx context.Context, request rules.VoteRequest, run func(ctx context.Context) error) error {
+	c := r.coordinator
+	timeout := request.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRuleVoteTimeout
+	}
+	minVoters := request.MinVoters
+	if minVoters <= 0 {
+		minVoters = 1
+	}
+
+	proposal := voting.VoteProposal{
+		Description: request.Description,
+		ProposedBy:  "rules",
+		Context: map[string]interface{}{
+			"agent": request.AgentID,
+			"event": request.EventType,
+			"data":  request.EventData,
+		},
+		Tags:     []string{VoteTagRule},
+		Deadline: c.clock.Now().Add(timeout),
+	}
+
+	// Stop waits for the goroutine only if it is added before stopping
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return ErrCoordinatorNotRunning
+	}
+	c.wg.Add(1)
+	c.mu.Unlock()
+
+	session, err := c.votingSystem.CreateVoteSession(proposal, voting.VoteTypeMajority, minVoters, nil)
+	if err != nil {
+		c.wg.Done()
+		return err
+	}
+	c.timeline.record(TimelineVoteOpened, session.ID, proposal.Description, map[string]interface{}{
+		"event":  request.EventType,
+		"voters": minVoters,
+	})
+
+	go func() {
+		defer c.wg.Done()
+		waitCtx, cancel := context.WithTimeout(c.ctx, timeout)
+		defer cancel()
+		result, err := c.votingSystem.WaitForResult(waitCtx, session.ID)
+		if err != nil {
+			// Close the session, unless it was decided meanwhile, so that
+			// it is no longer listed as open nor takes votes
+			result, err = c.votingSystem.Expire(session.ID)
+			if errors.Is(err, voting.ErrSessionCompleted) {
+				err = nil
+			}
+		}
+		if err != nil || result.Expired {
+			c.timeline.record(TimelineVoteDecided, session.ID, "Rule actions not approved in time", map[string]interface{}{
+				"event":   request.EventType,
+				"expired": true,
+			})
+			return
+		}
+
+		decision := "rejected"
+		if result.Decision {
+			decision = "approved"
+		}
+		c.timeline.record(TimelineVoteDecided, session.ID, fmt.Sprintf("Rule actions %s", decision), map[string]interface{}{
+			"event":    request.EventType,
+			"approved": result.Decision,
+			"yes":      result.YesVotes,
+			"no":       result.NoVotes,
+			"vetoed":   result.Vetoed,
+			"vetoedBy": result.VetoedBy,
+		})
+		if !result.Decision {
+			return
+		}
+		if err := run(c.ctx); err != nil {
+			c.timeline.record(TimelineAlert, session.ID, "Approved rule actions failed: "+err.Error(), map[string]interface{}{
+				"severity": "error",
+				"event":    request.EventType,
+			})
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,131 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/rules"
+	"github.com/opencode-ai/opencode/internal/swarm/voting"
+)
+
+func TestRuleVote(t *testing.T) {
+	c, err := NewCoordinator(CoordinatorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	ran := make(chan string, 2)
+	ctx := context.Background()
+	err = c.GetRuleEngine().AddRule(ctx, rules.Rule{
+		ID:        "restart",
+		Enabled:   true,
+		Condition: &rules.AlwaysCondition{},
+		Actions: []rules.Action{&rules.RequireVoteAction{
+			Description: "Restart ${service}",
+			Timeout:     time.Second,
+			Requester:   ruleVoteRequester{c},
+			Actions: []rules.Action{&rules.CallbackAction{Callback: func(ctx context.Context, ruleCtx rules.RuleContext) error {
+				ran <- ruleCtx.EventData["service"].(string)
+				return nil
+			}}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	evaluate := func(service string) *voting.VoteSession {
+		t.Helper()
+		if err := c.GetRuleEngine().EvaluateRules(ctx, rules.RuleContext{EventData: map[string]interface{}{"service": service}}); err != nil {
+			t.Fatal(err)
+		}
+		sessions := c.GetVotingSystem().ListSessions(voting.SessionFilter{Status: voting.SessionOpen, Tag: VoteTagRule})
+		if len(sessions) != 1 || sessions[0].Proposal.Description != "Restart "+service {
+			t.Fatalf("open rule votes = %+v, want the vote on %s", sessions, service)
+		}
+		return sessions[0]
+	}
+
+	// Rejected votes keep the actions from running
+	session := evaluate("db")
+	if err := c.GetVotingSystem().CastVote(session.ID, voting.Vote{AgentID: voting.HumanVoterID, Decision: false}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Approved ones run them
+	session = evaluate("cache")
+	if err := c.GetVotingSystem().CastVote(session.ID, voting.Vote{AgentID: voting.HumanVoterID, Decision: true}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case service := <-ran:
+		if service != "cache" {
+			t.Errorf("ran the actions for %s, want cache", service)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("approved actions did not run")
+	}
+
+	// Votes nobody decides in time expire and reject them
+	session = evaluate("queue")
+	select {
+	case service := <-ran:
+		t.Errorf("ran the actions for %s without a vote", service)
+	case <-time.After(1500 * time.Millisecond):
+	}
+	if result, err := c.GetVotingSystem().GetVoteResult(session.ID); err != nil || !result.Expired || result.Decision {
+		t.Errorf("result of the undecided vote = %+v, %v, want it expired", result, err)
+	}
+	if err := c.GetVotingSystem().CastVote(session.ID, voting.Vote{AgentID: voting.HumanVoterID, Decision: true}); !errors.Is(err, voting.ErrSessionCompleted) {
+		t.Errorf("late vote: %v, want ErrSessionCompleted", err)
+	}
+
+	// A stopped swarm opens no more votes
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	err = ruleVoteRequester{c}.RequestVote(ctx, rules.VoteRequest{Description: "Restart web"}, func(ctx context.Context) error { return nil })
+	if !errors.Is(err, ErrCoordinatorNotRunning) {
+		t.Errorf("vote after stopping: %v, want ErrCoordinatorNotRunning", err)
+	}
+}
+
+func TestRuleVoteWhileStopping(t *testing.T) {
+	c, err := NewCoordinator(CoordinatorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Votes are requested while Stop waits for the goroutines of the swarm
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			request := rules.VoteRequest{Description: "Restart", Timeout: time.Minute}
+			for {
+				err := ruleVoteRequester{c}.RequestVote(context.Background(), request, func(ctx context.Context) error { return nil })
+				if errors.Is(err, ErrCoordinatorNotRunning) {
+					return
+				}
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	if open := c.GetVotingSystem().ListSessions(voting.SessionFilter{Status: voting.SessionOpen, Tag: VoteTagRule}); len(open) != 0 {
+		t.Errorf("%d rule votes still open after stopping, want them expired", len(open))
+	}
+}
@@ -0,0 +1,96 @@
+package swarm
+
+import (
+	"fmt"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/health"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+// securityAlertSeverity is the severity from which new security findings
+// raise health alerts
+const securityAlertSeverity = 7.0
+
+// securityComponent is the component of the health alerts of security
+// findings
+const securityComponent = "security"
+
+// storeSecurityFindings remembers the findings of a security audit as facts
+// tagged "security" and raises a health alert for each severe finding not
+// known before. Findings that the audit no longer reports are forgotten,
+// except vulnerabilities when the database could not be reached.
+func (c *Coordinator) storeSecurityFindings(result *agent.TaskResult, report *agent.SecurityReport) {
+	current := make(map[string]bool, len(report.Findings))
+	for _, finding := range report.Findings {
+		id := securityMemoryID(finding)
+		current[id] = true
+		_, err := c.memoryStore.Retrieve(c.ctx, id)
+		known := err == nil
+		mem := memory.Memory{
+			ID:       id,
+			Type:     memory.MemoryTypeSemantic,
+			Content:  finding.String(),
+			Tags:     []string{"security", finding.Kind},
+			Priority: securityPriority(finding.Severity),
+			Metadata: map[string]interface{}{
+				"task_id":  result.TaskID,
+				"kind":     finding.Kind,
+				"id":       finding.ID,
+				"path":     finding.Path,
+				"line":     finding.Line,
+				"package":  finding.Package,
+				"version":  finding.Version,
+				"severity": finding.Severity,
+			},
+		}
+		_ = c.memoryStore.Store(c.ctx, mem)
+
+		if !known && finding.Severity >= securityAlertSeverity {
+			c.healthMonitor.RaiseAlert(health.HealthCheck{
+				ComponentID: securityComponent,
+				Status:      health.HealthStatusUnhealthy,
+				Score:       1 - finding.Severity/10,
+				Message:     finding.String(),
+				Details: map[string]interface{}{
+					"task_id":  result.TaskID,
+					"agent_id": result.AgentID,
+					"severity": finding.Severity,
+				},
+			})
+		}
+	}
+
+	stored, err := c.memoryStore.Query(c.ctx, memory.MemoryQuery{Type: memory.MemoryTypeSemantic, Tags: []string{"security"}})
+	if err != nil {
+		return
+	}
+	for _, mem := range stored {
+		kind, _ := mem.Metadata["kind"].(string)
+		if current[mem.ID] || (kind == agent.SecurityFindingVulnerability && report.DatabaseError != "") {
+			continue
+		}
+		_ = c.memoryStore.Delete(c.ctx, mem.ID)
+	}
+}
+
+// securityMemoryID identifies a finding across audits
+func securityMemoryID(finding agent.SecurityFinding) string {
+	if finding.Kind == agent.SecurityFindingVulnerability {
+		return fmt.Sprintf("security:%s:%s@%s", finding.ID, finding.Package, finding.Version)
+	}
+	return fmt.Sprintf("security:%s:%s:%d", finding.ID, finding.Path, finding.Line)
+}
+
+// securityPriority ranks findings in memory by their severity
+func securityPriority(severity float64) memory.MemoryPriority {
+	switch {
+	case severity >= 9:
+		return memory.PriorityCritical
+	case severity >= securityAlertSeverity:
+		return memory.PriorityHigh
+	case severity >= 4:
+		return memory.PriorityNormal
+	}
+	return memory.PriorityLow
+}
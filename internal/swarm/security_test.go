@@ -0,0 +1,68 @@
+package swarm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+)
+
+func TestStoreSecurityFindings(t *testing.T) {
+	s, err := Open(FileConfig{Agents: []AgentFileConfig{
+		{ID: "security", Type: string(agent.AgentTypeSecurity), Options: map[string]string{"dir": t.TempDir(), "database": "off"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := s.Subscribe(ctx)
+
+	key := agent.SecurityFinding{Kind: agent.SecurityFindingPattern, ID: "aws-access-key", Path: "main.go", Line: 3, Summary: "an AWS access key is hardcoded", Severity: 9}
+	hash := agent.SecurityFinding{Kind: agent.SecurityFindingPattern, ID: "weak-hash", Path: "sum.go", Line: 8, Summary: "a weak hash function is used", Severity: 3}
+	vuln := agent.SecurityFinding{Kind: agent.SecurityFindingVulnerability, ID: "GO-2023-2102", Path: "go.sum", Package: "golang.org/x/net", Version: "0.17.0", Summary: "HTTP/2 rapid reset", Severity: 8}
+	audit := func(report *agent.SecurityReport) []Memory {
+		s.coordinator.storeSecurityFindings(&agent.TaskResult{TaskID: "audit", AgentID: "security", Success: true}, report)
+		memories, err := s.Query(ctx, MemoryQuery{Type: MemoryTypeSemantic, Tags: []string{"security"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return memories
+	}
+	alerts := func() []string {
+		var messages []string
+		for {
+			select {
+			case event := <-events:
+				if event.Payload.Type == TimelineAlert {
+					messages = append(messages, event.Payload.Summary)
+				}
+			case <-time.After(50 * time.Millisecond):
+				return messages
+			}
+		}
+	}
+
+	if memories := audit(&agent.SecurityReport{Findings: []agent.SecurityFinding{key, vuln, hash}}); len(memories) != 3 {
+		t.Fatalf("memories = %+v", memories)
+	}
+	if got := alerts(); len(got) != 2 || got[0] != key.String() || got[1] != vuln.String() {
+		t.Errorf("alerts = %q, want the two severe findings", got)
+	}
+
+	// Known findings are not alerted again, fixed ones are forgotten but
+	// vulnerabilities are kept while the database fails
+	memories := audit(&agent.SecurityReport{Findings: []agent.SecurityFinding{key}, DatabaseError: "offline"})
+	if len(memories) != 2 {
+		t.Errorf("memories after the weak hash was fixed = %+v", memories)
+	}
+	if got := alerts(); len(got) != 0 {
+		t.Errorf("alerts = %q, want none", got)
+	}
+	if memories := audit(&agent.SecurityReport{}); len(memories) != 0 {
+		t.Errorf("memories after a clean audit = %+v", memories)
+	}
+}
@@ -0,0 +1,221 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/locks"
+)
+
+// shadowPermissions are the only permissions shadow agents keep. Edits they
+// propose are recorded, never applied; commands, memory writes, votes and
+// messages would change the swarm.
+var shadowPermissions = []agent.Permission{agent.PermissionReadMemory, agent.PermissionWriteFiles}
+
+// shadowConfig limits the permissions of a shadow agent's configuration to
+// the shadowPermissions it would otherwise have
+func shadowConfig(cfg agent.AgentConfig) agent.AgentConfig {
+	permissions := cfg.Permissions
+	if permissions == nil {
+		permissions = agent.RolePermissions(cfg.Type)
+	}
+	kept := []agent.Permission{}
+	for _, p := range permissions {
+		if slices.Contains(shadowPermissions, p) {
+			kept = append(kept, p)
+		}
+	}
+	cfg.Permissions = kept
+	return cfg
+}
+
+// ShadowResult is what a shadow agent made of a copy of a task, compared to
+// the result of the agent that ran it
+type ShadowResult struct {
+	AgentID  string                 `json:"agentId"`
+	Success  bool                   `json:"success"`
+	Output   map[string]interface{} `json:"output,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+	Duration time.Duration          `json:"duration"`
+	// Metadata is what the shadow reported along with its result, e.g.
+	// its model and tokens
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	// SameOutcome is whether the shadow succeeded or failed like the task
+	SameOutcome bool `json:"sameOutcome"`
+	// SameOutput is whether its output equals the output of the task
+	SameOutput bool `json:"sameOutput"`
+	// DryRun is set when the shadow only reported the commands or changes
+	// it would make. Its made-up result is not compared with the task's.
+	DryRun     bool      `json:"dryRun,omitempty"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// ShadowStats sums up the results of a shadow agent
+type ShadowStats struct {
+	Tasks       int
+	Succeeded   int
+	SameOutcome int
+	SameOutput  int
+	// Duration is the total time the shadow took
+	Duration time.Duration
+	// DryRuns counts the tasks the shadow only dry-ran, left out of the
+	// counts above
+	DryRuns int
+}
+
+// shadowStats counts the results of the shadow agents by agent ID
+type shadowStats struct {
+	mu    sync.Mutex
+	stats map[string]ShadowStats
+}
+
+func newShadowStats() *shadowStats {
+	return &shadowStats{stats: make(map[string]ShadowStats)}
+}
+
+func (s *shadowStats) add(result ShadowResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := s.stats[result.AgentID]
+	if result.DryRun {
+		stats.DryRuns++
+		s.stats[result.AgentID] = stats
+		return
+	}
+	stats.Tasks++
+	if result.Success {
+		stats.Succeeded++
+	}
+	if result.SameOutcome {
+		stats.SameOutcome++
+	}
+	if result.SameOutput {
+		stats.SameOutput++
+	}
+	stats.Duration += result.Duration
+	s.stats[result.AgentID] = stats
+}
+
+func (s *shadowStats) snapshot() map[string]ShadowStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.stats) == 0 {
+		return nil
+	}
+	return maps.Clone(s.stats)
+}
+
+// RegisterShadowAgent registers an agent in shadow mode with the
+// shadowPermissions of its role, see agent.Registry.RegisterShadowAgent
+func (c *Coordinator) RegisterShadowAgent(ag agent.Agent) error {
+	if err := c.registry.RegisterShadowAgent(ag); err != nil {
+		return err
+	}
+	c.grants.set(shadowConfig(agent.AgentConfig{ID: ag.GetID(), Type: ag.GetType()}))
+	return nil
+}
+
+// ShadowStats returns the sums of the results of the shadow agents, by
+// agent ID
+func (c *Coordinator) ShadowStats() map[string]ShadowStats {
+	return c.shadowStats.snapshot()
+}
+
+// runShadows hands copies of a finished task to the idle shadow agents that
+// can handle it and records their results with the task
+func (c *Coordinator) runShadows(task agent.Task, result *agent.TaskResult) {
+	for _, shadow := range c.registry.ShadowAgentsForTask(task) {
+		if c.ctx.Err() != nil {
+			return
+		}
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.runShadow(shadow, task, result)
+		}()
+	}
+}
+
+// runShadow runs a copy of a task on a shadow agent, as a dry run so that
+// commands and pull requests it would cause are only reported. Results of
+// agents that skipped their work for it are recorded but not compared.
+func (c *Coordinator) runShadow(shadow agent.Agent, task agent.Task, primary *agent.TaskResult) {
+	copied := task
+	copied.ID = task.ID + "-shadow-" + shadow.GetID()
+	copied.Input = maps.Clone(task.Input)
+
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Minute)
+	defer cancel()
+	ctx = locks.WithOwner(ctx, c.locks, locks.Owner{TaskID: copied.ID, AgentID: shadow.GetID()})
+	ctx = agent.WithGrants(ctx, shadow.GetID(), c.grants.of(shadow))
+	ctx = agent.WithDryRun(ctx)
+
+	start := c.clock.Now()
+	result, err := shadow.ExecuteTask(ctx, copied)
+	c.locks.ReleaseAll(copied.ID)
+	if c.ctx.Err() != nil || errors.Is(err, agent.ErrAgentBusy) {
+		// A shadow busy with another copy sits this one out
+		return
+	}
+
+	shadowResult := ShadowResult{
+		AgentID:    shadow.GetID(),
+		Duration:   c.clock.Now().Sub(start),
+		FinishedAt: c.clock.Now(),
+	}
+	switch {
+	case err != nil:
+		shadowResult.Error = err.Error()
+	case result != nil:
+		shadowResult.Success = result.Success
+		shadowResult.Output = result.Output
+		shadowResult.Metadata = result.Metadata
+		shadowResult.DryRun = dryRunResult(result)
+		if result.Error != nil {
+			shadowResult.Error = result.Error.Error()
+		}
+	}
+	if !shadowResult.DryRun {
+		shadowResult.SameOutcome = shadowResult.Success == primary.Success
+		shadowResult.SameOutput = shadowResult.SameOutcome && reflect.DeepEqual(shadowResult.Output, primary.Output)
+	}
+
+	c.tasks.addShadow(task.ID, shadowResult)
+	c.shadowStats.add(shadowResult)
+
+	summary := fmt.Sprintf("Shadow %s dry-ran task %s of %s, not compared", shadow.GetID(), task.ID, primary.AgentID)
+	if !shadowResult.DryRun {
+		agreement := "disagreed with"
+		if shadowResult.SameOutcome {
+			agreement = "agreed with"
+		}
+		summary = fmt.Sprintf("Shadow %s %s %s on task %s", shadow.GetID(), agreement, primary.AgentID, task.ID)
+	}
+	c.timeline.record(TimelineShadow, task.ID, summary, map[string]interface{}{
+		"shadow":      shadow.GetID(),
+		"agent":       primary.AgentID,
+		"success":     shadowResult.Success,
+		"sameOutcome": shadowResult.SameOutcome,
+		"sameOutput":  shadowResult.SameOutput,
+		"dryRun":      shadowResult.DryRun,
+		"duration":    shadowResult.Duration.String(),
+	})
+}
+
+// dryRunResult reports whether an agent skipped the work of a task for a
+// dry run, as the executor and tester say in their metadata and the GitHub
+// agent in its output
+func dryRunResult(result *agent.TaskResult) bool {
+	if dryRun, _ := result.Metadata["dry_run"].(bool); dryRun {
+		return true
+	}
+	dryRun, _ := result.Output["dry_run"].(bool)
+	return dryRun
+}
@@ -0,0 +1,158 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+)
+
+// refusingAgent fails every task
+type refusingAgent struct {
+	*agent.BaseAgent
+}
+
+func (a *refusingAgent) CanHandleTask(task agent.Task) bool {
+	return true
+}
+
+func (a *refusingAgent) ExecuteTask(ctx context.Context, task agent.Task) (*agent.TaskResult, error) {
+	return &agent.TaskResult{TaskID: task.ID, Error: errors.New("refused"), AgentID: a.GetID(), CompletedAt: time.Now()}, nil
+}
+
+func TestShadowAgents(t *testing.T) {
+	c, err := NewCoordinator(CoordinatorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cancelFunc()
+
+	start := func(ag agent.Agent) {
+		t.Helper()
+		if err := ag.Start(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = ag.Stop() })
+	}
+	primary := &echoAgent{BaseAgent: agent.NewBaseAgent(agent.AgentConfig{ID: "primary", Type: agent.AgentTypeExecutor})}
+	twin := &echoAgent{BaseAgent: agent.NewBaseAgent(agent.AgentConfig{ID: "twin", Type: agent.AgentTypeExecutor})}
+	refuser := &refusingAgent{BaseAgent: agent.NewBaseAgent(agent.AgentConfig{ID: "refuser", Type: agent.AgentTypeExecutor})}
+	start(primary)
+	start(twin)
+	start(refuser)
+	if err := c.GetRegistry().RegisterAgent(primary); err != nil {
+		t.Fatal(err)
+	}
+	for _, shadow := range []agent.Agent{twin, refuser} {
+		if err := c.RegisterShadowAgent(shadow); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Shadows are never picked, vote or run commands
+	task := agent.Task{ID: "task-1", Type: string(agent.AgentTypeExecutor), Description: "echo"}
+	if agents := c.registry.FindAgentsForTask(task); len(agents) != 1 || agents[0] != agent.Agent(primary) {
+		t.Fatalf("agents for task = %d, want only the primary", len(agents))
+	}
+	if voters := c.grants.voters([]agent.Agent{twin, refuser}); len(voters) != 0 {
+		t.Errorf("%d shadows may vote, want none", len(voters))
+	}
+	if c.grants.of(twin).Allows(agent.PermissionRunCommands) {
+		t.Error("shadow may run commands")
+	}
+
+	if _, _, err := c.tasks.enqueue(task); err != nil {
+		t.Fatal(err)
+	}
+	next, _ := c.tasks.next()
+	c.executeTask(primary, next)
+	c.wg.Wait()
+
+	record, err := c.tasks.get(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.State != TaskStateCompleted || record.AgentID != "primary" {
+		t.Fatalf("task %s by %s, want completed by the primary", record.State, record.AgentID)
+	}
+	shadows := make(map[string]ShadowResult)
+	for _, shadow := range record.Shadows {
+		shadows[shadow.AgentID] = shadow
+	}
+	if twin := shadows["twin"]; !twin.Success || !twin.SameOutput {
+		t.Errorf("twin = %+v, want the same output", twin)
+	}
+	if refuser := shadows["refuser"]; refuser.Success || refuser.SameOutcome || refuser.Error != "refused" {
+		t.Errorf("refuser = %+v, want a disagreeing failure", refuser)
+	}
+
+	stats := c.ShadowStats()
+	if stats["twin"].SameOutput != 1 || stats["refuser"].Tasks != 1 || stats["refuser"].SameOutcome != 0 {
+		t.Errorf("shadow stats = %+v", stats)
+	}
+	if events := c.Timeline(TimelineFilter{Types: []TimelineEventType{TimelineShadow}}); len(events) != 2 {
+		t.Errorf("%d shadow events, want 2", len(events))
+	}
+}
+
+func TestShadowExecutorNeverRunsCommands(t *testing.T) {
+	c, err := NewCoordinator(CoordinatorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cancelFunc()
+
+	dir := t.TempDir()
+	shadow, err := agent.New(agent.AgentConfig{ID: "shadow", Type: agent.AgentTypeExecutor, ScratchDir: t.TempDir(), CustomConfig: map[string]interface{}{
+		"dir": dir,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shadow.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer shadow.Stop()
+	if err := c.RegisterShadowAgent(shadow); err != nil {
+		t.Fatal(err)
+	}
+	// Even a shadow granted the commands of its role only reports them
+	c.grants.set(agent.AgentConfig{ID: shadow.GetID(), Type: agent.AgentTypeExecutor})
+
+	task := agent.Task{ID: "task-1", Type: agent.TaskTypeCommand, Input: map[string]interface{}{"command": "touch ran"}}
+	if _, _, err := c.tasks.enqueue(task); err != nil {
+		t.Fatal(err)
+	}
+	c.runShadow(shadow, task, &agent.TaskResult{TaskID: task.ID, Success: true})
+
+	if _, err := os.Stat(filepath.Join(dir, "ran")); !os.IsNotExist(err) {
+		t.Fatalf("shadow ran its command: %v", err)
+	}
+	record, err := c.tasks.get(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Shadows) != 1 {
+		t.Fatalf("%d shadow results, want 1", len(record.Shadows))
+	}
+	result := record.Shadows[0]
+	output, _ := result.Output["command"].(*agent.CommandOutput)
+	if !result.Success || output == nil || !output.DryRun {
+		t.Errorf("shadow result = %+v, want a successful dry run", result)
+	}
+
+	// The made-up success of a dry run is not compared with the task's
+	if !result.DryRun || result.SameOutcome || result.SameOutput {
+		t.Errorf("shadow result = %+v, want a dry run left uncompared", result)
+	}
+	if stats := c.ShadowStats()["shadow"]; stats.DryRuns != 1 || stats.Tasks != 0 {
+		t.Errorf("shadow stats = %+v, want 1 dry run and no compared task", stats)
+	}
+	if comparisons := c.Comparisons(time.Time{}, time.Time{}); len(comparisons) != 0 {
+		t.Errorf("comparisons = %+v, want none of a dry run", comparisons)
+	}
+}
@@ -0,0 +1,203 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/health"
+)
+
+// The tests in this file exercise concurrent start, stop and submit paths.
+// They are most useful under the race detector:
+//
+//	go test -race -run Shutdown ./internal/swarm/...
+
+// slowAgent takes a moment per task, so tasks are still running when the
+// swarm stops, and fails every other task to exercise the health monitor
+type slowAgent struct {
+	*agent.BaseAgent
+	mu    sync.Mutex
+	tasks int
+}
+
+func newSlowAgent(id string) *slowAgent {
+	return &slowAgent{BaseAgent: agent.NewBaseAgent(agent.AgentConfig{
+		ID:                  id,
+		Type:                agent.AgentType("slow"),
+		HealthCheckInterval: time.Millisecond,
+	})}
+}
+
+func (a *slowAgent) CanHandleTask(task agent.Task) bool {
+	return true
+}
+
+func (a *slowAgent) ExecuteTask(ctx context.Context, task agent.Task) (*agent.TaskResult, error) {
+	a.mu.Lock()
+	a.tasks++
+	fail := a.tasks%2 == 0
+	a.mu.Unlock()
+
+	select {
+	case <-time.After(time.Millisecond):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if fail {
+		return nil, fmt.Errorf("task %s failed", task.ID)
+	}
+	return &agent.TaskResult{TaskID: task.ID, Success: true, AgentID: a.GetID(), CompletedAt: time.Now()}, nil
+}
+
+func newShutdownCoordinator(t *testing.T, agents int, votingThreshold float64) *Coordinator {
+	t.Helper()
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(logFile, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := NewCoordinator(CoordinatorConfig{
+		SwarmConfig:           agent.SwarmConfig{VotingThreshold: votingThreshold},
+		HealthConfig:          health.HealthMonitorConfig{CheckInterval: time.Millisecond},
+		LogPaths:              []string{logFile},
+		ConsolidationInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < agents; i++ {
+		if err := c.GetRegistry().RegisterAgent(newSlowAgent(fmt.Sprintf("slow-%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	go appendLines(t, logFile, c.ctx)
+	return c
+}
+
+// appendLines writes to a log file until ctx ends, so log entries are in
+// flight during shutdown
+func appendLines(t *testing.T, path string, ctx context.Context) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer f.Close()
+	for i := 0; ctx.Err() == nil; i++ {
+		fmt.Fprintf(f, "line %d\n", i)
+		time.Sleep(100 * time.Microsecond)
+	}
+}
+
+func TestShutdownWhileSubmitting(t *testing.T) {
+	for _, votingThreshold := range []float64{0, 0.5} {
+		t.Run(fmt.Sprintf("voting=%v", votingThreshold), func(t *testing.T) {
+			c := newShutdownCoordinator(t, 3, votingThreshold)
+			if err := c.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					for j := 0; ; j++ {
+						err := c.SubmitTask(context.Background(), agent.Task{
+							Type:        "work",
+							Description: fmt.Sprintf("task %d-%d", i, j),
+						})
+						if err != nil {
+							return
+						}
+						_ = c.GetSystemStatus()
+						_ = c.ListTasks()
+					}
+				}(i)
+			}
+
+			time.Sleep(50 * time.Millisecond)
+			if err := c.Stop(); err != nil {
+				t.Fatal(err)
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func TestShutdownConcurrentStops(t *testing.T) {
+	c := newShutdownCoordinator(t, 2, 0)
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		_ = c.SubmitTask(context.Background(), agent.Task{Type: "work"})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Stop(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := c.SubmitTask(context.Background(), agent.Task{Type: "work"}); err == nil {
+		t.Error("SubmitTask succeeded after Stop")
+	}
+	if err := c.Start(); err == nil {
+		t.Error("Start succeeded after Stop")
+	}
+}
+
+func TestShutdownDuringStart(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		c := newShutdownCoordinator(t, 2, 0)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = c.Start()
+		}()
+		if err := c.Stop(); err != nil {
+			t.Fatal(err)
+		}
+		<-done
+		if err := c.Stop(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestShutdownWhileReconfiguring(t *testing.T) {
+	c := newShutdownCoordinator(t, 1, 0)
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; c.ctx.Err() == nil; i++ {
+			_ = c.setLogPaths([]string{filepath.Join(dir, fmt.Sprintf("%d-*.log", i%3))})
+			c.setVotingThreshold(float64(i%2) / 2)
+			c.healthMonitor.SetAlertThreshold(0.9)
+		}
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+}
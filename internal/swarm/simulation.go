@@ -0,0 +1,562 @@
+package swarm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+	"github.com/opencode-ai/opencode/internal/swarm/monitor"
+	"github.com/opencode-ai/opencode/internal/swarm/vault"
+	"github.com/opencode-ai/opencode/internal/swarm/voting"
+)
+
+// SimEventType identifies what a recorded event replays
+type SimEventType string
+
+const (
+	// SimEventLog is a line read by the log watcher
+	SimEventLog SimEventType = "log"
+	// SimEventTask is a submitted task
+	SimEventTask SimEventType = "task"
+	// SimEventResult is the outcome of a task. Simulated agents answer
+	// with it instead of doing the work.
+	SimEventResult SimEventType = "result"
+	// SimEventVote is a vote with all its ballots
+	SimEventVote SimEventType = "vote"
+)
+
+// SimEvent is one event of a recorded event stream. Streams are stored as
+// one JSON object per line.
+type SimEvent struct {
+	At     time.Time    `json:"at"`
+	Type   SimEventType `json:"type"`
+	Log    *SimLog      `json:"log,omitempty"`
+	Task   *SimTask     `json:"task,omitempty"`
+	Result *SimResult   `json:"result,omitempty"`
+	Vote   *SimVote     `json:"vote,omitempty"`
+}
+
+// SimLog is a recorded log entry
+type SimLog struct {
+	Level   string                 `json:"level,omitempty"`
+	Source  string                 `json:"source,omitempty"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	// Logged is when the line was logged, if not when it was read
+	Logged *time.Time `json:"logged,omitempty"`
+}
+
+// SimTask is a recorded task submission
+type SimTask struct {
+	ID             string                 `json:"id,omitempty"`
+	Type           string                 `json:"type"`
+	Description    string                 `json:"description,omitempty"`
+	Priority       int                    `json:"priority,omitempty"`
+	Input          map[string]interface{} `json:"input,omitempty"`
+	RequiresVote   bool                   `json:"requiresVote,omitempty"`
+	IdempotencyKey string                 `json:"idempotencyKey,omitempty"`
+}
+
+// SimResult is the recorded outcome of a task
+type SimResult struct {
+	TaskID   string                 `json:"taskId"`
+	Success  bool                   `json:"success"`
+	Output   map[string]interface{} `json:"output,omitempty"`
+	Error    string                 `json:"error,omitempty"`
+	Duration Duration               `json:"duration,omitempty"`
+}
+
+// SimVote is a vote held outside of task dispatch, e.g. on a proposal made
+// by the user
+type SimVote struct {
+	ID          string          `json:"id,omitempty"`
+	Description string          `json:"description"`
+	Type        voting.VoteType `json:"voteType,omitempty"`
+	Ballots     []SimBallot     `json:"ballots"`
+}
+
+// SimBallot is one vote cast in a SimVote
+type SimBallot struct {
+	Agent      string  `json:"agent"`
+	Decision   bool    `json:"decision"`
+	Confidence float64 `json:"confidence,omitempty"`
+	Reasoning  string  `json:"reasoning,omitempty"`
+}
+
+// validate checks that the event carries the payload its type needs
+func (e SimEvent) validate() error {
+	switch e.Type {
+	case SimEventLog:
+		if e.Log == nil {
+			return fmt.Errorf("log event without log")
+		}
+	case SimEventTask:
+		if e.Task == nil || e.Task.Type == "" {
+			return fmt.Errorf("task event without task type")
+		}
+	case SimEventResult:
+		if e.Result == nil || e.Result.TaskID == "" {
+			return fmt.Errorf("result event without task ID")
+		}
+	case SimEventVote:
+		if e.Vote == nil || len(e.Vote.Ballots) == 0 {
+			return fmt.Errorf("vote event without ballots")
+		}
+	default:
+		return fmt.Errorf("unknown event type %q", e.Type)
+	}
+	if e.At.IsZero() {
+		return fmt.Errorf("%s event without time", e.Type)
+	}
+	return nil
+}
+
+// logEntry returns the entry of a log event
+func (e SimEvent) logEntry() monitor.LogEntry {
+	level := e.Log.Level
+	if level == "" {
+		level = "INFO"
+	}
+	entry := monitor.LogEntry{
+		Timestamp:  e.At,
+		IngestedAt: e.At,
+		Level:      level,
+		Source:     e.Log.Source,
+		Message:    e.Log.Message,
+		Fields:     e.Log.Fields,
+	}
+	if e.Log.Logged != nil {
+		entry.Timestamp = *e.Log.Logged
+	}
+	return entry
+}
+
+// sealedEventPrefix starts the lines of encrypted events, followed by the
+// sealed JSON event in base64
+const sealedEventPrefix = "sealed:"
+
+// ReadSimEvents reads an event stream of one JSON event per line, ordered
+// by time. Blank lines are skipped.
+func ReadSimEvents(r io.Reader) ([]SimEvent, error) {
+	return ReadSealedSimEvents(r, nil)
+}
+
+// ReadSealedSimEvents reads an event stream like ReadSimEvents, decrypting
+// the events recorded with a sealer. Events in plaintext are refused.
+func ReadSealedSimEvents(r io.Reader, sealer *vault.Sealer) ([]SimEvent, error) {
+	var events []SimEvent
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := scanner.Bytes()
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		if encoded, ok := bytes.CutPrefix(data, []byte(sealedEventPrefix)); ok {
+			sealed, err := base64.StdEncoding.DecodeString(string(encoded))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if !vault.IsSealed(sealed) {
+				return nil, fmt.Errorf("line %d: %w", line, vault.ErrCorrupt)
+			}
+			if data, err = sealer.Open(sealed); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		} else if sealer != nil {
+			return nil, fmt.Errorf("line %d: %w", line, vault.ErrNotSealed)
+		}
+		var event SimEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := event.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].At.Before(events[j].At)
+	})
+	return events, nil
+}
+
+// SimRecorder writes the events a live swarm sees as a stream Simulate can
+// replay. It is safe for concurrent use.
+type SimRecorder struct {
+	mu     sync.Mutex
+	w      io.Writer
+	sealer *vault.Sealer
+	err    error
+}
+
+// NewSimRecorder creates a recorder writing to w
+func NewSimRecorder(w io.Writer) *SimRecorder {
+	return &SimRecorder{w: w}
+}
+
+// NewSealedSimRecorder creates a recorder encrypting every event it writes
+// to w. ReadSealedSimEvents reads them with the same master key.
+func NewSealedSimRecorder(w io.Writer, sealer *vault.Sealer) *SimRecorder {
+	return &SimRecorder{w: w, sealer: sealer}
+}
+
+// Record writes an event. After the first failed write nothing more is
+// written; Err returns the failure.
+func (r *SimRecorder) Record(event SimEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	if err := r.write(event); err != nil {
+		r.err = fmt.Errorf("failed to record event: %w", err)
+	}
+}
+
+func (r *SimRecorder) write(event SimEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if r.sealer != nil {
+		sealed, err := r.sealer.Seal(data)
+		if err != nil {
+			return err
+		}
+		data = []byte(sealedEventPrefix + base64.StdEncoding.EncodeToString(sealed))
+	}
+	_, err = r.w.Write(append(data, '\n'))
+	return err
+}
+
+// Err returns the first error writing events
+func (r *SimRecorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// record passes an event to the recorder, if any
+func (c *Coordinator) record(event SimEvent) {
+	if c.recorder != nil {
+		c.recorder.Record(event)
+	}
+}
+
+func simTask(task agent.Task) *SimTask {
+	return &SimTask{
+		ID:             task.ID,
+		Type:           task.Type,
+		Description:    task.Description,
+		Priority:       task.Priority,
+		Input:          task.Input,
+		RequiresVote:   task.RequiresVote,
+		IdempotencyKey: task.IdempotencyKey,
+	}
+}
+
+func simResult(result *agent.TaskResult) *SimResult {
+	r := &SimResult{
+		TaskID:   result.TaskID,
+		Success:  result.Success,
+		Output:   result.Output,
+		Duration: Duration(result.ExecutionTime),
+	}
+	if result.Error != nil {
+		r.Error = result.Error.Error()
+	}
+	return r
+}
+
+// SimulationReport is the outcome of a replay
+type SimulationReport struct {
+	Start  time.Time
+	End    time.Time
+	Events int
+	// Tasks are the submitted tasks, oldest first
+	Tasks []TaskRecord
+	// Timeline holds every decision the swarm made
+	Timeline []TimelineEvent
+	// Rules has the statistics of every evaluated rule, by rule ID
+	Rules []SimRuleStats
+	// Errors lists events the swarm rejected, e.g. tasks submitted to a
+	// full queue
+	Errors []string
+}
+
+// SimRuleStats counts how often a rule was evaluated and fired
+type SimRuleStats struct {
+	RuleID      string
+	Evaluations int
+	Fired       int
+	Failures    int
+}
+
+// Simulate replays an event stream against a new coordinator built from
+// config and reports what the swarm did. Time only moves with the events,
+// and the configured agents are replaced by agents that answer with the
+// recorded task results, so the same stream and configuration always give
+// the same report. Tasks without a recorded result succeed.
+//
+// Log paths, shell history, memory consolidation and recording are ignored,
+// and periodic health checks do not run on the simulated clock.
+func Simulate(ctx context.Context, config CoordinatorConfig, events []SimEvent) (*SimulationReport, error) {
+	start := time.Unix(0, 0).UTC()
+	if len(events) > 0 {
+		start = events[0].At
+	}
+	fake := clock.NewFake(start)
+
+	config.LogPaths = nil
+	config.ShellHistory = ""
+	config.ConsolidationInterval = 0
+	config.Recorder = nil
+	config.Clock = fake
+	// Periodic health checks would fire whenever the clock jumps, racing
+	// with the replay, so they keep to real time
+	config.HealthConfig.Clock = clock.Real
+
+	c, err := NewCoordinator(config)
+	if err != nil {
+		return nil, err
+	}
+	results := make(map[string]*SimResult)
+	for _, event := range events {
+		if event.Type == SimEventResult {
+			results[event.Result.TaskID] = event.Result
+		}
+	}
+	for _, cfg := range c.config.Agents {
+		if err := c.registry.RegisterAgent(newSimAgent(cfg, fake, results)); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	defer c.Stop()
+
+	report := &SimulationReport{Start: start, End: start, Events: len(events)}
+	tasks := 0
+	for i, event := range events {
+		if err := event.validate(); err != nil {
+			return nil, fmt.Errorf("event %d: %w", i+1, err)
+		}
+		fake.Set(event.At)
+
+		switch event.Type {
+		case SimEventLog:
+			c.handleLogEntry(event.logEntry())
+
+		case SimEventTask:
+			tasks++
+			task := agent.Task{
+				ID:             event.Task.ID,
+				Type:           event.Task.Type,
+				Description:    event.Task.Description,
+				Priority:       event.Task.Priority,
+				Input:          event.Task.Input,
+				CreatedAt:      event.At,
+				RequiresVote:   event.Task.RequiresVote,
+				IdempotencyKey: event.Task.IdempotencyKey,
+			}
+			if task.ID == "" {
+				// Generated IDs would differ between runs
+				task.ID = fmt.Sprintf("sim-task-%d", tasks)
+			}
+			if err := c.SubmitTask(ctx, task); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("event %d: %v", i+1, err))
+			}
+
+		case SimEventVote:
+			if err := c.simulateVote(event.Vote); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("event %d: %v", i+1, err))
+			}
+		}
+
+		if err := c.settle(ctx); err != nil {
+			return nil, err
+		}
+	}
+	report.End = fake.Now()
+
+	report.Tasks = c.ListTasks()
+	sort.SliceStable(report.Tasks, func(i, j int) bool {
+		return report.Tasks[i].SubmittedAt.Before(report.Tasks[j].SubmittedAt)
+	})
+	report.Timeline = numberVoteSessions(c.Timeline(TimelineFilter{}), report.Tasks)
+	for id, stats := range c.ruleEngine.GetRuleStats() {
+		report.Rules = append(report.Rules, SimRuleStats{
+			RuleID:      id,
+			Evaluations: stats.Evaluations,
+			Fired:       stats.Fired,
+			Failures:    stats.Failures,
+		})
+	}
+	sort.Slice(report.Rules, func(i, j int) bool {
+		return report.Rules[i].RuleID < report.Rules[j].RuleID
+	})
+	return report, nil
+}
+
+// numberVoteSessions replaces the random IDs of vote sessions in the
+// timeline and the tasks they decided by vote-1, vote-2 and so on, in order
+// of appearance in the timeline
+func numberVoteSessions(events []TimelineEvent, tasks []TaskRecord) []TimelineEvent {
+	ids := make(map[string]string)
+	for i, event := range events {
+		if event.Type != TimelineVoteOpened && event.Type != TimelineVoteDecided {
+			continue
+		}
+		id, ok := ids[event.Subject]
+		if !ok {
+			id = fmt.Sprintf("vote-%d", len(ids)+1)
+			ids[event.Subject] = id
+		}
+		events[i].Subject = id
+	}
+	for i, task := range tasks {
+		if id, ok := ids[task.VoteID]; ok {
+			tasks[i].VoteID = id
+		}
+	}
+	return events
+}
+
+// settle waits until no task is queued, running or being finished, so every
+// event is fully handled before the next one
+func (c *Coordinator) settle(ctx context.Context) error {
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	for c.tasks.activeCount() > 0 || c.finishing.Load() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.ctx.Done():
+			return ErrCoordinatorStopped
+		}
+	}
+	return nil
+}
+
+// simulateVote holds a recorded vote and adds it to the timeline
+func (c *Coordinator) simulateVote(v *SimVote) error {
+	voteType := v.Type
+	if voteType == "" {
+		voteType = voting.VoteTypeMajority
+	}
+	proposal := voting.VoteProposal{
+		ID:          v.ID,
+		Description: v.Description,
+		CreatedAt:   c.clock.Now(),
+		// Ballots are cast right away, the deadline only has to outlast
+		// the replay of this event
+		Deadline: c.clock.Now().Add(time.Minute),
+	}
+	session, err := c.votingSystem.CreateVoteSession(proposal, voteType, len(v.Ballots), nil)
+	if err != nil {
+		return err
+	}
+	c.timeline.record(TimelineVoteOpened, session.ID, v.Description, map[string]interface{}{
+		"voters": len(v.Ballots),
+	})
+	for _, ballot := range v.Ballots {
+		vote := voting.Vote{
+			AgentID:    ballot.Agent,
+			Decision:   ballot.Decision,
+			Confidence: ballot.Confidence,
+			Reasoning:  ballot.Reasoning,
+		}
+		if err := c.votingSystem.CastVote(session.ID, vote); err != nil {
+			return err
+		}
+	}
+	result, err := c.votingSystem.GetVoteResult(session.ID)
+	if err != nil {
+		return err
+	}
+	decision := "rejected"
+	if result.Decision {
+		decision = "approved"
+	}
+	c.timeline.record(TimelineVoteDecided, session.ID, fmt.Sprintf("%s %s", v.Description, decision), map[string]interface{}{
+		"approved": result.Decision,
+		"yes":      result.YesVotes,
+		"no":       result.NoVotes,
+	})
+	return nil
+}
+
+// simAgent stands in for a configured agent during a simulation. It
+// accepts the same tasks and answers them with their recorded results.
+type simAgent struct {
+	*agent.BaseAgent
+	clock   clock.Clock
+	results map[string]*SimResult
+}
+
+func newSimAgent(config agent.AgentConfig, clk clock.Clock, results map[string]*SimResult) *simAgent {
+	return &simAgent{
+		BaseAgent: agent.NewBaseAgent(config),
+		clock:     clk,
+		results:   results,
+	}
+}
+
+// CanHandleTask accepts tasks whose type is the agent type or one of its
+// capabilities, like the model-backed agents it replaces
+func (a *simAgent) CanHandleTask(task agent.Task) bool {
+	capabilities := a.GetCapabilities()
+	if len(capabilities) == 0 || task.Type == string(a.GetType()) {
+		return true
+	}
+	for _, capability := range capabilities {
+		if capability == task.Type {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *simAgent) ExecuteTask(ctx context.Context, task agent.Task) (*agent.TaskResult, error) {
+	result := &agent.TaskResult{
+		TaskID:      task.ID,
+		AgentID:     a.GetID(),
+		Success:     true,
+		CompletedAt: a.clock.Now(),
+	}
+	recorded, ok := a.results[task.ID]
+	if !ok {
+		a.RecordTask(0, true)
+		return result, nil
+	}
+
+	result.Success = recorded.Success
+	result.Output = recorded.Output
+	result.ExecutionTime = time.Duration(recorded.Duration)
+	a.RecordTask(result.ExecutionTime, result.Success)
+	if !recorded.Success {
+		reason := recorded.Error
+		if reason == "" {
+			reason = "recorded as failed"
+		}
+		result.Error = errors.New(reason)
+		return result, result.Error
+	}
+	return result, nil
+}
@@ -0,0 +1,227 @@
+package swarm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/vault"
+)
+
+const simEvents = `
+{"at":"2024-05-01T10:00:00Z","type":"log","log":{"level":"INFO","source":"app.log","message":"started"}}
+{"at":"2024-05-01T10:00:05Z","type":"task","task":{"id":"t1","type":"analysis","description":"Look for flaky tests"}}
+{"at":"2024-05-01T10:00:09Z","type":"result","result":{"taskId":"t1","success":true,"output":{"response":"none found"},"duration":"4s"}}
+{"at":"2024-05-01T10:01:00Z","type":"log","log":{"level":"ERROR","source":"app.log","message":"connection refused"}}
+{"at":"2024-05-01T10:01:30Z","type":"task","task":{"id":"t2","type":"testing","description":"Run the test suite"}}
+{"at":"2024-05-01T10:02:30Z","type":"result","result":{"taskId":"t2","success":false,"error":"2 tests failed","duration":"1m"}}
+
+{"at":"2024-05-01T10:03:00Z","type":"task","task":{"type":"analysis","description":"Unrecorded task"}}
+{"at":"2024-05-01T10:04:00Z","type":"vote","vote":{"description":"Roll back the release","ballots":[{"agent":"a","decision":true},{"agent":"b","decision":true},{"agent":"c","decision":false}]}}
+`
+
+const simRule = `
+id: errors
+enabled: true
+condition: {type: field, field: level, operator: "==", value: ERROR}
+actions: [{type: notify, level: error, title: Error logged}]
+`
+
+func simConfig(t *testing.T) CoordinatorConfig {
+	t.Helper()
+	rulesDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(rulesDir, "errors.yaml"), []byte(simRule), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return CoordinatorConfig{
+		SwarmConfig: agent.SwarmConfig{
+			VotingThreshold: 0.5,
+			Agents: []agent.AgentConfig{
+				{ID: "analyzer-1", Type: agent.AgentTypeAnalyzer, Capabilities: []string{"analysis"}},
+				{ID: "analyzer-2", Type: agent.AgentTypeAnalyzer, Capabilities: []string{"analysis"}},
+				{ID: "tester", Type: agent.AgentTypeTesting, Capabilities: []string{"testing"}},
+			},
+		},
+		RulesDir: rulesDir,
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	events, err := ReadSimEvents(strings.NewReader(simEvents))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := Simulate(context.Background(), simConfig(t), events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Errors) > 0 {
+		t.Errorf("errors: %v", report.Errors)
+	}
+	if got, want := report.End, events[len(events)-1].At; !got.Equal(want) {
+		t.Errorf("end = %v, want %v", got, want)
+	}
+
+	states := make(map[string]TaskState)
+	for _, task := range report.Tasks {
+		states[task.Task.ID] = task.State
+		if !task.SubmittedAt.Equal(task.Task.CreatedAt) {
+			t.Errorf("task %s submitted at %v, recorded at %v", task.Task.ID, task.SubmittedAt, task.Task.CreatedAt)
+		}
+	}
+	want := map[string]TaskState{
+		"t1":         TaskStateCompleted,
+		"t2":         TaskStateFailed,
+		"sim-task-3": TaskStateCompleted,
+	}
+	if !reflect.DeepEqual(states, want) {
+		t.Errorf("task states = %v, want %v", states, want)
+	}
+
+	var alerts, votes int
+	for _, event := range report.Timeline {
+		switch event.Type {
+		case TimelineAlert:
+			alerts++
+			if !event.Timestamp.Equal(events[3].At) {
+				t.Errorf("alert at %v, want the time of the error log", event.Timestamp)
+			}
+		case TimelineVoteDecided:
+			votes++
+		}
+	}
+	if alerts != 1 {
+		t.Errorf("%d alerts, want 1 for the error log", alerts)
+	}
+	// Two analysis tasks voted on by both analyzers, and the recorded vote
+	if votes != 3 {
+		t.Errorf("%d votes decided, want 3", votes)
+	}
+}
+
+func TestSimulateIsDeterministic(t *testing.T) {
+	events, err := ReadSimEvents(strings.NewReader(simEvents))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := Simulate(context.Background(), simConfig(t), events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		again, err := Simulate(context.Background(), simConfig(t), events)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(first, again) {
+			t.Fatalf("run %d differs:\n%+v\n%+v", i+2, first, again)
+		}
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	var recorded bytes.Buffer
+	c, err := NewCoordinator(CoordinatorConfig{Recorder: NewSimRecorder(&recorded)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.GetRegistry().RegisterAgent(newSlowAgent("slow")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	events, err := ReadSimEvents(strings.NewReader(simEvents))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.handleLogEntry(events[0].logEntry())
+	ctx := context.Background()
+	if err := c.SubmitTask(ctx, agent.Task{ID: "live", Type: "slow"}); err != nil {
+		t.Fatal(err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if _, err := c.GetTaskResult(waitCtx, "live"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := ReadSimEvents(&recorded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []SimEventType
+	for _, event := range replay {
+		types = append(types, event.Type)
+	}
+	if want := []SimEventType{SimEventLog, SimEventTask, SimEventResult}; !reflect.DeepEqual(types, want) {
+		t.Fatalf("recorded %v, want %v", types, want)
+	}
+
+	config := CoordinatorConfig{SwarmConfig: agent.SwarmConfig{
+		Agents: []agent.AgentConfig{{ID: "slow", Type: agent.AgentType("slow")}},
+	}}
+	report, err := Simulate(ctx, config, replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Tasks) != 1 || report.Tasks[0].State != TaskStateCompleted {
+		t.Fatalf("replayed tasks = %+v", report.Tasks)
+	}
+}
+
+func TestSealedRecording(t *testing.T) {
+	t.Setenv("TEST_SWARM_KEY", vault.EncodeKey(vault.GenerateKey()))
+	config := FileConfig{Encryption: EncryptionFileConfig{AtRest: true, KeyEnv: "TEST_SWARM_KEY"}}
+	sealer, err := config.Sealer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := ReadSimEvents(strings.NewReader(simEvents))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Recordings made before encryption was enabled are appended to, but
+	// only the sealed events are read back
+	plain := strings.TrimSpace(simEvents) + "\n"
+	recorded := bytes.NewBufferString(plain)
+	recorder := NewSealedSimRecorder(recorded, sealer)
+	for _, event := range events {
+		recorder.Record(event)
+	}
+	if err := recorder.Err(); err != nil {
+		t.Fatal(err)
+	}
+	sealedPart := recorded.String()[len(plain):]
+	if strings.Contains(sealedPart, "flaky") {
+		t.Errorf("recorded events in plaintext: %s", sealedPart)
+	}
+
+	if _, err := ReadSimEvents(bytes.NewReader(recorded.Bytes())); !errors.Is(err, vault.ErrNoKey) {
+		t.Errorf("ReadSimEvents() = %v, want ErrNoKey", err)
+	}
+	if _, err := ReadSealedSimEvents(bytes.NewReader(recorded.Bytes()), sealer); !errors.Is(err, vault.ErrNotSealed) {
+		t.Errorf("ReadSealedSimEvents() of a mixed recording = %v, want ErrNotSealed", err)
+	}
+	replay, err := ReadSealedSimEvents(strings.NewReader(sealedPart), sealer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replay) != len(events) {
+		t.Errorf("read %d events, want %d", len(replay), len(events))
+	}
+}
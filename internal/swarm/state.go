@@ -0,0 +1,98 @@
+package swarm
+
+import (
+	"maps"
+
+	"github.com/opencode-ai/opencode/internal/swarm/health"
+)
+
+// SwarmState is the state of the swarm as told by its timeline, the log of
+// every state transition of the coordinator: tasks accepted, assigned and
+// finished, votes opened and closed and alerts raised. The coordinator
+// applies each event as it records it, so the state also covers events the
+// timeline no longer keeps, and ReplayState rebuilds it from recorded
+// events.
+type SwarmState struct {
+	// Seq is the sequence number of the last event applied
+	Seq int64 `json:"seq"`
+	// Tasks are the states of the queued and running tasks, by task ID
+	Tasks map[string]TaskState `json:"tasks"`
+	// Finished counts the tasks that finished, by final state. Tasks
+	// retried and finished again are counted again.
+	Finished map[TaskState]int `json:"finished"`
+	// Votes are the descriptions of the open votes, by session ID
+	Votes map[string]string `json:"votes"`
+	// Alerts counts the alerts raised
+	Alerts int `json:"alerts"`
+	// CriticalAlerts counts the alerts raised with critical severity
+	CriticalAlerts int `json:"criticalAlerts"`
+}
+
+func newSwarmState() SwarmState {
+	return SwarmState{
+		Tasks:    make(map[string]TaskState),
+		Finished: make(map[TaskState]int),
+		Votes:    make(map[string]string),
+	}
+}
+
+// ReplayState folds events, oldest first, into the state they lead to
+func ReplayState(events []TimelineEvent) SwarmState {
+	state := newSwarmState()
+	for _, event := range events {
+		state.apply(event)
+	}
+	return state
+}
+
+// apply moves the state on by an event. Events that change no state only
+// advance Seq.
+func (s *SwarmState) apply(event TimelineEvent) {
+	s.Seq = event.Seq
+	switch event.Type {
+	case TimelineTaskSubmitted:
+		// A coalesced submission is answered by a task already queued
+		if coalesced, _ := event.Details["coalesced"].(bool); !coalesced {
+			s.Tasks[event.Subject] = TaskStateQueued
+		}
+	case TimelineTaskRequeued:
+		s.Tasks[event.Subject] = TaskStateQueued
+	case TimelineTaskStarted:
+		s.Tasks[event.Subject] = TaskStateRunning
+	case TimelineTaskFinished:
+		delete(s.Tasks, event.Subject)
+		state, _ := event.Details["state"].(string)
+		s.Finished[TaskState(state)]++
+	case TimelineVoteOpened:
+		s.Votes[event.Subject] = event.Summary
+	case TimelineVoteDecided:
+		delete(s.Votes, event.Subject)
+	case TimelineAlert:
+		s.Alerts++
+		if severity, _ := event.Details["severity"].(string); severity == string(health.AlertSeverityCritical) {
+			s.CriticalAlerts++
+		}
+	}
+}
+
+// Count returns how many of the tracked tasks are in a state
+func (s SwarmState) Count(state TaskState) int {
+	if state == TaskStateQueued || state == TaskStateRunning {
+		n := 0
+		for _, st := range s.Tasks {
+			if st == state {
+				n++
+			}
+		}
+		return n
+	}
+	return s.Finished[state]
+}
+
+// clone copies the state so it can be read without the timeline's lock
+func (s SwarmState) clone() SwarmState {
+	s.Tasks = maps.Clone(s.Tasks)
+	s.Finished = maps.Clone(s.Finished)
+	s.Votes = maps.Clone(s.Votes)
+	return s
+}
@@ -0,0 +1,63 @@
+package swarm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+)
+
+func TestSwarmState(t *testing.T) {
+	c, err := NewCoordinator(CoordinatorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cancelFunc()
+	ctx := context.Background()
+
+	for _, task := range []agent.Task{
+		{ID: "a", Type: "docs", IdempotencyKey: "docs"},
+		{ID: "b", Type: "docs", IdempotencyKey: "docs"},
+		{ID: "c", Type: "docs"},
+	} {
+		if err := c.SubmitTask(ctx, task); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.CancelTask("c"); err != nil {
+		t.Fatal(err)
+	}
+	c.timeline.record(TimelineTaskStarted, "a", "", nil)
+	c.timeline.record(TimelineVoteOpened, "vote-1", "Prune memories", nil)
+	c.timeline.record(TimelineAlert, "memory", "Memory is full", nil)
+
+	status := c.GetSystemStatus()
+	// b was coalesced into a
+	if status.QueuedTasks != 0 || status.RunningTasks != 1 || status.FinishedTasks[TaskStateCancelled] != 1 {
+		t.Errorf("tasks = %d queued, %d running, %v finished, want a running and c cancelled", status.QueuedTasks, status.RunningTasks, status.FinishedTasks)
+	}
+	if status.ActiveSessions != 1 || status.Alerts != 1 {
+		t.Errorf("status = %d votes and %d alerts, want 1 and 1", status.ActiveSessions, status.Alerts)
+	}
+
+	state := c.State()
+	if replayed := ReplayState(c.Timeline(TimelineFilter{})); !reflect.DeepEqual(replayed, state) {
+		t.Errorf("replayed state = %+v, want %+v", replayed, state)
+	}
+
+	// The state outlives the events the timeline drops
+	for range maxTimelineEvents {
+		c.timeline.record(TimelineTaskProgress, "a", "working", nil)
+	}
+	c.timeline.record(TimelineVoteDecided, "vote-1", "Prune approved", nil)
+	state = c.State()
+	if state.Tasks["a"] != TaskStateRunning || len(state.Votes) != 0 || state.Seq != status.LastEvent+maxTimelineEvents+1 {
+		t.Errorf("state = %+v after the timeline dropped events", state)
+	}
+
+	c.timeline.record(TimelineAlert, "memory", "Memory store unreachable", map[string]interface{}{"severity": "critical"})
+	if h := c.Health(); h.CriticalAlerts != 1 {
+		t.Errorf("health = %d critical alerts, want 1", h.CriticalAlerts)
+	}
+}
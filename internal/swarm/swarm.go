@@ -0,0 +1,188 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/artifact"
+	"github.com/opencode-ai/opencode/internal/swarm/locks"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+)
+
+// Types used by the Swarm API, so callers need not import the subpackages
+type (
+	Task         = agent.Task
+	TaskResult   = agent.TaskResult
+	FileEdit     = agent.FileEdit
+	Patch        = agent.Patch
+	Memory       = memory.Memory
+	MemoryQuery  = memory.MemoryQuery
+	MemoryType   = memory.MemoryType
+	HybridQuery  = memory.HybridQuery
+	ScoredMemory = memory.ScoredMemory
+	Artifact     = artifact.Artifact
+	Lock         = locks.Lock
+)
+
+// OutputPatch is the output of the *Patch of a task that changes files
+const OutputPatch = agent.OutputPatch
+
+// Memory types to query for
+const (
+	MemoryTypeWorking    = memory.MemoryTypeWorking
+	MemoryTypeEpisodic   = memory.MemoryTypeEpisodic
+	MemoryTypeSemantic   = memory.MemoryTypeSemantic
+	MemoryTypeProcedural = memory.MemoryTypeProcedural
+)
+
+// Swarm is a running swarm embedded in opencode. It is the API for the chat
+// agent, tools and other parts of opencode; the Coordinator behind it stays
+// free to change.
+type Swarm struct {
+	coordinator *Coordinator
+}
+
+// Open creates a swarm from its configuration and starts it. Load a
+// configuration file with LoadFileConfig.
+func Open(config FileConfig) (*Swarm, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	coordinatorConfig := config.CoordinatorConfig()
+	sealer, err := config.Sealer()
+	if err != nil {
+		return nil, err
+	}
+	coordinatorConfig.Sealer = sealer
+	c, err := NewCoordinator(coordinatorConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	return &Swarm{coordinator: c}, nil
+}
+
+// SubmitTask queues a task and returns its ID. Tasks without an ID are
+// given one. A task coalesced with another by its idempotency key returns
+// the ID of the other task.
+func (s *Swarm) SubmitTask(ctx context.Context, task Task) (string, error) {
+	if task.ID == "" {
+		task.ID = uuid.New().String()
+	}
+	if err := s.coordinator.SubmitTask(ctx, task); err != nil {
+		return "", err
+	}
+	if record, err := s.coordinator.GetTask(task.ID); err == nil {
+		return record.Task.ID, nil
+	}
+	return task.ID, nil
+}
+
+// Result waits until a task finished and returns its result. Failed and
+// cancelled tasks without a result return a *TaskError.
+func (s *Swarm) Result(ctx context.Context, taskID string) (*TaskResult, error) {
+	return s.coordinator.GetTaskResult(ctx, taskID)
+}
+
+// Task returns the record of a task, with the agent that ran it and the
+// vote that let it run
+func (s *Swarm) Task(taskID string) (TaskRecord, error) {
+	return s.coordinator.GetTask(taskID)
+}
+
+// CancelTask removes a queued task or cancels a running one
+func (s *Swarm) CancelTask(taskID string) error {
+	return s.coordinator.CancelTask(taskID)
+}
+
+// Query returns the memories of the swarm matching the query
+func (s *Swarm) Query(ctx context.Context, query MemoryQuery) ([]Memory, error) {
+	memories, err := s.coordinator.GetMemoryStore().Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query memories: %w", err)
+	}
+	return memories, nil
+}
+
+// Search returns the memories of the swarm best matching the query by
+// keywords and embedding, best first
+func (s *Swarm) Search(ctx context.Context, query HybridQuery) ([]ScoredMemory, error) {
+	memories, err := memory.HybridSearch(ctx, s.coordinator.GetMemoryStore(), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search memories: %w", err)
+	}
+	return memories, nil
+}
+
+// Artifacts returns the stored artifacts attached to a task. Task results
+// and memories refer to them by ID.
+func (s *Swarm) Artifacts(taskID string) []Artifact {
+	return s.coordinator.TaskArtifacts(taskID)
+}
+
+// OpenArtifact returns a stored artifact with its content, which the
+// caller closes
+func (s *Swarm) OpenArtifact(id string) (Artifact, io.ReadCloser, error) {
+	return s.coordinator.OpenArtifact(id)
+}
+
+// ReportDiagnostics passes the diagnostics of a file, e.g. from a language
+// server, to the swarm. Changes are remembered and trigger the rules on
+// "diagnostics" events.
+func (s *Swarm) ReportDiagnostics(ctx context.Context, report FileDiagnostics) error {
+	return s.coordinator.HandleDiagnostics(ctx, report)
+}
+
+// ReportEditOutcome tells the swarm which of the edits a task proposed were
+// applied, to be remembered
+func (s *Swarm) ReportEditOutcome(ctx context.Context, outcome EditOutcome) error {
+	return s.coordinator.HandleEditOutcome(ctx, outcome)
+}
+
+// UseFileHistory sets the file history the changes of tasks are rolled
+// back with, see Coordinator.RollbackTask
+func (s *Swarm) UseFileHistory(files FileHistory) {
+	s.coordinator.UseFileHistory(files)
+}
+
+// RollbackTask restores the files a task changed to their versions from
+// before the task, see Coordinator.RollbackTask
+func (s *Swarm) RollbackTask(ctx context.Context, taskID string) (Rollback, error) {
+	return s.coordinator.RollbackTask(ctx, taskID)
+}
+
+// Subscribe returns the timeline events of the swarm from now on, until
+// ctx is done or the swarm is closed. Events a slow subscriber is not
+// ready for are dropped.
+func (s *Swarm) Subscribe(ctx context.Context) <-chan pubsub.Event[TimelineEvent] {
+	return s.coordinator.Subscribe(ctx)
+}
+
+// Health returns the health of the swarm: its score, the checks of its
+// components and how many critical alerts were raised
+func (s *Swarm) Health() SwarmHealth {
+	return s.coordinator.Health()
+}
+
+// GetTopology returns the graph of the agents of the swarm and how they are
+// linked
+func (s *Swarm) GetTopology() Topology {
+	return s.coordinator.GetTopology()
+}
+
+// Timeline returns the timeline events matching filter, oldest first
+func (s *Swarm) Timeline(filter TimelineFilter) []TimelineEvent {
+	return s.coordinator.Timeline(filter)
+}
+
+// Close stops the swarm, cancelling running tasks. It is safe to call more
+// than once.
+func (s *Swarm) Close() error {
+	return s.coordinator.Stop()
+}
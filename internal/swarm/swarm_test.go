@@ -0,0 +1,100 @@
+package swarm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+)
+
+// echoAgent answers every task with its description
+type echoAgent struct {
+	*agent.BaseAgent
+}
+
+func (a *echoAgent) CanHandleTask(task agent.Task) bool {
+	return true
+}
+
+func (a *echoAgent) ExecuteTask(ctx context.Context, task agent.Task) (*agent.TaskResult, error) {
+	return &agent.TaskResult{
+		TaskID:      task.ID,
+		Success:     true,
+		Output:      map[string]interface{}{"response": task.Description},
+		AgentID:     a.GetID(),
+		CompletedAt: time.Now(),
+	}, nil
+}
+
+func TestSwarm(t *testing.T) {
+	agent.RegisterFactory(agent.AgentTypeDocumentation, func(config agent.AgentConfig) (agent.Agent, error) {
+		return &echoAgent{BaseAgent: agent.NewBaseAgent(config)}, nil
+	})
+
+	s, err := Open(FileConfig{Agents: []AgentFileConfig{{ID: "echo", Type: string(agent.AgentTypeDocumentation)}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events := s.Subscribe(ctx)
+
+	id, err := s.SubmitTask(ctx, Task{Type: "docs", Description: "Document the API"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == "" {
+		t.Fatal("no task ID")
+	}
+	result, err := s.Result(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || result.Output["response"] != "Document the API" {
+		t.Errorf("result = %+v", result)
+	}
+
+	var types []TimelineEventType
+	for len(types) < 3 {
+		select {
+		case event := <-events:
+			if event.Payload.Subject == id {
+				types = append(types, event.Payload.Type)
+			}
+		case <-ctx.Done():
+			t.Fatalf("events for the task: %v", types)
+		}
+	}
+	if types[0] != TimelineTaskSubmitted || types[2] != TimelineTaskFinished {
+		t.Errorf("events for the task: %v", types)
+	}
+
+	// The result is remembered once it was handled
+	for {
+		memories, err := s.Query(ctx, MemoryQuery{Type: MemoryTypeProcedural, Tags: []string{"task", "result"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(memories) == 1 && memories[0].Metadata["task_id"] == id {
+			break
+		}
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			t.Fatalf("memories = %+v", memories)
+		}
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// The subscription ends with the swarm
+	for range events {
+	}
+	if _, err := s.SubmitTask(ctx, Task{Type: "docs"}); err == nil {
+		t.Error("SubmitTask succeeded after Close")
+	}
+}
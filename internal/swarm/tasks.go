@@ -0,0 +1,595 @@
+package swarm
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+// TaskState is the lifecycle state of a submitted task
+type TaskState string
+
+const (
+	TaskStateQueued    TaskState = "queued"
+	TaskStateRunning   TaskState = "running"
+	TaskStateCompleted TaskState = "completed"
+	TaskStateFailed    TaskState = "failed"
+	TaskStateCancelled TaskState = "cancelled"
+)
+
+// maxFinishedTasks bounds how many finished tasks are kept for inspection
+const maxFinishedTasks = 1000
+
+// DefaultIdempotencyWindow is how long a completed task answers the
+// submissions with its idempotency key
+const DefaultIdempotencyWindow = time.Hour
+
+// TaskRecord tracks a task from submission to completion
+type TaskRecord struct {
+	Task        agent.Task
+	State       TaskState
+	AgentID     string
+	SubmittedAt time.Time
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	Result      *agent.TaskResult
+	Error       string
+	// VoteID is the vote that let the task run, if there was one
+	VoteID string
+	// Progress is what the agent reported while running the task
+	Progress TaskProgress
+	// Selection explains how the agent was picked: the scores of the
+	// agents that could handle the task when it was dispatched, best first
+	Selection []agent.SelectionScore
+	// StuckSince is when the watchdog suspected the running task stuck
+	StuckSince time.Time
+	// Shadows are the results of the shadow agents given copies of the
+	// task
+	Shadows []ShadowResult
+	// SpawnedBy is the task whose result asked for this one, if any
+	SpawnedBy string
+}
+
+// maxProgressLog bounds the log lines of progress kept per task
+const maxProgressLog = 50
+
+// TaskProgress is the progress an agent reported on a task so far
+type TaskProgress struct {
+	Percent float64
+	Stage   string
+	// Log holds the latest log lines, oldest first
+	Log       []string
+	UpdatedAt time.Time
+}
+
+// Finished returns whether the task will not run again unless retried
+func (r TaskRecord) Finished() bool {
+	switch r.State {
+	case TaskStateCompleted, TaskStateFailed, TaskStateCancelled:
+		return true
+	}
+	return false
+}
+
+// taskTracker keeps every submitted task and hands out pending tasks by
+// priority, oldest first among equal priorities
+type taskTracker struct {
+	mu         sync.Mutex
+	records    map[string]*TaskRecord
+	pending    []string
+	finished   []string
+	cancels    map[string]context.CancelFunc
+	cancelling map[string]bool
+	maxPending int
+	clock      clock.Clock
+
+	// keys maps idempotency keys to the task they were last submitted
+	// with, and aliases the IDs of coalesced submissions to that task
+	keys    map[string]string
+	aliases map[string]string
+	window  time.Duration
+
+	// ready is signalled when a task becomes pending
+	ready chan struct{}
+}
+
+func newTaskTracker(maxPending int, window time.Duration, clk clock.Clock) *taskTracker {
+	if window <= 0 {
+		window = DefaultIdempotencyWindow
+	}
+	return &taskTracker{
+		records:    make(map[string]*TaskRecord),
+		cancels:    make(map[string]context.CancelFunc),
+		cancelling: make(map[string]bool),
+		maxPending: maxPending,
+		clock:      clk,
+		keys:       make(map[string]string),
+		aliases:    make(map[string]string),
+		window:     window,
+		ready:      make(chan struct{}, 1),
+	}
+}
+
+func (t *taskTracker) signal() {
+	select {
+	case t.ready <- struct{}{}:
+	default:
+	}
+}
+
+// enqueue records a new task as pending and returns its ID. A task with the
+// idempotency key of a queued or running task, or of a task completed
+// within the idempotency window, is not queued: the ID of that task is
+// returned with coalesced set, and the ID of the submission refers to it.
+func (t *taskTracker) enqueue(task agent.Task) (id string, coalesced bool, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.enqueueLocked(task)
+}
+
+// enqueueLocked is enqueue with t.mu held
+func (t *taskTracker) enqueueLocked(task agent.Task) (id string, coalesced bool, err error) {
+	if task.ID == "" {
+		task.ID = uuid.New().String()
+	}
+	if existing := t.duplicate(task); existing != "" {
+		// Retries keep their own record
+		if _, ok := t.records[task.ID]; !ok {
+			t.aliases[task.ID] = existing
+		}
+		return existing, true, nil
+	}
+	if len(t.pending) >= t.maxPending {
+		return "", false, ErrQueueFull
+	}
+	if task.CreatedAt.IsZero() {
+		task.CreatedAt = t.clock.Now()
+	}
+	if existing, ok := t.records[task.ID]; ok && !existing.Finished() {
+		return "", false, &TaskError{TaskID: task.ID, State: existing.State, Err: ErrTaskExists}
+	}
+	if task.IdempotencyKey != "" {
+		t.keys[task.IdempotencyKey] = task.ID
+	}
+	delete(t.aliases, task.ID)
+
+	t.records[task.ID] = &TaskRecord{
+		Task:        task,
+		State:       TaskStateQueued,
+		SubmittedAt: t.clock.Now(),
+	}
+	t.removeFinished(task.ID)
+	t.pending = append(t.pending, task.ID)
+	t.signal()
+	return task.ID, false, nil
+}
+
+// enqueueBatch enqueues tasks in order under one lock, so tasks of the
+// batch with the same idempotency key are coalesced with the first of
+// them. With atomic set nothing is queued unless every task can be; the
+// results then hold the errors of the tasks that could not.
+func (t *taskTracker) enqueueBatch(tasks []agent.Task, atomic bool) (results []SubmitResult, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	results = make([]SubmitResult, len(tasks))
+	if atomic && !t.admit(tasks, results) {
+		return results, false
+	}
+	ok = true
+	for i, task := range tasks {
+		id, coalesced, err := t.enqueueLocked(task)
+		results[i] = SubmitResult{ID: id, Coalesced: coalesced, Err: err}
+		ok = ok && err == nil
+	}
+	return results, ok
+}
+
+// admit checks that every task of a batch can be enqueued, recording the
+// errors of those that cannot in results. Tasks must have their IDs. It is
+// called with t.mu held.
+func (t *taskTracker) admit(tasks []agent.Task, results []SubmitResult) bool {
+	pending := len(t.pending)
+	ids := make(map[string]bool, len(tasks))
+	keys := make(map[string]bool)
+	ok := true
+	for i, task := range tasks {
+		if t.duplicate(task) != "" || (task.IdempotencyKey != "" && keys[task.IdempotencyKey]) {
+			continue
+		}
+		var err error
+		if existing, found := t.records[task.ID]; ids[task.ID] || (found && !existing.Finished()) {
+			state := TaskStateQueued
+			if found {
+				state = existing.State
+			}
+			err = &TaskError{TaskID: task.ID, State: state, Err: ErrTaskExists}
+		} else if pending >= t.maxPending {
+			err = ErrQueueFull
+		}
+		if err != nil {
+			results[i].Err = err
+			ok = false
+			continue
+		}
+		ids[task.ID] = true
+		if task.IdempotencyKey != "" {
+			keys[task.IdempotencyKey] = true
+		}
+		pending++
+	}
+	return ok
+}
+
+// duplicate returns the task a submission is coalesced with, if any
+func (t *taskTracker) duplicate(task agent.Task) string {
+	if task.IdempotencyKey == "" {
+		return ""
+	}
+	id := t.keys[task.IdempotencyKey]
+	record, ok := t.records[id]
+	// Retries of a task run again
+	if !ok || id == task.ID {
+		return ""
+	}
+	switch record.State {
+	case TaskStateQueued, TaskStateRunning:
+		return id
+	case TaskStateCompleted:
+		if t.clock.Now().Sub(record.FinishedAt) < t.window {
+			return id
+		}
+	}
+	return ""
+}
+
+// resolve returns the ID of the task a submission was coalesced with, or
+// the ID itself
+func (t *taskTracker) resolve(taskID string) string {
+	if id, ok := t.aliases[taskID]; ok {
+		return id
+	}
+	return taskID
+}
+
+// next removes and returns the highest priority pending task
+func (t *taskTracker) next() (agent.Task, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.pending) == 0 {
+		return agent.Task{}, false
+	}
+	best := 0
+	for i, id := range t.pending {
+		if t.records[id].Task.Priority > t.records[t.pending[best]].Task.Priority {
+			best = i
+		}
+	}
+	id := t.pending[best]
+	t.pending = append(t.pending[:best], t.pending[best+1:]...)
+	return t.records[id].Task, true
+}
+
+// start marks a task as running on an agent
+func (t *taskTracker) start(taskID, agentID string, cancel context.CancelFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if record, ok := t.records[taskID]; ok {
+		record.State = TaskStateRunning
+		record.AgentID = agentID
+		record.StartedAt = t.clock.Now()
+		record.Progress = TaskProgress{}
+		record.StuckSince = time.Time{}
+		t.cancels[taskID] = cancel
+	}
+}
+
+// markStuck records that a running task is suspected stuck, and reports
+// whether it was running and not marked before
+func (t *taskTracker) markStuck(taskID string, at time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, ok := t.records[taskID]
+	if !ok || record.State != TaskStateRunning || !record.StuckSince.IsZero() {
+		return false
+	}
+	record.StuckSince = at
+	return true
+}
+
+// addShadow records the result of a shadow agent with a task
+func (t *taskTracker) addShadow(taskID string, result ShadowResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if record, ok := t.records[taskID]; ok {
+		// Copied rather than appended to, records handed out share it
+		record.Shadows = append(slices.Clip(record.Shadows), result)
+	}
+}
+
+// progress records the progress reported on a running task
+func (t *taskTracker) progress(update agent.TaskProgress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, ok := t.records[update.TaskID]
+	if !ok || record.State != TaskStateRunning {
+		return
+	}
+	progress := &record.Progress
+	if update.Percent > 0 {
+		progress.Percent = min(update.Percent, 100)
+	}
+	if update.Stage != "" {
+		progress.Stage = update.Stage
+	}
+	if update.Message != "" {
+		// Copied rather than appended to, records handed out share it
+		log := progress.Log
+		if len(log) >= maxProgressLog {
+			log = log[len(log)-maxProgressLog+1:]
+		}
+		progress.Log = append(append(make([]string, 0, len(log)+1), log...), update.Message)
+	}
+	progress.UpdatedAt = t.clock.Now()
+}
+
+// setSelection records the scores of the agents a task was dispatched
+// among
+func (t *taskTracker) setSelection(taskID string, scores []agent.SelectionScore) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if record, ok := t.records[taskID]; ok {
+		record.Selection = scores
+	}
+}
+
+// setVote records the vote deciding whether a task runs
+func (t *taskTracker) setVote(taskID, voteID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if record, ok := t.records[taskID]; ok {
+		record.VoteID = voteID
+	}
+}
+
+// setSpawnedBy records the task that asked for another one
+func (t *taskTracker) setSpawnedBy(taskID, parentID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if record, ok := t.records[t.resolve(taskID)]; ok && record.SpawnedBy == "" {
+		record.SpawnedBy = parentID
+	}
+}
+
+// requeue puts a task that an agent turned away back in the queue. It does
+// not count towards the queue limit since the task was already accepted.
+func (t *taskTracker) requeue(taskID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, ok := t.records[taskID]
+	if !ok || record.State != TaskStateRunning {
+		return
+	}
+	delete(t.cancels, taskID)
+	if t.cancelling[taskID] {
+		delete(t.cancelling, taskID)
+		record.State = TaskStateCancelled
+		record.FinishedAt = t.clock.Now()
+		t.addFinished(taskID)
+		return
+	}
+	record.State = TaskStateQueued
+	record.AgentID = ""
+	record.StartedAt = time.Time{}
+	t.pending = append(t.pending, taskID)
+	t.signal()
+}
+
+// finish records the outcome of a task
+func (t *taskTracker) finish(taskID string, result *agent.TaskResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, ok := t.records[taskID]
+	if !ok {
+		return
+	}
+	delete(t.cancels, taskID)
+	record.Result = result
+	record.FinishedAt = t.clock.Now()
+	switch {
+	case t.cancelling[taskID]:
+		record.State = TaskStateCancelled
+	case result != nil && result.Success:
+		record.State = TaskStateCompleted
+	default:
+		record.State = TaskStateFailed
+	}
+	if result != nil && result.Error != nil {
+		record.Error = result.Error.Error()
+	}
+	delete(t.cancelling, taskID)
+	t.addFinished(taskID)
+}
+
+// fail finishes a task that never ran
+func (t *taskTracker) fail(taskID, reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if record, ok := t.records[taskID]; ok {
+		record.State = TaskStateFailed
+		record.Error = reason
+		record.FinishedAt = t.clock.Now()
+		t.addFinished(taskID)
+	}
+}
+
+// cancel removes a pending task or interrupts a running one
+func (t *taskTracker) cancel(taskID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	taskID = t.resolve(taskID)
+	record, ok := t.records[taskID]
+	if !ok {
+		return &TaskError{TaskID: taskID, Err: ErrTaskNotFound}
+	}
+	switch record.State {
+	case TaskStateQueued:
+		t.removePending(taskID)
+		record.State = TaskStateCancelled
+		record.FinishedAt = t.clock.Now()
+		t.addFinished(taskID)
+	case TaskStateRunning:
+		t.cancelling[taskID] = true
+		if cancel := t.cancels[taskID]; cancel != nil {
+			cancel()
+		}
+	default:
+		return &TaskError{TaskID: taskID, State: record.State, Err: ErrInvalidTaskState}
+	}
+	return nil
+}
+
+// retry queues a finished task again
+func (t *taskTracker) retry(taskID string) error {
+	t.mu.Lock()
+	taskID = t.resolve(taskID)
+	record, ok := t.records[taskID]
+	if !ok {
+		t.mu.Unlock()
+		return &TaskError{TaskID: taskID, Err: ErrTaskNotFound}
+	}
+	if !record.Finished() {
+		t.mu.Unlock()
+		return &TaskError{TaskID: taskID, State: record.State, Err: ErrInvalidTaskState}
+	}
+	task := record.Task
+	t.mu.Unlock()
+
+	task.RetryCount++
+	_, _, err := t.enqueue(task)
+	return err
+}
+
+// setPriority changes the priority of a pending task
+func (t *taskTracker) setPriority(taskID string, priority int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	taskID = t.resolve(taskID)
+	record, ok := t.records[taskID]
+	if !ok {
+		return &TaskError{TaskID: taskID, Err: ErrTaskNotFound}
+	}
+	if record.State != TaskStateQueued {
+		return &TaskError{TaskID: taskID, State: record.State, Reason: "only queued tasks can be reprioritized", Err: ErrInvalidTaskState}
+	}
+	record.Task.Priority = priority
+	return nil
+}
+
+// get returns the record of a task, or of the task a submission was
+// coalesced with
+func (t *taskTracker) get(taskID string) (TaskRecord, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	taskID = t.resolve(taskID)
+	record, ok := t.records[taskID]
+	if !ok {
+		return TaskRecord{}, &TaskError{TaskID: taskID, Err: ErrTaskNotFound}
+	}
+	return *record, nil
+}
+
+// list returns copies of all records, newest submission first
+func (t *taskTracker) list() []TaskRecord {
+	t.mu.Lock()
+	records := make([]TaskRecord, 0, len(t.records))
+	for _, record := range t.records {
+		records = append(records, *record)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].SubmittedAt.After(records[j].SubmittedAt)
+	})
+	return records
+}
+
+// activeCount returns how many tasks are queued or running
+func (t *taskTracker) activeCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	active := 0
+	for _, record := range t.records {
+		if !record.Finished() {
+			active++
+		}
+	}
+	return active
+}
+
+func (t *taskTracker) pendingCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending)
+}
+
+func (t *taskTracker) removePending(taskID string) {
+	for i, id := range t.pending {
+		if id == taskID {
+			t.pending = append(t.pending[:i], t.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+func (t *taskTracker) removeFinished(taskID string) {
+	for i, id := range t.finished {
+		if id == taskID {
+			t.finished = append(t.finished[:i], t.finished[i+1:]...)
+			return
+		}
+	}
+}
+
+// addFinished remembers a finished task, forgetting the oldest finished
+// tasks beyond the retention limit
+func (t *taskTracker) addFinished(taskID string) {
+	t.finished = append(t.finished, taskID)
+	for len(t.finished) > maxFinishedTasks {
+		t.forget(t.finished[0])
+		t.finished = t.finished[1:]
+	}
+}
+
+// forget drops a finished task with its idempotency key and aliases
+func (t *taskTracker) forget(taskID string) {
+	if record, ok := t.records[taskID]; ok && t.keys[record.Task.IdempotencyKey] == taskID {
+		delete(t.keys, record.Task.IdempotencyKey)
+	}
+	for alias, id := range t.aliases {
+		if id == taskID {
+			delete(t.aliases, alias)
+		}
+	}
+	delete(t.records, taskID)
+}
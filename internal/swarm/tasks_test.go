@@ -0,0 +1,145 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+func TestTaskTrackerIdempotencyKey(t *testing.T) {
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tracker := newTaskTracker(10, time.Hour, clk)
+
+	first, coalesced, err := tracker.enqueue(agent.Task{ID: "a", Type: "patch", IdempotencyKey: "patch:boom"})
+	if err != nil || coalesced || first != "a" {
+		t.Fatalf("enqueue = %q, %v, %v", first, coalesced, err)
+	}
+	// Queued and running tasks take the submissions with their key
+	id, coalesced, err := tracker.enqueue(agent.Task{ID: "b", Type: "patch", IdempotencyKey: "patch:boom"})
+	if err != nil || !coalesced || id != "a" {
+		t.Fatalf("duplicate of a queued task = %q, %v, %v", id, coalesced, err)
+	}
+	if tracker.pendingCount() != 1 {
+		t.Errorf("%d tasks pending", tracker.pendingCount())
+	}
+	task, _ := tracker.next()
+	tracker.start(task.ID, "patcher", func() {})
+	if id, coalesced, _ := tracker.enqueue(agent.Task{Type: "patch", IdempotencyKey: "patch:boom"}); !coalesced || id != "a" {
+		t.Errorf("duplicate of a running task = %q, %v", id, coalesced)
+	}
+	// Other keys and tasks without one are queued
+	if _, coalesced, _ := tracker.enqueue(agent.Task{ID: "c", Type: "patch", IdempotencyKey: "patch:other"}); coalesced {
+		t.Error("task with another key coalesced")
+	}
+	if _, coalesced, _ := tracker.enqueue(agent.Task{ID: "d", Type: "patch"}); coalesced {
+		t.Error("task without a key coalesced")
+	}
+
+	// The ID of a coalesced submission refers to the task it joined
+	result := &agent.TaskResult{TaskID: "a", Success: true}
+	tracker.finish("a", result)
+	record, err := tracker.get("b")
+	if err != nil || record.Task.ID != "a" || record.Result != result {
+		t.Errorf("get(b) = %+v, %v", record, err)
+	}
+
+	// Completed tasks answer within the window
+	clk.Advance(30 * time.Minute)
+	if id, coalesced, _ := tracker.enqueue(agent.Task{ID: "e", Type: "patch", IdempotencyKey: "patch:boom"}); !coalesced || id != "a" {
+		t.Errorf("duplicate of a completed task = %q, %v", id, coalesced)
+	}
+	clk.Advance(time.Hour)
+	if id, coalesced, _ := tracker.enqueue(agent.Task{ID: "f", Type: "patch", IdempotencyKey: "patch:boom"}); coalesced || id != "f" {
+		t.Errorf("duplicate after the window = %q, %v", id, coalesced)
+	}
+}
+
+func TestTaskTrackerIdempotencyKeyFailedTask(t *testing.T) {
+	tracker := newTaskTracker(10, time.Hour, clock.NewFake(time.Now()))
+
+	if _, _, err := tracker.enqueue(agent.Task{ID: "a", Type: "patch", IdempotencyKey: "patch:boom"}); err != nil {
+		t.Fatal(err)
+	}
+	tracker.next()
+	tracker.fail("a", "no agent")
+
+	// A failed task is submitted again
+	id, coalesced, err := tracker.enqueue(agent.Task{ID: "b", Type: "patch", IdempotencyKey: "patch:boom"})
+	if err != nil || coalesced || id != "b" {
+		t.Fatalf("resubmission = %q, %v, %v", id, coalesced, err)
+	}
+	// Retrying a task runs it again even though its key is in use
+	tracker.next()
+	tracker.finish("b", &agent.TaskResult{TaskID: "b", Success: true})
+	if err := tracker.retry("b"); err != nil {
+		t.Fatal(err)
+	}
+	if record, _ := tracker.get("b"); record.State != TaskStateQueued || record.Task.RetryCount != 1 {
+		t.Errorf("retried task = %+v", record)
+	}
+
+	if _, err := tracker.get("missing"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("get(missing) = %v", err)
+	}
+}
+
+func TestSubmitTasks(t *testing.T) {
+	c, err := NewCoordinator(CoordinatorConfig{TaskQueueSize: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// Tasks of a batch with the same key are coalesced with the first
+	results, err := c.SubmitTasks(ctx, []agent.Task{
+		{ID: "a", Type: "patch", IdempotencyKey: "patch:boom"},
+		{ID: "b", Type: "patch", IdempotencyKey: "patch:boom"},
+	}, BatchOptions{Atomic: true})
+	if err != nil || results[0].ID != "a" || results[0].Coalesced || results[1].ID != "a" || !results[1].Coalesced {
+		t.Fatalf("SubmitTasks = %+v, %v", results, err)
+	}
+
+	// An atomic batch that does not fit queues nothing
+	results, err = c.SubmitTasks(ctx, []agent.Task{
+		{ID: "c", Type: "test"},
+		{ID: "d", Type: "test"},
+		{ID: "e", Type: "test"},
+	}, BatchOptions{Atomic: true})
+	if !errors.Is(err, ErrBatchRejected) || results[0].Err != nil || !errors.Is(results[2].Err, ErrQueueFull) {
+		t.Fatalf("SubmitTasks = %+v, %v, want the last task rejected", results, err)
+	}
+	if n := c.tasks.pendingCount(); n != 1 {
+		t.Errorf("%d tasks pending after a rejected batch, want 1", n)
+	}
+
+	// Otherwise the tasks that fit are queued
+	results, err = c.SubmitTasks(ctx, []agent.Task{
+		{ID: "c", Type: "test"},
+		{ID: "d"},
+		{ID: "e", Type: "test"},
+		{ID: "f", Type: "test"},
+	}, BatchOptions{})
+	if !errors.Is(err, ErrBatchRejected) {
+		t.Fatalf("err = %v, want the batch rejected", err)
+	}
+	for i, want := range []error{nil, ErrTaskTypeRequired, nil, ErrQueueFull} {
+		if !errors.Is(results[i].Err, want) {
+			t.Errorf("result %d = %v, want %v", i, results[i].Err, want)
+		}
+	}
+	if _, err := c.GetTask("e"); err != nil {
+		t.Errorf("task e not queued: %v", err)
+	}
+
+	// Single tasks need a type too
+	if err := c.SubmitTask(ctx, agent.Task{ID: "g"}); !errors.Is(err, ErrTaskTypeRequired) {
+		t.Errorf("SubmitTask without type = %v, want ErrTaskTypeRequired", err)
+	}
+	if _, err := c.GetTask("g"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("task without type queued: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package swarm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+	"github.com/opencode-ai/opencode/internal/swarm/rules"
+)
+
+// TimelineEventType identifies what kind of swarm decision an event records
+type TimelineEventType string
+
+const (
+	TimelineTaskSubmitted TimelineEventType = "task_submitted"
+	TimelineTaskStarted   TimelineEventType = "task_started"
+	TimelineTaskRequeued  TimelineEventType = "task_requeued"
+	TimelineTaskProgress  TimelineEventType = "task_progress"
+	TimelineTaskFinished  TimelineEventType = "task_finished"
+	TimelineVoteOpened    TimelineEventType = "vote_opened"
+	TimelineVoteDecided   TimelineEventType = "vote_decided"
+	TimelineAlert         TimelineEventType = "alert"
+	TimelineRecovery      TimelineEventType = "recovery"
+	TimelineConsolidation TimelineEventType = "consolidation"
+	TimelinePromotion     TimelineEventType = "promotion"
+	TimelineVerification  TimelineEventType = "verification"
+	TimelineShadow        TimelineEventType = "shadow"
+	TimelineRollback      TimelineEventType = "rollback"
+	TimelineTestReport    TimelineEventType = "test_report"
+)
+
+// TimelineEventTypes lists every event type in display order
+var TimelineEventTypes = []TimelineEventType{
+	TimelineTaskSubmitted,
+	TimelineTaskStarted,
+	TimelineTaskRequeued,
+	TimelineTaskProgress,
+	TimelineTaskFinished,
+	TimelineVoteOpened,
+	TimelineVoteDecided,
+	TimelineAlert,
+	TimelineRecovery,
+	TimelineConsolidation,
+	TimelinePromotion,
+	TimelineVerification,
+	TimelineShadow,
+	TimelineRollback,
+	TimelineTestReport,
+}
+
+// maxTimelineEvents bounds how many events the timeline keeps
+const maxTimelineEvents = 5000
+
+// TimelineEvent is a single entry in the swarm timeline
+type TimelineEvent struct {
+	// Seq increases by one for every recorded event
+	Seq       int64
+	Type      TimelineEventType
+	Timestamp time.Time
+	// Subject is the task, vote session or component the event is about
+	Subject string
+	Summary string
+	Details map[string]interface{}
+}
+
+// TimelineFilter selects timeline events. Zero values match everything.
+type TimelineFilter struct {
+	Types []TimelineEventType
+	Since time.Time
+	Until time.Time
+}
+
+func (f TimelineFilter) matches(event TimelineEvent) bool {
+	if !f.Since.IsZero() && event.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && event.Timestamp.After(f.Until) {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// timeline is a bounded, chronological journal of swarm events. New events
+// are published to subscribers as they are recorded and applied to the
+// state of the swarm.
+type timeline struct {
+	mu     sync.RWMutex
+	events []TimelineEvent
+	seq    int64
+	state  SwarmState
+	clock  clock.Clock
+	broker *pubsub.Broker[TimelineEvent]
+}
+
+func newTimeline(clk clock.Clock) *timeline {
+	return &timeline{state: newSwarmState(), clock: clk, broker: pubsub.NewBroker[TimelineEvent]()}
+}
+
+// record appends an event, dropping the oldest events beyond the limit
+func (t *timeline) record(eventType TimelineEventType, subject, summary string, details map[string]interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.seq++
+	event := TimelineEvent{
+		Seq:       t.seq,
+		Type:      eventType,
+		Timestamp: t.clock.Now(),
+		Subject:   subject,
+		Summary:   summary,
+		Details:   details,
+	}
+	t.events = append(t.events, event)
+	t.state.apply(event)
+	// Publish under the lock so subscribers see events in order
+	t.broker.Publish(pubsub.CreatedEvent, event)
+	if overflow := len(t.events) - maxTimelineEvents; overflow > 0 {
+		// Dropped events are released when append next reallocates
+		t.events = t.events[overflow:]
+	}
+}
+
+// list returns the events matching the filter, oldest first
+func (t *timeline) list(filter TimelineFilter) []TimelineEvent {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var events []TimelineEvent
+	for _, event := range t.events {
+		if filter.matches(event) {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// snapshot returns the state the recorded events lead to
+func (t *timeline) snapshot() SwarmState {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.state.clone()
+}
+
+// timelineNotifier records rule notifications as alerts
+type timelineNotifier struct {
+	timeline *timeline
+}
+
+func (n timelineNotifier) Notify(notification rules.Notification) {
+	summary := notification.Title
+	if notification.Message != "" {
+		summary += ": " + notification.Message
+	}
+	n.timeline.record(TimelineAlert, notification.AgentID, summary, map[string]interface{}{
+		"severity": notification.Level,
+		"event":    notification.EventType,
+	})
+}
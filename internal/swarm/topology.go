@@ -0,0 +1,235 @@
+package swarm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+)
+
+const (
+	// TopologyRateWindow is the time message rates are measured over
+	TopologyRateWindow = 5 * time.Minute
+	// BrokerNodeID is the node of the message broker, which broadcasts go
+	// to and subscriptions come from
+	BrokerNodeID = "broker"
+)
+
+// TopologyNodeKind tells the agents from the broker in a topology
+type TopologyNodeKind string
+
+const (
+	TopologyNodeAgent  TopologyNodeKind = "agent"
+	TopologyNodeBroker TopologyNodeKind = "broker"
+)
+
+// TopologyEdgeKind is what links two nodes of a topology
+type TopologyEdgeKind string
+
+const (
+	// TopologyEdgeMessage is messages sent from one agent to another, or
+	// broadcast through the broker
+	TopologyEdgeMessage TopologyEdgeKind = "message"
+	// TopologyEdgeSubscription is the broker delivering messages to an
+	// agent
+	TopologyEdgeSubscription TopologyEdgeKind = "subscription"
+	// TopologyEdgeDependency is tasks of one agent asking for tasks that
+	// another agent ran
+	TopologyEdgeDependency TopologyEdgeKind = "dependency"
+	// TopologyEdgeShadow is an agent's tasks copied to a shadow agent
+	TopologyEdgeShadow TopologyEdgeKind = "shadow"
+)
+
+// TopologyNode is an agent of the swarm, or the broker
+type TopologyNode struct {
+	ID     string            `json:"id"`
+	Kind   TopologyNodeKind  `json:"kind"`
+	Type   agent.AgentType   `json:"type,omitempty"`
+	Status agent.AgentStatus `json:"status,omitempty"`
+	Health float64           `json:"health,omitempty"`
+	Shadow bool              `json:"shadow,omitempty"`
+	// Unreachable is set once the agent missed its heartbeats
+	Unreachable bool `json:"unreachable,omitempty"`
+	// Removed marks agents that left the swarm but still have edges
+	Removed bool `json:"removed,omitempty"`
+}
+
+// TopologyEdge links two nodes. Count is how many messages or tasks went
+// along it; Rate is messages per minute over TopologyRateWindow, for
+// message edges only.
+type TopologyEdge struct {
+	From  string           `json:"from"`
+	To    string           `json:"to"`
+	Kind  TopologyEdgeKind `json:"kind"`
+	Count int              `json:"count,omitempty"`
+	Rate  float64          `json:"rate,omitempty"`
+}
+
+// Topology is the graph of the swarm: its agents and how they are linked
+type Topology struct {
+	Nodes []TopologyNode `json:"nodes"`
+	Edges []TopologyEdge `json:"edges"`
+	At    time.Time      `json:"at"`
+}
+
+// GetTopology returns the agents of the swarm with the messages they sent
+// each other, their subscriptions, the tasks they spawned for each other
+// and the shadows they copied tasks to
+func (c *Coordinator) GetTopology() Topology {
+	now := c.clock.Now()
+	t := Topology{Nodes: []TopologyNode{}, Edges: []TopologyEdge{}, At: now}
+
+	nodes := make(map[string]bool)
+	for id, h := range c.registry.GetHealthStatus() {
+		nodes[id] = true
+		t.Nodes = append(t.Nodes, TopologyNode{
+			ID:          id,
+			Kind:        TopologyNodeAgent,
+			Type:        h.Type,
+			Status:      h.Status,
+			Health:      h.HealthScore,
+			Shadow:      c.registry.IsShadow(id),
+			Unreachable: h.Unreachable,
+		})
+	}
+	sort.Slice(t.Nodes, func(i, j int) bool { return t.Nodes[i].ID < t.Nodes[j].ID })
+
+	subscribers := c.registry.Subscribers()
+	flows := c.registry.MessageFlows()
+	if len(subscribers) > 0 || len(flows) > 0 {
+		t.Nodes = append(t.Nodes, TopologyNode{ID: BrokerNodeID, Kind: TopologyNodeBroker})
+		nodes[BrokerNodeID] = true
+	}
+	for _, id := range subscribers {
+		t.Edges = append(t.Edges, TopologyEdge{From: BrokerNodeID, To: id, Kind: TopologyEdgeSubscription})
+	}
+	since := now.Add(-TopologyRateWindow)
+	for _, flow := range flows {
+		if flow.From == "" {
+			continue
+		}
+		to := flow.To
+		if to == "" {
+			to = BrokerNodeID
+		}
+		t.Edges = append(t.Edges, TopologyEdge{
+			From:  flow.From,
+			To:    to,
+			Kind:  TopologyEdgeMessage,
+			Count: flow.Count,
+			Rate:  float64(flow.Since(since)) / TopologyRateWindow.Minutes(),
+		})
+	}
+	t.Edges = append(t.Edges, c.taskEdges()...)
+
+	// Agents that left the swarm stay on the edges they had
+	for _, edge := range t.Edges {
+		for _, id := range []string{edge.From, edge.To} {
+			if !nodes[id] {
+				nodes[id] = true
+				t.Nodes = append(t.Nodes, TopologyNode{ID: id, Kind: TopologyNodeAgent, Removed: true})
+			}
+		}
+	}
+	return t
+}
+
+// taskEdges links the agents whose tasks spawned tasks of other agents, and
+// the agents whose tasks were copied to shadows, counting the tasks
+func (c *Coordinator) taskEdges() []TopologyEdge {
+	records := c.tasks.list()
+	agentOf := make(map[string]string, len(records))
+	for _, record := range records {
+		agentOf[record.Task.ID] = record.AgentID
+	}
+
+	type key struct {
+		from, to string
+		kind     TopologyEdgeKind
+	}
+	counts := make(map[key]int)
+	for _, record := range records {
+		if record.AgentID == "" {
+			continue
+		}
+		if parent := agentOf[record.SpawnedBy]; parent != "" {
+			counts[key{parent, record.AgentID, TopologyEdgeDependency}]++
+		}
+		for _, shadow := range record.Shadows {
+			counts[key{record.AgentID, shadow.AgentID, TopologyEdgeShadow}]++
+		}
+	}
+
+	edges := make([]TopologyEdge, 0, len(counts))
+	for k, count := range counts {
+		edges = append(edges, TopologyEdge{From: k.from, To: k.to, Kind: k.kind, Count: count})
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		return a.To < b.To
+	})
+	return edges
+}
+
+// dotEscaper escapes the IDs and labels of DOT graphs
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+// DOT renders the topology as a Graphviz graph, e.g. for `dot -Tsvg`
+func (t Topology) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph swarm {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box];\n")
+	for _, node := range t.Nodes {
+		if node.Kind == TopologyNodeBroker {
+			fmt.Fprintf(&b, "\t%s [shape=diamond];\n", dotQuote(node.ID))
+			continue
+		}
+		label := node.ID
+		if node.Type != "" {
+			label += "\n" + string(node.Type)
+		}
+		attrs := []string{"label=" + dotQuote(label)}
+		switch {
+		case node.Removed:
+			attrs = append(attrs, "style=dotted")
+		case node.Unreachable || node.Status == agent.AgentStatusError:
+			attrs = append(attrs, "color=red")
+		case node.Shadow:
+			attrs = append(attrs, "style=dashed")
+		}
+		fmt.Fprintf(&b, "\t%s [%s];\n", dotQuote(node.ID), strings.Join(attrs, ", "))
+	}
+	for _, edge := range t.Edges {
+		var attrs []string
+		switch edge.Kind {
+		case TopologyEdgeMessage:
+			label := fmt.Sprint(edge.Count)
+			if edge.Rate > 0 {
+				label += fmt.Sprintf(" (%.1f/min)", edge.Rate)
+			}
+			attrs = append(attrs, "label="+dotQuote(label))
+		case TopologyEdgeSubscription:
+			attrs = append(attrs, "style=dotted", "arrowhead=none")
+		case TopologyEdgeDependency:
+			attrs = append(attrs, "style=bold", "label="+dotQuote(fmt.Sprintf("%d tasks", edge.Count)))
+		case TopologyEdgeShadow:
+			attrs = append(attrs, "style=dashed", "color=gray", "label="+dotQuote(fmt.Sprintf("%d copies", edge.Count)))
+		}
+		fmt.Fprintf(&b, "\t%s -> %s [%s];\n", dotQuote(edge.From), dotQuote(edge.To), strings.Join(attrs, ", "))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package swarm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+)
+
+func TestGetTopology(t *testing.T) {
+	c, err := NewCoordinator(CoordinatorConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cancelFunc()
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b"} {
+		ag := &echoAgent{BaseAgent: agent.NewBaseAgent(agent.AgentConfig{ID: id, Type: agent.AgentTypeExecutor})}
+		if err := c.GetRegistry().RegisterAgent(ag); err != nil {
+			t.Fatal(err)
+		}
+	}
+	twin := &echoAgent{BaseAgent: agent.NewBaseAgent(agent.AgentConfig{ID: "twin", Type: agent.AgentTypeExecutor})}
+	if err := c.RegisterShadowAgent(twin); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two messages from a to b, one of them too old to count in the rate
+	now := time.Now()
+	for _, at := range []time.Time{now.Add(-time.Hour), now} {
+		if err := c.registry.SendMessage(ctx, "b", agent.Message{From: "a", Timestamp: at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.registry.BroadcastMessage(ctx, agent.Message{From: "b", Timestamp: now}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A task of a spawned a task of b, which the twin shadowed
+	for _, task := range []struct{ id, agentID string }{{"parent", "a"}, {"child", "b"}} {
+		if _, _, err := c.tasks.enqueue(agent.Task{ID: task.id, Type: "docs"}); err != nil {
+			t.Fatal(err)
+		}
+		c.tasks.start(task.id, task.agentID, func() {})
+	}
+	c.tasks.setSpawnedBy("child", "parent")
+	c.tasks.addShadow("child", ShadowResult{AgentID: "twin"})
+
+	topology := c.GetTopology()
+	nodes := make(map[string]TopologyNode)
+	for _, node := range topology.Nodes {
+		nodes[node.ID] = node
+	}
+	if len(nodes) != 4 || nodes[BrokerNodeID].Kind != TopologyNodeBroker || !nodes["twin"].Shadow || nodes["a"].Type != agent.AgentTypeExecutor {
+		t.Errorf("nodes = %+v, want a, b, the twin and the broker", topology.Nodes)
+	}
+
+	edges := make(map[TopologyEdge]bool)
+	for _, edge := range topology.Edges {
+		edges[edge] = true
+	}
+	for _, want := range []TopologyEdge{
+		{From: "a", To: "b", Kind: TopologyEdgeMessage, Count: 2, Rate: 1 / TopologyRateWindow.Minutes()},
+		{From: "b", To: BrokerNodeID, Kind: TopologyEdgeMessage, Count: 1, Rate: 1 / TopologyRateWindow.Minutes()},
+		{From: BrokerNodeID, To: "a", Kind: TopologyEdgeSubscription},
+		{From: BrokerNodeID, To: "twin", Kind: TopologyEdgeSubscription},
+		{From: "a", To: "b", Kind: TopologyEdgeDependency, Count: 1},
+		{From: "b", To: "twin", Kind: TopologyEdgeShadow, Count: 1},
+	} {
+		if !edges[want] {
+			t.Errorf("edges = %+v, missing %+v", topology.Edges, want)
+		}
+	}
+
+	dot := topology.DOT()
+	for _, want := range []string{
+		"digraph swarm {",
+		`"broker" [shape=diamond];`,
+		`"twin" [label="twin\nexecutor", style=dashed];`,
+		`"a" -> "b" [label="2 (0.2/min)"];`,
+		`"a" -> "b" [style=bold, label="1 tasks"];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT is missing %s:\n%s", want, dot)
+		}
+	}
+
+	// Agents that left keep their task edges, but not their messages
+	if err := c.registry.UnregisterAgent("a"); err != nil {
+		t.Fatal(err)
+	}
+	topology = c.GetTopology()
+	for _, node := range topology.Nodes {
+		if node.ID == "a" && !node.Removed {
+			t.Errorf("node a = %+v, want removed", node)
+		}
+	}
+	for _, edge := range topology.Edges {
+		if edge.Kind == TopologyEdgeMessage && edge.From == "a" {
+			t.Errorf("edge %+v of a removed agent", edge)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package vault
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// KeySize is the size of master keys, in bytes
+	KeySize = 32
+	// DefaultKeyEnv is the environment variable holding the master key
+	// unless configured otherwise
+	DefaultKeyEnv = "OPENCODE_SWARM_KEY"
+
+	// keychainService and keychainUser name the master key in the OS
+	// keychain
+	keychainService = "opencode"
+	keychainUser    = "swarm-master-key"
+)
+
+// GenerateKey returns a new random master key
+func GenerateKey() []byte {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// EncodeKey writes a master key as it is kept in the environment and the
+// keychain
+func EncodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func decodeKey(encoded, source string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != KeySize {
+		return nil, fmt.Errorf("the master key in %s must be %d bytes encoded as base64", source, KeySize)
+	}
+	return key, nil
+}
+
+// MasterKey returns the master key from the environment variable env,
+// DefaultKeyEnv if empty, or else from the OS keychain
+func MasterKey(env string) ([]byte, error) {
+	if env == "" {
+		env = DefaultKeyEnv
+	}
+	if encoded := os.Getenv(env); encoded != "" {
+		return decodeKey(encoded, "$"+env)
+	}
+	encoded, err := keyring.Get(keychainService, keychainUser)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, fmt.Errorf("%w: set $%s or store one in the keychain with opencode swarm keygen --keychain", ErrNoKey, env)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: $%s is not set and the keychain is unavailable: %v", ErrNoKey, env, err)
+	}
+	return decodeKey(encoded, "the keychain")
+}
+
+// ErrKeyExists means the keychain already holds a master key
+var ErrKeyExists = errors.New("the keychain already holds a master key")
+
+// StoreKeychainKey keeps a master key in the OS keychain. A key already
+// there is only replaced if replace is set, as data sealed with it can no
+// longer be read.
+func StoreKeychainKey(key []byte, replace bool) error {
+	if !replace {
+		_, err := keyring.Get(keychainService, keychainUser)
+		if err == nil {
+			return ErrKeyExists
+		}
+		if !errors.Is(err, keyring.ErrNotFound) {
+			return fmt.Errorf("failed to read the keychain: %w", err)
+		}
+	}
+	if err := keyring.Set(keychainService, keychainUser, EncodeKey(key)); err != nil {
+		return fmt.Errorf("failed to store the master key in the keychain: %w", err)
+	}
+	return nil
+}
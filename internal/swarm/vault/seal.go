@@ -0,0 +1,287 @@
+// Package vault encrypts what the swarm keeps on disk with a master key
+// from the environment or the OS keychain. Once a key is set, data in
+// plaintext is refused, so that files planted or left behind unencrypted
+// are not taken for sealed ones.
+package vault
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+var (
+	// ErrNoKey means data is encrypted but no master key was given
+	ErrNoKey = errors.New("no master key")
+	// ErrCorrupt means encrypted data was changed, truncated or sealed
+	// with another key
+	ErrCorrupt = errors.New("encrypted data is corrupt or sealed with another key")
+	// ErrNotSealed means a sealer with a key was handed plaintext
+	ErrNotSealed = errors.New("data is not encrypted")
+)
+
+// Sealed data starts with a NUL byte, which text and JSON never do
+var (
+	blobMagic   = []byte("\x00ocsb1")
+	streamMagic = []byte("\x00ocss1")
+)
+
+const (
+	// chunkSize is the most plaintext sealed per chunk of a stream
+	chunkSize = 64 << 10
+	// streamPrefixSize is the random part of the nonces of a stream, the
+	// rest counts chunks and marks the last one
+	streamPrefixSize = 7
+)
+
+// Sealer encrypts and decrypts data with AES-GCM under a key derived from
+// the master key. A nil Sealer leaves data in plaintext.
+type Sealer struct {
+	master []byte
+	aead   cipher.AEAD
+}
+
+// NewSealer creates a sealer for a master key of KeySize bytes
+func NewSealer(master []byte) (*Sealer, error) {
+	if len(master) != KeySize {
+		return nil, fmt.Errorf("master key must be %d bytes long, not %d", KeySize, len(master))
+	}
+	s := &Sealer{master: master}
+	block, err := aes.NewCipher(s.Key("at-rest", 32))
+	if err != nil {
+		return nil, err
+	}
+	if s.aead, err = cipher.NewGCM(block); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Key derives a key of size bytes for another purpose from the master key
+func (s *Sealer) Key(purpose string, size int) []byte {
+	key := make([]byte, size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, s.master, nil, []byte("opencode swarm "+purpose)), key); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// IsSealed returns whether data was sealed by a Sealer
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, blobMagic) || bytes.HasPrefix(data, streamMagic)
+}
+
+// Seal encrypts data
+func (s *Sealer) Seal(data []byte) ([]byte, error) {
+	if s == nil {
+		return data, nil
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := make([]byte, 0, len(blobMagic)+len(nonce)+len(data)+s.aead.Overhead())
+	sealed = append(sealed, blobMagic...)
+	sealed = append(sealed, nonce...)
+	return s.aead.Seal(sealed, nonce, data, nil), nil
+}
+
+// Open decrypts sealed data. A nil Sealer returns plaintext as is; a Sealer
+// with a key refuses it with ErrNotSealed.
+func (s *Sealer) Open(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, blobMagic) {
+		if s != nil {
+			return nil, ErrNotSealed
+		}
+		return data, nil
+	}
+	if s == nil {
+		return nil, fmt.Errorf("%w to decrypt with", ErrNoKey)
+	}
+	data = data[len(blobMagic):]
+	if len(data) < s.aead.NonceSize() {
+		return nil, ErrCorrupt
+	}
+	plain, err := s.aead.Open(nil, data[:s.aead.NonceSize()], data[s.aead.NonceSize():], nil)
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return plain, nil
+}
+
+// NewWriter returns a writer encrypting to w in chunks, for data too large
+// to seal at once. The stream is complete once the writer is closed; w is
+// not closed.
+func (s *Sealer) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	if s == nil {
+		return nopCloser{w}, nil
+	}
+	prefix := make([]byte, streamPrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(append(append([]byte(nil), streamMagic...), prefix...)); err != nil {
+		return nil, err
+	}
+	return &streamWriter{aead: s.aead, w: w, prefix: prefix, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+// NewReader returns a reader decrypting a stream written by NewWriter. Like
+// Open, only a nil Sealer reads plaintext.
+func (s *Sealer) NewReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(len(streamMagic) + streamPrefixSize)
+	if !bytes.HasPrefix(head, streamMagic) {
+		if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+			return nil, err
+		}
+		if s != nil {
+			return nil, ErrNotSealed
+		}
+		return br, nil
+	}
+	if s == nil {
+		return nil, fmt.Errorf("%w to decrypt with", ErrNoKey)
+	}
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	prefix := append([]byte(nil), head[len(streamMagic):]...)
+	_, _ = br.Discard(len(head))
+	return &streamReader{aead: s.aead, r: br, prefix: prefix}, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func streamNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, streamPrefixSize+5)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+// streamWriter seals chunks of chunkSize bytes, each written after its
+// length. The last chunk, possibly empty, is sealed with a nonce marking
+// it, so truncated streams do not decrypt.
+type streamWriter struct {
+	aead    cipher.AEAD
+	w       io.Writer
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	err     error
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	if sw.err != nil {
+		return 0, sw.err
+	}
+	written := 0
+	for len(p) > 0 {
+		if len(sw.buf) == chunkSize {
+			if sw.err = sw.flush(false); sw.err != nil {
+				return written, sw.err
+			}
+		}
+		n := min(chunkSize-len(sw.buf), len(p))
+		sw.buf = append(sw.buf, p[:n]...)
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (sw *streamWriter) flush(last bool) error {
+	sealed := sw.aead.Seal(nil, streamNonce(sw.prefix, sw.counter, last), sw.buf, nil)
+	sw.counter++
+	sw.buf = sw.buf[:0]
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(sealed)))
+	if _, err := sw.w.Write(length[:]); err != nil {
+		return err
+	}
+	_, err := sw.w.Write(sealed)
+	return err
+}
+
+func (sw *streamWriter) Close() error {
+	if sw.err != nil {
+		return sw.err
+	}
+	sw.err = sw.flush(true)
+	if sw.err == nil {
+		sw.err = errors.New("stream closed")
+		return nil
+	}
+	return sw.err
+}
+
+type streamReader struct {
+	aead    cipher.AEAD
+	r       *bufio.Reader
+	prefix  []byte
+	counter uint32
+	plain   []byte
+	done    bool
+}
+
+func (sr *streamReader) Read(p []byte) (int, error) {
+	for len(sr.plain) == 0 {
+		if sr.done {
+			return 0, io.EOF
+		}
+		if err := sr.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, sr.plain)
+	sr.plain = sr.plain[n:]
+	return n, nil
+}
+
+// next decrypts the next chunk
+func (sr *streamReader) next() error {
+	var length [4]byte
+	if _, err := io.ReadFull(sr.r, length[:]); err != nil {
+		// The last chunk is missing
+		return ErrCorrupt
+	}
+	size := binary.BigEndian.Uint32(length[:])
+	if size > chunkSize+uint32(sr.aead.Overhead()) {
+		return ErrCorrupt
+	}
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(sr.r, sealed); err != nil {
+		return ErrCorrupt
+	}
+	plain, err := sr.aead.Open(nil, streamNonce(sr.prefix, sr.counter, false), sealed, nil)
+	if err != nil {
+		if plain, err = sr.aead.Open(nil, streamNonce(sr.prefix, sr.counter, true), sealed, nil); err != nil {
+			return ErrCorrupt
+		}
+		sr.done = true
+		if _, err := sr.r.ReadByte(); err != io.EOF {
+			// Data after the last chunk
+			return ErrCorrupt
+		}
+	}
+	sr.counter++
+	sr.plain = plain
+	return nil
+}
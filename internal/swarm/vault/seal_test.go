@@ -0,0 +1,100 @@
+package vault
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestSeal(t *testing.T) {
+	s, err := NewSealer(GenerateKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := []byte(`{"task":"t1"}`)
+
+	sealed, err := s.Seal(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsSealed(sealed) || bytes.Contains(sealed, plain) {
+		t.Fatalf("Seal() = %q, want it encrypted", sealed)
+	}
+	if opened, err := s.Open(sealed); err != nil || !bytes.Equal(opened, plain) {
+		t.Errorf("Open() = %q, %v, want %q", opened, err, plain)
+	}
+	if _, err := s.Open(plain); !errors.Is(err, ErrNotSealed) {
+		t.Errorf("Open() of plaintext = %v, want ErrNotSealed", err)
+	}
+	if opened, err := (*Sealer)(nil).Open(plain); err != nil || !bytes.Equal(opened, plain) {
+		t.Errorf("Open() of plaintext without a key = %q, %v, want it as is", opened, err)
+	}
+	if _, err := (*Sealer)(nil).Open(sealed); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Open() without a key = %v, want ErrNoKey", err)
+	}
+	other, _ := NewSealer(GenerateKey())
+	if _, err := other.Open(sealed); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("Open() with another key = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestStream(t *testing.T) {
+	s, err := NewSealer(GenerateKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// More than two chunks
+	plain := bytes.Repeat([]byte("log line\n"), 2*chunkSize/9+100)
+
+	var buf bytes.Buffer
+	w, err := s.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sealed := buf.Bytes()
+
+	read := func(data []byte) ([]byte, error) {
+		r, err := s.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(r)
+	}
+	if got, err := read(sealed); err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("read %d bytes, %v, want %d", len(got), err, len(plain))
+	}
+	if _, err := read(plain); !errors.Is(err, ErrNotSealed) {
+		t.Errorf("plaintext stream: %v, want ErrNotSealed", err)
+	}
+	var none *Sealer
+	if r, err := none.NewReader(bytes.NewReader(plain)); err != nil {
+		t.Error(err)
+	} else if got, _ := io.ReadAll(r); !bytes.Equal(got, plain) {
+		t.Errorf("nil Sealer read %d bytes of plaintext, want them as is", len(got))
+	}
+	if _, err := read(sealed[:len(sealed)-100]); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("truncated stream: %v, want ErrCorrupt", err)
+	}
+	if _, err := read(sealed[:chunkSize+100]); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("stream cut after a chunk: %v, want ErrCorrupt", err)
+	}
+}
+
+func TestMasterKey(t *testing.T) {
+	key := GenerateKey()
+	t.Setenv("TEST_SWARM_KEY", EncodeKey(key))
+	if got, err := MasterKey("TEST_SWARM_KEY"); err != nil || !bytes.Equal(got, key) {
+		t.Errorf("MasterKey() = %x, %v, want %x", got, err, key)
+	}
+	t.Setenv("TEST_SWARM_KEY", "too short")
+	if _, err := MasterKey("TEST_SWARM_KEY"); err == nil {
+		t.Error("MasterKey() accepted a key that is not 32 bytes of base64")
+	}
+}
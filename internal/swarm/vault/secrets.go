@@ -0,0 +1,169 @@
+package vault
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+)
+
+// ErrSecretNotFound means no secret is stored under a name
+var ErrSecretNotFound = errors.New("secret not found")
+
+// secretPrefix names the secrets of the swarm in the OS keychain
+const secretPrefix = "swarm-secret-"
+
+// Secrets keeps named secrets, like the API keys of model providers, in the
+// OS keychain. Where no keychain is available they are kept in a file
+// encrypted with the master key instead.
+type Secrets struct {
+	file string
+	mu   sync.Mutex
+}
+
+// NewSecrets creates a secret store falling back to file, which is sealed
+// with the master key of DefaultKeyEnv or the keychain
+func NewSecrets(file string) *Secrets {
+	return &Secrets{file: file}
+}
+
+// DefaultSecretsFile is where secrets are kept without a keychain, in the
+// opencode directory of the user's configuration
+func DefaultSecretsFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "opencode", "swarm-secrets")
+}
+
+// Get returns a secret
+func (s *Secrets) Get(name string) (string, error) {
+	value, err := keyring.Get(keychainService, secretPrefix+name)
+	if err == nil {
+		return value, nil
+	}
+	// Secrets stored while the keychain was unavailable are in the file
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, fileErr := s.read()
+	if fileErr != nil {
+		return "", fileErr
+	}
+	if value, ok := secrets[name]; ok {
+		return value, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+}
+
+// Set stores a secret, in the file if the keychain is unavailable
+func (s *Secrets) Set(name, value string) error {
+	if err := keyring.Set(keychainService, secretPrefix+name, value); err == nil {
+		// Do not leave an older value behind in the file
+		_, err := s.deleteFromFile(name)
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.read()
+	if err != nil {
+		return err
+	}
+	if secrets == nil {
+		secrets = make(map[string]string)
+	}
+	secrets[name] = value
+	return s.write(secrets)
+}
+
+// Delete removes a secret from the keychain and the file
+func (s *Secrets) Delete(name string) error {
+	keychainErr := keyring.Delete(keychainService, secretPrefix+name)
+	inFile, err := s.deleteFromFile(name)
+	if err != nil {
+		return err
+	}
+	if keychainErr != nil && !inFile {
+		return fmt.Errorf("%w: %s", ErrSecretNotFound, name)
+	}
+	return nil
+}
+
+// deleteFromFile removes a secret from the file and returns whether it was
+// there
+func (s *Secrets) deleteFromFile(name string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets, err := s.read()
+	if err != nil {
+		return false, err
+	}
+	if _, ok := secrets[name]; !ok {
+		return false, nil
+	}
+	delete(secrets, name)
+	return true, s.write(secrets)
+}
+
+// read returns the secrets in the file, none if it does not exist
+func (s *Secrets) read() (map[string]string, error) {
+	data, err := os.ReadFile(s.file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+	sealer, err := s.sealer()
+	if err != nil {
+		return nil, err
+	}
+	if data, err = sealer.Open(data); err != nil {
+		return nil, fmt.Errorf("failed to read secrets from %s: %w", s.file, err)
+	}
+	var secrets map[string]string
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to read secrets from %s: %w", s.file, err)
+	}
+	return secrets, nil
+}
+
+// write replaces the file with the sealed secrets
+func (s *Secrets) write(secrets map[string]string) error {
+	sealer, err := s.sealer()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	if data, err = sealer.Seal(data); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.file), 0o700); err != nil {
+		return fmt.Errorf("failed to store secrets: %w", err)
+	}
+	tmp := s.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to store secrets: %w", err)
+	}
+	if err := os.Rename(tmp, s.file); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to store secrets: %w", err)
+	}
+	return nil
+}
+
+func (s *Secrets) sealer() (*Sealer, error) {
+	key, err := MasterKey("")
+	if err != nil {
+		return nil, fmt.Errorf("secrets kept in %s without a keychain need the master key: %w", s.file, err)
+	}
+	return NewSealer(key)
+}
@@ -0,0 +1,53 @@
+package vault
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestSecrets(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secrets")
+	s := NewSecrets(file)
+
+	keyring.MockInit()
+	if err := s.Set("openrouter", "sk-keychain"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.Get("openrouter"); err != nil || got != "sk-keychain" {
+		t.Errorf("Get() = %q, %v, want the key from the keychain", got, err)
+	}
+	if _, err := os.Stat(file); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the file was written while the keychain was available: %v", err)
+	}
+
+	// Without a keychain secrets go to the file, sealed with the master key
+	keyring.MockInitWithError(errors.New("no keychain"))
+	t.Setenv(DefaultKeyEnv, "")
+	if err := s.Set("huggingface", "hf-file"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Set() without a master key = %v, want ErrNoKey", err)
+	}
+	t.Setenv(DefaultKeyEnv, EncodeKey(GenerateKey()))
+	if err := s.Set("huggingface", "hf-file"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(file); err != nil || strings.Contains(string(data), "hf-file") {
+		t.Errorf("secrets file = %q, %v, want it encrypted", data, err)
+	}
+	if got, err := s.Get("huggingface"); err != nil || got != "hf-file" {
+		t.Errorf("Get() = %q, %v, want the key from the file", got, err)
+	}
+	if err := s.Delete("huggingface"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("huggingface"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Get() after Delete() = %v, want ErrSecretNotFound", err)
+	}
+	if err := s.Delete("huggingface"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Delete() of a missing secret = %v, want ErrSecretNotFound", err)
+	}
+}
@@ -0,0 +1,161 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/locks"
+)
+
+// DefaultVerificationTimeout is how long the verification of a result may
+// take unless configured otherwise
+const DefaultVerificationTimeout = 5 * time.Minute
+
+// reviewerPollInterval is how often a verification waiting for a busy
+// reviewer looks for an idle one
+const reviewerPollInterval = time.Second
+
+// VerificationConfig makes the successful results of a task type wait for
+// another agent to verify them before they are accepted, recorded and
+// learned from. The reviewer gets a task of its type whose input holds the
+// task and its output; a testing agent, for example, runs the tests. A
+// result the reviewer fails fails the task.
+type VerificationConfig struct {
+	// Reviewer is the type of the agents that verify results
+	Reviewer agent.AgentType
+	// Timeout bounds the verification including the wait for an idle
+	// reviewer, DefaultVerificationTimeout if zero
+	Timeout time.Duration
+	// Required fails results nobody verified in time. Otherwise they are
+	// accepted unverified.
+	Required bool
+}
+
+// verifyResult has a reviewer verify the successful result of a task of a
+// type with verification configured. The reviewer's artifacts are attached
+// to the result, and a rejected or, if required, unverified result fails.
+// Reviewers that return an error rather than a failed result could not
+// verify it.
+func (c *Coordinator) verifyResult(ag agent.Agent, task agent.Task, result *agent.TaskResult) {
+	config, ok := c.verification[task.Type]
+	if !ok || !result.Success {
+		return
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultVerificationTimeout
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, timeout)
+	defer cancel()
+
+	review := agent.Task{
+		ID:          task.ID + "-verify",
+		Type:        string(config.Reviewer),
+		Description: fmt.Sprintf("Verify the result of task %s: %s", task.ID, task.Description),
+		Input: map[string]interface{}{
+			"task":        task.ID,
+			"type":        task.Type,
+			"description": task.Description,
+			"input":       task.Input,
+			"output":      result.Output,
+			"agent":       ag.GetID(),
+		},
+		CreatedAt: c.clock.Now(),
+	}
+	reviewer, verdict, err := c.runReview(ctx, ag.GetID(), review)
+	if err != nil && !errors.Is(err, ErrResultUnverified) {
+		// Reviewers failing to run could not tell whether the result holds
+		err = fmt.Errorf("%w: %s could not verify it: %v", ErrResultUnverified, reviewer, err)
+	}
+	if errors.Is(err, ErrResultUnverified) {
+		summary := "Accepted result unverified: "
+		if config.Required {
+			result.Success = false
+			result.Error = err
+			summary = "Rejected unverified result: "
+		}
+		c.timeline.record(TimelineVerification, task.ID, summary+err.Error(), map[string]interface{}{
+			"reviewer": string(config.Reviewer),
+			"agent":    ag.GetID(),
+		})
+		return
+	}
+
+	passed := verdict.Success
+	reason := "no reason given"
+	if verdict.Error != nil {
+		reason = verdict.Error.Error()
+	}
+	result.Artifacts = append(result.Artifacts, verdict.Artifacts...)
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]interface{})
+	}
+	result.Metadata["verifiedBy"] = reviewer
+	result.Metadata["verified"] = passed
+
+	summary := fmt.Sprintf("Result of task %s verified by %s", task.ID, reviewer)
+	if !passed {
+		result.Success = false
+		result.Error = fmt.Errorf("%w by %s: %s", ErrResultRejected, reviewer, reason)
+		summary = fmt.Sprintf("Result of task %s rejected by %s: %s", task.ID, reviewer, reason)
+	}
+	c.timeline.record(TimelineVerification, task.ID, summary, map[string]interface{}{
+		"reviewer": reviewer,
+		"agent":    ag.GetID(),
+		"passed":   passed,
+	})
+}
+
+// runReview runs a review task on the best idle agent that can handle it,
+// other than the one that produced the result, waiting for one while all
+// are busy. It returns ErrResultUnverified if no other running agent can
+// handle the review, or none was idle in time.
+func (c *Coordinator) runReview(ctx context.Context, authorID string, review agent.Task) (string, *agent.TaskResult, error) {
+	for {
+		able := false
+		for _, ag := range c.registry.GetAllAgents() {
+			if ag.GetID() != authorID && !c.registry.IsShadow(ag.GetID()) && ag.GetStatus() != agent.AgentStatusStopped && ag.CanHandleTask(review) {
+				able = true
+				break
+			}
+		}
+		if !able {
+			return "", nil, fmt.Errorf("%w: no %s agent to verify it", ErrResultUnverified, review.Type)
+		}
+
+		agents, _ := c.registry.RankAgentsForTask(review)
+		for _, reviewer := range agents {
+			if reviewer.GetID() == authorID {
+				continue
+			}
+			verdict, err := c.executeReview(ctx, reviewer, review)
+			if errors.Is(err, agent.ErrAgentBusy) {
+				continue
+			}
+			return reviewer.GetID(), verdict, err
+		}
+
+		select {
+		case <-c.clock.After(reviewerPollInterval):
+		case <-ctx.Done():
+			return "", nil, fmt.Errorf("%w: no %s agent was idle in time", ErrResultUnverified, review.Type)
+		}
+	}
+}
+
+// executeReview runs a review task on a reviewer with its grants and locks
+// of its own
+func (c *Coordinator) executeReview(ctx context.Context, reviewer agent.Agent, review agent.Task) (*agent.TaskResult, error) {
+	ctx = locks.WithOwner(ctx, c.locks, locks.Owner{TaskID: review.ID, AgentID: reviewer.GetID()})
+	ctx = agent.WithGrants(ctx, reviewer.GetID(), c.grants.of(reviewer))
+	defer c.locks.ReleaseAll(review.ID)
+
+	verdict, err := reviewer.ExecuteTask(ctx, review)
+	if err == nil && verdict == nil {
+		err = fmt.Errorf("reviewer %s returned no result", reviewer.GetID())
+	}
+	return verdict, err
+}
@@ -0,0 +1,100 @@
+package swarm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+)
+
+// judgeAgent verifies results whose response is "ok" and attaches its
+// report
+type judgeAgent struct {
+	*agent.BaseAgent
+}
+
+func (a *judgeAgent) CanHandleTask(task agent.Task) bool {
+	return task.Type == "review"
+}
+
+func (a *judgeAgent) ExecuteTask(ctx context.Context, task agent.Task) (*agent.TaskResult, error) {
+	output, _ := task.Input["output"].(map[string]interface{})
+	result := &agent.TaskResult{
+		TaskID:      task.ID,
+		Success:     output["response"] == "ok",
+		AgentID:     a.GetID(),
+		CompletedAt: time.Now(),
+		Artifacts:   []agent.Artifact{{Name: "review.txt", Data: []byte("reviewed")}},
+	}
+	if !result.Success {
+		result.Error = fmt.Errorf("response %v is not ok", output["response"])
+	}
+	return result, nil
+}
+
+func TestVerifyResult(t *testing.T) {
+	c, err := NewCoordinator(CoordinatorConfig{Verification: map[string]VerificationConfig{
+		"build":  {Reviewer: "review"},
+		"deploy": {Reviewer: "review", Required: true},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cancelFunc()
+
+	author := &echoAgent{BaseAgent: agent.NewBaseAgent(agent.AgentConfig{ID: "author", Type: agent.AgentTypeExecutor})}
+	if err := c.GetRegistry().RegisterAgent(author); err != nil {
+		t.Fatal(err)
+	}
+	verify := func(taskType, response string) *agent.TaskResult {
+		t.Helper()
+		task := agent.Task{ID: taskType + "-" + response, Type: taskType, Description: "make it " + response}
+		result := &agent.TaskResult{
+			TaskID:  task.ID,
+			Success: true,
+			Output:  map[string]interface{}{"response": response},
+			AgentID: author.GetID(),
+		}
+		c.verifyResult(author, task, result)
+		return result
+	}
+
+	// Without a reviewer results are accepted unverified unless required.
+	// The author does not review its own result.
+	if result := verify("build", "ok"); !result.Success {
+		t.Fatalf("unverified build failed: %v", result.Error)
+	}
+	if result := verify("deploy", "ok"); result.Success || !errors.Is(result.Error, ErrResultUnverified) {
+		t.Fatalf("unverified deploy = %v, %v, want ErrResultUnverified", result.Success, result.Error)
+	}
+
+	judge := &judgeAgent{BaseAgent: agent.NewBaseAgent(agent.AgentConfig{ID: "judge", Type: "review"})}
+	if err := judge.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer judge.Stop()
+	if err := c.GetRegistry().RegisterAgent(judge); err != nil {
+		t.Fatal(err)
+	}
+	result := verify("build", "ok")
+	if !result.Success || result.Metadata["verifiedBy"] != "judge" || len(result.Artifacts) != 1 {
+		t.Fatalf("verified build = %v by %v with %d artifacts, want success by judge with its report",
+			result.Success, result.Metadata["verifiedBy"], len(result.Artifacts))
+	}
+	if result := verify("build", "broken"); result.Success || !errors.Is(result.Error, ErrResultRejected) {
+		t.Fatalf("rejected build = %v, %v, want ErrResultRejected", result.Success, result.Error)
+	}
+
+	// Task types without verification are left alone
+	if result := verify("lint", "broken"); !result.Success || result.Metadata != nil {
+		t.Errorf("unverified lint = %v with %v, want it untouched", result.Success, result.Metadata)
+	}
+
+	verifications := c.Timeline(TimelineFilter{Types: []TimelineEventType{TimelineVerification}})
+	if len(verifications) != 4 {
+		t.Errorf("%d verification events, want 4", len(verifications))
+	}
+}
@@ -3,10 +3,12 @@ package voting
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
 )
 
 // VoteType defines different voting mechanisms
@@ -20,6 +22,9 @@ const (
 	VoteTypeConsensus VoteType = "consensus"  // Iterative consensus building
 )
 
+// HumanVoterID is the voter ID used for votes and vetoes cast by the user
+const HumanVoterID = "human"
+
 // Vote represents a single vote
 type Vote struct {
 	AgentID   string
@@ -36,6 +41,8 @@ type VoteProposal struct {
 	ProposedBy  string
 	Options     []string
 	Context     map[string]interface{}
+	// Tags label the proposal for listing, e.g. "destructive"
+	Tags        []string
 	CreatedAt   time.Time
 	Deadline    time.Time
 }
@@ -63,18 +70,31 @@ type VoteResult struct {
 	Confidence    float64 // Average confidence
 	Reasoning     []string
 	CompletedAt   time.Time
+	Vetoed        bool   // Rejected by a veto regardless of the votes
+	VetoedBy      string
+	Expired       bool   // Rejected for want of a decision in time
 }
 
 // DemocraticVotingSystem coordinates voting among agents
 type DemocraticVotingSystem struct {
 	sessions map[string]*VoteSession
 	mu       sync.RWMutex
+	clock    clock.Clock
+	index    *sessionIndex
 }
 
 // NewDemocraticVotingSystem creates a new voting system
 func NewDemocraticVotingSystem() *DemocraticVotingSystem {
+	return NewDemocraticVotingSystemWithClock(clock.Real)
+}
+
+// NewDemocraticVotingSystemWithClock creates a voting system that checks
+// deadlines and timestamps votes with clk
+func NewDemocraticVotingSystemWithClock(clk clock.Clock) *DemocraticVotingSystem {
 	return &DemocraticVotingSystem{
 		sessions: make(map[string]*VoteSession),
+		clock:    clk,
+		index:    newSessionIndex(),
 	}
 }
 
@@ -93,7 +113,7 @@ func (dvs *DemocraticVotingSystem) CreateVoteSession(
 	}
 	
 	if proposal.CreatedAt.IsZero() {
-		proposal.CreatedAt = time.Now()
+		proposal.CreatedAt = dvs.clock.Now()
 	}
 	
 	session := &VoteSession{
@@ -106,6 +126,7 @@ func (dvs *DemocraticVotingSystem) CreateVoteSession(
 	}
 	
 	dvs.sessions[session.ID] = session
+	dvs.index.add(session)
 	return session, nil
 }
 
@@ -116,31 +137,123 @@ func (dvs *DemocraticVotingSystem) CastVote(sessionID string, vote Vote) error {
 	dvs.mu.RUnlock()
 	
 	if !exists {
-		return fmt.Errorf("vote session not found: %s", sessionID)
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
 	}
 	
 	session.mu.Lock()
 	defer session.mu.Unlock()
 	
 	if session.Completed {
-		return fmt.Errorf("vote session already completed")
+		return fmt.Errorf("%w: %s", ErrSessionCompleted, sessionID)
 	}
 	
-	if time.Now().After(session.Proposal.Deadline) {
-		return fmt.Errorf("vote deadline passed")
+	if dvs.clock.Now().After(session.Proposal.Deadline) {
+		return fmt.Errorf("%w: %s", ErrDeadlinePassed, sessionID)
 	}
 	
-	vote.Timestamp = time.Now()
+	vote.Timestamp = dvs.clock.Now()
 	session.Votes[vote.AgentID] = vote
 	
 	// Check if we can finalize
 	if len(session.Votes) >= session.MinVoters {
 		dvs.finalizeVote(session)
+		dvs.index.complete(session.ID, session.Result.CompletedAt)
 	}
 	
 	return nil
 }
 
+// Veto rejects a vote session immediately, regardless of the votes cast
+func (dvs *DemocraticVotingSystem) Veto(sessionID, vetoedBy, reason string) error {
+	dvs.mu.RLock()
+	session, exists := dvs.sessions[sessionID]
+	dvs.mu.RUnlock()
+	
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+	
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	
+	if session.Completed {
+		return fmt.Errorf("%w: %s", ErrSessionCompleted, sessionID)
+	}
+	
+	dvs.finalizeVote(session)
+	dvs.index.complete(session.ID, session.Result.CompletedAt)
+	session.Result.Decision = false
+	session.Result.Vetoed = true
+	session.Result.VetoedBy = vetoedBy
+	if reason != "" {
+		session.Result.Reasoning = append(session.Result.Reasoning, fmt.Sprintf("veto by %s: %s", vetoedBy, reason))
+	}
+	
+	return nil
+}
+
+// Expire rejects a vote session that was not decided in time, so that it
+// takes no more votes, and returns its result. A session decided meanwhile
+// keeps its result, returned with ErrSessionCompleted.
+func (dvs *DemocraticVotingSystem) Expire(sessionID string) (*VoteResult, error) {
+	dvs.mu.RLock()
+	session, exists := dvs.sessions[sessionID]
+	dvs.mu.RUnlock()
+	
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+	
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	
+	if session.Completed {
+		return session.Result, fmt.Errorf("%w: %s", ErrSessionCompleted, sessionID)
+	}
+	
+	dvs.finalizeVote(session)
+	dvs.index.complete(session.ID, session.Result.CompletedAt)
+	session.Result.Decision = false
+	session.Result.Expired = true
+	session.Result.Reasoning = append(session.Result.Reasoning, "expired without a decision")
+	
+	return session.Result, nil
+}
+
+// GetSession retrieves a vote session by ID
+func (dvs *DemocraticVotingSystem) GetSession(sessionID string) (*VoteSession, error) {
+	dvs.mu.RLock()
+	defer dvs.mu.RUnlock()
+	
+	session, exists := dvs.sessions[sessionID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+	return session, nil
+}
+
+// GetVotes returns a copy of the votes cast so far, oldest first
+func (s *VoteSession) GetVotes() []Vote {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	
+	votes := make([]Vote, 0, len(s.Votes))
+	for _, vote := range s.Votes {
+		votes = append(votes, vote)
+	}
+	sort.Slice(votes, func(i, j int) bool {
+		return votes[i].Timestamp.Before(votes[j].Timestamp)
+	})
+	return votes
+}
+
+// IsCompleted returns whether the session has a result
+func (s *VoteSession) IsCompleted() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Completed
+}
+
 // GetVoteResult retrieves the result of a vote session
 func (dvs *DemocraticVotingSystem) GetVoteResult(sessionID string) (*VoteResult, error) {
 	dvs.mu.RLock()
@@ -148,14 +261,14 @@ func (dvs *DemocraticVotingSystem) GetVoteResult(sessionID string) (*VoteResult,
 	dvs.mu.RUnlock()
 	
 	if !exists {
-		return nil, fmt.Errorf("vote session not found: %s", sessionID)
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
 	}
 	
 	session.mu.RLock()
 	defer session.mu.RUnlock()
 	
 	if !session.Completed {
-		return nil, fmt.Errorf("vote session not completed")
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotCompleted, sessionID)
 	}
 	
 	return session.Result, nil
@@ -166,6 +279,13 @@ func (dvs *DemocraticVotingSystem) WaitForResult(
 	ctx context.Context,
 	sessionID string,
 ) (*VoteResult, error) {
+	// Votes are often all cast by the time anyone waits
+	if result, err := dvs.GetVoteResult(sessionID); err == nil {
+		return result, nil
+	}
+	
+	// Poll in real time, votes arrive from other goroutines whatever the
+	// clock shows
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 	
@@ -226,7 +346,7 @@ func (dvs *DemocraticVotingSystem) finalizeVote(session *VoteSession) {
 		YesPercentage: yesPercentage,
 		Confidence:    avgConfidence,
 		Reasoning:     reasoning,
-		CompletedAt:   time.Now(),
+		CompletedAt:   dvs.clock.Now(),
 	}
 	
 	session.Completed = true
@@ -275,21 +395,18 @@ func (dvs *DemocraticVotingSystem) determineDecision(
 	}
 }
 
-// GetActiveSessions returns all active voting sessions
+// GetActiveSessions returns all active voting sessions, soonest deadline
+// first
 func (dvs *DemocraticVotingSystem) GetActiveSessions() []*VoteSession {
-	dvs.mu.RLock()
-	defer dvs.mu.RUnlock()
-	
-	var active []*VoteSession
-	for _, session := range dvs.sessions {
-		session.mu.RLock()
-		if !session.Completed {
-			active = append(active, session)
-		}
-		session.mu.RUnlock()
-	}
-	
-	return active
+	return dvs.ListSessions(SessionFilter{Status: SessionOpen})
+}
+
+// ListSessions returns the sessions matching filter, soonest deadline
+// first. Sessions are found through indexes without locking them, e.g.
+// open votes tagged "destructive", or those due within a minute with
+// DueBefore.
+func (dvs *DemocraticVotingSystem) ListSessions(filter SessionFilter) []*VoteSession {
+	return dvs.index.list(filter)
 }
 
 // CleanupCompletedSessions removes old completed sessions
@@ -297,18 +414,8 @@ func (dvs *DemocraticVotingSystem) CleanupCompletedSessions(olderThan time.Durat
 	dvs.mu.Lock()
 	defer dvs.mu.Unlock()
 	
-	cutoff := time.Now().Add(-olderThan)
-	toDelete := make([]string, 0)
-	
-	for id, session := range dvs.sessions {
-		session.mu.RLock()
-		if session.Completed && session.Result.CompletedAt.Before(cutoff) {
-			toDelete = append(toDelete, id)
-		}
-		session.mu.RUnlock()
-	}
-	
-	for _, id := range toDelete {
+	cutoff := dvs.clock.Now().Add(-olderThan)
+	for _, id := range dvs.index.removeCompleted(cutoff) {
 		delete(dvs.sessions, id)
 	}
 }
@@ -341,7 +448,7 @@ func (cb *ConsensusBuilder) RunConsensusRound(ctx context.Context, minVoters int
 	cb.currentRound++
 	
 	if cb.currentRound > cb.maxRounds {
-		return nil, false, fmt.Errorf("max rounds exceeded")
+		return nil, false, ErrMaxRounds
 	}
 	
 	session, err := cb.votingSystems.CreateVoteSession(
@@ -0,0 +1,120 @@
+package voting
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/perfbudget"
+)
+
+const benchVoters = 50
+
+// newBenchSession returns a session with a vote from every voter
+func newBenchSession(voteType VoteType) *VoteSession {
+	session := &VoteSession{
+		ID:           "bench",
+		VoteType:     voteType,
+		Votes:        make(map[string]Vote, benchVoters),
+		MinVoters:    benchVoters,
+		AgentWeights: make(map[string]float64, benchVoters),
+	}
+	for i := 0; i < benchVoters; i++ {
+		id := fmt.Sprintf("agent-%d", i)
+		session.Votes[id] = Vote{
+			AgentID:    id,
+			Decision:   i%3 != 0,
+			Confidence: float64(i%10) / 10,
+			Reasoning:  "capability assessment",
+			Timestamp:  time.Now(),
+		}
+		session.AgentWeights[id] = 1 + float64(i%4)/4
+	}
+	return session
+}
+
+func benchmarkFinalizeVote(b *testing.B, voteType VoteType) {
+	dvs := NewDemocraticVotingSystem()
+	session := newBenchSession(voteType)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dvs.finalizeVote(session)
+	}
+}
+
+func BenchmarkFinalizeVoteMajority(b *testing.B) {
+	benchmarkFinalizeVote(b, VoteTypeMajority)
+}
+
+func BenchmarkFinalizeVoteWeighted(b *testing.B) {
+	benchmarkFinalizeVote(b, VoteTypeWeighted)
+}
+
+// BenchmarkVoteSession measures a whole session, from creation until the
+// last vote finalizes it
+func BenchmarkVoteSession(b *testing.B) {
+	dvs := NewDemocraticVotingSystem()
+	votes := newBenchSession(VoteTypeMajority).GetVotes()
+	proposal := VoteProposal{
+		Description: "Should we execute the task",
+		Deadline:    time.Now().Add(time.Hour),
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		session, err := dvs.CreateVoteSession(proposal, VoteTypeMajority, benchVoters, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, vote := range votes {
+			if err := dvs.CastVote(session.ID, vote); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if !session.IsCompleted() {
+			b.Fatal("session not finalized")
+		}
+	}
+}
+
+// BenchmarkListOpenTagged lists the open sessions with a tag among many
+// completed ones
+func BenchmarkListOpenTagged(b *testing.B) {
+	dvs := NewDemocraticVotingSystem()
+	for i := 0; i < 10000; i++ {
+		proposal := VoteProposal{Deadline: time.Now().Add(time.Hour)}
+		if i%100 == 0 {
+			proposal.Tags = []string{"destructive"}
+		}
+		session, err := dvs.CreateVoteSession(proposal, VoteTypeMajority, 1, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if i%10 != 0 {
+			if err := dvs.CastVote(session.ID, Vote{AgentID: "agent"}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	filter := SessionFilter{Status: SessionOpen, Tag: "destructive"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if len(dvs.ListSessions(filter)) != 100 {
+			b.Fatal("wrong sessions listed")
+		}
+	}
+}
+
+func TestPerformanceBudgets(t *testing.T) {
+	perfbudget.Enforce(t,
+		perfbudget.Budget{Name: "FinalizeVoteMajority", Bench: BenchmarkFinalizeVoteMajority, MaxTime: 20 * time.Microsecond, MaxAllocs: 10},
+		perfbudget.Budget{Name: "FinalizeVoteWeighted", Bench: BenchmarkFinalizeVoteWeighted, MaxTime: 20 * time.Microsecond, MaxAllocs: 2},
+		perfbudget.Budget{Name: "VoteSession", Bench: BenchmarkVoteSession, MaxTime: 200 * time.Microsecond, MaxAllocs: 40},
+		perfbudget.Budget{Name: "ListOpenTagged", Bench: BenchmarkListOpenTagged, MaxTime: 100 * time.Microsecond, MaxAllocs: 2},
+	)
+}
@@ -0,0 +1,18 @@
+package voting
+
+import "errors"
+
+// Common errors
+var (
+	// ErrSessionNotFound means no vote session with the ID exists
+	ErrSessionNotFound = errors.New("vote session not found")
+	// ErrSessionCompleted means the session was finalized and takes no
+	// more votes
+	ErrSessionCompleted = errors.New("vote session already completed")
+	// ErrSessionNotCompleted means the session has no result yet
+	ErrSessionNotCompleted = errors.New("vote session not completed")
+	// ErrDeadlinePassed means the vote arrived after the session deadline
+	ErrDeadlinePassed = errors.New("vote deadline passed")
+	// ErrMaxRounds means a consensus builder ran out of rounds
+	ErrMaxRounds = errors.New("max rounds exceeded")
+)
@@ -0,0 +1,204 @@
+package voting
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SessionStatus is whether a vote session still takes votes
+type SessionStatus string
+
+const (
+	SessionOpen      SessionStatus = "open"
+	SessionCompleted SessionStatus = "completed"
+)
+
+// SessionFilter selects the sessions listed by ListSessions. Empty fields
+// match every session.
+type SessionFilter struct {
+	Status SessionStatus
+	// Tag matches sessions whose proposal has the tag
+	Tag string
+	// DueBefore matches open sessions with a deadline before it
+	DueBefore time.Time
+}
+
+// indexEntry is what the index knows about a session, so listing never
+// locks sessions
+type indexEntry struct {
+	session     *VoteSession
+	status      SessionStatus
+	completedAt time.Time
+}
+
+// sessionIndex finds sessions by status, proposal tag and deadline. Its
+// lock is always taken last, after any session lock.
+type sessionIndex struct {
+	mu       sync.RWMutex
+	entries  map[string]*indexEntry
+	byStatus map[SessionStatus]map[string]*indexEntry
+	byTag    map[string]map[string]*indexEntry
+	// open sessions, soonest deadline first, without deadlines last
+	byDeadline []*indexEntry
+}
+
+func newSessionIndex() *sessionIndex {
+	return &sessionIndex{
+		entries: make(map[string]*indexEntry),
+		byStatus: map[SessionStatus]map[string]*indexEntry{
+			SessionOpen:      make(map[string]*indexEntry),
+			SessionCompleted: make(map[string]*indexEntry),
+		},
+		byTag: make(map[string]map[string]*indexEntry),
+	}
+}
+
+// deadlineBefore orders sessions by deadline, those without one last
+func deadlineBefore(a, b *indexEntry) bool {
+	da, db := a.session.Proposal.Deadline, b.session.Proposal.Deadline
+	if da.IsZero() != db.IsZero() {
+		return db.IsZero()
+	}
+	if !da.Equal(db) {
+		return da.Before(db)
+	}
+	return a.session.ID < b.session.ID
+}
+
+// compareDeadlines is deadlineBefore for slices.SortFunc
+func compareDeadlines(a, b *indexEntry) int {
+	switch {
+	case deadlineBefore(a, b):
+		return -1
+	case deadlineBefore(b, a):
+		return 1
+	}
+	return 0
+}
+
+// add indexes a new, open session
+func (idx *sessionIndex) add(session *VoteSession) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	entry := &indexEntry{session: session, status: SessionOpen}
+	idx.entries[session.ID] = entry
+	idx.byStatus[SessionOpen][session.ID] = entry
+	for _, tag := range session.Proposal.Tags {
+		if idx.byTag[tag] == nil {
+			idx.byTag[tag] = make(map[string]*indexEntry)
+		}
+		idx.byTag[tag][session.ID] = entry
+	}
+	i := sort.Search(len(idx.byDeadline), func(i int) bool {
+		return deadlineBefore(entry, idx.byDeadline[i])
+	})
+	idx.byDeadline = append(idx.byDeadline, nil)
+	copy(idx.byDeadline[i+1:], idx.byDeadline[i:])
+	idx.byDeadline[i] = entry
+}
+
+// complete moves a session to the completed sessions
+func (idx *sessionIndex) complete(sessionID string, at time.Time) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	entry, ok := idx.entries[sessionID]
+	if !ok || entry.status != SessionOpen {
+		return
+	}
+	idx.removeOpen(entry)
+	entry.status = SessionCompleted
+	entry.completedAt = at
+	idx.byStatus[SessionCompleted][sessionID] = entry
+}
+
+// removeOpen drops an open session from the open sessions. It is called
+// with mu held.
+func (idx *sessionIndex) removeOpen(entry *indexEntry) {
+	delete(idx.byStatus[SessionOpen], entry.session.ID)
+	i := sort.Search(len(idx.byDeadline), func(i int) bool {
+		return !deadlineBefore(idx.byDeadline[i], entry)
+	})
+	if i < len(idx.byDeadline) && idx.byDeadline[i] == entry {
+		idx.byDeadline = append(idx.byDeadline[:i], idx.byDeadline[i+1:]...)
+	}
+}
+
+// removeCompleted drops the sessions completed before cutoff and returns
+// their IDs
+func (idx *sessionIndex) removeCompleted(cutoff time.Time) []string {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	var removed []string
+	for id, entry := range idx.byStatus[SessionCompleted] {
+		if !entry.completedAt.Before(cutoff) {
+			continue
+		}
+		delete(idx.entries, id)
+		delete(idx.byStatus[SessionCompleted], id)
+		for _, tag := range entry.session.Proposal.Tags {
+			delete(idx.byTag[tag], id)
+			if len(idx.byTag[tag]) == 0 {
+				delete(idx.byTag, tag)
+			}
+		}
+		removed = append(removed, id)
+	}
+	return removed
+}
+
+// list returns the sessions matching filter, soonest deadline first
+func (idx *sessionIndex) list(filter SessionFilter) []*VoteSession {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	matches := func(entry *indexEntry) bool {
+		if filter.Status != "" && entry.status != filter.Status {
+			return false
+		}
+		if filter.Tag != "" && idx.byTag[filter.Tag][entry.session.ID] == nil {
+			return false
+		}
+		return true
+	}
+
+	var sessions []*VoteSession
+	if !filter.DueBefore.IsZero() {
+		// Open sessions are already in deadline order
+		for _, entry := range idx.byDeadline {
+			deadline := entry.session.Proposal.Deadline
+			if deadline.IsZero() || !deadline.Before(filter.DueBefore) {
+				break
+			}
+			if matches(entry) {
+				sessions = append(sessions, entry.session)
+			}
+		}
+		return sessions
+	}
+
+	// Start from the smallest index that can match
+	candidates := idx.entries
+	if filter.Tag != "" {
+		candidates = idx.byTag[filter.Tag]
+	}
+	if filter.Status != "" && len(idx.byStatus[filter.Status]) < len(candidates) {
+		candidates = idx.byStatus[filter.Status]
+	}
+	entries := make([]*indexEntry, 0, len(candidates))
+	for _, entry := range candidates {
+		if matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	slices.SortFunc(entries, compareDeadlines)
+	sessions = make([]*VoteSession, len(entries))
+	for i, entry := range entries {
+		sessions[i] = entry.session
+	}
+	return sessions
+}
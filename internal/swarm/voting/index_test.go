@@ -0,0 +1,65 @@
+package voting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+func TestListSessions(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	dvs := NewDemocraticVotingSystemWithClock(fake)
+	create := func(description string, deadline time.Duration, tags ...string) *VoteSession {
+		session, err := dvs.CreateVoteSession(VoteProposal{
+			Description: description,
+			Tags:        tags,
+			Deadline:    fake.Now().Add(deadline),
+		}, VoteTypeMajority, 1, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return session
+	}
+	rmLater := create("rm later", time.Hour, "destructive")
+	build := create("build", time.Minute)
+	rmSoon := create("rm soon", 30*time.Second, "destructive")
+	dropped := create("drop table", 10*time.Second, "destructive")
+	if err := dvs.CastVote(dropped.ID, Vote{AgentID: "a", Decision: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		filter SessionFilter
+		want   []*VoteSession
+	}{
+		{"open", SessionFilter{Status: SessionOpen}, []*VoteSession{rmSoon, build, rmLater}},
+		{"open destructive", SessionFilter{Status: SessionOpen, Tag: "destructive"}, []*VoteSession{rmSoon, rmLater}},
+		{"destructive", SessionFilter{Tag: "destructive"}, []*VoteSession{dropped, rmSoon, rmLater}},
+		{"completed", SessionFilter{Status: SessionCompleted}, []*VoteSession{dropped}},
+		{"expiring", SessionFilter{DueBefore: fake.Now().Add(time.Minute)}, []*VoteSession{rmSoon}},
+		{"expiring destructive", SessionFilter{Tag: "destructive", DueBefore: fake.Now().Add(2 * time.Hour)}, []*VoteSession{rmSoon, rmLater}},
+		{"unknown tag", SessionFilter{Tag: "deploy"}, nil},
+	} {
+		got := dvs.ListSessions(tt.filter)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: listed %d sessions, want %d", tt.name, len(got), len(tt.want))
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: session %d is %q, want %q", tt.name, i, got[i].Proposal.Description, tt.want[i].Proposal.Description)
+			}
+		}
+	}
+
+	fake.Advance(time.Hour)
+	dvs.CleanupCompletedSessions(time.Minute)
+	if _, err := dvs.GetSession(dropped.ID); err == nil {
+		t.Error("completed session not cleaned up")
+	}
+	if got := dvs.ListSessions(SessionFilter{Tag: "destructive"}); len(got) != 2 {
+		t.Errorf("listed %d destructive sessions after cleanup, want 2", len(got))
+	}
+}
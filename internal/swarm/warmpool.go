@@ -0,0 +1,287 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+)
+
+// DefaultWarmKeepAlive is how often the agents of a warm pool are warmed
+// again unless configured otherwise. Ollama unloads models idle for five
+// minutes.
+const DefaultWarmKeepAlive = 4 * time.Minute
+
+// warmTimeout bounds a single warm up, which includes loading the model
+const warmTimeout = 2 * time.Minute
+
+// WarmPoolConfig keeps agents of a type started with their model loaded, so
+// tasks do not wait for a slow provider to load it. The pool agents are
+// copies of the first configured agent of the type.
+type WarmPoolConfig struct {
+	// Size is how many agents the pool keeps besides the configured one
+	Size int
+	// KeepAlive is how often idle pool agents are warmed again,
+	// DefaultWarmKeepAlive if zero
+	KeepAlive time.Duration
+	// IdleTimeout evicts the pool agents once no task for the type arrived
+	// for that long. The pool is refilled by the next task. Zero keeps the
+	// agents for as long as the swarm runs.
+	IdleTimeout time.Duration
+}
+
+// WarmPoolStats describes a warm pool
+type WarmPoolStats struct {
+	// Size is how many agents the pool keeps
+	Size int
+	// Agents are the pool agents running, Idle those without a task
+	Agents int
+	Idle   int
+	// Warmups counts the warm ups sent, WarmFailures those that failed
+	Warmups      int64
+	WarmFailures int64
+	// Evictions counts the times the pool was emptied after being idle,
+	// Refills the times a task filled it again
+	Evictions int64
+	Refills   int64
+	// LastUsed is when the last task for the type arrived
+	LastUsed time.Time
+}
+
+// warmPool tracks the agents kept warm for one agent type
+type warmPool struct {
+	agentType agent.AgentType
+	config    WarmPoolConfig
+	// template is the configuration of the agents of the pool, set when
+	// the swarm starts
+	template agent.AgentConfig
+
+	mu       sync.Mutex
+	agents   []string
+	next     int
+	lastUsed time.Time
+	lastWarm time.Time
+	stats    WarmPoolStats
+}
+
+// newWarmPools checks the pool configurations and creates their pools
+func newWarmPools(configs map[agent.AgentType]WarmPoolConfig) (map[agent.AgentType]*warmPool, error) {
+	pools := make(map[agent.AgentType]*warmPool, len(configs))
+	for agentType, config := range configs {
+		if config.Size < 0 || config.KeepAlive < 0 || config.IdleTimeout < 0 {
+			return nil, fmt.Errorf("warm pool %s: size and durations cannot be negative", agentType)
+		}
+		if config.Size == 0 {
+			continue
+		}
+		if config.KeepAlive == 0 {
+			config.KeepAlive = DefaultWarmKeepAlive
+		}
+		pools[agentType] = &warmPool{agentType: agentType, config: config}
+	}
+	return pools, nil
+}
+
+// warmInterval is how often the pools are maintained, the shortest keep
+// alive of any pool
+func (c *Coordinator) warmInterval() time.Duration {
+	var interval time.Duration
+	for _, pool := range c.warmPools {
+		if interval == 0 || pool.config.KeepAlive < interval {
+			interval = pool.config.KeepAlive
+		}
+	}
+	return interval
+}
+
+// startWarmPools fills the warm pools from the configured agents. It is
+// called with c.mu held.
+func (c *Coordinator) startWarmPools() error {
+	for _, agentType := range c.warmPoolTypes() {
+		pool := c.warmPools[agentType]
+		found := false
+		for _, cfg := range c.config.Agents {
+			if cfg.Type == agentType && !cfg.Shadow {
+				pool.template = cfg
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("warm pool %s: no agent of the type is configured", agentType)
+		}
+		pool.mu.Lock()
+		pool.lastUsed = c.clock.Now()
+		pool.mu.Unlock()
+		if err := c.fillWarmPool(pool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// warmPoolTypes returns the agent types with a warm pool in a stable order
+func (c *Coordinator) warmPoolTypes() []agent.AgentType {
+	types := make([]agent.AgentType, 0, len(c.warmPools))
+	for agentType := range c.warmPools {
+		types = append(types, agentType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// fillWarmPool starts pool agents until the pool has its size and warms
+// them in the background
+func (c *Coordinator) fillWarmPool(pool *warmPool) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for len(pool.agents) < pool.config.Size {
+		cfg := pool.template
+		cfg.ID = fmt.Sprintf("%s-warm-%d", pool.template.ID, pool.next)
+		pool.next++
+
+		ag, err := c.newAgent(cfg)
+		if err != nil {
+			return fmt.Errorf("warm pool %s: %w", pool.agentType, err)
+		}
+		if err := c.registry.RegisterAgent(ag); err != nil {
+			return fmt.Errorf("warm pool %s: %w", pool.agentType, err)
+		}
+		if err := ag.Start(c.ctx); err != nil {
+			_ = c.registry.UnregisterAgent(cfg.ID)
+			return fmt.Errorf("warm pool %s: failed to start agent %s: %w", pool.agentType, cfg.ID, err)
+		}
+		c.watchProgress(ag)
+		pool.agents = append(pool.agents, cfg.ID)
+		c.warm(pool, ag)
+	}
+	pool.lastWarm = c.clock.Now()
+	return nil
+}
+
+// warm warms an agent in the background. It is called with pool.mu held.
+func (c *Coordinator) warm(pool *warmPool, ag agent.Agent) {
+	warmer, ok := ag.(agent.Warmer)
+	if !ok {
+		return
+	}
+	pool.stats.Warmups++
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ctx, cancel := context.WithTimeout(c.ctx, warmTimeout)
+		defer cancel()
+		if err := warmer.Warm(ctx); err != nil && c.ctx.Err() == nil {
+			pool.mu.Lock()
+			pool.stats.WarmFailures++
+			pool.mu.Unlock()
+		}
+	}()
+}
+
+// useWarmPools marks the pools whose agents can handle a task as used and
+// refills those that were evicted. It is called before the task is handed
+// to an agent, so the pool agents are not evicted under it.
+func (c *Coordinator) useWarmPools(task agent.Task) {
+	if len(c.warmPools) == 0 {
+		return
+	}
+	for _, agentType := range c.warmPoolTypes() {
+		pool := c.warmPools[agentType]
+		template, err := c.registry.GetAgent(pool.template.ID)
+		if err != nil || !template.CanHandleTask(task) {
+			continue
+		}
+
+		pool.mu.Lock()
+		pool.lastUsed = c.clock.Now()
+		refill := len(pool.agents) < pool.config.Size
+		if refill {
+			pool.stats.Refills++
+		}
+		pool.mu.Unlock()
+
+		if refill {
+			if err := c.fillWarmPool(pool); err != nil {
+				c.timeline.record(TimelineAlert, string(agentType), err.Error(), map[string]interface{}{
+					"warm_pool": string(agentType),
+				})
+			}
+		}
+	}
+}
+
+// maintainWarmPools evicts the pools idle past their timeout and warms the
+// idle agents of the others once their keep alive passed
+func (c *Coordinator) maintainWarmPools() {
+	now := c.clock.Now()
+	for _, agentType := range c.warmPoolTypes() {
+		pool := c.warmPools[agentType]
+		pool.mu.Lock()
+		switch {
+		case len(pool.agents) == 0:
+		case pool.config.IdleTimeout > 0 && now.Sub(pool.lastUsed) >= pool.config.IdleTimeout:
+			c.evictWarmPool(pool)
+		case now.Sub(pool.lastWarm) >= pool.config.KeepAlive:
+			for _, id := range pool.agents {
+				ag, err := c.registry.GetAgent(id)
+				if err == nil && ag.GetStatus() == agent.AgentStatusIdle {
+					c.warm(pool, ag)
+				}
+			}
+			pool.lastWarm = now
+		}
+		pool.mu.Unlock()
+	}
+}
+
+// evictWarmPool stops the idle agents of a pool. Agents still running a
+// task stay until the next maintenance. It is called with pool.mu held.
+func (c *Coordinator) evictWarmPool(pool *warmPool) {
+	kept := pool.agents[:0]
+	for _, id := range pool.agents {
+		ag, err := c.registry.GetAgent(id)
+		if err != nil {
+			continue
+		}
+		if ag.GetStatus() == agent.AgentStatusBusy {
+			kept = append(kept, id)
+			continue
+		}
+		_ = c.registry.UnregisterAgent(id)
+		_ = ag.Stop()
+		c.grants.remove(id)
+	}
+	if len(kept) == 0 {
+		pool.stats.Evictions++
+	}
+	pool.agents = kept
+}
+
+// warmPoolStats returns the stats of every warm pool by agent type
+func (c *Coordinator) warmPoolStats() map[agent.AgentType]WarmPoolStats {
+	if len(c.warmPools) == 0 {
+		return nil
+	}
+	stats := make(map[agent.AgentType]WarmPoolStats, len(c.warmPools))
+	for agentType, pool := range c.warmPools {
+		pool.mu.Lock()
+		s := pool.stats
+		s.Size = pool.config.Size
+		s.Agents = len(pool.agents)
+		s.LastUsed = pool.lastUsed
+		for _, id := range pool.agents {
+			if ag, err := c.registry.GetAgent(id); err == nil && ag.GetStatus() == agent.AgentStatusIdle {
+				s.Idle++
+			}
+		}
+		pool.mu.Unlock()
+		stats[agentType] = s
+	}
+	return stats
+}
@@ -0,0 +1,119 @@
+package swarm
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+// warmAgent counts the warm ups of its model
+type warmAgent struct {
+	*agent.BaseAgent
+	warmups *atomic.Int64
+}
+
+func (a *warmAgent) Warm(ctx context.Context) error {
+	a.warmups.Add(1)
+	return nil
+}
+
+func (a *warmAgent) CanHandleTask(task agent.Task) bool {
+	return task.Type == "summarize"
+}
+
+func (a *warmAgent) ExecuteTask(ctx context.Context, task agent.Task) (*agent.TaskResult, error) {
+	return &agent.TaskResult{TaskID: task.ID, AgentID: a.GetID(), Success: true}, nil
+}
+
+func TestWarmPool(t *testing.T) {
+	var warmups atomic.Int64
+	agent.RegisterFactory("warmtest", func(cfg agent.AgentConfig) (agent.Agent, error) {
+		return &warmAgent{BaseAgent: agent.NewBaseAgent(cfg), warmups: &warmups}, nil
+	})
+
+	clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	c, err := NewCoordinator(CoordinatorConfig{
+		SwarmConfig: agent.SwarmConfig{
+			Agents: []agent.AgentConfig{{ID: "summarizer", Type: "warmtest"}},
+		},
+		WarmPools: map[agent.AgentType]WarmPoolConfig{
+			"warmtest": {Size: 2, KeepAlive: time.Minute, IdleTimeout: 10 * time.Minute},
+		},
+		Clock: clk,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		c.cancelFunc()
+		c.wg.Wait()
+		_ = c.registry.StopAll(context.Background())
+	}()
+
+	// Maintained by hand, without the task queue's ticker
+	if err := c.createConfiguredAgents(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.startWarmPools(); err != nil {
+		t.Fatal(err)
+	}
+	waitWarmups := func(want int64) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for warmups.Load() < want {
+			if time.Now().After(deadline) {
+				t.Fatalf("warmups = %d, want %d", warmups.Load(), want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitWarmups(2)
+	if _, err := c.registry.GetAgent("summarizer-warm-1"); err != nil {
+		t.Fatalf("pool agent not registered: %v", err)
+	}
+
+	// Idle pool agents are warmed again once their keep alive passed
+	clk.Advance(30 * time.Second)
+	c.maintainWarmPools()
+	clk.Advance(30 * time.Second)
+	c.maintainWarmPools()
+	waitWarmups(4)
+
+	// A task for the type keeps the pool from being evicted
+	c.useWarmPools(agent.Task{Type: "summarize"})
+	clk.Advance(9 * time.Minute)
+	c.maintainWarmPools()
+	if stats := c.GetSystemStatus().WarmPools["warmtest"]; stats.Agents != 2 || stats.Evictions != 0 {
+		t.Fatalf("stats = %+v, want the pool kept", stats)
+	}
+
+	clk.Advance(time.Minute)
+	c.maintainWarmPools()
+	stats := c.GetSystemStatus().WarmPools["warmtest"]
+	if stats.Agents != 0 || stats.Evictions != 1 {
+		t.Fatalf("stats = %+v, want the pool evicted", stats)
+	}
+	if _, err := c.registry.GetAgent("summarizer-warm-0"); err == nil {
+		t.Error("evicted agent is still registered")
+	}
+
+	// Tasks of other types leave the evicted pool alone
+	c.useWarmPools(agent.Task{Type: "deploy"})
+	if stats := c.GetSystemStatus().WarmPools["warmtest"]; stats.Agents != 0 {
+		t.Fatalf("stats = %+v, want the pool empty", stats)
+	}
+
+	c.useWarmPools(agent.Task{Type: "summarize"})
+	stats = c.GetSystemStatus().WarmPools["warmtest"]
+	if stats.Agents != 2 || stats.Idle != 2 || stats.Refills != 1 || !stats.LastUsed.Equal(clk.Now()) {
+		t.Fatalf("stats = %+v, want the pool refilled", stats)
+	}
+	if _, err := c.registry.GetAgent("summarizer-warm-3"); err != nil {
+		t.Fatalf("refilled agent not registered: %v", err)
+	}
+	waitWarmups(6)
+}
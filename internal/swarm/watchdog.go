@@ -0,0 +1,266 @@
+package swarm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/health"
+)
+
+const (
+	// DefaultStuckFactor is how many times its longest recent successful
+	// run a task may take before it is suspected stuck, unless configured
+	// otherwise
+	DefaultStuckFactor = 3.0
+	// DefaultStuckAfter is how long tasks of types that succeeded too few
+	// times to learn from may run, unless configured otherwise. Tasks time
+	// out after five minutes.
+	DefaultStuckAfter = 2 * time.Minute
+
+	// stuckSamples is how many successful runs are kept per task type
+	stuckSamples = 20
+	// stuckMinSamples is how many successful runs a task type needs
+	// before its durations are learned from
+	stuckMinSamples = 3
+	// pingTimeout bounds how long the agent of a stuck task has to answer
+	pingTimeout = 10 * time.Second
+)
+
+// StuckAction is what the watchdog does with a stuck task
+type StuckAction string
+
+const (
+	// StuckAlert only raises an alert
+	StuckAlert StuckAction = "alert"
+	// StuckCancel cancels the task
+	StuckCancel StuckAction = "cancel"
+	// StuckRetry cancels the task and queues it again, unless it used up
+	// its retries
+	StuckRetry StuckAction = "retry"
+)
+
+// ValidateStuckAction checks that a stuck action is known
+func ValidateStuckAction(action StuckAction) error {
+	switch action {
+	case "", StuckAlert, StuckCancel, StuckRetry:
+		return nil
+	}
+	return fmt.Errorf("unknown stuck action %q, expected %s, %s or %s", action, StuckAlert, StuckCancel, StuckRetry)
+}
+
+// WatchdogConfig makes the coordinator watch running tasks for ones taking
+// much longer than tasks of their type usually do. Such tasks are marked
+// suspected stuck, raise an alert, and their agent is pinged.
+type WatchdogConfig struct {
+	// Interval is how often running tasks are checked, zero to not watch
+	// them
+	Interval time.Duration
+	// Factor times the longest of the recent successful runs of a task
+	// type is how long its tasks may run, DefaultStuckFactor if zero
+	Factor float64
+	// DefaultExpected is how long tasks of a type with too few successful
+	// runs may run, DefaultStuckAfter if zero
+	DefaultExpected time.Duration
+	// Action is taken on stuck tasks whose agent did not answer the ping
+	// or reported no progress for as long as the task may run. StuckAlert
+	// if empty.
+	Action StuckAction
+}
+
+// watchdog learns how long tasks take by type and remembers the stuck tasks
+// it cancelled
+type watchdog struct {
+	mu     sync.Mutex
+	config WatchdogConfig
+	// durations holds the recent successful runs by task type, oldest
+	// first
+	durations map[string][]time.Duration
+	// cancelled holds whether the cancelled tasks are queued again once
+	// they finished
+	cancelled map[string]bool
+}
+
+func newWatchdog(config WatchdogConfig) *watchdog {
+	return &watchdog{
+		config:    config,
+		durations: make(map[string][]time.Duration),
+		cancelled: make(map[string]bool),
+	}
+}
+
+// setConfig changes the limits and action, for the checks from now on. The
+// interval is fixed once the coordinator started.
+func (w *watchdog) setConfig(config WatchdogConfig) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.config = config
+}
+
+func (w *watchdog) action() StuckAction {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.config.Action == "" {
+		return StuckAlert
+	}
+	return w.config.Action
+}
+
+// learn records how long a successful task of a type ran
+func (w *watchdog) learn(taskType string, d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	durations := append(w.durations[taskType], d)
+	if len(durations) > stuckSamples {
+		durations = durations[len(durations)-stuckSamples:]
+	}
+	w.durations[taskType] = durations
+}
+
+// expected returns how long a task of a type may run before it is
+// suspected stuck
+func (w *watchdog) expected(taskType string) time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	durations := w.durations[taskType]
+	if len(durations) < stuckMinSamples {
+		if w.config.DefaultExpected > 0 {
+			return w.config.DefaultExpected
+		}
+		return DefaultStuckAfter
+	}
+	var longest time.Duration
+	for _, d := range durations {
+		longest = max(longest, d)
+	}
+	factor := w.config.Factor
+	if factor <= 0 {
+		factor = DefaultStuckFactor
+	}
+	return time.Duration(float64(longest) * factor)
+}
+
+// cancel remembers a task cancelled for being stuck, and reports whether
+// it was not cancelled already
+func (w *watchdog) cancel(taskID string, retry bool) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.cancelled[taskID]; ok {
+		return false
+	}
+	w.cancelled[taskID] = retry
+	return true
+}
+
+// finished forgets a finished task, and reports whether it is to be queued
+// again
+func (w *watchdog) finished(taskID string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	retry := w.cancelled[taskID]
+	delete(w.cancelled, taskID)
+	return retry
+}
+
+// watchTasks checks the running tasks on the watchdog interval
+func (c *Coordinator) watchTasks(interval time.Duration) {
+	defer c.wg.Done()
+
+	ticker := c.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			c.checkStuckTasks()
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// checkStuckTasks handles the running tasks that ran longer than tasks of
+// their type may
+func (c *Coordinator) checkStuckTasks() {
+	now := c.clock.Now()
+	for _, record := range c.tasks.list() {
+		if record.State != TaskStateRunning {
+			continue
+		}
+		expected := c.watchdog.expected(record.Task.Type)
+		if now.Sub(record.StartedAt) < expected {
+			continue
+		}
+		c.handleStuckTask(record, now, expected)
+	}
+}
+
+// handleStuckTask pings the agent of a task suspected stuck, alerts on the
+// task the first time and, once the agent seems to have given up on it,
+// takes the configured action
+func (c *Coordinator) handleStuckTask(record TaskRecord, now time.Time, expected time.Duration) {
+	taskID := record.Task.ID
+	running := now.Sub(record.StartedAt)
+
+	// Agents that cannot be pinged count as answering
+	answered := true
+	if ag, err := c.registry.GetAgent(record.AgentID); err == nil {
+		if pinger, ok := ag.(agent.Pinger); ok {
+			ctx, cancel := context.WithTimeout(c.ctx, pingTimeout)
+			_, err := pinger.Ping(ctx)
+			cancel()
+			answered = err == nil
+		}
+	}
+	progressing := !record.Progress.UpdatedAt.IsZero() && now.Sub(record.Progress.UpdatedAt) < expected
+
+	if c.tasks.markStuck(taskID, now) {
+		message := fmt.Sprintf("Task %s suspected stuck on %s: running %s, expected at most %s",
+			taskID, record.AgentID, running.Round(time.Second), expected.Round(time.Second))
+		score := 0.5
+		if !answered {
+			message += ", agent did not answer a health check"
+			score = 0.3
+			c.healthMonitor.UpdateCheck(health.HealthCheck{
+				ComponentID: record.AgentID,
+				Status:      health.HealthStatusUnhealthy,
+				Score:       0,
+				Message:     fmt.Sprintf("Agent %s did not answer a health check while running task %s", record.AgentID, taskID),
+			})
+		}
+		c.healthMonitor.RaiseAlert(health.HealthCheck{
+			ComponentID: taskID,
+			Status:      health.HealthStatusDegraded,
+			Score:       score,
+			Message:     message,
+			Details: map[string]interface{}{
+				"agent":    record.AgentID,
+				"type":     record.Task.Type,
+				"running":  running.String(),
+				"expected": expected.String(),
+			},
+		})
+	}
+
+	action := c.watchdog.action()
+	if action == StuckAlert || (answered && progressing) {
+		return
+	}
+	retry := action == StuckRetry && record.Task.RetryCount < max(record.Task.MaxRetries, 1)
+	if !retry {
+		action = StuckCancel
+	}
+	if !c.watchdog.cancel(taskID, retry) {
+		return
+	}
+	if err := c.CancelTask(taskID); err != nil {
+		c.watchdog.finished(taskID)
+		return
+	}
+	c.timeline.record(TimelineRecovery, taskID, fmt.Sprintf("Cancelled stuck task %s", taskID), map[string]interface{}{
+		"action": string(action),
+		"agent":  record.AgentID,
+	})
+}
@@ -0,0 +1,130 @@
+package swarm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/swarm/agent"
+	"github.com/opencode-ai/opencode/internal/swarm/clock"
+)
+
+// hangingAgent runs tasks until they are cancelled, reporting progress when
+// told to
+type hangingAgent struct {
+	*agent.BaseAgent
+	report chan struct{}
+}
+
+func (a *hangingAgent) CanHandleTask(task agent.Task) bool {
+	return true
+}
+
+func (a *hangingAgent) ExecuteTask(ctx context.Context, task agent.Task) (*agent.TaskResult, error) {
+	for {
+		select {
+		case <-a.report:
+			agent.ReportProgress(ctx, agent.TaskProgress{TaskID: task.ID, AgentID: a.GetID(), Stage: "working"})
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	clk := clock.NewFake(time.Now())
+	c, err := NewCoordinator(CoordinatorConfig{Clock: clk, Watchdog: WatchdogConfig{Action: StuckRetry}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.cancelFunc()
+
+	ag := &hangingAgent{
+		BaseAgent: agent.NewBaseAgent(agent.AgentConfig{ID: "hanging", Type: agent.AgentTypeExecutor}),
+		report:    make(chan struct{}),
+	}
+	if err := ag.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer ag.Stop()
+	if err := c.GetRegistry().RegisterAgent(ag); err != nil {
+		t.Fatal(err)
+	}
+
+	// Builds took up to 10s, so they may run 30s
+	for _, d := range []time.Duration{5 * time.Second, 10 * time.Second, 8 * time.Second} {
+		c.watchdog.learn("build", d)
+	}
+	if expected := c.watchdog.expected("build"); expected != 30*time.Second {
+		t.Fatalf("expected = %s, want 30s", expected)
+	}
+	if expected := c.watchdog.expected("deploy"); expected != DefaultStuckAfter {
+		t.Fatalf("expected = %s for an unknown type, want the default", expected)
+	}
+
+	id, _, err := c.tasks.enqueue(agent.Task{ID: "build-1", Type: "build", Description: "build it", MaxRetries: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, _ := c.tasks.next()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.executeTask(ag, task)
+	}()
+	record := func() TaskRecord {
+		t.Helper()
+		record, err := c.tasks.get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return record
+	}
+	for record().State != TaskStateRunning {
+		time.Sleep(time.Millisecond)
+	}
+	stuckAlerts := func() int {
+		n := 0
+		for _, event := range c.Timeline(TimelineFilter{Types: []TimelineEventType{TimelineAlert}}) {
+			if event.Subject == id && strings.Contains(event.Summary, "suspected stuck") {
+				n++
+			}
+		}
+		return n
+	}
+
+	clk.Advance(20 * time.Second)
+	c.checkStuckTasks()
+	if !record().StuckSince.IsZero() {
+		t.Fatal("task marked stuck before running as long as it may")
+	}
+
+	// A task past its time that makes progress is only alerted on, once
+	ag.report <- struct{}{}
+	for record().Progress.UpdatedAt.IsZero() {
+		time.Sleep(time.Millisecond)
+	}
+	clk.Advance(15 * time.Second)
+	c.checkStuckTasks()
+	c.checkStuckTasks()
+	if got := record(); got.StuckSince.IsZero() || got.State != TaskStateRunning {
+		t.Fatalf("task %s stuck since %v, want marked stuck and running", got.State, got.StuckSince)
+	}
+	if n := stuckAlerts(); n != 1 {
+		t.Fatalf("%d stuck alerts, want 1", n)
+	}
+
+	// Without progress for as long as it may run it is cancelled and
+	// queued again
+	clk.Advance(30 * time.Second)
+	c.checkStuckTasks()
+	<-done
+	got := record()
+	if got.State != TaskStateQueued || got.Task.RetryCount != 1 {
+		t.Errorf("task %s with %d retries, want queued again once", got.State, got.Task.RetryCount)
+	}
+	if !got.StuckSince.IsZero() {
+		t.Errorf("retried task still marked stuck since %v", got.StuckSince)
+	}
+}
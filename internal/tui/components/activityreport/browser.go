@@ -0,0 +1,286 @@
+package activityreport
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	bubbletable "github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/tui/components/chart"
+	"github.com/opencode-ai/opencode/internal/tui/components/markdown"
+	"github.com/opencode-ai/opencode/internal/tui/components/table"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// refreshInterval is how often the reports are reloaded
+const refreshInterval = 30 * time.Second
+
+// ReportSource is the part of the swarm coordinator the browser needs
+type ReportSource interface {
+	ActivityReports() []swarm.ActivityReport
+	ActivityReport(since, until time.Time) swarm.ActivityReport
+}
+
+// live are the reports made on request, listed above the scheduled ones
+var live = []struct {
+	label    string
+	duration time.Duration
+}{
+	{label: "last 24h", duration: 24 * time.Hour},
+	{label: "last 7 days", duration: 7 * 24 * time.Hour},
+}
+
+// Browser lists the activity reports of the swarm, the scheduled ones and
+// reports of the last day and week made on request, and shows the selected
+// report
+type Browser struct {
+	source ReportSource
+	table  *table.DataTable
+	width  int
+	height int
+
+	// reports are the reports of the rows, by their first cell
+	reports map[string]swarm.ActivityReport
+
+	refresher *util.Refresher
+}
+
+// NewBrowser creates a browser of the activity reports of source
+func NewBrowser(source ReportSource) *Browser {
+	m := &Browser{
+		source:    source,
+		table:     table.NewDataTable(columns(24), nil),
+		reports:   make(map[string]swarm.ActivityReport),
+		refresher: util.NewRefresher(refreshInterval),
+	}
+	m.refresh()
+	return m
+}
+
+func columns(reportWidth int) []bubbletable.Column {
+	return []bubbletable.Column{
+		{Title: "Report", Width: reportWidth},
+		{Title: "Tasks", Width: 6},
+		{Title: "Success", Width: 7},
+		{Title: "Incidents", Width: 9},
+		{Title: "Memories", Width: 12},
+		{Title: "Cost", Width: 9},
+	}
+}
+
+// Open reloads the reports and keeps them up to date while shown
+func (m *Browser) Open() tea.Cmd {
+	m.refresh()
+	return m.refresher.Start()
+}
+
+// Capturing returns whether the row filter is focused
+func (m *Browser) Capturing() bool {
+	return m.table.IsFiltering()
+}
+
+// Init implements tea.Model
+func (m *Browser) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *Browser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case util.RefreshMsg:
+		if !m.refresher.Due(msg) {
+			return m, nil
+		}
+		m.refresh()
+		return m, m.refresher.Next()
+	case tea.KeyMsg:
+		if !m.table.IsFiltering() {
+			switch msg.String() {
+			case "e":
+				return m, m.export(false)
+			case "h":
+				return m, m.export(true)
+			case "r":
+				m.refresh()
+				return m, nil
+			}
+		}
+	}
+
+	_, cmd := m.table.Update(msg)
+	return m, cmd
+}
+
+// export writes the selected report to a markdown or HTML file in the
+// working directory
+func (m *Browser) export(html bool) tea.Cmd {
+	report, ok := m.selected()
+	if !ok {
+		return util.ReportWarn("No report selected")
+	}
+	period := string(report.Period)
+	if period == "" {
+		period = "report"
+	}
+	name := fmt.Sprintf("%s-%s.md", period, report.Until.Format("2006-01-02-1504"))
+	body := report.Markdown()
+	if html {
+		name = strings.TrimSuffix(name, ".md") + ".html"
+		body = report.HTML()
+	}
+	if err := os.WriteFile(name, []byte(body), 0o644); err != nil {
+		return util.ReportError(err)
+	}
+	return util.ReportInfo("Report written to " + name)
+}
+
+// refresh makes the reports on request and reloads the scheduled ones
+func (m *Browser) refresh() {
+	m.reports = make(map[string]swarm.ActivityReport)
+	if m.source == nil {
+		m.table.SetRows(nil)
+		return
+	}
+
+	var rows []bubbletable.Row
+	add := func(label string, report swarm.ActivityReport) {
+		m.reports[label] = report
+		memories := fmt.Sprintf("%d", report.Memory.Total)
+		if !report.Memory.GrowthSince.IsZero() {
+			memories = fmt.Sprintf("%d (%+d)", report.Memory.Total, report.Memory.Growth)
+		}
+		success := "-"
+		if report.Tasks.Completed+report.Tasks.Failed > 0 {
+			success = fmt.Sprintf("%.0f%%", report.Tasks.SuccessRate()*100)
+		}
+		rows = append(rows, bubbletable.Row{
+			label,
+			fmt.Sprintf("%d", report.Tasks.Finished()),
+			success,
+			fmt.Sprintf("%d", len(report.Incidents)+report.MoreIncidents),
+			memories,
+			fmt.Sprintf("$%.2f", report.Cost.Cost),
+		})
+	}
+
+	now := time.Now()
+	for _, l := range live {
+		add(l.label, m.source.ActivityReport(now.Add(-l.duration), now))
+	}
+	for _, report := range m.source.ActivityReports() {
+		add(fmt.Sprintf("%s %s", report.Period, report.Until.Format("2006-01-02 15:04")), report)
+	}
+	m.table.SetRows(rows)
+}
+
+// selected returns the report under the cursor
+func (m *Browser) selected() (swarm.ActivityReport, bool) {
+	row := m.table.SelectedRow()
+	if row == nil {
+		return swarm.ActivityReport{}, false
+	}
+	report, ok := m.reports[row[0]]
+	return report, ok
+}
+
+// View implements tea.Model
+func (m *Browser) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Activity Reports")
+
+	status := "No swarm is running"
+	if m.source != nil {
+		status = fmt.Sprintf("%d scheduled reports • the last day and week on request", len(m.reports)-len(live))
+	}
+
+	help := "e: export markdown • h: export HTML • r: refresh"
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		styles.BaseStyle.Foreground(styles.ForgroundMid).Render(status),
+		styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help),
+		"",
+		m.table.View(),
+		"",
+		m.details(),
+	)
+}
+
+// details renders the selected report
+func (m *Browser) details() string {
+	report, ok := m.selected()
+	if !ok {
+		return styles.BaseStyle.Foreground(styles.ForgroundDim).Render("No report selected")
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	text := report.Markdown()
+	if rendered, err := markdown.RenderMarkdown(text, width-4); err == nil {
+		text = rendered
+	}
+	lines := strings.Split(strings.Trim(text, "\n"), "\n")
+	if bars := taskBars(report, width); bars != "" {
+		lines = append(strings.Split(bars, "\n"), append([]string{""}, lines...)...)
+	}
+
+	available := m.height - m.tableHeight() - 5
+	if available < 1 {
+		available = 1
+	}
+	if len(lines) > available {
+		lines = lines[:available]
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// taskBars charts the tasks finished per type, in yellow for types with
+// failed tasks
+func taskBars(report swarm.ActivityReport, width int) string {
+	bars := make([]chart.Bar, 0, len(report.ByType))
+	for _, typ := range report.ByType {
+		color := styles.Green
+		if typ.Failed > 0 {
+			color = styles.Warning
+		}
+		bars = append(bars, chart.Bar{Label: typ.Type, Value: float64(typ.Finished()), Color: color})
+	}
+	return chart.BarChart(bars, min(width, 60))
+}
+
+// tableHeight gives the reports a third of the screen, the selected report
+// the rest
+func (m *Browser) tableHeight() int {
+	height := (m.height - 5) / 3
+	if height < 5 {
+		height = 5
+	}
+	return height
+}
+
+// SetSize sets the size of the browser
+func (m *Browser) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	reportWidth := width - 6 - 7 - 9 - 12 - 9 - 12
+	if reportWidth < 20 {
+		reportWidth = 20
+	}
+	m.table.SetColumns(columns(reportWidth))
+	m.table.SetSize(width, m.tableHeight())
+}
@@ -0,0 +1,190 @@
+// Package chart renders small text charts for the TUI: sparklines, line
+// charts drawn with braille dots and horizontal bar charts.
+package chart
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+)
+
+// Range bounds the values of a chart. The zero Range fits the values.
+type Range struct {
+	Min, Max float64
+}
+
+// fit returns the range, or the range of values if it is zero. Flat
+// values get a range of one above them.
+func (r Range) fit(values []float64) Range {
+	if r.Min == 0 && r.Max == 0 && len(values) > 0 {
+		r.Min, r.Max = values[0], values[0]
+		for _, v := range values[1:] {
+			r.Min = math.Min(r.Min, v)
+			r.Max = math.Max(r.Max, v)
+		}
+	}
+	if r.Max <= r.Min {
+		r.Max = r.Min + 1
+	}
+	return r
+}
+
+// scale maps v to 0..1 within the range, clamped
+func (r Range) scale(v float64) float64 {
+	s := (v - r.Min) / (r.Max - r.Min)
+	if math.IsNaN(s) {
+		return 0
+	}
+	return math.Max(0, math.Min(1, s))
+}
+
+// latest keeps the last n values
+func latest(values []float64, n int) []float64 {
+	if len(values) > n {
+		return values[len(values)-n:]
+	}
+	return values
+}
+
+var blocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as block characters, one per value, keeping the
+// most recent values that fit in width. It is empty for fewer than two
+// values, which show no trend.
+func Sparkline(values []float64, r Range, width int) string {
+	if width <= 0 || len(values) < 2 {
+		return ""
+	}
+	values = latest(values, width)
+	r = r.fit(values)
+
+	var sb strings.Builder
+	for _, v := range values {
+		sb.WriteRune(blocks[int(r.scale(v)*float64(len(blocks)-1))])
+	}
+	return sb.String()
+}
+
+// Braille cells hold 2x4 dots; dotBits are the bits of the dots by column
+// and row from the top
+var dotBits = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// LineChart renders values as a line of braille dots height rows tall,
+// keeping the most recent values that fit, two per column. The highest and
+// lowest values of the range label the axis on the left, formatted by
+// label, or as numbers if it is nil.
+func LineChart(values []float64, r Range, width, height int, label func(float64) string) string {
+	if label == nil {
+		label = func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	}
+	r = r.fit(values)
+	top, bottom := label(r.Max), label(r.Min)
+	axis := max(ansi.StringWidth(top), ansi.StringWidth(bottom))
+
+	cols := width - axis - 1
+	if cols < 1 || height < 1 {
+		return ""
+	}
+	values = latest(values, cols*2)
+
+	// Dots count from the bottom; a line connects each value to the last
+	dotRows := height * 4
+	cells := make([][]rune, height)
+	for i := range cells {
+		cells[i] = make([]rune, cols)
+	}
+	set := func(x, y int) {
+		row := height - 1 - y/4
+		cells[row][x/2] |= dotBits[x%2][3-y%4]
+	}
+	prev := -1
+	for x, v := range values {
+		y := int(math.Round(r.scale(v) * float64(dotRows-1)))
+		from, to := y, y
+		if prev >= 0 {
+			from, to = min(prev, y), max(prev, y)
+		}
+		for dot := from; dot <= to; dot++ {
+			set(x, dot)
+		}
+		prev = y
+	}
+
+	axisStyle := styles.BaseStyle.Foreground(styles.ForgroundDim)
+	lines := make([]string, height)
+	for i, row := range cells {
+		var sb strings.Builder
+		for _, cell := range row {
+			sb.WriteRune(0x2800 | cell)
+		}
+		tick := ""
+		switch i {
+		case 0:
+			tick = top
+		case height - 1:
+			tick = bottom
+		}
+		lines[i] = axisStyle.Render(fmt.Sprintf("%*s┤", axis, tick)) + sb.String()
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// Bar is a labeled value of a bar chart. Bars without a color take the
+// primary color.
+type Bar struct {
+	Label string
+	Value float64
+	Color lipgloss.TerminalColor
+}
+
+// BarChart renders bars one per line, their labels aligned on the left and
+// their values on the right, scaled to the largest value so the longest bar
+// fills width
+func BarChart(bars []Bar, width int) string {
+	if len(bars) == 0 || width <= 0 {
+		return ""
+	}
+	labelWidth, valueWidth, largest := 0, 0, 0.0
+	for _, bar := range bars {
+		labelWidth = max(labelWidth, ansi.StringWidth(bar.Label))
+		valueWidth = max(valueWidth, len(strconv.FormatFloat(bar.Value, 'f', -1, 64)))
+		largest = math.Max(largest, bar.Value)
+	}
+	// Long labels give way to the bars
+	labelWidth = min(labelWidth, width/3)
+	barWidth := width - labelWidth - valueWidth - 2
+	if barWidth < 1 {
+		return ""
+	}
+
+	lines := make([]string, len(bars))
+	for i, bar := range bars {
+		// Eighths of a cell, so small values still show
+		eighths := 0
+		if largest > 0 && bar.Value > 0 {
+			eighths = max(1, int(math.Round(bar.Value/largest*float64(barWidth*8))))
+		}
+		body := strings.Repeat("█", eighths/8)
+		if rest := eighths % 8; rest > 0 {
+			body += string([]rune("▏▎▍▌▋▊▉")[rest-1])
+		}
+		color := bar.Color
+		if color == nil {
+			color = styles.PrimaryColor
+		}
+		lines[i] = fmt.Sprintf("%-*s %s%s %*s",
+			labelWidth, ansi.Truncate(bar.Label, labelWidth, "…"),
+			styles.BaseStyle.Foreground(color).Render(body),
+			strings.Repeat(" ", barWidth-ansi.StringWidth(body)),
+			valueWidth, strconv.FormatFloat(bar.Value, 'f', -1, 64))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
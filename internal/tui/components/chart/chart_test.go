@@ -0,0 +1,99 @@
+package chart
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		r      Range
+		width  int
+		want   string
+	}{
+		{name: "empty", values: nil, width: 10, want: ""},
+		{name: "single point", values: []float64{3}, width: 10, want: ""},
+		{name: "all equal", values: []float64{3, 3, 3}, width: 10, want: "▁▁▁"},
+		{name: "negative", values: []float64{-2, -1, 0}, width: 10, want: "▁▄█"},
+		{name: "clamped to the range", values: []float64{-5, 5, 15}, r: Range{Min: 0, Max: 10}, width: 10, want: "▁▄█"},
+		{name: "truncated to the width", values: []float64{1, 2, 3, 4}, width: 2, want: "▁█"},
+		{name: "no width", values: []float64{1, 2}, width: 0, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sparkline(tt.values, tt.r, tt.width); got != tt.want {
+				t.Errorf("Sparkline(%v, %v, %d) = %q, want %q", tt.values, tt.r, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineChart(t *testing.T) {
+	tests := []struct {
+		name          string
+		values        []float64
+		r             Range
+		width, height int
+		want          []string
+	}{
+		{name: "empty", values: nil, width: 4, height: 1, want: []string{"1┤⠀⠀"}},
+		{name: "single point", values: []float64{5}, width: 3, height: 1, want: []string{"6┤⡀"}},
+		{name: "all equal", values: []float64{2, 2, 2, 2}, width: 4, height: 1, want: []string{"3┤⣀⣀"}},
+		{name: "negative", values: []float64{-1, 1}, width: 4, height: 2, want: []string{" 1┤⢸", "-1┤⣸"}},
+		{name: "truncated to the width", values: []float64{1, 2, 3, 4, 5, 6}, r: Range{Min: 0, Max: 10}, width: 4, height: 1, want: []string{"10┤⠒"}},
+		{name: "narrower than the axis", values: []float64{-1, 1}, width: 3, height: 1, want: nil},
+		{name: "no height", values: []float64{1, 2}, width: 10, height: 0, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ansi.Strip(LineChart(tt.values, tt.r, tt.width, tt.height, nil))
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("LineChart(%v, %v, %d, %d) =\n%s\nwant\n%s", tt.values, tt.r, tt.width, tt.height, got, want)
+			}
+		})
+	}
+}
+
+func TestBarChart(t *testing.T) {
+	tests := []struct {
+		name  string
+		bars  []Bar
+		width int
+		want  []string
+	}{
+		{name: "empty", bars: nil, width: 10, want: nil},
+		{name: "single bar", bars: []Bar{{Label: "a", Value: 2}}, width: 10, want: []string{"a ██████ 2"}},
+		{
+			name:  "all equal",
+			bars:  []Bar{{Label: "a", Value: 1}, {Label: "b", Value: 1}},
+			width: 10,
+			want:  []string{"a ██████ 1", "b ██████ 1"},
+		},
+		{
+			name:  "eighths of a cell",
+			bars:  []Bar{{Label: "a", Value: 1}, {Label: "b", Value: 5}},
+			width: 10,
+			want:  []string{"a █▎     1", "b ██████ 5"},
+		},
+		{
+			name:  "negative",
+			bars:  []Bar{{Label: "up", Value: 4}, {Label: "down", Value: -2}},
+			width: 12,
+			want:  []string{"up   ████  4", "down      -2"},
+		},
+		{name: "truncated label", bars: []Bar{{Label: "a long label", Value: 1}}, width: 12, want: []string{"a l… █████ 1"}},
+		{name: "too narrow", bars: []Bar{{Label: "a", Value: 1}}, width: 3, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ansi.Strip(BarChart(tt.bars, tt.width))
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("BarChart(%d) =\n%s\nwant\n%s", tt.width, got, want)
+			}
+		})
+	}
+}
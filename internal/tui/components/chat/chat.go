@@ -20,6 +20,12 @@ type SessionSelectedMsg = session.Session
 
 type SessionClearedMsg struct{}
 
+// InsertTextMsg appends text to the chat editor so it can be reviewed
+// before sending, e.g. context exported from a tool
+type InsertTextMsg struct {
+	Text string
+}
+
 type EditorFocusMsg bool
 
 func lspsConfigured(width int) string {
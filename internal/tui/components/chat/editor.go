@@ -105,6 +105,13 @@ func (m *editorCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.session = msg
 		}
 		return m, nil
+	case InsertTextMsg:
+		value := m.textarea.Value()
+		if value != "" && value[len(value)-1] != '\n' {
+			value += "\n"
+		}
+		m.textarea.SetValue(value + msg.Text)
+		return m, nil
 	case tea.KeyMsg:
 		if key.Matches(msg, messageKeys.PageUp) || key.Matches(msg, messageKeys.PageDown) ||
 			key.Matches(msg, messageKeys.HalfPageUp) || key.Matches(msg, messageKeys.HalfPageDown) {
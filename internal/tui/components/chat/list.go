@@ -10,6 +10,7 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
 	"github.com/opencode-ai/opencode/internal/app"
 	"github.com/opencode-ai/opencode/internal/message"
 	"github.com/opencode-ai/opencode/internal/pubsub"
@@ -33,6 +34,7 @@ type messagesCmp struct {
 	cachedContent map[string]cacheItem
 	spinner       spinner.Model
 	rendering     bool
+	zoneID        string
 }
 type renderFinishedMsg struct{}
 
@@ -76,6 +78,11 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 		return m, nil
+	case styles.ThemeChangedMsg:
+		// Rendered messages carry the colors of the old theme
+		m.cachedContent = make(map[string]cacheItem)
+		m.renderView()
+		return m, nil
 	case SessionClearedMsg:
 		m.session = session.Session{}
 		m.messages = make([]message.Message, 0)
@@ -90,6 +97,14 @@ func (m *messagesCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.viewport = u
 			cmds = append(cmds, cmd)
 		}
+	case tea.MouseMsg:
+		// The page forwards mouse events to every panel, only scroll when
+		// the wheel is over the messages
+		if zone.Get(m.zoneID).InBounds(msg) {
+			u, cmd := m.viewport.Update(msg)
+			m.viewport = u
+			cmds = append(cmds, cmd)
+		}
 
 	case renderFinishedMsg:
 		m.rendering = false
@@ -282,7 +297,7 @@ func (m *messagesCmp) View() string {
 		Render(
 			lipgloss.JoinVertical(
 				lipgloss.Top,
-				m.viewport.View(),
+				zone.Mark(m.zoneID, m.viewport.View()),
 				m.working(),
 				m.help(),
 			),
@@ -444,5 +459,6 @@ func NewMessagesCmp(app *app.App) tea.Model {
 		cachedContent: make(map[string]cacheItem),
 		viewport:      vp,
 		spinner:       s,
+		zoneID:        zone.NewPrefix() + "messages",
 	}
 }
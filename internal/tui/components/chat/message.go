@@ -197,6 +197,8 @@ func toolName(name string) string {
 		return "Task"
 	case tools.BashToolName:
 		return "Bash"
+	case tools.DelegateToolName:
+		return "Swarm"
 	case tools.EditToolName:
 		return "Edit"
 	case tools.FetchToolName:
@@ -225,6 +227,8 @@ func getToolAction(name string) string {
 		return "Preparing prompt..."
 	case tools.BashToolName:
 		return "Building command..."
+	case tools.DelegateToolName:
+		return "Delegating to swarm..."
 	case tools.EditToolName:
 		return "Preparing edit..."
 	case tools.FetchToolName:
@@ -320,6 +324,11 @@ func renderToolParams(paramWidth int, toolCall message.ToolCall) string {
 		json.Unmarshal([]byte(toolCall.Input), &params)
 		command := strings.ReplaceAll(params.Command, "\n", " ")
 		return renderParams(paramWidth, command)
+	case tools.DelegateToolName:
+		var params tools.DelegateParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		description := strings.ReplaceAll(params.Description, "\n", " ")
+		return renderParams(paramWidth, description, "type", params.Type)
 	case tools.EditToolName:
 		var params tools.EditParams
 		json.Unmarshal([]byte(toolCall.Input), &params)
@@ -435,6 +444,20 @@ func renderToolResponse(toolCall message.ToolCall, response message.ToolResult,
 			toMarkdown(resultContent, true, width),
 			styles.Background,
 		)
+	case tools.DelegateToolName:
+		metadata := tools.DelegateResponseMetadata{}
+		json.Unmarshal([]byte(response.Metadata), &metadata)
+		if metadata.AgentID != "" {
+			byline := metadata.AgentID
+			if metadata.Duration != "" {
+				byline += ", " + metadata.Duration
+			}
+			resultContent = fmt.Sprintf("*%s*\n\n%s", byline, resultContent)
+		}
+		return styles.ForceReplaceBackgroundWithLipgloss(
+			toMarkdown(resultContent, false, width),
+			styles.Background,
+		)
 	case tools.EditToolName:
 		metadata := tools.EditResponseMetadata{}
 		json.Unmarshal([]byte(response.Metadata), &metadata)
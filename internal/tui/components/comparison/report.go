@@ -0,0 +1,257 @@
+package comparison
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	bubbletable "github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/tui/components/markdown"
+	"github.com/opencode-ai/opencode/internal/tui/components/table"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// refreshInterval is how often the comparisons are reloaded
+const refreshInterval = 5 * time.Second
+
+// ComparisonSource is the part of the swarm coordinator the report needs
+type ComparisonSource interface {
+	Comparisons(since, until time.Time) []swarm.Comparison
+}
+
+// window is the time range compared, cycled with w
+type window struct {
+	label    string
+	duration time.Duration
+}
+
+var windows = []window{
+	{label: "last 24h", duration: 24 * time.Hour},
+	{label: "last hour", duration: time.Hour},
+	{label: "last 7 days", duration: 7 * 24 * time.Hour},
+}
+
+// Report compares the shadow agents to the agents they shadowed and shows
+// the report of the selected pair
+type Report struct {
+	source ComparisonSource
+	table  *table.DataTable
+	width  int
+	height int
+
+	windowIndex int
+	comparisons map[string]swarm.Comparison
+
+	refresher *util.Refresher
+}
+
+// NewReport creates a comparison report of the shadow agents of source
+func NewReport(source ComparisonSource) *Report {
+	m := &Report{
+		source:      source,
+		table:       table.NewDataTable(columns(16), nil),
+		comparisons: make(map[string]swarm.Comparison),
+		refresher:   util.NewRefresher(refreshInterval),
+	}
+	m.refresh()
+	return m
+}
+
+func columns(agentWidth int) []bubbletable.Column {
+	return []bubbletable.Column{
+		{Title: "Primary", Width: agentWidth},
+		{Title: "Shadow", Width: agentWidth},
+		{Title: "Tasks", Width: 5},
+		{Title: "Success", Width: 9},
+		{Title: "p95", Width: 13},
+		{Title: "Cost", Width: 15},
+		{Title: "Agree", Width: 5},
+		{Title: "Diff", Width: 5},
+	}
+}
+
+// Open reloads the comparisons and keeps them up to date while shown
+func (m *Report) Open() tea.Cmd {
+	m.refresh()
+	return m.refresher.Start()
+}
+
+// Capturing returns whether the row filter is focused
+func (m *Report) Capturing() bool {
+	return m.table.IsFiltering()
+}
+
+// Init implements tea.Model
+func (m *Report) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *Report) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case util.RefreshMsg:
+		if !m.refresher.Due(msg) {
+			return m, nil
+		}
+		m.refresh()
+		return m, m.refresher.Next()
+	case tea.KeyMsg:
+		if !m.table.IsFiltering() {
+			switch msg.String() {
+			case "w":
+				m.windowIndex = (m.windowIndex + 1) % len(windows)
+				m.refresh()
+				return m, nil
+			case "e":
+				return m, m.export()
+			case "r":
+				m.refresh()
+				return m, nil
+			}
+		}
+	}
+
+	_, cmd := m.table.Update(msg)
+	return m, cmd
+}
+
+// export writes the report of the selected pair to a markdown file in the
+// working directory
+func (m *Report) export() tea.Cmd {
+	comparison, ok := m.selected()
+	if !ok {
+		return util.ReportWarn("No comparison selected")
+	}
+	name := fmt.Sprintf("comparison-%s-%s.md", comparison.Primary.AgentID, comparison.Shadow.AgentID)
+	if err := os.WriteFile(name, []byte(comparison.Markdown()), 0o644); err != nil {
+		return util.ReportError(err)
+	}
+	return util.ReportInfo("Report written to " + name)
+}
+
+// refresh compares the pairs seen within the selected window
+func (m *Report) refresh() {
+	m.comparisons = make(map[string]swarm.Comparison)
+	if m.source == nil {
+		m.table.SetRows(nil)
+		return
+	}
+
+	now := time.Now()
+	comparisons := m.source.Comparisons(now.Add(-windows[m.windowIndex].duration), now)
+	rows := make([]bubbletable.Row, 0, len(comparisons))
+	for _, c := range comparisons {
+		m.comparisons[c.Primary.AgentID+"\x00"+c.Shadow.AgentID] = c
+		diff := "-"
+		if c.EditTasks > 0 {
+			diff = fmt.Sprintf("%.0f%%", c.DiffAgreement*100)
+		}
+		rows = append(rows, bubbletable.Row{
+			c.Primary.AgentID,
+			c.Shadow.AgentID,
+			fmt.Sprintf("%d", c.Primary.Tasks),
+			fmt.Sprintf("%.0f%%/%.0f%%", c.Primary.SuccessRate()*100, c.Shadow.SuccessRate()*100),
+			fmt.Sprintf("%s/%s", c.Primary.P95Latency.Round(time.Second), c.Shadow.P95Latency.Round(time.Second)),
+			fmt.Sprintf("$%.2f/$%.2f", c.Primary.Cost, c.Shadow.Cost),
+			fmt.Sprintf("%.0f%%", float64(c.SameOutcome)/float64(max(c.Primary.Tasks, 1))*100),
+			diff,
+		})
+	}
+	m.table.SetRows(rows)
+}
+
+// selected returns the comparison under the cursor
+func (m *Report) selected() (swarm.Comparison, bool) {
+	row := m.table.SelectedRow()
+	if row == nil {
+		return swarm.Comparison{}, false
+	}
+	comparison, ok := m.comparisons[row[0]+"\x00"+row[1]]
+	return comparison, ok
+}
+
+// View implements tea.Model
+func (m *Report) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Shadow Comparisons")
+
+	status := "No swarm is running"
+	if m.source != nil {
+		status = fmt.Sprintf("%d pairs • %s • success, p95 and cost as primary/shadow",
+			len(m.comparisons), windows[m.windowIndex].label)
+	}
+
+	help := "w: time range • e: export markdown • r: refresh"
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		styles.BaseStyle.Foreground(styles.ForgroundMid).Render(status),
+		styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help),
+		"",
+		m.table.View(),
+		"",
+		m.details(),
+	)
+}
+
+// details renders the report of the selected pair
+func (m *Report) details() string {
+	comparison, ok := m.selected()
+	if !ok {
+		return styles.BaseStyle.Foreground(styles.ForgroundDim).Render("No shadow agent finished a task in this time range")
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	report := comparison.Markdown()
+	if rendered, err := markdown.RenderMarkdown(report, width-4); err == nil {
+		report = rendered
+	}
+	lines := strings.Split(strings.Trim(report, "\n"), "\n")
+
+	available := m.height - m.tableHeight() - 5
+	if available < 1 {
+		available = 1
+	}
+	if len(lines) > available {
+		lines = lines[:available]
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// tableHeight gives the pairs a third of the screen, the report the rest
+func (m *Report) tableHeight() int {
+	height := (m.height - 5) / 3
+	if height < 5 {
+		height = 5
+	}
+	return height
+}
+
+// SetSize sets the size of the report
+func (m *Report) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	// The agent columns share what the numbers leave
+	agentWidth := (width - 5 - 9 - 13 - 15 - 5 - 5 - 16) / 2
+	if agentWidth < 10 {
+		agentWidth = 10
+	}
+	m.table.SetColumns(columns(agentWidth))
+	m.table.SetSize(width, m.tableHeight())
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/opencode-ai/opencode/internal/lsp/protocol"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/session"
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/swarm/health"
 	"github.com/opencode-ai/opencode/internal/tui/components/chat"
 	"github.com/opencode-ai/opencode/internal/tui/styles"
 	"github.com/opencode-ai/opencode/internal/tui/util"
@@ -23,12 +25,27 @@ type StatusCmp interface {
 	SetHelpMsg(string)
 }
 
+// swarmHealthInterval is how often the swarm health is reloaded
+const swarmHealthInterval = 5 * time.Second
+
+// SwarmHealthSource is the part of the swarm the status bar shows
+type SwarmHealthSource interface {
+	Health() swarm.SwarmHealth
+}
+
+// SwarmHealthTickMsg reloads the swarm health shown in the status bar
+type SwarmHealthTickMsg struct{}
+
 type statusCmp struct {
 	info       util.InfoMsg
 	width      int
 	messageTTL time.Duration
 	lspClients map[string]*lsp.Client
 	session    session.Session
+
+	// swarm is nil without a swarm, health is its latest health
+	swarm  SwarmHealthSource
+	health swarm.SwarmHealth
 }
 
 // clearMessageCmd is a command that clears status messages after a timeout
@@ -39,7 +56,17 @@ func (m statusCmp) clearMessageCmd(ttl time.Duration) tea.Cmd {
 }
 
 func (m statusCmp) Init() tea.Cmd {
-	return nil
+	return m.swarmHealthCmd()
+}
+
+// swarmHealthCmd schedules the next reload of the swarm health
+func (m statusCmp) swarmHealthCmd() tea.Cmd {
+	if m.swarm == nil {
+		return nil
+	}
+	return tea.Tick(swarmHealthInterval, func(time.Time) tea.Msg {
+		return SwarmHealthTickMsg{}
+	})
 }
 
 func (m statusCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -66,6 +93,16 @@ func (m statusCmp) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.clearMessageCmd(ttl)
 	case util.ClearStatusMsg:
 		m.info = util.InfoMsg{}
+	case SwarmHealthTickMsg:
+		if m.swarm != nil {
+			m.health = m.swarm.Health()
+		}
+		return m, m.swarmHealthCmd()
+	case pubsub.Event[swarm.TimelineEvent]:
+		// Alerts show up at once, not at the next reload
+		if m.swarm != nil && msg.Payload.Type == swarm.TimelineAlert {
+			m.health = m.swarm.Health()
+		}
 	}
 	return m, nil
 }
@@ -138,10 +175,31 @@ func (m statusCmp) View() string {
 	}
 
 	status += diagnostics
+	status += m.swarmHealth()
 	status += m.model()
 	return status
 }
 
+// swarmHealth renders the health score of the swarm and the critical alerts
+// raised, colored by the overall status
+func (m statusCmp) swarmHealth() string {
+	if m.swarm == nil || m.health.ComponentCount == 0 {
+		return ""
+	}
+	color := styles.Green
+	switch m.health.OverallStatus {
+	case health.HealthStatusDegraded:
+		color = styles.Warning
+	case health.HealthStatusUnhealthy, health.HealthStatusCritical:
+		color = styles.Error
+	}
+	text := fmt.Sprintf("swarm %.0f%%", m.health.OverallScore*100)
+	if m.health.CriticalAlerts > 0 {
+		text += fmt.Sprintf(" %s %d", styles.WarningIcon, m.health.CriticalAlerts)
+	}
+	return styles.Padded.Background(styles.BackgroundDarker).Foreground(color).Render(text)
+}
+
 func (m *statusCmp) projectDiagnostics() string {
 	// Check if any LSP server is still initializing
 	initializing := false
@@ -226,7 +284,7 @@ func (m statusCmp) availableFooterMsgWidth(diagnostics string) int {
 		tokens = formatTokensAndCost(m.session.PromptTokens+m.session.CompletionTokens, m.session.Cost)
 		tokensWidth = lipgloss.Width(tokens) + 2
 	}
-	return max(0, m.width-lipgloss.Width(helpWidget)-lipgloss.Width(m.model())-lipgloss.Width(diagnostics)-tokensWidth)
+	return max(0, m.width-lipgloss.Width(helpWidget)-lipgloss.Width(m.model())-lipgloss.Width(m.swarmHealth())-lipgloss.Width(diagnostics)-tokensWidth)
 }
 
 func (m statusCmp) model() string {
@@ -244,9 +302,15 @@ func (m statusCmp) SetHelpMsg(s string) {
 	helpWidget = styles.Padded.Background(styles.Forground).Foreground(styles.BackgroundDarker).Bold(true).Render(s)
 }
 
-func NewStatusCmp(lspClients map[string]*lsp.Client) StatusCmp {
-	return &statusCmp{
+// NewStatusCmp creates the status bar. swarm is nil when no swarm runs.
+func NewStatusCmp(lspClients map[string]*lsp.Client, swarm SwarmHealthSource) StatusCmp {
+	m := &statusCmp{
 		messageTTL: 10 * time.Second,
 		lspClients: lspClients,
+		swarm:      swarm,
+	}
+	if swarm != nil {
+		m.health = swarm.Health()
 	}
+	return m
 }
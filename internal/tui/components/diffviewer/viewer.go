@@ -0,0 +1,439 @@
+package diffviewer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/diff"
+	"github.com/opencode-ai/opencode/internal/tui/components/highlight"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+)
+
+// diffStyle highlights the code of the diff lines in the colors of the
+// theme
+var diffStyle = diff.NewStyleConfig(diff.WithHighlighter(highlight.Line))
+
+// fileDiff is the parsed diff of a single file
+type fileDiff struct {
+	name      string
+	result    diff.DiffResult
+	additions int
+	removals  int
+}
+
+// DiffViewer compares files or git revisions and renders the result side by
+// side or unified
+type DiffViewer struct {
+	viewport viewport.Model
+	width    int
+	height   int
+
+	input    textinput.Model
+	choosing bool
+
+	title      string
+	files      []fileDiff
+	sideBySide bool
+
+	// hunkOffsets holds the viewport line of every hunk header
+	hunkOffsets []int
+	hunkIndex   int
+
+	err error
+}
+
+// NewDiffViewer creates a new diff viewer
+func NewDiffViewer() *DiffViewer {
+	input := textinput.New()
+	input.Prompt = "Compare: "
+	input.Placeholder = "file | old new | rev1 rev2 | rev1..rev2 (empty: working tree vs HEAD)"
+
+	return &DiffViewer{
+		viewport:   viewport.New(80, 20),
+		input:      input,
+		sideBySide: true,
+	}
+}
+
+// Open asks what to compare when nothing has been loaded yet
+func (m *DiffViewer) Open() tea.Cmd {
+	if m.files != nil {
+		return nil
+	}
+	m.choosing = true
+	return m.input.Focus()
+}
+
+// Capturing returns whether the compare prompt is focused, in which case
+// keys like q and esc must not close the tool
+func (m *DiffViewer) Capturing() bool {
+	return m.choosing
+}
+
+// Init implements tea.Model
+func (m *DiffViewer) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *DiffViewer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if mouseMsg, ok := msg.(tea.MouseMsg); ok {
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(mouseMsg)
+		return m, cmd
+	}
+
+	if _, ok := msg.(styles.ThemeChangedMsg); ok {
+		// Code is highlighted in the colors of the theme
+		if m.files != nil {
+			m.render()
+		}
+		return m, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.choosing {
+		switch keyMsg.String() {
+		case "esc":
+			m.choosing = false
+			m.input.Blur()
+			return m, nil
+		case "enter":
+			m.choosing = false
+			m.input.Blur()
+			m.load(m.input.Value())
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(keyMsg)
+		return m, cmd
+	}
+
+	switch keyMsg.String() {
+	case "o":
+		m.choosing = true
+		return m, m.input.Focus()
+	case "v":
+		m.sideBySide = !m.sideBySide
+		m.render()
+		return m, nil
+	case "n", "]":
+		m.jumpToHunk(m.hunkIndex + 1)
+		return m, nil
+	case "N", "p", "[":
+		m.jumpToHunk(m.hunkIndex - 1)
+		return m, nil
+	case "r":
+		m.load(m.input.Value())
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(keyMsg)
+	return m, cmd
+}
+
+// View implements tea.Model
+func (m *DiffViewer) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Diff Viewer")
+	if m.title != "" {
+		title = lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			title,
+			styles.BaseStyle.Foreground(styles.ForgroundMid).Render("  "+m.title),
+		)
+	}
+
+	var status string
+	switch {
+	case m.err != nil:
+		status = styles.BaseStyle.Foreground(styles.Error).Render(m.err.Error())
+	case m.files != nil:
+		additions, removals := 0, 0
+		for _, f := range m.files {
+			additions += f.additions
+			removals += f.removals
+		}
+		hunk := ""
+		if len(m.hunkOffsets) > 0 {
+			hunk = fmt.Sprintf(" • hunk %d/%d", m.hunkIndex+1, len(m.hunkOffsets))
+		}
+		status = lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			styles.BaseStyle.Foreground(styles.ForgroundMid).Render(fmt.Sprintf("%d files • ", len(m.files))),
+			styles.BaseStyle.Foreground(styles.Green).Render(fmt.Sprintf("+%d", additions)),
+			styles.BaseStyle.Render(" "),
+			styles.BaseStyle.Foreground(styles.Red).Render(fmt.Sprintf("-%d", removals)),
+			styles.BaseStyle.Foreground(styles.ForgroundMid).Render(hunk),
+		)
+	}
+
+	prompt := styles.BaseStyle.
+		Foreground(styles.ForgroundDim).
+		Render("o: compare • n/N: next/prev hunk • v: side-by-side/unified • r: reload • q/esc: close")
+	if m.choosing {
+		prompt = m.input.View()
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		status,
+		prompt,
+		"",
+		m.viewport.View(),
+	)
+}
+
+// SetSize sets the size of the viewer
+func (m *DiffViewer) SetSize(width, height int) {
+	resized := width != m.width
+	m.width = width
+	m.height = height
+
+	viewportHeight := height - 4
+	if viewportHeight < 1 {
+		viewportHeight = 1
+	}
+	m.viewport.Width = width
+	m.viewport.Height = viewportHeight
+	m.input.Width = width - len(m.input.Prompt) - 1
+
+	// Diff lines are rendered to the full width
+	if resized && m.files != nil {
+		m.render()
+	}
+}
+
+// load resolves the compare spec and renders the resulting diff
+func (m *DiffViewer) load(spec string) {
+	files, title, err := resolve(strings.Fields(spec))
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.err = nil
+	m.files = files
+	m.title = title
+	m.hunkIndex = 0
+	m.render()
+	m.viewport.GotoTop()
+}
+
+func (m *DiffViewer) render() {
+	width := m.width
+	if width < 20 {
+		width = 80
+	}
+
+	var sb strings.Builder
+	lines := 0
+	write := func(text string) {
+		sb.WriteString(text)
+		lines += strings.Count(text, "\n")
+	}
+
+	m.hunkOffsets = nil
+	if len(m.files) == 0 {
+		write(styles.BaseStyle.Foreground(styles.ForgroundDim).Render("No differences") + "\n")
+	}
+	for _, f := range m.files {
+		header := styles.BaseStyle.
+			Bold(true).
+			Foreground(styles.Forground).
+			Width(width).
+			Render(fmt.Sprintf("%s  +%d -%d", f.name, f.additions, f.removals))
+		write(header + "\n")
+
+		for _, h := range f.result.Hunks {
+			m.hunkOffsets = append(m.hunkOffsets, lines)
+			write(styles.BaseStyle.Foreground(styles.ForgroundDim).Render(h.Header) + "\n")
+			if m.sideBySide {
+				write(diff.RenderSideBySideHunk(f.name, h, diff.WithTotalWidth(width), diff.WithStyle(diffStyle)))
+			} else {
+				write(diff.RenderUnifiedHunk(f.name, h, diff.WithTotalWidth(width), diff.WithStyle(diffStyle)))
+			}
+		}
+		write("\n")
+	}
+
+	m.viewport.SetContent(strings.TrimSuffix(sb.String(), "\n"))
+	if m.hunkIndex >= len(m.hunkOffsets) {
+		m.hunkIndex = 0
+	}
+}
+
+func (m *DiffViewer) jumpToHunk(index int) {
+	if len(m.hunkOffsets) == 0 {
+		return
+	}
+	if index < 0 {
+		index = len(m.hunkOffsets) - 1
+	}
+	if index >= len(m.hunkOffsets) {
+		index = 0
+	}
+	m.hunkIndex = index
+	m.viewport.SetYOffset(m.hunkOffsets[index])
+}
+
+// resolve turns the compare arguments into file diffs:
+//
+//	(none)        working tree against HEAD
+//	file          file against its HEAD version
+//	old new       two files, or two git revisions
+//	rev1..rev2    two git revisions
+func resolve(args []string) ([]fileDiff, string, error) {
+	switch len(args) {
+	case 0:
+		files, err := gitDiff("HEAD")
+		return files, "working tree vs HEAD", err
+	case 1:
+		if isFile(args[0]) {
+			files, err := fileAgainstHead(args[0])
+			return files, args[0] + " vs HEAD", err
+		}
+		files, err := gitDiff(args[0])
+		return files, args[0], err
+	case 2:
+		if isFile(args[0]) && isFile(args[1]) {
+			files, err := compareFiles(args[0], args[1])
+			return files, args[0] + " vs " + args[1], err
+		}
+		files, err := gitDiff(args[0], args[1])
+		return files, args[0] + " vs " + args[1], err
+	}
+	return nil, "", fmt.Errorf("expected at most two files or revisions")
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(absPath(path))
+	return err == nil && !info.IsDir()
+}
+
+func absPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(config.WorkingDirectory(), path)
+}
+
+func compareFiles(oldPath, newPath string) ([]fileDiff, error) {
+	before, err := os.ReadFile(absPath(oldPath))
+	if err != nil {
+		return nil, err
+	}
+	after, err := os.ReadFile(absPath(newPath))
+	if err != nil {
+		return nil, err
+	}
+	return fromContents(string(before), string(after), newPath)
+}
+
+func fileAgainstHead(path string) ([]fileDiff, error) {
+	after, err := os.ReadFile(absPath(path))
+	if err != nil {
+		return nil, err
+	}
+	// Untracked files compare against an empty file
+	before, _ := git("show", "HEAD:./"+filepath.ToSlash(path))
+	return fromContents(before, string(after), path)
+}
+
+func fromContents(before, after, name string) ([]fileDiff, error) {
+	unified, additions, removals := diff.GenerateDiff(before, after, name)
+	if additions == 0 && removals == 0 {
+		return []fileDiff{}, nil
+	}
+	result, err := diff.ParseUnifiedDiff(unified)
+	if err != nil {
+		return nil, err
+	}
+	return []fileDiff{{name: name, result: result, additions: additions, removals: removals}}, nil
+}
+
+// gitDiff runs git diff for the revisions and splits the output per file
+func gitDiff(revisions ...string) ([]fileDiff, error) {
+	args := append([]string{"diff", "--no-color", "--no-ext-diff"}, revisions...)
+	out, err := git(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []fileDiff{}
+	for _, section := range splitFiles(out) {
+		result, err := diff.ParseUnifiedDiff(section)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Hunks) == 0 {
+			continue
+		}
+		name := result.NewFile
+		if name == "" {
+			name = result.OldFile
+		}
+		f := fileDiff{name: name, result: result}
+		for _, h := range result.Hunks {
+			for _, line := range h.Lines {
+				switch line.Kind {
+				case diff.LineAdded:
+					f.additions++
+				case diff.LineRemoved:
+					f.removals++
+				}
+			}
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// splitFiles splits multi file git diff output at each file header
+func splitFiles(out string) []string {
+	var sections []string
+	var current strings.Builder
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "diff --git ") && current.Len() > 0 {
+			sections = append(sections, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+		current.WriteByte('\n')
+	}
+	if strings.TrimSpace(current.String()) != "" {
+		sections = append(sections, current.String())
+	}
+	return sections
+}
+
+func git(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = config.WorkingDirectory()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
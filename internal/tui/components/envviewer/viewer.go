@@ -0,0 +1,287 @@
+package envviewer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	bubbletable "github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/tui/components/chat"
+	"github.com/opencode-ai/opencode/internal/tui/components/table"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// shellTimeout bounds how long loading the login shell environment may take
+const shellTimeout = 3 * time.Second
+
+// secretName matches variable names that usually hold credentials
+var secretName = regexp.MustCompile(`(?i)(KEY|TOKEN|SECRET|PASSWORD|PASSWD|PASS|CREDENTIAL|AUTH|PRIVATE|SESSION|COOKIE|DSN)`)
+
+const (
+	sourceProcess = "process"
+	sourceShell   = "shell"
+	sourceBoth    = "both"
+)
+
+// variable is an environment variable and where it was found
+type variable struct {
+	name   string
+	value  string
+	source string
+}
+
+// shellEnvMsg carries the environment of the user's login shell
+type shellEnvMsg struct {
+	env map[string]string
+	err error
+}
+
+// EnvViewer lists process and shell environment variables, masking values
+// that look like secrets
+type EnvViewer struct {
+	table  *table.DataTable
+	width  int
+	height int
+
+	process  map[string]string
+	shell    map[string]string
+	shellErr error
+	reveal   bool
+}
+
+// NewEnvViewer creates a new environment variables viewer
+func NewEnvViewer() *EnvViewer {
+	columns := []bubbletable.Column{
+		{Title: "Name", Width: 30},
+		{Title: "Value", Width: 60},
+		{Title: "Source", Width: 8},
+	}
+	t := table.NewDataTable(columns, nil)
+	t.SetMultiSelect(true, 0)
+	t.SortBy(0, false)
+
+	m := &EnvViewer{
+		table:   t,
+		process: processEnv(),
+	}
+	m.refresh()
+	return m
+}
+
+// Open reloads the process environment and the login shell environment
+func (m *EnvViewer) Open() tea.Cmd {
+	m.process = processEnv()
+	m.refresh()
+	return loadShellEnv
+}
+
+// Capturing returns whether the filter input is focused
+func (m *EnvViewer) Capturing() bool {
+	return m.table.IsFiltering()
+}
+
+// Init implements tea.Model
+func (m *EnvViewer) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *EnvViewer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case shellEnvMsg:
+		m.shell = msg.env
+		m.shellErr = msg.err
+		m.refresh()
+		return m, nil
+	case tea.KeyMsg:
+		if !m.table.IsFiltering() {
+			switch msg.String() {
+			case "v":
+				m.reveal = !m.reveal
+				m.refresh()
+				return m, nil
+			case "e":
+				return m, m.export()
+			case "R":
+				return m, m.Open()
+			}
+		}
+	}
+
+	_, cmd := m.table.Update(msg)
+	return m, cmd
+}
+
+// View implements tea.Model
+func (m *EnvViewer) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Environment Variables")
+
+	status := fmt.Sprintf("%d process • %d shell", len(m.process), len(m.shell))
+	if m.shellErr != nil {
+		status = fmt.Sprintf("%d process • shell unavailable: %s", len(m.process), m.shellErr)
+	}
+	if m.reveal {
+		status += " • secrets revealed"
+	}
+
+	help := "v: reveal secrets • e: export marked (or current) to chat • R: reload"
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		styles.BaseStyle.Foreground(styles.ForgroundMid).Render(status),
+		styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help),
+		"",
+		m.table.View(),
+	)
+}
+
+// SetSize sets the size of the viewer
+func (m *EnvViewer) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	// Source and separators take a fixed width, names get a third of the rest
+	rest := width - 8 - 6
+	if rest < 20 {
+		rest = 20
+	}
+	m.table.SetColumns([]bubbletable.Column{
+		{Title: "Name", Width: rest / 3},
+		{Title: "Value", Width: rest - rest/3},
+		{Title: "Source", Width: 8},
+	})
+	m.table.SetSize(width, height-4)
+}
+
+// variables merges the process and shell environments
+func (m *EnvViewer) variables() map[string]variable {
+	vars := make(map[string]variable, len(m.process)+len(m.shell))
+	for name, value := range m.process {
+		vars[name] = variable{name: name, value: value, source: sourceProcess}
+	}
+	for name, value := range m.shell {
+		if v, ok := vars[name]; ok {
+			v.source = sourceBoth
+			vars[name] = v
+			continue
+		}
+		vars[name] = variable{name: name, value: value, source: sourceShell}
+	}
+	return vars
+}
+
+func (m *EnvViewer) refresh() {
+	vars := m.variables()
+	rows := make([]bubbletable.Row, 0, len(vars))
+	for _, v := range vars {
+		value := v.value
+		if !m.reveal && isSecret(v.name) {
+			value = mask(value)
+		}
+		rows = append(rows, bubbletable.Row{v.name, value, v.source})
+	}
+	m.table.SetRows(rows)
+}
+
+// export inserts the marked variables, or the current one, into the chat
+// editor. Secret values are always redacted so they never reach the model.
+func (m *EnvViewer) export() tea.Cmd {
+	rows := m.table.SelectedRows()
+	if len(rows) == 0 {
+		if row := m.table.SelectedRow(); row != nil {
+			rows = []bubbletable.Row{row}
+		}
+	}
+	if len(rows) == 0 {
+		return util.ReportWarn("No variables selected")
+	}
+
+	vars := m.variables()
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, row[0])
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("Environment variables:\n```\n")
+	for _, name := range names {
+		value := vars[name].value
+		if isSecret(name) {
+			value = "<redacted>"
+		}
+		fmt.Fprintf(&sb, "%s=%s\n", name, value)
+	}
+	sb.WriteString("```\n")
+
+	m.table.ClearSelection()
+	return tea.Batch(
+		util.CmdHandler(chat.InsertTextMsg{Text: sb.String()}),
+		util.ReportInfo(fmt.Sprintf("Exported %d variables to chat", len(names))),
+	)
+}
+
+func isSecret(name string) bool {
+	return secretName.MatchString(name)
+}
+
+// mask hides a value, keeping a short prefix so similar values can be told
+// apart
+func mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) <= 8 {
+		return strings.Repeat("•", 8)
+	}
+	return value[:4] + strings.Repeat("•", 8)
+}
+
+func processEnv() map[string]string {
+	return parseEnv(os.Environ())
+}
+
+func parseEnv(lines []string) map[string]string {
+	env := make(map[string]string, len(lines))
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || name == "" {
+			continue
+		}
+		env[name] = value
+	}
+	return env
+}
+
+// loadShellEnv reads the environment a login shell would start with, which
+// may differ from the one opencode was launched with
+func loadShellEnv() tea.Msg {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		return shellEnvMsg{err: fmt.Errorf("SHELL is not set")}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shellTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, shell, "-l", "-c", "env")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return shellEnvMsg{err: err}
+	}
+	return shellEnvMsg{env: parseEnv(strings.Split(stdout.String(), "\n"))}
+}
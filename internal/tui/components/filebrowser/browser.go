@@ -1,14 +1,18 @@
 package filebrowser
 
 import (
-	"os"
+	"fmt"
+	"io"
 	"path/filepath"
-	"sort"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/paginator"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/opencode-ai/opencode/internal/tui/keymap"
 	"github.com/opencode-ai/opencode/internal/tui/styles"
 )
 
@@ -17,7 +21,6 @@ type FileItem struct {
 	name    string
 	path    string
 	isDir   bool
-	size    int64
 }
 
 // Implement list.Item interface
@@ -36,155 +39,251 @@ func (i FileItem) Description() string {
 	return i.path
 }
 
-// FileBrowser is a file tree browser component
+// zoneDelegate wraps the default delegate and marks every rendered item as a
+// mouse zone so rows can be clicked
+type zoneDelegate struct {
+	list.DefaultDelegate
+	prefix string
+}
+
+func (d zoneDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	var sb strings.Builder
+	d.DefaultDelegate.Render(&sb, m, index, item)
+	fmt.Fprint(w, zone.Mark(d.itemZone(index), sb.String()))
+}
+
+func (d zoneDelegate) itemZone(index int) string {
+	return fmt.Sprintf("%sitem-%d", d.prefix, index)
+}
+
+// FileBrowser is a file tree browser component. Directories are loaded in
+// batches by commands, and the list only renders the page that is shown, so
+// directories with tens of thousands of entries stay responsive.
 type FileBrowser struct {
 	list          list.Model
+	delegate      zoneDelegate
 	currentPath   string
 	width         int
 	height        int
 	selectedFile  string
+	// entries of currentPath loaded so far, sorted, without ".."
+	entries       []FileItem
+	// loadID identifies the load in progress, zero when idle
+	loadID        uint64
+	// preview of the selected file, see preview.go
+	preview       filePreview
 }
 
-// NewFileBrowser creates a new file browser
+// NewFileBrowser creates a new file browser. The start directory is loaded
+// by Init.
 func NewFileBrowser(startPath string) *FileBrowser {
 	items := []list.Item{}
 	
-	delegate := list.NewDefaultDelegate()
+	delegate := zoneDelegate{
+		DefaultDelegate: list.NewDefaultDelegate(),
+		prefix:          zone.NewPrefix(),
+	}
 	l := list.New(items, delegate, 0, 0)
 	l.Title = "File Browser"
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(true)
+	// Dots render one per page, which is a lot of dots for a huge directory
+	l.Paginator.Type = paginator.Arabic
 	
-	fb := &FileBrowser{
+	return &FileBrowser{
 		list:        l,
+		delegate:    delegate,
 		currentPath: startPath,
 	}
-	
-	// Load initial directory
-	_ = fb.loadDirectory(startPath)
-	
-	return fb
 }
 
-// loadDirectory loads files from a directory
-func (m *FileBrowser) loadDirectory(path string) error {
-	entries, err := os.ReadDir(path)
-	if err != nil {
-		return err
+// load starts loading a directory, abandoning any load in progress. The
+// current listing stays until the first batch arrives.
+func (m *FileBrowser) load(path string) tea.Cmd {
+	m.loadID = loadIDs.Add(1)
+	m.list.Title = "File Browser: " + path
+	return tea.Batch(m.list.StartSpinner(), loadDirectory(m.loadID, path))
+}
+
+// entriesLoaded merges a batch of entries into the list and reads the next
+func (m *FileBrowser) entriesLoaded(msg entriesLoadedMsg) tea.Cmd {
+	if msg.loadID != m.loadID {
+		// Abandoned loads stop here
+		if !msg.done {
+			msg.dir.Close()
+		}
+		return nil
 	}
-	
-	var items []list.Item
-	
+
+	var cmds []tea.Cmd
+	selected, _ := m.list.SelectedItem().(FileItem)
+	if msg.first {
+		if msg.err != nil {
+			// Keep showing the directory we were in
+			m.loadID = 0
+			m.list.Title = "File Browser: " + m.currentPath
+			m.list.StopSpinner()
+			return m.list.NewStatusMessage("Cannot open " + msg.path + ": " + msg.err.Error())
+		}
+		m.currentPath = msg.path
+		m.entries = nil
+		selected = FileItem{}
+		m.list.ResetSelected()
+	}
+
+	m.entries = mergeItems(m.entries, msg.entries)
+	cmds = append(cmds, m.setItems(selected))
+
+	if msg.done {
+		m.loadID = 0
+		m.list.Title = "File Browser: " + m.currentPath
+		m.list.StopSpinner()
+		if msg.err != nil {
+			cmds = append(cmds, m.list.NewStatusMessage("Listing stopped early: "+msg.err.Error()))
+		}
+	} else {
+		m.list.Title = fmt.Sprintf("File Browser: %s (loading %d entries…)", m.currentPath, len(m.entries))
+		cmds = append(cmds, readMore(msg))
+	}
+	return tea.Batch(cmds...)
+}
+
+// setItems shows the loaded entries, keeping the selected entry selected
+// as entries are inserted before it
+func (m *FileBrowser) setItems(selected FileItem) tea.Cmd {
+	items := make([]list.Item, 0, len(m.entries)+1)
 	// Add parent directory entry if not at root
-	if path != "/" && path != "" {
+	if parent := filepath.Dir(m.currentPath); parent != m.currentPath {
 		items = append(items, FileItem{
 			name:  "..",
-			path:  filepath.Dir(path),
+			path:  parent,
 			isDir: true,
 		})
 	}
-	
-	// Sort directories first, then files
-	var dirs []os.DirEntry
-	var files []os.DirEntry
-	
-	for _, entry := range entries {
-		// Skip hidden files
-		if strings.HasPrefix(entry.Name(), ".") {
-			continue
-		}
-		
-		if entry.IsDir() {
-			dirs = append(dirs, entry)
-		} else {
-			files = append(files, entry)
-		}
+	for _, entry := range m.entries {
+		items = append(items, entry)
 	}
-	
-	// Sort each group alphabetically
-	sort.Slice(dirs, func(i, j int) bool {
-		return dirs[i].Name() < dirs[j].Name()
-	})
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].Name() < files[j].Name()
-	})
-	
-	// Add directories
-	for _, dir := range dirs {
-		info, _ := dir.Info()
-		items = append(items, FileItem{
-			name:  dir.Name(),
-			path:  filepath.Join(path, dir.Name()),
-			isDir: true,
-			size:  info.Size(),
-		})
+	cmd := m.list.SetItems(items)
+
+	if selected.path == "" || m.list.FilterState() != list.Unfiltered {
+		return cmd
 	}
-	
-	// Add files
-	for _, file := range files {
-		info, _ := file.Info()
-		items = append(items, FileItem{
-			name:  file.Name(),
-			path:  filepath.Join(path, file.Name()),
-			isDir: false,
-			size:  info.Size(),
-		})
+	for i, item := range items {
+		if item.(FileItem).path == selected.path {
+			m.list.Select(i)
+			break
+		}
 	}
-	
-	m.list.SetItems(items)
-	m.currentPath = path
-	m.list.Title = "File Browser: " + path
-	
-	return nil
+	return cmd
 }
 
 // Init implements tea.Model
 func (m *FileBrowser) Init() tea.Cmd {
-	return nil
+	return m.load(m.currentPath)
 }
 
 // Update implements tea.Model
 func (m *FileBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(previewLoadedMsg); ok {
+		m.previewLoaded(msg)
+		return m, nil
+	}
+	cmd := m.update(msg)
+	return m, tea.Batch(cmd, m.previewSelected())
+}
+
+// update handles a message, after which the selected file is previewed
+func (m *FileBrowser) update(msg tea.Msg) tea.Cmd {
 	var cmd tea.Cmd
 	
 	switch msg := msg.(type) {
+	case entriesLoadedMsg:
+		return m.entriesLoaded(msg)
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "esc":
-			return m, nil
-		case "enter":
-			// Navigate into directory or select file
-			if selected, ok := m.list.SelectedItem().(FileItem); ok {
-				if selected.isDir {
-					// Navigate into directory
-					_ = m.loadDirectory(selected.path)
-					return m, nil
-				} else {
-					// File selected
-					m.selectedFile = selected.path
-					return m, nil
-				}
-			}
-		case "backspace":
+		if m.list.SettingFilter() {
+			break
+		}
+		switch {
+		case key.Matches(msg, keymap.Get(keymap.FileBrowserClose)):
+			return nil
+		case key.Matches(msg, keymap.Get(keymap.FileBrowserOpen)):
+			return m.openSelected()
+		case key.Matches(msg, keymap.Get(keymap.FileBrowserParent)):
 			// Go to parent directory
 			parent := filepath.Dir(m.currentPath)
 			if parent != m.currentPath {
-				_ = m.loadDirectory(parent)
+				return m.load(parent)
 			}
-			return m, nil
+			return nil
 		}
 	}
 	
 	m.list, cmd = m.list.Update(msg)
-	return m, cmd
+	return cmd
+}
+
+// openSelected navigates into the selected directory or selects the file
+func (m *FileBrowser) openSelected() tea.Cmd {
+	selected, ok := m.list.SelectedItem().(FileItem)
+	if !ok {
+		return nil
+	}
+	if selected.isDir {
+		return m.load(selected.path)
+	}
+	m.selectedFile = selected.path
+	return nil
+}
+
+// handleMouse scrolls with the wheel and selects the clicked row. Clicking
+// the row that is already selected opens it.
+func (m *FileBrowser) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.list.SettingFilter() {
+		return nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.list.CursorUp()
+		return nil
+	case tea.MouseButtonWheelDown:
+		m.list.CursorDown()
+		return nil
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	start, end := m.list.Paginator.GetSliceBounds(len(m.list.VisibleItems()))
+	for index := start; index < end; index++ {
+		if !zone.Get(m.delegate.itemZone(index)).InBounds(msg) {
+			continue
+		}
+		if index == m.list.Index() {
+			return m.openSelected()
+		}
+		m.list.Select(index)
+		return nil
+	}
+	return nil
 }
 
 // View implements tea.Model
 func (m *FileBrowser) View() string {
 	helpStyle := lipgloss.NewStyle().Foreground(styles.ForgroundDim)
-	help := helpStyle.Render("\nenter: open • backspace: parent • /: filter • q/esc: close")
+	help := helpStyle.Render(fmt.Sprintf("\n%s: open • %s: parent • /: filter • %s: close",
+		keymap.Get(keymap.FileBrowserOpen).Help().Key,
+		keymap.Get(keymap.FileBrowserParent).Help().Key,
+		keymap.Get(keymap.FileBrowserClose).Help().Key))
 	
-	return m.list.View() + "\n" + help
+	if !m.showPreview() {
+		return m.list.View() + "\n" + help
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, m.list.View(), " ", m.previewView()) + "\n" + help
 }
 
 // SetSize sets the size of the browser
@@ -192,8 +291,12 @@ func (m *FileBrowser) SetSize(width, height int) {
 	m.width = width
 	m.height = height
 	
-	// Leave room for help text
-	m.list.SetSize(width, height-2)
+	// Leave room for help text, and for the preview on wide screens
+	listWidth := width
+	if m.showPreview() {
+		listWidth = width / 2
+	}
+	m.list.SetSize(listWidth, height-2)
 }
 
 // GetSelectedFile returns the currently selected file path
@@ -206,7 +309,8 @@ func (m *FileBrowser) GetCurrentPath() string {
 	return m.currentPath
 }
 
-// SetCurrentPath sets the current directory and loads it
-func (m *FileBrowser) SetCurrentPath(path string) error {
-	return m.loadDirectory(path)
+// SetCurrentPath starts loading a directory, which becomes the current
+// directory once its first entries arrive
+func (m *FileBrowser) SetCurrentPath(path string) tea.Cmd {
+	return m.load(path)
 }
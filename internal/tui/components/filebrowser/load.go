@@ -0,0 +1,107 @@
+package filebrowser
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// loadBatchSize is how many directory entries are read per command, so a
+// huge directory fills the list a batch at a time instead of blocking the UI
+const loadBatchSize = 512
+
+// loadIDs numbers directory loads so results of abandoned loads are ignored
+var loadIDs atomic.Uint64
+
+// entriesLoadedMsg carries a batch of entries read from a directory. The
+// directory stays open until the last batch.
+type entriesLoadedMsg struct {
+	loadID  uint64
+	path    string
+	dir     *os.File
+	entries []FileItem
+	first   bool
+	done    bool
+	err     error
+}
+
+// loadDirectory opens a directory and reads its first batch of entries
+func loadDirectory(loadID uint64, path string) tea.Cmd {
+	return func() tea.Msg {
+		dir, err := os.Open(path)
+		if err != nil {
+			return entriesLoadedMsg{loadID: loadID, path: path, first: true, done: true, err: err}
+		}
+		msg := readEntries(loadID, path, dir)
+		msg.first = true
+		return msg
+	}
+}
+
+// readMore reads the next batch of entries from a directory being loaded
+func readMore(msg entriesLoadedMsg) tea.Cmd {
+	return func() tea.Msg {
+		return readEntries(msg.loadID, msg.path, msg.dir)
+	}
+}
+
+// readEntries reads a batch of entries, sorted, and closes the directory
+// after the last one. Hidden entries are skipped and entries are not
+// stat'ed, which is most of the cost of listing large directories.
+func readEntries(loadID uint64, path string, dir *os.File) entriesLoadedMsg {
+	msg := entriesLoadedMsg{loadID: loadID, path: path, dir: dir}
+	entries, err := dir.ReadDir(loadBatchSize)
+	if err != nil {
+		msg.done = true
+		if !errors.Is(err, io.EOF) {
+			msg.err = err
+		}
+		dir.Close()
+	}
+
+	msg.entries = make([]FileItem, 0, len(entries))
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		msg.entries = append(msg.entries, FileItem{
+			name:  entry.Name(),
+			path:  filepath.Join(path, entry.Name()),
+			isDir: entry.IsDir(),
+		})
+	}
+	sort.Slice(msg.entries, func(i, j int) bool {
+		return itemLess(msg.entries[i], msg.entries[j])
+	})
+	return msg
+}
+
+// itemLess orders directories first, then alphabetically
+func itemLess(a, b FileItem) bool {
+	if a.isDir != b.isDir {
+		return a.isDir
+	}
+	return a.name < b.name
+}
+
+// mergeItems merges two sorted runs of entries into a new sorted slice
+func mergeItems(a, b []FileItem) []FileItem {
+	merged := make([]FileItem, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if itemLess(b[0], a[0]) {
+			merged = append(merged, b[0])
+			b = b[1:]
+		} else {
+			merged = append(merged, a[0])
+			a = a[1:]
+		}
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
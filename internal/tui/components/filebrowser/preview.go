@@ -0,0 +1,127 @@
+package filebrowser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/tui/components/highlight"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+)
+
+const (
+	// previewMinWidth is the narrowest browser that previews files, beside
+	// the list
+	previewMinWidth = 100
+	// previewMaxBytes bounds what is read of a previewed file
+	previewMaxBytes = 64 * 1024
+)
+
+// filePreview is the start of the selected file, read by a command so
+// slow disks do not block the list
+type filePreview struct {
+	// path is the file previewed or being read
+	path    string
+	content string
+	err     error
+	loading bool
+}
+
+// previewLoadedMsg carries the start of a file read for the preview
+type previewLoadedMsg struct {
+	path    string
+	content string
+	err     error
+}
+
+// errBinary marks files that are not text
+var errBinary = errors.New("binary file")
+
+// showPreview returns whether the browser is wide enough for the preview
+func (m *FileBrowser) showPreview() bool {
+	return m.width >= previewMinWidth
+}
+
+// previewSelected starts reading the selected file unless it is already
+// previewed
+func (m *FileBrowser) previewSelected() tea.Cmd {
+	if !m.showPreview() {
+		return nil
+	}
+	selected, ok := m.list.SelectedItem().(FileItem)
+	if !ok || selected.isDir || selected.path == m.preview.path {
+		return nil
+	}
+	m.preview = filePreview{path: selected.path, loading: true}
+	return readPreview(selected.path)
+}
+
+// readPreview reads the start of a file, telling text from binary files
+func readPreview(path string) tea.Cmd {
+	return func() tea.Msg {
+		f, err := os.Open(path)
+		if err != nil {
+			return previewLoadedMsg{path: path, err: err}
+		}
+		defer f.Close()
+		data, err := io.ReadAll(io.LimitReader(f, previewMaxBytes))
+		if err != nil {
+			return previewLoadedMsg{path: path, err: err}
+		}
+		// A rune cut at the limit is no sign of a binary file
+		if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data[:max(0, len(data)-utf8.UTFMax)]) {
+			return previewLoadedMsg{path: path, err: errBinary}
+		}
+		return previewLoadedMsg{path: path, content: string(data)}
+	}
+}
+
+// previewLoaded shows a file read for the preview, unless another file was
+// selected in the meantime
+func (m *FileBrowser) previewLoaded(msg previewLoadedMsg) {
+	if msg.path != m.preview.path {
+		return
+	}
+	m.preview = filePreview{path: msg.path, content: msg.content, err: msg.err}
+}
+
+// previewView renders the selected file with its code highlighted and its
+// lines numbered, as much as fits beside the list
+func (m *FileBrowser) previewView() string {
+	width := m.width - m.width/2 - 1
+	height := m.height - 2
+	dim := styles.BaseStyle.Foreground(styles.ForgroundDim)
+
+	lines := []string{styles.BaseStyle.Bold(true).Foreground(styles.PrimaryColor).Render(filepath.Base(m.preview.path))}
+	switch {
+	case m.preview.path == "":
+		lines[0] = dim.Render("Select a file to preview it")
+	case m.preview.loading:
+		lines = append(lines, dim.Render("Loading…"))
+	case m.preview.err != nil:
+		lines = append(lines, dim.Render(m.preview.err.Error()))
+	default:
+		code := highlight.Code(m.preview.content, m.preview.path, styles.Background)
+		numberWidth := len(fmt.Sprint(strings.Count(m.preview.content, "\n") + 1))
+		for i, line := range strings.Split(strings.TrimSuffix(code, "\n"), "\n") {
+			if len(lines) >= height {
+				break
+			}
+			number := dim.Render(fmt.Sprintf("%*d ", numberWidth, i+1))
+			lines = append(lines, number+strings.ReplaceAll(line, "\t", "    "))
+		}
+	}
+
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	return lipgloss.NewStyle().Width(width).Height(height).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,237 @@
+// Package highlight colors code for the TUI with chroma. Colors follow the
+// active theme, and highlighted snippets are cached per file so views that
+// rerender on every resize or scroll do not tokenize again.
+package highlight
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	chromastyles "github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+)
+
+const (
+	// maxFiles bounds the files snippets are cached for, the least recently
+	// highlighted is dropped first
+	maxFiles = 64
+	// maxSnippets bounds the snippets cached per file
+	maxSnippets = 4096
+)
+
+// themeKey identifies the colors snippets were highlighted with
+type themeKey struct {
+	theme styles.Theme
+	dark  bool
+}
+
+// styleKey identifies a chroma style: the theme over a background
+type styleKey struct {
+	themeKey
+	bg string
+}
+
+// fileCache holds the highlighted snippets of a file by background and text
+type fileCache struct {
+	lexer    chroma.Lexer
+	snippets map[string]string
+}
+
+var cache = struct {
+	mu     sync.Mutex
+	theme  themeKey
+	styles map[styleKey]*chroma.Style
+	files  map[string]*fileCache
+	// order lists the files from least to most recently highlighted
+	order []string
+}{
+	styles: make(map[styleKey]*chroma.Style),
+	files:  make(map[string]*fileCache),
+}
+
+// Detect reports whether name, a file name or a language such as "go", has
+// a lexer
+func Detect(name string) bool {
+	return lexers.Match(name) != nil || lexers.Get(name) != nil
+}
+
+// Code highlights source over bg, in the language of name: a file name or a
+// language such as "go". Unknown languages are guessed from the source.
+// Without a background the terminal's shows through.
+func Code(source, name string, bg lipgloss.TerminalColor) string {
+	key := themeKey{theme: styles.CurrentTheme()}
+	key.dark = key.theme.IsDark()
+	bgHex := hex(bg, key.dark)
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	// Snippets of another theme are of no use anymore
+	if key != cache.theme {
+		cache.theme = key
+		cache.styles = make(map[styleKey]*chroma.Style)
+		cache.files = make(map[string]*fileCache)
+		cache.order = nil
+	}
+
+	file := cachedFile(name)
+	snippetKey := bgHex + "\x00" + source
+	if highlighted, ok := file.snippets[snippetKey]; ok {
+		return highlighted
+	}
+
+	lexer := file.lexer
+	if lexer == nil {
+		lexer = lexers.Analyse(source)
+		if lexer == nil {
+			lexer = lexers.Fallback
+		}
+		lexer = chroma.Coalesce(lexer)
+	}
+	style := cachedStyle(styleKey{themeKey: key, bg: bgHex})
+
+	highlighted := source
+	if it, err := lexer.Tokenise(nil, source); err == nil {
+		var buf bytes.Buffer
+		if formatters.TTY16m.Format(&buf, style, it) == nil {
+			highlighted = buf.String()
+		}
+	}
+
+	if len(file.snippets) >= maxSnippets {
+		file.snippets = make(map[string]string)
+	}
+	file.snippets[snippetKey] = highlighted
+	return highlighted
+}
+
+// Line highlights a single line of a file, e.g. of a diff. The line is
+// highlighted on its own, so constructs spanning lines lose their color.
+func Line(fileName, line string, bg lipgloss.TerminalColor) string {
+	return strings.TrimSuffix(Code(line, fileName, bg), "\n")
+}
+
+// cachedFile returns the cache of a file, creating it with the file's lexer
+// if need be, and marks it most recently used. Callers hold cache.mu.
+func cachedFile(name string) *fileCache {
+	for i, cached := range cache.order {
+		if cached == name {
+			cache.order = append(append(cache.order[:i:i], cache.order[i+1:]...), name)
+			break
+		}
+	}
+	if file, ok := cache.files[name]; ok {
+		return file
+	}
+
+	file := &fileCache{snippets: make(map[string]string)}
+	if lexer := lexers.Match(name); lexer != nil {
+		file.lexer = chroma.Coalesce(lexer)
+	} else if lexer := lexers.Get(name); lexer != nil {
+		file.lexer = chroma.Coalesce(lexer)
+	}
+	cache.files[name] = file
+	cache.order = append(cache.order, name)
+	if len(cache.order) > maxFiles {
+		delete(cache.files, cache.order[0])
+		cache.order = cache.order[1:]
+	}
+	return file
+}
+
+// cachedStyle returns the chroma style of a theme over a background.
+// Callers hold cache.mu.
+func cachedStyle(key styleKey) *chroma.Style {
+	if style, ok := cache.styles[key]; ok {
+		return style
+	}
+	style := themeStyle(key)
+	cache.styles[key] = style
+	return style
+}
+
+// themeStyle maps the colors of a theme to the tokens of chroma
+func themeStyle(key styleKey) *chroma.Style {
+	t := key.theme
+	c := func(color lipgloss.AdaptiveColor) string {
+		if key.dark {
+			return color.Dark
+		}
+		return color.Light
+	}
+
+	entries := chroma.StyleEntries{
+		chroma.Text:                c(t.Foreground),
+		chroma.Error:               c(t.Red),
+		chroma.Comment:             "italic " + c(t.ForegroundDim),
+		chroma.CommentPreproc:      c(t.Mauve),
+		chroma.Keyword:             c(t.Mauve),
+		chroma.KeywordType:         c(t.Yellow),
+		chroma.KeywordConstant:     c(t.Peach),
+		chroma.Name:                c(t.Foreground),
+		chroma.NameFunction:        c(t.Blue),
+		chroma.NameBuiltin:         c(t.Peach),
+		chroma.NameClass:           c(t.Yellow),
+		chroma.NameTag:             c(t.Blue),
+		chroma.NameAttribute:       c(t.Yellow),
+		chroma.NameDecorator:       c(t.Peach),
+		chroma.Literal:             c(t.Peach),
+		chroma.LiteralString:       c(t.Green),
+		chroma.LiteralStringEscape: c(t.Peach),
+		chroma.LiteralNumber:       c(t.Peach),
+		chroma.Operator:            c(t.ForegroundMid),
+		chroma.Punctuation:         c(t.ForegroundMid),
+		chroma.GenericDeleted:      c(t.Red),
+		chroma.GenericInserted:     c(t.Green),
+		chroma.GenericHeading:      "bold " + c(t.Primary),
+		chroma.GenericSubheading:   "bold " + c(t.Primary),
+		chroma.GenericEmph:         "italic",
+		chroma.GenericStrong:       "bold",
+		chroma.Generic:             c(t.Foreground),
+		chroma.Other:               c(t.Foreground),
+	}
+	style, err := chroma.NewStyle("opencode-"+t.Name, entries)
+	if err != nil {
+		return chromastyles.Fallback
+	}
+	if key.bg == "" {
+		return style
+	}
+	// Terminal formatters drop the Background entry, every token carries
+	// the background instead
+	bg := chroma.ParseColour(key.bg)
+	withBg, err := style.Builder().Transform(func(entry chroma.StyleEntry) chroma.StyleEntry {
+		entry.Background = bg
+		return entry
+	}).Build()
+	if err != nil {
+		return style
+	}
+	return withBg
+}
+
+// hex returns the color as #rrggbb, empty without a color. Adaptive
+// colors take the variant of the background.
+func hex(color lipgloss.TerminalColor, dark bool) string {
+	switch c := color.(type) {
+	case nil, lipgloss.NoColor:
+		return ""
+	case lipgloss.Color:
+		if strings.HasPrefix(string(c), "#") {
+			return string(c)
+		}
+	case lipgloss.AdaptiveColor:
+		if dark {
+			return hex(lipgloss.Color(c.Dark), dark)
+		}
+		return hex(lipgloss.Color(c.Light), dark)
+	}
+	r, g, b, _ := color.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
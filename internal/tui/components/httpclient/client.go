@@ -0,0 +1,502 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/logging"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	// maxResponseSize bounds how much of a response body is read
+	maxResponseSize = 1 << 20
+)
+
+var methods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodHead,
+	http.MethodOptions,
+}
+
+// field identifies the focused input
+type field int
+
+const (
+	fieldMethod field = iota
+	fieldURL
+	fieldHeaders
+	fieldBody
+	fieldCount
+)
+
+// ResponseMsg carries the result of a sent request
+type ResponseMsg struct {
+	entry    HistoryEntry
+	response string
+}
+
+// HTTPClient is a small REST client for composing and sending requests
+type HTTPClient struct {
+	width  int
+	height int
+
+	method  int
+	url     textinput.Model
+	headers textarea.Model
+	body    textarea.Model
+
+	focus   field
+	editing bool
+
+	response viewport.Model
+
+	sessionID     string
+	history       []HistoryEntry
+	showHistory   bool
+	historyCursor int
+}
+
+// NewHTTPClient creates a new HTTP client tool
+func NewHTTPClient() *HTTPClient {
+	url := textinput.New()
+	url.Prompt = ""
+	url.Placeholder = "https://api.example.com/resource"
+
+	headers := textarea.New()
+	headers.Placeholder = "Content-Type: application/json"
+	headers.ShowLineNumbers = false
+	headers.SetHeight(3)
+
+	body := textarea.New()
+	body.Placeholder = "request body"
+	body.ShowLineNumbers = false
+	body.SetHeight(5)
+
+	return &HTTPClient{
+		url:      url,
+		headers:  headers,
+		body:     body,
+		focus:    fieldURL,
+		response: viewport.New(80, 10),
+	}
+}
+
+// Capturing returns whether keys are being typed into a field, in which
+// case keys like q and esc must not close the tool
+func (m *HTTPClient) Capturing() bool {
+	return m.editing
+}
+
+// Focus enters editing mode on the URL field
+func (m *HTTPClient) Focus() tea.Cmd {
+	m.editing = true
+	return m.setFocus(fieldURL)
+}
+
+// SetSession switches the request history to the given session
+func (m *HTTPClient) SetSession(sessionID string) {
+	if sessionID == m.sessionID {
+		return
+	}
+	m.sessionID = sessionID
+	m.historyCursor = 0
+	history, err := loadHistory(sessionID)
+	if err != nil {
+		logging.Warn("Failed to load HTTP history", "session", sessionID, "error", err)
+	}
+	m.history = history
+}
+
+// Init implements tea.Model
+func (m *HTTPClient) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *HTTPClient) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ResponseMsg:
+		m.addHistory(msg.entry)
+		m.response.SetContent(msg.response)
+		m.response.GotoTop()
+		return m, nil
+	case tea.KeyMsg:
+		if m.editing {
+			return m, m.updateEditing(msg)
+		}
+		if m.showHistory {
+			return m, m.updateHistory(msg)
+		}
+
+		switch msg.String() {
+		case "e", "i", "tab":
+			m.editing = true
+			return m, m.setFocus(m.focus)
+		case "enter", "ctrl+s":
+			return m, m.send()
+		case "H":
+			if len(m.history) > 0 {
+				m.showHistory = true
+			}
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.response, cmd = m.response.Update(msg)
+		return m, cmd
+	case tea.MouseMsg:
+		var cmd tea.Cmd
+		m.response, cmd = m.response.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *HTTPClient) updateEditing(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.editing = false
+		m.blurAll()
+		return nil
+	case "ctrl+s":
+		m.editing = false
+		m.blurAll()
+		return m.send()
+	case "tab":
+		return m.setFocus((m.focus + 1) % fieldCount)
+	case "shift+tab":
+		return m.setFocus((m.focus + fieldCount - 1) % fieldCount)
+	}
+
+	var cmd tea.Cmd
+	switch m.focus {
+	case fieldMethod:
+		switch msg.String() {
+		case "left", "h", "up", "k":
+			m.method = (m.method + len(methods) - 1) % len(methods)
+		case "right", "l", "down", "j", " ":
+			m.method = (m.method + 1) % len(methods)
+		case "enter":
+			m.editing = false
+			m.blurAll()
+			return m.send()
+		}
+	case fieldURL:
+		if msg.String() == "enter" {
+			m.editing = false
+			m.blurAll()
+			return m.send()
+		}
+		m.url, cmd = m.url.Update(msg)
+	case fieldHeaders:
+		m.headers, cmd = m.headers.Update(msg)
+	case fieldBody:
+		m.body, cmd = m.body.Update(msg)
+	}
+	return cmd
+}
+
+func (m *HTTPClient) updateHistory(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "H":
+		m.showHistory = false
+	case "up", "k":
+		if m.historyCursor > 0 {
+			m.historyCursor--
+		}
+	case "down", "j":
+		if m.historyCursor < len(m.history)-1 {
+			m.historyCursor++
+		}
+	case "enter":
+		m.load(m.history[m.historyCursor])
+		m.showHistory = false
+	}
+	return nil
+}
+
+func (m *HTTPClient) setFocus(f field) tea.Cmd {
+	m.blurAll()
+	m.focus = f
+	switch f {
+	case fieldURL:
+		return m.url.Focus()
+	case fieldHeaders:
+		return m.headers.Focus()
+	case fieldBody:
+		return m.body.Focus()
+	}
+	return nil
+}
+
+func (m *HTTPClient) blurAll() {
+	m.url.Blur()
+	m.headers.Blur()
+	m.body.Blur()
+}
+
+// load fills the form from a previous request
+func (m *HTTPClient) load(entry HistoryEntry) {
+	for i, method := range methods {
+		if method == entry.Method {
+			m.method = i
+		}
+	}
+	m.url.SetValue(entry.URL)
+	m.headers.SetValue(entry.Headers)
+	m.body.SetValue(entry.Body)
+}
+
+func (m *HTTPClient) addHistory(entry HistoryEntry) {
+	m.history = append([]HistoryEntry{entry}, m.history...)
+	if len(m.history) > maxHistory {
+		m.history = m.history[:maxHistory]
+	}
+	m.historyCursor = 0
+	if err := saveHistory(m.sessionID, m.history); err != nil {
+		logging.Warn("Failed to save HTTP history", "session", m.sessionID, "error", err)
+	}
+}
+
+// send builds the request from the form and performs it in a command
+func (m *HTTPClient) send() tea.Cmd {
+	entry := HistoryEntry{
+		Method:  methods[m.method],
+		URL:     strings.TrimSpace(m.url.Value()),
+		Headers: m.headers.Value(),
+		Body:    m.body.Value(),
+	}
+	if entry.URL == "" {
+		m.response.SetContent(styles.BaseStyle.Foreground(styles.Error).Render("URL is required"))
+		return nil
+	}
+	if !strings.Contains(entry.URL, "://") {
+		entry.URL = "http://" + entry.URL
+	}
+
+	m.response.SetContent(styles.BaseStyle.Foreground(styles.ForgroundDim).Render("Sending..."))
+	return func() tea.Msg {
+		return doRequest(entry)
+	}
+}
+
+func doRequest(entry HistoryEntry) ResponseMsg {
+	entry.SentAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	var body io.Reader
+	if entry.Body != "" {
+		body = strings.NewReader(entry.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, entry.Method, entry.URL, body)
+	if err != nil {
+		return failed(entry, err)
+	}
+	headers, err := parseHeaders(entry.Headers)
+	if err != nil {
+		return failed(entry, err)
+	}
+	for name, values := range headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	entry.Duration = time.Since(entry.SentAt)
+	if err != nil {
+		return failed(entry, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return failed(entry, err)
+	}
+	entry.Status = resp.StatusCode
+
+	return ResponseMsg{entry: entry, response: formatResponse(resp, data, entry.Duration)}
+}
+
+func failed(entry HistoryEntry, err error) ResponseMsg {
+	entry.Error = err.Error()
+	if entry.Duration == 0 {
+		entry.Duration = time.Since(entry.SentAt)
+	}
+	return ResponseMsg{
+		entry:    entry,
+		response: styles.BaseStyle.Foreground(styles.Error).Render("Request failed: " + err.Error()),
+	}
+}
+
+// parseHeaders reads one "Name: value" header per line
+func parseHeaders(text string) (http.Header, error) {
+	headers := http.Header{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header line: %q", line)
+		}
+		headers.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return headers, nil
+}
+
+// formatResponse renders the status line, headers and body, indenting JSON
+// bodies
+func formatResponse(resp *http.Response, data []byte, duration time.Duration) string {
+	statusColor := styles.Green
+	switch {
+	case resp.StatusCode >= 500:
+		statusColor = styles.Error
+	case resp.StatusCode >= 400:
+		statusColor = styles.Warning
+	case resp.StatusCode >= 300:
+		statusColor = styles.Blue
+	}
+
+	var sb strings.Builder
+	sb.WriteString(styles.BaseStyle.Bold(true).Foreground(statusColor).Render(resp.Status))
+	sb.WriteString(styles.BaseStyle.Foreground(styles.ForgroundDim).Render(
+		fmt.Sprintf("  %s • %d bytes", duration.Round(time.Millisecond), len(data)),
+	))
+	sb.WriteString("\n\n")
+
+	names := make([]string, 0, len(resp.Header))
+	for name := range resp.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sb.WriteString(styles.BaseStyle.Foreground(styles.ForgroundMid).Render(
+			fmt.Sprintf("%s: %s", name, strings.Join(resp.Header[name], ", ")),
+		))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	var pretty bytes.Buffer
+	if json.Valid(data) && json.Indent(&pretty, data, "", "  ") == nil {
+		sb.WriteString(pretty.String())
+	} else {
+		sb.WriteString(string(data))
+	}
+	return sb.String()
+}
+
+// View implements tea.Model
+func (m *HTTPClient) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("HTTP Client")
+
+	if m.showHistory {
+		return lipgloss.JoinVertical(lipgloss.Left, title, m.historyView())
+	}
+
+	label := func(f field, text string) string {
+		style := styles.BaseStyle.Foreground(styles.ForgroundDim)
+		if m.editing && m.focus == f {
+			style = styles.BaseStyle.Foreground(styles.PrimaryColor).Bold(true)
+		}
+		return style.Render(text)
+	}
+
+	method := styles.BaseStyle.Bold(true).Foreground(styles.Forground).Render("◀ " + methods[m.method] + " ▶")
+	requestLine := lipgloss.JoinHorizontal(
+		lipgloss.Left,
+		label(fieldMethod, "Method "),
+		method,
+		"  ",
+		label(fieldURL, "URL "),
+		m.url.View(),
+	)
+
+	var help string
+	if m.editing {
+		help = "tab: next field • ←/→: method • ctrl+s: send • esc: done editing"
+	} else {
+		help = "e: edit • enter: send • H: history • ↑/↓: scroll • q/esc: close"
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help),
+		"",
+		requestLine,
+		label(fieldHeaders, "Headers"),
+		m.headers.View(),
+		label(fieldBody, "Body"),
+		m.body.View(),
+		"",
+		m.response.View(),
+	)
+}
+
+func (m *HTTPClient) historyView() string {
+	lines := []string{
+		styles.BaseStyle.Foreground(styles.ForgroundDim).Render("↑/↓: select • enter: load • esc: back"),
+		"",
+	}
+	for i, entry := range m.history {
+		result := fmt.Sprintf("%d", entry.Status)
+		if entry.Error != "" {
+			result = "ERR"
+		}
+		line := fmt.Sprintf("%-7s %-3s %6s  %s",
+			entry.Method, result, entry.Duration.Round(time.Millisecond), entry.URL)
+		style := styles.BaseStyle.Foreground(styles.Forground)
+		if i == m.historyCursor {
+			style = styles.BaseStyle.Foreground(styles.PrimaryColor).Bold(true)
+			line = "> " + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, style.Render(line))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// SetSize sets the size of the client
+func (m *HTTPClient) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	m.url.Width = width - 30
+	m.headers.SetWidth(width)
+	m.body.SetWidth(width)
+
+	// Title, help, blank, request line, two labels, inputs and a blank line
+	responseHeight := height - 7 - m.headers.Height() - m.body.Height()
+	if responseHeight < 3 {
+		responseHeight = 3
+	}
+	m.response.Width = width
+	m.response.Height = responseHeight
+}
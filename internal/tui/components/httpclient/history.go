@@ -0,0 +1,68 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/opencode-ai/opencode/internal/config"
+)
+
+// maxHistory bounds the number of requests remembered per session
+const maxHistory = 50
+
+// HistoryEntry is a request sent from the tool together with a summary of
+// its response
+type HistoryEntry struct {
+	Method   string        `json:"method"`
+	URL      string        `json:"url"`
+	Headers  string        `json:"headers,omitempty"`
+	Body     string        `json:"body,omitempty"`
+	Status   int           `json:"status,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+	SentAt   time.Time     `json:"sentAt"`
+}
+
+// historyPath returns where the history of a session is stored
+func historyPath(sessionID string) string {
+	return filepath.Join(config.Get().Data.Directory, "http", sessionID+".json")
+}
+
+// loadHistory reads the saved requests of a session, newest first
+func loadHistory(sessionID string) ([]HistoryEntry, error) {
+	if sessionID == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(historyPath(sessionID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []HistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// saveHistory writes the requests of a session. Requests made without a
+// session are only kept in memory.
+func saveHistory(sessionID string, entries []HistoryEntry) error {
+	if sessionID == "" {
+		return nil
+	}
+	path := historyPath(sessionID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,490 @@
+package inspector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// mode is what the inspector is currently showing
+type mode int
+
+const (
+	modeTree mode = iota
+	modePaste
+	modeOpen
+	modeText
+)
+
+// Inspector loads JSON or YAML documents and shows them as a collapsible
+// tree
+type Inspector struct {
+	width  int
+	height int
+
+	mode mode
+
+	pasteInput textarea.Model
+	pathInput  textinput.Model
+	text       viewport.Model
+
+	source string
+	value  any
+	format Format
+	root   *node
+	cursor int
+	offset int
+
+	// textFormat is the format shown in text mode
+	textFormat Format
+
+	err error
+}
+
+// NewInspector creates a new JSON/YAML inspector
+func NewInspector() *Inspector {
+	pasteInput := textarea.New()
+	pasteInput.Placeholder = "Paste JSON or YAML, then press ctrl+s"
+	pasteInput.ShowLineNumbers = true
+	pasteInput.CharLimit = 0
+
+	pathInput := textinput.New()
+	pathInput.Prompt = "File: "
+	pathInput.Placeholder = "path to a .json or .yaml file"
+
+	return &Inspector{
+		mode:       modePaste,
+		pasteInput: pasteInput,
+		pathInput:  pathInput,
+		text:       viewport.New(80, 20),
+	}
+}
+
+// Open prepares the inspector when the tool is selected
+func (m *Inspector) Open() tea.Cmd {
+	if m.root == nil {
+		m.mode = modePaste
+		return m.pasteInput.Focus()
+	}
+	return nil
+}
+
+// Capturing returns whether keys are being typed into an input, in which
+// case keys like q and esc must not close the tool
+func (m *Inspector) Capturing() bool {
+	return m.mode == modePaste || m.mode == modeOpen
+}
+
+// Init implements tea.Model
+func (m *Inspector) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *Inspector) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if mouseMsg, ok := msg.(tea.MouseMsg); ok {
+		return m, m.updateMouse(mouseMsg)
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch m.mode {
+	case modePaste:
+		return m, m.updatePaste(keyMsg)
+	case modeOpen:
+		return m, m.updateOpen(keyMsg)
+	case modeText:
+		return m, m.updateText(keyMsg)
+	}
+	return m, m.updateTree(keyMsg)
+}
+
+func (m *Inspector) updatePaste(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		if m.root != nil {
+			m.mode = modeTree
+			m.pasteInput.Blur()
+		}
+		return nil
+	case "ctrl+s":
+		m.pasteInput.Blur()
+		m.load(m.pasteInput.Value())
+		if m.err != nil {
+			return m.pasteInput.Focus()
+		}
+		return nil
+	case "ctrl+o":
+		m.pasteInput.Blur()
+		m.mode = modeOpen
+		return m.pathInput.Focus()
+	}
+	var cmd tea.Cmd
+	m.pasteInput, cmd = m.pasteInput.Update(msg)
+	return cmd
+}
+
+func (m *Inspector) updateOpen(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.pathInput.Blur()
+		if m.root != nil {
+			m.mode = modeTree
+			return nil
+		}
+		m.mode = modePaste
+		return m.pasteInput.Focus()
+	case "enter":
+		path := strings.TrimSpace(m.pathInput.Value())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		m.pathInput.Blur()
+		m.load(string(data))
+		if m.err != nil {
+			m.mode = modePaste
+			m.pasteInput.SetValue(string(data))
+			return m.pasteInput.Focus()
+		}
+		m.source = filepath.Base(path)
+		return nil
+	}
+	var cmd tea.Cmd
+	m.pathInput, cmd = m.pathInput.Update(msg)
+	return cmd
+}
+
+func (m *Inspector) updateText(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "t", "backspace":
+		m.mode = modeTree
+		return nil
+	case "c":
+		return m.showText(other(m.textFormat))
+	case "y", "Y":
+		text, err := encode(m.value, m.textFormat)
+		if err != nil {
+			return util.ReportError(err)
+		}
+		return copyToClipboard(text, strings.ToUpper(string(m.textFormat)))
+	}
+	var cmd tea.Cmd
+	m.text, cmd = m.text.Update(msg)
+	return cmd
+}
+
+func (m *Inspector) updateTree(msg tea.KeyMsg) tea.Cmd {
+	if m.root == nil {
+		return nil
+	}
+	nodes := visible(m.root)
+	current := nodes[m.cursor]
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(nodes)-1 {
+			m.cursor++
+		}
+	case "home", "g":
+		m.cursor = 0
+	case "end", "G":
+		m.cursor = len(nodes) - 1
+	case "enter", " ":
+		if current.isContainer() {
+			current.expanded = !current.expanded
+		}
+	case "right", "l":
+		if current.isContainer() {
+			current.expanded = true
+		}
+	case "left", "h":
+		if current.expanded && current.isContainer() && current.depth > 0 {
+			current.expanded = false
+		} else {
+			m.cursor = m.parentIndex(nodes, m.cursor)
+		}
+	case "E":
+		setExpanded(m.root, true)
+	case "C":
+		setExpanded(m.root, false)
+		m.root.expanded = true
+		m.cursor = 0
+	case "y":
+		path := current.path
+		if path == "" {
+			path = "."
+		}
+		return copyToClipboard(path, path)
+	case "f":
+		return m.showText(m.format)
+	case "c":
+		return m.showText(other(m.format))
+	case "p":
+		m.mode = modePaste
+		return m.pasteInput.Focus()
+	case "o":
+		m.mode = modeOpen
+		return m.pathInput.Focus()
+	}
+	m.ensureVisible()
+	return nil
+}
+
+// updateMouse scrolls the text view or moves the tree cursor with the wheel
+func (m *Inspector) updateMouse(msg tea.MouseMsg) tea.Cmd {
+	switch m.mode {
+	case modeText:
+		var cmd tea.Cmd
+		m.text, cmd = m.text.Update(msg)
+		return cmd
+	case modeTree:
+		if m.root == nil {
+			return nil
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case tea.MouseButtonWheelDown:
+			if m.cursor < len(visible(m.root))-1 {
+				m.cursor++
+			}
+		}
+		m.ensureVisible()
+	}
+	return nil
+}
+
+// Load shows a JSON or YAML document, labelled with source in the title
+func (m *Inspector) Load(text, source string) error {
+	m.load(text)
+	if m.err != nil {
+		return m.err
+	}
+	m.source = source
+	return nil
+}
+
+// load parses a document and shows its tree, or keeps the previous document
+// and records the syntax error
+func (m *Inspector) load(text string) {
+	value, format, err := parse(text)
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.err = nil
+	m.value = value
+	m.format = format
+	m.root = buildTree(value)
+	m.cursor = 0
+	m.offset = 0
+	m.source = "pasted " + strings.ToUpper(string(format))
+	m.mode = modeTree
+}
+
+// showText switches to a formatted text rendering in the given format
+func (m *Inspector) showText(format Format) tea.Cmd {
+	text, err := encode(m.value, format)
+	if err != nil {
+		return util.ReportError(err)
+	}
+	m.textFormat = format
+	m.text.SetContent(text)
+	m.text.GotoTop()
+	m.mode = modeText
+	return nil
+}
+
+func (m *Inspector) parentIndex(nodes []*node, index int) int {
+	depth := nodes[index].depth
+	for i := index - 1; i >= 0; i-- {
+		if nodes[i].depth < depth {
+			return i
+		}
+	}
+	return index
+}
+
+// ensureVisible scrolls the tree so the cursor stays on screen
+func (m *Inspector) ensureVisible() {
+	height := m.treeHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+}
+
+func (m *Inspector) treeHeight() int {
+	// Title, status, help, a blank line and the path footer
+	height := m.height - 6
+	if height < 1 {
+		height = 1
+	}
+	return height
+}
+
+// View implements tea.Model
+func (m *Inspector) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("JSON/YAML Inspector")
+	if m.source != "" {
+		title = lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			title,
+			styles.BaseStyle.Foreground(styles.ForgroundMid).Render("  "+m.source),
+		)
+	}
+
+	status := styles.BaseStyle.Foreground(styles.Green).Render("✓ valid " + string(m.format))
+	if m.err != nil {
+		status = styles.BaseStyle.Foreground(styles.Error).Render("✗ " + m.err.Error())
+	} else if m.root == nil {
+		status = ""
+	}
+
+	var help, body string
+	switch m.mode {
+	case modePaste:
+		help = "ctrl+s: parse • ctrl+o: open file • esc: back to tree"
+		body = m.pasteInput.View()
+	case modeOpen:
+		help = "enter: open • esc: cancel"
+		body = m.pathInput.View()
+	case modeText:
+		help = fmt.Sprintf("c: convert • y: copy %s • t: tree • ↑/↓: scroll", m.textFormat)
+		body = m.text.View()
+	default:
+		help = "enter: toggle • E/C: expand/collapse all • y: copy path • f: format • c: convert • p: paste • o: open"
+		body = m.treeView()
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		status,
+		styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help),
+		"",
+		body,
+	)
+}
+
+func (m *Inspector) treeView() string {
+	if m.root == nil {
+		return ""
+	}
+	nodes := visible(m.root)
+	end := m.offset + m.treeHeight()
+	if end > len(nodes) {
+		end = len(nodes)
+	}
+
+	lines := make([]string, 0, end-m.offset)
+	for i := m.offset; i < end; i++ {
+		n := nodes[i]
+		marker := "  "
+		if n.isContainer() {
+			marker = "▸ "
+			if n.expanded {
+				marker = "▾ "
+			}
+		}
+
+		key := styles.BaseStyle.Foreground(styles.Blue).Render(n.key)
+		value := styles.BaseStyle.Foreground(valueColor(n.value)).Render(summary(n))
+		line := strings.Repeat("  ", n.depth) + marker + key + styles.BaseStyle.Render(": ") + value
+
+		if i == m.cursor {
+			line = styles.BaseStyle.
+				Background(styles.BackgroundDim).
+				Width(m.width).
+				Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(nodes) > 0 {
+		path := nodes[m.cursor].path
+		if path == "" {
+			path = "."
+		}
+		lines = append(lines, "", styles.BaseStyle.Foreground(styles.ForgroundDim).Render(path))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// SetSize sets the size of the inspector
+func (m *Inspector) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	bodyHeight := height - 4
+	if bodyHeight < 3 {
+		bodyHeight = 3
+	}
+	m.pasteInput.SetWidth(width)
+	m.pasteInput.SetHeight(bodyHeight)
+	m.pathInput.Width = width - len(m.pathInput.Prompt) - 1
+	m.text.Width = width
+	m.text.Height = bodyHeight
+	m.ensureVisible()
+}
+
+func valueColor(value any) lipgloss.AdaptiveColor {
+	switch value.(type) {
+	case string:
+		return styles.Green
+	case nil:
+		return styles.ForgroundDim
+	case map[string]any, []any:
+		return styles.ForgroundMid
+	}
+	return styles.Peach
+}
+
+func setExpanded(n *node, expanded bool) {
+	if n.isContainer() {
+		n.expanded = expanded
+	}
+	for _, child := range n.children {
+		setExpanded(child, expanded)
+	}
+}
+
+func other(format Format) Format {
+	if format == FormatJSON {
+		return FormatYAML
+	}
+	return FormatJSON
+}
+
+func copyToClipboard(text, what string) tea.Cmd {
+	if err := clipboard.WriteAll(text); err != nil {
+		return util.ReportError(fmt.Errorf("failed to copy to clipboard: %w", err))
+	}
+	return util.ReportInfo("Copied " + what)
+}
@@ -0,0 +1,246 @@
+package inspector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a structured document format
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// node is an entry in the document tree
+type node struct {
+	key      string
+	path     string
+	value    any
+	children []*node
+	expanded bool
+	depth    int
+}
+
+func (n *node) isContainer() bool {
+	switch n.value.(type) {
+	case map[string]any, []any:
+		return true
+	}
+	return false
+}
+
+// parse decodes a JSON or YAML document, detecting the format from content
+func parse(text string) (any, Format, error) {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return nil, "", fmt.Errorf("document is empty")
+	}
+
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		decoder := json.NewDecoder(strings.NewReader(trimmed))
+		decoder.UseNumber()
+		var value any
+		err := decoder.Decode(&value)
+		if err == nil {
+			return value, FormatJSON, nil
+		}
+		// Flow style YAML also starts with a brace, only report the JSON
+		// error if YAML cannot make sense of it either
+		if yamlValue, yamlErr := parseYAML(trimmed); yamlErr == nil {
+			return yamlValue, FormatYAML, nil
+		}
+		return nil, FormatJSON, jsonError(trimmed, err)
+	}
+
+	value, err := parseYAML(text)
+	if err != nil {
+		return nil, FormatYAML, err
+	}
+	return value, FormatYAML, nil
+}
+
+func parseYAML(text string) (any, error) {
+	var value any
+	if err := yaml.Unmarshal([]byte(text), &value); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	return normalize(value), nil
+}
+
+// normalize converts YAML maps with non string keys so documents from both
+// formats share the same representation
+func normalize(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			v[key] = normalize(child)
+		}
+		return v
+	case map[any]any:
+		out := make(map[string]any, len(v))
+		for key, child := range v {
+			out[fmt.Sprint(key)] = normalize(child)
+		}
+		return out
+	case []any:
+		for i, child := range v {
+			v[i] = normalize(child)
+		}
+		return v
+	}
+	return value
+}
+
+// jsonError adds the line and column to JSON syntax errors
+func jsonError(text string, err error) error {
+	if syntaxErr, ok := err.(*json.SyntaxError); ok {
+		line, col := position(text, syntaxErr.Offset)
+		return fmt.Errorf("invalid JSON at line %d, column %d: %w", line, col, err)
+	}
+	return fmt.Errorf("invalid JSON: %w", err)
+}
+
+func position(text string, offset int64) (int, int) {
+	if offset > int64(len(text)) {
+		offset = int64(len(text))
+	}
+	before := text[:offset]
+	line := strings.Count(before, "\n") + 1
+	col := int(offset) - strings.LastIndex(before, "\n")
+	return line, col
+}
+
+// encode renders a document in the given format
+func encode(value any, format Format) (string, error) {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case FormatYAML:
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(jsonNumbers(value)); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(buf.String(), "\n"), nil
+	}
+	return "", fmt.Errorf("unknown format: %s", format)
+}
+
+// jsonNumbers replaces json.Number values so YAML encodes them as numbers
+// instead of strings
+func jsonNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, child := range v {
+			out[key] = jsonNumbers(child)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, child := range v {
+			out[i] = jsonNumbers(child)
+		}
+		return out
+	}
+	return value
+}
+
+// buildTree creates the node tree for a document. The root and first level
+// are expanded.
+func buildTree(value any) *node {
+	root := &node{key: "$", value: value, expanded: true}
+	addChildren(root)
+	for _, child := range root.children {
+		child.expanded = child.depth < 1
+	}
+	return root
+}
+
+func addChildren(n *node) {
+	switch v := n.value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := &node{key: key, path: n.path + keySegment(key), value: v[key], depth: n.depth + 1}
+			addChildren(child)
+			n.children = append(n.children, child)
+		}
+	case []any:
+		for i, item := range v {
+			child := &node{key: fmt.Sprintf("[%d]", i), path: fmt.Sprintf("%s[%d]", n.path, i), value: item, depth: n.depth + 1}
+			addChildren(child)
+			n.children = append(n.children, child)
+		}
+	}
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// keySegment formats a map key as a path segment, quoting keys that are not
+// plain identifiers
+func keySegment(key string) string {
+	if identifier.MatchString(key) {
+		return "." + key
+	}
+	quoted, _ := json.Marshal(key)
+	return "[" + string(quoted) + "]"
+}
+
+// visible flattens the expanded part of the tree in display order
+func visible(root *node) []*node {
+	var nodes []*node
+	var walk func(n *node)
+	walk = func(n *node) {
+		nodes = append(nodes, n)
+		if !n.expanded {
+			return
+		}
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	walk(root)
+	return nodes
+}
+
+// summary describes a node value on a single line
+func summary(n *node) string {
+	switch v := n.value.(type) {
+	case map[string]any:
+		return fmt.Sprintf("{%d}", len(v))
+	case []any:
+		return fmt.Sprintf("[%d]", len(v))
+	case string:
+		quoted, _ := json.Marshal(v)
+		return string(quoted)
+	case nil:
+		return "null"
+	}
+	return fmt.Sprint(n.value)
+}
@@ -0,0 +1,500 @@
+package logviewer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/swarm/monitor"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+)
+
+const (
+	// maxLines bounds the number of lines kept in memory
+	maxLines = 5000
+	// initialLines is how much of the existing file is shown when opened
+	initialLines = 200
+	// maxBatch bounds how many entries are delivered in a single message
+	maxBatch = 500
+)
+
+// LogLinesMsg delivers new entries from the watched file
+type LogLinesMsg struct {
+	generation int
+	Entries    []monitor.LogEntry
+}
+
+// logClosedMsg reports that the watcher stopped delivering entries
+type logClosedMsg struct {
+	generation int
+}
+
+// LogViewer tails a log file, coloring lines by level
+type LogViewer struct {
+	viewport viewport.Model
+	width    int
+	height   int
+
+	path    string
+	watcher *monitor.LogWatcher
+	// generation increases every time a file is opened so stale wait
+	// commands from a previous file or a previous visit do not start
+	// duplicate read loops
+	generation int
+
+	lines   []string
+	pending []string
+	paused  bool
+	follow  bool
+
+	pathInput   textinput.Model
+	choosing    bool
+	filterInput textinput.Model
+	filtering   bool
+	filter      *regexp.Regexp
+
+	err error
+}
+
+// NewLogViewer creates a new log viewer
+func NewLogViewer() *LogViewer {
+	pathInput := textinput.New()
+	pathInput.Prompt = "File: "
+	pathInput.Placeholder = "path to a log file"
+
+	filterInput := textinput.New()
+	filterInput.Prompt = "/"
+	filterInput.Placeholder = "regular expression"
+
+	return &LogViewer{
+		viewport:    viewport.New(80, 20),
+		follow:      true,
+		pathInput:   pathInput,
+		filterInput: filterInput,
+	}
+}
+
+// Open asks for a file to tail. If a file is already open the read loop is
+// resumed instead.
+func (m *LogViewer) Open() tea.Cmd {
+	if m.watcher != nil {
+		m.generation++
+		return m.waitForEntries()
+	}
+	m.choosing = true
+	m.err = nil
+	return m.pathInput.Focus()
+}
+
+// Capturing returns whether the viewer is reading text input, in which case
+// keys like q and esc must not close the tool
+func (m *LogViewer) Capturing() bool {
+	return m.choosing || m.filtering
+}
+
+// Close stops tailing the current file
+func (m *LogViewer) Close() {
+	if m.watcher != nil {
+		_ = m.watcher.Stop()
+		m.watcher = nil
+	}
+}
+
+// Init implements tea.Model
+func (m *LogViewer) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *LogViewer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case LogLinesMsg:
+		// Lines of a watcher replaced meanwhile belong to another file
+		if msg.generation != m.generation {
+			return m, nil
+		}
+		m.appendEntries(msg.Entries)
+		return m, m.waitForEntries()
+	case logClosedMsg:
+		return m, nil
+	case tea.KeyMsg:
+		if m.choosing {
+			return m, m.updatePathInput(msg)
+		}
+		if m.filtering {
+			return m, m.updateFilterInput(msg)
+		}
+
+		switch msg.String() {
+		case "o":
+			m.choosing = true
+			m.pathInput.SetValue(m.path)
+			return m, m.pathInput.Focus()
+		case "/":
+			m.filtering = true
+			return m, m.filterInput.Focus()
+		case "p", " ":
+			m.togglePause()
+			return m, nil
+		case "G", "end":
+			m.follow = true
+			m.viewport.GotoBottom()
+			return m, nil
+		case "c":
+			m.lines = nil
+			m.pending = nil
+			m.render()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	m.follow = m.viewport.AtBottom()
+	return m, cmd
+}
+
+// View implements tea.Model
+func (m *LogViewer) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Log Viewer")
+
+	if m.path != "" {
+		title = lipgloss.JoinHorizontal(
+			lipgloss.Left,
+			title,
+			styles.BaseStyle.Foreground(styles.ForgroundMid).Render("  "+m.path),
+		)
+	}
+
+	status := m.statusLine()
+
+	var prompt string
+	switch {
+	case m.choosing:
+		prompt = m.pathInput.View()
+	case m.filtering:
+		prompt = m.filterInput.View()
+	default:
+		prompt = styles.BaseStyle.
+			Foreground(styles.ForgroundDim).
+			Render("o: open • /: filter • p: pause • G: bottom • c: clear • q/esc: close")
+	}
+
+	body := m.viewport.View()
+	if m.path == "" && !m.choosing {
+		body = styles.BaseStyle.Foreground(styles.ForgroundDim).Render("Press o to open a log file")
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		status,
+		prompt,
+		"",
+		body,
+	)
+}
+
+// SetSize sets the size of the viewer
+func (m *LogViewer) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	// Title, status, prompt and a blank line
+	viewportHeight := height - 4
+	if viewportHeight < 1 {
+		viewportHeight = 1
+	}
+	m.viewport.Width = width
+	m.viewport.Height = viewportHeight
+	m.pathInput.Width = width - len(m.pathInput.Prompt) - 1
+	m.filterInput.Width = width - len(m.filterInput.Prompt) - 1
+	m.render()
+}
+
+func (m *LogViewer) statusLine() string {
+	var parts []string
+	if m.err != nil {
+		return styles.BaseStyle.Foreground(styles.Error).Render(m.err.Error())
+	}
+	parts = append(parts, fmt.Sprintf("%d lines", len(m.lines)))
+	if m.paused {
+		parts = append(parts, fmt.Sprintf("paused (%d new)", len(m.pending)))
+	} else if m.follow {
+		parts = append(parts, "following")
+	}
+	if m.filter != nil {
+		parts = append(parts, "filter: "+m.filter.String())
+	}
+	return styles.BaseStyle.Foreground(styles.ForgroundMid).Render(strings.Join(parts, " • "))
+}
+
+func (m *LogViewer) updatePathInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.choosing = false
+		m.pathInput.Blur()
+		return nil
+	case "enter":
+		m.choosing = false
+		m.pathInput.Blur()
+		return m.openFile(strings.TrimSpace(m.pathInput.Value()))
+	}
+	var cmd tea.Cmd
+	m.pathInput, cmd = m.pathInput.Update(msg)
+	return cmd
+}
+
+func (m *LogViewer) updateFilterInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.filtering = false
+		m.filterInput.Blur()
+		m.filterInput.SetValue("")
+		m.filter = nil
+		m.err = nil
+		m.render()
+		return nil
+	case "enter":
+		m.filtering = false
+		m.filterInput.Blur()
+		m.setFilter(m.filterInput.Value())
+		return nil
+	}
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	return cmd
+}
+
+// setFilter compiles the filter expression, keeping the previous filter if
+// it is invalid
+func (m *LogViewer) setFilter(expr string) {
+	m.err = nil
+	if expr == "" {
+		m.filter = nil
+		m.render()
+		return
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		m.err = fmt.Errorf("invalid filter: %w", err)
+		return
+	}
+	m.filter = re
+	m.render()
+}
+
+func (m *LogViewer) togglePause() {
+	m.paused = !m.paused
+	if !m.paused && len(m.pending) > 0 {
+		m.lines = appendBounded(m.lines, m.pending...)
+		m.pending = nil
+		m.render()
+	}
+}
+
+// openFile starts tailing path, replacing any file already open
+func (m *LogViewer) openFile(path string) tea.Cmd {
+	if path == "" {
+		return nil
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	lines, err := readTail(path, initialLines)
+	if err != nil {
+		m.err = err
+		return nil
+	}
+
+	watcher, err := monitor.NewLogWatcher(monitor.LogWatcherConfig{
+		Paths: []string{path},
+	})
+	if err != nil {
+		m.err = err
+		return nil
+	}
+	if err := watcher.Start(); err != nil {
+		_ = watcher.Stop()
+		m.err = err
+		return nil
+	}
+
+	m.Close()
+	m.watcher = watcher
+	m.path = path
+	m.err = nil
+	m.lines = lines
+	m.pending = nil
+	m.paused = false
+	m.follow = true
+	m.generation++
+	m.render()
+
+	return m.waitForEntries()
+}
+
+// waitForEntries blocks until the watcher produces entries, then drains
+// whatever else is already buffered so bursts arrive as one message
+func (m *LogViewer) waitForEntries() tea.Cmd {
+	if m.watcher == nil {
+		return nil
+	}
+	entries := m.watcher.Entries()
+	generation := m.generation
+	return func() tea.Msg {
+		entry, ok := <-entries
+		if !ok {
+			return logClosedMsg{generation: generation}
+		}
+		batch := []monitor.LogEntry{entry}
+		for len(batch) < maxBatch {
+			select {
+			case entry, ok := <-entries:
+				if !ok {
+					return LogLinesMsg{generation: generation, Entries: batch}
+				}
+				batch = append(batch, entry)
+			default:
+				return LogLinesMsg{generation: generation, Entries: batch}
+			}
+		}
+		return LogLinesMsg{generation: generation, Entries: batch}
+	}
+}
+
+func (m *LogViewer) appendEntries(entries []monitor.LogEntry) {
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, entry.Message)
+	}
+
+	if m.paused {
+		m.pending = appendBounded(m.pending, lines...)
+		return
+	}
+	m.lines = appendBounded(m.lines, lines...)
+	m.render()
+}
+
+// render rebuilds the viewport content from the visible lines
+func (m *LogViewer) render() {
+	var sb strings.Builder
+	for _, line := range m.lines {
+		if m.filter != nil && !m.filter.MatchString(line) {
+			continue
+		}
+		sb.WriteString(colorLine(line))
+		sb.WriteByte('\n')
+	}
+	m.viewport.SetContent(strings.TrimSuffix(sb.String(), "\n"))
+	if m.follow {
+		m.viewport.GotoBottom()
+	}
+}
+
+// colorLine styles a line according to the log level it mentions
+func colorLine(line string) string {
+	style := styles.BaseStyle.Foreground(styles.Forground)
+	switch detectLevel(line) {
+	case "error":
+		style = styles.BaseStyle.Foreground(styles.Error)
+	case "warn":
+		style = styles.BaseStyle.Foreground(styles.Warning)
+	case "debug":
+		style = styles.BaseStyle.Foreground(styles.ForgroundDim)
+	}
+	return style.Render(line)
+}
+
+// detectLevel guesses the level of a plain text log line from common
+// level markers
+func detectLevel(line string) string {
+	upper := strings.ToUpper(line)
+	switch {
+	case containsAny(upper, "ERROR", "ERR ", "FATAL", "PANIC", "LEVEL=ERROR"):
+		return "error"
+	case containsAny(upper, "WARN", "LEVEL=WARN"):
+		return "warn"
+	case containsAny(upper, "DEBUG", "TRACE"):
+		return "debug"
+	}
+	return "info"
+}
+
+func containsAny(s string, substrs ...string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func appendBounded(lines []string, more ...string) []string {
+	lines = append(lines, more...)
+	if len(lines) > maxLines {
+		lines = append([]string(nil), lines[len(lines)-maxLines:]...)
+	}
+	return lines
+}
+
+// readTail returns up to n lines from the end of the file
+func readTail(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	// Lines are rarely longer than 512 bytes, read just enough of the end
+	size := info.Size()
+	chunk := int64(n * 512)
+	offset := size - chunk
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(file, size-offset))
+	if err != nil {
+		return nil, err
+	}
+	// Drop the first line when starting mid file since it is likely partial
+	if offset > 0 {
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+
+	text := strings.TrimRight(string(data), "\n")
+	if text == "" {
+		return nil, nil
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
@@ -0,0 +1,37 @@
+package logviewer
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/opencode-ai/opencode/internal/swarm/monitor"
+)
+
+func TestLogLines(t *testing.T) {
+	tests := []struct {
+		name        string
+		generation  int
+		paused      bool
+		wantLines   []string
+		wantPending []string
+	}{
+		{name: "current file", generation: 2, wantLines: []string{"old", "new"}},
+		{name: "replaced file", generation: 1, wantLines: []string{"old"}},
+		{name: "file of a later visit", generation: 3, wantLines: []string{"old"}},
+		{name: "paused", generation: 2, paused: true, wantLines: []string{"old"}, wantPending: []string{"new"}},
+		{name: "paused on a replaced file", generation: 1, paused: true, wantLines: []string{"old"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewLogViewer()
+			m.generation = 2
+			m.lines = []string{"old"}
+			m.paused = tt.paused
+
+			m.Update(LogLinesMsg{generation: tt.generation, Entries: []monitor.LogEntry{{Message: "new"}}})
+			if !slices.Equal(m.lines, tt.wantLines) || !slices.Equal(m.pending, tt.wantPending) {
+				t.Errorf("lines %q, pending %q, want %q, %q", m.lines, m.pending, tt.wantLines, tt.wantPending)
+			}
+		})
+	}
+}
@@ -14,6 +14,8 @@ import (
 type MarkdownViewer struct {
 	viewport viewport.Model
 	content  string
+	title    string
+	help     string
 	width    int
 	height   int
 	renderer *glamour.TermRenderer
@@ -21,18 +23,26 @@ type MarkdownViewer struct {
 
 // NewMarkdownViewer creates a new markdown viewer
 func NewMarkdownViewer() *MarkdownViewer {
-	// Create a glamour renderer with a dark theme
-	renderer, _ := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
-		glamour.WithWordWrap(80),
-	)
+	renderer, _ := newRenderer(80)
 
 	return &MarkdownViewer{
 		viewport: viewport.New(80, 20),
 		renderer: renderer,
+		title:    "Markdown Preview",
+		help:     "↑/↓: scroll • q/esc: close",
 	}
 }
 
+// SetTitle sets the title shown above the content, e.g. its file name
+func (m *MarkdownViewer) SetTitle(title string) {
+	m.title = title
+}
+
+// SetHelp sets the key help shown below the title
+func (m *MarkdownViewer) SetHelp(help string) {
+	m.help = help
+}
+
 // SetContent sets the markdown content to be rendered
 func (m *MarkdownViewer) SetContent(content string) error {
 	m.content = content
@@ -44,6 +54,7 @@ func (m *MarkdownViewer) SetContent(content string) error {
 	}
 	
 	m.viewport.SetContent(rendered)
+	m.viewport.GotoTop()
 	return nil
 }
 
@@ -63,6 +74,10 @@ func (m *MarkdownViewer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Close the viewer
 			return m, nil
 		}
+	case styles.ThemeChangedMsg:
+		// Rebuild the renderer so the glamour style follows the theme
+		m.rerender(m.viewport.Width - 4)
+		return m, nil
 	}
 	
 	m.viewport, cmd = m.viewport.Update(msg)
@@ -74,11 +89,11 @@ func (m *MarkdownViewer) View() string {
 	title := styles.BaseStyle.
 		Bold(true).
 		Foreground(styles.PrimaryColor).
-		Render("Markdown Preview")
+		Render(m.title)
 	
 	help := styles.BaseStyle.
 		Foreground(styles.ForgroundDim).
-		Render("↑/↓: scroll • q/esc: close")
+		Render(m.help)
 	
 	header := lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -110,20 +125,43 @@ func (m *MarkdownViewer) SetSize(width, height int) {
 	
 	// Re-render with new width if we have content
 	if m.content != "" {
-		// Update renderer word wrap
-		m.renderer, _ = glamour.NewTermRenderer(
-			glamour.WithAutoStyle(),
-			glamour.WithWordWrap(width-4),
-		)
-		
-		// Re-render content
-		rendered, err := m.renderer.Render(m.content)
-		if err == nil {
-			m.viewport.SetContent(rendered)
-		}
+		m.rerender(width - 4)
 	}
 }
 
+// rerender rebuilds the renderer for the given word wrap and the active
+// theme, then renders the content again
+func (m *MarkdownViewer) rerender(wordWrap int) {
+	if wordWrap < 1 {
+		wordWrap = 80
+	}
+	renderer, err := newRenderer(wordWrap)
+	if err != nil {
+		return
+	}
+	m.renderer = renderer
+	
+	if m.content == "" {
+		return
+	}
+	rendered, err := m.renderer.Render(m.content)
+	if err == nil {
+		m.viewport.SetContent(rendered)
+	}
+}
+
+// newRenderer creates a glamour renderer matching the active theme
+func newRenderer(wordWrap int) (*glamour.TermRenderer, error) {
+	style := "light"
+	if styles.CurrentTheme().IsDark() {
+		style = "dark"
+	}
+	return glamour.NewTermRenderer(
+		glamour.WithStandardStyle(style),
+		glamour.WithWordWrap(wordWrap),
+	)
+}
+
 // GetContent returns the raw markdown content
 func (m *MarkdownViewer) GetContent() string {
 	return m.content
@@ -137,10 +175,7 @@ type MarkdownPreviewMsg struct {
 
 // RenderMarkdown is a helper function to quickly render markdown to a string
 func RenderMarkdown(content string, width int) (string, error) {
-	renderer, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
-		glamour.WithWordWrap(width),
-	)
+	renderer, err := newRenderer(width)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,544 @@
+package memorybrowser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	bubbletable "github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+	"github.com/opencode-ai/opencode/internal/tui/components/inspector"
+	"github.com/opencode-ai/opencode/internal/tui/components/markdown"
+	"github.com/opencode-ai/opencode/internal/tui/components/table"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// types are the memory type filters, cycled with t
+var types = []memory.MemoryType{
+	"",
+	memory.MemoryTypeWorking,
+	memory.MemoryTypeEpisodic,
+	memory.MemoryTypeSemantic,
+	memory.MemoryTypeProcedural,
+}
+
+var priorityNames = map[memory.MemoryPriority]string{
+	memory.PriorityLow:      "low",
+	memory.PriorityNormal:   "normal",
+	memory.PriorityHigh:     "high",
+	memory.PriorityCritical: "critical",
+}
+
+// view is the screen the browser is showing
+type view int
+
+const (
+	viewList view = iota
+	viewMarkdown
+	viewTree
+	viewCode
+)
+
+// input is the text field being edited
+type input int
+
+const (
+	inputNone input = iota
+	inputSearch
+	inputTags
+	inputEditTags
+)
+
+// MemoryBrowser queries a swarm memory store and shows the results
+type MemoryBrowser struct {
+	store  memory.MemoryStore
+	table  *table.DataTable
+	width  int
+	height int
+
+	// Query
+	typeIndex   int
+	searchInput textinput.Model
+	tagsInput   textinput.Model
+
+	// Tag editing of the selected memory
+	editInput textinput.Model
+
+	editing       input
+	confirmDelete bool
+
+	results map[string]memory.Memory
+	err     error
+
+	view     view
+	markdown *markdown.MarkdownViewer
+	tree     *inspector.Inspector
+	code     *codeView
+}
+
+// NewMemoryBrowser creates a browser for the given memory store
+func NewMemoryBrowser(store memory.MemoryStore) *MemoryBrowser {
+	columns := []bubbletable.Column{
+		{Title: "ID", Width: 8},
+		{Title: "Type", Width: 10},
+		{Title: "Priority", Width: 8},
+		{Title: "Tags", Width: 20},
+		{Title: "Content", Width: 40},
+		{Title: "Created", Width: 16},
+		{Title: "Hits", Width: 4},
+	}
+	t := table.NewDataTable(columns, nil)
+
+	searchInput := textinput.New()
+	searchInput.Prompt = "Query: "
+	searchInput.Placeholder = `text, "phrase", type:episodic tag:error after:2024-01-01 priority:high`
+
+	tagsInput := textinput.New()
+	tagsInput.Prompt = "Tags: "
+	tagsInput.Placeholder = "comma separated, any matches"
+
+	editInput := textinput.New()
+	editInput.Prompt = "Set tags: "
+
+	m := &MemoryBrowser{
+		store:       store,
+		table:       t,
+		searchInput: searchInput,
+		tagsInput:   tagsInput,
+		editInput:   editInput,
+		results:     make(map[string]memory.Memory),
+		markdown:    markdown.NewMarkdownViewer(),
+		tree:        inspector.NewInspector(),
+		code:        newCodeView(),
+	}
+	m.query()
+	return m
+}
+
+// Open reruns the current query so the results are fresh
+func (m *MemoryBrowser) Open() tea.Cmd {
+	m.query()
+	return nil
+}
+
+// Capturing returns whether keys go to an input or a content view, in which
+// case q and esc must not close the tool
+func (m *MemoryBrowser) Capturing() bool {
+	return m.editing != inputNone || m.view != viewList || m.table.IsFiltering()
+}
+
+// Init implements tea.Model
+func (m *MemoryBrowser) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *MemoryBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, m.updateContent(msg)
+	}
+
+	if m.editing != inputNone {
+		return m, m.updateInput(keyMsg)
+	}
+
+	if m.view != viewList {
+		if keyMsg.String() == "esc" || (keyMsg.String() == "q" && !m.tree.Capturing()) {
+			m.view = viewList
+			return m, nil
+		}
+		return m, m.updateContent(msg)
+	}
+
+	if m.confirmDelete {
+		m.confirmDelete = false
+		if keyMsg.String() == "y" {
+			return m, m.deleteSelected()
+		}
+		return m, nil
+	}
+
+	if !m.table.IsFiltering() {
+		switch keyMsg.String() {
+		case "f":
+			m.editing = inputSearch
+			return m, m.searchInput.Focus()
+		case "t":
+			m.typeIndex = (m.typeIndex + 1) % len(types)
+			m.query()
+			return m, nil
+		case "r":
+			m.query()
+			return m, nil
+		case "enter":
+			return m, m.showSelected()
+		case "p":
+			return m, m.togglePin()
+		case "T":
+			selected, ok := m.selected()
+			if !ok {
+				return m, nil
+			}
+			m.editing = inputEditTags
+			m.editInput.SetValue(strings.Join(selected.Tags, ", "))
+			m.editInput.CursorEnd()
+			return m, m.editInput.Focus()
+		case "D":
+			if _, ok := m.selected(); ok {
+				m.confirmDelete = true
+			}
+			return m, nil
+		}
+	}
+
+	_, cmd := m.table.Update(msg)
+	return m, cmd
+}
+
+// updateContent forwards a message to the content view being shown
+func (m *MemoryBrowser) updateContent(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	switch m.view {
+	case viewMarkdown:
+		_, cmd = m.markdown.Update(msg)
+	case viewTree:
+		_, cmd = m.tree.Update(msg)
+	case viewCode:
+		cmd = m.code.Update(msg)
+	default:
+		_, cmd = m.table.Update(msg)
+	}
+	return cmd
+}
+
+func (m *MemoryBrowser) updateInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.blurInputs()
+		return nil
+	case "tab", "shift+tab":
+		// Switch between the two query fields
+		switch m.editing {
+		case inputSearch:
+			m.searchInput.Blur()
+			m.editing = inputTags
+			return m.tagsInput.Focus()
+		case inputTags:
+			m.tagsInput.Blur()
+			m.editing = inputSearch
+			return m.searchInput.Focus()
+		}
+		return nil
+	case "enter":
+		editing := m.editing
+		m.blurInputs()
+		if editing == inputEditTags {
+			return m.setTags(splitTags(m.editInput.Value()))
+		}
+		m.query()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	switch m.editing {
+	case inputSearch:
+		m.searchInput, cmd = m.searchInput.Update(msg)
+	case inputTags:
+		m.tagsInput, cmd = m.tagsInput.Update(msg)
+	case inputEditTags:
+		m.editInput, cmd = m.editInput.Update(msg)
+	}
+	return cmd
+}
+
+func (m *MemoryBrowser) blurInputs() {
+	m.editing = inputNone
+	m.searchInput.Blur()
+	m.tagsInput.Blur()
+	m.editInput.Blur()
+}
+
+// query runs the current query and fills the table
+func (m *MemoryBrowser) query() {
+	m.results = make(map[string]memory.Memory)
+	if m.store == nil {
+		m.table.SetRows(nil)
+		return
+	}
+
+	// The type picked with t applies unless the query names one
+	var memories []memory.Memory
+	query, err := memory.ParseQuery(m.searchInput.Value())
+	if err == nil {
+		if query.Type == "" {
+			query.Type = types[m.typeIndex]
+		}
+		query.Tags = append(query.Tags, splitTags(m.tagsInput.Value())...)
+		memories, err = m.store.Query(context.Background(), query)
+	}
+	m.err = err
+	if err != nil {
+		m.table.SetRows(nil)
+		return
+	}
+
+	// Pinned first, then newest first
+	sort.Slice(memories, func(i, j int) bool {
+		pi, pj := memories[i].Pinned, memories[j].Pinned
+		if pi != pj {
+			return pi
+		}
+		return memories[i].CreatedAt.After(memories[j].CreatedAt)
+	})
+
+	rows := make([]bubbletable.Row, 0, len(memories))
+	for _, mem := range memories {
+		m.results[mem.ID] = mem
+		id := mem.ID
+		if mem.Pinned {
+			id = "📌" + id
+		}
+		rows = append(rows, bubbletable.Row{
+			id,
+			string(mem.Type),
+			priorityNames[mem.Priority],
+			strings.Join(mem.Tags, ","),
+			preview(mem),
+			mem.CreatedAt.Format("2006-01-02 15:04"),
+			fmt.Sprintf("%d", mem.AccessCount),
+		})
+	}
+	m.table.SetRows(rows)
+}
+
+// selected returns the memory under the cursor
+func (m *MemoryBrowser) selected() (memory.Memory, bool) {
+	row := m.table.SelectedRow()
+	if row == nil {
+		return memory.Memory{}, false
+	}
+	mem, ok := m.results[strings.TrimPrefix(row[0], "📌")]
+	return mem, ok
+}
+
+// showSelected renders the content of the selected memory, code in its
+// language, other strings as markdown and anything else as a JSON tree
+func (m *MemoryBrowser) showSelected() tea.Cmd {
+	selected, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	mem, err := m.store.Retrieve(context.Background(), selected.ID)
+	if err != nil {
+		return util.ReportError(err)
+	}
+
+	if text, ok := mem.Content.(string); ok {
+		if language, ok := codeLanguage(*mem); ok {
+			m.code.SetContent(string(mem.Type)+" memory "+shortID(mem.ID), text, language)
+			m.view = viewCode
+			return nil
+		}
+		if err := m.markdown.SetContent(text); err != nil {
+			return util.ReportError(err)
+		}
+		m.view = viewMarkdown
+		return nil
+	}
+
+	data, err := json.Marshal(mem.Content)
+	if err != nil {
+		return util.ReportError(fmt.Errorf("cannot render memory content: %w", err))
+	}
+	if err := m.tree.Load(string(data), string(mem.Type)+" memory "+shortID(mem.ID)); err != nil {
+		return util.ReportError(err)
+	}
+	m.view = viewTree
+	return nil
+}
+
+// update changes the selected memory. The memory is retrieved again so
+// encrypted content is stored from its decrypted form.
+func (m *MemoryBrowser) update(change func(*memory.Memory)) error {
+	selected, ok := m.selected()
+	if !ok {
+		return fmt.Errorf("no memory selected")
+	}
+	current, err := m.store.Retrieve(context.Background(), selected.ID)
+	if err != nil {
+		return err
+	}
+	updated := *current
+	updated.Tags = append([]string(nil), current.Tags...)
+	change(&updated)
+	if err := m.store.Update(context.Background(), updated.ID, updated); err != nil {
+		return err
+	}
+	m.query()
+	return nil
+}
+
+// togglePin pins the selected memory, which keeps it from being pruned or
+// consolidated, or unpins it
+func (m *MemoryBrowser) togglePin() tea.Cmd {
+	selected, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	pinned := !selected.Pinned
+	if err := m.store.Pin(context.Background(), selected.ID, pinned); err != nil {
+		return util.ReportError(err)
+	}
+	m.query()
+	if pinned {
+		return util.ReportInfo("Memory pinned")
+	}
+	return util.ReportInfo("Memory unpinned")
+}
+
+func (m *MemoryBrowser) setTags(tags []string) tea.Cmd {
+	err := m.update(func(mem *memory.Memory) {
+		mem.Tags = tags
+	})
+	if err != nil {
+		return util.ReportError(err)
+	}
+	return util.ReportInfo("Tags updated")
+}
+
+func (m *MemoryBrowser) deleteSelected() tea.Cmd {
+	selected, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	if err := m.store.Delete(context.Background(), selected.ID); err != nil {
+		return util.ReportError(err)
+	}
+	m.query()
+	return util.ReportInfo("Memory deleted")
+}
+
+// View implements tea.Model
+func (m *MemoryBrowser) View() string {
+	switch m.view {
+	case viewMarkdown:
+		return m.markdown.View()
+	case viewTree:
+		return m.tree.View()
+	case viewCode:
+		return m.code.View()
+	}
+
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Memory Browser")
+
+	typeName := string(types[m.typeIndex])
+	if typeName == "" {
+		typeName = "all"
+	}
+	status := fmt.Sprintf("%d memories • type: %s", len(m.results), typeName)
+	if m.store == nil {
+		status = "No swarm is running"
+	}
+	statusStyle := styles.BaseStyle.Foreground(styles.ForgroundMid)
+	if m.err != nil {
+		status = "Query failed: " + m.err.Error()
+		statusStyle = styles.BaseStyle.Foreground(styles.Error)
+	}
+
+	help := "f: search • t: type • enter: view • p: pin • T: tags • D: delete • r: refresh"
+	bottom := styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help)
+	switch {
+	case m.editing == inputEditTags:
+		bottom = m.editInput.View()
+	case m.confirmDelete:
+		bottom = styles.BaseStyle.Foreground(styles.Warning).Render("Delete the selected memory? y to confirm")
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		statusStyle.Render(status),
+		m.searchInput.View(),
+		m.tagsInput.View(),
+		bottom,
+		"",
+		m.table.View(),
+	)
+}
+
+// SetSize sets the size of the browser
+func (m *MemoryBrowser) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	// Everything but the content preview has a fixed width
+	rest := width - 8 - 10 - 8 - 20 - 16 - 4 - 14
+	if rest < 20 {
+		rest = 20
+	}
+	m.table.SetColumns([]bubbletable.Column{
+		{Title: "ID", Width: 8},
+		{Title: "Type", Width: 10},
+		{Title: "Priority", Width: 8},
+		{Title: "Tags", Width: 20},
+		{Title: "Content", Width: rest},
+		{Title: "Created", Width: 16},
+		{Title: "Hits", Width: 4},
+	})
+	m.table.SetSize(width, height-6)
+	m.searchInput.Width = width - 8
+	m.tagsInput.Width = width - 8
+	m.editInput.Width = width - 12
+	m.markdown.SetSize(width, height)
+	m.tree.SetSize(width, height)
+	m.code.SetSize(width, height)
+}
+
+// preview is a single line summary of the content of a memory
+func preview(mem memory.Memory) string {
+	if mem.Encrypted {
+		return "🔒 encrypted"
+	}
+	text, ok := mem.Content.(string)
+	if !ok {
+		data, err := json.Marshal(mem.Content)
+		if err != nil {
+			text = fmt.Sprintf("%v", mem.Content)
+		} else {
+			text = string(data)
+		}
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if mem.Occurrences > 1 {
+		// Compacted repeats, e.g. a log flood
+		text = fmt.Sprintf("×%d %s", mem.Occurrences, text)
+	}
+	return text
+}
+
+func splitTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
@@ -0,0 +1,102 @@
+package memorybrowser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/swarm/memory"
+	"github.com/opencode-ai/opencode/internal/tui/components/highlight"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+)
+
+// codeView shows a memory holding code, highlighted in its language
+type codeView struct {
+	viewport viewport.Model
+	title    string
+	source   string
+	// language is a language such as "go" or a file name
+	language string
+}
+
+func newCodeView() *codeView {
+	return &codeView{viewport: viewport.New(80, 20)}
+}
+
+// codeLanguage returns the language of a memory holding code: its language
+// metadata, or the path of a memory tagged as code or a snippet
+func codeLanguage(mem memory.Memory) (string, bool) {
+	if language, ok := mem.Metadata["language"].(string); ok && highlight.Detect(language) {
+		return language, true
+	}
+	path, ok := mem.Metadata["path"].(string)
+	if !ok || !highlight.Detect(path) {
+		return "", false
+	}
+	for _, tag := range mem.Tags {
+		if tag == "code" || tag == "snippet" {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// SetContent shows source highlighted in language
+func (v *codeView) SetContent(title, source, language string) {
+	v.title = title
+	v.source = source
+	v.language = language
+	v.render()
+	v.viewport.GotoTop()
+}
+
+// render highlights the source with the active theme, numbering its lines
+func (v *codeView) render() {
+	code := highlight.Code(v.source, v.language, styles.Background)
+	lines := strings.Split(strings.TrimSuffix(code, "\n"), "\n")
+	numberWidth := len(fmt.Sprint(len(lines)))
+	dim := styles.BaseStyle.Foreground(styles.ForgroundDim)
+	for i, line := range lines {
+		lines[i] = dim.Render(fmt.Sprintf("%*d ", numberWidth, i+1)) + strings.ReplaceAll(line, "\t", "    ")
+	}
+	v.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// Update scrolls the code and highlights it again when the theme changes
+func (v *codeView) Update(msg tea.Msg) tea.Cmd {
+	if _, ok := msg.(styles.ThemeChangedMsg); ok {
+		v.render()
+		return nil
+	}
+	var cmd tea.Cmd
+	v.viewport, cmd = v.viewport.Update(msg)
+	return cmd
+}
+
+// View renders the title, the key help and the visible code
+func (v *codeView) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render(v.title)
+
+	help := styles.BaseStyle.
+		Foreground(styles.ForgroundDim).
+		Render("↑/↓: scroll • q/esc: close")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		help,
+		"",
+		v.viewport.View(),
+	)
+}
+
+// SetSize sets the size of the view, the header taking three lines
+func (v *codeView) SetSize(width, height int) {
+	v.viewport.Width = width
+	v.viewport.Height = max(1, height-3)
+}
@@ -0,0 +1,351 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/swarm/health"
+	"github.com/opencode-ai/opencode/internal/swarm/rules"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+)
+
+// Level is the importance of a notification
+type Level string
+
+const (
+	LevelInfo    Level = "info"
+	LevelSuccess Level = "success"
+	LevelWarn    Level = "warn"
+	LevelError   Level = "error"
+)
+
+const (
+	defaultDuration = 4 * time.Second
+	// maxVisible is the number of toasts stacked on screen at once
+	maxVisible = 3
+	maxHistory = 100
+	toastWidth = 44
+)
+
+// Msg posts a notification. A zero Duration uses the default, errors stay
+// twice as long.
+type Msg struct {
+	Level    Level
+	Title    string
+	Message  string
+	Duration time.Duration
+}
+
+// ToggleHistoryMsg shows or hides the notification history popup
+type ToggleHistoryMsg struct{}
+
+// DismissMsg removes a toast once its duration elapsed
+type DismissMsg struct {
+	id int
+}
+
+// Post returns a command that posts a notification
+func Post(level Level, title, message string) tea.Cmd {
+	return func() tea.Msg {
+		return Msg{Level: level, Title: title, Message: message}
+	}
+}
+
+// Info posts an informational notification
+func Info(title, message string) tea.Cmd {
+	return Post(LevelInfo, title, message)
+}
+
+// Warn posts a warning notification
+func Warn(title, message string) tea.Cmd {
+	return Post(LevelWarn, title, message)
+}
+
+// Error posts an error notification
+func Error(title, message string) tea.Cmd {
+	return Post(LevelError, title, message)
+}
+
+type entry struct {
+	id   int
+	msg  Msg
+	time time.Time
+}
+
+// Notifier keeps the visible toasts and the notification history
+type Notifier struct {
+	width  int
+	height int
+
+	nextID      int
+	toasts      []entry
+	history     []entry
+	showHistory bool
+	scroll      int
+}
+
+// NewNotifier creates an empty notification layer
+func NewNotifier() *Notifier {
+	return &Notifier{}
+}
+
+// Init implements tea.Model
+func (n *Notifier) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (n *Notifier) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case Msg:
+		return n, n.add(msg)
+	case DismissMsg:
+		for i, t := range n.toasts {
+			if t.id == msg.id {
+				n.toasts = append(n.toasts[:i], n.toasts[i+1:]...)
+				break
+			}
+		}
+	case ToggleHistoryMsg:
+		n.showHistory = !n.showHistory
+		n.scroll = 0
+	case tea.WindowSizeMsg:
+		n.width, n.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		if !n.showHistory {
+			return n, nil
+		}
+		switch msg.String() {
+		case "esc", "q":
+			n.showHistory = false
+		case "up", "k":
+			if n.scroll > 0 {
+				n.scroll--
+			}
+		case "down", "j":
+			if n.scroll < len(n.history)-1 {
+				n.scroll++
+			}
+		case "c":
+			n.history = nil
+			n.scroll = 0
+		}
+	}
+	return n, nil
+}
+
+func (n *Notifier) add(msg Msg) tea.Cmd {
+	if msg.Level == "" {
+		msg.Level = LevelInfo
+	}
+	duration := msg.Duration
+	if duration <= 0 {
+		duration = defaultDuration
+		if msg.Level == LevelError {
+			duration *= 2
+		}
+	}
+
+	n.nextID++
+	e := entry{id: n.nextID, msg: msg, time: time.Now()}
+	n.toasts = append(n.toasts, e)
+	if len(n.toasts) > maxVisible {
+		n.toasts = n.toasts[len(n.toasts)-maxVisible:]
+	}
+	n.history = append([]entry{e}, n.history...)
+	if len(n.history) > maxHistory {
+		n.history = n.history[:maxHistory]
+	}
+
+	id := e.id
+	return tea.Tick(duration, func(time.Time) tea.Msg {
+		return DismissMsg{id: id}
+	})
+}
+
+// ShowingHistory returns whether the history popup is open and should
+// receive key presses
+func (n *Notifier) ShowingHistory() bool {
+	return n.showHistory
+}
+
+// View implements tea.Model and renders the toast stack
+func (n *Notifier) View() string {
+	if len(n.toasts) == 0 {
+		return ""
+	}
+	views := make([]string, 0, len(n.toasts))
+	for _, t := range n.toasts {
+		views = append(views, renderToast(t.msg))
+	}
+	return lipgloss.JoinVertical(lipgloss.Right, views...)
+}
+
+// Overlay draws the toasts in the top right corner and the history popup in
+// the center of the given view
+func (n *Notifier) Overlay(view string) string {
+	if toasts := n.View(); toasts != "" {
+		col := lipgloss.Width(view) - lipgloss.Width(toasts) - 1
+		if col < 0 {
+			col = 0
+		}
+		view = layout.PlaceOverlay(col, 1, toasts, view, false)
+	}
+	if n.showHistory {
+		popup := n.historyView()
+		row := lipgloss.Height(view)/2 - lipgloss.Height(popup)/2
+		col := lipgloss.Width(view)/2 - lipgloss.Width(popup)/2
+		view = layout.PlaceOverlay(col, row, popup, view, true)
+	}
+	return view
+}
+
+func (n *Notifier) historyView() string {
+	width := 70
+	if n.width > 0 && n.width-4 < width {
+		width = n.width - 4
+	}
+	height := 15
+	if n.height > 0 && n.height-6 < height {
+		height = n.height - 6
+	}
+
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Width(width).
+		Render(fmt.Sprintf("Notifications (%d)", len(n.history)))
+
+	var lines []string
+	if len(n.history) == 0 {
+		lines = append(lines, styles.BaseStyle.Foreground(styles.ForgroundDim).Width(width).Render("No notifications yet"))
+	}
+	end := n.scroll + height
+	if end > len(n.history) {
+		end = len(n.history)
+	}
+	for _, e := range n.history[n.scroll:end] {
+		text := e.msg.Title
+		if e.msg.Message != "" {
+			if text != "" {
+				text += ": "
+			}
+			text += e.msg.Message
+		}
+		line := fmt.Sprintf("%s %s %s", e.time.Format("15:04:05"), levelIcon(e.msg.Level), strings.ReplaceAll(text, "\n", " "))
+		line = ansi.Truncate(line, width, "…")
+		lines = append(lines, styles.BaseStyle.Foreground(levelColor(e.msg.Level)).Width(width).Render(line))
+	}
+
+	help := styles.BaseStyle.
+		Foreground(styles.ForgroundDim).
+		Width(width).
+		Render("↑/↓: scroll • c: clear • esc: close")
+
+	content := lipgloss.JoinVertical(lipgloss.Left, title, "", lipgloss.JoinVertical(lipgloss.Left, lines...), "", help)
+	return styles.BaseStyle.
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(styles.Background).
+		BorderForeground(styles.ForgroundDim).
+		Render(content)
+}
+
+func renderToast(msg Msg) string {
+	color := levelColor(msg.Level)
+	title := msg.Title
+	if title == "" {
+		title = strings.ToUpper(string(msg.Level[:1])) + string(msg.Level[1:])
+	}
+	parts := []string{
+		styles.BaseStyle.Bold(true).Foreground(color).Width(toastWidth).Render(levelIcon(msg.Level) + " " + title),
+	}
+	if msg.Message != "" {
+		parts = append(parts, styles.BaseStyle.Foreground(styles.Forground).Width(toastWidth).Render(msg.Message))
+	}
+	return styles.BaseStyle.
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderBackground(styles.Background).
+		BorderForeground(color).
+		Render(lipgloss.JoinVertical(lipgloss.Left, parts...))
+}
+
+func levelColor(level Level) lipgloss.AdaptiveColor {
+	switch level {
+	case LevelSuccess:
+		return styles.Green
+	case LevelWarn:
+		return styles.Warning
+	case LevelError:
+		return styles.Error
+	}
+	return styles.Blue
+}
+
+func levelIcon(level Level) string {
+	switch level {
+	case LevelSuccess:
+		return "✓"
+	case LevelWarn:
+		return "⚠"
+	case LevelError:
+		return "✗"
+	}
+	return "ℹ"
+}
+
+// Sink forwards swarm alerts and rule notifications to the TUI as Msg
+// values through the channel the program reads external messages from
+type Sink struct {
+	ch chan<- tea.Msg
+}
+
+// NewSink creates a sink writing to ch. Messages are dropped instead of
+// blocking when the channel is full.
+func NewSink(ch chan<- tea.Msg) *Sink {
+	return &Sink{ch: ch}
+}
+
+// HandleAlert implements health.AlertSink
+func (s *Sink) HandleAlert(alert health.HealthAlert) {
+	level := LevelWarn
+	switch alert.Severity {
+	case health.AlertSeverityInfo:
+		level = LevelInfo
+	case health.AlertSeverityError, health.AlertSeverityCritical:
+		level = LevelError
+	}
+	s.send(Msg{
+		Level:   level,
+		Title:   fmt.Sprintf("%s is %s", alert.ComponentID, alert.Status),
+		Message: alert.Check.Message,
+	})
+}
+
+// Notify implements rules.NotificationSink
+func (s *Sink) Notify(notification rules.Notification) {
+	level := Level(notification.Level)
+	switch level {
+	case LevelInfo, LevelSuccess, LevelWarn, LevelError:
+	default:
+		level = LevelInfo
+	}
+	s.send(Msg{
+		Level:   level,
+		Title:   notification.Title,
+		Message: notification.Message,
+	})
+}
+
+func (s *Sink) send(msg Msg) {
+	select {
+	case s.ch <- msg:
+	default:
+	}
+}
@@ -0,0 +1,253 @@
+package promotionreview
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	bubbletable "github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/tui/components/table"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// refreshInterval is how often the queue is reloaded
+const refreshInterval = 2 * time.Second
+
+// PromotionSource is the part of a coordinator the review screen uses
+type PromotionSource interface {
+	PromotionCandidates() []swarm.PromotionCandidate
+	ApprovePromotion(memoryID string) error
+	RejectPromotion(memoryID string) error
+}
+
+// PromotionReview lists the session memories queued for promotion to the
+// global namespace and lets the user approve or reject them
+type PromotionReview struct {
+	source PromotionSource
+	table  *table.DataTable
+	width  int
+	height int
+
+	candidates map[string]swarm.PromotionCandidate
+	refresher  *util.Refresher
+}
+
+// NewPromotionReview creates a review screen for the promotion queue of a
+// swarm
+func NewPromotionReview(source PromotionSource) *PromotionReview {
+	columns := []bubbletable.Column{
+		{Title: "Memory", Width: 12},
+		{Title: "Type", Width: 10},
+		{Title: "Content", Width: 40},
+		{Title: "Uses", Width: 5},
+		{Title: "Success", Width: 7},
+		{Title: "Hits", Width: 4},
+		{Title: "Queued", Width: 8},
+	}
+	m := &PromotionReview{
+		source:     source,
+		table:      table.NewDataTable(columns, nil),
+		candidates: make(map[string]swarm.PromotionCandidate),
+		refresher:  util.NewRefresher(refreshInterval),
+	}
+	m.refresh()
+	return m
+}
+
+// Open reloads the queue and keeps it up to date while shown
+func (m *PromotionReview) Open() tea.Cmd {
+	m.refresh()
+	return m.refresher.Start()
+}
+
+// Capturing returns whether keys go to the table filter
+func (m *PromotionReview) Capturing() bool {
+	return m.table.IsFiltering()
+}
+
+// Init implements tea.Model
+func (m *PromotionReview) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *PromotionReview) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case util.RefreshMsg:
+		if !m.refresher.Due(msg) {
+			return m, nil
+		}
+		m.refresh()
+		return m, m.refresher.Next()
+	case tea.KeyMsg:
+		if !m.table.IsFiltering() {
+			switch msg.String() {
+			case "a", "y":
+				return m, m.decide(true)
+			case "x", "n":
+				return m, m.decide(false)
+			case "r":
+				m.refresh()
+				return m, nil
+			}
+		}
+	}
+
+	_, cmd := m.table.Update(msg)
+	return m, cmd
+}
+
+// decide approves or rejects the promotion of the selected memory
+func (m *PromotionReview) decide(approve bool) tea.Cmd {
+	candidate, ok := m.selected()
+	if !ok {
+		return util.ReportWarn("No memory selected")
+	}
+	var err error
+	if approve {
+		err = m.source.ApprovePromotion(candidate.MemoryID)
+	} else {
+		err = m.source.RejectPromotion(candidate.MemoryID)
+	}
+	m.refresh()
+	if err != nil {
+		return util.ReportError(err)
+	}
+	if approve {
+		return util.ReportInfo("Memory promoted to the global namespace")
+	}
+	return util.ReportInfo("Promotion rejected, the memory stays in its session")
+}
+
+// selected returns the candidate under the cursor
+func (m *PromotionReview) selected() (swarm.PromotionCandidate, bool) {
+	row := m.table.SelectedRow()
+	if row == nil {
+		return swarm.PromotionCandidate{}, false
+	}
+	candidate, ok := m.candidates[row[0]]
+	return candidate, ok
+}
+
+// refresh reloads the queue into the table
+func (m *PromotionReview) refresh() {
+	m.candidates = make(map[string]swarm.PromotionCandidate)
+	if m.source == nil {
+		m.table.SetRows(nil)
+		return
+	}
+
+	candidates := m.source.PromotionCandidates()
+	rows := make([]bubbletable.Row, 0, len(candidates))
+	for _, candidate := range candidates {
+		m.candidates[candidate.MemoryID] = candidate
+		content := candidate.Preview
+		if content == "" {
+			content = "🔒 encrypted"
+		}
+		rows = append(rows, bubbletable.Row{
+			candidate.MemoryID,
+			string(candidate.Type),
+			content,
+			fmt.Sprintf("%d", candidate.Uses),
+			fmt.Sprintf("%.0f%%", candidate.SuccessRate()*100),
+			fmt.Sprintf("%d", candidate.AccessCount),
+			candidate.QueuedAt.Format("15:04:05"),
+		})
+	}
+	m.table.SetRows(rows)
+}
+
+// View implements tea.Model
+func (m *PromotionReview) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Memory Promotions")
+
+	status := fmt.Sprintf("%d memories waiting for review • uses are finished tasks that retrieved the memory", len(m.candidates))
+	if m.source == nil {
+		status = "No swarm is running"
+	}
+
+	help := "a/y: promote • x/n: reject • r: refresh"
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		styles.BaseStyle.Foreground(styles.ForgroundMid).Render(status),
+		styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help),
+		"",
+		m.table.View(),
+		"",
+		m.details(),
+	)
+}
+
+// details renders the selected memory in full
+func (m *PromotionReview) details() string {
+	candidate, ok := m.selected()
+	if !ok {
+		return styles.BaseStyle.Foreground(styles.ForgroundDim).Render("No memories waiting for promotion")
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	label := styles.BaseStyle.Foreground(styles.ForgroundMid)
+	text := styles.BaseStyle.Foreground(styles.Forground)
+
+	lines := []string{
+		label.Render("Memory: ") + text.Render(candidate.MemoryID),
+		label.Render("From: ") + text.Render(candidate.Namespace),
+		label.Render("Used by: ") + text.Render(fmt.Sprintf("%d tasks, %d succeeded", candidate.Uses, candidate.Successes)),
+	}
+	if len(candidate.Tags) > 0 {
+		lines = append(lines, label.Render("Tags: ")+text.Render(strings.Join(candidate.Tags, ", ")))
+	}
+	if candidate.Preview != "" {
+		lines = append(lines, "", text.Render(candidate.Preview))
+	}
+
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// tableHeight gives the queue half of the screen
+func (m *PromotionReview) tableHeight() int {
+	height := (m.height - 5) / 2
+	if height < 5 {
+		height = 5
+	}
+	return height
+}
+
+// SetSize sets the size of the review screen
+func (m *PromotionReview) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	// Everything but the content preview has a fixed width
+	rest := width - 12 - 10 - 5 - 7 - 4 - 8 - 14
+	if rest < 20 {
+		rest = 20
+	}
+	m.table.SetColumns([]bubbletable.Column{
+		{Title: "Memory", Width: 12},
+		{Title: "Type", Width: 10},
+		{Title: "Content", Width: rest},
+		{Title: "Uses", Width: 5},
+		{Title: "Success", Width: 7},
+		{Title: "Hits", Width: 4},
+		{Title: "Queued", Width: 8},
+	})
+	m.table.SetSize(width, m.tableHeight())
+}
@@ -0,0 +1,549 @@
+package rulemanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	bubbletable "github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/swarm/rules"
+	"github.com/opencode-ai/opencode/internal/tui/components/table"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+const (
+	// refreshInterval is how often firing counts are reloaded
+	refreshInterval = 2 * time.Second
+	// historyLimit is the number of executions shown for a rule
+	historyLimit = 100
+)
+
+// newRuleTemplate is the editor content for a new rule
+const newRuleTemplate = `id: my-rule
+name: My rule
+description: What the rule is for
+priority: 10
+enabled: true
+tags: []
+condition:
+  type: event_type        # always, event_type or field
+  event_type: error
+actions:
+  - type: notify          # log or notify
+    level: warn
+    title: Something happened
+    message: Details for the user
+`
+
+// view is the screen the manager is showing
+type view int
+
+const (
+	viewList view = iota
+	viewHistory
+	viewEditor
+)
+
+// RuleManager lists the rules of a rule engine and lets the user toggle,
+// inspect and edit them
+type RuleManager struct {
+	engine *rules.RuleEngine
+	width  int
+	height int
+
+	view    view
+	rules   *table.DataTable
+	history *table.DataTable
+	// historyRule is the rule whose executions are shown
+	historyRule string
+
+	editor textarea.Model
+	// editingID is the rule being edited, empty for a new rule
+	editingID string
+	editErr   error
+
+	refresher *util.Refresher
+}
+
+// NewRuleManager creates a manager for the rules of an engine
+func NewRuleManager(engine *rules.RuleEngine) *RuleManager {
+	ruleTable := table.NewDataTable([]bubbletable.Column{
+		{Title: "ID", Width: 16},
+		{Title: "Name", Width: 24},
+		{Title: "On", Width: 3},
+		{Title: "Priority", Width: 8},
+		{Title: "Tags", Width: 16},
+		{Title: "Last fired", Width: 19},
+		{Title: "Fired", Width: 6},
+		{Title: "Failures", Width: 8},
+	}, nil)
+
+	historyTable := table.NewDataTable([]bubbletable.Column{
+		{Title: "Time", Width: 19},
+		{Title: "Event", Width: 16},
+		{Title: "Agent", Width: 16},
+		{Title: "Fired", Width: 5},
+		{Title: "Result", Width: 7},
+		{Title: "Duration", Width: 10},
+		{Title: "Error", Width: 40},
+	}, nil)
+
+	editor := textarea.New()
+	editor.ShowLineNumbers = true
+	editor.CharLimit = 0
+
+	m := &RuleManager{
+		engine:    engine,
+		rules:     ruleTable,
+		history:   historyTable,
+		editor:    editor,
+		refresher: util.NewRefresher(refreshInterval),
+	}
+	m.refresh()
+	return m
+}
+
+// Open reloads the rules and keeps the statistics up to date while shown
+func (m *RuleManager) Open() tea.Cmd {
+	m.refresh()
+	return m.refresher.Start()
+}
+
+// Capturing returns whether keys go to the editor or a sub view, in which
+// case q and esc must not close the tool
+func (m *RuleManager) Capturing() bool {
+	return m.view != viewList || m.rules.IsFiltering()
+}
+
+// Init implements tea.Model
+func (m *RuleManager) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *RuleManager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(util.RefreshMsg); ok {
+		if !m.refresher.Due(msg) {
+			return m, nil
+		}
+		m.refresh()
+		return m, m.refresher.Next()
+	}
+
+	switch m.view {
+	case viewEditor:
+		return m, m.updateEditor(msg)
+	case viewHistory:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && !m.history.IsFiltering() {
+			switch keyMsg.String() {
+			case "esc", "q", "backspace":
+				m.view = viewList
+				return m, nil
+			}
+		}
+		_, cmd := m.history.Update(msg)
+		return m, cmd
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && !m.rules.IsFiltering() {
+		switch keyMsg.String() {
+		case " ", "e":
+			return m, m.toggleSelected()
+		case "enter", "H":
+			return m, m.showHistory()
+		case "E":
+			return m, m.editSelected()
+		case "n":
+			return m, m.edit("", newRuleTemplate)
+		case "r":
+			m.refresh()
+			return m, nil
+		}
+	}
+
+	_, cmd := m.rules.Update(msg)
+	return m, cmd
+}
+
+// refresh reloads the rule list and, when shown, the execution history
+func (m *RuleManager) refresh() {
+	if m.engine == nil {
+		return
+	}
+
+	all := m.engine.GetAllRules()
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Priority != all[j].Priority {
+			return all[i].Priority > all[j].Priority
+		}
+		return all[i].ID < all[j].ID
+	})
+	stats := m.engine.GetRuleStats()
+
+	rows := make([]bubbletable.Row, 0, len(all))
+	for _, rule := range all {
+		s := stats[rule.ID]
+		enabled := "✗"
+		if rule.Enabled {
+			enabled = "✓"
+		}
+		lastFired := "never"
+		if !s.LastFired.IsZero() {
+			lastFired = s.LastFired.Format("2006-01-02 15:04:05")
+		}
+		rows = append(rows, bubbletable.Row{
+			rule.ID,
+			rule.Name,
+			enabled,
+			fmt.Sprintf("%d", rule.Priority),
+			strings.Join(rule.Tags, ","),
+			lastFired,
+			fmt.Sprintf("%d", s.Fired),
+			fmt.Sprintf("%d", s.Failures),
+		})
+	}
+	m.rules.SetRows(rows)
+
+	if m.view == viewHistory {
+		m.loadHistory()
+	}
+}
+
+func (m *RuleManager) loadHistory() {
+	executions := m.engine.GetRuleHistory(m.historyRule, historyLimit)
+	rows := make([]bubbletable.Row, 0, len(executions))
+	// Newest first
+	for i := len(executions) - 1; i >= 0; i-- {
+		e := executions[i]
+		fired := "no"
+		if e.Fired {
+			fired = "yes"
+		}
+		result := "ok"
+		errText := ""
+		if e.Error != nil {
+			result = "failed"
+			errText = e.Error.Error()
+		} else if !e.Fired {
+			result = "-"
+		}
+		rows = append(rows, bubbletable.Row{
+			e.Timestamp.Format("2006-01-02 15:04:05"),
+			e.Context.EventType,
+			e.Context.AgentID,
+			fired,
+			result,
+			e.Duration.Round(time.Microsecond).String(),
+			errText,
+		})
+	}
+	m.history.SetRows(rows)
+}
+
+// selectedRule returns the rule under the cursor
+func (m *RuleManager) selectedRule() *rules.Rule {
+	row := m.rules.SelectedRow()
+	if row == nil || m.engine == nil {
+		return nil
+	}
+	rule, err := m.engine.GetRule(row[0])
+	if err != nil {
+		return nil
+	}
+	return rule
+}
+
+func (m *RuleManager) toggleSelected() tea.Cmd {
+	rule := m.selectedRule()
+	if rule == nil {
+		return nil
+	}
+	if err := m.engine.SetRuleEnabled(context.Background(), rule.ID, !rule.Enabled); err != nil {
+		return util.ReportError(err)
+	}
+	m.refresh()
+	if rule.Enabled {
+		return util.ReportInfo("Disabled rule " + rule.ID)
+	}
+	return util.ReportInfo("Enabled rule " + rule.ID)
+}
+
+func (m *RuleManager) showHistory() tea.Cmd {
+	rule := m.selectedRule()
+	if rule == nil {
+		return nil
+	}
+	m.historyRule = rule.ID
+	m.view = viewHistory
+	m.loadHistory()
+	return nil
+}
+
+// editSelected opens the YAML definition of the selected rule
+func (m *RuleManager) editSelected() tea.Cmd {
+	rule := m.selectedRule()
+	if rule == nil {
+		return nil
+	}
+	def, err := rules.DefinitionFromRule(rule)
+	if err != nil {
+		return util.ReportWarn(err.Error())
+	}
+	data, err := rules.MarshalRuleDefinition(def)
+	if err != nil {
+		return util.ReportError(err)
+	}
+	return m.edit(rule.ID, string(data))
+}
+
+func (m *RuleManager) edit(ruleID, content string) tea.Cmd {
+	if m.engine == nil {
+		return nil
+	}
+	m.editingID = ruleID
+	m.editor.SetValue(content)
+	m.validate()
+	m.view = viewEditor
+	return m.editor.Focus()
+}
+
+func (m *RuleManager) updateEditor(msg tea.Msg) tea.Cmd {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			m.editor.Blur()
+			m.view = viewList
+			return nil
+		case "ctrl+s":
+			return m.save()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.editor, cmd = m.editor.Update(msg)
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m.validate()
+	}
+	return cmd
+}
+
+// parse reads the single rule definition in the editor
+func (m *RuleManager) parse() (rules.RuleDefinition, error) {
+	defs, err := rules.ParseRuleDefinitions([]byte(m.editor.Value()))
+	if err != nil {
+		return rules.RuleDefinition{}, err
+	}
+	if len(defs) != 1 {
+		return rules.RuleDefinition{}, fmt.Errorf("expected one rule, found %d", len(defs))
+	}
+	if err := defs[0].Validate(); err != nil {
+		return rules.RuleDefinition{}, err
+	}
+	if m.editingID != "" && defs[0].ID != m.editingID {
+		return rules.RuleDefinition{}, fmt.Errorf("rule ID cannot be changed from %s", m.editingID)
+	}
+	return defs[0], nil
+}
+
+func (m *RuleManager) validate() {
+	_, m.editErr = m.parse()
+}
+
+// save validates the editor content and adds or replaces the rule
+func (m *RuleManager) save() tea.Cmd {
+	def, err := m.parse()
+	m.editErr = err
+	if err != nil {
+		return nil
+	}
+
+	rule, err := def.Build(rules.BuildOptions{
+		NotificationSink: m.notificationSink(),
+		TaskSubmitter:    m.taskSubmitter(),
+		MemorySearcher:   m.memorySearcher(),
+		VoteRequester:    m.voteRequester(),
+	})
+	if err != nil {
+		m.editErr = err
+		return nil
+	}
+	if m.editingID == "" {
+		if _, err := m.engine.GetRule(rule.ID); err == nil {
+			m.editErr = fmt.Errorf("rule %s already exists", rule.ID)
+			return nil
+		}
+		err = m.engine.AddRule(context.Background(), rule)
+	} else {
+		if existing, getErr := m.engine.GetRule(rule.ID); getErr == nil {
+			rule.CreatedAt = existing.CreatedAt
+		}
+		err = m.engine.UpdateRule(context.Background(), rule)
+	}
+	if err != nil {
+		m.editErr = err
+		return nil
+	}
+
+	m.editor.Blur()
+	m.view = viewList
+	m.refresh()
+	return util.ReportInfo("Saved rule " + rule.ID)
+}
+
+// notificationSink reuses the sink of an existing notify action so edited
+// rules notify the same place as the rules loaded at startup
+func (m *RuleManager) notificationSink() rules.NotificationSink {
+	for _, rule := range m.engine.GetAllRules() {
+		for _, action := range rule.Actions {
+			if notify, ok := action.(*rules.NotifyAction); ok && notify.Sink != nil {
+				return notify.Sink
+			}
+		}
+	}
+	return nil
+}
+
+// taskSubmitter reuses the submitter of an existing submit_task action so
+// edited rules queue tasks with the same swarm
+func (m *RuleManager) taskSubmitter() rules.TaskSubmitter {
+	for _, rule := range m.engine.GetAllRules() {
+		for _, action := range rule.Actions {
+			if submit, ok := action.(*rules.SubmitTaskAction); ok && submit.Submitter != nil {
+				return submit.Submitter
+			}
+		}
+	}
+	return nil
+}
+
+// voteRequester reuses the requester of an existing require_vote action so
+// edited rules open their votes in the same swarm
+func (m *RuleManager) voteRequester() rules.VoteRequester {
+	for _, rule := range m.engine.GetAllRules() {
+		for _, action := range rule.Actions {
+			if vote, ok := action.(*rules.RequireVoteAction); ok && vote.Requester != nil {
+				return vote.Requester
+			}
+		}
+	}
+	return nil
+}
+
+// memorySearcher reuses the searcher of an existing memory condition so
+// edited rules search the same memory store
+func (m *RuleManager) memorySearcher() rules.MemorySearcher {
+	for _, rule := range m.engine.GetAllRules() {
+		if searcher := conditionSearcher(rule.Condition); searcher != nil {
+			return searcher
+		}
+	}
+	return nil
+}
+
+func conditionSearcher(condition rules.Condition) rules.MemorySearcher {
+	switch c := condition.(type) {
+	case *rules.MemoryCondition:
+		return c.Searcher
+	case *rules.AllCondition:
+		for _, sub := range c.Conditions {
+			if searcher := conditionSearcher(sub); searcher != nil {
+				return searcher
+			}
+		}
+	}
+	return nil
+}
+
+// View implements tea.Model
+func (m *RuleManager) View() string {
+	switch m.view {
+	case viewHistory:
+		return m.historyView()
+	case viewEditor:
+		return m.editorView()
+	}
+
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Rules")
+
+	status := "No swarm is running"
+	if m.engine != nil {
+		status = fmt.Sprintf("%d rules", len(m.engine.GetAllRules()))
+	}
+	help := "space: toggle • enter: executions • E: edit YAML • n: new rule • r: refresh"
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		styles.BaseStyle.Foreground(styles.ForgroundMid).Render(status),
+		styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help),
+		"",
+		m.rules.View(),
+	)
+}
+
+func (m *RuleManager) historyView() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Executions of " + m.historyRule)
+
+	help := "newest first • esc: back to rules"
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help),
+		"",
+		m.history.View(),
+	)
+}
+
+func (m *RuleManager) editorView() string {
+	heading := "New rule"
+	if m.editingID != "" {
+		heading = "Edit rule " + m.editingID
+	}
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render(heading)
+
+	status := styles.BaseStyle.Foreground(styles.Green).Render("✓ valid")
+	if m.editErr != nil {
+		status = styles.BaseStyle.Foreground(styles.Error).Render("✗ " + m.editErr.Error())
+	}
+
+	help := styles.BaseStyle.
+		Foreground(styles.ForgroundDim).
+		Render("ctrl+s: save • esc: cancel")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		status,
+		"",
+		m.editor.View(),
+		"",
+		help,
+	)
+}
+
+// SetSize sets the size of the manager
+func (m *RuleManager) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	m.rules.SetSize(width, height-4)
+	m.history.SetSize(width, height-3)
+	m.editor.SetWidth(width)
+	m.editor.SetHeight(height - 5)
+}
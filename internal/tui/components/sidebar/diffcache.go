@@ -0,0 +1,145 @@
+package sidebar
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/opencode-ai/opencode/internal/diff"
+	"github.com/opencode-ai/opencode/internal/history"
+)
+
+// modifiedFilesDebounce is how long the sidebar waits for more changes
+// before diffing changed files, so a burst of edits is diffed once
+const modifiedFilesDebounce = 150 * time.Millisecond
+
+// diffKey identifies a version of a file
+type diffKey struct {
+	path    string
+	version string
+}
+
+// diffStats counts the lines a version adds and removes since the initial
+// version of its file
+type diffStats struct {
+	additions int
+	removals  int
+}
+
+// diffCache remembers the initial version of each file and the diff stats
+// of its latest version, so each version is diffed once
+type diffCache struct {
+	initial map[string]history.File
+	stats   map[diffKey]diffStats
+	// latest is the version of each path in stats
+	latest map[string]string
+}
+
+func newDiffCache() *diffCache {
+	return &diffCache{
+		initial: make(map[string]history.File),
+		stats:   make(map[diffKey]diffStats),
+		latest:  make(map[string]string),
+	}
+}
+
+// get returns the cached diff stats of a version
+func (c *diffCache) get(file history.File) (diffStats, bool) {
+	stats, ok := c.stats[diffKey{file.Path, file.Version}]
+	return stats, ok
+}
+
+// put caches the diff stats of a version, replacing those of older
+// versions of the file
+func (c *diffCache) put(file history.File, stats diffStats) {
+	if previous, ok := c.latest[file.Path]; ok && previous != file.Version {
+		delete(c.stats, diffKey{file.Path, previous})
+	}
+	c.latest[file.Path] = file.Version
+	c.stats[diffKey{file.Path, file.Version}] = stats
+}
+
+// diffVersion diffs a version against the initial version of its file
+func diffVersion(initial, file history.File) diffStats {
+	if initial.Content == file.Content {
+		return diffStats{}
+	}
+	_, additions, removals := diff.GenerateDiff(initial.Content, file.Content, file.Path)
+	return diffStats{additions: additions, removals: removals}
+}
+
+// modifiedFilesLoadedMsg carries the modified files of a session, diffed
+// in the background
+type modifiedFilesLoadedMsg struct {
+	sessionID string
+	initial   map[string]history.File
+	latest    []history.File
+	stats     []diffStats
+}
+
+// fileDiffedMsg carries the diff of a changed file
+type fileDiffedMsg struct {
+	sessionID string
+	initial   history.File
+	file      history.File
+	stats     diffStats
+}
+
+// diffPendingMsg diffs the files changed since the debounce started
+type diffPendingMsg struct {
+	sessionID string
+}
+
+// loadModifiedFiles lists the files of a session and diffs their latest
+// versions inside the command, so large sessions do not block the UI
+func loadModifiedFiles(files history.Service, sessionID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		latest, err := files.ListLatestSessionFiles(ctx, sessionID)
+		if err != nil {
+			return nil
+		}
+		all, err := files.ListBySession(ctx, sessionID)
+		if err != nil {
+			return nil
+		}
+		msg := modifiedFilesLoadedMsg{sessionID: sessionID, initial: make(map[string]history.File)}
+		for _, v := range all {
+			if v.Version == history.InitialVersion {
+				msg.initial[v.Path] = v
+			}
+		}
+		for _, file := range latest {
+			initial, ok := msg.initial[file.Path]
+			if file.Version == history.InitialVersion || !ok {
+				continue
+			}
+			msg.latest = append(msg.latest, file)
+			msg.stats = append(msg.stats, diffVersion(initial, file))
+		}
+		return msg
+	}
+}
+
+// diffFile diffs a changed file in a command, looking up its initial
+// version unless known
+func diffFile(files history.Service, initial history.File, file history.File) tea.Cmd {
+	return func() tea.Msg {
+		if initial.ID == "" {
+			versions, err := files.ListBySession(context.Background(), file.SessionID)
+			if err != nil {
+				return nil
+			}
+			for _, v := range versions {
+				if v.Path == file.Path && v.Version == history.InitialVersion {
+					initial = v
+					break
+				}
+			}
+			if initial.ID == "" {
+				return nil
+			}
+		}
+		return fileDiffedMsg{sessionID: file.SessionID, initial: initial, file: file, stats: diffVersion(initial, file)}
+	}
+}
@@ -5,17 +5,25 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
 	"github.com/opencode-ai/opencode/internal/config"
-	"github.com/opencode-ai/opencode/internal/diff"
 	"github.com/opencode-ai/opencode/internal/history"
 	"github.com/opencode-ai/opencode/internal/pubsub"
 	"github.com/opencode-ai/opencode/internal/session"
+	"github.com/opencode-ai/opencode/internal/tui/keymap"
 	"github.com/opencode-ai/opencode/internal/tui/styles"
 )
 
+// ToggleSectionMsg expands or collapses the section bound to a sidebar
+// toggle action
+type ToggleSectionMsg struct {
+	Action keymap.Action
+}
+
 // ModularSidebar is an enhanced sidebar with collapsible widget sections
 type ModularSidebar struct {
 	width, height int
@@ -27,6 +35,14 @@ type ModularSidebar struct {
 		additions int
 		removals  int
 	}
+	filesCh <-chan pubsub.Event[history.File]
+	// cancelFiles ends the subscription to file events
+	cancelFiles context.CancelFunc
+	diffs   *diffCache
+	// pending are the files changed since the debounce started, by path
+	pending map[string]history.File
+	// diffing is the ID of the version of each path last sent to be diffed
+	diffing map[string]string
 	
 	// Widgets
 	widgets        []Widget
@@ -38,11 +54,15 @@ type ModularSidebar struct {
 	showSession      bool
 	showLSP          bool
 	showModifiedFiles bool
+	
+	// Prefix for the mouse zones of the section headers
+	zonePrefix string
 }
 
 func NewModularSidebar(session session.Session, history history.Service) tea.Model {
 	// Create widgets
 	progressWidget := NewProgressWidget().(*ProgressWidget)
+	progressWidget.SetSession(session.ID)
 	filesWidget := NewFilesystemWidget().(*FilesystemWidget)
 	systemWidget := NewSystemInfoWidget().(*SystemInfoWidget)
 	
@@ -62,6 +82,7 @@ func NewModularSidebar(session session.Session, history history.Service) tea.Mod
 		showSession:       true,
 		showLSP:           true,
 		showModifiedFiles: true,
+		zonePrefix:        zone.NewPrefix(),
 	}
 }
 
@@ -77,23 +98,27 @@ func (m *ModularSidebar) Init() tea.Cmd {
 	}
 	
 	if m.history != nil {
-		ctx := context.Background()
-		// Subscribe to file events
-		filesCh := m.history.Subscribe(ctx)
+		// Subscribe to file events once, every event re-arms the command
+		// receiving the next. Close ends the subscription.
+		m.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		m.cancelFiles = cancel
+		m.filesCh = m.history.Subscribe(ctx)
 
 		// Initialize the modified files map
 		m.modFiles = make(map[string]struct {
 			additions int
 			removals  int
 		})
+		m.diffs = newDiffCache()
+		m.pending = make(map[string]history.File)
+		m.diffing = make(map[string]string)
 
-		// Load initial files and calculate diffs
-		m.loadModifiedFiles(ctx)
-
-		// Return a command that will send file events to the Update method
-		cmds = append(cmds, func() tea.Msg {
-			return <-filesCh
-		})
+		// Load initial files and calculate diffs in the background
+		if m.session.ID != "" {
+			cmds = append(cmds, loadModifiedFiles(m.history, m.session.ID))
+		}
+		cmds = append(cmds, m.nextFileEvent())
 	}
 	
 	return tea.Batch(cmds...)
@@ -103,59 +128,77 @@ func (m *ModularSidebar) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	cmds := []tea.Cmd{}
 	
 	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		// Handle keyboard shortcuts for toggling sections
-		switch msg.String() {
-		case "ctrl+t s":
-			// Toggle Session section
-			m.ToggleSession()
-			return m, nil
-		case "ctrl+t l":
-			// Toggle LSP section
-			m.ToggleLSP()
-			return m, nil
-		case "ctrl+t m":
-			// Toggle Modified Files section
-			m.ToggleModifiedFiles()
-			return m, nil
-		case "ctrl+t p":
-			// Toggle Progress widget
-			if m.progressWidget != nil {
-				m.progressWidget.ToggleCollapse()
-			}
-			return m, nil
-		case "ctrl+t f":
-			// Toggle Filesystem widget
-			if m.filesWidget != nil {
-				m.filesWidget.ToggleCollapse()
-			}
-			return m, nil
-		case "ctrl+t i":
-			// Toggle System Info widget
-			if m.systemWidget != nil {
-				m.systemWidget.ToggleCollapse()
+	case tea.MouseMsg:
+		// Clicking a section header expands or collapses it
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			if m.toggleSectionAt(msg) {
+				return m, nil
 			}
-			return m, nil
 		}
+	case ToggleSectionMsg:
+		m.toggle(msg.Action)
+		return m, nil
 	case pubsub.Event[session.Session]:
 		if msg.Type == pubsub.UpdatedEvent {
 			if m.session.ID == msg.Payload.ID {
 				m.session = msg.Payload
 			}
 		}
-	case pubsub.Event[history.File]:
-		if msg.Payload.SessionID == m.session.ID {
-			// Process the individual file change
-			ctx := context.Background()
-			m.processFileChanges(ctx, msg.Payload)
-
-			// Return a command to continue receiving events
-			return m, func() tea.Msg {
-				ctx := context.Background()
-				filesCh := m.history.Subscribe(ctx)
-				return <-filesCh
+	case fileEventMsg:
+		// Events of the subscription of a replaced sidebar are not ours
+		if msg.filesCh != m.filesCh {
+			return m, nil
+		}
+		cmds = append(cmds, m.nextFileEvent())
+		file := msg.event.Payload
+		if file.SessionID == m.session.ID && file.Version != history.InitialVersion {
+			// Only the changed file is diffed, once changes settle
+			if len(m.pending) == 0 {
+				sessionID := m.session.ID
+				cmds = append(cmds, tea.Tick(modifiedFilesDebounce, func(time.Time) tea.Msg {
+					return diffPendingMsg{sessionID: sessionID}
+				}))
+			}
+			m.pending[file.Path] = file
+		}
+		return m, tea.Batch(cmds...)
+	case diffPendingMsg:
+		if msg.sessionID == m.session.ID {
+			for path, file := range m.pending {
+				if stats, ok := m.diffs.get(file); ok {
+					m.setModified(file.Path, stats)
+				} else {
+					m.diffing[path] = file.ID
+					cmds = append(cmds, diffFile(m.history, m.diffs.initial[path], file))
+				}
 			}
+			clear(m.pending)
 		}
+		return m, tea.Batch(cmds...)
+	case fileDiffedMsg:
+		// Results of versions replaced while they were diffed are stale
+		if msg.sessionID == m.session.ID && m.diffing[msg.file.Path] == msg.file.ID {
+			delete(m.diffing, msg.file.Path)
+			m.diffs.initial[msg.file.Path] = msg.initial
+			m.diffs.put(msg.file, msg.stats)
+			m.setModified(msg.file.Path, msg.stats)
+		}
+		return m, nil
+	case modifiedFilesLoadedMsg:
+		if msg.sessionID == m.session.ID {
+			for path, initial := range msg.initial {
+				m.diffs.initial[path] = initial
+			}
+			for i, file := range msg.latest {
+				// Files changed since they were listed are diffed already
+				if _, changed := m.diffs.latest[file.Path]; changed {
+					continue
+				}
+				m.diffs.put(file, msg.stats[i])
+				m.setModified(file.Path, msg.stats[i])
+			}
+		}
+		return m, nil
 	}
 	
 	// Update all widgets
@@ -171,57 +214,58 @@ func (m *ModularSidebar) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *ModularSidebar) View() string {
-	sections := []string{
-		m.renderHeader(),
-		"",
-	}
+	var blocks []string
 	
 	// Session section
 	if m.showSession {
-		sections = append(sections, m.renderCollapsibleSection("Session", m.sessionContent(), "ctrl+t s"))
-		sections = append(sections, "")
+		blocks = append(blocks, m.renderCollapsibleSection("Session", m.sessionContent(), shortcut(keymap.ToggleSession)))
 	} else {
-		sections = append(sections, m.renderCollapsedSection("Session", "ctrl+t s"))
-		sections = append(sections, "")
+		blocks = append(blocks, m.renderCollapsedSection("Session", shortcut(keymap.ToggleSession)))
 	}
 	
 	// LSP section
 	if m.showLSP {
-		sections = append(sections, m.renderCollapsibleSection("LSP Configuration", m.lspContent(), "ctrl+t l"))
-		sections = append(sections, "")
+		blocks = append(blocks, m.renderCollapsibleSection("LSP Configuration", m.lspContent(), shortcut(keymap.ToggleLSP)))
 	} else {
-		sections = append(sections, m.renderCollapsedSection("LSP Configuration", "ctrl+t l"))
-		sections = append(sections, "")
+		blocks = append(blocks, m.renderCollapsedSection("LSP Configuration", shortcut(keymap.ToggleLSP)))
 	}
 	
 	// Modified Files section
 	if m.showModifiedFiles {
-		sections = append(sections, m.renderCollapsibleSection("Modified Files", m.modifiedFilesContent(), "ctrl+t m"))
-		sections = append(sections, "")
+		blocks = append(blocks, m.renderCollapsibleSection("Modified Files", m.modifiedFilesContent(), shortcut(keymap.ToggleModifiedFiles)))
 	} else {
-		sections = append(sections, m.renderCollapsedSection("Modified Files", "ctrl+t m"))
-		sections = append(sections, "")
+		blocks = append(blocks, m.renderCollapsedSection("Modified Files", shortcut(keymap.ToggleModifiedFiles)))
 	}
 	
 	// Widget sections
 	widgetShortcuts := map[string]string{
-		"Progress":    "ctrl+t p",
-		"Filesystem":  "ctrl+t f",
-		"System Info": "ctrl+t i",
+		"Progress":    shortcut(keymap.ToggleProgress),
+		"Filesystem":  shortcut(keymap.ToggleFilesystem),
+		"System Info": shortcut(keymap.ToggleSystemInfo),
 	}
 	
 	for _, widget := range m.widgets {
 		shortcut := widgetShortcuts[widget.Title()]
 		if !widget.IsCollapsed() {
-			sections = append(sections, m.renderCollapsibleSection(widget.Title(), widget.View(), shortcut))
-			sections = append(sections, "")
+			blocks = append(blocks, m.renderCollapsibleSection(widget.Title(), widget.View(), shortcut))
 		} else {
-			sections = append(sections, m.renderCollapsedSection(widget.Title(), shortcut))
-			sections = append(sections, "")
+			blocks = append(blocks, m.renderCollapsedSection(widget.Title(), shortcut))
 		}
 	}
 	
-	content := lipgloss.JoinVertical(lipgloss.Top, sections...)
+	header := m.renderHeader()
+	var content string
+	if columns := m.columns(); columns > 1 {
+		// Flow the sections into columns that fit the height
+		available := m.height - 1 - lipgloss.Height(header) - 1
+		content = lipgloss.JoinVertical(lipgloss.Top, header, "", flowColumns(blocks, columns, sidebarColumnWidth, available))
+	} else {
+		sections := []string{header, ""}
+		for _, block := range blocks {
+			sections = append(sections, block, "")
+		}
+		content = lipgloss.JoinVertical(lipgloss.Top, sections...)
+	}
 	
 	return styles.BaseStyle.
 		Width(m.width).
@@ -231,6 +275,60 @@ func (m *ModularSidebar) View() string {
 		Render(content)
 }
 
+// sidebarColumnWidth is the width of each column when the sidebar is wide
+// enough to show its sections side by side
+const sidebarColumnWidth = 32
+
+// compactSidebarWidth is the width below which shortcut hints are hidden
+const compactSidebarWidth = 40
+
+// columns returns how many section columns fit the sidebar. This is more than
+// one when the layout stacks the sidebar below the main panel, so sections
+// that would overflow the shorter panel move to the next column instead.
+func (m *ModularSidebar) columns() int {
+	inner := m.width - 6
+	columns := inner / (sidebarColumnWidth + 2)
+	if columns < 1 {
+		return 1
+	}
+	return columns
+}
+
+// flowColumns lays blocks out top to bottom, moving to the next column when
+// one is full. Blocks that do not fit anywhere are summarized on the last
+// line.
+func flowColumns(blocks []string, columns, width, height int) string {
+	columnStyle := styles.BaseStyle.Width(width).MarginRight(2)
+	var rendered []string
+	var current []string
+	used := 0
+	hidden := 0
+	for i, block := range blocks {
+		h := lipgloss.Height(block)
+		if used > 0 && used+h > height {
+			rendered = append(rendered, columnStyle.Render(lipgloss.JoinVertical(lipgloss.Top, current...)))
+			current, used = nil, 0
+		}
+		if len(rendered) == columns {
+			hidden = len(blocks) - i
+			break
+		}
+		current = append(current, block, "")
+		used += h + 1
+	}
+	if len(current) > 0 && len(rendered) < columns {
+		rendered = append(rendered, columnStyle.Render(lipgloss.JoinVertical(lipgloss.Top, current...)))
+	}
+	
+	out := lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+	if hidden > 0 {
+		out = lipgloss.JoinVertical(lipgloss.Top, out, styles.BaseStyle.
+			Foreground(styles.ForgroundDim).
+			Render(fmt.Sprintf("+%d sections hidden, collapse some with %s", hidden, keymap.Get(keymap.SidebarPrefix).Help().Key)))
+	}
+	return out
+}
+
 func (m *ModularSidebar) renderHeader() string {
 	logo := fmt.Sprintf("%s %s", styles.OpenCodeIcon, "OpenCode")
 	version := styles.BaseStyle.Foreground(styles.ForgroundDim).Render("Sidebar")
@@ -278,6 +376,10 @@ func (m *ModularSidebar) renderCollapsibleSection(title string, content string,
 		Foreground(styles.PrimaryColor).
 		Bold(true)
 	
+	if m.width < compactSidebarWidth {
+		shortcutHint = ""
+	}
+	
 	titleLine := lipgloss.JoinHorizontal(
 		lipgloss.Left,
 		titleStyle.Render(titleWithIndicator),
@@ -286,7 +388,7 @@ func (m *ModularSidebar) renderCollapsibleSection(title string, content string,
 	
 	return lipgloss.JoinVertical(
 		lipgloss.Top,
-		titleLine,
+		zone.Mark(m.zonePrefix+title, titleLine),
 		content,
 	)
 }
@@ -304,11 +406,15 @@ func (m *ModularSidebar) renderCollapsedSection(title string, shortcut string) s
 		Foreground(styles.ForgroundDim).
 		Bold(true)
 	
-	return lipgloss.JoinHorizontal(
+	if m.width < compactSidebarWidth {
+		shortcutHint = ""
+	}
+	
+	return zone.Mark(m.zonePrefix+title, lipgloss.JoinHorizontal(
 		lipgloss.Left,
 		titleStyle.Render(titleWithIndicator),
 		shortcutHint,
-	)
+	))
 }
 
 func (m *ModularSidebar) sessionContent() string {
@@ -389,8 +495,12 @@ func (m *ModularSidebar) SetSize(width, height int) tea.Cmd {
 	m.height = height
 	
 	// Update widget sizes
+	widgetWidth := width
+	if m.columns() > 1 {
+		widgetWidth = sidebarColumnWidth
+	}
 	for _, widget := range m.widgets {
-		widget.SetSize(width, 0) // Height will be calculated dynamically
+		widget.SetSize(widgetWidth, 0) // Height will be calculated dynamically
 	}
 	
 	return nil
@@ -400,6 +510,35 @@ func (m *ModularSidebar) GetSize() (int, int) {
 	return m.width, m.height
 }
 
+// shortcut returns the key sequence of a sidebar toggle for display
+func shortcut(action keymap.Action) string {
+	return keymap.Get(keymap.SidebarPrefix).Help().Key + " " + keymap.Get(action).Help().Key
+}
+
+// toggle expands or collapses the section bound to a toggle action
+func (m *ModularSidebar) toggle(action keymap.Action) {
+	switch action {
+	case keymap.ToggleSession:
+		m.ToggleSession()
+	case keymap.ToggleLSP:
+		m.ToggleLSP()
+	case keymap.ToggleModifiedFiles:
+		m.ToggleModifiedFiles()
+	case keymap.ToggleProgress:
+		if m.progressWidget != nil {
+			m.progressWidget.ToggleCollapse()
+		}
+	case keymap.ToggleFilesystem:
+		if m.filesWidget != nil {
+			m.filesWidget.ToggleCollapse()
+		}
+	case keymap.ToggleSystemInfo:
+		if m.systemWidget != nil {
+			m.systemWidget.ToggleCollapse()
+		}
+	}
+}
+
 // Toggle methods for sections
 func (m *ModularSidebar) ToggleSession() {
 	m.showSession = !m.showSession
@@ -413,117 +552,71 @@ func (m *ModularSidebar) ToggleModifiedFiles() {
 	m.showModifiedFiles = !m.showModifiedFiles
 }
 
-// File tracking methods (from original sidebar)
-func (m *ModularSidebar) loadModifiedFiles(ctx context.Context) {
-	if m.history == nil || m.session.ID == "" {
-		return
-	}
-
-	// Get all latest files for this session
-	latestFiles, err := m.history.ListLatestSessionFiles(ctx, m.session.ID)
-	if err != nil {
-		return
-	}
-
-	// Get all files for this session
-	allFiles, err := m.history.ListBySession(ctx, m.session.ID)
-	if err != nil {
-		return
+// toggleSectionAt toggles the section whose header was clicked and reports
+// whether there was one
+func (m *ModularSidebar) toggleSectionAt(msg tea.MouseMsg) bool {
+	switch {
+	case zone.Get(m.zonePrefix + "Session").InBounds(msg):
+		m.ToggleSession()
+		return true
+	case zone.Get(m.zonePrefix + "LSP Configuration").InBounds(msg):
+		m.ToggleLSP()
+		return true
+	case zone.Get(m.zonePrefix + "Modified Files").InBounds(msg):
+		m.ToggleModifiedFiles()
+		return true
 	}
-
-	// Clear the existing map
-	m.modFiles = make(map[string]struct {
-		additions int
-		removals  int
-	})
-
-	// Process each latest file
-	for _, file := range latestFiles {
-		if file.Version == history.InitialVersion {
-			continue
-		}
-
-		// Find the initial version
-		var initialVersion history.File
-		for _, v := range allFiles {
-			if v.Path == file.Path && v.Version == history.InitialVersion {
-				initialVersion = v
-				break
-			}
-		}
-
-		if initialVersion.ID == "" {
-			continue
-		}
-		if initialVersion.Content == file.Content {
-			continue
-		}
-
-		// Calculate diff
-		_, additions, removals := diff.GenerateDiff(initialVersion.Content, file.Content, file.Path)
-
-		if additions > 0 || removals > 0 {
-			displayPath := file.Path
-			workingDir := config.WorkingDirectory()
-			displayPath = strings.TrimPrefix(displayPath, workingDir)
-			displayPath = strings.TrimPrefix(displayPath, "/")
-
-			m.modFiles[displayPath] = struct {
-				additions int
-				removals  int
-			}{
-				additions: additions,
-				removals:  removals,
-			}
+	for _, widget := range m.widgets {
+		if zone.Get(m.zonePrefix + widget.Title()).InBounds(msg) {
+			widget.ToggleCollapse()
+			return true
 		}
 	}
+	return false
 }
 
-func (m *ModularSidebar) processFileChanges(ctx context.Context, file history.File) {
-	if file.Version == history.InitialVersion {
-		return
-	}
-
-	initialVersion, err := m.findInitialVersion(ctx, file.Path)
-	if err != nil || initialVersion.ID == "" {
-		return
-	}
+// fileEventMsg is a change to a file received on a sidebar's subscription
+type fileEventMsg struct {
+	filesCh <-chan pubsub.Event[history.File]
+	event   pubsub.Event[history.File]
+}
 
-	displayPath := getDisplayPath(file.Path)
-	
-	if initialVersion.Content == file.Content {
-		delete(m.modFiles, displayPath)
-		return
+// Close ends the subscription to file events, once the sidebar is closed
+// or replaced
+func (m *ModularSidebar) Close() {
+	if m.cancelFiles != nil {
+		m.cancelFiles()
+		m.cancelFiles = nil
 	}
+}
 
-	_, additions, removals := diff.GenerateDiff(initialVersion.Content, file.Content, file.Path)
-
-	if additions > 0 || removals > 0 {
-		m.modFiles[displayPath] = struct {
-			additions int
-			removals  int
-		}{
-			additions: additions,
-			removals:  removals,
+// nextFileEvent waits for the next change to a file
+func (m *ModularSidebar) nextFileEvent() tea.Cmd {
+	filesCh := m.filesCh
+	return func() tea.Msg {
+		event, ok := <-filesCh
+		if !ok {
+			return nil
 		}
-	} else {
-		delete(m.modFiles, displayPath)
+		return fileEventMsg{filesCh: filesCh, event: event}
 	}
 }
 
-func (m *ModularSidebar) findInitialVersion(ctx context.Context, path string) (history.File, error) {
-	fileVersions, err := m.history.ListBySession(ctx, m.session.ID)
-	if err != nil {
-		return history.File{}, err
+// setModified shows the diff stats of a file, or hides a file without
+// changes
+func (m *ModularSidebar) setModified(path string, stats diffStats) {
+	displayPath := getDisplayPath(path)
+	if stats.additions == 0 && stats.removals == 0 {
+		delete(m.modFiles, displayPath)
+		return
 	}
-
-	for _, v := range fileVersions {
-		if v.Path == path && v.Version == history.InitialVersion {
-			return v, nil
-		}
+	m.modFiles[displayPath] = struct {
+		additions int
+		removals  int
+	}{
+		additions: stats.additions,
+		removals:  stats.removals,
 	}
-
-	return history.File{}, fmt.Errorf("initial version not found")
 }
 
 func getDisplayPath(path string) string {
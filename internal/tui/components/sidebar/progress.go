@@ -5,15 +5,31 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/llm/agent"
+	"github.com/opencode-ai/opencode/internal/pubsub"
+	"github.com/opencode-ai/opencode/internal/swarm"
 	"github.com/opencode-ai/opencode/internal/tui/styles"
 )
 
 // ProgressWidget displays current AI operations and progress
 type ProgressWidget struct {
 	BaseWidget
+	sessionID   string
 	isBusy      bool
 	currentTask string
 	progress    float64
+
+	phase          agent.ProgressPhase
+	tokens         int
+	toolCallsDone  int
+	toolCallsTotal int
+
+	// The swarm task that last reported progress, until it finishes
+	swarmTask    string
+	swarmStage   string
+	swarmLine    string
+	swarmPercent float64
 }
 
 func NewProgressWidget() Widget {
@@ -29,10 +45,60 @@ func (w *ProgressWidget) Init() tea.Cmd {
 }
 
 func (w *ProgressWidget) Update(msg tea.Msg) (Widget, tea.Cmd) {
-	// TODO: Handle progress update messages
+	switch msg := msg.(type) {
+	case pubsub.Event[agent.ProgressEvent]:
+		w.handleProgress(msg.Payload)
+	case pubsub.Event[swarm.TimelineEvent]:
+		w.handleSwarmEvent(msg.Payload)
+	}
 	return w, nil
 }
 
+// handleSwarmEvent follows the progress swarm agents stream on their tasks
+func (w *ProgressWidget) handleSwarmEvent(event swarm.TimelineEvent) {
+	switch event.Type {
+	case swarm.TimelineTaskProgress:
+		if event.Subject != w.swarmTask {
+			w.swarmTask = event.Subject
+			w.swarmStage, w.swarmLine, w.swarmPercent = "", "", 0
+		}
+		if stage, ok := event.Details["stage"].(string); ok {
+			w.swarmStage = stage
+		}
+		if percent, ok := event.Details["percent"].(float64); ok {
+			w.swarmPercent = percent / 100
+		}
+		if event.Summary != "" {
+			w.swarmLine = event.Summary
+		}
+	case swarm.TimelineTaskFinished:
+		if event.Subject == w.swarmTask {
+			w.swarmTask = ""
+		}
+	}
+}
+
+// handleProgress applies an agent progress event to the widget state
+func (w *ProgressWidget) handleProgress(event agent.ProgressEvent) {
+	if w.sessionID != "" && event.SessionID != w.sessionID {
+		return
+	}
+
+	w.phase = event.Phase
+	w.tokens = event.TokensStreamed
+	w.toolCallsDone = event.ToolCallsDone
+	w.toolCallsTotal = event.ToolCallsTotal
+
+	if event.Done() {
+		w.SetBusy(false, event.Message)
+		w.SetProgress(0)
+		return
+	}
+
+	w.SetBusy(true, event.Message)
+	w.SetProgress(event.Progress)
+}
+
 func (w *ProgressWidget) View() string {
 	if w.collapsed {
 		return ""
@@ -40,20 +106,33 @@ func (w *ProgressWidget) View() string {
 
 	content := ""
 	if w.isBusy {
-		status := styles.BaseStyle.Foreground(styles.PrimaryColor).Render("● Active")
+		status := styles.BaseStyle.Foreground(styles.PrimaryColor).Render("● " + w.statusText())
 		if w.currentTask != "" {
 			task := styles.BaseStyle.Foreground(styles.Forground).Render(fmt.Sprintf("\n  %s", w.currentTask))
 			content = lipgloss.JoinVertical(lipgloss.Left, status, task)
 		} else {
 			content = status
 		}
-		
+
 		if w.progress > 0 && w.progress < 1 {
 			progressBar := renderProgressBar(w.width-4, w.progress)
 			content = lipgloss.JoinVertical(lipgloss.Left, content, progressBar)
 		}
 	} else {
-		content = styles.BaseStyle.Foreground(styles.ForgroundDim).Render("○ Idle")
+		idle := "○ Idle"
+		switch w.phase {
+		case agent.ProgressPhaseCancelled:
+			idle = "○ Cancelled"
+		case agent.ProgressPhaseFailed:
+			idle = "○ Failed"
+		}
+		if w.tokens > 0 {
+			idle = fmt.Sprintf("%s • last: %s tokens", idle, formatTokens(w.tokens))
+		}
+		content = styles.BaseStyle.Foreground(styles.ForgroundDim).Render(idle)
+	}
+	if w.swarmTask != "" {
+		content = lipgloss.JoinVertical(lipgloss.Left, content, w.swarmView())
 	}
 
 	return styles.BaseStyle.
@@ -65,13 +144,35 @@ func (w *ProgressWidget) GetHeight() int {
 	if w.collapsed {
 		return 0
 	}
+	height := 1 // Just status
 	if w.isBusy && w.currentTask != "" {
+		height = 2 // Status + task
 		if w.progress > 0 && w.progress < 1 {
-			return 3 // Status + task + progress bar
+			height = 3 // Status + task + progress bar
 		}
-		return 2 // Status + task
 	}
-	return 1 // Just status
+	if w.swarmTask != "" {
+		height += 2 // Swarm task + its last line or progress bar
+	}
+	return height
+}
+
+// swarmView renders the progress of the swarm task
+func (w *ProgressWidget) swarmView() string {
+	status := "Swarm task"
+	if w.swarmStage != "" {
+		status += ": " + w.swarmStage
+	}
+	if w.swarmPercent > 0 {
+		status += fmt.Sprintf(" %.0f%%", w.swarmPercent*100)
+	}
+	status = styles.BaseStyle.Foreground(styles.PrimaryColor).Render("● " + status)
+
+	detail := styles.BaseStyle.Foreground(styles.ForgroundDim).Render("  " + ansi.Truncate(w.swarmLine, max(w.width-2, 0), "…"))
+	if w.swarmPercent > 0 && w.swarmPercent < 1 {
+		detail = renderProgressBar(w.width-4, w.swarmPercent)
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, status, detail)
 }
 
 func (w *ProgressWidget) SetBusy(busy bool, task string) {
@@ -83,14 +184,40 @@ func (w *ProgressWidget) SetProgress(progress float64) {
 	w.progress = progress
 }
 
+// SetSession limits the widget to progress events for the given session.
+// An empty ID accepts events from every session.
+func (w *ProgressWidget) SetSession(sessionID string) {
+	w.sessionID = sessionID
+}
+
+// statusText describes the current phase for the status line
+func (w *ProgressWidget) statusText() string {
+	switch w.phase {
+	case agent.ProgressPhaseThinking:
+		return fmt.Sprintf("Thinking (%s tokens)", formatTokens(w.tokens))
+	case agent.ProgressPhaseStreaming:
+		return fmt.Sprintf("Streaming (%s tokens)", formatTokens(w.tokens))
+	case agent.ProgressPhaseToolCall:
+		return fmt.Sprintf("Tools %d/%d", w.toolCallsDone+1, w.toolCallsTotal)
+	}
+	return "Active"
+}
+
+func formatTokens(tokens int) string {
+	if tokens >= 1000 {
+		return fmt.Sprintf("%.1fk", float64(tokens)/1000)
+	}
+	return fmt.Sprintf("%d", tokens)
+}
+
 func renderProgressBar(width int, progress float64) string {
 	if width < 4 {
 		return ""
 	}
-	
+
 	filled := int(float64(width-2) * progress)
 	empty := width - 2 - filled
-	
+
 	bar := "["
 	for i := 0; i < filled; i++ {
 		bar += "="
@@ -99,6 +226,6 @@ func renderProgressBar(width int, progress float64) string {
 		bar += " "
 	}
 	bar += "]"
-	
+
 	return styles.BaseStyle.Foreground(styles.PrimaryColor).Render(bar)
 }
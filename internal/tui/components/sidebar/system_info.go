@@ -3,17 +3,53 @@ package sidebar
 import (
 	"fmt"
 	"runtime"
+	"sync/atomic"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/tui/components/chart"
 	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/shirou/gopsutil/v4/cpu"
+	"github.com/shirou/gopsutil/v4/disk"
 )
 
+const (
+	// systemInfoInterval is how often the widget refreshes its stats
+	systemInfoInterval = 2 * time.Second
+	// systemInfoHistory is the number of CPU samples kept for the sparkline
+	systemInfoHistory = 30
+)
+
+var lastSystemInfoID int64
+
+// systemInfoTickMsg carries freshly collected stats to the widget that
+// scheduled the tick
+type systemInfoTickMsg struct {
+	id    int
+	stats systemStats
+}
+
+// systemStats is a snapshot of process and host statistics
+type systemStats struct {
+	memStats      runtime.MemStats
+	numGoroutines int
+
+	cpuPercent float64
+	cpuOK      bool
+
+	diskUsed  uint64
+	diskTotal uint64
+	diskOK    bool
+}
+
 // SystemInfoWidget displays system information and statistics
 type SystemInfoWidget struct {
 	BaseWidget
-	memStats    runtime.MemStats
-	numGoroutines int
+	id             int
+	stats          systemStats
+	cpuHistory     []float64
 	lspConnections int
 }
 
@@ -22,44 +58,98 @@ func NewSystemInfoWidget() Widget {
 		BaseWidget: BaseWidget{
 			title: "System Info",
 		},
+		id: int(atomic.AddInt64(&lastSystemInfoID, 1)),
 	}
 }
 
 func (w *SystemInfoWidget) Init() tea.Cmd {
-	w.updateStats()
-	return nil
+	w.stats = collectSystemStats()
+	return w.tick()
 }
 
 func (w *SystemInfoWidget) Update(msg tea.Msg) (Widget, tea.Cmd) {
-	// Update stats periodically
-	w.updateStats()
+	switch msg := msg.(type) {
+	case systemInfoTickMsg:
+		// Ignore ticks scheduled by other instances, e.g. the sidebar of a
+		// previous session, so only one refresh loop is active per widget
+		if msg.id != w.id {
+			return w, nil
+		}
+		w.stats = msg.stats
+		if msg.stats.cpuOK {
+			w.cpuHistory = append(w.cpuHistory, msg.stats.cpuPercent)
+			if len(w.cpuHistory) > systemInfoHistory {
+				w.cpuHistory = w.cpuHistory[len(w.cpuHistory)-systemInfoHistory:]
+			}
+		}
+		return w, w.tick()
+	}
 	return w, nil
 }
 
+// tick schedules the next stats collection. Stats are gathered inside the
+// command so the UI goroutine never blocks on host queries.
+func (w *SystemInfoWidget) tick() tea.Cmd {
+	id := w.id
+	return tea.Tick(systemInfoInterval, func(time.Time) tea.Msg {
+		return systemInfoTickMsg{id: id, stats: collectSystemStats()}
+	})
+}
+
 func (w *SystemInfoWidget) View() string {
 	if w.collapsed {
 		return ""
 	}
 
 	var lines []string
-	
+	text := styles.BaseStyle.Foreground(styles.Forground)
+
+	// CPU usage with history
+	if w.stats.cpuOK {
+		cpuLine := fmt.Sprintf("CPU: %5.1f%%", w.stats.cpuPercent)
+		if spark := chart.Sparkline(w.cpuHistory, chart.Range{Max: 100}, w.width-len(cpuLine)-1); spark != "" {
+			cpuLine = lipgloss.JoinHorizontal(
+				lipgloss.Left,
+				text.Render(cpuLine+" "),
+				styles.BaseStyle.Foreground(styles.PrimaryColor).Render(spark),
+			)
+			lines = append(lines, cpuLine)
+		} else {
+			lines = append(lines, text.Render(cpuLine))
+		}
+	}
+
 	// Memory usage
-	memMB := float64(w.memStats.Alloc) / 1024 / 1024
+	memMB := float64(w.stats.memStats.Alloc) / 1024 / 1024
 	memLine := fmt.Sprintf("Memory: %.1f MB", memMB)
-	lines = append(lines, styles.BaseStyle.Foreground(styles.Forground).Render(memLine))
-	
+	lines = append(lines, text.Render(memLine))
+
+	// Garbage collector
+	gcLine := fmt.Sprintf("GC: %d runs", w.stats.memStats.NumGC)
+	if w.stats.memStats.NumGC > 0 {
+		lastPause := time.Duration(w.stats.memStats.PauseNs[(w.stats.memStats.NumGC+255)%256])
+		gcLine = fmt.Sprintf("%s • last pause %s", gcLine, lastPause.Round(time.Microsecond))
+	}
+	lines = append(lines, styles.BaseStyle.Foreground(styles.ForgroundDim).Render(gcLine))
+
 	// Goroutines
-	goroutinesLine := fmt.Sprintf("Goroutines: %d", w.numGoroutines)
-	lines = append(lines, styles.BaseStyle.Foreground(styles.Forground).Render(goroutinesLine))
-	
+	goroutinesLine := fmt.Sprintf("Goroutines: %d", w.stats.numGoroutines)
+	lines = append(lines, text.Render(goroutinesLine))
+
+	// Disk usage of the working directory's filesystem
+	if w.stats.diskOK {
+		diskLine := fmt.Sprintf("Disk: %s / %s", formatBytes(w.stats.diskUsed), formatBytes(w.stats.diskTotal))
+		lines = append(lines, text.Render(diskLine))
+	}
+
 	// LSP connections
 	if w.lspConnections > 0 {
 		lspLine := fmt.Sprintf("LSP Servers: %d", w.lspConnections)
 		lines = append(lines, styles.BaseStyle.Foreground(styles.PrimaryColor).Render(lspLine))
 	}
-	
+
 	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
-	
+
 	return styles.BaseStyle.
 		Width(w.width).
 		Render(content)
@@ -69,19 +159,55 @@ func (w *SystemInfoWidget) GetHeight() int {
 	if w.collapsed {
 		return 0
 	}
-	
-	height := 2 // Memory + Goroutines
+
+	height := 3 // Memory + GC + Goroutines
+	if w.stats.cpuOK {
+		height++
+	}
+	if w.stats.diskOK {
+		height++
+	}
 	if w.lspConnections > 0 {
 		height++ // LSP connections
 	}
 	return height
 }
 
-func (w *SystemInfoWidget) updateStats() {
-	runtime.ReadMemStats(&w.memStats)
-	w.numGoroutines = runtime.NumGoroutine()
-}
-
 func (w *SystemInfoWidget) SetLSPConnections(count int) {
 	w.lspConnections = count
 }
+
+// collectSystemStats gathers runtime, CPU and disk statistics. Host stats
+// that are unavailable on the platform are left out of the view.
+func collectSystemStats() systemStats {
+	var stats systemStats
+	runtime.ReadMemStats(&stats.memStats)
+	stats.numGoroutines = runtime.NumGoroutine()
+
+	// An interval of 0 compares against the previous call, so it never blocks
+	if percents, err := cpu.Percent(0, false); err == nil && len(percents) > 0 {
+		stats.cpuPercent = percents[0]
+		stats.cpuOK = true
+	}
+
+	if usage, err := disk.Usage(config.WorkingDirectory()); err == nil {
+		stats.diskUsed = usage.Used
+		stats.diskTotal = usage.Total
+		stats.diskOK = true
+	}
+
+	return stats
+}
+
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -1,6 +1,10 @@
 package spinner
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -111,13 +115,17 @@ func (m *InlineSpinner) View() string {
 	return m.spinner.View()
 }
 
-// ProgressIndicator shows progress with a message and percentage
+// ProgressIndicator shows progress with a message, percentage, elapsed time
+// and an ETA estimated from the progress rate
 type ProgressIndicator struct {
-	current  int
-	total    int
-	message  string
-	spinner  spinner.Model
+	current     int
+	total       int
+	message     string
+	spinner     spinner.Model
 	showSpinner bool
+	bar         progress.Model
+	startedAt   time.Time
+	finishedAt  time.Time
 }
 
 // NewProgressIndicator creates a new progress indicator
@@ -125,13 +133,21 @@ func NewProgressIndicator(total int) *ProgressIndicator {
 	s := spinner.New()
 	s.Spinner = spinner.Points
 	s.Style = lipgloss.NewStyle().Foreground(styles.PrimaryColor)
-	
+
+	bar := progress.New(
+		progress.WithGradient(string(styles.Peach.Dark), string(styles.Mauve.Dark)),
+		progress.WithWidth(20),
+		progress.WithoutPercentage(),
+	)
+
 	return &ProgressIndicator{
 		current:     0,
 		total:       total,
 		message:     "",
 		spinner:     s,
 		showSpinner: true,
+		bar:         bar,
+		startedAt:   time.Now(),
 	}
 }
 
@@ -139,12 +155,14 @@ func NewProgressIndicator(total int) *ProgressIndicator {
 func (m *ProgressIndicator) SetProgress(current int, message string) {
 	m.current = current
 	m.message = message
+	m.markFinished()
 }
 
 // Increment increments the progress by 1
 func (m *ProgressIndicator) Increment(message string) {
 	m.current++
 	m.message = message
+	m.markFinished()
 }
 
 // SetTotal sets the total number of items
@@ -152,12 +170,29 @@ func (m *ProgressIndicator) SetTotal(total int) {
 	m.total = total
 }
 
+// SetBarWidth sets the width of the progress bar
+func (m *ProgressIndicator) SetBarWidth(width int) {
+	m.bar.Width = width
+}
+
+// Reset clears progress and restarts timing so the indicator can be reused
+func (m *ProgressIndicator) Reset() {
+	m.current = 0
+	m.message = ""
+	m.startedAt = time.Now()
+	m.finishedAt = time.Time{}
+}
+
 // GetPercentage returns the completion percentage
 func (m *ProgressIndicator) GetPercentage() int {
 	if m.total == 0 {
 		return 0
 	}
-	return (m.current * 100) / m.total
+	percentage := (m.current * 100) / m.total
+	if percentage > 100 {
+		percentage = 100
+	}
+	return percentage
 }
 
 // IsComplete returns whether the progress is complete
@@ -165,6 +200,32 @@ func (m *ProgressIndicator) IsComplete() bool {
 	return m.current >= m.total
 }
 
+// Elapsed returns the time since the indicator was created or reset, up
+// to when it completed
+func (m *ProgressIndicator) Elapsed() time.Duration {
+	if m.startedAt.IsZero() {
+		return 0
+	}
+	if !m.finishedAt.IsZero() {
+		return m.finishedAt.Sub(m.startedAt)
+	}
+	return time.Since(m.startedAt)
+}
+
+// ETA estimates the remaining time from the average progress rate so far.
+// It returns false when there is not enough progress to estimate.
+func (m *ProgressIndicator) ETA() (time.Duration, bool) {
+	if m.total == 0 || m.current <= 0 || m.IsComplete() {
+		return 0, false
+	}
+	elapsed := m.Elapsed()
+	if elapsed <= 0 {
+		return 0, false
+	}
+	perItem := elapsed / time.Duration(m.current)
+	return perItem * time.Duration(m.total-m.current), true
+}
+
 // Init implements tea.Model
 func (m *ProgressIndicator) Init() tea.Cmd {
 	if m.showSpinner {
@@ -178,7 +239,7 @@ func (m *ProgressIndicator) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if !m.showSpinner || m.IsComplete() {
 		return m, nil
 	}
-	
+
 	var cmd tea.Cmd
 	m.spinner, cmd = m.spinner.Update(msg)
 	return m, cmd
@@ -187,46 +248,64 @@ func (m *ProgressIndicator) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // View implements tea.Model
 func (m *ProgressIndicator) View() string {
 	percentage := m.GetPercentage()
-	
-	progressBar := renderProgressBar(percentage, 20)
-	percentText := lipgloss.NewStyle().Render(string(rune(percentage)) + "%")
-	
+
 	var spinnerView string
 	if m.showSpinner && !m.IsComplete() {
 		spinnerView = m.spinner.View() + " "
 	}
-	
-	percentStr := lipgloss.NewStyle().
+
+	percentText := lipgloss.NewStyle().
 		Foreground(styles.PrimaryColor).
 		Bold(true).
-		Render(spinnerView + progressBar + " " + percentText)
-	
+		Render(fmt.Sprintf("%3d%%", percentage))
+
+	line := spinnerView + m.bar.ViewAs(float64(percentage)/100) + " " + percentText
+
+	if timing := m.timingText(); timing != "" {
+		line += styles.BaseStyle.Foreground(styles.ForgroundDim).Render(" " + timing)
+	}
+
 	if m.message != "" {
 		return lipgloss.JoinHorizontal(
 			lipgloss.Left,
-			percentStr,
+			line,
 			" ",
 			styles.BaseStyle.Foreground(styles.Forground).Render(m.message),
 		)
 	}
-	
-	return percentStr
+
+	return line
 }
 
-// renderProgressBar renders a simple text-based progress bar
-func renderProgressBar(percentage int, width int) string {
-	filled := (percentage * width) / 100
-	empty := width - filled
-	
-	bar := ""
-	for i := 0; i < filled; i++ {
-		bar += "█"
+// timingText renders the elapsed time and, while running, the ETA
+func (m *ProgressIndicator) timingText() string {
+	elapsed := m.Elapsed()
+	if elapsed <= 0 {
+		return ""
 	}
-	for i := 0; i < empty; i++ {
-		bar += "░"
+	text := formatDuration(elapsed)
+	if eta, ok := m.ETA(); ok {
+		text += " • ~" + formatDuration(eta) + " left"
 	}
-	
-	return lipgloss.NewStyle().
-		Foreground(styles.PrimaryColor).
-		Render(bar)
+	return text
+}
+
+func (m *ProgressIndicator) markFinished() {
+	if m.IsComplete() && m.finishedAt.IsZero() {
+		m.finishedAt = time.Now()
+	} else if !m.IsComplete() {
+		m.finishedAt = time.Time{}
+	}
+}
+
+// formatDuration renders a duration compactly, e.g. "45s" or "3m12s"
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
 }
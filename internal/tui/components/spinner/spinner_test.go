@@ -0,0 +1,37 @@
+package spinner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProgressETA(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int
+		current int
+		elapsed time.Duration
+		want    time.Duration
+		ok      bool
+	}{
+		{name: "no progress", total: 4, current: 0, elapsed: 10 * time.Second, ok: false},
+		{name: "half done", total: 4, current: 2, elapsed: 10 * time.Second, want: 10 * time.Second, ok: true},
+		{name: "quarter done", total: 4, current: 1, elapsed: 3 * time.Second, want: 9 * time.Second, ok: true},
+		{name: "complete", total: 4, current: 4, elapsed: 10 * time.Second, ok: false},
+		{name: "no total", total: 0, current: 2, elapsed: 10 * time.Second, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewProgressIndicator(tt.total)
+			// Timing starts when the indicator is created, not at the
+			// first update
+			m.startedAt = time.Now().Add(-tt.elapsed)
+			m.SetProgress(tt.current, "")
+
+			got, ok := m.ETA()
+			if ok != tt.ok || got.Round(time.Second) != tt.want {
+				t.Errorf("ETA() = %v, %v, want %v, %v", got.Round(time.Second), ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package swarmhealth
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	bubbletable "github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/swarm/health"
+	"github.com/opencode-ai/opencode/internal/tui/components/chart"
+	"github.com/opencode-ai/opencode/internal/tui/components/table"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+const (
+	// refreshInterval is how often the dashboard is reloaded
+	refreshInterval = 2 * time.Second
+	// recentAlerts is how many of the latest alerts are listed
+	recentAlerts = 10
+	// historySize is how many refreshes the trends span, ten minutes
+	historySize = 300
+	// trendHeight is the rows of the chart of the overall score
+	trendHeight = 4
+	// trendWidth is the width of the trend column of the components
+	trendWidth = 12
+)
+
+// HealthSource is the part of the swarm the dashboard needs
+type HealthSource interface {
+	Health() swarm.SwarmHealth
+	Timeline(filter swarm.TimelineFilter) []swarm.TimelineEvent
+}
+
+// Dashboard shows the health of the swarm: its overall score, the checks of
+// its components and the latest alerts
+type Dashboard struct {
+	source HealthSource
+	table  *table.DataTable
+	width  int
+	height int
+
+	health swarm.SwarmHealth
+	alerts []swarm.TimelineEvent
+
+	// history holds the overall score in percent at every refresh, and
+	// components the scores of each component, oldest first
+	history    []float64
+	components map[string][]float64
+
+	refresher *util.Refresher
+}
+
+// NewDashboard creates a health dashboard of source
+func NewDashboard(source HealthSource) *Dashboard {
+	m := &Dashboard{
+		source:     source,
+		table:      table.NewDataTable(columns(40), nil),
+		components: make(map[string][]float64),
+		refresher:  util.NewRefresher(refreshInterval),
+	}
+	m.refresh()
+	return m
+}
+
+func columns(messageWidth int) []bubbletable.Column {
+	return []bubbletable.Column{
+		{Title: "Component", Width: 20},
+		{Title: "Status", Width: 10},
+		{Title: "Score", Width: 5},
+		{Title: "Checked", Width: 9},
+		{Title: "Trend", Width: trendWidth},
+		{Title: "Message", Width: messageWidth},
+	}
+}
+
+// Open reloads the dashboard and keeps it up to date while shown
+func (m *Dashboard) Open() tea.Cmd {
+	m.refresh()
+	return m.refresher.Start()
+}
+
+// Capturing returns whether the row filter is focused
+func (m *Dashboard) Capturing() bool {
+	return m.table.IsFiltering()
+}
+
+// Init implements tea.Model
+func (m *Dashboard) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *Dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case util.RefreshMsg:
+		if !m.refresher.Due(msg) {
+			return m, nil
+		}
+		m.refresh()
+		return m, m.refresher.Next()
+	case tea.KeyMsg:
+		if !m.table.IsFiltering() && msg.String() == "r" {
+			m.refresh()
+			return m, nil
+		}
+	}
+
+	_, cmd := m.table.Update(msg)
+	return m, cmd
+}
+
+// refresh reloads the health of the swarm and its latest alerts
+func (m *Dashboard) refresh() {
+	if m.source == nil {
+		m.table.SetRows(nil)
+		return
+	}
+
+	m.health = m.source.Health()
+	m.history = record(m.history, m.health.OverallScore*100)
+	rows := make([]bubbletable.Row, 0, len(m.health.Checks))
+	for _, check := range m.health.Checks {
+		m.components[check.ComponentID] = record(m.components[check.ComponentID], check.Score*100)
+		rows = append(rows, bubbletable.Row{
+			check.ComponentID,
+			string(check.Status),
+			fmt.Sprintf("%.0f%%", check.Score*100),
+			check.Timestamp.Format("15:04:05"),
+			chart.Sparkline(m.components[check.ComponentID], chart.Range{Max: 100}, trendWidth),
+			check.Message,
+		})
+	}
+	m.table.SetRows(rows)
+
+	m.alerts = m.source.Timeline(swarm.TimelineFilter{Types: []swarm.TimelineEventType{swarm.TimelineAlert}})
+	if len(m.alerts) > recentAlerts {
+		m.alerts = m.alerts[len(m.alerts)-recentAlerts:]
+	}
+}
+
+// record appends a sample to a history, dropping the oldest beyond
+// historySize
+func record(history []float64, value float64) []float64 {
+	history = append(history, value)
+	if len(history) > historySize {
+		history = history[len(history)-historySize:]
+	}
+	return history
+}
+
+// statusColor is the color of a health status
+func statusColor(status health.HealthStatus) lipgloss.AdaptiveColor {
+	switch status {
+	case health.HealthStatusDegraded:
+		return styles.Warning
+	case health.HealthStatusUnhealthy, health.HealthStatusCritical:
+		return styles.Error
+	}
+	return styles.Green
+}
+
+// View implements tea.Model
+func (m *Dashboard) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Swarm Health")
+
+	status := "No swarm is running"
+	if m.source != nil {
+		h := m.health
+		status = styles.BaseStyle.Foreground(statusColor(h.OverallStatus)).Bold(true).
+			Render(fmt.Sprintf("%s %.0f%%", h.OverallStatus, h.OverallScore*100)) +
+			styles.BaseStyle.Foreground(styles.ForgroundMid).
+				Render(fmt.Sprintf(" • %d components: %d healthy, %d degraded, %d unhealthy, %d critical • %d critical alerts",
+					h.ComponentCount, h.HealthyCount, h.DegradedCount, h.UnhealthyCount, h.CriticalCount, h.CriticalAlerts))
+	}
+
+	help := "r: refresh • /: filter components"
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		status,
+		styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help),
+		"",
+		m.trend(),
+		"",
+		m.table.View(),
+		"",
+		m.alertList(),
+	)
+}
+
+// trend charts the overall score since the dashboard was first opened
+func (m *Dashboard) trend() string {
+	if len(m.history) < 2 {
+		return styles.BaseStyle.Foreground(styles.ForgroundDim).Render("Collecting the health trend…")
+	}
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	percent := func(v float64) string { return fmt.Sprintf("%.0f%%", v) }
+	return chart.LineChart(m.history, chart.Range{Max: 100}, width, trendHeight, percent)
+}
+
+// alertList renders the latest alerts, newest first
+func (m *Dashboard) alertList() string {
+	if len(m.alerts) == 0 {
+		return styles.BaseStyle.Foreground(styles.ForgroundDim).Render("No alerts raised")
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	lines := []string{styles.BaseStyle.Bold(true).Render("Recent alerts")}
+	available := m.height - m.tableHeight() - trendHeight - 7
+	for i := len(m.alerts) - 1; i >= 0 && len(lines) <= available; i-- {
+		alert := m.alerts[i]
+		severity, _ := alert.Details["severity"].(string)
+		color := styles.ForgroundMid
+		switch severity {
+		case string(health.AlertSeverityCritical), string(health.AlertSeverityError):
+			color = styles.Error
+		case string(health.AlertSeverityWarning):
+			color = styles.Warning
+		}
+		summary := strings.Join(strings.Fields(alert.Summary), " ")
+		line := fmt.Sprintf("%s %-8s %s: %s", alert.Timestamp.Format("15:04:05"), severity, alert.Subject, summary)
+		lines = append(lines, styles.BaseStyle.Foreground(color).Render(ansi.Truncate(line, width, "…")))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// tableHeight gives the components half of what the trend leaves, the
+// alerts the rest
+func (m *Dashboard) tableHeight() int {
+	height := (m.height - trendHeight - 7) / 2
+	if height < 5 {
+		height = 5
+	}
+	return height
+}
+
+// SetSize sets the size of the dashboard
+func (m *Dashboard) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	messageWidth := width - 20 - 10 - 5 - 9 - trendWidth - 12
+	if messageWidth < 20 {
+		messageWidth = 20
+	}
+	m.table.SetColumns(columns(messageWidth))
+	m.table.SetSize(width, m.tableHeight())
+}
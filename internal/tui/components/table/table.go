@@ -1,17 +1,75 @@
 package table
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
 	"github.com/opencode-ai/opencode/internal/tui/styles"
 )
 
-// DataTable is a wrapper around bubbles table with custom styling
+// selectionColumnWidth is the width of the marker column shown in multi-select mode
+const selectionColumnWidth = 3
+
+// headerHeight is the number of lines taken by the header and its border
+const headerHeight = 2
+
+// RowSelectedMsg is sent when the user presses enter on a row
+type RowSelectedMsg struct {
+	Row table.Row
+}
+
+// SelectionChangedMsg is sent whenever the set of marked rows changes in
+// multi-select mode
+type SelectionChangedMsg struct {
+	Rows []table.Row
+}
+
+// SortChangedMsg is sent when the sort column or direction changes
+type SortChangedMsg struct {
+	Column     int
+	Descending bool
+}
+
+// DataTable is a wrapper around bubbles table with custom styling, column
+// sorting, row filtering, multi-select and horizontal scrolling
 type DataTable struct {
 	table  table.Model
 	width  int
 	height int
+
+	columns []table.Column
+	allRows []table.Row
+	rows    []table.Row // filtered and sorted view of allRows
+
+	// Sorting (-1 means unsorted)
+	sortColumn int
+	sortDesc   bool
+
+	// Filtering
+	filter    textinput.Model
+	filtering bool
+
+	// Multi-select, keyed by the value in keyColumn
+	multiSelect bool
+	keyColumn   int
+	selected    map[string]bool
+
+	// Horizontal scrolling
+	colOffset int
+	// Source column index of each rendered column, -1 for the marker column
+	visibleColumns []int
+
+	// Mouse zone of the rendered table
+	zoneID string
 }
 
 // NewDataTable creates a new data table
@@ -23,7 +81,27 @@ func NewDataTable(columns []table.Column, rows []table.Row) *DataTable {
 		table.WithHeight(10),
 	)
 
-	// Custom styling
+	filter := textinput.New()
+	filter.Prompt = "/"
+	filter.Placeholder = "filter rows"
+
+	m := &DataTable{
+		table:      t,
+		columns:    columns,
+		allRows:    rows,
+		sortColumn: -1,
+		filter:     filter,
+		selected:   make(map[string]bool),
+		zoneID:     zone.NewPrefix() + "table",
+	}
+	m.applyStyles()
+	m.refresh()
+
+	return m
+}
+
+// applyStyles sets the table styles from the active theme colors
+func (m *DataTable) applyStyles() {
 	s := table.DefaultStyles()
 	s.Header = s.Header.
 		BorderStyle(lipgloss.NormalBorder()).
@@ -34,27 +112,95 @@ func NewDataTable(columns []table.Column, rows []table.Row) *DataTable {
 		Foreground(styles.Forground).
 		Background(styles.PrimaryColor).
 		Bold(false)
-	
-	t.SetStyles(s)
 
-	return &DataTable{
-		table: t,
-	}
+	m.table.SetStyles(s)
 }
 
 // SetRows updates the table rows
 func (m *DataTable) SetRows(rows []table.Row) {
-	m.table.SetRows(rows)
+	m.allRows = rows
+	m.refresh()
 }
 
 // SetColumns updates the table columns
 func (m *DataTable) SetColumns(columns []table.Column) {
-	m.table.SetColumns(columns)
+	m.columns = columns
+	if m.sortColumn >= len(columns) {
+		m.sortColumn = -1
+	}
+	if m.colOffset >= len(columns) {
+		m.colOffset = 0
+	}
+	m.refresh()
 }
 
-// SelectedRow returns the currently selected row
+// SelectedRow returns the row under the cursor
 func (m *DataTable) SelectedRow() table.Row {
-	return m.table.SelectedRow()
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.rows) {
+		return nil
+	}
+	return m.rows[cursor]
+}
+
+// SetMultiSelect enables or disables marking multiple rows. Rows are
+// identified by the value in keyColumn, so it should be unique.
+func (m *DataTable) SetMultiSelect(enabled bool, keyColumn int) {
+	m.multiSelect = enabled
+	m.keyColumn = keyColumn
+	if !enabled {
+		m.selected = make(map[string]bool)
+	}
+	m.refresh()
+}
+
+// SelectedRows returns all marked rows in display order
+func (m *DataTable) SelectedRows() []table.Row {
+	var rows []table.Row
+	for _, row := range m.allRows {
+		if m.selected[m.rowKey(row)] {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// ClearSelection unmarks all rows
+func (m *DataTable) ClearSelection() {
+	m.selected = make(map[string]bool)
+	m.refresh()
+}
+
+// SortBy sorts the table by the given column index. A negative column
+// restores the original row order.
+func (m *DataTable) SortBy(column int, descending bool) {
+	if column >= len(m.columns) {
+		return
+	}
+	m.sortColumn = column
+	m.sortDesc = descending
+	m.refresh()
+}
+
+// SortColumn returns the current sort column and direction
+func (m *DataTable) SortColumn() (int, bool) {
+	return m.sortColumn, m.sortDesc
+}
+
+// SetFilter filters rows to those containing the text in any cell
+func (m *DataTable) SetFilter(text string) {
+	m.filter.SetValue(text)
+	m.refresh()
+}
+
+// Filter returns the current filter text
+func (m *DataTable) Filter() string {
+	return m.filter.Value()
+}
+
+// IsFiltering returns whether the filter input has focus
+func (m *DataTable) IsFiltering() bool {
+	return m.filtering
 }
 
 // Init implements tea.Model
@@ -65,46 +211,145 @@ func (m *DataTable) Init() tea.Cmd {
 // Update implements tea.Model
 func (m *DataTable) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
-	
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
+
+	if _, ok := msg.(styles.ThemeChangedMsg); ok {
+		m.applyStyles()
+		return m, nil
+	}
+
+	if mouseMsg, ok := msg.(tea.MouseMsg); ok {
+		return m, m.handleMouse(mouseMsg)
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if m.filtering {
+			return m, m.updateFilter(keyMsg)
+		}
+
+		switch keyMsg.String() {
 		case "q", "esc":
 			return m, nil
+		case "/":
+			m.filtering = true
+			return m, m.filter.Focus()
+		case "s":
+			return m, m.cycleSort()
+		case "S":
+			if m.sortColumn < 0 {
+				return m, nil
+			}
+			m.sortDesc = !m.sortDesc
+			m.refresh()
+			return m, m.sortChanged()
+		case "left", "h":
+			if m.colOffset > 0 {
+				m.colOffset--
+				m.refresh()
+			}
+			return m, nil
+		case "right", "l":
+			if m.colOffset < len(m.columns)-1 {
+				m.colOffset++
+				m.refresh()
+			}
+			return m, nil
+		case " ":
+			if !m.multiSelect {
+				break
+			}
+			row := m.SelectedRow()
+			if row == nil {
+				return m, nil
+			}
+			key := m.rowKey(row)
+			if m.selected[key] {
+				delete(m.selected, key)
+			} else {
+				m.selected[key] = true
+			}
+			m.refresh()
+			return m, m.selectionChanged()
+		case "a":
+			if !m.multiSelect {
+				break
+			}
+			// Toggles the rows shown, the selection of rows the filter
+			// hides is kept
+			all := true
+			for _, row := range m.rows {
+				if !m.selected[m.rowKey(row)] {
+					all = false
+					break
+				}
+			}
+			for _, row := range m.rows {
+				if all {
+					delete(m.selected, m.rowKey(row))
+				} else {
+					m.selected[m.rowKey(row)] = true
+				}
+			}
+			m.refresh()
+			return m, m.selectionChanged()
+		case "enter":
+			row := m.SelectedRow()
+			if row == nil {
+				return m, nil
+			}
+			return m, func() tea.Msg {
+				return RowSelectedMsg{Row: row}
+			}
 		}
 	}
-	
+
 	m.table, cmd = m.table.Update(msg)
 	return m, cmd
 }
 
 // View implements tea.Model
 func (m *DataTable) View() string {
+	body := m.table.View()
+	if m.cardMode() {
+		body = m.cardView()
+	}
+	parts := []string{zone.Mark(m.zoneID, body)}
+
+	if m.filtering || m.filter.Value() != "" {
+		parts = append(parts, m.filter.View())
+	} else {
+		parts = append(parts, "")
+	}
+
+	helpText := "↑/↓/j/k: navigate • ←/→: scroll • s/S: sort • /: filter • enter: select • q/esc: close"
+	if m.cardMode() {
+		helpText = "↑/↓: navigate • s/S: sort • /: filter • enter: select • esc: close"
+	}
+	if m.multiSelect {
+		helpText = "space: mark • a: mark all • " + helpText
+	}
 	help := styles.BaseStyle.
 		Foreground(styles.ForgroundDim).
-		Render("↑/↓/j/k: navigate • enter: select • q/esc: close")
-	
-	return lipgloss.JoinVertical(
-		lipgloss.Top,
-		m.table.View(),
-		"",
-		help,
-	)
+		Render(helpText)
+	parts = append(parts, help)
+
+	return lipgloss.JoinVertical(lipgloss.Top, parts...)
 }
 
 // SetSize sets the size of the table
 func (m *DataTable) SetSize(width, height int) {
 	m.width = width
 	m.height = height
-	
-	// Update table size (leave room for help)
+
+	// Update table size (leave room for filter and help)
 	tableHeight := height - 3
 	if tableHeight < 1 {
 		tableHeight = 1
 	}
-	
+
 	m.table.SetWidth(width)
 	m.table.SetHeight(tableHeight)
+	m.filter.Width = width - 2
+	m.refresh()
 }
 
 // Focus focuses the table
@@ -121,3 +366,307 @@ func (m *DataTable) Blur() {
 func (m *DataTable) Focused() bool {
 	return m.table.Focused()
 }
+
+// updateFilter handles key input while the filter has focus
+func (m *DataTable) updateFilter(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.filtering = false
+		m.filter.Blur()
+		m.filter.SetValue("")
+		m.refresh()
+		return nil
+	case "enter":
+		m.filtering = false
+		m.filter.Blur()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	m.refresh()
+	return cmd
+}
+
+// handleMouse scrolls rows with the wheel and sorts by a column when its
+// header is clicked. Clicking the current sort column reverses the order.
+func (m *DataTable) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.table.MoveUp(1)
+		return nil
+	case tea.MouseButtonWheelDown:
+		m.table.MoveDown(1)
+		return nil
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	x, y := zone.Get(m.zoneID).Pos(msg)
+	if x < 0 || y >= headerHeight || m.cardMode() {
+		return nil
+	}
+	column := m.columnAt(x)
+	if column < 0 {
+		return nil
+	}
+	if column == m.sortColumn {
+		m.sortDesc = !m.sortDesc
+	} else {
+		m.sortColumn = column
+		m.sortDesc = false
+	}
+	m.refresh()
+	return m.sortChanged()
+}
+
+// cardMode reports whether the table is too narrow for columns and shows
+// each row as a card instead
+func (m *DataTable) cardMode() bool {
+	return m.width > 0 && layout.BreakpointFor(m.width) == layout.BreakpointCompact
+}
+
+// cardView renders the page of rows around the cursor as cards with one
+// "column: value" line per column
+func (m *DataTable) cardView() string {
+	if len(m.rows) == 0 {
+		return styles.BaseStyle.Foreground(styles.ForgroundDim).Render("No rows")
+	}
+
+	labelWidth := 0
+	for _, c := range m.columns {
+		if w := ansi.StringWidth(c.Title); w > labelWidth {
+			labelWidth = w
+		}
+	}
+
+	// Each card is one line per column plus a separator
+	cardHeight := len(m.columns) + 1
+	if m.multiSelect {
+		cardHeight++
+	}
+	perPage := (m.height - 4) / cardHeight
+	if perPage < 1 {
+		perPage = 1
+	}
+	cursor := m.table.Cursor()
+	start := cursor / perPage * perPage
+	end := start + perPage
+	if end > len(m.rows) {
+		end = len(m.rows)
+	}
+
+	label := styles.BaseStyle.Foreground(styles.ForgroundMid).Width(labelWidth + 2)
+	value := styles.BaseStyle.Foreground(styles.Forground)
+	var cards []string
+	for i := start; i < end; i++ {
+		row := m.rows[i]
+		marker := "  "
+		if i == cursor {
+			marker = styles.BaseStyle.Foreground(styles.PrimaryColor).Bold(true).Render("▌ ")
+		}
+
+		var lines []string
+		if m.multiSelect && m.selected[m.rowKey(row)] {
+			lines = append(lines, marker+value.Render("✓ marked"))
+		} else if m.multiSelect {
+			lines = append(lines, marker)
+		}
+		for c, column := range m.columns {
+			title := column.Title
+			if c == m.sortColumn {
+				indicator := "▲"
+				if m.sortDesc {
+					indicator = "▼"
+				}
+				title += " " + indicator
+			}
+			line := marker + label.Render(title) + value.Render(cell(row, c))
+			lines = append(lines, ansi.Truncate(line, m.width, "…"))
+		}
+		cards = append(cards, lipgloss.JoinVertical(lipgloss.Left, lines...), "")
+	}
+	cards = append(cards, styles.BaseStyle.
+		Foreground(styles.ForgroundDim).
+		Render(fmt.Sprintf("%d-%d of %d", start+1, end, len(m.rows))))
+
+	return lipgloss.NewStyle().
+		Height(m.height - 3).
+		MaxHeight(m.height - 3).
+		Render(lipgloss.JoinVertical(lipgloss.Left, cards...))
+}
+
+// columnAt returns the source column rendered at x, or -1 if there is none
+func (m *DataTable) columnAt(x int) int {
+	start := 0
+	for i, c := range m.table.Columns() {
+		// Each cell is padded by one column on either side
+		end := start + c.Width + 2
+		if x >= start && x < end {
+			if i < len(m.visibleColumns) {
+				return m.visibleColumns[i]
+			}
+			return -1
+		}
+		start = end
+	}
+	return -1
+}
+
+// cycleSort advances the sort column: unsorted -> 0 -> 1 ... -> unsorted
+func (m *DataTable) cycleSort() tea.Cmd {
+	if len(m.columns) == 0 {
+		return nil
+	}
+	m.sortColumn++
+	if m.sortColumn >= len(m.columns) {
+		m.sortColumn = -1
+	}
+	m.sortDesc = false
+	m.refresh()
+	return m.sortChanged()
+}
+
+func (m *DataTable) sortChanged() tea.Cmd {
+	msg := SortChangedMsg{Column: m.sortColumn, Descending: m.sortDesc}
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+func (m *DataTable) selectionChanged() tea.Cmd {
+	msg := SelectionChangedMsg{Rows: m.SelectedRows()}
+	return func() tea.Msg {
+		return msg
+	}
+}
+
+func (m *DataTable) rowKey(row table.Row) string {
+	if m.keyColumn >= 0 && m.keyColumn < len(row) {
+		return row[m.keyColumn]
+	}
+	return strings.Join(row, "\x00")
+}
+
+// refresh rebuilds the visible rows and columns from the source data
+func (m *DataTable) refresh() {
+	query := strings.ToLower(m.filter.Value())
+
+	rows := make([]table.Row, 0, len(m.allRows))
+	for _, row := range m.allRows {
+		if query == "" || rowContains(row, query) {
+			rows = append(rows, row)
+		}
+	}
+
+	if m.sortColumn >= 0 {
+		col := m.sortColumn
+		desc := m.sortDesc
+		sort.SliceStable(rows, func(i, j int) bool {
+			a, b := cell(rows[i], col), cell(rows[j], col)
+			if desc {
+				return compareCells(b, a)
+			}
+			return compareCells(a, b)
+		})
+	}
+	m.rows = rows
+
+	// Work out which columns fit starting at the scroll offset
+	start := m.colOffset
+	if start >= len(m.columns) {
+		start = 0
+	}
+	end := len(m.columns)
+	if m.width > 0 {
+		used := 0
+		if m.multiSelect {
+			used += selectionColumnWidth
+		}
+		end = start
+		for end < len(m.columns) {
+			// Each cell is padded by one column on either side
+			w := m.columns[end].Width + 2
+			if used+w > m.width && end > start {
+				break
+			}
+			used += w
+			end++
+		}
+	}
+
+	var columns []table.Column
+	m.visibleColumns = m.visibleColumns[:0]
+	if m.multiSelect {
+		columns = append(columns, table.Column{Title: "", Width: selectionColumnWidth - 2})
+		m.visibleColumns = append(m.visibleColumns, -1)
+	}
+	for i := start; i < end; i++ {
+		m.visibleColumns = append(m.visibleColumns, i)
+		c := m.columns[i]
+		if i == m.sortColumn {
+			indicator := "▲"
+			if m.sortDesc {
+				indicator = "▼"
+			}
+			c.Title = fmt.Sprintf("%s %s", c.Title, indicator)
+		}
+		columns = append(columns, c)
+	}
+
+	visible := make([]table.Row, len(rows))
+	for i, row := range rows {
+		var out table.Row
+		if m.multiSelect {
+			marker := " "
+			if m.selected[m.rowKey(row)] {
+				marker = "✓"
+			}
+			out = append(out, marker)
+		}
+		for c := start; c < end; c++ {
+			out = append(out, cell(row, c))
+		}
+		visible[i] = out
+	}
+
+	// Clear rows before swapping columns so the table never renders rows
+	// with a different column count
+	m.table.SetRows(nil)
+	m.table.SetColumns(columns)
+	m.table.SetRows(visible)
+	if m.table.Cursor() >= len(visible) && len(visible) > 0 {
+		m.table.SetCursor(len(visible) - 1)
+	}
+}
+
+func cell(row table.Row, i int) string {
+	if i < len(row) {
+		return row[i]
+	}
+	return ""
+}
+
+func rowContains(row table.Row, query string) bool {
+	for _, c := range row {
+		if strings.Contains(strings.ToLower(c), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareCells orders numerically when both cells are numbers and
+// case-insensitively otherwise
+func compareCells(a, b string) bool {
+	af, aErr := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	bf, bErr := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if aErr == nil && bErr == nil {
+		return af < bf
+	}
+	return strings.ToLower(a) < strings.ToLower(b)
+}
@@ -0,0 +1,49 @@
+package table
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	zone "github.com/lrstanley/bubblezone"
+)
+
+func TestMarkAll(t *testing.T) {
+	// The table marks its mouse zones, as the app does at startup
+	zone.NewGlobal()
+	rows := []table.Row{{"api-1"}, {"api-2"}, {"web-1"}}
+	tests := []struct {
+		name     string
+		selected []string
+		filter   string
+		want     []string
+	}{
+		{name: "marks every row", selected: nil, filter: "", want: []string{"api-1", "api-2", "web-1"}},
+		{name: "clears every row", selected: []string{"api-1", "api-2", "web-1"}, filter: "", want: nil},
+		{name: "marks the rows shown", selected: nil, filter: "api", want: []string{"api-1", "api-2"}},
+		{name: "keeps hidden rows marked", selected: []string{"web-1"}, filter: "api", want: []string{"api-1", "api-2", "web-1"}},
+		{name: "completes the rows shown", selected: []string{"api-1", "web-1"}, filter: "api", want: []string{"api-1", "api-2", "web-1"}},
+		{name: "clears only the rows shown", selected: []string{"api-1", "api-2", "web-1"}, filter: "api", want: []string{"web-1"}},
+		{name: "nothing shown", selected: []string{"web-1"}, filter: "db", want: []string{"web-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewDataTable([]table.Column{{Title: "Name", Width: 10}}, rows)
+			m.SetMultiSelect(true, 0)
+			for _, key := range tt.selected {
+				m.selected[key] = true
+			}
+			m.SetFilter(tt.filter)
+
+			m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+			var got []string
+			for _, row := range m.SelectedRows() {
+				got = append(got, row[0])
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("marked %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
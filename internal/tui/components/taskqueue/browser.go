@@ -0,0 +1,502 @@
+package taskqueue
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	bubbletable "github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/swarm/artifact"
+	"github.com/opencode-ai/opencode/internal/tui/components/table"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// refreshInterval is how often the task list is reloaded
+const refreshInterval = time.Second
+
+// maxArtifactView bounds the content of an artifact shown, in bytes
+const maxArtifactView = 64 << 10
+
+// maxProgressView bounds the progress log lines shown for a task
+const maxProgressView = 5
+
+// TaskSource is the part of the swarm coordinator the browser needs
+type TaskSource interface {
+	ListTasks() []swarm.TaskRecord
+	CancelTask(taskID string) error
+	RetryTask(taskID string) error
+	SetTaskPriority(taskID string, priority int) error
+	OpenArtifact(id string) (swarm.Artifact, io.ReadCloser, error)
+	Locks() []swarm.Lock
+}
+
+// states are the state filters, cycled with f
+var states = []swarm.TaskState{
+	"",
+	swarm.TaskStateQueued,
+	swarm.TaskStateRunning,
+	swarm.TaskStateCompleted,
+	swarm.TaskStateFailed,
+	swarm.TaskStateCancelled,
+}
+
+// Browser lists swarm tasks with controls to cancel, retry and reprioritize
+// them
+type Browser struct {
+	source TaskSource
+	table  *table.DataTable
+	width  int
+	height int
+
+	stateIndex int
+	records    map[string]swarm.TaskRecord
+	counts     map[swarm.TaskState]int
+	locks      []swarm.Lock
+
+	refresher *util.Refresher
+
+	// The artifact of a task shown instead of its input and output
+	artifactTask    string
+	artifactIndex   int
+	artifactContent string
+}
+
+// NewBrowser creates a task queue browser
+func NewBrowser(source TaskSource) *Browser {
+	t := table.NewDataTable(columns(40), nil)
+	m := &Browser{
+		source:    source,
+		table:     t,
+		records:   make(map[string]swarm.TaskRecord),
+		counts:    make(map[swarm.TaskState]int),
+		refresher: util.NewRefresher(refreshInterval),
+	}
+	m.refresh()
+	return m
+}
+
+func columns(descriptionWidth int) []bubbletable.Column {
+	return []bubbletable.Column{
+		{Title: "ID", Width: 8},
+		{Title: "Type", Width: 12},
+		{Title: "Pri", Width: 4},
+		{Title: "State", Width: 9},
+		{Title: "Agent", Width: 14},
+		{Title: "Submitted", Width: 9},
+		{Title: "Duration", Width: 9},
+		{Title: "Description", Width: descriptionWidth},
+	}
+}
+
+// Open reloads the tasks and keeps them up to date while shown
+func (m *Browser) Open() tea.Cmd {
+	m.refresh()
+	return m.refresher.Start()
+}
+
+// Capturing returns whether the row filter is focused
+func (m *Browser) Capturing() bool {
+	return m.table.IsFiltering()
+}
+
+// Init implements tea.Model
+func (m *Browser) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *Browser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case util.RefreshMsg:
+		if !m.refresher.Due(msg) {
+			return m, nil
+		}
+		m.refresh()
+		return m, m.refresher.Next()
+	case tea.KeyMsg:
+		if !m.table.IsFiltering() {
+			switch msg.String() {
+			case "c":
+				return m, m.act("Cancelled", m.source.CancelTask)
+			case "R":
+				return m, m.act("Retrying", m.source.RetryTask)
+			case "+", "=":
+				return m, m.bump(1)
+			case "-":
+				return m, m.bump(-1)
+			case "a":
+				return m, m.nextArtifact()
+			case "f":
+				m.stateIndex = (m.stateIndex + 1) % len(states)
+				m.refresh()
+				return m, nil
+			case "r":
+				m.refresh()
+				return m, nil
+			}
+		}
+	}
+
+	_, cmd := m.table.Update(msg)
+	return m, cmd
+}
+
+// act applies an action to the selected task
+func (m *Browser) act(verb string, action func(string) error) tea.Cmd {
+	record, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	err := action(record.Task.ID)
+	m.refresh()
+	if err != nil {
+		return util.ReportError(err)
+	}
+	return util.ReportInfo(fmt.Sprintf("%s task %s", verb, shortID(record.Task.ID)))
+}
+
+func (m *Browser) bump(delta int) tea.Cmd {
+	record, ok := m.selected()
+	if !ok {
+		return nil
+	}
+	if err := m.source.SetTaskPriority(record.Task.ID, record.Task.Priority+delta); err != nil {
+		return util.ReportError(err)
+	}
+	m.refresh()
+	return nil
+}
+
+// nextArtifact shows the next artifact of the selected task, and its input
+// and output again after the last one
+func (m *Browser) nextArtifact() tea.Cmd {
+	record, ok := m.selected()
+	if !ok || record.Result == nil || len(record.Result.Artifacts) == 0 {
+		return nil
+	}
+	index := 0
+	if m.artifactTask == record.Task.ID {
+		index = m.artifactIndex + 1
+	}
+	m.artifactContent = ""
+	if index >= len(record.Result.Artifacts) {
+		m.artifactTask = ""
+		return nil
+	}
+	m.artifactTask = record.Task.ID
+	m.artifactIndex = index
+
+	_, content, err := m.source.OpenArtifact(record.Result.Artifacts[index].ID)
+	if err != nil {
+		m.artifactTask = ""
+		return util.ReportError(err)
+	}
+	defer content.Close()
+	data, err := io.ReadAll(io.LimitReader(content, maxArtifactView))
+	if err != nil {
+		m.artifactTask = ""
+		return util.ReportError(err)
+	}
+	m.artifactContent = string(data)
+	return nil
+}
+
+// selected returns the task under the cursor
+func (m *Browser) selected() (swarm.TaskRecord, bool) {
+	row := m.table.SelectedRow()
+	if row == nil {
+		return swarm.TaskRecord{}, false
+	}
+	record, ok := m.records[row[0]]
+	return record, ok
+}
+
+// refresh reloads the tasks matching the state filter
+func (m *Browser) refresh() {
+	m.records = make(map[string]swarm.TaskRecord)
+	m.counts = make(map[swarm.TaskState]int)
+	m.locks = nil
+	if m.source == nil {
+		m.table.SetRows(nil)
+		return
+	}
+	m.locks = m.source.Locks()
+
+	filter := states[m.stateIndex]
+	var rows []bubbletable.Row
+	for _, record := range m.source.ListTasks() {
+		m.counts[record.State]++
+		if filter != "" && record.State != filter {
+			continue
+		}
+		m.records[record.Task.ID] = record
+		state := string(record.State)
+		if record.State == swarm.TaskStateRunning && !record.StuckSince.IsZero() {
+			state += " (stuck)"
+		}
+		rows = append(rows, bubbletable.Row{
+			record.Task.ID,
+			record.Task.Type,
+			fmt.Sprintf("%d", record.Task.Priority),
+			state,
+			record.AgentID,
+			record.SubmittedAt.Format("15:04:05"),
+			duration(record),
+			record.Task.Description,
+		})
+	}
+	m.table.SetRows(rows)
+}
+
+func duration(record swarm.TaskRecord) string {
+	switch {
+	case record.StartedAt.IsZero():
+		return "-"
+	case record.FinishedAt.IsZero():
+		return time.Since(record.StartedAt).Round(time.Second).String()
+	default:
+		return record.FinishedAt.Sub(record.StartedAt).Round(time.Millisecond).String()
+	}
+}
+
+// View implements tea.Model
+func (m *Browser) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Task Queue")
+
+	status := "No swarm is running"
+	if m.source != nil {
+		filter := string(states[m.stateIndex])
+		if filter == "" {
+			filter = "all"
+		}
+		status = fmt.Sprintf("%d queued • %d running • %d completed • %d failed • %d cancelled • %d locks • showing %s",
+			m.counts[swarm.TaskStateQueued],
+			m.counts[swarm.TaskStateRunning],
+			m.counts[swarm.TaskStateCompleted],
+			m.counts[swarm.TaskStateFailed],
+			m.counts[swarm.TaskStateCancelled],
+			len(m.locks),
+			filter,
+		)
+	}
+
+	help := "c: cancel • R: retry • +/-: priority • a: artifacts • f: filter state • r: refresh"
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		styles.BaseStyle.Foreground(styles.ForgroundMid).Render(status),
+		styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help),
+		"",
+		m.table.View(),
+		"",
+		m.details(),
+	)
+}
+
+// details renders the input and result of the selected task
+func (m *Browser) details() string {
+	record, ok := m.selected()
+	if !ok {
+		return styles.BaseStyle.Foreground(styles.ForgroundDim).Render("No tasks")
+	}
+
+	label := styles.BaseStyle.Foreground(styles.ForgroundMid)
+	text := styles.BaseStyle.Foreground(styles.Forground)
+
+	lines := []string{
+		label.Render("Task: ") + text.Render(record.Task.ID),
+		label.Render("Description: ") + text.Render(record.Task.Description),
+		label.Render("Retries: ") + text.Render(fmt.Sprintf("%d of %d", record.Task.RetryCount, record.Task.MaxRetries)),
+	}
+	if record.Task.Deadline != nil {
+		lines = append(lines, label.Render("Deadline: ")+text.Render(record.Task.Deadline.Format(time.DateTime)))
+	}
+	if record.State == swarm.TaskStateRunning && !record.StuckSince.IsZero() {
+		lines = append(lines, styles.BaseStyle.Foreground(styles.Warning).Render("Suspected stuck since "+record.StuckSince.Format(time.DateTime)))
+	}
+	lines = append(lines, m.lockLines(record.Task.ID, label, text)...)
+	lines = append(lines, progressLines(record.Progress, label, text)...)
+	if record.Result != nil && len(record.Result.Artifacts) > 0 {
+		lines = append(lines, label.Bold(true).Render("Artifacts"))
+		for i, a := range record.Result.Artifacts {
+			marker := "  "
+			if m.artifactTask == record.Task.ID && m.artifactIndex == i {
+				marker = "▸ "
+			}
+			lines = append(lines, marker+text.Render(a.Name)+label.Render(fmt.Sprintf(" %s, %s, %s", a.Kind, size(a.Size), artifact.ShortID(a.ID))))
+		}
+	}
+	if m.artifactTask == record.Task.ID {
+		// The artifact takes the place of the input and output
+		for _, line := range strings.Split(strings.TrimRight(m.artifactContent, "\n"), "\n") {
+			lines = append(lines, text.Render(strings.ReplaceAll(line, "\t", "    ")))
+		}
+	} else {
+		lines = append(lines, fieldLines("Input", record.Task.Input, label, text)...)
+		if record.Result != nil {
+			lines = append(lines, fieldLines("Output", record.Result.Output, label, text)...)
+		}
+	}
+	if record.Error != "" {
+		lines = append(lines, styles.BaseStyle.Foreground(styles.Error).Render("Error: "+record.Error))
+	}
+	if len(record.Shadows) > 0 {
+		lines = append(lines, label.Bold(true).Render("Shadows"))
+		for _, shadow := range record.Shadows {
+			verdict := "disagrees"
+			switch {
+			case shadow.DryRun:
+				verdict = "dry run, not compared"
+			case shadow.SameOutput:
+				verdict = "same output"
+			case shadow.SameOutcome:
+				verdict = "agrees"
+			}
+			lines = append(lines, "  "+text.Render(shadow.AgentID)+label.Render(fmt.Sprintf(" %s in %s", verdict, shadow.Duration.Round(time.Millisecond))))
+		}
+	}
+
+	available := m.height - m.tableHeight() - 5
+	if available < 1 {
+		available = 1
+	}
+	if len(lines) > available {
+		lines = lines[:available]
+	}
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// lockLines renders the locks a task holds and waits for, with the tasks
+// in its way
+func (m *Browser) lockLines(taskID string, label, text lipgloss.Style) []string {
+	var lines []string
+	for _, lock := range m.locks {
+		if lock.Owner.TaskID == taskID {
+			line := "  " + text.Render(lock.Resource) + label.Render(" held since "+lock.AcquiredAt.Format("15:04:05"))
+			if len(lock.Waiting) > 0 {
+				line += label.Render(fmt.Sprintf(", %d waiting", len(lock.Waiting)))
+			}
+			lines = append(lines, line)
+			continue
+		}
+		for _, waiter := range lock.Waiting {
+			if waiter.TaskID == taskID {
+				lines = append(lines, "  "+text.Render(lock.Resource)+label.Render(" waiting for "+lock.Owner.TaskID))
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return append([]string{label.Bold(true).Render("Locks")}, lines...)
+}
+
+// progressLines renders the progress reported on a task with its latest
+// log lines
+func progressLines(progress swarm.TaskProgress, label, text lipgloss.Style) []string {
+	if progress.UpdatedAt.IsZero() {
+		return nil
+	}
+	var parts []string
+	if progress.Percent > 0 {
+		parts = append(parts, fmt.Sprintf("%.0f%%", progress.Percent))
+	}
+	if progress.Stage != "" {
+		parts = append(parts, progress.Stage)
+	}
+	parts = append(parts, "updated "+progress.UpdatedAt.Format("15:04:05"))
+	lines := []string{label.Render("Progress: ") + text.Render(strings.Join(parts, " • "))}
+
+	log := progress.Log
+	if len(log) > maxProgressView {
+		log = log[len(log)-maxProgressView:]
+	}
+	for _, line := range log {
+		lines = append(lines, "  "+text.Render(strings.ReplaceAll(line, "\t", "    ")))
+	}
+	return lines
+}
+
+// fieldLines renders a map as one line per key
+func fieldLines(title string, fields map[string]interface{}, label, text lipgloss.Style) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := []string{label.Bold(true).Render(title)}
+	for _, key := range keys {
+		value := fmt.Sprintf("%v", fields[key])
+		if data, err := json.Marshal(fields[key]); err == nil {
+			value = string(data)
+		}
+		value = strings.Join(strings.Fields(value), " ")
+		lines = append(lines, "  "+label.Render(key+": ")+text.Render(value))
+	}
+	return lines
+}
+
+// tableHeight gives the task list half of the screen
+func (m *Browser) tableHeight() int {
+	height := (m.height - 5) / 2
+	if height < 5 {
+		height = 5
+	}
+	return height
+}
+
+// SetSize sets the size of the browser
+func (m *Browser) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	// Everything but the description has a fixed width
+	rest := width - 8 - 12 - 4 - 9 - 14 - 9 - 9 - 16
+	if rest < 20 {
+		rest = 20
+	}
+	m.table.SetColumns(columns(rest))
+	m.table.SetSize(width, m.tableHeight())
+}
+
+// size formats a size in bytes
+func size(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
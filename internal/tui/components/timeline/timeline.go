@@ -0,0 +1,278 @@
+package timeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	bubbletable "github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/tui/components/table"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// refreshInterval is how often the timeline is reloaded
+const refreshInterval = time.Second
+
+// EventSource is the part of the swarm coordinator the timeline needs
+type EventSource interface {
+	Timeline(filter swarm.TimelineFilter) []swarm.TimelineEvent
+}
+
+// window is a time range filter, cycled with w
+type window struct {
+	label    string
+	duration time.Duration
+}
+
+var windows = []window{
+	{label: "all time"},
+	{label: "last 15m", duration: 15 * time.Minute},
+	{label: "last hour", duration: time.Hour},
+	{label: "last 24h", duration: 24 * time.Hour},
+}
+
+// Timeline shows swarm events, newest first, filtered by type and time
+// range
+type Timeline struct {
+	source EventSource
+	table  *table.DataTable
+	width  int
+	height int
+
+	// typeIndex selects an entry of swarm.TimelineEventTypes, -1 shows all
+	typeIndex   int
+	windowIndex int
+	events      map[string]swarm.TimelineEvent
+
+	refresher *util.Refresher
+}
+
+// NewTimeline creates a timeline of the events recorded by source
+func NewTimeline(source EventSource) *Timeline {
+	m := &Timeline{
+		source:    source,
+		table:     table.NewDataTable(columns(40), nil),
+		typeIndex: -1,
+		events:    make(map[string]swarm.TimelineEvent),
+		refresher: util.NewRefresher(refreshInterval),
+	}
+	m.refresh()
+	return m
+}
+
+func columns(summaryWidth int) []bubbletable.Column {
+	return []bubbletable.Column{
+		{Title: "#", Width: 6},
+		{Title: "Time", Width: 9},
+		{Title: "Type", Width: 14},
+		{Title: "Subject", Width: 14},
+		{Title: "Summary", Width: summaryWidth},
+	}
+}
+
+// Open reloads the timeline and keeps it up to date while shown
+func (m *Timeline) Open() tea.Cmd {
+	m.refresh()
+	return m.refresher.Start()
+}
+
+// Capturing returns whether the row filter is focused
+func (m *Timeline) Capturing() bool {
+	return m.table.IsFiltering()
+}
+
+// Init implements tea.Model
+func (m *Timeline) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *Timeline) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case util.RefreshMsg:
+		if !m.refresher.Due(msg) {
+			return m, nil
+		}
+		m.refresh()
+		return m, m.refresher.Next()
+	case tea.KeyMsg:
+		if !m.table.IsFiltering() {
+			switch msg.String() {
+			case "t":
+				m.typeIndex++
+				if m.typeIndex >= len(swarm.TimelineEventTypes) {
+					m.typeIndex = -1
+				}
+				m.refresh()
+				return m, nil
+			case "w":
+				m.windowIndex = (m.windowIndex + 1) % len(windows)
+				m.refresh()
+				return m, nil
+			case "r":
+				m.refresh()
+				return m, nil
+			}
+		}
+	}
+
+	_, cmd := m.table.Update(msg)
+	return m, cmd
+}
+
+// filter builds the swarm filter from the selected type and window
+func (m *Timeline) filter() swarm.TimelineFilter {
+	var filter swarm.TimelineFilter
+	if m.typeIndex >= 0 {
+		filter.Types = []swarm.TimelineEventType{swarm.TimelineEventTypes[m.typeIndex]}
+	}
+	if d := windows[m.windowIndex].duration; d > 0 {
+		filter.Since = time.Now().Add(-d)
+	}
+	return filter
+}
+
+// refresh reloads the events matching the filters
+func (m *Timeline) refresh() {
+	m.events = make(map[string]swarm.TimelineEvent)
+	if m.source == nil {
+		m.table.SetRows(nil)
+		return
+	}
+
+	events := m.source.Timeline(m.filter())
+	rows := make([]bubbletable.Row, 0, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		key := strconv.FormatInt(event.Seq, 10)
+		m.events[key] = event
+		rows = append(rows, bubbletable.Row{
+			key,
+			event.Timestamp.Format("15:04:05"),
+			string(event.Type),
+			event.Subject,
+			event.Summary,
+		})
+	}
+	m.table.SetRows(rows)
+}
+
+// selected returns the event under the cursor
+func (m *Timeline) selected() (swarm.TimelineEvent, bool) {
+	row := m.table.SelectedRow()
+	if row == nil {
+		return swarm.TimelineEvent{}, false
+	}
+	event, ok := m.events[row[0]]
+	return event, ok
+}
+
+// View implements tea.Model
+func (m *Timeline) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Swarm Timeline")
+
+	status := "No swarm is running"
+	if m.source != nil {
+		eventType := "all events"
+		if m.typeIndex >= 0 {
+			eventType = string(swarm.TimelineEventTypes[m.typeIndex])
+		}
+		status = fmt.Sprintf("%d events • showing %s • %s",
+			len(m.events), eventType, windows[m.windowIndex].label)
+	}
+
+	help := "t: filter type • w: time range • r: refresh"
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		styles.BaseStyle.Foreground(styles.ForgroundMid).Render(status),
+		styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help),
+		"",
+		m.table.View(),
+		"",
+		m.details(),
+	)
+}
+
+// details renders the selected event in full
+func (m *Timeline) details() string {
+	event, ok := m.selected()
+	if !ok {
+		return styles.BaseStyle.Foreground(styles.ForgroundDim).Render("No events")
+	}
+
+	label := styles.BaseStyle.Foreground(styles.ForgroundMid)
+	text := styles.BaseStyle.Foreground(styles.Forground)
+
+	lines := []string{
+		label.Render("Time: ") + text.Render(event.Timestamp.Format(time.DateTime)),
+		label.Render("Type: ") + text.Render(string(event.Type)),
+		label.Render("Subject: ") + text.Render(event.Subject),
+		label.Render("Summary: ") + text.Render(event.Summary),
+	}
+
+	keys := make([]string, 0, len(event.Details))
+	for key := range event.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := fmt.Sprintf("%v", event.Details[key])
+		if data, err := json.Marshal(event.Details[key]); err == nil {
+			value = string(data)
+		}
+		value = strings.Join(strings.Fields(value), " ")
+		lines = append(lines, "  "+label.Render(key+": ")+text.Render(value))
+	}
+
+	available := m.height - m.tableHeight() - 5
+	if available < 1 {
+		available = 1
+	}
+	if len(lines) > available {
+		lines = lines[:available]
+	}
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// tableHeight gives the event list most of the screen
+func (m *Timeline) tableHeight() int {
+	height := (m.height - 5) * 2 / 3
+	if height < 5 {
+		height = 5
+	}
+	return height
+}
+
+// SetSize sets the size of the timeline
+func (m *Timeline) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	// Everything but the summary has a fixed width
+	rest := width - 6 - 9 - 14 - 14 - 10
+	if rest < 20 {
+		rest = 20
+	}
+	m.table.SetColumns(columns(rest))
+	m.table.SetSize(width, m.tableHeight())
+}
@@ -0,0 +1,277 @@
+package topology
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	bubbletable "github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/tui/components/table"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+const (
+	// refreshInterval is how often the topology is reloaded
+	refreshInterval = 2 * time.Second
+	// exportFile is where e writes the DOT graph, in the working directory
+	exportFile = "swarm-topology.dot"
+)
+
+// TopologySource is the part of the swarm the view needs
+type TopologySource interface {
+	GetTopology() swarm.Topology
+}
+
+// View lists the nodes of the swarm's topology with their message rates,
+// and the edges of the selected node
+type View struct {
+	source TopologySource
+	table  *table.DataTable
+	width  int
+	height int
+
+	topology swarm.Topology
+
+	refresher *util.Refresher
+}
+
+// NewView creates a topology view of source
+func NewView(source TopologySource) *View {
+	m := &View{
+		source:    source,
+		table:     table.NewDataTable(columns(20), nil),
+		refresher: util.NewRefresher(refreshInterval),
+	}
+	m.refresh()
+	return m
+}
+
+func columns(idWidth int) []bubbletable.Column {
+	return []bubbletable.Column{
+		{Title: "Node", Width: idWidth},
+		{Title: "Type", Width: 12},
+		{Title: "Status", Width: 12},
+		{Title: "Out/min", Width: 8},
+		{Title: "In/min", Width: 8},
+		{Title: "Edges", Width: 5},
+	}
+}
+
+// Open reloads the topology and keeps it up to date while shown
+func (m *View) Open() tea.Cmd {
+	m.refresh()
+	return m.refresher.Start()
+}
+
+// Capturing returns whether the row filter is focused
+func (m *View) Capturing() bool {
+	return m.table.IsFiltering()
+}
+
+// Init implements tea.Model
+func (m *View) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *View) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case util.RefreshMsg:
+		if !m.refresher.Due(msg) {
+			return m, nil
+		}
+		m.refresh()
+		return m, m.refresher.Next()
+	case tea.KeyMsg:
+		if !m.table.IsFiltering() {
+			switch msg.String() {
+			case "e":
+				return m, m.export()
+			case "r":
+				m.refresh()
+				return m, nil
+			}
+		}
+	}
+
+	_, cmd := m.table.Update(msg)
+	return m, cmd
+}
+
+// export writes the topology as a Graphviz graph to the working directory
+func (m *View) export() tea.Cmd {
+	if m.source == nil {
+		return util.ReportWarn("No swarm is running")
+	}
+	if err := os.WriteFile(exportFile, []byte(m.topology.DOT()), 0o644); err != nil {
+		return util.ReportError(err)
+	}
+	return util.ReportInfo("Topology written to " + exportFile)
+}
+
+// refresh reloads the topology and sums the message rates of its nodes
+func (m *View) refresh() {
+	if m.source == nil {
+		m.table.SetRows(nil)
+		return
+	}
+
+	m.topology = m.source.GetTopology()
+	out := make(map[string]float64)
+	in := make(map[string]float64)
+	edges := make(map[string]int)
+	for _, edge := range m.topology.Edges {
+		edges[edge.From]++
+		edges[edge.To]++
+		if edge.Kind == swarm.TopologyEdgeMessage {
+			out[edge.From] += edge.Rate
+			in[edge.To] += edge.Rate
+		}
+	}
+
+	rows := make([]bubbletable.Row, 0, len(m.topology.Nodes))
+	for _, node := range m.topology.Nodes {
+		rows = append(rows, bubbletable.Row{
+			node.ID,
+			nodeType(node),
+			nodeStatus(node),
+			fmt.Sprintf("%.1f", out[node.ID]),
+			fmt.Sprintf("%.1f", in[node.ID]),
+			fmt.Sprintf("%d", edges[node.ID]),
+		})
+	}
+	m.table.SetRows(rows)
+}
+
+func nodeType(node swarm.TopologyNode) string {
+	switch {
+	case node.Kind == swarm.TopologyNodeBroker:
+		return "broker"
+	case node.Shadow:
+		return string(node.Type) + " (shadow)"
+	}
+	return string(node.Type)
+}
+
+func nodeStatus(node swarm.TopologyNode) string {
+	switch {
+	case node.Kind == swarm.TopologyNodeBroker:
+		return ""
+	case node.Removed:
+		return "removed"
+	case node.Unreachable:
+		return "unreachable"
+	}
+	return string(node.Status)
+}
+
+// View implements tea.Model
+func (m *View) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Swarm Topology")
+
+	status := "No swarm is running"
+	if m.source != nil {
+		status = fmt.Sprintf("%d nodes • %d edges • rates over the last %s",
+			len(m.topology.Nodes), len(m.topology.Edges), swarm.TopologyRateWindow)
+	}
+
+	help := "e: export DOT • r: refresh • /: filter nodes"
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		styles.BaseStyle.Foreground(styles.ForgroundMid).Render(status),
+		styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help),
+		"",
+		m.table.View(),
+		"",
+		m.edgeList(),
+	)
+}
+
+// edgeList renders the edges of the selected node, outgoing first
+func (m *View) edgeList() string {
+	row := m.table.SelectedRow()
+	if row == nil {
+		return styles.BaseStyle.Foreground(styles.ForgroundDim).Render("No agents in the swarm")
+	}
+	id := row[0]
+
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	lines := []string{styles.BaseStyle.Bold(true).Render("Edges of " + id)}
+	var incoming []string
+	for _, edge := range m.topology.Edges {
+		switch id {
+		case edge.From:
+			lines = append(lines, edgeLine("→", edge.To, edge))
+		case edge.To:
+			incoming = append(incoming, edgeLine("←", edge.From, edge))
+		}
+	}
+	lines = append(lines, incoming...)
+	if len(lines) == 1 {
+		lines = append(lines, styles.BaseStyle.Foreground(styles.ForgroundDim).Render("None"))
+	}
+
+	available := m.height - m.tableHeight() - 5
+	if available < 2 {
+		available = 2
+	}
+	if len(lines) > available {
+		lines = lines[:available]
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// edgeLine describes an edge to or from peer
+func edgeLine(arrow, peer string, edge swarm.TopologyEdge) string {
+	color := styles.ForgroundMid
+	detail := ""
+	switch edge.Kind {
+	case swarm.TopologyEdgeMessage:
+		detail = fmt.Sprintf("%d messages, %.1f/min", edge.Count, edge.Rate)
+		color = styles.Text
+	case swarm.TopologyEdgeDependency:
+		detail = fmt.Sprintf("%d tasks spawned", edge.Count)
+	case swarm.TopologyEdgeShadow:
+		detail = fmt.Sprintf("%d tasks copied", edge.Count)
+	}
+	line := fmt.Sprintf("%s %-20s %-12s %s", arrow, peer, edge.Kind, detail)
+	return styles.BaseStyle.Foreground(color).Render(line)
+}
+
+// tableHeight gives the nodes half of the screen, the edges the rest
+func (m *View) tableHeight() int {
+	height := (m.height - 5) / 2
+	if height < 5 {
+		height = 5
+	}
+	return height
+}
+
+// SetSize sets the size of the view
+func (m *View) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	idWidth := width - 12 - 8 - 8 - 5 - 12 - 12
+	if idWidth < 12 {
+		idWidth = 12
+	}
+	m.table.SetColumns(columns(idWidth))
+	m.table.SetSize(width, m.tableHeight())
+}
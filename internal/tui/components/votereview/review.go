@@ -0,0 +1,383 @@
+package votereview
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	bubbletable "github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/opencode-ai/opencode/internal/swarm/voting"
+	"github.com/opencode-ai/opencode/internal/tui/components/table"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
+	"github.com/opencode-ai/opencode/internal/tui/util"
+)
+
+// refreshInterval is how often open sessions are reloaded
+const refreshInterval = time.Second
+
+// expiringWithin is how close to their deadline sessions are counted as
+// expiring soon
+const expiringWithin = 10 * time.Second
+
+// action is what the human is about to do with the selected session
+type action int
+
+const (
+	actionNone action = iota
+	actionApprove
+	actionDeny
+	actionVeto
+)
+
+func (a action) String() string {
+	switch a {
+	case actionApprove:
+		return "Approve"
+	case actionDeny:
+		return "Deny"
+	case actionVeto:
+		return "Veto"
+	}
+	return ""
+}
+
+// VoteReview lists open vote sessions of a swarm and lets the user cast a
+// vote or veto a proposal
+type VoteReview struct {
+	voting *voting.DemocraticVotingSystem
+	table  *table.DataTable
+	width  int
+	height int
+
+	sessions  map[string]*voting.VoteSession
+	refresher *util.Refresher
+
+	pending action
+	reason  textinput.Model
+}
+
+// NewVoteReview creates a review screen for the sessions of a voting system
+func NewVoteReview(vs *voting.DemocraticVotingSystem) *VoteReview {
+	columns := []bubbletable.Column{
+		{Title: "ID", Width: 8},
+		{Title: "Proposal", Width: 40},
+		{Title: "By", Width: 12},
+		{Title: "Type", Width: 10},
+		{Title: "Votes", Width: 9},
+		{Title: "Deadline", Width: 9},
+	}
+	t := table.NewDataTable(columns, nil)
+
+	reason := textinput.New()
+	reason.Placeholder = "reasoning (optional)"
+
+	m := &VoteReview{
+		voting:    vs,
+		table:     t,
+		sessions:  make(map[string]*voting.VoteSession),
+		reason:    reason,
+		refresher: util.NewRefresher(refreshInterval),
+	}
+	m.refresh()
+	return m
+}
+
+// Open reloads the sessions and keeps them up to date while shown
+func (m *VoteReview) Open() tea.Cmd {
+	m.refresh()
+	return m.refresher.Start()
+}
+
+// Capturing returns whether the reasoning input is focused
+func (m *VoteReview) Capturing() bool {
+	return m.pending != actionNone || m.table.IsFiltering()
+}
+
+// Init implements tea.Model
+func (m *VoteReview) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *VoteReview) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case util.RefreshMsg:
+		if !m.refresher.Due(msg) {
+			return m, nil
+		}
+		m.refresh()
+		return m, m.refresher.Next()
+	case tea.KeyMsg:
+		if m.pending != actionNone {
+			return m, m.updateReason(msg)
+		}
+		if !m.table.IsFiltering() {
+			switch msg.String() {
+			case "a", "y":
+				return m, m.prompt(actionApprove)
+			case "d", "n":
+				return m, m.prompt(actionDeny)
+			case "x":
+				return m, m.prompt(actionVeto)
+			case "r":
+				m.refresh()
+				return m, nil
+			}
+		}
+	}
+
+	_, cmd := m.table.Update(msg)
+	return m, cmd
+}
+
+// prompt asks for the reasoning of a vote or veto on the selected session
+func (m *VoteReview) prompt(a action) tea.Cmd {
+	if m.selected() == nil {
+		return util.ReportWarn("No open vote session selected")
+	}
+	m.pending = a
+	m.reason.Prompt = a.String() + ": "
+	m.reason.SetValue("")
+	return m.reason.Focus()
+}
+
+func (m *VoteReview) updateReason(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.pending = actionNone
+		m.reason.Blur()
+		return nil
+	case "enter":
+		a := m.pending
+		m.pending = actionNone
+		m.reason.Blur()
+		return m.submit(a, strings.TrimSpace(m.reason.Value()))
+	}
+	var cmd tea.Cmd
+	m.reason, cmd = m.reason.Update(msg)
+	return cmd
+}
+
+// submit casts the human vote or veto on the selected session
+func (m *VoteReview) submit(a action, reasoning string) tea.Cmd {
+	session := m.selected()
+	if session == nil {
+		return util.ReportWarn("Vote session is no longer open")
+	}
+
+	var err error
+	switch a {
+	case actionVeto:
+		err = m.voting.Veto(session.ID, voting.HumanVoterID, reasoning)
+	default:
+		err = m.voting.CastVote(session.ID, voting.Vote{
+			AgentID:    voting.HumanVoterID,
+			Decision:   a == actionApprove,
+			Confidence: 1.0,
+			Reasoning:  reasoning,
+		})
+	}
+	m.refresh()
+	if err != nil {
+		return util.ReportError(err)
+	}
+
+	verb := map[action]string{
+		actionApprove: "Approved",
+		actionDeny:    "Denied",
+		actionVeto:    "Vetoed",
+	}[a]
+	return util.ReportInfo(fmt.Sprintf("%s: %s", verb, session.Proposal.Description))
+}
+
+// selected returns the session under the cursor
+func (m *VoteReview) selected() *voting.VoteSession {
+	row := m.table.SelectedRow()
+	if row == nil {
+		return nil
+	}
+	return m.sessions[row[0]]
+}
+
+// refresh reloads the open sessions into the table
+func (m *VoteReview) refresh() {
+	m.sessions = make(map[string]*voting.VoteSession)
+	if m.voting == nil {
+		m.table.SetRows(nil)
+		return
+	}
+
+	active := m.voting.GetActiveSessions()
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].Proposal.CreatedAt.Before(active[j].Proposal.CreatedAt)
+	})
+
+	rows := make([]bubbletable.Row, 0, len(active))
+	for _, session := range active {
+		m.sessions[session.ID] = session
+		yes, no := tally(session.GetVotes())
+		rows = append(rows, bubbletable.Row{
+			session.ID,
+			session.Proposal.Description,
+			session.Proposal.ProposedBy,
+			string(session.VoteType),
+			fmt.Sprintf("%d/%d/%d", yes, no, session.MinVoters),
+			remaining(session.Proposal.Deadline),
+		})
+	}
+	m.table.SetRows(rows)
+}
+
+func tally(votes []voting.Vote) (yes, no int) {
+	for _, vote := range votes {
+		if vote.Decision {
+			yes++
+		} else {
+			no++
+		}
+	}
+	return yes, no
+}
+
+func remaining(deadline time.Time) string {
+	if deadline.IsZero() {
+		return "-"
+	}
+	left := time.Until(deadline)
+	if left <= 0 {
+		return "expired"
+	}
+	return left.Round(time.Second).String()
+}
+
+// View implements tea.Model
+func (m *VoteReview) View() string {
+	title := styles.BaseStyle.
+		Bold(true).
+		Foreground(styles.PrimaryColor).
+		Render("Vote Review")
+
+	status := fmt.Sprintf("%d open sessions • votes are yes/no/required", len(m.sessions))
+	if m.voting == nil {
+		status = "No swarm is running"
+	} else if expiring := len(m.voting.ListSessions(voting.SessionFilter{DueBefore: time.Now().Add(expiringWithin)})); expiring > 0 {
+		status = fmt.Sprintf("%d open sessions, %d expiring soon • votes are yes/no/required", len(m.sessions), expiring)
+	}
+
+	help := "a/y: approve • d/n: deny • x: veto • r: refresh"
+	bottom := styles.BaseStyle.Foreground(styles.ForgroundDim).Render(help)
+	if m.pending != actionNone {
+		bottom = m.reason.View()
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		styles.BaseStyle.Foreground(styles.ForgroundMid).Render(status),
+		bottom,
+		"",
+		m.table.View(),
+		"",
+		m.details(),
+	)
+}
+
+// details renders the proposal and the votes of the selected session
+func (m *VoteReview) details() string {
+	session := m.selected()
+	if session == nil {
+		return styles.BaseStyle.Foreground(styles.ForgroundDim).Render("No open vote sessions")
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	label := styles.BaseStyle.Foreground(styles.ForgroundMid)
+	text := styles.BaseStyle.Foreground(styles.Forground)
+
+	lines := []string{
+		label.Render("Proposal: ") + text.Render(session.Proposal.Description),
+	}
+	if len(session.Proposal.Tags) > 0 {
+		lines = append(lines, label.Render("Tags: ")+text.Render(strings.Join(session.Proposal.Tags, ", ")))
+	}
+	if len(session.Proposal.Options) > 0 {
+		lines = append(lines, label.Render("Options: ")+text.Render(strings.Join(session.Proposal.Options, ", ")))
+	}
+
+	keys := make([]string, 0, len(session.Proposal.Context))
+	for key := range session.Proposal.Context {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, label.Render(key+": ")+text.Render(fmt.Sprintf("%v", session.Proposal.Context[key])))
+	}
+
+	votes := session.GetVotes()
+	lines = append(lines, "", label.Bold(true).Render(fmt.Sprintf("Votes (%d of %d required)", len(votes), session.MinVoters)))
+	if len(votes) == 0 {
+		lines = append(lines, styles.BaseStyle.Foreground(styles.ForgroundDim).Render("No votes yet"))
+	}
+	for _, vote := range votes {
+		mark := styles.BaseStyle.Foreground(styles.Green).Render("✓")
+		if !vote.Decision {
+			mark = styles.BaseStyle.Foreground(styles.Error).Render("✗")
+		}
+		line := fmt.Sprintf("%s %s (%.0f%%)", mark, vote.AgentID, vote.Confidence*100)
+		if vote.Reasoning != "" {
+			line += " " + styles.BaseStyle.Foreground(styles.ForgroundDim).Render(vote.Reasoning)
+		}
+		lines = append(lines, line)
+	}
+
+	// Whatever is left below the table
+	available := m.height - m.tableHeight() - 5
+	if available < 1 {
+		available = 1
+	}
+	if len(lines) > available {
+		lines = lines[:available]
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// tableHeight gives the session list half of the screen
+func (m *VoteReview) tableHeight() int {
+	height := (m.height - 5) / 2
+	if height < 5 {
+		height = 5
+	}
+	return height
+}
+
+// SetSize sets the size of the review screen
+func (m *VoteReview) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	// ID, by, type, votes and deadline are fixed, the proposal takes the rest
+	rest := width - 8 - 12 - 10 - 9 - 9 - 12
+	if rest < 20 {
+		rest = 20
+	}
+	m.table.SetColumns([]bubbletable.Column{
+		{Title: "ID", Width: 8},
+		{Title: "Proposal", Width: rest},
+		{Title: "By", Width: 12},
+		{Title: "Type", Width: 10},
+		{Title: "Votes", Width: 9},
+		{Title: "Deadline", Width: 9},
+	})
+	m.table.SetSize(width, m.tableHeight())
+	m.reason.Width = width - 12
+}
@@ -0,0 +1,306 @@
+// Package keymap holds the key bindings of the TUI. Every binding has an
+// action name, e.g. "app.quit", that the user config can rebind:
+//
+//	"tui": {"keybindings": {"app.logs": ["ctrl+y"], "sidebar.toggleLSP": ["L"]}}
+//
+// Bindings are grouped in scopes. Keys must be unique within a scope and
+// must not shadow a global binding.
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// Scope groups the bindings that are active at the same time
+type Scope string
+
+const (
+	// ScopeGlobal bindings work on every page
+	ScopeGlobal      Scope = "app"
+	ScopeChat        Scope = "chat"
+	ScopeSidebar     Scope = "sidebar"
+	ScopeTools       Scope = "tools"
+	ScopeFileBrowser Scope = "filebrowser"
+)
+
+// Action names a bindable command as "<scope>.<name>"
+type Action string
+
+const (
+	Quit          Action = "app.quit"
+	Help          Action = "app.help"
+	Logs          Action = "app.logs"
+	SwitchSession Action = "app.switchSession"
+	Commands      Action = "app.commands"
+	SwarmHealth   Action = "app.swarmHealth"
+
+	NewSession    Action = "chat.newSession"
+	Cancel        Action = "chat.cancel"
+	SidebarPrefix Action = "chat.sidebarPrefix"
+
+	// Sidebar toggles are pressed after the sidebar prefix
+	ToggleSession       Action = "sidebar.toggleSession"
+	ToggleLSP           Action = "sidebar.toggleLSP"
+	ToggleModifiedFiles Action = "sidebar.toggleModifiedFiles"
+	ToggleProgress      Action = "sidebar.toggleProgress"
+	ToggleFilesystem    Action = "sidebar.toggleFilesystem"
+	ToggleSystemInfo    Action = "sidebar.toggleSystemInfo"
+
+	ToolsUp     Action = "tools.up"
+	ToolsDown   Action = "tools.down"
+	ToolsOpen   Action = "tools.open"
+	ToolsFilter Action = "tools.filter"
+	ToolsBack   Action = "tools.back"
+
+	FileBrowserOpen   Action = "filebrowser.open"
+	FileBrowserParent Action = "filebrowser.parent"
+	FileBrowserClose  Action = "filebrowser.close"
+)
+
+// Scope returns the scope encoded in the action name
+func (a Action) Scope() Scope {
+	scope, _, _ := strings.Cut(string(a), ".")
+	return Scope(scope)
+}
+
+// definition is the default binding of an action
+type definition struct {
+	action Action
+	keys   []string
+	help   string
+}
+
+var defaults = []definition{
+	{Quit, []string{"ctrl+c"}, "quit"},
+	{Help, []string{"ctrl+_"}, "toggle help"},
+	{Logs, []string{"ctrl+l"}, "logs"},
+	{SwitchSession, []string{"ctrl+a"}, "switch session"},
+	{Commands, []string{"ctrl+k"}, "commands"},
+	{SwarmHealth, []string{"ctrl+g"}, "swarm health"},
+
+	{NewSession, []string{"ctrl+n"}, "new session"},
+	{Cancel, []string{"esc"}, "cancel"},
+	{SidebarPrefix, []string{"ctrl+t"}, "sidebar sections"},
+
+	{ToggleSession, []string{"s"}, "toggle session section"},
+	{ToggleLSP, []string{"l"}, "toggle LSP section"},
+	{ToggleModifiedFiles, []string{"m"}, "toggle modified files"},
+	{ToggleProgress, []string{"p"}, "toggle progress"},
+	{ToggleFilesystem, []string{"f"}, "toggle filesystem"},
+	{ToggleSystemInfo, []string{"i"}, "toggle system info"},
+
+	{ToolsUp, []string{"up", "k"}, "previous tool"},
+	{ToolsDown, []string{"down", "j"}, "next tool"},
+	{ToolsOpen, []string{"enter"}, "open tool"},
+	{ToolsFilter, []string{"/"}, "filter tools"},
+	{ToolsBack, []string{"q", "esc"}, "return"},
+
+	{FileBrowserOpen, []string{"enter"}, "open"},
+	{FileBrowserParent, []string{"backspace"}, "parent directory"},
+	{FileBrowserClose, []string{"q", "esc"}, "close"},
+}
+
+// helpNames renders keys whose terminal name differs from what users expect
+var helpNames = map[string]string{
+	"ctrl+_": "ctrl+?",
+	"up":     "↑",
+	"down":   "↓",
+}
+
+// prefixed maps the scopes whose keys are read after another binding to that
+// binding. Their keys only conflict among themselves.
+var prefixed = map[Scope]Action{
+	ScopeSidebar: SidebarPrefix,
+}
+
+// Conflict reports two actions bound to the same key
+type Conflict struct {
+	Key     string
+	Actions []Action
+}
+
+func (c Conflict) Error() string {
+	names := make([]string, len(c.Actions))
+	for i, a := range c.Actions {
+		names[i] = string(a)
+	}
+	return fmt.Sprintf("key %q is bound to %s", c.Key, strings.Join(names, " and "))
+}
+
+// KeyMap is a set of bindings by action
+type KeyMap struct {
+	bindings map[Action]key.Binding
+	order    []Action
+}
+
+// New builds the default key map with the overrides applied. Overrides map
+// action names to keys. Overrides with unknown actions or no keys are
+// skipped and reported. Conflicting keys are reported but kept, so the
+// returned key map is always usable.
+func New(overrides map[string][]string) (*KeyMap, []error) {
+	km := &KeyMap{bindings: make(map[Action]key.Binding)}
+	known := make(map[Action]definition)
+	for _, def := range defaults {
+		known[def.action] = def
+		km.order = append(km.order, def.action)
+		km.bindings[def.action] = binding(def.keys, def.help)
+	}
+
+	var errs []error
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def, ok := known[Action(name)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown key binding action %q", name))
+			continue
+		}
+		keys := overrides[name]
+		if len(keys) == 0 {
+			errs = append(errs, fmt.Errorf("key binding %q has no keys", name))
+			continue
+		}
+		km.bindings[def.action] = binding(keys, def.help)
+	}
+
+	for _, conflict := range km.Conflicts() {
+		errs = append(errs, conflict)
+	}
+	return km, errs
+}
+
+func binding(keys []string, help string) key.Binding {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k
+		if name, ok := helpNames[k]; ok {
+			names[i] = name
+		}
+	}
+	return key.NewBinding(
+		key.WithKeys(keys...),
+		key.WithHelp(strings.Join(names, "/"), help),
+	)
+}
+
+// Get returns the binding of an action
+func (km *KeyMap) Get(action Action) key.Binding {
+	return km.bindings[action]
+}
+
+// Scope returns the bindings of a scope in definition order, for help
+// overlays. Sidebar toggles are shown with their prefix.
+func (km *KeyMap) Scope(scope Scope) []key.Binding {
+	var bindings []key.Binding
+	prefix, hasPrefix := prefixed[scope]
+	for _, action := range km.order {
+		if action.Scope() != scope {
+			continue
+		}
+		b := km.bindings[action]
+		if hasPrefix {
+			h := b.Help()
+			b = key.NewBinding(
+				key.WithKeys(b.Keys()...),
+				key.WithHelp(km.bindings[prefix].Help().Key+" "+h.Key, h.Desc),
+			)
+		}
+		bindings = append(bindings, b)
+	}
+	return bindings
+}
+
+// Conflicts returns the keys bound to more than one action in a scope,
+// including keys of a directly pressed scope that shadow a global binding.
+// Some defaults share keys on purpose, e.g. esc cancels in the chat and
+// closes the file browser, which is fine because they are in different
+// scopes.
+func (km *KeyMap) Conflicts() []Conflict {
+	byKey := make(map[Scope]map[string][]Action)
+	add := func(scope Scope, k string, action Action) {
+		if byKey[scope] == nil {
+			byKey[scope] = make(map[string][]Action)
+		}
+		for _, existing := range byKey[scope][k] {
+			if existing == action {
+				return
+			}
+		}
+		byKey[scope][k] = append(byKey[scope][k], action)
+	}
+
+	for _, action := range km.order {
+		for _, k := range km.bindings[action].Keys() {
+			add(action.Scope(), k, action)
+		}
+	}
+
+	// Global keys are handled before any page sees them, so they shadow
+	// directly pressed keys in every other scope
+	for scope, keys := range byKey {
+		if _, ok := prefixed[scope]; ok || scope == ScopeGlobal {
+			continue
+		}
+		for k, actions := range keys {
+			if globals, ok := byKey[ScopeGlobal][k]; ok {
+				byKey[scope][k] = append(append([]Action{}, globals...), actions...)
+			}
+		}
+	}
+
+	var conflicts []Conflict
+	for _, keys := range byKey {
+		for k, actions := range keys {
+			if len(actions) > 1 {
+				conflicts = append(conflicts, Conflict{Key: k, Actions: actions})
+			}
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Actions[0] != conflicts[j].Actions[0] {
+			return conflicts[i].Actions[0] < conflicts[j].Actions[0]
+		}
+		return conflicts[i].Key < conflicts[j].Key
+	})
+	return conflicts
+}
+
+var (
+	active   *KeyMap
+	activeMu sync.RWMutex
+)
+
+// SetActive makes km the key map used by the TUI
+func SetActive(km *KeyMap) {
+	activeMu.Lock()
+	defer activeMu.Unlock()
+	active = km
+}
+
+// Active returns the key map used by the TUI, the defaults until SetActive
+// is called
+func Active() *KeyMap {
+	activeMu.RLock()
+	km := active
+	activeMu.RUnlock()
+	if km != nil {
+		return km
+	}
+
+	km, _ = New(nil)
+	SetActive(km)
+	return km
+}
+
+// Get returns the active binding of an action
+func Get(action Action) key.Binding {
+	return Active().Get(action)
+}
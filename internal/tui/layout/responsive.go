@@ -0,0 +1,44 @@
+package layout
+
+// Breakpoint classifies an available width so components can hide, stack or
+// reflow content on narrow terminals
+type Breakpoint int
+
+const (
+	// BreakpointCompact is narrower than MediumWidth
+	BreakpointCompact Breakpoint = iota
+	// BreakpointMedium is at least MediumWidth but narrower than WideWidth
+	BreakpointMedium
+	// BreakpointWide is WideWidth or more
+	BreakpointWide
+)
+
+const (
+	// MediumWidth is the narrowest width laid out as medium
+	MediumWidth = 80
+	// WideWidth is the narrowest width laid out as wide
+	WideWidth = 100
+)
+
+// BreakpointFor returns the breakpoint of a width
+func BreakpointFor(width int) Breakpoint {
+	switch {
+	case width >= WideWidth:
+		return BreakpointWide
+	case width >= MediumWidth:
+		return BreakpointMedium
+	default:
+		return BreakpointCompact
+	}
+}
+
+func (b Breakpoint) String() string {
+	switch b {
+	case BreakpointCompact:
+		return "compact"
+	case BreakpointMedium:
+		return "medium"
+	default:
+		return "wide"
+	}
+}
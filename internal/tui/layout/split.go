@@ -26,6 +26,11 @@ type splitPaneLayout struct {
 	ratio         float64
 	verticalRatio float64
 
+	// On medium widths the right panel is stacked below the left one and
+	// gets 1-stackedRatio of the height. On compact widths it is hidden.
+	stackedRatio float64
+	breakpoint   Breakpoint
+
 	rightPanel  Container
 	leftPanel   Container
 	bottomPanel Container
@@ -92,8 +97,14 @@ func (s *splitPaneLayout) View() string {
 
 	if s.leftPanel != nil && s.rightPanel != nil {
 		leftView := s.leftPanel.View()
-		rightView := s.rightPanel.View()
-		topSection = lipgloss.JoinHorizontal(lipgloss.Top, leftView, rightView)
+		switch s.breakpoint {
+		case BreakpointWide:
+			topSection = lipgloss.JoinHorizontal(lipgloss.Top, leftView, s.rightPanel.View())
+		case BreakpointMedium:
+			topSection = lipgloss.JoinVertical(lipgloss.Left, leftView, s.rightPanel.View())
+		default:
+			topSection = leftView
+		}
 	} else if s.leftPanel != nil {
 		topSection = s.leftPanel.View()
 	} else if s.rightPanel != nil {
@@ -128,6 +139,7 @@ func (s *splitPaneLayout) View() string {
 func (s *splitPaneLayout) SetSize(width, height int) tea.Cmd {
 	s.width = width
 	s.height = height
+	s.breakpoint = BreakpointFor(width)
 
 	var topHeight, bottomHeight int
 	if s.bottomPanel != nil {
@@ -139,9 +151,21 @@ func (s *splitPaneLayout) SetSize(width, height int) tea.Cmd {
 	}
 
 	var leftWidth, rightWidth int
+	leftHeight, rightHeight := topHeight, topHeight
 	if s.leftPanel != nil && s.rightPanel != nil {
-		leftWidth = int(float64(width) * s.ratio)
-		rightWidth = width - leftWidth
+		switch s.breakpoint {
+		case BreakpointWide:
+			leftWidth = int(float64(width) * s.ratio)
+			rightWidth = width - leftWidth
+		case BreakpointMedium:
+			leftWidth, rightWidth = width, width
+			leftHeight = int(float64(topHeight) * s.stackedRatio)
+			rightHeight = topHeight - leftHeight
+		default:
+			// The right panel keeps its last size while hidden
+			leftWidth = width
+			rightWidth, rightHeight = s.rightPanel.GetSize()
+		}
 	} else if s.leftPanel != nil {
 		leftWidth = width
 		rightWidth = 0
@@ -152,12 +176,12 @@ func (s *splitPaneLayout) SetSize(width, height int) tea.Cmd {
 
 	var cmds []tea.Cmd
 	if s.leftPanel != nil {
-		cmd := s.leftPanel.SetSize(leftWidth, topHeight)
+		cmd := s.leftPanel.SetSize(leftWidth, leftHeight)
 		cmds = append(cmds, cmd)
 	}
 
 	if s.rightPanel != nil {
-		cmd := s.rightPanel.SetSize(rightWidth, topHeight)
+		cmd := s.rightPanel.SetSize(rightWidth, rightHeight)
 		cmds = append(cmds, cmd)
 	}
 
@@ -244,6 +268,8 @@ func NewSplitPane(options ...SplitPaneOption) SplitPaneLayout {
 	layout := &splitPaneLayout{
 		ratio:           0.7,
 		verticalRatio:   0.9, // Default 80% for top section, 20% for bottom
+		stackedRatio:    0.65,
+		breakpoint:      BreakpointWide,
 		backgroundColor: styles.Background,
 	}
 	for _, option := range options {
@@ -287,3 +313,11 @@ func WithVerticalRatio(ratio float64) SplitPaneOption {
 		s.verticalRatio = ratio
 	}
 }
+
+// WithStackedRatio sets the share of the height given to the left panel
+// when the right panel is stacked below it on medium widths
+func WithStackedRatio(ratio float64) SplitPaneOption {
+	return func(s *splitPaneLayout) {
+		s.stackedRatio = ratio
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/opencode-ai/opencode/internal/session"
 	"github.com/opencode-ai/opencode/internal/tui/components/chat"
 	"github.com/opencode-ai/opencode/internal/tui/components/sidebar"
+	"github.com/opencode-ai/opencode/internal/tui/keymap"
 	"github.com/opencode-ai/opencode/internal/tui/layout"
 	"github.com/opencode-ai/opencode/internal/tui/util"
 )
@@ -22,22 +23,22 @@ type chatPage struct {
 	layout        layout.SplitPaneLayout
 	session       session.Session
 	useModularSidebar bool
+	// sidebar is the modular sidebar shown, closed when it is replaced
+	sidebar *sidebar.ModularSidebar
+	
+	// sidebarPrefix is set after the sidebar prefix key, so the next key
+	// toggles a sidebar section instead of reaching the editor
+	sidebarPrefix bool
 }
 
-type ChatKeyMap struct {
-	NewSession key.Binding
-	Cancel     key.Binding
-}
-
-var keyMap = ChatKeyMap{
-	NewSession: key.NewBinding(
-		key.WithKeys("ctrl+n"),
-		key.WithHelp("ctrl+n", "new session"),
-	),
-	Cancel: key.NewBinding(
-		key.WithKeys("esc"),
-		key.WithHelp("esc", "cancel"),
-	),
+// sidebarToggles are the actions read after the sidebar prefix key
+var sidebarToggles = []keymap.Action{
+	keymap.ToggleSession,
+	keymap.ToggleLSP,
+	keymap.ToggleModifiedFiles,
+	keymap.ToggleProgress,
+	keymap.ToggleFilesystem,
+	keymap.ToggleSystemInfo,
 }
 
 func (p *chatPage) Init() tea.Cmd {
@@ -67,14 +68,28 @@ func (p *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		p.session = msg
 	case tea.KeyMsg:
+		if p.sidebarPrefix {
+			p.sidebarPrefix = false
+			for _, action := range sidebarToggles {
+				if key.Matches(msg, keymap.Get(action)) {
+					u, cmd := p.layout.Update(sidebar.ToggleSectionMsg{Action: action})
+					p.layout = u.(layout.SplitPaneLayout)
+					return p, cmd
+				}
+			}
+			return p, nil
+		}
 		switch {
-		case key.Matches(msg, keyMap.NewSession):
+		case key.Matches(msg, keymap.Get(keymap.SidebarPrefix)):
+			p.sidebarPrefix = true
+			return p, nil
+		case key.Matches(msg, keymap.Get(keymap.NewSession)):
 			p.session = session.Session{}
 			return p, tea.Batch(
 				p.clearSidebar(),
 				util.CmdHandler(chat.SessionClearedMsg{}),
 			)
-		case key.Matches(msg, keyMap.Cancel):
+		case key.Matches(msg, keymap.Get(keymap.Cancel)):
 			if p.session.ID != "" {
 				// Cancel the current session's generation process
 				// This allows users to interrupt long-running operations
@@ -91,10 +106,12 @@ func (p *chatPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (p *chatPage) setSidebar() tea.Cmd {
 	var sidebarModel tea.Model
+	p.closeSidebar()
 	
 	// Use the new modular sidebar by default
 	if p.useModularSidebar {
 		sidebarModel = sidebar.NewModularSidebar(p.session, p.app.History)
+		p.sidebar, _ = sidebarModel.(*sidebar.ModularSidebar)
 	} else {
 		sidebarModel = chat.NewSidebarCmp(p.session, p.app.History)
 	}
@@ -107,9 +124,18 @@ func (p *chatPage) setSidebar() tea.Cmd {
 }
 
 func (p *chatPage) clearSidebar() tea.Cmd {
+	p.closeSidebar()
 	return p.layout.ClearRightPanel()
 }
 
+// closeSidebar ends the subscriptions of the modular sidebar shown
+func (p *chatPage) closeSidebar() {
+	if p.sidebar != nil {
+		p.sidebar.Close()
+		p.sidebar = nil
+	}
+}
+
 func (p *chatPage) sendMessage(text string) tea.Cmd {
 	var cmds []tea.Cmd
 	if p.session.ID == "" {
@@ -143,7 +169,10 @@ func (p *chatPage) View() string {
 }
 
 func (p *chatPage) BindingKeys() []key.Binding {
-	bindings := layout.KeyMapToSlice(keyMap)
+	bindings := keymap.Active().Scope(keymap.ScopeChat)
+	if p.useModularSidebar {
+		bindings = append(bindings, keymap.Active().Scope(keymap.ScopeSidebar)...)
+	}
 	bindings = append(bindings, p.messages.BindingKeys()...)
 	return bindings
 }
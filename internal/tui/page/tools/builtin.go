@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/opencode-ai/opencode/internal/config"
+	"github.com/opencode-ai/opencode/internal/tui/components/diffviewer"
+	"github.com/opencode-ai/opencode/internal/tui/components/envviewer"
+	"github.com/opencode-ai/opencode/internal/tui/components/filebrowser"
+	"github.com/opencode-ai/opencode/internal/tui/components/httpclient"
+	"github.com/opencode-ai/opencode/internal/tui/components/inspector"
+	"github.com/opencode-ai/opencode/internal/tui/components/logviewer"
+	"github.com/opencode-ai/opencode/internal/tui/components/markdown"
+	"github.com/opencode-ai/opencode/internal/tui/components/ssh"
+)
+
+func init() {
+	RegisterTool("Markdown Viewer", "📖", func() Tool { return &readmeViewer{MarkdownViewer: markdown.NewMarkdownViewer()} },
+		WithDescription("View README and markdown files with beautiful rendering"))
+	RegisterTool("SSH Keys", "🔑", func() Tool { return &sshKeys{ssh.NewSSHKeyViewer()} },
+		WithDescription("View your SSH keys and configuration"))
+	RegisterTool("File Browser", "📂", func() Tool { return filebrowser.NewFileBrowser(config.WorkingDirectory()) },
+		WithDescription("Navigate project files with an interactive browser"))
+	RegisterTool("Log Viewer", "📜", func() Tool { return logviewer.NewLogViewer() },
+		WithDescription("Tail a log file with filtering and level highlighting"))
+	RegisterTool("HTTP Client", "🌐", func() Tool { return httpclient.NewHTTPClient() },
+		WithDescription("Compose requests and inspect responses, with history per session"))
+	RegisterTool("JSON/YAML Inspector", "🧾", func() Tool { return inspector.NewInspector() },
+		WithDescription("Validate, browse and convert structured documents"))
+	RegisterTool("Diff Viewer", "🔀", func() Tool { return diffviewer.NewDiffViewer() },
+		WithDescription("Compare files, a file against HEAD, or git revisions"))
+	RegisterTool("Environment", "🌱", func() Tool { return envviewer.NewEnvViewer() },
+		WithDescription("Browse environment variables and export them to the chat"))
+}
+
+// decisionsDir holds the decision records a swarm writes of its votes,
+// when its decisionsDir is set to the conventional docs/decisions
+const decisionsDir = "docs/decisions"
+
+// readmeViewer shows the project README when opened. Tab steps through the
+// decision records of the swarm after it.
+type readmeViewer struct {
+	*markdown.MarkdownViewer
+	docs  []string
+	index int
+}
+
+func (t *readmeViewer) Open() tea.Cmd {
+	wd := config.WorkingDirectory()
+	t.docs = []string{filepath.Join(wd, "README.md")}
+	records, _ := filepath.Glob(filepath.Join(wd, decisionsDir, "*.md"))
+	t.docs = append(t.docs, records...)
+	t.index = 0
+	help := "↑/↓: scroll • q/esc: close"
+	if len(t.docs) > 1 {
+		help = fmt.Sprintf("↑/↓: scroll • tab/shift+tab: next/previous of %d documents • q/esc: close", len(t.docs))
+	}
+	t.SetHelp(help)
+	t.show()
+	return nil
+}
+
+func (t *readmeViewer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok && len(t.docs) > 1 {
+		switch key.String() {
+		case "tab":
+			t.index = (t.index + 1) % len(t.docs)
+			t.show()
+			return t, nil
+		case "shift+tab":
+			t.index = (t.index + len(t.docs) - 1) % len(t.docs)
+			t.show()
+			return t, nil
+		}
+	}
+	_, cmd := t.MarkdownViewer.Update(msg)
+	return t, cmd
+}
+
+// show renders the selected document
+func (t *readmeViewer) show() {
+	path := t.docs[t.index]
+	content, err := os.ReadFile(path)
+	if err != nil && t.index == 0 {
+		t.SetTitle("Markdown Preview")
+		_ = t.SetContent("# Markdown Viewer\n\nNo README.md found in the current directory.\n\nThis viewer uses Glamour to render markdown beautifully in the terminal.")
+		return
+	}
+	title := filepath.Base(path)
+	if t.index > 0 {
+		title = filepath.Join(decisionsDir, title)
+	}
+	t.SetTitle(title)
+	if err != nil {
+		_ = t.SetContent("Failed to read " + title + ": " + err.Error())
+		return
+	}
+	_ = t.SetContent(string(content))
+}
+
+// sshKeys reloads the keys every time it is opened
+type sshKeys struct {
+	*ssh.SSHKeyViewer
+}
+
+func (t *sshKeys) Open() tea.Cmd {
+	_ = t.LoadKeys()
+	return nil
+}
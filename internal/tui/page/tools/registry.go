@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Tool is a component shown full screen on the tools page
+type Tool interface {
+	Init() tea.Cmd
+	Update(msg tea.Msg) (tea.Model, tea.Cmd)
+	View() string
+	SetSize(width, height int)
+}
+
+// Opener is implemented by tools that need to prepare when they are shown,
+// for example to focus an input or reload data
+type Opener interface {
+	Open() tea.Cmd
+}
+
+// InputCapturer is implemented by tools with text inputs. While Capturing
+// returns true, q and esc are sent to the tool instead of closing it.
+type InputCapturer interface {
+	Capturing() bool
+}
+
+// SessionAware is implemented by tools that keep state per chat session
+type SessionAware interface {
+	SetSession(sessionID string)
+}
+
+// ToolFactory creates a tool. It is called once per tools page, the first
+// time the tool is opened.
+type ToolFactory func() Tool
+
+// ToolOption configures a registered tool
+type ToolOption func(*ToolRegistration)
+
+// WithDescription sets the text shown next to the tool in the menu
+func WithDescription(description string) ToolOption {
+	return func(r *ToolRegistration) {
+		r.Description = description
+	}
+}
+
+// ToolRegistration describes a tool listed in the tools menu
+type ToolRegistration struct {
+	Name        string
+	Icon        string
+	Description string
+	Factory     ToolFactory
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   []ToolRegistration
+)
+
+// RegisterTool adds a tool to the tools menu. Tools are listed in
+// registration order; registering an existing name replaces it in place.
+func RegisterTool(name, icon string, factory ToolFactory, opts ...ToolOption) {
+	reg := ToolRegistration{
+		Name:    name,
+		Icon:    icon,
+		Factory: factory,
+	}
+	for _, opt := range opts {
+		opt(&reg)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, existing := range registry {
+		if existing.Name == name {
+			registry[i] = reg
+			return
+		}
+	}
+	registry = append(registry, reg)
+}
+
+// RegisteredTools returns the tools in menu order
+func RegisteredTools() []ToolRegistration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]ToolRegistration(nil), registry...)
+}
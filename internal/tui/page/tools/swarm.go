@@ -0,0 +1,49 @@
+package tools
+
+import (
+	"github.com/opencode-ai/opencode/internal/swarm"
+	"github.com/opencode-ai/opencode/internal/tui/components/activityreport"
+	"github.com/opencode-ai/opencode/internal/tui/components/comparison"
+	"github.com/opencode-ai/opencode/internal/tui/components/memorybrowser"
+	"github.com/opencode-ai/opencode/internal/tui/components/promotionreview"
+	"github.com/opencode-ai/opencode/internal/tui/components/rulemanager"
+	"github.com/opencode-ai/opencode/internal/tui/components/swarmhealth"
+	"github.com/opencode-ai/opencode/internal/tui/components/taskqueue"
+	"github.com/opencode-ai/opencode/internal/tui/components/timeline"
+	"github.com/opencode-ai/opencode/internal/tui/components/topology"
+	"github.com/opencode-ai/opencode/internal/tui/components/votereview"
+)
+
+// SwarmHealthTool is the name of the swarm health dashboard
+const SwarmHealthTool = "Swarm Health"
+
+// RegisterSwarmHealth adds the swarm health dashboard. It works with the
+// Swarm opencode embeds as well as with a Coordinator.
+func RegisterSwarmHealth(source swarmhealth.HealthSource) {
+	RegisterTool(SwarmHealthTool, "🩺", func() Tool { return swarmhealth.NewDashboard(source) },
+		WithDescription("Health score, component checks and alerts of the swarm"))
+}
+
+// RegisterSwarmTools adds the tools that inspect and steer a running swarm.
+// Call it before the TUI starts when a coordinator runs in-process.
+func RegisterSwarmTools(c *swarm.Coordinator) {
+	RegisterSwarmHealth(c)
+	RegisterTool("Vote Review", "🗳", func() Tool { return votereview.NewVoteReview(c.GetVotingSystem()) },
+		WithDescription("Approve, deny or veto open swarm proposals"))
+	RegisterTool("Memory Browser", "🧠", func() Tool { return memorybrowser.NewMemoryBrowser(c.GetMemoryStore()) },
+		WithDescription("Search, pin, tag and delete swarm memories"))
+	RegisterTool("Memory Promotions", "⬆", func() Tool { return promotionreview.NewPromotionReview(c) },
+		WithDescription("Approve session memories for the global namespace"))
+	RegisterTool("Rules", "📏", func() Tool { return rulemanager.NewRuleManager(c.GetRuleEngine()) },
+		WithDescription("Toggle, inspect and edit swarm rules"))
+	RegisterTool("Task Queue", "📋", func() Tool { return taskqueue.NewBrowser(c) },
+		WithDescription("Inspect, cancel, retry and reprioritize swarm tasks"))
+	RegisterTool("Timeline", "🕒", func() Tool { return timeline.NewTimeline(c) },
+		WithDescription("Follow tasks, votes, alerts and recoveries as they happen"))
+	RegisterTool("Topology", "🕸", func() Tool { return topology.NewView(c) },
+		WithDescription("Agents, their message rates and how they depend on each other"))
+	RegisterTool("Shadow Comparisons", "⚖", func() Tool { return comparison.NewReport(c) },
+		WithDescription("Compare shadow agents to the agents they shadowed"))
+	RegisterTool("Activity Reports", "📰", func() Tool { return activityreport.NewBrowser(c) },
+		WithDescription("Browse daily and weekly reports of the swarm's activity"))
+}
@@ -1,54 +1,63 @@
 package tools
 
 import (
-	"os"
+	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/opencode-ai/opencode/internal/config"
-	"github.com/opencode-ai/opencode/internal/tui/components/filebrowser"
-	"github.com/opencode-ai/opencode/internal/tui/components/markdown"
-	"github.com/opencode-ai/opencode/internal/tui/components/ssh"
+	"github.com/charmbracelet/x/ansi"
+	zone "github.com/lrstanley/bubblezone"
+	"github.com/opencode-ai/opencode/internal/tui/components/chat"
+	"github.com/opencode-ai/opencode/internal/tui/keymap"
+	"github.com/opencode-ai/opencode/internal/tui/layout"
 	"github.com/opencode-ai/opencode/internal/tui/styles"
 )
 
-// ToolType represents different tool views
-type ToolType int
-
-const (
-	ToolNone ToolType = iota
-	ToolMarkdownViewer
-	ToolSSHKeys
-	ToolFileBrowser
-)
-
 // ToolsPage is a page that showcases various tools and utilities
 type ToolsPage struct {
 	width  int
 	height int
-	
-	// Current tool being displayed
-	currentTool ToolType
-	
-	// Tool components
-	markdownViewer *markdown.MarkdownViewer
-	sshViewer      *ssh.SSHKeyViewer
-	fileBrowser    *filebrowser.FileBrowser
+
+	// Tools created so far, by name. Tools are created the first time they
+	// are opened and kept so they retain their state.
+	instances map[string]Tool
+	// Name of the tool being displayed, empty while the menu is shown
+	current string
+
+	sessionID string
+
+	// Menu state
+	cursor    int
+	offset    int
+	filter    textinput.Model
+	filtering bool
+
+	// Prefix for the mouse zones of the menu items
+	zonePrefix string
 }
 
 // NewToolsPage creates a new tools page
 func NewToolsPage() *ToolsPage {
-	workingDir := config.WorkingDirectory()
-	
+	filter := textinput.New()
+	filter.Prompt = "/"
+	filter.Placeholder = "filter tools"
+
 	return &ToolsPage{
-		currentTool:    ToolNone,
-		markdownViewer: markdown.NewMarkdownViewer(),
-		sshViewer:      ssh.NewSSHKeyViewer(),
-		fileBrowser:    filebrowser.NewFileBrowser(workingDir),
+		instances:  make(map[string]Tool),
+		filter:     filter,
+		zonePrefix: zone.NewPrefix(),
 	}
 }
 
+// OpenToolMsg opens a registered tool by name, e.g. from a key binding of
+// another page
+type OpenToolMsg struct {
+	Name string
+}
+
 // Init implements tea.Model
 func (m *ToolsPage) Init() tea.Cmd {
 	return nil
@@ -57,76 +66,232 @@ func (m *ToolsPage) Init() tea.Cmd {
 // Update implements tea.Model
 func (m *ToolsPage) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
-	
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		// Handle tool-specific keys
-		if m.currentTool != ToolNone {
-			switch m.currentTool {
-			case ToolMarkdownViewer:
-				_, cmd := m.markdownViewer.Update(msg)
-				cmds = append(cmds, cmd)
-			case ToolSSHKeys:
-				_, cmd := m.sshViewer.Update(msg)
-				cmds = append(cmds, cmd)
-			case ToolFileBrowser:
-				_, cmd := m.fileBrowser.Update(msg)
-				cmds = append(cmds, cmd)
+		if tool := m.activeTool(); tool != nil {
+			capturing := false
+			if c, ok := tool.(InputCapturer); ok {
+				capturing = c.Capturing()
 			}
-			
-			// Escape key to return to menu
-			if msg.String() == "esc" || msg.String() == "q" {
-				m.currentTool = ToolNone
+			_, cmd := tool.Update(msg)
+			// Text inputs consume q and esc themselves
+			if !capturing && key.Matches(msg, keymap.Get(keymap.ToolsBack)) {
+				m.current = ""
 			}
-			return m, tea.Batch(cmds...)
+			return m, cmd
 		}
-		
-		// Main menu keys
-		switch msg.String() {
-		case "1":
-			m.currentTool = ToolMarkdownViewer
-			// Load README as example
-			readmePath := config.WorkingDirectory() + "/README.md"
-			if content, err := os.ReadFile(readmePath); err == nil {
-				_ = m.markdownViewer.SetContent(string(content))
-			} else {
-				_ = m.markdownViewer.SetContent("# Markdown Viewer\n\nNo README.md found in the current directory.\n\nThis viewer uses Glamour to render markdown beautifully in the terminal.")
-			}
-		case "2":
-			m.currentTool = ToolSSHKeys
-			_ = m.sshViewer.LoadKeys()
-		case "3":
-			m.currentTool = ToolFileBrowser
-		case "q", "esc":
-			// Return to previous page would be handled by parent
+		return m, m.updateMenu(msg)
+	case tea.MouseMsg:
+		if tool := m.activeTool(); tool != nil {
+			_, cmd := tool.Update(msg)
+			return m, cmd
 		}
+		return m, m.menuMouse(msg)
 	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		
-		// Update component sizes
-		m.markdownViewer.SetSize(msg.Width, msg.Height)
-		m.sshViewer.SetSize(msg.Width, msg.Height)
-		m.fileBrowser.SetSize(msg.Width, msg.Height)
-	}
-	
+		return m, m.SetSize(msg.Width, msg.Height)
+	case OpenToolMsg:
+		for _, reg := range RegisteredTools() {
+			if reg.Name == msg.Name {
+				return m, m.open(reg)
+			}
+		}
+		return m, nil
+	case chat.SessionSelectedMsg:
+		m.setSession(msg.ID)
+		return m, nil
+	case chat.SessionClearedMsg:
+		m.setSession("")
+		return m, nil
+	}
+
+	// Everything else, e.g. async results and theme changes, reaches every
+	// tool that has been created so background work keeps flowing
+	for _, tool := range m.instances {
+		_, cmd := tool.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+
 	return m, tea.Batch(cmds...)
 }
 
+// InTool returns whether a tool is shown instead of the menu
+func (m *ToolsPage) InTool() bool {
+	return m.current != ""
+}
+
+// HandlesBackKeys returns whether q and backspace are used by the page, so
+// the app must not treat them as navigation back to the chat
+func (m *ToolsPage) HandlesBackKeys() bool {
+	return m.InTool() || m.filtering
+}
+
+func (m *ToolsPage) activeTool() Tool {
+	if m.current == "" {
+		return nil
+	}
+	return m.instances[m.current]
+}
+
+// open shows a tool, creating it on first use
+func (m *ToolsPage) open(reg ToolRegistration) tea.Cmd {
+	var cmds []tea.Cmd
+	tool, ok := m.instances[reg.Name]
+	if !ok {
+		tool = reg.Factory()
+		tool.SetSize(m.width, m.height)
+		if s, ok := tool.(SessionAware); ok {
+			s.SetSession(m.sessionID)
+		}
+		m.instances[reg.Name] = tool
+		cmds = append(cmds, tool.Init())
+	}
+	m.current = reg.Name
+	if o, ok := tool.(Opener); ok {
+		cmds = append(cmds, o.Open())
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *ToolsPage) setSession(sessionID string) {
+	m.sessionID = sessionID
+	for _, tool := range m.instances {
+		if s, ok := tool.(SessionAware); ok {
+			s.SetSession(sessionID)
+		}
+	}
+}
+
+// visibleTools returns the registered tools matching the menu filter
+func (m *ToolsPage) visibleTools() []ToolRegistration {
+	all := RegisteredTools()
+	query := strings.ToLower(strings.TrimSpace(m.filter.Value()))
+	if query == "" {
+		return all
+	}
+	var matches []ToolRegistration
+	for _, reg := range all {
+		if strings.Contains(strings.ToLower(reg.Name), query) ||
+			strings.Contains(strings.ToLower(reg.Description), query) {
+			matches = append(matches, reg)
+		}
+	}
+	return matches
+}
+
+func (m *ToolsPage) updateMenu(msg tea.KeyMsg) tea.Cmd {
+	if m.filtering {
+		switch msg.String() {
+		case "esc":
+			m.filtering = false
+			m.filter.Blur()
+			m.filter.SetValue("")
+			m.cursor, m.offset = 0, 0
+			return nil
+		case "enter", "up", "down":
+			m.filtering = false
+			m.filter.Blur()
+			if msg.String() != "enter" {
+				break
+			}
+			if tools := m.visibleTools(); len(tools) > 0 {
+				return m.open(tools[m.cursor])
+			}
+			return nil
+		default:
+			var cmd tea.Cmd
+			m.filter, cmd = m.filter.Update(msg)
+			m.cursor, m.offset = 0, 0
+			return cmd
+		}
+	}
+
+	tools := m.visibleTools()
+	switch {
+	case key.Matches(msg, keymap.Get(keymap.ToolsFilter)):
+		m.filtering = true
+		return m.filter.Focus()
+	case key.Matches(msg, keymap.Get(keymap.ToolsUp)):
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case key.Matches(msg, keymap.Get(keymap.ToolsDown)):
+		if m.cursor < len(tools)-1 {
+			m.cursor++
+		}
+	case key.Matches(msg, keymap.Get(keymap.ToolsOpen)):
+		if m.cursor < len(tools) {
+			return m.open(tools[m.cursor])
+		}
+	case len(msg.String()) == 1 && msg.String() >= "1" && msg.String() <= "9":
+		// Number keys open one of the first nine listed tools
+		index := int(msg.String()[0] - '1')
+		if index < len(tools) {
+			m.cursor = index
+			return m.open(tools[index])
+		}
+	}
+	m.ensureCursorVisible()
+	return nil
+}
+
+// menuMouse moves the cursor with the wheel and opens a clicked tool
+func (m *ToolsPage) menuMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.filtering {
+		return nil
+	}
+	tools := m.visibleTools()
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case tea.MouseButtonWheelDown:
+		if m.cursor < len(tools)-1 {
+			m.cursor++
+		}
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress {
+			return nil
+		}
+		for i, reg := range tools {
+			if zone.Get(m.zonePrefix + reg.Name).InBounds(msg) {
+				m.cursor = i
+				return m.open(reg)
+			}
+		}
+	}
+	m.ensureCursorVisible()
+	return nil
+}
+
+// menuHeight is the number of tool rows that fit on the page
+func (m *ToolsPage) menuHeight() int {
+	// Title, subtitle, filter, help and spacing
+	height := m.height - 9
+	if height < 3 {
+		height = 3
+	}
+	return height
+}
+
+func (m *ToolsPage) ensureCursorVisible() {
+	height := m.menuHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+}
+
 // View implements tea.Model
 func (m *ToolsPage) View() string {
 	// Show tool-specific view if a tool is active
-	if m.currentTool != ToolNone {
-		switch m.currentTool {
-		case ToolMarkdownViewer:
-			return m.markdownViewer.View()
-		case ToolSSHKeys:
-			return m.sshViewer.View()
-		case ToolFileBrowser:
-			return m.fileBrowser.View()
-		}
+	if tool := m.activeTool(); tool != nil {
+		return tool.View()
 	}
-	
+
 	// Show main menu
 	return m.renderMenu()
 }
@@ -137,40 +302,89 @@ func (m *ToolsPage) renderMenu() string {
 		Bold(true).
 		Foreground(styles.PrimaryColor).
 		Render("🔧 OpenCode Tools")
-	
+
 	subtitle := styles.BaseStyle.
 		Foreground(styles.ForgroundDim).
 		Render("Enhanced features powered by Charm Bracelet")
-	
-	menuItems := []string{
-		"1. 📖 Markdown Viewer - View README and markdown files with beautiful rendering",
-		"2. 🔑 SSH Keys - View your SSH keys and configuration",
-		"3. 📂 File Browser - Navigate project files with an interactive browser",
+
+	// Narrow terminals drop the descriptions and align the menu left so
+	// tool names are not truncated
+	compact := layout.BreakpointFor(m.width) == layout.BreakpointCompact
+
+	tools := m.visibleTools()
+	end := m.offset + m.menuHeight()
+	if end > len(tools) {
+		end = len(tools)
 	}
-	
+
 	var styledItems []string
-	for _, item := range menuItems {
+	for i := m.offset; i < end; i++ {
+		reg := tools[i]
+		shortcut := "  "
+		if i < 9 {
+			shortcut = fmt.Sprintf("%d.", i+1)
+		}
+		item := fmt.Sprintf("%s %s %s", shortcut, reg.Icon, reg.Name)
+		if reg.Description != "" && !compact {
+			item += " - " + reg.Description
+		}
+
+		style := styles.BaseStyle.Foreground(styles.Forground)
+		prefix := "  "
+		if i == m.cursor {
+			style = styles.BaseStyle.Foreground(styles.PrimaryColor).Bold(true)
+			prefix = "> "
+		}
+		if m.width > 0 {
+			item = ansi.Truncate(item, m.width-len(prefix)-1, "…")
+		}
+		styledItems = append(styledItems, zone.Mark(m.zonePrefix+reg.Name, style.Render(prefix+item)))
+	}
+	if len(tools) == 0 {
 		styledItems = append(styledItems, styles.BaseStyle.
-			Foreground(styles.Forground).
-			Render("  "+item))
+			Foreground(styles.ForgroundDim).
+			Render("  No tools match the filter"))
+	}
+	if len(tools) > end-m.offset {
+		styledItems = append(styledItems, styles.BaseStyle.
+			Foreground(styles.ForgroundDim).
+			Render(fmt.Sprintf("  %d-%d of %d", m.offset+1, end, len(tools))))
+	}
+
+	filter := ""
+	if m.filtering || m.filter.Value() != "" {
+		filter = m.filter.View()
+	}
+
+	up, down := keymap.Get(keymap.ToolsUp).Help().Key, keymap.Get(keymap.ToolsDown).Help().Key
+	open := keymap.Get(keymap.ToolsOpen).Help().Key
+	filterKey := keymap.Get(keymap.ToolsFilter).Help().Key
+	back := keymap.Get(keymap.ToolsBack).Help().Key
+	helpText := fmt.Sprintf("\n%s/%s: select • %s/1-9: open • %s: filter • %s: return", up, down, open, filterKey, back)
+	if compact {
+		helpText = fmt.Sprintf("\n%s: open • %s: filter • %s: back", open, filterKey, back)
 	}
-	
 	help := styles.BaseStyle.
 		Foreground(styles.ForgroundDim).
-		Render("\nPress 1-3 to select a tool • q/esc to return")
-	
+		Render(helpText)
+
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
 		"",
 		title,
 		subtitle,
-		"",
+		filter,
 		"",
 		lipgloss.JoinVertical(lipgloss.Left, styledItems...),
 		"",
 		help,
 	)
-	
+
+	if compact {
+		return lipgloss.Place(m.width, m.height, lipgloss.Left, lipgloss.Top,
+			styles.BaseStyle.PaddingLeft(1).Render(content))
+	}
+
 	// Center the content
 	return lipgloss.Place(
 		m.width,
@@ -185,11 +399,12 @@ func (m *ToolsPage) renderMenu() string {
 func (m *ToolsPage) SetSize(width, height int) tea.Cmd {
 	m.width = width
 	m.height = height
-	
-	m.markdownViewer.SetSize(width, height)
-	m.sshViewer.SetSize(width, height)
-	m.fileBrowser.SetSize(width, height)
-	
+
+	for _, tool := range m.instances {
+		tool.SetSize(width, height)
+	}
+	m.ensureCursorVisible()
+
 	return nil
 }
 
@@ -200,23 +415,11 @@ func (m *ToolsPage) GetSize() (int, int) {
 
 // BindingKeys implements layout.Bindings
 func (m *ToolsPage) BindingKeys() []key.Binding {
-	if m.currentTool != ToolNone {
-		return []key.Binding{
-			key.NewBinding(
-				key.WithKeys("esc", "q"),
-				key.WithHelp("esc/q", "return to menu"),
-			),
-		}
-	}
-	
-	return []key.Binding{
-		key.NewBinding(
-			key.WithKeys("1", "2", "3"),
-			key.WithHelp("1-3", "select tool"),
-		),
-		key.NewBinding(
-			key.WithKeys("q", "esc"),
-			key.WithHelp("q/esc", "return"),
-		),
+	if m.InTool() {
+		back := keymap.Get(keymap.ToolsBack)
+		back.SetHelp(back.Help().Key, "return to menu")
+		return []key.Binding{back}
 	}
+
+	return keymap.Active().Scope(keymap.ScopeTools)
 }
@@ -0,0 +1,313 @@
+package styles
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Built-in theme names
+const (
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeHighContrast = "high-contrast"
+	ThemeCatppuccin   = "catppuccin"
+	ThemeCustom       = "custom"
+)
+
+// ThemeChangedMsg is sent after the active theme changes so components that
+// cache styles can rebuild them
+type ThemeChangedMsg struct {
+	Theme Theme
+}
+
+// Theme is a named set of colors applied to the package level style variables
+type Theme struct {
+	Name string
+	// Dark reports whether the theme is designed for a dark background, used
+	// by renderers such as glamour that need a base style
+	Dark bool
+	// Adaptive themes follow the terminal background instead of Dark
+	Adaptive bool
+
+	Background       lipgloss.AdaptiveColor
+	BackgroundDim    lipgloss.AdaptiveColor
+	BackgroundDarker lipgloss.AdaptiveColor
+	BorderColor      lipgloss.AdaptiveColor
+	Foreground       lipgloss.AdaptiveColor
+	ForegroundMid    lipgloss.AdaptiveColor
+	ForegroundDim    lipgloss.AdaptiveColor
+	Primary          lipgloss.AdaptiveColor
+
+	Red    lipgloss.AdaptiveColor
+	Green  lipgloss.AdaptiveColor
+	Yellow lipgloss.AdaptiveColor
+	Blue   lipgloss.AdaptiveColor
+	Peach  lipgloss.AdaptiveColor
+	Mauve  lipgloss.AdaptiveColor
+}
+
+var (
+	themesMu     sync.RWMutex
+	themes       = map[string]Theme{}
+	currentTheme Theme
+)
+
+func init() {
+	for _, t := range []Theme{darkTheme(), lightTheme(), highContrastTheme(), catppuccinTheme()} {
+		themes[t.Name] = t
+	}
+	currentTheme = themes[ThemeDark]
+}
+
+// solid returns an adaptive color using the same value for both backgrounds
+func solid(hex string) lipgloss.AdaptiveColor {
+	return lipgloss.AdaptiveColor{Dark: hex, Light: hex}
+}
+
+func darkTheme() Theme {
+	return Theme{
+		Name:             ThemeDark,
+		Dark:             true,
+		Background:       solid("#212121"),
+		BackgroundDim:    solid("#2c2c2c"),
+		BackgroundDarker: solid("#181818"),
+		BorderColor:      solid("#4b4c5c"),
+		Foreground:       solid("#d3d3d3"),
+		ForegroundMid:    solid("#a0a0a0"),
+		ForegroundDim:    solid("#737373"),
+		Primary:          solid("#fab283"),
+		Red:              solid(dark.Red().Hex),
+		Green:            solid(dark.Green().Hex),
+		Yellow:           solid(dark.Yellow().Hex),
+		Blue:             solid(dark.Blue().Hex),
+		Peach:            solid(dark.Peach().Hex),
+		Mauve:            solid(dark.Mauve().Hex),
+	}
+}
+
+func lightTheme() Theme {
+	return Theme{
+		Name:             ThemeLight,
+		Dark:             false,
+		Background:       solid("#fafafa"),
+		BackgroundDim:    solid("#eeeeee"),
+		BackgroundDarker: solid("#e0e0e0"),
+		BorderColor:      solid("#bcc0cc"),
+		Foreground:       solid("#2c2c2c"),
+		ForegroundMid:    solid("#5c5c5c"),
+		ForegroundDim:    solid("#8c8c8c"),
+		Primary:          solid("#d2691e"),
+		Red:              solid(light.Red().Hex),
+		Green:            solid(light.Green().Hex),
+		Yellow:           solid(light.Yellow().Hex),
+		Blue:             solid(light.Blue().Hex),
+		Peach:            solid(light.Peach().Hex),
+		Mauve:            solid(light.Mauve().Hex),
+	}
+}
+
+func highContrastTheme() Theme {
+	return Theme{
+		Name:             ThemeHighContrast,
+		Dark:             true,
+		Background:       solid("#000000"),
+		BackgroundDim:    solid("#101010"),
+		BackgroundDarker: solid("#000000"),
+		BorderColor:      solid("#ffffff"),
+		Foreground:       solid("#ffffff"),
+		ForegroundMid:    solid("#e0e0e0"),
+		ForegroundDim:    solid("#c0c0c0"),
+		Primary:          solid("#ffff00"),
+		Red:              solid("#ff5555"),
+		Green:            solid("#55ff55"),
+		Yellow:           solid("#ffff55"),
+		Blue:             solid("#5599ff"),
+		Peach:            solid("#ffaa55"),
+		Mauve:            solid("#ff55ff"),
+	}
+}
+
+// catppuccinTheme adapts to the terminal background using Mocha and Latte
+func catppuccinTheme() Theme {
+	return Theme{
+		Name:             ThemeCatppuccin,
+		Dark:             true,
+		Adaptive:         true,
+		Background:       lipgloss.AdaptiveColor{Dark: dark.Base().Hex, Light: light.Base().Hex},
+		BackgroundDim:    lipgloss.AdaptiveColor{Dark: dark.Mantle().Hex, Light: light.Mantle().Hex},
+		BackgroundDarker: lipgloss.AdaptiveColor{Dark: dark.Crust().Hex, Light: light.Crust().Hex},
+		BorderColor:      lipgloss.AdaptiveColor{Dark: dark.Surface1().Hex, Light: light.Surface1().Hex},
+		Foreground:       lipgloss.AdaptiveColor{Dark: dark.Text().Hex, Light: light.Text().Hex},
+		ForegroundMid:    lipgloss.AdaptiveColor{Dark: dark.Subtext1().Hex, Light: light.Subtext1().Hex},
+		ForegroundDim:    lipgloss.AdaptiveColor{Dark: dark.Overlay1().Hex, Light: light.Overlay1().Hex},
+		Primary:          lipgloss.AdaptiveColor{Dark: dark.Peach().Hex, Light: light.Peach().Hex},
+		Red:              lipgloss.AdaptiveColor{Dark: dark.Red().Hex, Light: light.Red().Hex},
+		Green:            lipgloss.AdaptiveColor{Dark: dark.Green().Hex, Light: light.Green().Hex},
+		Yellow:           lipgloss.AdaptiveColor{Dark: dark.Yellow().Hex, Light: light.Yellow().Hex},
+		Blue:             lipgloss.AdaptiveColor{Dark: dark.Blue().Hex, Light: light.Blue().Hex},
+		Peach:            lipgloss.AdaptiveColor{Dark: dark.Peach().Hex, Light: light.Peach().Hex},
+		Mauve:            lipgloss.AdaptiveColor{Dark: dark.Mauve().Hex, Light: light.Mauve().Hex},
+	}
+}
+
+// IsDark reports whether the theme renders on a dark background
+func (t Theme) IsDark() bool {
+	if t.Adaptive {
+		return lipgloss.HasDarkBackground()
+	}
+	return t.Dark
+}
+
+// RegisterTheme adds or replaces a theme that can be selected by name
+func RegisterTheme(theme Theme) {
+	themesMu.Lock()
+	defer themesMu.Unlock()
+	themes[theme.Name] = theme
+}
+
+// AvailableThemes returns the names of all registered themes, sorted
+func AvailableThemes() []string {
+	themesMu.RLock()
+	defer themesMu.RUnlock()
+
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CurrentTheme returns the active theme
+func CurrentTheme() Theme {
+	themesMu.RLock()
+	defer themesMu.RUnlock()
+	return currentTheme
+}
+
+// SetTheme activates a registered theme by name
+func SetTheme(name string) error {
+	themesMu.RLock()
+	theme, ok := themes[name]
+	themesMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown theme: %s", name)
+	}
+	ApplyTheme(theme)
+	return nil
+}
+
+// NextTheme returns the name of the theme after the active one, for cycling
+func NextTheme() string {
+	names := AvailableThemes()
+	current := CurrentTheme().Name
+	for i, name := range names {
+		if name == current {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return names[0]
+}
+
+// ApplyTheme updates the package level colors, the colors derived from
+// them, the base style and the markdown styles. Components that read the
+// style variables while rendering pick up the change on their next View.
+func ApplyTheme(theme Theme) {
+	themesMu.Lock()
+	currentTheme = theme
+	themesMu.Unlock()
+
+	Background = theme.Background
+	BackgroundDim = theme.BackgroundDim
+	BackgroundDarker = theme.BackgroundDarker
+	BorderColor = theme.BorderColor
+	Forground = theme.Foreground
+	ForgroundMid = theme.ForegroundMid
+	ForgroundDim = theme.ForegroundDim
+	PrimaryColor = theme.Primary
+
+	Red = theme.Red
+	Green = theme.Green
+	Yellow = theme.Yellow
+	Blue = theme.Blue
+	Peach = theme.Peach
+	Mauve = theme.Mauve
+
+	Primary = Blue
+	Secondary = Mauve
+	Warning = Peach
+	Error = Red
+
+	// Secondary text and surfaces
+	Text = theme.Foreground
+	SubText1 = theme.ForegroundMid
+	SubText0 = theme.ForegroundDim
+	Base = theme.Background
+	Crust = theme.BackgroundDarker
+	LightGrey = theme.BackgroundDim
+	Grey = theme.BorderColor
+	DarkGrey = theme.ForegroundDim
+
+	BaseStyle = lipgloss.NewStyle().
+		Background(Background).
+		Foreground(Forground)
+
+	// The markdown styles embed colors and the base style, so they are built
+	// last
+	ASCIIStyleConfig = asciiStyleConfig()
+	DraculaStyleConfig = draculaStyleConfig()
+}
+
+// CustomTheme builds a theme from a base theme with colors overridden by
+// name. Keys match the Theme field names in lower camel case, e.g.
+// "background", "foregroundDim" or "primary"; values are hex colors.
+func CustomTheme(base Theme, colors map[string]string) (Theme, error) {
+	theme := base
+	theme.Name = ThemeCustom
+
+	fields := map[string]*lipgloss.AdaptiveColor{
+		"background":       &theme.Background,
+		"backgroundDim":    &theme.BackgroundDim,
+		"backgroundDarker": &theme.BackgroundDarker,
+		"borderColor":      &theme.BorderColor,
+		"foreground":       &theme.Foreground,
+		"foregroundMid":    &theme.ForegroundMid,
+		"foregroundDim":    &theme.ForegroundDim,
+		"primary":          &theme.Primary,
+		"red":              &theme.Red,
+		"green":            &theme.Green,
+		"yellow":           &theme.Yellow,
+		"blue":             &theme.Blue,
+		"peach":            &theme.Peach,
+		"mauve":            &theme.Mauve,
+	}
+
+	for key, value := range colors {
+		field, ok := fields[key]
+		if !ok {
+			return base, fmt.Errorf("unknown theme color: %s", key)
+		}
+		if !isHexColor(value) {
+			return base, fmt.Errorf("invalid color for %s: %s", key, value)
+		}
+		*field = solid(value)
+	}
+
+	return theme, nil
+}
+
+func isHexColor(value string) bool {
+	if !strings.HasPrefix(value, "#") || (len(value) != 7 && len(value) != 4) {
+		return false
+	}
+	for _, c := range value[1:] {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+package styles
+
+import "testing"
+
+func TestApplyThemeDerivedStyles(t *testing.T) {
+	previous := CurrentTheme()
+	t.Cleanup(func() { ApplyTheme(previous) })
+
+	ApplyTheme(darkTheme())
+	darkMarkdown := *DraculaStyleConfig.Document.Color
+
+	theme := lightTheme()
+	ApplyTheme(theme)
+
+	if Text != theme.Foreground {
+		t.Errorf("Text = %v, want the foreground %v", Text, theme.Foreground)
+	}
+	if Grey != theme.BorderColor {
+		t.Errorf("Grey = %v, want the border color %v", Grey, theme.BorderColor)
+	}
+	if SubText0 != theme.ForegroundDim {
+		t.Errorf("SubText0 = %v, want the dim foreground %v", SubText0, theme.ForegroundDim)
+	}
+
+	if got := *DraculaStyleConfig.Document.Color; got != theme.Foreground.Dark || got == darkMarkdown {
+		t.Errorf("focused markdown text = %s, want %s of the light theme", got, theme.Foreground.Dark)
+	}
+	if got := *DraculaStyleConfig.Heading.Color; got != theme.Primary.Dark {
+		t.Errorf("focused markdown heading = %s, want the primary color %s", got, theme.Primary.Dark)
+	}
+	if got := *ASCIIStyleConfig.Document.Color; got != theme.ForegroundDim.Dark {
+		t.Errorf("unfocused markdown text = %s, want the dim foreground %s", got, theme.ForegroundDim.Dark)
+	}
+	if got := *MarkdownTheme(true).Document.BackgroundColor; got != theme.Background.Dark {
+		t.Errorf("markdown background = %s, want %s", got, theme.Background.Dark)
+	}
+}
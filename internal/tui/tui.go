@@ -6,6 +6,7 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	zone "github.com/lrstanley/bubblezone"
 	"github.com/opencode-ai/opencode/internal/app"
 	"github.com/opencode-ai/opencode/internal/config"
 	"github.com/opencode-ai/opencode/internal/logging"
@@ -14,46 +15,15 @@ import (
 	"github.com/opencode-ai/opencode/internal/tui/components/chat"
 	"github.com/opencode-ai/opencode/internal/tui/components/core"
 	"github.com/opencode-ai/opencode/internal/tui/components/dialog"
+	"github.com/opencode-ai/opencode/internal/tui/components/notify"
+	"github.com/opencode-ai/opencode/internal/tui/keymap"
 	"github.com/opencode-ai/opencode/internal/tui/layout"
 	"github.com/opencode-ai/opencode/internal/tui/page"
 	"github.com/opencode-ai/opencode/internal/tui/page/tools"
+	"github.com/opencode-ai/opencode/internal/tui/styles"
 	"github.com/opencode-ai/opencode/internal/tui/util"
 )
 
-type keyMap struct {
-	Logs          key.Binding
-	Quit          key.Binding
-	Help          key.Binding
-	SwitchSession key.Binding
-	Commands      key.Binding
-}
-
-var keys = keyMap{
-	Logs: key.NewBinding(
-		key.WithKeys("ctrl+l"),
-		key.WithHelp("ctrl+l", "logs"),
-	),
-
-	Quit: key.NewBinding(
-		key.WithKeys("ctrl+c"),
-		key.WithHelp("ctrl+c", "quit"),
-	),
-	Help: key.NewBinding(
-		key.WithKeys("ctrl+_"),
-		key.WithHelp("ctrl+?", "toggle help"),
-	),
-
-	SwitchSession: key.NewBinding(
-		key.WithKeys("ctrl+a"),
-		key.WithHelp("ctrl+a", "switch session"),
-	),
-
-	Commands: key.NewBinding(
-		key.WithKeys("ctrl+k"),
-		key.WithHelp("ctrl+k", "commands"),
-	),
-}
-
 var helpEsc = key.NewBinding(
 	key.WithKeys("?"),
 	key.WithHelp("?", "toggle help"),
@@ -69,6 +39,9 @@ var logsKeyReturnKey = key.NewBinding(
 	key.WithHelp("backspace/q", "go back"),
 )
 
+// toggleKnowledgeMsg turns swarm knowledge on or off for the open session
+type toggleKnowledgeMsg struct{}
+
 type appModel struct {
 	width, height int
 	currentPage   page.PageID
@@ -77,6 +50,8 @@ type appModel struct {
 	loadedPages   map[page.PageID]bool
 	status        core.StatusCmp
 	app           *app.App
+	// sessionID is the session open in the chat page
+	sessionID string
 
 	showPermissions bool
 	permissions     dialog.PermissionDialogCmp
@@ -96,6 +71,8 @@ type appModel struct {
 
 	showInitDialog bool
 	initDialog     dialog.InitDialogCmp
+
+	notifier *notify.Notifier
 }
 
 func (a appModel) Init() tea.Cmd {
@@ -134,11 +111,23 @@ func (a appModel) Init() tea.Cmd {
 func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 	var cmd tea.Cmd
+
+	// The notification history popup takes key presses while it is open
+	if _, ok := msg.(tea.KeyMsg); ok && a.notifier.ShowingHistory() {
+		_, cmd = a.notifier.Update(msg)
+		return a, cmd
+	}
+
 	switch msg := msg.(type) {
+	case notify.Msg, notify.DismissMsg, notify.ToggleHistoryMsg:
+		_, cmd = a.notifier.Update(msg)
+		return a, cmd
 	case tea.WindowSizeMsg:
 		msg.Height -= 1 // Make space for the status bar
 		a.width, a.height = msg.Width, msg.Height
 
+		a.notifier.Update(msg)
+
 		s, _ := a.status.Update(msg)
 		a.status = s.(core.StatusCmp)
 		a.pages[a.currentPage], cmd = a.pages[a.currentPage].Update(msg)
@@ -162,6 +151,13 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		a.initDialog.SetSize(msg.Width, msg.Height)
 
+		return a, tea.Batch(cmds...)
+	case styles.ThemeChangedMsg:
+		// Every page rebuilds its cached styles, not only the visible one
+		for id, p := range a.pages {
+			a.pages[id], cmd = p.Update(msg)
+			cmds = append(cmds, cmd)
+		}
 		return a, tea.Batch(cmds...)
 	// Status
 	case util.InfoMsg:
@@ -211,6 +207,10 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case util.ClearStatusMsg:
 		s, _ := a.status.Update(msg)
 		a.status = s.(core.StatusCmp)
+	case core.SwarmHealthTickMsg:
+		s, cmd := a.status.Update(msg)
+		a.status = s.(core.StatusCmp)
+		return a, cmd
 
 	// Permission
 	case pubsub.Event[permission.PermissionRequest]:
@@ -269,8 +269,17 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 
-	case chat.SessionSelectedMsg:
-		a.sessionDialog.SetSelectedSession(msg.ID)
+	case chat.SessionSelectedMsg, chat.SessionClearedMsg:
+		a.sessionID = ""
+		if msg, ok := msg.(chat.SessionSelectedMsg); ok {
+			a.sessionDialog.SetSelectedSession(msg.ID)
+			a.sessionID = msg.ID
+		}
+		// Tools keep per session state, keep them in sync while hidden
+		if a.currentPage != page.ToolsPage {
+			a.pages[page.ToolsPage], cmd = a.pages[page.ToolsPage].Update(msg)
+			cmds = append(cmds, cmd)
+		}
 	case dialog.SessionSelectedMsg:
 		a.showSessionDialog = false
 		if a.currentPage == page.ChatPage {
@@ -278,6 +287,24 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 
+	case toggleKnowledgeMsg:
+		if a.sessionID == "" {
+			return a, util.ReportWarn("Start a session first")
+		}
+		if a.app.Knowledge.Toggle(a.sessionID) {
+			return a, util.ReportInfo("Swarm knowledge on for this session")
+		}
+		return a, util.ReportInfo("Swarm knowledge off for this session")
+
+	case chat.InsertTextMsg:
+		// Text exported from other pages lands in the chat editor
+		if a.currentPage != page.ChatPage {
+			cmds = append(cmds, a.moveToPage(page.ChatPage))
+		}
+		a.pages[page.ChatPage], cmd = a.pages[page.ChatPage].Update(msg)
+		cmds = append(cmds, cmd)
+		return a, tea.Batch(cmds...)
+
 	case dialog.CommandSelectedMsg:
 		a.showCommandDialog = false
 		// Execute the command handler if available
@@ -288,7 +315,7 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		switch {
-		case key.Matches(msg, keys.Quit):
+		case key.Matches(msg, keymap.Get(keymap.Quit)):
 			a.showQuit = !a.showQuit
 			if a.showHelp {
 				a.showHelp = false
@@ -300,7 +327,7 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				a.showCommandDialog = false
 			}
 			return a, nil
-		case key.Matches(msg, keys.SwitchSession):
+		case key.Matches(msg, keymap.Get(keymap.SwitchSession)):
 			if a.currentPage == page.ChatPage && !a.showQuit && !a.showPermissions && !a.showCommandDialog {
 				// Load sessions and show the dialog
 				sessions, err := a.app.Sessions.List(context.Background())
@@ -315,7 +342,7 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return a, nil
 			}
 			return a, nil
-		case key.Matches(msg, keys.Commands):
+		case key.Matches(msg, keymap.Get(keymap.Commands)):
 			if a.currentPage == page.ChatPage && !a.showQuit && !a.showPermissions && !a.showSessionDialog {
 				// Show commands dialog
 				if len(a.commands) == 0 {
@@ -331,7 +358,10 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return a, a.moveToPage(page.ChatPage)
 			}
 			if a.currentPage == page.ToolsPage {
-				return a, a.moveToPage(page.ChatPage)
+				// Open tools and the menu filter use q and backspace
+				if p, ok := a.pages[page.ToolsPage].(interface{ HandlesBackKeys() bool }); !ok || !p.HandlesBackKeys() {
+					return a, a.moveToPage(page.ChatPage)
+				}
 			}
 		case key.Matches(msg, returnKey):
 			if a.showQuit {
@@ -350,9 +380,20 @@ func (a appModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				return a, nil
 			}
-		case key.Matches(msg, keys.Logs):
+		case key.Matches(msg, keymap.Get(keymap.Logs)):
 			return a, a.moveToPage(page.LogsPage)
-		case key.Matches(msg, keys.Help):
+		case key.Matches(msg, keymap.Get(keymap.SwarmHealth)):
+			if a.app.Swarm == nil {
+				return a, util.ReportWarn("No swarm is running")
+			}
+			cmd := a.moveToPage(page.ToolsPage)
+			if a.currentPage != page.ToolsPage {
+				return a, cmd
+			}
+			var openCmd tea.Cmd
+			a.pages[page.ToolsPage], openCmd = a.pages[page.ToolsPage].Update(tools.OpenToolMsg{Name: tools.SwarmHealthTool})
+			return a, tea.Batch(cmd, openCmd)
+		case key.Matches(msg, keymap.Get(keymap.Help)):
 			if a.showQuit {
 				return a, nil
 			}
@@ -477,13 +518,13 @@ func (a appModel) View() string {
 	}
 
 	if !a.app.CoderAgent.IsBusy() {
-		a.status.SetHelpMsg("ctrl+? help")
+		a.status.SetHelpMsg(keymap.Get(keymap.Help).Help().Key + " help")
 	} else {
 		a.status.SetHelpMsg("? help")
 	}
 
 	if a.showHelp {
-		bindings := layout.KeyMapToSlice(keys)
+		bindings := keymap.Active().Scope(keymap.ScopeGlobal)
 		if p, ok := a.pages[a.currentPage].(layout.Bindings); ok {
 			bindings = append(bindings, p.BindingKeys()...)
 		}
@@ -568,21 +609,65 @@ func (a appModel) View() string {
 		)
 	}
 
-	return appView
+	// Resolve the mouse zones marked by components and strip their markers
+	return zone.Scan(a.notifier.Overlay(appView))
+}
+
+// applyConfiguredTheme activates the theme selected in the user config
+func applyConfiguredTheme() {
+	cfg := config.Get()
+	if cfg == nil || cfg.TUI.Theme == "" {
+		return
+	}
+	if cfg.TUI.Theme == styles.ThemeCustom {
+		theme, err := styles.CustomTheme(styles.CurrentTheme(), cfg.TUI.CustomTheme)
+		if err != nil {
+			logging.Warn("Invalid custom theme, using default", "error", err)
+			return
+		}
+		styles.RegisterTheme(theme)
+	}
+	if err := styles.SetTheme(cfg.TUI.Theme); err != nil {
+		logging.Warn("Failed to apply theme", "error", err)
+	}
+}
+
+// applyConfiguredKeys activates the key bindings from the user config
+func applyConfiguredKeys() {
+	cfg := config.Get()
+	if cfg == nil {
+		return
+	}
+	km, errs := keymap.New(cfg.TUI.Keybindings)
+	for _, err := range errs {
+		logging.WarnPersist("Invalid key binding: " + err.Error())
+	}
+	keymap.SetActive(km)
 }
 
 func New(app *app.App) tea.Model {
+	applyConfiguredTheme()
+	applyConfiguredKeys()
+
+	// The status bar and the dashboard show the health of the swarm
+	var swarmHealth core.SwarmHealthSource
+	if app.Swarm != nil {
+		swarmHealth = app.Swarm
+		tools.RegisterSwarmHealth(app.Swarm)
+	}
+
 	startPage := page.ChatPage
 	model := &appModel{
 		currentPage:   startPage,
 		loadedPages:   make(map[page.PageID]bool),
-		status:        core.NewStatusCmp(app.LSPClients),
+		status:        core.NewStatusCmp(app.LSPClients, swarmHealth),
 		help:          dialog.NewHelpCmp(),
 		quit:          dialog.NewQuitCmp(),
 		sessionDialog: dialog.NewSessionDialogCmp(),
 		commandDialog: dialog.NewCommandDialogCmp(),
 		permissions:   dialog.NewPermissionDialogCmp(),
 		initDialog:    dialog.NewInitDialogCmp(),
+		notifier:      notify.NewNotifier(),
 		app:           app,
 		commands:      []dialog.Command{},
 		pages: map[page.PageID]tea.Model{
@@ -611,6 +696,39 @@ If there are Cursor rules (in .cursor/rules/ or .cursorrules) or Copilot rules (
 			)
 		},
 	})
+	model.RegisterCommand(dialog.Command{
+		ID:          "theme",
+		Title:       "Switch Theme",
+		Description: "Cycle through the available color themes",
+		Handler: func(cmd dialog.Command) tea.Cmd {
+			name := styles.NextTheme()
+			if err := styles.SetTheme(name); err != nil {
+				return util.ReportError(err)
+			}
+			return tea.Batch(
+				util.CmdHandler(styles.ThemeChangedMsg{Theme: styles.CurrentTheme()}),
+				util.ReportInfo("Theme: "+name),
+			)
+		},
+	})
+	model.RegisterCommand(dialog.Command{
+		ID:          "notifications",
+		Title:       "Notifications",
+		Description: "Show recent notifications",
+		Handler: func(cmd dialog.Command) tea.Cmd {
+			return util.CmdHandler(notify.ToggleHistoryMsg{})
+		},
+	})
+	if app.Knowledge != nil {
+		model.RegisterCommand(dialog.Command{
+			ID:          "knowledge",
+			Title:       "Toggle Swarm Knowledge",
+			Description: "Add relevant swarm memories to the prompts of this session, or stop",
+			Handler: func(cmd dialog.Command) tea.Cmd {
+				return util.CmdHandler(toggleKnowledgeMsg{})
+			},
+		})
+	}
 	// Add Tools command to access the new tools page
 	model.RegisterCommand(dialog.Command{
 		ID:          "tools",
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RefreshMsg asks the view whose Refresher sent it to reload
+type RefreshMsg struct {
+	refresher  *Refresher
+	generation int
+}
+
+// Refresher reloads a view on an interval while it is shown. Every Start
+// begins a new loop and ends the one before, so reopening a view never runs
+// two, and views ignore the ticks of other views.
+type Refresher struct {
+	interval   time.Duration
+	generation int
+}
+
+// NewRefresher creates a refresher ticking every interval
+func NewRefresher(interval time.Duration) *Refresher {
+	return &Refresher{interval: interval}
+}
+
+// Start begins a new refresh loop, ending the one before
+func (r *Refresher) Start() tea.Cmd {
+	r.generation++
+	return r.Next()
+}
+
+// Due reports whether msg is a tick of the current loop of r. The view
+// reloads and returns Next to keep the loop going.
+func (r *Refresher) Due(msg RefreshMsg) bool {
+	return msg.refresher == r && msg.generation == r.generation
+}
+
+// Next schedules the next tick of the current loop
+func (r *Refresher) Next() tea.Cmd {
+	generation := r.generation
+	return tea.Tick(r.interval, func(time.Time) tea.Msg {
+		return RefreshMsg{refresher: r, generation: generation}
+	})
+}